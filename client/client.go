@@ -0,0 +1,64 @@
+// Package client generates keys and encrypts and decrypts boolean
+// ciphertexts on the caller's side, so plaintexts and client keys never
+// reach the server. It needs neither the server nor tfhe-c and builds for
+// js/wasm; see cmd/wasm for the browser bindings.
+//
+// Keys and ciphertexts use the pure-Go backend's format, which servers and
+// tools built with -tags purego accept. The server key from GenerateKeys is
+// what a server needs to compute on the ciphertexts.
+package client
+
+import (
+	"tfhe-go/internal/tfhe/purego"
+)
+
+// ClientKey encrypts and decrypts.
+type ClientKey struct {
+	sk *purego.SecretKey
+}
+
+// GenerateKeys creates a client key and the serialized server key that
+// evaluates gates on its ciphertexts.
+func GenerateKeys() (*ClientKey, []byte, error) {
+	sk, ck, err := purego.GenerateKeys(purego.DefaultParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	server, err := ck.MarshalBinary()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &ClientKey{sk: sk}, server, nil
+}
+
+// ParseClientKey decodes a client key serialized with MarshalBinary.
+func ParseClientKey(data []byte) (*ClientKey, error) {
+	sk, err := purego.UnmarshalSecretKey(data)
+	if err != nil {
+		return nil, err
+	}
+	return &ClientKey{sk: sk}, nil
+}
+
+// MarshalBinary serializes the key for storage on the client.
+func (k *ClientKey) MarshalBinary() ([]byte, error) {
+	return k.sk.MarshalBinary()
+}
+
+// Encrypt returns the serialized encryption of v.
+func (k *ClientKey) Encrypt(v bool) ([]byte, error) {
+	ct, err := k.sk.Encrypt(v)
+	if err != nil {
+		return nil, err
+	}
+	return ct.MarshalBinary()
+}
+
+// Decrypt decrypts a serialized ciphertext.
+func (k *ClientKey) Decrypt(data []byte) (bool, error) {
+	ct, err := purego.UnmarshalCiphertext(data)
+	if err != nil {
+		return false, err
+	}
+	return k.sk.Decrypt(ct)
+}
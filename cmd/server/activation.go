@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd.
+const listenFDsStart = 3
+
+// activatedListener is a socket inherited through socket activation.
+type activatedListener struct {
+	name string
+	net.Listener
+}
+
+// activatedListeners returns the sockets passed by systemd socket activation
+// (LISTEN_PID, LISTEN_FDS and LISTEN_FDNAMES) in descriptor order, or nil
+// when the process was not socket-activated. The variables are cleared so
+// child processes do not inherit them.
+func activatedListeners() ([]activatedListener, error) {
+	pid, err := strconv.Atoi(os.Getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, nil
+	}
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+	n, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", os.Getenv("LISTEN_FDS"))
+	}
+	names := strings.Split(os.Getenv("LISTEN_FDNAMES"), ":")
+	out := make([]activatedListener, 0, n)
+	for i := 0; i < n; i++ {
+		name := ""
+		if i < len(names) {
+			name = names[i]
+		}
+		f := os.NewFile(uintptr(listenFDsStart+i), name)
+		l, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("socket-activated fd %d (%s): %w", listenFDsStart+i, name, err)
+		}
+		out = append(out, activatedListener{name: name, Listener: l})
+	}
+	return out, nil
+}
+
+// take returns the inherited socket for name: the one whose
+// FileDescriptorName is name, or else the index-th socket when none is
+// named "http" or "grpc". It returns nil when there is no such socket.
+func take(activated []activatedListener, name string, index int) net.Listener {
+	named := false
+	for _, l := range activated {
+		if l.name == name {
+			return l.Listener
+		}
+		named = named || l.name == "http" || l.name == "grpc"
+	}
+	if !named && index < len(activated) {
+		return activated[index].Listener
+	}
+	return nil
+}
+
+// listen returns the inherited socket for name, or binds addr.
+func listen(activated []activatedListener, name string, index int, addr string) (net.Listener, error) {
+	if l := take(activated, name, index); l != nil {
+		return l, nil
+	}
+	return net.Listen("tcp", addr)
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"tfhe-go/internal/audit"
+)
+
+// openAuditLog opens the audit sink spec names, chaining records with the
+// key read from keyFile when one is given.
+func openAuditLog(spec, keyFile string) (*audit.Logger, error) {
+	var key []byte
+	if keyFile != "" {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		if key = bytes.TrimSpace(data); len(key) < 16 {
+			return nil, fmt.Errorf("%s: audit key must be at least 16 bytes", keyFile)
+		}
+	}
+	sink, err := audit.Open(spec)
+	if err != nil {
+		return nil, err
+	}
+	l, err := audit.New(sink, key)
+	if err != nil {
+		sink.Close()
+		return nil, err
+	}
+	return l, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"expvar"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// debugListenAddr defaults an empty host to the loopback interface and
+// refuses anything else, since profiles and goroutine dumps reveal internals.
+func debugListenAddr(addr string) (string, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if host == "" {
+		return net.JoinHostPort("127.0.0.1", port), nil
+	}
+	if host == "localhost" {
+		return addr, nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return "", fmt.Errorf("debug address %q must be a loopback address", addr)
+	}
+	return addr, nil
+}
+
+// newDebugServer serves net/http/pprof under /debug/pprof/ and expvar under
+// /debug/vars, which adds cgo call counts, goroutines, and time spent in
+// native calls versus whole operations to the standard memstats and cmdline.
+func newDebugServer(addr string, recorder *tfhe.Recorder) *http.Server {
+	publishDebugVars(recorder)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.Handle("/debug/vars", expvar.Handler())
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+func publishDebugVars(recorder *tfhe.Recorder) {
+	expvar.Publish("cgo_calls", expvar.Func(func() any { return runtime.NumCgoCall() }))
+	expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+	expvar.Publish("tfhe_in_flight", expvar.Func(func() any { return tfhe.InFlight() }))
+	expvar.Publish("tfhe_op_nanos", expvar.Func(func() any { return tfhe.OpNanos() }))
+	expvar.Publish("tfhe_native", expvar.Func(func() any { return tfhe.NativeStats() }))
+	expvar.Publish("tfhe_memory", expvar.Func(func() any { return tfhe.MemoryUsage() }))
+	expvar.Publish("tfhe_deadlines", expvar.Func(func() any { return tfhe.DeadlineUsage() }))
+	expvar.Publish("tfhe_compute", expvar.Func(func() any { return tfhe.ComputeUsage() }))
+	expvar.Publish("tfhe_native_panics", expvar.Func(func() any { return tfhe.NativePanics() }))
+	expvar.Publish("tfhe_operand_cache", expvar.Func(func() any { return tfhe.OperandCacheUsage() }))
+	expvar.Publish("tfhe_expansion_cache", expvar.Func(func() any { return tfhe.ExpansionCacheUsage() }))
+	if recorder != nil {
+		expvar.Publish("tfhe_ops", expvar.Func(func() any { return recorder.Snapshot() }))
+	}
+}
@@ -2,57 +2,555 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
+	"database/sql"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
+	"runtime"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
-	"tfhe-go/internal/httpapi"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/config"
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/kms"
+	"tfhe-go/internal/postgres"
+	"tfhe-go/internal/quota"
+	"tfhe-go/internal/store"
 	"tfhe-go/internal/tfhe"
+	"tfhe-go/internal/tracing"
+	"tfhe-go/internal/vault"
+	"tfhe-go/pkg/server"
 )
 
 func main() {
-	booleanService, err := tfhe.NewBooleanService()
+	// A process started as an isolated worker only serves its parent.
+	tfhe.RunIsolatedWorker()
+
+	// The config file only fills in unset TFHE_* variables, so it must be
+	// applied before the flags below read their defaults from them.
+	configPath := config.PathFromArgs(os.Args[1:])
+	var fileConfig *config.Config
+	if configPath != "" {
+		var err error
+		if fileConfig, err = config.Load(configPath); err != nil {
+			log.Fatalf("invalid configuration: %v", err)
+		}
+		if err := fileConfig.Apply(); err != nil {
+			log.Fatalf("failed to apply configuration: %v", err)
+		}
+	}
+	flag.String("config", configPath, "YAML configuration file; flags and TFHE_* environment variables take precedence over its settings")
+
+	var tlsOpts tlsOptions
+	flag.StringVar(&tlsOpts.CertFile, "tls-cert", os.Getenv("TFHE_TLS_CERT_FILE"), "PEM certificate chain; enables TLS on both listeners")
+	flag.StringVar(&tlsOpts.KeyFile, "tls-key", os.Getenv("TFHE_TLS_KEY_FILE"), "PEM private key for -tls-cert")
+	flag.BoolVar(&tlsOpts.HTTP2, "http2", os.Getenv("TFHE_HTTP2") != "0", "negotiate HTTP/2 over TLS")
+	addr := flag.String("addr", envString("TFHE_ADDR", ":8999"), "HTTP listen address")
+	grpcAddr := flag.String("grpc-addr", envString("TFHE_GRPC_ADDR", ":9090"), "gRPC listen address")
+	readHeaderTimeout := flag.Duration("read-header-timeout", envDuration("TFHE_READ_HEADER_TIMEOUT", 5*time.Second), "time allowed to read request headers")
+	readTimeout := flag.Duration("read-timeout", envDuration("TFHE_READ_TIMEOUT", time.Minute), "time allowed to read a whole request, including the body; 0 means no limit")
+	writeTimeout := flag.Duration("write-timeout", envDuration("TFHE_WRITE_TIMEOUT", 5*time.Minute), "time allowed from the end of the request headers to the end of the response, which includes FHE evaluation; 0 means no limit")
+	idleTimeout := flag.Duration("idle-timeout", envDuration("TFHE_IDLE_TIMEOUT", 2*time.Minute), "how long an idle keep-alive connection is kept open")
+	maxHeaderBytes := flag.Int("max-header-bytes", envInt("TFHE_MAX_HEADER_BYTES", http.DefaultMaxHeaderBytes), "largest request header block accepted, in bytes")
+	shutdownGrace := flag.Duration("shutdown-grace", envDuration("TFHE_SHUTDOWN_GRACE", 30*time.Second), "how long in-flight requests may run after SIGINT/SIGTERM before connections are closed")
+	workers := flag.Int("workers", envInt("TFHE_WORKERS", runtime.GOMAXPROCS(0)), "operations a single batch request or circuit runs in parallel")
+	rateLimit := flag.Float64("rate-limit", envFloat("TFHE_RATE_LIMIT", 0), "requests per second allowed per client; 0 disables rate limiting")
+	rateBurst := flag.Int("rate-burst", envInt("TFHE_RATE_BURST", 0), "requests a client may burst above -rate-limit; defaults to one second's worth")
+	metricsAddr := flag.String("metrics-addr", os.Getenv("TFHE_METRICS_ADDR"), "address serving Prometheus /metrics, e.g. :9100; empty disables")
+	tracingEnabled := flag.Bool("tracing", tracing.Configured(), "export OpenTelemetry traces over OTLP/HTTP; defaults to on when OTEL_EXPORTER_OTLP_ENDPOINT is set")
+	corsOrigins := flag.String("cors-origins", os.Getenv("TFHE_CORS_ORIGINS"), "comma-separated origins allowed to call the API from a browser, or *; empty disables CORS")
+	corsMethods := flag.String("cors-methods", os.Getenv("TFHE_CORS_METHODS"), "comma-separated methods allowed for cross-origin requests")
+	corsHeaders := flag.String("cors-headers", os.Getenv("TFHE_CORS_HEADERS"), "comma-separated request headers allowed for cross-origin requests")
+	corsCredentials := flag.Bool("cors-credentials", os.Getenv("TFHE_CORS_CREDENTIALS") != "", "allow credentialed cross-origin requests")
+	compression := flag.Bool("compression", os.Getenv("TFHE_COMPRESSION") != "0", "negotiate gzip/deflate Content-Encoding for requests and responses")
+	serverTiming := flag.Bool("server-timing", os.Getenv("TFHE_SERVER_TIMING") != "0", "report each request's queue, deserialize, compute and serialize time in a Server-Timing header (a server-timing trailer over gRPC)")
+	compressMinSize := flag.Int("compress-min-size", envInt("TFHE_COMPRESS_MIN_SIZE", 1<<10), "smallest response body, in bytes, worth compressing")
+	readyMaxInFlight := flag.Int("ready-max-inflight", envInt("TFHE_READY_MAX_INFLIGHT", 4*runtime.GOMAXPROCS(0)), "operations in flight at which /readyz reports no spare capacity; 0 disables the check")
+	readyMaxNativeMemory := flag.Int("ready-max-native-memory", envInt("TFHE_READY_MAX_NATIVE_MEMORY", 0), "bytes of native memory (the C heap where measured, else the live-object estimate) at which /readyz reports not ready; 0 disables the check")
+	opTimeout := flag.Duration("op-timeout", envDuration("TFHE_OP_TIMEOUT", 0), "how long a request waits for one homomorphic evaluation before failing with 503; the native call still runs to completion; 0 waits indefinitely")
+	computeLimit := flag.Int("compute-limit", envInt("TFHE_COMPUTE_LIMIT", 0), "native tfhe calls running at once across all requests; further calls queue for a slot; 0 is unbounded")
+	computeLimitTenant := flag.Int("compute-limit-tenant", envInt("TFHE_COMPUTE_LIMIT_TENANT", 0), "native tfhe calls one tenant runs at once; 0 is unbounded")
+	computeQueueTimeout := flag.Duration("compute-queue-timeout", envDuration("TFHE_COMPUTE_QUEUE_TIMEOUT", 0), "how long a call queues for a compute slot before its request fails with 503; 0 waits until the request ends")
+	slowOp := flag.Duration("slow-op", envDuration("TFHE_SLOW_OP", 0), "log and count evaluations taking at least this long; 0 disables")
+	isolateWorkers := flag.Int("isolate-workers", envInt("TFHE_ISOLATE_WORKERS", 0), "worker processes that deserialize client ciphertexts and keys before the server does, so input crashing tfhe-c fails its request instead of the server; 0 disables")
+	rejectTrivial := flag.Bool("reject-trivial", os.Getenv("TFHE_REJECT_TRIVIAL") != "", "fail evaluations whose result is a trivial encryption, readable without the key, with 400 instead of returning it")
+	operandCache := flag.Int("operand-cache", envInt("TFHE_OPERAND_CACHE", 0), "deserialized operands kept for reuse across requests, by SHA-256 of their bytes; 0 disables")
+	expansionCache := flag.Int("expansion-cache", envInt("TFHE_EXPANSION_CACHE", 0), "bytes of expanded compact ciphertext lists kept for reuse, by SHA-256 of the list; 0 disables")
+	expansionCacheTenant := flag.Int("expansion-cache-tenant", envInt("TFHE_EXPANSION_CACHE_TENANT", 0), "bytes of the -expansion-cache one tenant may hold; 0 leaves tenants bounded by the cache alone")
+	selfTestInterval := flag.Duration("self-test-interval", envDuration("TFHE_SELF_TEST_INTERVAL", 30*time.Second), "how often /readyz re-runs the native self-test")
+	idempotencyTTL := flag.Duration("idempotency-ttl", envDuration("TFHE_IDEMPOTENCY_TTL", 24*time.Hour), "how long responses to requests with an Idempotency-Key are replayed; 0 disables")
+	quotaDaily := flag.String("quota-daily", os.Getenv("TFHE_QUOTA_DAILY"), "per-tenant daily quota, e.g. operations=100000,compute=2h,bytes=10GiB; empty is unlimited")
+	quotaMonthly := flag.String("quota-monthly", os.Getenv("TFHE_QUOTA_MONTHLY"), "per-tenant monthly quota in the -quota-daily syntax; empty is unlimited")
+	debugAddr := flag.String("debug-addr", os.Getenv("TFHE_DEBUG_ADDR"), "loopback address serving pprof and expvar under /debug/, e.g. :6060 (bound to 127.0.0.1); empty disables")
+	storageBackend := flag.String("storage", envString("TFHE_STORAGE_BACKEND", "memory"), "ciphertext store: memory, s3 configured by TFHE_S3_*, or postgres")
+	memoryStoreMaxBytes := flag.Int("memory-store-max-bytes", envInt("TFHE_MEMORY_STORE_MAX_BYTES", 1<<30), "ciphertext bytes the memory store holds before evicting the least recently used handles; 0 is unlimited")
+	memoryStoreMaxEntries := flag.Int("memory-store-max-entries", envInt("TFHE_MEMORY_STORE_MAX_ENTRIES", 0), "handles the memory store holds before evicting the least recently used; 0 is unlimited")
+	memoryStoreTTL := flag.Duration("memory-store-ttl", envDuration("TFHE_MEMORY_STORE_TTL", 24*time.Hour), "how long the memory store keeps a handle nobody reads; 0 keeps it until evicted or deleted")
+	keyCustody := flag.String("key-custody", os.Getenv("TFHE_KEY_CUSTODY"), "where client keys are kept: empty (with the other keys) or vault, configured by VAULT_* and TFHE_VAULT_*; needs -postgres-dsn")
+	trustedDecrypt := flag.Bool("trusted-decrypt", os.Getenv("TFHE_TRUSTED_DECRYPT") != "", "with -key-custody, fetch client keys so the server can encrypt and decrypt; otherwise it only evaluates")
+	trustedReencrypt := flag.Bool("trusted-reencrypt", os.Getenv("TFHE_TRUSTED_REENCRYPT") != "", "allow recipient_key and /v1/reencrypt/public, which decrypt each result on this server before encrypting it under the caller's compact public key; otherwise they fail with 403")
+	kmsProvider := flag.String("kms", os.Getenv("TFHE_KMS"), "key management service encrypting persisted keys at rest: empty or aws; needs -postgres-dsn")
+	kmsKeyID := flag.String("kms-key-id", os.Getenv("TFHE_KMS_KEY_ID"), "-kms key wrapping the data keys, e.g. alias/tfhe-go")
+	kmsMigratePlaintext := flag.Bool("kms-migrate-plaintext", os.Getenv("TFHE_KMS_MIGRATE_PLAINTEXT") != "", "with -kms, trust key sets stored in plaintext and seal them on load, logging each; otherwise they fail startup")
+	postgresDSN := flag.String("postgres-dsn", os.Getenv("TFHE_POSTGRES_DSN"), "PostgreSQL URL persisting key sets (and handles with -storage postgres); empty keeps keys in memory")
+	queueBackend := flag.String("queue", os.Getenv("TFHE_QUEUE"), "message queue to evaluate requests from alongside the APIs: empty or nats")
+	queueURL := flag.String("queue-url", envString("TFHE_QUEUE_URL", "nats://127.0.0.1:4222"), "-queue server URL")
+	queueRequests := flag.String("queue-requests", envString("TFHE_QUEUE_REQUESTS", "tfhe.requests"), "subject requests are consumed from")
+	queueResults := flag.String("queue-results", envString("TFHE_QUEUE_RESULTS", "tfhe.results"), "subject results are published to when a request has no reply subject")
+	queueGroup := flag.String("queue-group", envString("TFHE_QUEUE_GROUP", "tfhe-go"), "queue group sharing requests between workers")
+	featureSpec := flag.String("features", os.Getenv("TFHE_FEATURES"), "route families switched off or restricted to -admin-ids, e.g. decrypt=off,public_encrypt=off,keys=admin; families: "+featureNames())
+	adminIDs := flag.String("admin-ids", os.Getenv("TFHE_ADMIN_IDS"), "comma-separated identity IDs (API key names or token subjects) allowed on /admin; empty allows any authenticated caller")
+	estimateProfile := flag.String("estimate-profile", os.Getenv("TFHE_ESTIMATE_PROFILE"), "`tfhe bench -format json` results, measured on this hardware, that /estimate predicts costs from")
+	estimateCalibrate := flag.Int("estimate-calibrate", envInt("TFHE_ESTIMATE_CALIBRATE", 0), "without -estimate-profile, iterations per operation of a calibration run in the background at startup; 0 disables /estimate")
+	auditSpec := flag.String("audit-log", os.Getenv("TFHE_AUDIT_LOG"), "audit log sink for key, decrypt and compute events: a file path, syslog, syslog://host:port, syslog+tcp://host:port or kafka+http(s)://proxy/topic; empty disables")
+	auditKeyFile := flag.String("audit-key-file", os.Getenv("TFHE_AUDIT_KEY_FILE"), "file holding the HMAC key chaining -audit-log records; empty chains them with plain SHA-256")
+	sessionLimit := flag.Int("session-limit", envInt("TFHE_SESSION_LIMIT", 0), "session key sets one tenant may hold open at once; 0 disables /sessions")
+	sessionTTL := flag.Duration("session-ttl", envDuration("TFHE_SESSION_TTL", 15*time.Minute), "lifetime of a session created without ttl_seconds")
+	sessionMaxTTL := flag.Duration("session-max-ttl", envDuration("TFHE_SESSION_MAX_TTL", 24*time.Hour), "longest lifetime a session may ask for")
+	replayKeyFile := flag.String("replay-key-file", os.Getenv("TFHE_REPLAY_KEY_FILE"), "file holding the key clients sign mutating requests with; enables replay protection, rejecting unsigned requests and reused nonces")
+	replayWindow := flag.Duration("replay-window", envDuration("TFHE_REPLAY_WINDOW", 5*time.Minute), "how far a signed request's timestamp may be from the server's clock; nonces are remembered this long")
+	flag.Parse()
+
+	opts := server.Options{
+		Workers:              *workers,
+		Features:             *featureSpec,
+		AdminIDs:             splitList(*adminIDs),
+		RateLimit:            *rateLimit,
+		RateBurst:            *rateBurst,
+		IdempotencyTTL:       *idempotencyTTL,
+		Compression:          *compression,
+		CompressMinSize:      *compressMinSize,
+		ServerTiming:         *serverTiming,
+		SessionLimit:         *sessionLimit,
+		SessionTTL:           *sessionTTL,
+		SessionMaxTTL:        *sessionMaxTTL,
+		EstimateProfile:      *estimateProfile,
+		EstimateCalibrate:    *estimateCalibrate,
+		ReadyMaxInFlight:     *readyMaxInFlight,
+		ReadyMaxNativeMemory: int64(*readyMaxNativeMemory),
+		SelfTestInterval:     *selfTestInterval,
+		Metrics:              *metricsAddr != "",
+		Tracing:              *tracingEnabled,
+		SwaggerUI:            os.Getenv("TFHE_SWAGGER_UI") != "",
+		Memory: store.MemoryOptions{
+			MaxBytes:   int64(*memoryStoreMaxBytes),
+			MaxEntries: *memoryStoreMaxEntries,
+			TTL:        *memoryStoreTTL,
+		},
+	}
+	var err error
+	if opts.Quotas.Daily, err = quota.ParseLimits(*quotaDaily); err != nil {
+		log.Fatalf("invalid -quota-daily: %v", err)
+	}
+	if opts.Quotas.Monthly, err = quota.ParseLimits(*quotaMonthly); err != nil {
+		log.Fatalf("invalid -quota-monthly: %v", err)
+	}
+	if origins := splitList(*corsOrigins); len(origins) > 0 {
+		opts.CORS = &server.CORSOptions{
+			AllowedOrigins:   origins,
+			AllowedMethods:   splitList(*corsMethods),
+			AllowedHeaders:   splitList(*corsHeaders),
+			AllowCredentials: *corsCredentials,
+		}
+	}
+
+	if *auditSpec != "" {
+		if opts.AuditLog, err = openAuditLog(*auditSpec, *auditKeyFile); err != nil {
+			log.Fatalf("failed to open audit log: %v", err)
+		}
+		defer opts.AuditLog.Close()
+		log.Printf("audit log enabled")
+	}
+
+	if *tracingEnabled {
+		shutdown, err := tracing.Setup(context.Background())
+		if err != nil {
+			log.Fatalf("failed to configure tracing: %v", err)
+		}
+		defer func() {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := shutdown(ctx); err != nil {
+				log.Printf("trace export shutdown failed: %v", err)
+			}
+		}()
+		log.Printf("opentelemetry tracing enabled")
+	}
+
+	var tlsConfig *tls.Config
+	var reload reloader
+	if tlsOpts.Enabled() {
+		var err error
+		if tlsConfig, reload.cert, err = tlsOpts.Config(); err != nil {
+			log.Fatalf("failed to load tls configuration: %v", err)
+		}
+	}
+	opts.Reload = reload.Reload
+
+	if issuer := os.Getenv("TFHE_JWT_ISSUER"); issuer != "" {
+		validator, err := auth.NewJWTValidator(auth.JWTConfig{
+			Issuer:      issuer,
+			Audience:    os.Getenv("TFHE_JWT_AUDIENCE"),
+			JWKSURL:     os.Getenv("TFHE_JWT_JWKS_URL"),
+			TenantClaim: os.Getenv("TFHE_JWT_TENANT_CLAIM"),
+			KeyIDClaim:  os.Getenv("TFHE_JWT_KEY_ID_CLAIM"),
+		})
+		if err != nil {
+			log.Fatalf("failed to configure jwt authentication: %v", err)
+		}
+		log.Printf("jwt authentication enabled (issuer %s)", issuer)
+		opts.Authenticators = append(opts.Authenticators, validator)
+	}
+	reload.apiKeysFile, reload.apiKeysInline = os.Getenv("TFHE_API_KEYS_FILE"), os.Getenv("TFHE_API_KEYS")
+	apiKeys, err := auth.LoadAPIKeys(reload.apiKeysFile, reload.apiKeysInline)
 	if err != nil {
-		log.Fatalf("failed to init tfhe boolean service: %v", err)
+		log.Fatalf("failed to load api keys: %v", err)
+	}
+	if apiKeys.Len() > 0 {
+		log.Printf("api key authentication enabled (%d keys)", apiKeys.Len())
+		opts.Authenticators = append(opts.Authenticators, apiKeys)
+		reload.apiKeys = apiKeys
+	}
+	if *rateLimit > 0 {
+		log.Printf("rate limiting enabled (%g req/s per client)", *rateLimit)
+	}
+	if *replayKeyFile != "" {
+		if opts.ReplayGuard, err = openReplayGuard(*replayKeyFile, *replayWindow); err != nil {
+			log.Fatalf("failed to enable replay protection: %v", err)
+		}
+		log.Printf("replay protection enabled; mutating requests must be signed")
 	}
-	defer booleanService.Close()
 
-	uint8Service, err := tfhe.NewUint8Service()
+	var db *sql.DB
+	if *postgresDSN != "" {
+		dbCtx, cancelDB := context.WithTimeout(context.Background(), time.Minute)
+		if db, err = postgres.Open(dbCtx, *postgresDSN); err != nil {
+			log.Fatalf("failed to open postgres: %v", err)
+		}
+		defer db.Close()
+		opts.KeyStore = postgres.NewKeys(db)
+		switch *kmsProvider {
+		case "":
+		case "aws":
+			kek, err := kms.NewAWS(dbCtx, *kmsKeyID)
+			if err != nil {
+				log.Fatal(err)
+			}
+			envelope := kms.NewEnvelope(opts.KeyStore, kek, *kmsMigratePlaintext)
+			reload.rewrap = envelope.Rewrap
+			opts.KeyStore = envelope
+		default:
+			log.Fatalf("unknown -kms %q (want aws)", *kmsProvider)
+		}
+		switch *keyCustody {
+		case "":
+		case "vault":
+			vaultClient, err := vault.New(dbCtx, vaultConfig())
+			if err != nil {
+				log.Fatal(err)
+			}
+			defer vaultClient.Close()
+			opts.KeyStore = vault.NewCustody(vaultClient, opts.KeyStore, *trustedDecrypt)
+		default:
+			log.Fatalf("unknown -key-custody %q (want vault)", *keyCustody)
+		}
+		cancelDB()
+		// Every instance sharing the database computes under the same keys.
+		opts.WithholdClientKeys = *keyCustody != "" && !*trustedDecrypt
+	} else {
+		if *keyCustody != "" {
+			log.Fatalf("-key-custody needs -postgres-dsn to keep the server keys")
+		}
+		if *kmsProvider != "" {
+			log.Fatalf("-kms needs -postgres-dsn to keep the encrypted keys")
+		}
+	}
+
+	storeCtx, cancelStore := context.WithTimeout(context.Background(), 30*time.Second)
+	opts.Store, err = openStore(storeCtx, *storageBackend, db, opts.Memory)
+	cancelStore()
 	if err != nil {
-		log.Fatalf("failed to init tfhe uint8 service: %v", err)
+		log.Fatalf("failed to open %s ciphertext store: %v", *storageBackend, err)
+	}
+
+	if *queueBackend != "" {
+		broker, err := openQueue(*queueBackend, *queueURL, *queueRequests, *queueGroup, *queueResults)
+		if err != nil {
+			log.Fatalf("failed to connect to %s queue: %v", *queueBackend, err)
+		}
+		defer broker.Close()
+		opts.Queue = broker
 	}
-	defer uint8Service.Close()
 
-	mux := http.NewServeMux()
-	handler := httpapi.NewHandler(booleanService, uint8Service)
-	handler.Register(mux)
+	srv, err := server.New(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	addr := ":8999"
-	server := &http.Server{
-		Addr:              addr,
-		Handler:           mux,
-		ReadHeaderTimeout: 5 * time.Second,
+	// Listen before generating keys so probes are answered meanwhile: /healthz
+	// is up at once, while /readyz and the API report 503 until keys are ready.
+	httpServer := &http.Server{
+		Addr:              *addr,
+		Handler:           srv.Handler(),
+		ReadHeaderTimeout: *readHeaderTimeout,
+		ReadTimeout:       *readTimeout,
+		WriteTimeout:      *writeTimeout,
+		IdleTimeout:       *idleTimeout,
+		MaxHeaderBytes:    *maxHeaderBytes,
+		TLSConfig:         tlsConfig,
+	}
+	if tlsConfig != nil && !tlsOpts.HTTP2 {
+		// A non-nil empty map stops net/http from enabling HTTP/2.
+		httpServer.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
 	}
 
+	// Under socket activation the sockets named "http" and "grpc" (or the
+	// first and second, when unnamed) replace -addr and -grpc-addr.
+	activated, err := activatedListeners()
+	if err != nil {
+		log.Fatalf("socket activation: %v", err)
+	}
+	httpListener, err := listen(activated, "http", 0, *addr)
+	if err != nil {
+		log.Fatalf("listen error: %v", err)
+	}
 	go func() {
-		log.Printf("tfhe-go server listening on %s", addr)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		var err error
+		if tlsConfig != nil {
+			log.Printf("tfhe-go server listening on %s (tls)", httpListener.Addr())
+			err = httpServer.ServeTLS(httpListener, "", "")
+		} else {
+			log.Printf("tfhe-go server listening on %s", httpListener.Addr())
+			err = httpServer.Serve(httpListener)
+		}
+		if err != nil && err != http.ErrServerClosed {
 			log.Fatalf("server error: %v", err)
 		}
 	}()
 
+	if fileConfig != nil {
+		applyLimits(fileConfig.Limits)
+	}
+	tfhe.SetOperandCache(*operandCache)
+	tfhe.SetExpansionCache(int64(*expansionCache), int64(*expansionCacheTenant))
+	tfhe.SetOpTimeout(*opTimeout)
+	tfhe.SetSlowOpThreshold(*slowOp)
+	tfhe.SetComputeLimit(tfhe.ComputeLimit{Global: *computeLimit, PerTenant: *computeLimitTenant, Wait: *computeQueueTimeout})
+	tfhe.SetRejectTrivial(*rejectTrivial)
+	if err := tfhe.SetIsolation(*isolateWorkers); err != nil {
+		log.Fatalf("invalid -isolate-workers: %v", err)
+	}
+	tfhe.SetTrustedReencrypt(*trustedReencrypt)
+
+	if err := srv.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+	if db != nil {
+		log.Printf("key sets persisted in postgres")
+	}
+	if opts.WithholdClientKeys {
+		log.Printf("client keys held in %s custody; encrypt, decrypt and public keys are disabled", *keyCustody)
+	}
+	log.Printf("keys ready; serving api")
+
+	var grpcOpts []grpc.ServerOption
+	if tlsConfig != nil {
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig.Clone())))
+	}
+	grpcServer, err := srv.GRPC(grpcOpts...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	go func() {
+		lis, err := listen(activated, "grpc", 1, *grpcAddr)
+		if err != nil {
+			log.Fatalf("grpc listen error: %v", err)
+		}
+		log.Printf("tfhe-go gRPC server listening on %s", lis.Addr())
+		if err := grpcServer.Serve(lis); err != nil {
+			log.Fatalf("grpc server error: %v", err)
+		}
+	}()
+
+	var debugServer *http.Server
+	if *debugAddr != "" {
+		listen, err := debugListenAddr(*debugAddr)
+		if err != nil {
+			log.Fatalf("invalid -debug-addr: %v", err)
+		}
+		debugServer = newDebugServer(listen, srv.Recorder())
+		go func() {
+			log.Printf("debug endpoints listening on %s", listen)
+			if err := debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("debug server error: %v", err)
+			}
+		}()
+	}
+
+	var metricsServer *http.Server
+	if h := srv.MetricsHandler(); h != nil {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", h)
+		metricsServer = &http.Server{
+			Addr:              *metricsAddr,
+			Handler:           metricsMux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			log.Printf("metrics listening on %s", *metricsAddr)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				log.Fatalf("metrics server error: %v", err)
+			}
+		}()
+	}
+	if opts.Queue != nil {
+		log.Printf("evaluating requests from %s subject %s", *queueBackend, *queueRequests)
+	}
+
+	reloadCtx, stopReload := context.WithCancel(context.Background())
+	defer stopReload()
+	go reload.watchSIGHUP(reloadCtx)
+
 	// Graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("shutting down...")
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(context.Background(), *shutdownGrace)
 	defer cancel()
-	if err := server.Shutdown(ctx); err != nil {
+	if err := httpServer.Shutdown(ctx); err != nil {
 		log.Printf("graceful shutdown failed: %v", err)
 	}
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+	if metricsServer != nil {
+		_ = metricsServer.Shutdown(ctx)
+	}
+	if debugServer != nil {
+		_ = debugServer.Shutdown(ctx)
+	}
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("shutdown failed: %v", err)
+	}
+}
+
+// applyLimits installs the ciphertext size and native memory limits from the
+// config file.
+func applyLimits(l config.Limits) {
+	if l.MemoryBytes > 0 {
+		tfhe.SetMemoryLimit(l.MemoryBytes)
+	}
+	if len(l.Ciphertext) == 0 {
+		return
+	}
+	limits := tfhe.CurrentLimits()
+	for typ, n := range l.Ciphertext {
+		switch typ {
+		case "boolean":
+			limits.MaxBooleanCiphertext = n
+		case "bool":
+			limits.MaxFheBoolCiphertext = n
+		case "uint2":
+			limits.MaxUint2Ciphertext = n
+		case "uint4":
+			limits.MaxUint4Ciphertext = n
+		case "uint8":
+			limits.MaxUint8Ciphertext = n
+		case "uint16":
+			limits.MaxUint16Ciphertext = n
+		case "uint32":
+			limits.MaxUint32Ciphertext = n
+		case "uint64":
+			limits.MaxUint64Ciphertext = n
+		}
+	}
+	tfhe.SetLimits(limits)
+}
+
+// vaultConfig reads the TFHE_VAULT_* settings.
+func vaultConfig() vault.Config {
+	return vault.Config{
+		KVMount:      os.Getenv("TFHE_VAULT_KV_MOUNT"),
+		Prefix:       os.Getenv("TFHE_VAULT_PREFIX"),
+		TransitMount: os.Getenv("TFHE_VAULT_TRANSIT_MOUNT"),
+		TransitKey:   os.Getenv("TFHE_VAULT_TRANSIT_KEY"),
+		Auth:         os.Getenv("TFHE_VAULT_AUTH"),
+		Role:         os.Getenv("TFHE_VAULT_ROLE"),
+		SecretIDFile: os.Getenv("TFHE_VAULT_SECRET_ID_FILE"),
+		AuthMount:    os.Getenv("TFHE_VAULT_AUTH_MOUNT"),
+	}
+}
+
+// envString returns the environment variable name, or def when it is unset
+// or empty.
+// featureNames lists the route families for the -features usage.
+func featureNames() string {
+	names := make([]string, len(features.All))
+	for i, f := range features.All {
+		names[i] = string(f)
+	}
+	return strings.Join(names, ", ")
+}
+
+func envString(name, def string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return def
+}
+
+// envFloat returns the float value of the environment variable name, or def
+// when it is unset or malformed.
+func envFloat(name string, def float64) float64 {
+	if v, err := strconv.ParseFloat(os.Getenv(name), 64); err == nil {
+		return v
+	}
+	return def
+}
+
+// envDuration returns the duration value (e.g. "30s") of the environment
+// variable name, or def when it is unset or malformed.
+func envDuration(name string, def time.Duration) time.Duration {
+	if v, err := time.ParseDuration(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+// envInt returns the integer value of the environment variable name, or def
+// when it is unset or malformed.
+func envInt(name string, def int) int {
+	if v, err := strconv.Atoi(os.Getenv(name)); err == nil {
+		return v
+	}
+	return def
+}
+
+// splitList splits a comma-separated value, dropping blanks.
+func splitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
 }
@@ -0,0 +1,17 @@
+package main
+
+import (
+	"fmt"
+
+	"tfhe-go/internal/queue"
+)
+
+// openQueue connects the broker selected by backend.
+func openQueue(backend, url, requests, group, results string) (queue.Broker, error) {
+	switch backend {
+	case "nats":
+		return queue.NewNATS(url, requests, group, results)
+	default:
+		return nil, fmt.Errorf("unknown queue %q (want nats)", backend)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"tfhe-go/internal/auth"
+)
+
+// reloader re-reads the settings that can change without a restart: the TLS
+// key pair and the API keys. Each is swapped atomically, so requests in
+// flight finish under the settings they started with, and a source that
+// fails to load keeps its previous value. It also re-wraps persisted keys
+// whose KMS key has changed.
+type reloader struct {
+	cert *certificate // nil without TLS
+
+	apiKeys       *auth.APIKeys // nil when API key authentication is off
+	apiKeysFile   string
+	apiKeysInline string
+
+	rewrap func(context.Context) (int, error) // nil without -kms
+}
+
+// Reload reloads every source, returning the failures joined.
+func (r *reloader) Reload(ctx context.Context) error {
+	var errs []error
+	if r.cert != nil {
+		if err := r.cert.Reload(); err != nil {
+			errs = append(errs, fmt.Errorf("tls certificate: %w", err))
+		} else {
+			log.Printf("reloaded tls certificate %s", r.cert.certFile)
+		}
+	}
+	if r.apiKeys != nil {
+		next, err := auth.LoadAPIKeys(r.apiKeysFile, r.apiKeysInline)
+		switch {
+		case err != nil:
+			errs = append(errs, fmt.Errorf("api keys: %w", err))
+		case next.Len() == 0:
+			// An empty set would lock every caller out; turning
+			// authentication off takes a restart.
+			errs = append(errs, errors.New("api keys: no keys configured"))
+		default:
+			r.apiKeys.Replace(next)
+			log.Printf("reloaded api keys (%d keys)", next.Len())
+		}
+	}
+	if r.rewrap != nil {
+		if n, err := r.rewrap(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("re-wrap keys: %w", err))
+		} else if n > 0 {
+			log.Printf("re-wrapped %d key sets under the current kms key", n)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// watchSIGHUP reloads on every SIGHUP until ctx is done.
+func (r *reloader) watchSIGHUP(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			log.Printf("SIGHUP received; reloading")
+			if err := r.Reload(ctx); err != nil {
+				log.Printf("reload failed: %v", err)
+			}
+		}
+	}
+}
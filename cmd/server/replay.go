@@ -0,0 +1,24 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"time"
+
+	"tfhe-go/internal/replay"
+)
+
+// openReplayGuard returns a guard checking request signatures made with the
+// key read from keyFile.
+func openReplayGuard(keyFile string, window time.Duration) (*replay.Guard, error) {
+	data, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key := bytes.TrimSpace(data)
+	if len(key) < 16 {
+		return nil, fmt.Errorf("%s: request signing key must be at least 16 bytes", keyFile)
+	}
+	return replay.New(replay.Options{Key: key, Window: window})
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"tfhe-go/internal/store"
+)
+
+// openStore returns the ciphertext store selected by backend; the memory
+// backend is bounded by memoryOpts. The s3
+// backend is configured from TFHE_S3_* and takes its credentials from the
+// AWS_* or MINIO_* environment variables or the instance role. The postgres
+// backend keeps handles in db, and their bytes in S3 when TFHE_S3_BUCKET is
+// set.
+func openStore(ctx context.Context, backend string, db *sql.DB, memoryOpts store.MemoryOptions) (store.Store, error) {
+	switch backend {
+	case "", "memory":
+		return store.NewMemory(memoryOpts), nil
+	case "s3":
+		return openS3(ctx)
+	case "postgres":
+		if db == nil {
+			return nil, errors.New("the postgres backend needs -postgres-dsn")
+		}
+		if os.Getenv("TFHE_S3_BUCKET") == "" {
+			return store.NewPostgres(db, nil), nil
+		}
+		blobs, err := openS3(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return store.NewPostgres(db, blobs), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q (want memory, s3 or postgres)", backend)
+	}
+}
+
+// openS3 connects to the bucket configured by TFHE_S3_*.
+func openS3(ctx context.Context) (*store.S3, error) {
+	opts := store.S3Options{
+		Endpoint: os.Getenv("TFHE_S3_ENDPOINT"),
+		Bucket:   os.Getenv("TFHE_S3_BUCKET"),
+		Prefix:   os.Getenv("TFHE_S3_PREFIX"),
+		Region:   os.Getenv("TFHE_S3_REGION"),
+		Insecure: os.Getenv("TFHE_S3_INSECURE") != "",
+		SSE:      os.Getenv("TFHE_S3_SSE"),
+		KMSKeyID: os.Getenv("TFHE_S3_KMS_KEY_ID"),
+		Timeout:  envDuration("TFHE_S3_TIMEOUT", 30*time.Second),
+	}
+	if file := os.Getenv("TFHE_S3_SSE_C_KEY_FILE"); file != "" {
+		key, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if len(key) != 32 {
+			return nil, fmt.Errorf("%s: SSE-C key must be 32 bytes, got %d", file, len(key))
+		}
+		opts.SSECKey = key
+	}
+	return store.NewS3(ctx, opts)
+}
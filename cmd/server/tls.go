@@ -0,0 +1,71 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"sync/atomic"
+)
+
+// tlsOptions selects how the listeners terminate TLS.
+type tlsOptions struct {
+	CertFile string
+	KeyFile  string
+	HTTP2    bool
+}
+
+// Enabled reports whether a certificate was configured.
+func (o tlsOptions) Enabled() bool {
+	return o.CertFile != "" || o.KeyFile != ""
+}
+
+// certificate holds the key pair served to new handshakes; Reload swaps it
+// without affecting established connections.
+type certificate struct {
+	certFile, keyFile string
+	current           atomic.Pointer[tls.Certificate]
+}
+
+// Reload re-reads the key pair, keeping the current one if that fails.
+func (c *certificate) Reload() error {
+	cert, err := tls.LoadX509KeyPair(c.certFile, c.keyFile)
+	if err != nil {
+		return err
+	}
+	c.current.Store(&cert)
+	return nil
+}
+
+func (c *certificate) get(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return c.current.Load(), nil
+}
+
+// Config loads the key pair and returns a server config restricted to TLS
+// 1.2+ with AEAD ECDHE suites; TLS 1.3 suites are not configurable and are
+// always enabled. The returned certificate reloads the key pair in place.
+func (o tlsOptions) Config() (*tls.Config, *certificate, error) {
+	if o.CertFile == "" || o.KeyFile == "" {
+		return nil, nil, errors.New("both a certificate and a key file are required")
+	}
+	cert := &certificate{certFile: o.CertFile, keyFile: o.KeyFile}
+	if err := cert.Reload(); err != nil {
+		return nil, nil, err
+	}
+	protos := []string{"http/1.1"}
+	if o.HTTP2 {
+		protos = []string{"h2", "http/1.1"}
+	}
+	return &tls.Config{
+		GetCertificate:   cert.get,
+		MinVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519, tls.CurveP256},
+		CipherSuites: []uint16{
+			tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256,
+			tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384,
+			tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256,
+			tls.TLS_ECDHE_RSA_WITH_CHACHA20_POLY1305_SHA256,
+		},
+		NextProtos: protos,
+	}, cert, nil
+}
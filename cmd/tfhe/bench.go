@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"tfhe-go/internal/bench"
+)
+
+func benchmark(args []string) error {
+	fs := newFlagSet("bench")
+	params := fs.String("params", strings.Join(bench.ParameterSets, ","), "comma-separated parameter sets to run under")
+	iterations := fs.Int("n", 10, "iterations per case, after one warm-up run")
+	keygenIterations := fs.Int("keygen-n", 1, "iterations per key generation case")
+	filter := fs.String("run", "", "regular expression selecting cases by name, e.g. '^uint8\\.'")
+	format := fs.String("format", "json", "output format: json or csv")
+	out := fs.String("out", "-", "file to write results to; - for stdout")
+	baseline := fs.String("baseline", "", "JSON results of an earlier run; exit with an error if a case got slower")
+	threshold := fs.Float64("threshold", 1.2, "slowdown ratio over -baseline counted as a regression")
+	_ = fs.Parse(args)
+
+	if *format != "json" && *format != "csv" {
+		return fmt.Errorf("unknown format %q (want json or csv)", *format)
+	}
+	opts := bench.Options{
+		ParameterSets:    splitList(*params),
+		Iterations:       *iterations,
+		KeygenIterations: *keygenIterations,
+		Progress: func(r bench.Result) {
+			fmt.Fprintf(os.Stderr, "%s/%s\t%d\t%d ns/op\n", r.ParameterSet, r.Name, r.Iterations, r.MeanNanos)
+		},
+	}
+	if *filter != "" {
+		re, err := regexp.Compile(*filter)
+		if err != nil {
+			return err
+		}
+		opts.Filter = re
+	}
+	var base []bench.Result
+	if *baseline != "" {
+		f, err := os.Open(*baseline)
+		if err != nil {
+			return err
+		}
+		base, err = bench.ReadJSON(f)
+		f.Close()
+		if err != nil {
+			return fmt.Errorf("baseline %s: %w", *baseline, err)
+		}
+	}
+
+	results, err := bench.Run(opts)
+	if err != nil {
+		return err
+	}
+	w := os.Stdout
+	if *out != "-" {
+		if w, err = os.Create(*out); err != nil {
+			return err
+		}
+		defer w.Close()
+	}
+	if *format == "csv" {
+		err = bench.WriteCSV(w, results)
+	} else {
+		err = bench.WriteJSON(w, results)
+	}
+	if err != nil {
+		return err
+	}
+
+	if regressions := bench.Compare(base, results, *threshold); len(regressions) > 0 {
+		for _, r := range regressions {
+			fmt.Fprintf(os.Stderr, "regression: %s\n", r)
+		}
+		return fmt.Errorf("%d case(s) slower than %s by more than %.0f%%", len(regressions), *baseline, (*threshold-1)*100)
+	}
+	return nil
+}
@@ -0,0 +1,469 @@
+// Command tfhe generates keys and encrypts, decrypts, computes on and
+// inspects ciphertexts offline, working on files instead of a server.
+package main
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// Key files written by keygen, relative to its output directory.
+const (
+	booleanClientKeyFile = "boolean_client.key"
+	booleanServerKeyFile = "boolean_server.key"
+	clientKeyFile        = "client.key"
+	serverKeyFile        = "server.key"
+	publicKeyFile        = "public.key"
+)
+
+// Ciphertext types, named as in the HTTP API.
+const (
+	typeBoolean = "boolean"
+	typeBool    = "bool"
+	typeUint8   = "uint8"
+)
+
+type command struct {
+	name, args, help string
+	run              func(args []string) error
+}
+
+var commands = []command{
+	{"keygen", "-out DIR", "generate boolean and integer keys into DIR", keygen},
+	{"switchkey", "-from FILE -to FILE -out FILE", "generate a key re-encrypting boolean ciphertexts from one client key to another (pure-Go build only)", switchkey},
+	{"encrypt", "-key FILE -type TYPE -value V [-out FILE]", "encrypt a plaintext with a client or public key", encrypt},
+	{"decrypt", "-key FILE -type TYPE -in FILE", "decrypt a ciphertext with a client key", decrypt},
+	{"op", "-key FILE -type TYPE -op OP -in A[,B] [-out FILE]", "compute on ciphertexts with a server key", op},
+	{"inspect", "-in FILE", "report the size and type of serialized data", inspect},
+	{"bench", "[-n N] [-run REGEXP] [-format json|csv] [-baseline FILE]", "measure keygen, encryption, operations and serialization", benchmark},
+	{"params", "[-min-security BITS] [-max-latency D] [-max-server-key-mib N]", "profile parameter sets on this machine and recommend one", params},
+	{"audit", "-in FILE [-key-file FILE]", "verify the hash chain of a server audit log", auditVerify},
+	{"sign", "-key-file FILE -uri URI [-method M] [-in FILE]", "print the headers signing a request for a server with -replay-key-file", sign},
+}
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] == "-h" || os.Args[1] == "-help" || os.Args[1] == "help" {
+		usage()
+		os.Exit(2)
+	}
+	for _, c := range commands {
+		if c.name == os.Args[1] {
+			if err := c.run(os.Args[2:]); err != nil {
+				fmt.Fprintf(os.Stderr, "tfhe %s: %v\n", c.name, err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+	fmt.Fprintf(os.Stderr, "tfhe: unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(2)
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: tfhe <command> [flags]")
+	fmt.Fprintln(os.Stderr)
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n            %s\n", c.name, c.args, c.help)
+	}
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "Types: boolean (boolean scheme), bool (integer comparison result), uint2, uint4, uint8, uint16, uint32, uint64.")
+	fmt.Fprintln(os.Stderr, "Run 'tfhe <command> -h' for the flags of a command.")
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("tfhe "+name, flag.ExitOnError)
+}
+
+func keygen(args []string) error {
+	fs := newFlagSet("keygen")
+	out := fs.String("out", ".", "directory to write the key files to")
+	_ = fs.Parse(args)
+
+	if err := os.MkdirAll(*out, 0o700); err != nil {
+		return err
+	}
+	bck, bsk, err := tfhe.GenerateBooleanKeys()
+	if err != nil {
+		return err
+	}
+	defer bck.Close()
+	defer bsk.Close()
+	ck, sk, err := tfhe.GenerateUint8Keys()
+	if err != nil {
+		return err
+	}
+	defer ck.Close()
+	defer sk.Close()
+	pk, err := tfhe.NewUint8PublicKey(ck)
+	if err != nil {
+		return err
+	}
+	defer pk.Close()
+
+	// Client keys decrypt everything, so only the owner may read them.
+	for _, k := range []struct {
+		file      string
+		serialize func() ([]byte, error)
+		perm      os.FileMode
+	}{
+		{booleanClientKeyFile, bck.Serialize, 0o600},
+		{booleanServerKeyFile, bsk.Serialize, 0o644},
+		{clientKeyFile, ck.Serialize, 0o600},
+		{serverKeyFile, sk.Serialize, 0o644},
+		{publicKeyFile, pk.Serialize, 0o644},
+	} {
+		data, err := k.serialize()
+		if err != nil {
+			return fmt.Errorf("%s: %w", k.file, err)
+		}
+		path := filepath.Join(*out, k.file)
+		if err := os.WriteFile(path, data, k.perm); err != nil {
+			return err
+		}
+		fmt.Printf("%s\t%d bytes\n", path, len(data))
+	}
+	return nil
+}
+
+func switchkey(args []string) error {
+	fs := newFlagSet("switchkey")
+	fromPath := fs.String("from", "", "boolean client key ciphertexts are encrypted under, e.g. the server's")
+	toPath := fs.String("to", "", "boolean client key to re-encrypt them under")
+	out := fs.String("out", "", "file to write the switch key to")
+	_ = fs.Parse(args)
+	if *fromPath == "" || *toPath == "" || *out == "" {
+		return errors.New("-from, -to and -out are required")
+	}
+	if !tfhe.KeySwitching {
+		return fmt.Errorf("the %s backend cannot switch keys; build with -tags purego", tfhe.Backend)
+	}
+	var cks [2]*tfhe.ClientKey
+	for i, path := range []string{*fromPath, *toPath} {
+		data, err := readInput(path)
+		if err != nil {
+			return err
+		}
+		if cks[i], err = tfhe.DeserializeClientKey(data); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		defer cks[i].Close()
+	}
+	k, err := tfhe.GenerateSwitchKey(cks[0], cks[1])
+	if err != nil {
+		return err
+	}
+	defer k.Close()
+	data, err := k.Serialize()
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Printf("%s\t%d bytes\n", *out, len(data))
+	return nil
+}
+
+func encrypt(args []string) error {
+	fs := newFlagSet("encrypt")
+	keyPath := fs.String("key", "", "client key, or for integer types the public key, to encrypt with")
+	typ := fs.String("type", typeUint8, "ciphertext type")
+	value := fs.String("value", "", "plaintext: true/false for boolean and bool, a number otherwise")
+	out := fs.String("out", "-", "file to write the ciphertext to; - for stdout")
+	b64 := fs.Bool("base64", false, "write base64, as the HTTP API expects, instead of raw bytes")
+	_ = fs.Parse(args)
+	if *keyPath == "" || *value == "" {
+		return errors.New("-key and -value are required")
+	}
+	keyData, err := readInput(*keyPath)
+	if err != nil {
+		return err
+	}
+
+	if *typ == typeBoolean {
+		v, err := strconv.ParseBool(*value)
+		if err != nil {
+			return err
+		}
+		ck, err := tfhe.DeserializeClientKey(keyData)
+		if err != nil {
+			return err
+		}
+		defer ck.Close()
+		ct, err := tfhe.EncryptBool(ck, v)
+		if err != nil {
+			return err
+		}
+		defer ct.Close()
+		return writeCiphertext(*out, ct, *b64)
+	}
+
+	ck, pk, err := loadEncryptionKey(keyData)
+	if err != nil {
+		return err
+	}
+	defer ck.Close()
+	defer pk.Close()
+	var ct serializer
+	if *typ == typeBool {
+		v, err := strconv.ParseBool(*value)
+		if err != nil {
+			return err
+		}
+		if pk != nil {
+			ct, err = tfhe.EncryptFheBoolPublic(pk, v)
+		} else {
+			ct, err = tfhe.EncryptFheBool(ck, v)
+		}
+		if err != nil {
+			return err
+		}
+	} else {
+		bits, err := intBits(*typ)
+		if err != nil {
+			return err
+		}
+		v, err := strconv.ParseUint(*value, 10, bits)
+		if err != nil {
+			return err
+		}
+		if ct, err = encryptInt(ck, pk, bits, v); err != nil {
+			return err
+		}
+	}
+	defer ct.Close()
+	return writeCiphertext(*out, ct, *b64)
+}
+
+// loadEncryptionKey loads an integer client key or, failing that, a public
+// key; exactly one of the results is non-nil on success.
+func loadEncryptionKey(data []byte) (*tfhe.Uint8ClientKey, *tfhe.Uint8PublicKey, error) {
+	ck, err := tfhe.DeserializeUint8ClientKey(data)
+	if err == nil {
+		return ck, nil, nil
+	}
+	if pk, perr := tfhe.DeserializeUint8PublicKey(data); perr == nil {
+		return nil, pk, nil
+	}
+	return nil, nil, fmt.Errorf("not an integer client or public key: %w", err)
+}
+
+func decrypt(args []string) error {
+	fs := newFlagSet("decrypt")
+	keyPath := fs.String("key", "", "client key to decrypt with")
+	typ := fs.String("type", typeUint8, "ciphertext type")
+	in := fs.String("in", "-", "ciphertext file, raw or base64; - for stdin")
+	_ = fs.Parse(args)
+	if *keyPath == "" {
+		return errors.New("-key is required")
+	}
+	keyData, err := readInput(*keyPath)
+	if err != nil {
+		return err
+	}
+	data, err := readInput(*in)
+	if err != nil {
+		return err
+	}
+
+	if *typ == typeBoolean {
+		ck, err := tfhe.DeserializeClientKey(keyData)
+		if err != nil {
+			return err
+		}
+		defer ck.Close()
+		ct, err := tfhe.DeserializeCiphertext(data)
+		if err != nil {
+			return err
+		}
+		defer ct.Close()
+		v, err := tfhe.DecryptBool(ck, ct)
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	}
+
+	ck, err := tfhe.DeserializeUint8ClientKey(keyData)
+	if err != nil {
+		return err
+	}
+	defer ck.Close()
+	if *typ == typeBool {
+		ct, err := tfhe.DeserializeFheBool(data)
+		if err != nil {
+			return err
+		}
+		defer ct.Close()
+		v, err := tfhe.DecryptFheBool(ck, ct)
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	}
+	bits, err := intBits(*typ)
+	if err != nil {
+		return err
+	}
+	if bits == 8 {
+		ct, err := tfhe.Uint8Deserialize(data)
+		if err != nil {
+			return err
+		}
+		defer ct.Close()
+		v, err := tfhe.DecryptUint8(ck, ct)
+		if err != nil {
+			return err
+		}
+		fmt.Println(v)
+		return nil
+	}
+	ct, err := tfhe.DeserializeInt(bits, data)
+	if err != nil {
+		return err
+	}
+	defer ct.Close()
+	v, err := tfhe.DecryptInt(ck, ct)
+	if err != nil {
+		return err
+	}
+	fmt.Println(v)
+	return nil
+}
+
+// serializer is the part of every ciphertext type the commands need.
+type serializer interface {
+	io.WriterTo
+	Close() error
+}
+
+// encryptInt encrypts v with ck, or with pk when ck is nil.
+func encryptInt(ck *tfhe.Uint8ClientKey, pk *tfhe.Uint8PublicKey, bits int, v uint64) (serializer, error) {
+	switch {
+	case bits == 8 && ck != nil:
+		ct, err := tfhe.EncryptUint8(ck, uint8(v))
+		if err != nil {
+			return nil, err
+		}
+		return ct, nil
+	case bits == 8:
+		ct, err := tfhe.EncryptUint8Public(pk, uint8(v))
+		if err != nil {
+			return nil, err
+		}
+		return ct, nil
+	case ck != nil:
+		return tfhe.EncryptInt(ck, bits, v)
+	}
+	return tfhe.EncryptIntPublic(pk, bits, v)
+}
+
+// intBits parses an integer type name such as "uint16".
+func intBits(typ string) (int, error) {
+	switch typ {
+	case "uint2":
+		return 2, nil
+	case "uint4":
+		return 4, nil
+	case typeUint8:
+		return 8, nil
+	case "uint16":
+		return 16, nil
+	case "uint32":
+		return 32, nil
+	case "uint64":
+		return 64, nil
+	}
+	return 0, fmt.Errorf("unknown type %q (want %s, %s, uint2, uint4, uint8, uint16, uint32 or uint64)", typ, typeBoolean, typeBool)
+}
+
+// readInput reads a file, or stdin for "-". Base64 text, as the HTTP API
+// uses, is decoded so either form can be passed around.
+func readInput(path string) ([]byte, error) {
+	data, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if decoded, ok := decodeBase64(data); ok {
+		return decoded, nil
+	}
+	return data, nil
+}
+
+// readFile reads a file as is, or stdin for "-".
+func readFile(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// decodeBase64 reports whether data is entirely standard base64 text and
+// returns it decoded.
+func decodeBase64(data []byte) ([]byte, bool) {
+	text := bytes.TrimSpace(data)
+	if len(text) == 0 || len(text)%4 != 0 {
+		return nil, false
+	}
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(text)))
+	n, err := base64.StdEncoding.Decode(decoded, text)
+	if err != nil {
+		return nil, false
+	}
+	return decoded[:n], true
+}
+
+func writeCiphertext(path string, ct serializer, b64 bool) (err error) {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				_ = os.Remove(path)
+			}
+		}()
+		w = f
+	}
+	if !b64 {
+		_, err = ct.WriteTo(w)
+		return err
+	}
+	enc := base64.NewEncoder(base64.StdEncoding, w)
+	if _, err := ct.WriteTo(enc); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"tfhe-go/internal/tfhe"
+)
+
+func op(args []string) error {
+	fs := newFlagSet("op")
+	keyPath := fs.String("key", "", "server key: "+booleanServerKeyFile+" for boolean, "+serverKeyFile+" otherwise")
+	typ := fs.String("type", typeUint8, "operand type: boolean, uint2, uint4, uint8, uint16, uint32 or uint64")
+	name := fs.String("op", "", "boolean: and, or, xor, not; integers: add, bitand, bitxor, "+comparisonNames()+"; uint8 also: shl, shr, rotate_left, rotate_right")
+	in := fs.String("in", "", "comma-separated operand ciphertext files")
+	out := fs.String("out", "-", "file to write the result to; - for stdout")
+	b64 := fs.Bool("base64", false, "write base64 instead of raw bytes")
+	_ = fs.Parse(args)
+	if *keyPath == "" || *name == "" || *in == "" {
+		return errors.New("-key, -op and -in are required")
+	}
+	keyData, err := readInput(*keyPath)
+	if err != nil {
+		return err
+	}
+	operands := splitList(*in)
+	want := 2
+	if *name == "not" {
+		want = 1
+	}
+	if len(operands) != want {
+		return fmt.Errorf("%s takes %d operands, got %d", *name, want, len(operands))
+	}
+	inputs := make([][]byte, len(operands))
+	for i, path := range operands {
+		if inputs[i], err = readInput(path); err != nil {
+			return err
+		}
+	}
+
+	var result serializer
+	if *typ == typeBoolean {
+		result, err = booleanOp(keyData, *name, inputs)
+	} else {
+		result, err = intOp(keyData, *typ, *name, inputs)
+	}
+	if err != nil {
+		return err
+	}
+	defer result.Close()
+	return writeCiphertext(*out, result, *b64)
+}
+
+func booleanOp(keyData []byte, name string, inputs [][]byte) (serializer, error) {
+	sk, err := tfhe.DeserializeServerKey(keyData)
+	if err != nil {
+		return nil, err
+	}
+	defer sk.Close()
+	cts := make([]*tfhe.Ciphertext, len(inputs))
+	for i, data := range inputs {
+		if cts[i], err = tfhe.DeserializeCiphertext(data); err != nil {
+			return nil, fmt.Errorf("operand %d: %w", i+1, err)
+		}
+		defer cts[i].Close()
+	}
+	switch name {
+	case "and":
+		return sk.And(cts[0], cts[1])
+	case "or":
+		return sk.Or(cts[0], cts[1])
+	case "xor":
+		return sk.Xor(cts[0], cts[1])
+	case "not":
+		return sk.Not(cts[0])
+	}
+	return nil, fmt.Errorf("unknown boolean op %q (want and, or, xor or not)", name)
+}
+
+func intOp(keyData []byte, typ, name string, inputs [][]byte) (serializer, error) {
+	bits, err := intBits(typ)
+	if err != nil {
+		return nil, err
+	}
+	sk, err := tfhe.DeserializeUint8ServerKey(keyData)
+	if err != nil {
+		return nil, err
+	}
+	defer sk.Close()
+	if len(inputs) != 2 {
+		return nil, fmt.Errorf("%s takes 2 operands", name)
+	}
+
+	if bits == 8 {
+		a, err := tfhe.Uint8Deserialize(inputs[0])
+		if err != nil {
+			return nil, fmt.Errorf("operand 1: %w", err)
+		}
+		defer a.Close()
+		b, err := tfhe.Uint8Deserialize(inputs[1])
+		if err != nil {
+			return nil, fmt.Errorf("operand 2: %w", err)
+		}
+		defer b.Close()
+		var ct *tfhe.Uint8Ciphertext
+		switch name {
+		case "add":
+			ct, err = sk.Add(a, b)
+		case "bitand":
+			ct, err = sk.BitAnd(a, b)
+		case "bitxor":
+			ct, err = sk.BitXor(a, b)
+		case "shl", "shr", "rotate_left", "rotate_right":
+			ct, err = sk.Shift(tfhe.Shift(name), a, b)
+		default:
+			return compare(name, func(cmp tfhe.Comparison) (*tfhe.FheBool, error) { return sk.Compare(cmp, a, b) })
+		}
+		if err != nil {
+			return nil, err
+		}
+		return ct, nil
+	}
+
+	a, err := tfhe.DeserializeInt(bits, inputs[0])
+	if err != nil {
+		return nil, fmt.Errorf("operand 1: %w", err)
+	}
+	defer a.Close()
+	b, err := tfhe.DeserializeInt(bits, inputs[1])
+	if err != nil {
+		return nil, fmt.Errorf("operand 2: %w", err)
+	}
+	defer b.Close()
+	switch name {
+	case "add":
+		return sk.IntAdd(a, b)
+	case "bitand":
+		return sk.IntBitAnd(a, b)
+	case "bitxor":
+		return sk.IntBitXor(a, b)
+	}
+	return compare(name, func(cmp tfhe.Comparison) (*tfhe.FheBool, error) { return sk.IntCompare(cmp, a, b) })
+}
+
+// compare runs a comparison op, whose result is a bool ciphertext.
+func compare(name string, fn func(tfhe.Comparison) (*tfhe.FheBool, error)) (serializer, error) {
+	for _, cmp := range tfhe.Comparisons {
+		if string(cmp) == name {
+			return fn(cmp)
+		}
+	}
+	return nil, fmt.Errorf("unknown integer op %q (want add, bitand, bitxor, %s)", name, comparisonNames())
+}
+
+func comparisonNames() string {
+	names := make([]string, len(tfhe.Comparisons))
+	for i, cmp := range tfhe.Comparisons {
+		names[i] = string(cmp)
+	}
+	return strings.Join(names, ", ")
+}
+
+func inspect(args []string) error {
+	fs := newFlagSet("inspect")
+	in := fs.String("in", "-", "file to inspect; - for stdin")
+	_ = fs.Parse(args)
+
+	raw, err := readFile(*in)
+	if err != nil {
+		return err
+	}
+	data := raw
+	encoding := "raw"
+	if decoded, ok := decodeBase64(raw); ok {
+		data, encoding = decoded, "base64"
+	}
+	fmt.Printf("encoding:\t%s\n", encoding)
+	fmt.Printf("size:\t%d bytes\n", len(data))
+
+	// The serialized formats carry no type tag, so the first deserializer
+	// that accepts data names it; this is a best guess. Ciphertexts are tried
+	// first since they are cheap to load.
+	for _, p := range probes {
+		if p.limit != nil {
+			if err := tfhe.CheckSerialized(data, p.limit(tfhe.CurrentLimits())); err != nil {
+				continue
+			}
+		}
+		obj, err := p.load(data)
+		if err != nil {
+			continue
+		}
+		_ = obj.Close()
+		fmt.Printf("type:\t%s\n", p.name)
+		return nil
+	}
+	fmt.Printf("type:\tunknown\n")
+	if err := tfhe.CheckSerialized(data, len(data)); err != nil {
+		fmt.Printf("error:\t%v\n", err)
+	}
+	return nil
+}
+
+type closer interface{ Close() error }
+
+// probes lists the formats inspect recognises, in the order it tries them.
+var probes = []struct {
+	name  string
+	limit func(tfhe.Limits) int // ciphertext size limit, nil for keys
+	load  func([]byte) (closer, error)
+}{
+	{"boolean ciphertext", func(l tfhe.Limits) int { return l.MaxBooleanCiphertext },
+		func(d []byte) (closer, error) { return tfhe.DeserializeCiphertext(d) }},
+	{"bool ciphertext", func(l tfhe.Limits) int { return l.MaxFheBoolCiphertext },
+		func(d []byte) (closer, error) { return tfhe.DeserializeFheBool(d) }},
+	{"uint8 ciphertext", func(l tfhe.Limits) int { return l.MaxUint8Ciphertext },
+		func(d []byte) (closer, error) { return tfhe.Uint8Deserialize(d) }},
+	{"uint16 ciphertext", func(l tfhe.Limits) int { return l.MaxUint16Ciphertext },
+		func(d []byte) (closer, error) { return tfhe.DeserializeInt(16, d) }},
+	{"uint32 ciphertext", func(l tfhe.Limits) int { return l.MaxUint32Ciphertext },
+		func(d []byte) (closer, error) { return tfhe.DeserializeInt(32, d) }},
+	{"uint64 ciphertext", func(l tfhe.Limits) int { return l.MaxUint64Ciphertext },
+		func(d []byte) (closer, error) { return tfhe.DeserializeInt(64, d) }},
+	{"uint2 ciphertext", func(l tfhe.Limits) int { return l.MaxUint2Ciphertext },
+		func(d []byte) (closer, error) { return tfhe.DeserializeInt(2, d) }},
+	{"uint4 ciphertext", func(l tfhe.Limits) int { return l.MaxUint4Ciphertext },
+		func(d []byte) (closer, error) { return tfhe.DeserializeInt(4, d) }},
+	{"boolean client key", nil, func(d []byte) (closer, error) { return tfhe.DeserializeClientKey(d) }},
+	{"boolean server key", nil, func(d []byte) (closer, error) { return tfhe.DeserializeServerKey(d) }},
+	{"integer client key", nil, func(d []byte) (closer, error) { return tfhe.DeserializeUint8ClientKey(d) }},
+	{"integer public key", nil, func(d []byte) (closer, error) { return tfhe.DeserializeUint8PublicKey(d) }},
+	{"integer server key", nil, func(d []byte) (closer, error) { return tfhe.DeserializeUint8ServerKey(d) }},
+}
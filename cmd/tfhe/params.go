@@ -0,0 +1,66 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"tfhe-go/internal/bench"
+	"tfhe-go/internal/paramsets"
+)
+
+func params(args []string) error {
+	fs := newFlagSet("params")
+	sets := fs.String("params", strings.Join(bench.ParameterSets, ","), "comma-separated parameter sets to profile")
+	iterations := fs.Int("n", 10, "iterations per operation, after one warm-up run")
+	minSecurity := fs.Int("min-security", 128, "minimum security level in bits; 0 accepts any")
+	maxLatency := fs.Duration("max-latency", 0, "maximum mean latency of one operation of the mix; 0 for no limit")
+	maxServerKey := fs.Int("max-server-key-mib", 0, "maximum size of the server keys in MiB; 0 for no limit")
+	format := fs.String("format", "text", "output format: text or json")
+	_ = fs.Parse(args)
+
+	if *format != "text" && *format != "json" {
+		return fmt.Errorf("unknown format %q (want text or json)", *format)
+	}
+	profiles, err := paramsets.Run(paramsets.Options{
+		ParameterSets: splitList(*sets),
+		Iterations:    *iterations,
+		Progress: func(params string, r bench.Result) {
+			fmt.Fprintf(os.Stderr, "%s/%s\t%d ns/op\n", params, r.Name, r.MeanNanos)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	best, rejected, recErr := paramsets.Recommend(profiles, paramsets.Requirements{
+		MinSecurityBits:   *minSecurity,
+		MaxMixLatency:     *maxLatency,
+		MaxServerKeyBytes: *maxServerKey << 20,
+	})
+
+	if *format == "json" {
+		out := struct {
+			Profiles    []paramsets.Profile `json:"profiles"`
+			Recommended string              `json:"recommended,omitempty"`
+			Rejected    []string            `json:"rejected,omitempty"`
+		}{profiles, best.ParameterSet, rejected}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+		return recErr
+	}
+	if err := paramsets.WriteTable(os.Stdout, profiles); err != nil {
+		return err
+	}
+	fmt.Println()
+	if recErr == nil {
+		fmt.Printf("recommended: %s\n", best.ParameterSet)
+	}
+	for _, r := range rejected {
+		fmt.Printf("not chosen: %s\n", r)
+	}
+	return recErr
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"tfhe-go/internal/replay"
+)
+
+func sign(args []string) error {
+	fs := newFlagSet("sign")
+	keyFile := fs.String("key-file", "", "request signing key the server's -replay-key-file names")
+	method := fs.String("method", http.MethodPost, "request method")
+	uri := fs.String("uri", "", "request path and query, e.g. /v1/uint8/add")
+	in := fs.String("in", "", "request body file; - for stdin; empty for no body")
+	_ = fs.Parse(args)
+
+	if *keyFile == "" || *uri == "" {
+		return errors.New("-key-file and -uri are required")
+	}
+	key, err := os.ReadFile(*keyFile)
+	if err != nil {
+		return err
+	}
+	var body []byte
+	if *in != "" {
+		if body, err = readFile(*in); err != nil {
+			return err
+		}
+	}
+	r, err := http.NewRequest(*method, *uri, nil)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	replay.Sign(r, bytes.TrimSpace(key), body, hex.EncodeToString(nonce), time.Now())
+	for _, name := range []string{replay.TimestampHeader, replay.NonceHeader, replay.DigestHeader, replay.SignatureHeader} {
+		fmt.Printf("%s: %s\n", name, r.Header.Get(name))
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+//go:build js && wasm
+
+// Command wasm exposes the client package to JavaScript as the global
+// tfhe object, so web apps encrypt and decrypt in the browser and send the
+// server only ciphertexts. Keys and ciphertexts are base64 strings, as in
+// the HTTP API, and every function returns a Promise:
+//
+//	const { clientKey, serverKey } = await tfhe.generateKeys();
+//	const ct = await tfhe.encrypt(clientKey, true);
+//	const value = await tfhe.decrypt(clientKey, ct);
+package main
+
+import (
+	"encoding/base64"
+	"errors"
+	"syscall/js"
+
+	"tfhe-go/client"
+)
+
+func main() {
+	js.Global().Set("tfhe", js.ValueOf(map[string]any{
+		"generateKeys": js.FuncOf(generateKeys),
+		"encrypt":      js.FuncOf(encrypt),
+		"decrypt":      js.FuncOf(decrypt),
+	}))
+	select {}
+}
+
+func generateKeys(this js.Value, args []js.Value) any {
+	return promise(func() (any, error) {
+		key, server, err := client.GenerateKeys()
+		if err != nil {
+			return nil, err
+		}
+		data, err := key.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"clientKey": encode(data), "serverKey": encode(server)}, nil
+	})
+}
+
+func encrypt(this js.Value, args []js.Value) any {
+	return promise(func() (any, error) {
+		if len(args) != 2 || args[1].Type() != js.TypeBoolean {
+			return nil, errors.New("encrypt(clientKey, value): value must be a boolean")
+		}
+		key, err := clientKey(args[0])
+		if err != nil {
+			return nil, err
+		}
+		ct, err := key.Encrypt(args[1].Bool())
+		if err != nil {
+			return nil, err
+		}
+		return encode(ct), nil
+	})
+}
+
+func decrypt(this js.Value, args []js.Value) any {
+	return promise(func() (any, error) {
+		if len(args) != 2 || args[1].Type() != js.TypeString {
+			return nil, errors.New("decrypt(clientKey, ciphertext): ciphertext must be a base64 string")
+		}
+		key, err := clientKey(args[0])
+		if err != nil {
+			return nil, err
+		}
+		ct, err := base64.StdEncoding.DecodeString(args[1].String())
+		if err != nil {
+			return nil, err
+		}
+		return key.Decrypt(ct)
+	})
+}
+
+func clientKey(v js.Value) (*client.ClientKey, error) {
+	if v.Type() != js.TypeString {
+		return nil, errors.New("client key must be a base64 string")
+	}
+	data, err := base64.StdEncoding.DecodeString(v.String())
+	if err != nil {
+		return nil, err
+	}
+	return client.ParseClientKey(data)
+}
+
+func encode(data []byte) string { return base64.StdEncoding.EncodeToString(data) }
+
+// promise runs fn off the JavaScript event loop, since key generation takes
+// seconds, and settles the returned Promise with its result.
+func promise(fn func() (any, error)) js.Value {
+	executor := js.FuncOf(func(this js.Value, args []js.Value) any {
+		resolve, reject := args[0], args[1]
+		go func() {
+			v, err := fn()
+			if err != nil {
+				reject.Invoke(js.Global().Get("Error").New(err.Error()))
+				return
+			}
+			resolve.Invoke(v)
+		}()
+		return nil
+	})
+	// The Promise constructor calls the executor before returning.
+	defer executor.Release()
+	return js.Global().Get("Promise").New(executor)
+}
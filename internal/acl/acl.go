@@ -0,0 +1,125 @@
+// Package acl keeps access-control lists for stored ciphertext handles. A
+// handle's owning tenant may always use it; the list grants other
+// principals, identified by their authenticated identity ID, the right to
+// use the handle as an operand, to re-encrypt it or to decrypt it, in the
+// manner of fhEVM's handle ACLs.
+package acl
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Permission is one right over a handle.
+type Permission string
+
+const (
+	// Use allows reading the handle and passing it as an operand.
+	Use Permission = "use"
+	// Reencrypt allows re-encrypting the handle to the caller's own key.
+	Reencrypt Permission = "reencrypt"
+	// Decrypt allows asking the server to decrypt the handle.
+	Decrypt Permission = "decrypt"
+)
+
+var (
+	// ErrDenied is returned when a principal with some rights over a handle
+	// asks for one it was not granted.
+	ErrDenied = errors.New("permission denied")
+	// ErrInvalidGrant is returned for an empty principal or an unknown
+	// permission.
+	ErrInvalidGrant = errors.New("invalid grant")
+)
+
+// Valid reports whether p is a known permission.
+func (p Permission) Valid() bool {
+	return p == Use || p == Reencrypt || p == Decrypt
+}
+
+// Grant is the permissions of one principal over a handle.
+type Grant struct {
+	Principal   string       `json:"principal"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// Registry holds the grants of every handle in memory.
+type Registry struct {
+	mu     sync.RWMutex
+	grants map[string]map[string][]Permission
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{grants: make(map[string]map[string][]Permission)}
+}
+
+// Set replaces principal's permissions over handle with perms; no perms
+// revokes them all.
+func (r *Registry) Set(handle, principal string, perms []Permission) error {
+	if principal == "" {
+		return fmt.Errorf("%w: principal is required", ErrInvalidGrant)
+	}
+	var set []Permission
+	for _, p := range perms {
+		if !p.Valid() {
+			return fmt.Errorf("%w: unknown permission %q; want use, reencrypt or decrypt", ErrInvalidGrant, p)
+		}
+		if !slices.Contains(set, p) {
+			set = append(set, p)
+		}
+	}
+	slices.Sort(set)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(set) == 0 {
+		delete(r.grants[handle], principal)
+		if len(r.grants[handle]) == 0 {
+			delete(r.grants, handle)
+		}
+		return nil
+	}
+	if r.grants[handle] == nil {
+		r.grants[handle] = make(map[string][]Permission)
+	}
+	r.grants[handle][principal] = set
+	return nil
+}
+
+// Check reports whether principal holds any permission over handle and, if
+// it does, fails with ErrDenied unless perm is among them. Callers can treat
+// a handle the principal holds nothing over as missing.
+func (r *Registry) Check(handle, principal string, perm Permission) (known bool, err error) {
+	r.mu.RLock()
+	perms, ok := r.grants[handle][principal]
+	r.mu.RUnlock()
+	if !ok || principal == "" {
+		return false, nil
+	}
+	if !slices.Contains(perms, perm) {
+		return true, fmt.Errorf("%w: %s may not %s handle %s", ErrDenied, principal, perm, handle)
+	}
+	return true, nil
+}
+
+// Grants returns handle's grants ordered by principal.
+func (r *Registry) Grants(handle string) []Grant {
+	r.mu.RLock()
+	out := make([]Grant, 0, len(r.grants[handle]))
+	for principal, perms := range r.grants[handle] {
+		out = append(out, Grant{Principal: principal, Permissions: slices.Clone(perms)})
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Principal < out[j].Principal })
+	return out
+}
+
+// Forget drops every grant over handle, as when the handle is deleted.
+func (r *Registry) Forget(handle string) {
+	r.mu.Lock()
+	delete(r.grants, handle)
+	r.mu.Unlock()
+}
@@ -0,0 +1,134 @@
+// Package apierror writes the JSON error responses of the HTTP API and
+// tags each request with an ID that error responses carry.
+package apierror
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+)
+
+// RequestIDHeader carries the request ID: the client's, when it sends an
+// acceptable one, or one the server generates. Responses echo it.
+const RequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLen bounds the IDs accepted from clients.
+const maxRequestIDLen = 128
+
+// Machine-readable codes shared by the middleware packages; package httpapi
+// maps its typed errors to more specific ones.
+const (
+	CodeBadRequest      = "bad_request"
+	CodeUnauthenticated = "unauthenticated"
+	CodeForbidden       = "forbidden"
+	CodeNotFound        = "not_found"
+	CodeConflict        = "conflict"
+	CodeTooLarge        = "too_large"
+	CodeRateLimited     = "rate_limited"
+	CodeQuotaExceeded   = "quota_exceeded"
+	CodeInternal        = "internal"
+	CodeNotImplemented  = "not_implemented"
+	CodeUnavailable     = "unavailable"
+)
+
+// Detail points at the part of a request that failed validation.
+type Detail struct {
+	// Field is the JSON path of the offending field, e.g. "left" or
+	// "inputs.a"; empty when the problem is not tied to one field.
+	Field  string `json:"field,omitempty"`
+	Reason string `json:"reason"`
+}
+
+// Body is the JSON of every error response.
+type Body struct {
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	Details   []Detail `json:"details,omitempty"`
+	RequestID string   `json:"request_id,omitempty"`
+	// Error repeats Message for clients written against the earlier
+	// {"error": "..."} responses.
+	Error string `json:"error"`
+}
+
+// Write writes an error response with status, code and message, tagged
+// with the request ID Middleware put in the response headers.
+func Write(w http.ResponseWriter, status int, code, message string, details ...Detail) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Body{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: w.Header().Get(RequestIDHeader),
+		Error:     message,
+	})
+}
+
+// CodeFor returns the generic code of status, for errors without a more
+// specific one.
+func CodeFor(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return CodeUnauthenticated
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusRequestEntityTooLarge:
+		return CodeTooLarge
+	case http.StatusTooManyRequests:
+		return CodeRateLimited
+	case http.StatusNotImplemented:
+		return CodeNotImplemented
+	case http.StatusServiceUnavailable:
+		return CodeUnavailable
+	}
+	if status >= 500 {
+		return CodeInternal
+	}
+	return CodeBadRequest
+}
+
+type requestIDKey struct{}
+
+// Middleware gives every request an ID, taken from its X-Request-Id header
+// when that is at most 128 printable ASCII characters and generated
+// otherwise, and sets it on the response before next runs.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// RequestID returns the ID Middleware gave the request of ctx, or "".
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
@@ -0,0 +1,208 @@
+// Package audit records key lifecycle events, decryptions and compute calls
+// to an append-only sink as a hash chain, so that removing, reordering or
+// editing a record breaks every hash after it.
+package audit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Action classifies an audited event.
+type Action string
+
+const (
+	// KeyGenerate, KeyImport, KeyRotate and KeyRevoke cover the key
+	// lifecycle: sets generated or loaded from a key store at startup,
+	// switch keys uploaded by tenants, rotations and revocations.
+	KeyGenerate Action = "key.generate"
+	KeyImport   Action = "key.import"
+	KeyRotate   Action = "key.rotate"
+	KeyRevoke   Action = "key.revoke"
+	// Decrypt covers every request that reveals a plaintext, or re-encrypts
+	// one for a recipient.
+	Decrypt Action = "decrypt"
+	// Compute covers homomorphic operations and encryption.
+	Compute Action = "compute"
+	// Read covers requests that return stored data without computing.
+	Read Action = "read"
+	// Admin covers the other administrative requests.
+	Admin Action = "admin"
+)
+
+// Outcomes of an audited event.
+const (
+	Success = "success"
+	Denied  = "denied"
+	Failure = "failure"
+)
+
+// maxError bounds the error text kept in a record.
+const maxError = 1024
+
+// ErrTampered reports a record that does not follow from the one before.
+var ErrTampered = errors.New("audit chain broken")
+
+// Event is one audit record. Seq, Time, Prev and Hash are filled in by the
+// Logger.
+type Event struct {
+	Seq    uint64    `json:"seq"`
+	Time   time.Time `json:"time"`
+	Action Action    `json:"action"`
+	// Op names what was done, e.g. "POST /v1/uint8/add" or a gRPC method.
+	Op string `json:"op"`
+	// Actor is the caller's identity ID and Tenant its tenant; both are
+	// empty for unauthenticated callers and for the server itself.
+	Actor  string `json:"actor,omitempty"`
+	Tenant string `json:"tenant,omitempty"`
+	Remote string `json:"remote,omitempty"`
+	// KeyID is the key set used.
+	KeyID         string `json:"key_id,omitempty"`
+	RequestBytes  int64  `json:"request_bytes,omitempty"`
+	ResponseBytes int64  `json:"response_bytes,omitempty"`
+	// Status is the HTTP status or gRPC code.
+	Status        int    `json:"status,omitempty"`
+	Outcome       string `json:"outcome"`
+	Error         string `json:"error,omitempty"`
+	DurationNanos int64  `json:"duration_ns,omitempty"`
+	// Prev is the hash of the previous record, empty for the first.
+	Prev string `json:"prev"`
+	Hash string `json:"hash"`
+}
+
+// Sink stores audit records, one JSON object per call, in order.
+// Implementations must not drop or reorder records; Write is called by one
+// goroutine at a time.
+type Sink interface {
+	Write(record []byte) error
+	Close() error
+}
+
+// Resumer is implemented by sinks that can return their last record, so a
+// restarted Logger continues the chain instead of starting a new one.
+type Resumer interface {
+	Last() (Event, bool, error)
+}
+
+// Logger appends events to a sink, chaining each record to the one before.
+type Logger struct {
+	sink Sink
+	key  []byte
+
+	mu      sync.Mutex
+	seq     uint64
+	prev    string
+	errors  uint64
+	lastErr error
+}
+
+// New returns a Logger writing to sink. With a key, records are chained with
+// HMAC-SHA-256, so only holders of the key can produce a chain that
+// verifies; without one, with plain SHA-256, which exposes edits but not a
+// rewrite of the whole log.
+func New(sink Sink, key []byte) (*Logger, error) {
+	l := &Logger{sink: sink, key: key}
+	if r, ok := sink.(Resumer); ok {
+		last, ok, err := r.Last()
+		if err != nil {
+			return nil, fmt.Errorf("audit: resume chain: %w", err)
+		}
+		if ok {
+			l.seq, l.prev = last.Seq, last.Hash
+		}
+	}
+	return l, nil
+}
+
+// Record appends e. The error is also kept for Err, as a failing sink must
+// not fail the request it audits. A nil Logger records nothing.
+func (l *Logger) Record(e Event) error {
+	if l == nil {
+		return nil
+	}
+	// Invalid UTF-8 would not survive a JSON round trip, and with it the
+	// hash would not verify.
+	for _, f := range []*string{&e.Op, &e.Actor, &e.Tenant, &e.Remote, &e.KeyID, &e.Outcome, &e.Error} {
+		*f = strings.ToValidUTF8(*f, "\uFFFD")
+	}
+	if len(e.Error) > maxError {
+		e.Error = strings.ToValidUTF8(e.Error[:maxError], "")
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.Seq, e.Time, e.Prev, e.Hash = l.seq+1, time.Now().UTC(), l.prev, ""
+	sum, err := digest(l.key, e)
+	if err == nil {
+		e.Hash = sum
+		var record []byte
+		if record, err = json.Marshal(e); err == nil {
+			err = l.sink.Write(record)
+		}
+	}
+	if err != nil {
+		l.errors++
+		l.lastErr = err
+		return fmt.Errorf("audit: %w", err)
+	}
+	l.seq, l.prev, l.lastErr = e.Seq, e.Hash, nil
+	return nil
+}
+
+// Head is the position of the chain: anchoring it elsewhere from time to time
+// also exposes records cut from the end of the log.
+type Head struct {
+	Seq       uint64 `json:"seq"`
+	Hash      string `json:"hash"`
+	Keyed     bool   `json:"keyed"`
+	Errors    uint64 `json:"errors"`
+	LastError string `json:"last_error,omitempty"`
+}
+
+// Head returns the last record written and the sink's error count.
+func (l *Logger) Head() Head {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	h := Head{Seq: l.seq, Hash: l.prev, Keyed: len(l.key) > 0, Errors: l.errors}
+	if l.lastErr != nil {
+		h.LastError = l.lastErr.Error()
+	}
+	return h
+}
+
+// Err returns the error of the last write, nil once a write succeeds again.
+func (l *Logger) Err() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lastErr
+}
+
+// Close closes the sink.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sink.Close()
+}
+
+// digest hashes e, whose Hash must be empty, in its JSON encoding.
+func digest(key []byte, e Event) (string, error) {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	var h hash.Hash
+	if len(key) > 0 {
+		h = hmac.New(sha256.New, key)
+	} else {
+		h = sha256.New()
+	}
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,134 @@
+package audit
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/keys"
+)
+
+// Middleware records every API request with its caller, key set, body sizes
+// and status. It must run inside authentication. WebSocket sessions are
+// recorded when they close.
+func (l *Logger) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.IsPublic(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		var body *countingBody
+		if r.Body != nil {
+			body = &countingBody{ReadCloser: r.Body}
+			r.Body = body
+		}
+		sw := &statusWriter{ResponseWriter: w}
+		next.ServeHTTP(sw, r)
+
+		e := Event{
+			Action:        classify(r.Method, r.URL.Path),
+			Op:            r.Method + " " + r.URL.Path,
+			Remote:        r.RemoteAddr,
+			KeyID:         keys.DefaultID,
+			ResponseBytes: sw.n,
+			Status:        sw.status,
+			DurationNanos: int64(time.Since(start)),
+		}
+		if body != nil {
+			e.RequestBytes = body.n
+		}
+		if id, ok := auth.FromContext(r.Context()); ok {
+			e.Actor, e.Tenant = id.ID, id.Tenant
+			if id.KeyID != "" {
+				e.KeyID = id.KeyID
+			}
+		}
+		if e.Status == 0 {
+			e.Status = http.StatusOK
+			if sw.hijacked {
+				e.Status = http.StatusSwitchingProtocols
+			}
+		}
+		e.Outcome = outcome(e.Status)
+		_ = l.Record(e)
+	})
+}
+
+// classify maps a request to its action by route.
+func classify(method, path string) Action {
+	path = strings.TrimPrefix(path, "/v1")
+	switch {
+	case path == "/admin/keys/rotate" && method == http.MethodPost:
+		return KeyRotate
+	case strings.HasPrefix(path, "/admin/keys/") && method == http.MethodDelete:
+		return KeyRevoke
+	case path == "/keys/switch" && method == http.MethodPut:
+		return KeyImport
+	case strings.HasSuffix(path, "/decrypt") || path == "/reencrypt/public" || path == "/admin/elections/results":
+		return Decrypt
+	case strings.HasPrefix(path, "/admin/"):
+		return Admin
+	case path == "/ws" || method == http.MethodPost || method == http.MethodPut:
+		return Compute
+	}
+	return Read
+}
+
+func outcome(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden || status == http.StatusTooManyRequests:
+		return Denied
+	case status >= 400:
+		return Failure
+	}
+	return Success
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+// statusWriter captures the status and counts the bytes of a response.
+type statusWriter struct {
+	http.ResponseWriter
+	status   int
+	n        int64
+	hijacked bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *statusWriter) Flush() { _ = http.NewResponseController(w.ResponseWriter).Flush() }
+
+func (w *statusWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *statusWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	w.hijacked = true
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
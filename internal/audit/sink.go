@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Open returns the sink spec names:
+//
+//	/var/log/tfhe/audit.log          append to a file (also file:PATH)
+//	syslog                           the local syslog daemon
+//	syslog://host:514                a remote daemon over UDP
+//	syslog+tcp://host:601            a remote daemon over TCP
+//	kafka+http://proxy:8082/topic    a Kafka topic through a REST proxy
+//	kafka+https://proxy:8082/topic
+func Open(spec string) (Sink, error) {
+	scheme, rest, ok := strings.Cut(spec, "://")
+	if !ok {
+		if spec == "syslog" {
+			return openSyslog("", "")
+		}
+		return OpenFile(strings.TrimPrefix(spec, "file:"))
+	}
+	switch scheme {
+	case "syslog":
+		return openSyslog("udp", rest)
+	case "syslog+tcp":
+		return openSyslog("tcp", rest)
+	case "kafka+http", "kafka+https":
+		u, err := url.Parse(strings.TrimPrefix(scheme, "kafka+") + "://" + rest)
+		if err != nil {
+			return nil, err
+		}
+		topic := strings.Trim(u.Path, "/")
+		if topic == "" || strings.Contains(topic, "/") {
+			return nil, fmt.Errorf("audit sink %q: want %s://host:port/topic", spec, scheme)
+		}
+		u.Path = "/topics/" + topic
+		return &kafkaSink{url: u.String(), client: &http.Client{Timeout: 10 * time.Second}}, nil
+	}
+	return nil, fmt.Errorf("unknown audit sink %q (want a file path, syslog, syslog://, syslog+tcp://, kafka+http:// or kafka+https://)", spec)
+}
+
+// FileSink appends records to a file, one per line, syncing each to disk
+// before the write returns.
+type FileSink struct {
+	mu sync.Mutex
+	f  *os.File
+}
+
+// OpenFile opens path for appending, creating it readable by its owner only.
+func OpenFile(path string) (*FileSink, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &FileSink{f: f}, nil
+}
+
+// Write appends record and a newline.
+func (s *FileSink) Write(record []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.f.Write(append(record, '\n')); err != nil {
+		return err
+	}
+	return s.f.Sync()
+}
+
+// Last returns the last complete record in the file.
+func (s *FileSink) Last() (Event, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(s.f.Name())
+	if err != nil {
+		return Event{}, false, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return Event{}, false, err
+	}
+	// Records are far smaller than maxRecord, so the last one lies within
+	// the file's final maxRecord bytes.
+	off := max(info.Size()-maxRecord, 0)
+	tail := make([]byte, info.Size()-off)
+	if _, err := f.ReadAt(tail, off); err != nil && !errors.Is(err, io.EOF) {
+		return Event{}, false, err
+	}
+	tail = bytes.TrimRight(tail, "\n")
+	if len(tail) == 0 {
+		return Event{}, false, nil
+	}
+	if i := bytes.LastIndexByte(tail, '\n'); i >= 0 {
+		tail = tail[i+1:]
+	}
+	var e Event
+	if err := json.Unmarshal(tail, &e); err != nil {
+		return Event{}, false, fmt.Errorf("%s: last record: %w", s.f.Name(), err)
+	}
+	return e, true, nil
+}
+
+// Close closes the file.
+func (s *FileSink) Close() error { return s.f.Close() }
+
+// kafkaSink produces each record to a Kafka topic through the REST proxy
+// API v2 (Confluent REST Proxy, Redpanda's HTTP proxy and compatible
+// gateways), waiting for the proxy to acknowledge it.
+type kafkaSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *kafkaSink) Write(record []byte) error {
+	body, err := json.Marshal(map[string]any{"records": []map[string]json.RawMessage{{"value": record}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	var result struct {
+		Offsets []struct {
+			ErrorCode *int   `json:"error_code"`
+			Error     string `json:"error"`
+		} `json:"offsets"`
+		Message string `json:"message"`
+	}
+	_ = json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("kafka proxy: %s: %s", resp.Status, result.Message)
+	}
+	for _, o := range result.Offsets {
+		if o.ErrorCode != nil || o.Error != "" {
+			return fmt.Errorf("kafka proxy: produce failed: %s", o.Error)
+		}
+	}
+	return nil
+}
+
+func (s *kafkaSink) Close() error {
+	s.client.CloseIdleConnections()
+	return nil
+}
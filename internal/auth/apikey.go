@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+type apiKey struct {
+	digest [sha256.Size]byte
+	id     Identity
+}
+
+// APIKeys authenticates requests carrying one of a set of API keys. The set
+// can be swapped at run time with Replace.
+type APIKeys struct {
+	mu   sync.RWMutex
+	keys []apiKey
+}
+
+// ParseAPIKeys reads "name:secret[:tenant]" entries, one per line or
+// separated by commas. Blank lines and lines starting with # are ignored.
+func ParseAPIKeys(r io.Reader) (*APIKeys, error) {
+	k := &APIKeys{}
+	seen := make(map[string]bool)
+	sc := bufio.NewScanner(r)
+	line := 0
+	for sc.Scan() {
+		line++
+		text := strings.TrimSpace(sc.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		for _, entry := range strings.Split(text, ",") {
+			entry = strings.TrimSpace(entry)
+			if entry == "" {
+				continue
+			}
+			parts := strings.SplitN(entry, ":", 3)
+			if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("api keys line %d: expected name:secret[:tenant]", line)
+			}
+			if seen[parts[0]] {
+				return nil, fmt.Errorf("api keys line %d: duplicate key name %q", line, parts[0])
+			}
+			seen[parts[0]] = true
+			id := Identity{ID: parts[0], Tenant: parts[0], Method: "api_key"}
+			if len(parts) == 3 && parts[2] != "" {
+				id.Tenant = parts[2]
+			}
+			k.keys = append(k.keys, apiKey{digest: sha256.Sum256([]byte(parts[1])), id: id})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// LoadAPIKeys combines keys from the file at path (if non-empty) and the
+// inline list in env (if non-empty).
+func LoadAPIKeys(path, inline string) (*APIKeys, error) {
+	var sources []io.Reader
+	if path != "" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("open api keys: %w", err)
+		}
+		defer f.Close()
+		sources = append(sources, f, strings.NewReader("\n"))
+	}
+	if inline != "" {
+		sources = append(sources, strings.NewReader(inline))
+	}
+	return ParseAPIKeys(io.MultiReader(sources...))
+}
+
+// Len returns the number of configured keys.
+func (k *APIKeys) Len() int {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return len(k.keys)
+}
+
+// Replace swaps in the keys of next, e.g. after re-reading the keys file.
+// Requests already authenticated keep their identity.
+func (k *APIKeys) Replace(next *APIKeys) {
+	next.mu.RLock()
+	keys := next.keys
+	next.mu.RUnlock()
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+}
+
+// Lookup returns the identity for secret. Every configured key is compared in
+// constant time so timing does not reveal which, if any, matched.
+func (k *APIKeys) Lookup(secret string) (Identity, bool) {
+	digest := sha256.Sum256([]byte(secret))
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	var found Identity
+	matched := 0
+	for _, key := range k.keys {
+		if subtle.ConstantTimeCompare(digest[:], key.digest[:]) == 1 {
+			found = key.id
+			matched = 1
+		}
+	}
+	return found, matched == 1
+}
+
+// AuthenticateToken implements TokenAuthenticator.
+func (k *APIKeys) AuthenticateToken(token string) (Identity, error) {
+	id, ok := k.Lookup(token)
+	if !ok {
+		return Identity{}, errors.New("invalid api key")
+	}
+	return id, nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"tfhe-go/internal/apierror"
+)
+
+// ErrNoCredentials is returned by an authenticator that does not recognise
+// the presented credential, so the next one may try.
+var ErrNoCredentials = errors.New("missing credentials")
+
+// TokenAuthenticator maps a bearer credential to an Identity.
+type TokenAuthenticator interface {
+	AuthenticateToken(token string) (Identity, error)
+}
+
+// Identity describes the authenticated caller of a request.
+type Identity struct {
+	// ID is the API key name or token subject.
+	ID string
+	// Tenant scopes the caller's keys and stored data; it defaults to ID.
+	Tenant string
+	// Method records how the caller authenticated, e.g. "api_key" or "jwt".
+	Method string
+	// KeyID selects a registered key set to compute under; empty means default.
+	KeyID string
+}
+
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// FromContext returns the identity attached by an authentication middleware.
+func FromContext(ctx context.Context) (Identity, bool) {
+	id, ok := ctx.Value(identityKey{}).(Identity)
+	return id, ok
+}
+
+// publicPaths are reachable without credentials.
+var publicPaths = map[string]bool{
+	"/health":       true,
+	"/openapi.json": true,
+	"/docs":         true,
+}
+
+// IsPublic reports whether path is exempt from authentication.
+func IsPublic(path string) bool {
+	return publicPaths[path]
+}
+
+// Authenticate tries each authenticator in order and returns the first
+// identity that token maps to, or the first rejection reason.
+func Authenticate(token string, authenticators ...TokenAuthenticator) (Identity, error) {
+	if token == "" {
+		return Identity{}, ErrNoCredentials
+	}
+	err := ErrNoCredentials
+	for _, a := range authenticators {
+		id, aerr := a.AuthenticateToken(token)
+		if aerr == nil {
+			return id, nil
+		}
+		if errors.Is(err, ErrNoCredentials) && !errors.Is(aerr, ErrNoCredentials) {
+			err = aerr
+		}
+	}
+	return Identity{}, err
+}
+
+// Middleware rejects requests to non-public paths without a credential
+// accepted by one of authenticators, sent as "Authorization: Bearer <token>"
+// or "X-API-Key: <key>", and attaches the caller's Identity to the context.
+func Middleware(authenticators ...TokenAuthenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if IsPublic(r.URL.Path) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			id, err := Authenticate(tokenFromRequest(r), authenticators...)
+			if err != nil {
+				writeUnauthorized(w, "Bearer", err.Error())
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(WithIdentity(r.Context(), id)))
+		})
+	}
+}
+
+func tokenFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	token, _ := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return token
+}
+
+func writeUnauthorized(w http.ResponseWriter, scheme, msg string) {
+	w.Header().Set("WWW-Authenticate", scheme)
+	apierror.Write(w, http.StatusUnauthorized, apierror.CodeUnauthenticated, msg)
+}
@@ -0,0 +1,264 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// JWTConfig configures bearer-token validation against an identity provider.
+type JWTConfig struct {
+	// Issuer must match the "iss" claim.
+	Issuer string
+	// Audience, when set, must appear in the "aud" claim.
+	Audience string
+	// JWKSURL serves the provider's signing keys.
+	JWKSURL string
+	// TenantClaim names the claim carrying the tenant; defaults to "tenant".
+	TenantClaim string
+	// KeyIDClaim names the claim selecting registered keys; defaults to "key_id".
+	KeyIDClaim string
+	// RefreshInterval bounds how long fetched signing keys are trusted; defaults to 10 minutes.
+	RefreshInterval time.Duration
+}
+
+// JWTValidator authenticates RS256/ES256 bearer tokens issued by a configured provider.
+type JWTValidator struct {
+	cfg    JWTConfig
+	parser *jwt.Parser
+	jwks   *jwksCache
+}
+
+// NewJWTValidator validates cfg and returns a validator. Signing keys are fetched lazily.
+func NewJWTValidator(cfg JWTConfig) (*JWTValidator, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("jwt: issuer is required")
+	}
+	if cfg.JWKSURL == "" {
+		return nil, errors.New("jwt: jwks url is required")
+	}
+	if cfg.TenantClaim == "" {
+		cfg.TenantClaim = "tenant"
+	}
+	if cfg.KeyIDClaim == "" {
+		cfg.KeyIDClaim = "key_id"
+	}
+	if cfg.RefreshInterval <= 0 {
+		cfg.RefreshInterval = 10 * time.Minute
+	}
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(cfg.Issuer),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30 * time.Second),
+	}
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+	return &JWTValidator{
+		cfg:    cfg,
+		parser: jwt.NewParser(opts...),
+		jwks: &jwksCache{
+			url:     cfg.JWKSURL,
+			ttl:     cfg.RefreshInterval,
+			client:  &http.Client{Timeout: 10 * time.Second},
+			keys:    make(map[string]any),
+			minWait: time.Minute,
+		},
+	}, nil
+}
+
+// AuthenticateToken verifies token and maps its claims to an Identity.
+func (v *JWTValidator) AuthenticateToken(token string) (Identity, error) {
+	if strings.Count(token, ".") != 2 {
+		return Identity{}, ErrNoCredentials
+	}
+	claims := jwt.MapClaims{}
+	if _, err := v.parser.ParseWithClaims(token, claims, v.keyFunc); err != nil {
+		return Identity{}, fmt.Errorf("invalid token: %w", err)
+	}
+	sub, _ := claims.GetSubject()
+	id := Identity{ID: sub, Tenant: sub, Method: "jwt"}
+	if tenant, ok := claims[v.cfg.TenantClaim].(string); ok && tenant != "" {
+		id.Tenant = tenant
+	}
+	if keyID, ok := claims[v.cfg.KeyIDClaim].(string); ok {
+		id.KeyID = keyID
+	}
+	return id, nil
+}
+
+func (v *JWTValidator) keyFunc(t *jwt.Token) (any, error) {
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("token has no kid header")
+	}
+	return v.jwks.key(kid)
+}
+
+// jwksCache holds the provider's public keys, refetching them when stale or
+// when an unknown kid appears, at most once per minWait. Fetches run without
+// the lock: stale keys are served while one is in flight, and lookups of an
+// unknown kid wait for it.
+type jwksCache struct {
+	url     string
+	ttl     time.Duration
+	minWait time.Duration
+	client  *http.Client
+
+	mu          sync.Mutex
+	keys        map[string]any
+	fetchedAt   time.Time
+	attemptedAt time.Time
+	fetching    chan struct{} // closed when the fetch in flight ends; nil if none
+	fetchErr    error         // of the last fetch
+}
+
+func (c *jwksCache) key(kid string) (any, error) {
+	c.mu.Lock()
+	k, ok := c.keys[kid]
+	if ok && time.Since(c.fetchedAt) <= c.ttl {
+		c.mu.Unlock()
+		return k, nil
+	}
+	if c.fetching == nil && (c.attemptedAt.IsZero() || time.Since(c.attemptedAt) >= c.minWait) {
+		c.startRefresh()
+	}
+	done, err := c.fetching, c.fetchErr
+	c.mu.Unlock()
+	if ok {
+		return k, nil
+	}
+	if done == nil {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+
+	<-done
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if k, ok := c.keys[kid]; ok {
+		return k, nil
+	}
+	if c.fetchErr != nil {
+		return nil, c.fetchErr
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// startRefresh fetches the keys in the background. c.mu must be held.
+func (c *jwksCache) startRefresh() {
+	done := make(chan struct{})
+	c.fetching = done
+	c.attemptedAt = time.Now()
+	go func() {
+		keys, err := c.fetch()
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if err == nil {
+			c.keys = keys
+			c.fetchedAt = time.Now()
+		}
+		c.fetchErr = err
+		c.fetching = nil
+		close(done)
+	}()
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (c *jwksCache) fetch() (map[string]any, error) {
+	resp, err := c.client.Get(c.url)
+	if err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch jwks: unexpected status %s", resp.Status)
+	}
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("decode jwks: %w", err)
+	}
+	keys := make(map[string]any, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			continue
+		}
+		keys[k.Kid] = pub
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (any, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,274 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testIssuer = "https://issuer.test"
+
+// provider is an identity provider serving its signing keys over JWKS.
+type provider struct {
+	t       *testing.T
+	srv     *httptest.Server
+	fetches atomic.Int64
+
+	mu    sync.Mutex
+	keys  map[string]*ecdsa.PrivateKey
+	block chan struct{} // while non-nil, fetches wait for it to close
+}
+
+func newProvider(t *testing.T, kids ...string) *provider {
+	p := &provider{t: t, keys: make(map[string]*ecdsa.PrivateKey)}
+	for _, kid := range kids {
+		p.addKey(kid)
+	}
+	p.srv = httptest.NewServer(http.HandlerFunc(p.serveJWKS))
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+func (p *provider) addKey(kid string) {
+	k, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys[kid] = k
+}
+
+func (p *provider) serveJWKS(w http.ResponseWriter, r *http.Request) {
+	p.fetches.Add(1)
+	p.mu.Lock()
+	block := p.block
+	p.mu.Unlock()
+	if block != nil {
+		<-block
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	for kid, k := range p.keys {
+		set.Keys = append(set.Keys, jwk{
+			Kid: kid, Kty: "EC", Use: "sig", Crv: "P-256",
+			X: base64.RawURLEncoding.EncodeToString(k.X.Bytes()),
+			Y: base64.RawURLEncoding.EncodeToString(k.Y.Bytes()),
+		})
+	}
+	_ = json.NewEncoder(w).Encode(set)
+}
+
+// sign issues a token under kid with claims over valid defaults; a nil
+// claim value removes it.
+func (p *provider) sign(kid string, claims jwt.MapClaims) string {
+	p.t.Helper()
+	all := jwt.MapClaims{
+		"iss": testIssuer,
+		"sub": "alice",
+		"aud": "tfhe",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, v := range claims {
+		if v == nil {
+			delete(all, name)
+		} else {
+			all[name] = v
+		}
+	}
+	p.mu.Lock()
+	k, ok := p.keys[kid]
+	p.mu.Unlock()
+	if !ok {
+		var err error
+		if k, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader); err != nil {
+			p.t.Fatal(err)
+		}
+	}
+	tok := jwt.NewWithClaims(jwt.SigningMethodES256, all)
+	tok.Header["kid"] = kid
+	s, err := tok.SignedString(k)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return s
+}
+
+func (p *provider) validator(cfg JWTConfig) *JWTValidator {
+	p.t.Helper()
+	cfg.Issuer = testIssuer
+	cfg.JWKSURL = p.srv.URL
+	v, err := NewJWTValidator(cfg)
+	if err != nil {
+		p.t.Fatal(err)
+	}
+	return v
+}
+
+func TestJWTClaims(t *testing.T) {
+	p := newProvider(t, "k1")
+	for _, tc := range []struct {
+		name   string
+		cfg    JWTConfig
+		kid    string
+		claims jwt.MapClaims
+		want   Identity // zero if the token is refused
+	}{
+		{name: "subject as tenant", kid: "k1",
+			want: Identity{ID: "alice", Tenant: "alice", Method: "jwt"}},
+		{name: "tenant claim", kid: "k1", claims: jwt.MapClaims{"tenant": "acme", "key_id": "k-7"},
+			want: Identity{ID: "alice", Tenant: "acme", KeyID: "k-7", Method: "jwt"}},
+		{name: "custom claims", cfg: JWTConfig{TenantClaim: "org", KeyIDClaim: "kid"}, kid: "k1",
+			claims: jwt.MapClaims{"org": "acme", "tenant": "other", "kid": "k-7"},
+			want:   Identity{ID: "alice", Tenant: "acme", KeyID: "k-7", Method: "jwt"}},
+		{name: "empty tenant claim", kid: "k1", claims: jwt.MapClaims{"tenant": ""},
+			want: Identity{ID: "alice", Tenant: "alice", Method: "jwt"}},
+		{name: "audience", cfg: JWTConfig{Audience: "tfhe"}, kid: "k1",
+			want: Identity{ID: "alice", Tenant: "alice", Method: "jwt"}},
+		{name: "wrong audience", cfg: JWTConfig{Audience: "other"}, kid: "k1"},
+		{name: "wrong issuer", kid: "k1", claims: jwt.MapClaims{"iss": "https://evil.test"}},
+		{name: "expired", kid: "k1", claims: jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}},
+		{name: "within leeway", kid: "k1", claims: jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()},
+			want: Identity{ID: "alice", Tenant: "alice", Method: "jwt"}},
+		{name: "no expiry", kid: "k1", claims: jwt.MapClaims{"exp": nil}},
+		{name: "unknown key", kid: "k2"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			v := p.validator(tc.cfg)
+			id, err := v.AuthenticateToken(p.sign(tc.kid, tc.claims))
+			if tc.want == (Identity{}) {
+				if err == nil {
+					t.Fatalf("token accepted as %+v", id)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if id != tc.want {
+				t.Fatalf("identity %+v, want %+v", id, tc.want)
+			}
+		})
+	}
+}
+
+func TestJWKSUnknownKeyRefresh(t *testing.T) {
+	p := newProvider(t, "k1")
+	v := p.validator(JWTConfig{})
+	if _, err := v.AuthenticateToken(p.sign("k1", nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tokens under keys the provider does not publish refetch the keys at
+	// most once per minWait.
+	for range 5 {
+		if _, err := v.AuthenticateToken(p.sign("forged", nil)); err == nil {
+			t.Fatal("token under an unpublished key accepted")
+		}
+	}
+	if n := p.fetches.Load(); n != 1 {
+		t.Fatalf("%d fetches, want 1", n)
+	}
+
+	// Once minWait has passed, a key the provider rotated in is picked up.
+	p.addKey("k2")
+	v.jwks.mu.Lock()
+	v.jwks.attemptedAt = time.Now().Add(-v.jwks.minWait)
+	v.jwks.mu.Unlock()
+	if _, err := v.AuthenticateToken(p.sign("k2", nil)); err != nil {
+		t.Fatal(err)
+	}
+	if n := p.fetches.Load(); n != 2 {
+		t.Fatalf("%d fetches, want 2", n)
+	}
+}
+
+// Stale keys are served while a refresh is in flight, and the lock is not
+// held across the fetch.
+func TestJWKSStaleDuringRefresh(t *testing.T) {
+	p := newProvider(t, "k1")
+	v := p.validator(JWTConfig{RefreshInterval: time.Millisecond})
+	v.jwks.minWait = 0
+	token := p.sign("k1", nil)
+	if _, err := v.AuthenticateToken(token); err != nil {
+		t.Fatal(err)
+	}
+
+	block := make(chan struct{})
+	p.mu.Lock()
+	p.block = block
+	p.mu.Unlock()
+	time.Sleep(5 * time.Millisecond)
+
+	done := make(chan error, 1)
+	go func() {
+		for range 10 {
+			if _, err := v.AuthenticateToken(token); err != nil {
+				done <- err
+				return
+			}
+		}
+		done <- nil
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("validation waited for the refresh of a stale key")
+	}
+	for deadline := time.Now().Add(5 * time.Second); p.fetches.Load() < 2; time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("stale keys were not refreshed")
+		}
+	}
+	if n := p.fetches.Load(); n != 2 {
+		t.Fatalf("%d fetches, want the first and the one in flight", n)
+	}
+
+	// A new key waits for the refresh in flight rather than failing.
+	p.addKey("k2")
+	refreshed := make(chan error, 1)
+	go func() {
+		_, err := v.AuthenticateToken(p.sign("k2", nil))
+		refreshed <- err
+	}()
+	select {
+	case err := <-refreshed:
+		t.Fatalf("unknown key resolved before the refresh ended: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(block)
+	if err := <-refreshed; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestJWKSFetchError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	v, err := NewJWTValidator(JWTConfig{Issuer: testIssuer, JWKSURL: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newProvider(t, "k1")
+	if _, err := v.AuthenticateToken(p.sign("k1", nil)); err == nil {
+		t.Fatal("token accepted without signing keys")
+	}
+}
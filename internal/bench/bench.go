@@ -0,0 +1,483 @@
+// Package bench measures key generation, encryption, every gate and integer
+// operation, and serialization, for sizing deployments and catching
+// performance regressions between releases.
+package bench
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// ParameterSets lists the parameter sets a benchmark can run under.
+var ParameterSets = []string{keys.DefaultParams}
+
+// Env holds the keys and sample ciphertexts the cases run against.
+type Env struct {
+	BoolClient *tfhe.ClientKey
+	BoolServer *tfhe.ServerKey
+	Client     *tfhe.Uint8ClientKey
+	Server     *tfhe.Uint8ServerKey
+	Public     *tfhe.Uint8PublicKey
+
+	boolean    [2]*tfhe.Ciphertext
+	uint8      [2]*tfhe.Uint8Ciphertext
+	ints       map[int][2]*tfhe.IntCiphertext
+	condition  *tfhe.FheBool
+	serialized map[string][]byte // type -> a serialized ciphertext
+}
+
+// NewEnv generates keys for params and encrypts the operands the cases use.
+func NewEnv(params string) (*Env, error) {
+	if !supported(params) {
+		return nil, fmt.Errorf("unsupported parameter set %q (want one of %v)", params, ParameterSets)
+	}
+	e := &Env{ints: make(map[int][2]*tfhe.IntCiphertext), serialized: make(map[string][]byte)}
+	if err := e.init(); err != nil {
+		e.Close()
+		return nil, err
+	}
+	return e, nil
+}
+
+func supported(params string) bool {
+	for _, p := range ParameterSets {
+		if p == params {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *Env) init() error {
+	var err error
+	if e.BoolClient, e.BoolServer, err = tfhe.GenerateBooleanKeys(); err != nil {
+		return err
+	}
+	if e.Client, e.Server, err = tfhe.GenerateUint8Keys(); err != nil {
+		return err
+	}
+	if e.Public, err = tfhe.NewUint8PublicKey(e.Client); err != nil {
+		return err
+	}
+	for i, v := range []bool{true, false} {
+		if e.boolean[i], err = tfhe.EncryptBool(e.BoolClient, v); err != nil {
+			return err
+		}
+	}
+	for i, v := range []uint8{200, 100} {
+		if e.uint8[i], err = tfhe.EncryptUint8(e.Client, v); err != nil {
+			return err
+		}
+	}
+	for _, bits := range tfhe.IntWidths {
+		operands := []uint64{40000, 30000}
+		if bits < 16 {
+			operands = []uint64{3, 2}
+		}
+		var pair [2]*tfhe.IntCiphertext
+		for i, v := range operands {
+			if pair[i], err = tfhe.EncryptInt(e.Client, bits, v); err != nil {
+				return err
+			}
+		}
+		e.ints[bits] = pair
+	}
+	if e.condition, err = e.Server.Compare(tfhe.CompareLt, e.uint8[0], e.uint8[1]); err != nil {
+		return err
+	}
+
+	if e.serialized["boolean"], err = e.boolean[0].Serialize(); err != nil {
+		return err
+	}
+	if e.serialized["uint8"], err = e.uint8[0].Uint8Serialize(); err != nil {
+		return err
+	}
+	if e.serialized["bool"], err = e.condition.Serialize(); err != nil {
+		return err
+	}
+	for bits, pair := range e.ints {
+		if e.serialized[intType(bits)], err = pair[0].Serialize(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close releases the keys and ciphertexts.
+func (e *Env) Close() {
+	for _, ct := range e.boolean {
+		_ = ct.Close()
+	}
+	for _, ct := range e.uint8 {
+		_ = ct.Close()
+	}
+	for _, pair := range e.ints {
+		_ = pair[0].Close()
+		_ = pair[1].Close()
+	}
+	_ = e.condition.Close()
+	_ = e.Public.Close()
+	_ = e.Server.Close()
+	_ = e.Client.Close()
+	_ = e.BoolServer.Close()
+	_ = e.BoolClient.Close()
+}
+
+// Case is one measured operation. Op returns the number of bytes it
+// produced, for serialization, or 0.
+type Case struct {
+	Name string
+	// Keygen cases are slow and run for Options.KeygenIterations only.
+	Keygen bool
+	Op     func(e *Env) (int, error)
+}
+
+// closing runs fn and releases what it returns.
+func closing[T interface{ Close() error }](fn func() (T, error)) (int, error) {
+	v, err := fn()
+	if err != nil {
+		return 0, err
+	}
+	return 0, v.Close()
+}
+
+// Cases lists every benchmark, named "<type>.<operation>".
+func Cases() []Case {
+	cases := []Case{
+		{Name: "boolean.keygen", Keygen: true, Op: func(e *Env) (int, error) {
+			ck, sk, err := tfhe.GenerateBooleanKeys()
+			if err != nil {
+				return 0, err
+			}
+			_ = ck.Close()
+			return 0, sk.Close()
+		}},
+		{Name: "integer.keygen", Keygen: true, Op: func(e *Env) (int, error) {
+			ck, sk, err := tfhe.GenerateUint8Keys()
+			if err != nil {
+				return 0, err
+			}
+			_ = ck.Close()
+			return 0, sk.Close()
+		}},
+		{Name: "integer.public_key", Keygen: true, Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Uint8PublicKey, error) { return tfhe.NewUint8PublicKey(e.Client) })
+		}},
+		{Name: "boolean.encrypt", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Ciphertext, error) { return tfhe.EncryptBool(e.BoolClient, true) })
+		}},
+		{Name: "boolean.decrypt", Op: func(e *Env) (int, error) {
+			_, err := tfhe.DecryptBool(e.BoolClient, e.boolean[0])
+			return 0, err
+		}},
+	}
+	for _, gate := range []struct {
+		name string
+		fn   func(e *Env) (*tfhe.Ciphertext, error)
+	}{
+		{"and", func(e *Env) (*tfhe.Ciphertext, error) { return e.BoolServer.And(e.boolean[0], e.boolean[1]) }},
+		{"or", func(e *Env) (*tfhe.Ciphertext, error) { return e.BoolServer.Or(e.boolean[0], e.boolean[1]) }},
+		{"xor", func(e *Env) (*tfhe.Ciphertext, error) { return e.BoolServer.Xor(e.boolean[0], e.boolean[1]) }},
+		{"not", func(e *Env) (*tfhe.Ciphertext, error) { return e.BoolServer.Not(e.boolean[0]) }},
+	} {
+		cases = append(cases, Case{Name: "boolean." + gate.name, Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Ciphertext, error) { return gate.fn(e) })
+		}})
+	}
+	cases = append(cases,
+		Case{Name: "boolean.serialize", Op: func(e *Env) (int, error) {
+			data, err := e.boolean[0].Serialize()
+			return len(data), err
+		}},
+		Case{Name: "boolean.deserialize", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Ciphertext, error) { return tfhe.DeserializeCiphertext(e.serialized["boolean"]) })
+		}},
+	)
+	cases = append(cases, uint8Cases()...)
+	for _, bits := range tfhe.IntWidths {
+		cases = append(cases, intCases(bits)...)
+	}
+	return cases
+}
+
+func uint8Cases() []Case {
+	cases := []Case{
+		{Name: "uint8.encrypt", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Uint8Ciphertext, error) { return tfhe.EncryptUint8(e.Client, 7) })
+		}},
+		{Name: "uint8.encrypt_public", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Uint8Ciphertext, error) { return tfhe.EncryptUint8Public(e.Public, 7) })
+		}},
+		{Name: "uint8.decrypt", Op: func(e *Env) (int, error) {
+			_, err := tfhe.DecryptUint8(e.Client, e.uint8[0])
+			return 0, err
+		}},
+	}
+	for _, op := range []struct {
+		name string
+		fn   func(sk *tfhe.Uint8ServerKey, lhs, rhs *tfhe.Uint8Ciphertext) (*tfhe.Uint8Ciphertext, error)
+	}{{"add", (*tfhe.Uint8ServerKey).Add}, {"bitand", (*tfhe.Uint8ServerKey).BitAnd}, {"bitxor", (*tfhe.Uint8ServerKey).BitXor}} {
+		cases = append(cases, Case{Name: "uint8." + op.name, Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Uint8Ciphertext, error) { return op.fn(e.Server, e.uint8[0], e.uint8[1]) })
+		}})
+	}
+	for _, cmp := range tfhe.Comparisons {
+		cases = append(cases, Case{Name: "uint8." + string(cmp), Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.FheBool, error) { return e.Server.Compare(cmp, e.uint8[0], e.uint8[1]) })
+		}})
+	}
+	return append(cases,
+		Case{Name: "uint8.if_then_else", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Uint8Ciphertext, error) { return e.Server.IfThenElse(e.condition, e.uint8[0], e.uint8[1]) })
+		}},
+		Case{Name: "uint8.serialize", Op: func(e *Env) (int, error) {
+			data, err := e.uint8[0].Uint8Serialize()
+			return len(data), err
+		}},
+		Case{Name: "uint8.deserialize", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.Uint8Ciphertext, error) { return tfhe.Uint8Deserialize(e.serialized["uint8"]) })
+		}},
+		Case{Name: "bool.serialize", Op: func(e *Env) (int, error) {
+			data, err := e.condition.Serialize()
+			return len(data), err
+		}},
+		Case{Name: "bool.deserialize", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.FheBool, error) { return tfhe.DeserializeFheBool(e.serialized["bool"]) })
+		}},
+	)
+}
+
+func intCases(bits int) []Case {
+	typ := intType(bits)
+	cases := []Case{
+		{Name: typ + ".encrypt", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.IntCiphertext, error) { return tfhe.EncryptInt(e.Client, bits, 3) })
+		}},
+		{Name: typ + ".encrypt_public", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.IntCiphertext, error) { return tfhe.EncryptIntPublic(e.Public, bits, 3) })
+		}},
+		{Name: typ + ".decrypt", Op: func(e *Env) (int, error) {
+			_, err := tfhe.DecryptInt(e.Client, e.ints[bits][0])
+			return 0, err
+		}},
+	}
+	for _, op := range []struct {
+		name string
+		fn   func(sk *tfhe.Uint8ServerKey, lhs, rhs *tfhe.IntCiphertext) (*tfhe.IntCiphertext, error)
+	}{{"add", (*tfhe.Uint8ServerKey).IntAdd}, {"bitand", (*tfhe.Uint8ServerKey).IntBitAnd}, {"bitxor", (*tfhe.Uint8ServerKey).IntBitXor}} {
+		cases = append(cases, Case{Name: typ + "." + op.name, Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.IntCiphertext, error) { return op.fn(e.Server, e.ints[bits][0], e.ints[bits][1]) })
+		}})
+	}
+	for _, cmp := range tfhe.Comparisons {
+		cases = append(cases, Case{Name: typ + "." + string(cmp), Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.FheBool, error) { return e.Server.IntCompare(cmp, e.ints[bits][0], e.ints[bits][1]) })
+		}})
+	}
+	return append(cases,
+		Case{Name: typ + ".if_then_else", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.IntCiphertext, error) {
+				return e.Server.IntIfThenElse(e.condition, e.ints[bits][0], e.ints[bits][1])
+			})
+		}},
+		Case{Name: typ + ".serialize", Op: func(e *Env) (int, error) {
+			data, err := e.ints[bits][0].Serialize()
+			return len(data), err
+		}},
+		Case{Name: typ + ".deserialize", Op: func(e *Env) (int, error) {
+			return closing(func() (*tfhe.IntCiphertext, error) { return tfhe.DeserializeInt(bits, e.serialized[typ]) })
+		}},
+	)
+}
+
+func intType(bits int) string { return "uint" + strconv.Itoa(bits) }
+
+// Options configures Run.
+type Options struct {
+	// ParameterSets to run under; defaults to all of ParameterSets.
+	ParameterSets []string
+	// Iterations per case, after one warm-up run. Defaults to 10.
+	Iterations int
+	// KeygenIterations per key generation case. Defaults to 1.
+	KeygenIterations int
+	// Filter, if set, selects cases by name.
+	Filter *regexp.Regexp
+	// Progress, if set, is called after each case.
+	Progress func(Result)
+}
+
+// Result summarises one case under one parameter set.
+type Result struct {
+	ParameterSet string `json:"parameter_set"`
+	Name         string `json:"name"`
+	Iterations   int    `json:"iterations"`
+	MeanNanos    int64  `json:"mean_ns"`
+	MinNanos     int64  `json:"min_ns"`
+	P50Nanos     int64  `json:"p50_ns"`
+	P99Nanos     int64  `json:"p99_ns"`
+	MaxNanos     int64  `json:"max_ns"`
+	Bytes        int    `json:"bytes,omitempty"`
+}
+
+// Run measures the selected cases under each parameter set.
+func Run(opts Options) ([]Result, error) {
+	if len(opts.ParameterSets) == 0 {
+		opts.ParameterSets = ParameterSets
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = 10
+	}
+	if opts.KeygenIterations <= 0 {
+		opts.KeygenIterations = 1
+	}
+	var results []Result
+	for _, params := range opts.ParameterSets {
+		env, err := NewEnv(params)
+		if err != nil {
+			return results, fmt.Errorf("parameter set %s: %w", params, err)
+		}
+		for _, c := range Cases() {
+			if opts.Filter != nil && !opts.Filter.MatchString(c.Name) {
+				continue
+			}
+			n := opts.Iterations
+			if c.Keygen {
+				n = opts.KeygenIterations
+			}
+			r, err := Measure(env, c, n)
+			if err != nil {
+				env.Close()
+				return results, fmt.Errorf("parameter set %s: %s: %w", params, c.Name, err)
+			}
+			r.ParameterSet = params
+			results = append(results, r)
+			if opts.Progress != nil {
+				opts.Progress(r)
+			}
+		}
+		env.Close()
+	}
+	return results, nil
+}
+
+// Measure runs c n times against env, after a warm-up run for cases other
+// than key generation, and summarises the timings.
+func Measure(env *Env, c Case, n int) (Result, error) {
+	// The warm-up run pays one-off costs such as thread-local key setup.
+	if !c.Keygen {
+		if _, err := c.Op(env); err != nil {
+			return Result{}, err
+		}
+	}
+	samples := make([]int64, n)
+	var bytes int
+	var total int64
+	for i := range samples {
+		start := time.Now()
+		b, err := c.Op(env)
+		if err != nil {
+			return Result{}, err
+		}
+		samples[i] = int64(time.Since(start))
+		total += samples[i]
+		bytes = b
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return Result{
+		Name:       c.Name,
+		Iterations: n,
+		MeanNanos:  total / int64(n),
+		MinNanos:   samples[0],
+		P50Nanos:   percentile(samples, 0.50),
+		P99Nanos:   percentile(samples, 0.99),
+		MaxNanos:   samples[n-1],
+		Bytes:      bytes,
+	}, nil
+}
+
+// percentile picks the nearest-rank percentile of sorted samples.
+func percentile(sorted []int64, p float64) int64 {
+	i := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[min(max(i, 0), len(sorted)-1)]
+}
+
+// WriteJSON writes results as an indented JSON array.
+func WriteJSON(w io.Writer, results []Result) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(results)
+}
+
+// WriteCSV writes results with a header row.
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"parameter_set", "name", "iterations", "mean_ns", "min_ns", "p50_ns", "p99_ns", "max_ns", "bytes"})
+	for _, r := range results {
+		_ = cw.Write([]string{
+			r.ParameterSet, r.Name, strconv.Itoa(r.Iterations),
+			strconv.FormatInt(r.MeanNanos, 10), strconv.FormatInt(r.MinNanos, 10),
+			strconv.FormatInt(r.P50Nanos, 10), strconv.FormatInt(r.P99Nanos, 10),
+			strconv.FormatInt(r.MaxNanos, 10), strconv.Itoa(r.Bytes),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadJSON reads results written by WriteJSON, e.g. a previous release's
+// baseline.
+func ReadJSON(r io.Reader) ([]Result, error) {
+	var results []Result
+	if err := json.NewDecoder(r).Decode(&results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// Regression is a case whose mean time grew past the allowed ratio.
+type Regression struct {
+	ParameterSet string
+	Name         string
+	Baseline     time.Duration
+	Current      time.Duration
+}
+
+func (r Regression) String() string {
+	return fmt.Sprintf("%s/%s: %v -> %v (%.2fx)", r.ParameterSet, r.Name, r.Baseline, r.Current, float64(r.Current)/float64(r.Baseline))
+}
+
+// Compare reports the cases in current whose mean exceeds the same case in
+// baseline by more than ratio, e.g. 1.2 for 20%. Cases missing from either
+// side are ignored.
+func Compare(baseline, current []Result, ratio float64) []Regression {
+	type key struct{ params, name string }
+	base := make(map[key]Result, len(baseline))
+	for _, r := range baseline {
+		base[key{r.ParameterSet, r.Name}] = r
+	}
+	var out []Regression
+	for _, r := range current {
+		b, ok := base[key{r.ParameterSet, r.Name}]
+		if !ok || b.MeanNanos <= 0 {
+			continue
+		}
+		if float64(r.MeanNanos) > float64(b.MeanNanos)*ratio {
+			out = append(out, Regression{
+				ParameterSet: r.ParameterSet,
+				Name:         r.Name,
+				Baseline:     time.Duration(b.MeanNanos),
+				Current:      time.Duration(r.MeanNanos),
+			})
+		}
+	}
+	return out
+}
@@ -0,0 +1,31 @@
+package bench
+
+import (
+	"testing"
+
+	"tfhe-go/internal/keys"
+)
+
+// BenchmarkCases runs every case as a sub-benchmark, e.g.
+//
+//	go test ./internal/bench -run '^$' -bench 'Cases/uint8\.' -benchtime 20x
+func BenchmarkCases(b *testing.B) {
+	env, err := NewEnv(keys.DefaultParams)
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer env.Close()
+	for _, c := range Cases() {
+		b.Run(c.Name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				n, err := c.Op(env)
+				if err != nil {
+					b.Fatal(err)
+				}
+				if n > 0 {
+					b.SetBytes(int64(n))
+				}
+			}
+		})
+	}
+}
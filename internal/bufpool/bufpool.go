@@ -0,0 +1,76 @@
+// Package bufpool recycles the transient buffers of ciphertext serialization
+// and base64 coding, which otherwise make up most of the service's garbage.
+package bufpool
+
+import (
+	"bytes"
+	"encoding/base64"
+	"sync"
+	"unsafe"
+)
+
+// maxPooled bounds the buffers kept for reuse, so one oversized request does
+// not pin its memory in the pool.
+const maxPooled = 4 << 20
+
+var (
+	bytesPool  = sync.Pool{New: func() any { return new([]byte) }}
+	bufferPool = sync.Pool{New: func() any { return new(bytes.Buffer) }}
+)
+
+// Get returns an empty slice with room for at least n bytes. Hand it back
+// with Put once nothing refers to it any more.
+func Get(n int) *[]byte {
+	b := bytesPool.Get().(*[]byte)
+	if cap(*b) < n {
+		*b = make([]byte, 0, n)
+	}
+	*b = (*b)[:0]
+	return b
+}
+
+// Put returns b to the pool. A nil b is ignored.
+func Put(b *[]byte) {
+	if b == nil || cap(*b) > maxPooled {
+		return
+	}
+	bytesPool.Put(b)
+}
+
+// GetBuffer returns an empty buffer; hand it back with PutBuffer.
+func GetBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// PutBuffer resets buf and returns it to the pool.
+func PutBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooled {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
+
+// DecodeBase64 decodes standard base64 into a pooled slice, which the caller
+// hands back with Put.
+func DecodeBase64(s string) (*[]byte, error) {
+	b := Get(base64.StdEncoding.DecodedLen(len(s)))
+	// Decode only reads src, so it can view s instead of copying it.
+	src := unsafe.Slice(unsafe.StringData(s), len(s))
+	n, err := base64.StdEncoding.Decode((*b)[:cap(*b)], src)
+	if err != nil {
+		Put(b)
+		return nil, err
+	}
+	*b = (*b)[:n]
+	return b, nil
+}
+
+// EncodeBase64 returns data in standard base64, encoding through a pooled
+// buffer so the string is the only allocation.
+func EncodeBase64(data []byte) string {
+	b := Get(base64.StdEncoding.EncodedLen(len(data)))
+	defer Put(b)
+	*b = base64.StdEncoding.AppendEncode(*b, data)
+	return string(*b)
+}
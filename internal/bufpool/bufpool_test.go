@@ -0,0 +1,75 @@
+package bufpool
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+)
+
+// payload is about the size of a serialized uint8 ciphertext.
+var payload = func() string {
+	raw := make([]byte, 64<<10)
+	_, _ = rand.Read(raw)
+	return base64.StdEncoding.EncodeToString(raw)
+}()
+
+// Compare the pooled paths with the standard library's, e.g.
+//
+//	go test ./internal/bufpool -run '^$' -bench . -benchmem
+func BenchmarkDecodeBase64(b *testing.B) {
+	b.Run("std", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := base64.StdEncoding.DecodeString(payload); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			raw, err := DecodeBase64(payload)
+			if err != nil {
+				b.Fatal(err)
+			}
+			Put(raw)
+		}
+	})
+}
+
+func BenchmarkEncodeBase64(b *testing.B) {
+	raw, _ := base64.StdEncoding.DecodeString(payload)
+	b.Run("std", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = base64.StdEncoding.EncodeToString(raw)
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			_ = EncodeBase64(raw)
+		}
+	})
+}
+
+func BenchmarkJSON(b *testing.B) {
+	body := map[string]string{"ciphertext": payload}
+	b.Run("std", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			var buf bytes.Buffer
+			_ = json.NewEncoder(&buf).Encode(body)
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			buf := GetBuffer()
+			_ = json.NewEncoder(buf).Encode(body)
+			PutBuffer(buf)
+		}
+	})
+}
@@ -0,0 +1,312 @@
+// Package cbor encodes and decodes the subset of CBOR (RFC 8949) that maps
+// onto JSON documents plus byte strings: maps with text keys, arrays, text
+// and byte strings, integers, floats, booleans and null. It lets the HTTP
+// API carry ciphertexts as byte strings without a general-purpose codec.
+package cbor
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"unicode/utf8"
+)
+
+// ErrSyntax matches every error Unmarshal returns for malformed input.
+var ErrSyntax = errors.New("invalid CBOR")
+
+// maxDepth bounds the nesting of arrays and maps Unmarshal accepts.
+const maxDepth = 64
+
+// Major types.
+const (
+	majorUint   = 0
+	majorNegint = 1
+	majorBytes  = 2
+	majorText   = 3
+	majorArray  = 4
+	majorMap    = 5
+	majorTag    = 6
+	majorSimple = 7
+)
+
+// Marshal encodes v, which may be nil, a bool, a string, a []byte, an int,
+// int64, uint64, float64 or json.Number, or a []any or map[string]any of
+// those, as decoded by encoding/json with UseNumber. Map keys are written
+// in sorted order, so equal values encode identically.
+func Marshal(v any) ([]byte, error) {
+	return appendValue(nil, v)
+}
+
+func appendValue(b []byte, v any) ([]byte, error) {
+	switch v := v.(type) {
+	case nil:
+		return append(b, 0xf6), nil
+	case bool:
+		if v {
+			return append(b, 0xf5), nil
+		}
+		return append(b, 0xf4), nil
+	case string:
+		return append(appendHead(b, majorText, uint64(len(v))), v...), nil
+	case []byte:
+		return append(appendHead(b, majorBytes, uint64(len(v))), v...), nil
+	case int:
+		return appendInt(b, int64(v)), nil
+	case int64:
+		return appendInt(b, v), nil
+	case uint64:
+		return appendHead(b, majorUint, v), nil
+	case float64:
+		return appendFloat(b, v), nil
+	case json.Number:
+		if i, err := strconv.ParseInt(string(v), 10, 64); err == nil {
+			return appendInt(b, i), nil
+		}
+		if u, err := strconv.ParseUint(string(v), 10, 64); err == nil {
+			return appendHead(b, majorUint, u), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("cbor: number %s: %w", v, err)
+		}
+		return appendFloat(b, f), nil
+	case []any:
+		b = appendHead(b, majorArray, uint64(len(v)))
+		for _, item := range v {
+			var err error
+			if b, err = appendValue(b, item); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b = appendHead(b, majorMap, uint64(len(v)))
+		for _, k := range keys {
+			b = append(appendHead(b, majorText, uint64(len(k))), k...)
+			var err error
+			if b, err = appendValue(b, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return b, nil
+	}
+	return nil, fmt.Errorf("cbor: unsupported type %T", v)
+}
+
+func appendInt(b []byte, i int64) []byte {
+	if i < 0 {
+		return appendHead(b, majorNegint, uint64(-(i + 1)))
+	}
+	return appendHead(b, majorUint, uint64(i))
+}
+
+// appendFloat writes f as a single-precision float when that loses nothing.
+func appendFloat(b []byte, f float64) []byte {
+	if f32 := float32(f); float64(f32) == f || math.IsNaN(f) {
+		return binary.BigEndian.AppendUint32(append(b, 0xfa), math.Float32bits(f32))
+	}
+	return binary.BigEndian.AppendUint64(append(b, 0xfb), math.Float64bits(f))
+}
+
+// appendHead writes the initial byte of an item of major type major with
+// argument n, in the shortest form.
+func appendHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(b, major|27), n)
+}
+
+// Unmarshal decodes a single CBOR item filling all of data into nil, bool,
+// string, []byte, int64, uint64, float64, []any and map[string]any values.
+// Tags are skipped, leaving the tagged item; undefined decodes as nil.
+// Indefinite-length items and maps with keys other than text are refused.
+func Unmarshal(data []byte) (any, error) {
+	d := decoder{data: data}
+	v, err := d.value(0)
+	if err != nil {
+		return nil, err
+	}
+	if d.off != len(data) {
+		return nil, d.errorf("%d bytes after the top-level item", len(data)-d.off)
+	}
+	return v, nil
+}
+
+type decoder struct {
+	data []byte
+	off  int
+}
+
+func (d *decoder) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at byte %d: %s", ErrSyntax, d.off, fmt.Sprintf(format, args...))
+}
+
+// head reads the initial byte and argument of an item.
+func (d *decoder) head() (major byte, info byte, n uint64, err error) {
+	if d.off >= len(d.data) {
+		return 0, 0, 0, d.errorf("unexpected end of data")
+	}
+	major, info = d.data[d.off]>>5, d.data[d.off]&0x1f
+	d.off++
+	size := 0
+	switch {
+	case info < 24:
+		return major, info, uint64(info), nil
+	case info == 24:
+		size = 1
+	case info == 25:
+		size = 2
+	case info == 26:
+		size = 4
+	case info == 27:
+		size = 8
+	case info == 31:
+		return 0, 0, 0, d.errorf("indefinite-length items are not supported")
+	default:
+		return 0, 0, 0, d.errorf("reserved additional information %d", info)
+	}
+	if len(d.data)-d.off < size {
+		return 0, 0, 0, d.errorf("unexpected end of data")
+	}
+	for _, c := range d.data[d.off : d.off+size] {
+		n = n<<8 | uint64(c)
+	}
+	d.off += size
+	return major, info, n, nil
+}
+
+// take returns the next n bytes.
+func (d *decoder) take(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.off) {
+		return nil, d.errorf("length %d exceeds the remaining %d bytes", n, len(d.data)-d.off)
+	}
+	b := d.data[d.off : d.off+int(n)]
+	d.off += int(n)
+	return b, nil
+}
+
+func (d *decoder) value(depth int) (any, error) {
+	if depth > maxDepth {
+		return nil, d.errorf("nested deeper than %d", maxDepth)
+	}
+	major, info, n, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+	switch major {
+	case majorUint:
+		if n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+		return n, nil
+	case majorNegint:
+		if n > math.MaxInt64 {
+			return nil, d.errorf("negative integer out of range")
+		}
+		return -int64(n) - 1, nil
+	case majorBytes:
+		b, err := d.take(n)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.Clone(b), nil
+	case majorText:
+		b, err := d.take(n)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			return nil, d.errorf("text string is not valid UTF-8")
+		}
+		return string(b), nil
+	case majorArray:
+		// Every item takes at least a byte.
+		if n > uint64(len(d.data)-d.off) {
+			return nil, d.errorf("array of %d items exceeds the remaining data", n)
+		}
+		items := make([]any, n)
+		for i := range items {
+			if items[i], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	case majorMap:
+		if n > uint64(len(d.data)-d.off)/2 {
+			return nil, d.errorf("map of %d entries exceeds the remaining data", n)
+		}
+		m := make(map[string]any, n)
+		for i := uint64(0); i < n; i++ {
+			key, err := d.value(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			k, ok := key.(string)
+			if !ok {
+				return nil, d.errorf("map key is %T, not a text string", key)
+			}
+			if _, dup := m[k]; dup {
+				return nil, d.errorf("duplicate map key %q", k)
+			}
+			if m[k], err = d.value(depth + 1); err != nil {
+				return nil, err
+			}
+		}
+		return m, nil
+	case majorTag:
+		return d.value(depth + 1)
+	}
+	switch info {
+	case 20:
+		return false, nil
+	case 21:
+		return true, nil
+	case 22, 23:
+		return nil, nil
+	case 25:
+		return float16(uint16(n)), nil
+	case 26:
+		return float64(math.Float32frombits(uint32(n))), nil
+	case 27:
+		return math.Float64frombits(n), nil
+	}
+	return nil, d.errorf("unsupported simple value %d", n)
+}
+
+// float16 widens an IEEE 754 half-precision float.
+func float16(h uint16) float64 {
+	sign := 1.0
+	if h&0x8000 != 0 {
+		sign = -1
+	}
+	exp, frac := int(h>>10&0x1f), float64(h&0x3ff)
+	switch exp {
+	case 0:
+		return sign * math.Ldexp(frac, -24)
+	case 0x1f:
+		if frac != 0 {
+			return math.NaN()
+		}
+		return math.Inf(int(sign))
+	}
+	return sign * math.Ldexp(1024+frac, exp-25)
+}
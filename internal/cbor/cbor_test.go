@@ -0,0 +1,150 @@
+package cbor
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	for _, v := range []any{
+		nil, true, false, "", "héllo", []byte{}, []byte{0, 1, 2},
+		int64(0), int64(23), int64(24), int64(-1), int64(-25), int64(1 << 40),
+		int64(math.MinInt64), uint64(math.MaxUint64), 0.5, -1e300, math.Inf(1),
+		[]any{int64(1), "a", []byte("b")},
+		map[string]any{"ciphertext": []byte{9}, "nested": map[string]any{"n": nil}},
+	} {
+		data, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(%v): %v", v, err)
+		}
+		got, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal(%x): %v", data, err)
+		}
+		if !reflect.DeepEqual(got, v) {
+			t.Errorf("round trip of %#v gave %#v", v, got)
+		}
+	}
+}
+
+// The vectors are from RFC 8949, appendix A.
+func TestVectors(t *testing.T) {
+	for _, tc := range []struct {
+		hex  string
+		want any
+	}{
+		{"1903e8", int64(1000)},
+		{"3863", int64(-100)},
+		{"f93c00", 1.0},
+		{"f90001", 5.960464477539063e-08},
+		{"fa47c35000", 100000.0},
+		{"fb3ff199999999999a", 1.1},
+		{"4401020304", []byte{1, 2, 3, 4}},
+		{"6449455446", "IETF"},
+		{"a26161016162820203", map[string]any{"a": int64(1), "b": []any{int64(2), int64(3)}}},
+		{"c074323031332d30332d32315432303a30343a30305a", "2013-03-21T20:04:00Z"},
+		{"f7", nil},
+	} {
+		data, _ := hex.DecodeString(tc.hex)
+		got, err := Unmarshal(data)
+		if err != nil {
+			t.Fatalf("Unmarshal(%s): %v", tc.hex, err)
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("Unmarshal(%s) = %#v, want %#v", tc.hex, got, tc.want)
+		}
+	}
+}
+
+func TestMarshalNumber(t *testing.T) {
+	for number, want := range map[json.Number]string{"7": "07", "-500": "3901f3", "18446744073709551615": "1bffffffffffffffff", "0.5": "fa3f000000"} {
+		data, err := Marshal(number)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := hex.EncodeToString(data); got != want {
+			t.Errorf("Marshal(%s) = %s, want %s", number, got, want)
+		}
+	}
+}
+
+func TestUnmarshalInvalid(t *testing.T) {
+	for _, h := range []string{
+		"",                   // no item
+		"18",                 // missing argument
+		"5f",                 // indefinite length
+		"45010203",           // short byte string
+		"9bffffffffffffffff", // huge array
+		"a1010203",           // integer key
+		"a2616101616102",     // duplicate key
+		"62c328",             // invalid UTF-8
+		"3bffffffffffffffff", // negative overflow
+		"0101",               // trailing data
+		"f800",               // unassigned simple value
+	} {
+		data, _ := hex.DecodeString(h)
+		if _, err := Unmarshal(data); !errors.Is(err, ErrSyntax) {
+			t.Errorf("Unmarshal(%s) = %v, want ErrSyntax", h, err)
+		}
+	}
+	deep := append(bytes.Repeat([]byte{0x81}, maxDepth+1), 0)
+	if _, err := Unmarshal(deep); !errors.Is(err, ErrSyntax) {
+		t.Errorf("Unmarshal of %d nested arrays = %v, want ErrSyntax", maxDepth+1, err)
+	}
+}
+
+func FuzzUnmarshal(f *testing.F) {
+	f.Add([]byte{0xa1, 0x61, 0x61, 0x42, 1, 2})
+	f.Add([]byte{0x9f, 0xff})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		v, err := Unmarshal(data)
+		if err != nil {
+			if !errors.Is(err, ErrSyntax) {
+				t.Fatalf("error %v is not ErrSyntax", err)
+			}
+			return
+		}
+		again, err := Marshal(v)
+		if err != nil {
+			t.Fatalf("Marshal(%#v): %v", v, err)
+		}
+		if _, err := Unmarshal(again); err != nil {
+			t.Fatalf("re-encoded %x: %v", again, err)
+		}
+	})
+}
+
+// Compare a ciphertext-sized body with its JSON form, e.g.
+//
+//	go test ./internal/cbor -run '^$' -bench . -benchmem
+func BenchmarkCiphertext(b *testing.B) {
+	ct := bytes.Repeat([]byte{0x5a, 0xc3}, 32<<10)
+	b.Run("json", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, _ := json.Marshal(map[string]any{"ciphertext": base64.StdEncoding.EncodeToString(ct)})
+			var v map[string]string
+			if err := json.Unmarshal(data, &v); err != nil {
+				b.Fatal(err)
+			}
+			if _, err := base64.StdEncoding.DecodeString(v["ciphertext"]); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cbor", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			data, _ := Marshal(map[string]any{"ciphertext": ct})
+			if _, err := Unmarshal(data); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
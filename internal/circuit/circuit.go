@@ -0,0 +1,287 @@
+// Package circuit evaluates small homomorphic circuits: a graph of operations
+// over named input ciphertexts, given either as JSON or as an expression.
+package circuit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// MaxNodes bounds the number of operations in one circuit.
+const MaxNodes = 1024
+
+// ErrInvalid marks circuits that are malformed or apply an operation to
+// operands it does not support.
+var ErrInvalid = errors.New("invalid circuit")
+
+// Invalid wraps err as ErrInvalid.
+func Invalid(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalid, err)
+}
+
+// Graph is a circuit as a DAG: each node may refer to inputs and to other
+// nodes, listed in any order, as long as no node depends on itself.
+type Graph struct {
+	Nodes []Node `json:"nodes"`
+	// Outputs maps each output name to the node or input it returns.
+	Outputs map[string]string `json:"outputs"`
+}
+
+// Node applies Op to the values named by Args.
+type Node struct {
+	ID   string   `json:"id"`
+	Op   string   `json:"op"`
+	Args []string `json:"args"`
+}
+
+// Value is a serialized ciphertext and its type name, e.g. "uint8".
+type Value struct {
+	Type string
+	Data []byte
+}
+
+// Ops runs single operations for the evaluator. Implementations return
+// errors wrapping ErrInvalid for unsupported operations or operand types.
+type Ops interface {
+	Apply(ctx context.Context, op string, args []Value) (Value, error)
+}
+
+// Validate checks that g is well formed against the given input names.
+func (g *Graph) Validate(inputs map[string]Value) error {
+	_, err := g.Order(inputs)
+	return err
+}
+
+// Order validates g against the given input names and returns the indexes
+// of its nodes in an order where every node follows the nodes it refers
+// to. Nodes keep their listed order where their dependencies allow.
+func (g *Graph) Order(inputs map[string]Value) ([]int, error) {
+	if len(g.Nodes) > MaxNodes {
+		return nil, Invalid(fmt.Errorf("%d nodes, limit is %d", len(g.Nodes), MaxNodes))
+	}
+	if len(g.Outputs) == 0 {
+		return nil, Invalid(errors.New("no outputs"))
+	}
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		if n.ID == "" {
+			return nil, Invalid(fmt.Errorf("node %d has no id", i))
+		}
+		if _, ok := inputs[n.ID]; ok {
+			return nil, Invalid(fmt.Errorf("node %q redefines an input", n.ID))
+		}
+		if _, ok := index[n.ID]; ok {
+			return nil, Invalid(fmt.Errorf("node %q is defined twice", n.ID))
+		}
+		if n.Op == "" {
+			return nil, Invalid(fmt.Errorf("node %q has no op", n.ID))
+		}
+		index[n.ID] = i
+	}
+	waiting, dependents, err := g.dependencies(inputs, index)
+	if err != nil {
+		return nil, err
+	}
+	for name, ref := range g.Outputs {
+		if _, ok := inputs[ref]; !ok {
+			if _, ok := index[ref]; !ok {
+				return nil, Invalid(fmt.Errorf("output %q refers to undefined %q", name, ref))
+			}
+		}
+	}
+
+	// Kahn's algorithm, taking the earliest listed ready node each time so
+	// a graph already in evaluation order keeps it.
+	order := make([]int, 0, len(g.Nodes))
+	var ready []int
+	for i := range g.Nodes {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+	for len(ready) > 0 {
+		k := slices.Index(ready, slices.Min(ready))
+		i := ready[k]
+		ready = slices.Delete(ready, k, k+1)
+		order = append(order, i)
+		for _, j := range dependents[i] {
+			if waiting[j]--; waiting[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	if len(order) < len(g.Nodes) {
+		for i, n := range g.Nodes {
+			if waiting[i] > 0 {
+				return nil, Invalid(fmt.Errorf("node %q is part of a cycle", n.ID))
+			}
+		}
+	}
+	return order, nil
+}
+
+// dependencies checks that every operand of g is an input or a node and
+// returns, per node, the number of operands that are nodes and the nodes
+// that take it as an operand.
+func (g *Graph) dependencies(inputs map[string]Value, index map[string]int) (waiting []int, dependents [][]int, err error) {
+	waiting = make([]int, len(g.Nodes))
+	dependents = make([][]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		for _, arg := range n.Args {
+			if j, ok := index[arg]; ok {
+				waiting[i]++
+				dependents[j] = append(dependents[j], i)
+			} else if _, ok := inputs[arg]; !ok {
+				return nil, nil, Invalid(fmt.Errorf("node %q refers to undefined %q", n.ID, arg))
+			}
+		}
+	}
+	return waiting, dependents, nil
+}
+
+// Evaluate validates g and runs its nodes in order, returning the outputs.
+func Evaluate(ctx context.Context, g *Graph, inputs map[string]Value, ops Ops) (map[string]Value, error) {
+	return EvaluateParallel(ctx, g, inputs, ops, 1)
+}
+
+// EvaluateParallel is Evaluate running up to workers independent nodes at a
+// time: a node starts as soon as the nodes it refers to have finished. Ops
+// must then be safe for concurrent use. The first failing node stops the
+// evaluation; nodes already running finish first.
+func EvaluateParallel(ctx context.Context, g *Graph, inputs map[string]Value, ops Ops, workers int) (map[string]Value, error) {
+	order, err := g.Order(inputs)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]Value, len(inputs)+len(g.Nodes))
+	for name, v := range inputs {
+		values[name] = v
+	}
+	if workers <= 1 {
+		err = runSequential(ctx, g, order, values, ops)
+	} else {
+		err = runParallel(ctx, g, order, inputs, values, ops, workers)
+	}
+	if err != nil {
+		return nil, err
+	}
+	outputs := make(map[string]Value, len(g.Outputs))
+	for name, ref := range g.Outputs {
+		outputs[name] = values[ref]
+	}
+	return outputs, nil
+}
+
+func runSequential(ctx context.Context, g *Graph, order []int, values map[string]Value, ops Ops) error {
+	for _, i := range order {
+		n := g.Nodes[i]
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		out, err := ops.Apply(ctx, n.Op, n.args(values))
+		if err != nil {
+			return fmt.Errorf("node %q: %w", n.ID, err)
+		}
+		values[n.ID] = out
+	}
+	return nil
+}
+
+// runParallel schedules nodes by their dependencies. Of the nodes ready to
+// run, those heading the longest chains of dependents start first, so wide
+// circuits do not leave workers idle behind a long critical path. Only this
+// goroutine touches values; workers get their operands and return their
+// result.
+func runParallel(ctx context.Context, g *Graph, order []int, inputs, values map[string]Value, ops Ops, workers int) error {
+	index := make(map[string]int, len(g.Nodes))
+	for i, n := range g.Nodes {
+		index[n.ID] = i
+	}
+	// waiting counts each node's operands still being computed; dependents
+	// lists, per node, the nodes that take it as an operand.
+	waiting, dependents, err := g.dependencies(inputs, index)
+	if err != nil {
+		return err
+	}
+	// depth is the number of nodes on the longest chain from a node to an
+	// output, found by walking order backwards.
+	depth := make([]int, len(g.Nodes))
+	for k := len(order) - 1; k >= 0; k-- {
+		i := order[k]
+		depth[i] = 1
+		for _, j := range dependents[i] {
+			depth[i] = max(depth[i], depth[j]+1)
+		}
+	}
+	var ready []int
+	for _, i := range order {
+		if waiting[i] == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	type result struct {
+		node int
+		out  Value
+		err  error
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	results := make(chan result)
+	running := 0
+	var firstErr error
+	for done := 0; done < len(g.Nodes); {
+		for firstErr == nil && running < workers && len(ready) > 0 {
+			if err := ctx.Err(); err != nil {
+				firstErr = err
+				break
+			}
+			k := 0
+			for m, i := range ready {
+				if depth[i] > depth[ready[k]] {
+					k = m
+				}
+			}
+			i := ready[k]
+			ready = slices.Delete(ready, k, k+1)
+			n := g.Nodes[i]
+			args := n.args(values)
+			running++
+			go func() {
+				out, err := ops.Apply(ctx, n.Op, args)
+				results <- result{node: i, out: out, err: err}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+		r := <-results
+		running--
+		done++
+		if r.err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("node %q: %w", g.Nodes[r.node].ID, r.err)
+				cancel()
+			}
+			continue
+		}
+		values[g.Nodes[r.node].ID] = r.out
+		for _, j := range dependents[r.node] {
+			if waiting[j]--; waiting[j] == 0 {
+				ready = append(ready, j)
+			}
+		}
+	}
+	return firstErr
+}
+
+// args looks up n's operands.
+func (n Node) args(values map[string]Value) []Value {
+	args := make([]Value, len(n.Args))
+	for i, arg := range n.Args {
+		args[i] = values[arg]
+	}
+	return args
+}
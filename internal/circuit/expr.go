@@ -0,0 +1,233 @@
+package circuit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Parse compiles an expression program into a Graph. A program is a list of
+// assignments separated by semicolons or newlines, each of which becomes an
+// output; later assignments may use earlier ones:
+//
+//	sum = a + b
+//	max = a > b ? a : b
+//
+// Operators, from lowest to highest precedence: c ? x : y, |, ^, &, the
+// comparisons == != < <= > >=, +, and unary !. Parentheses group.
+func Parse(src string) (*Graph, error) {
+	toks, err := tokenize(src)
+	if err != nil {
+		return nil, Invalid(err)
+	}
+	p := &parser{toks: toks, scope: make(map[string]string), g: &Graph{Outputs: make(map[string]string)}}
+	if err := p.program(); err != nil {
+		return nil, Invalid(err)
+	}
+	return p.g, nil
+}
+
+type token struct {
+	kind string // "ident", "op" or "end"
+	text string
+	pos  int
+}
+
+func tokenize(src string) ([]token, error) {
+	var toks []token
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case c == '\n' || c == ';':
+			toks = append(toks, token{kind: "end", text: ";", pos: i})
+			i++
+		case unicode.IsSpace(c):
+			i++
+		case c == '_' || unicode.IsLetter(c):
+			j := i + 1
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			toks = append(toks, token{kind: "ident", text: src[i:j], pos: i})
+			i = j
+		default:
+			op := ""
+			for _, cand := range []string{"==", "!=", "<=", ">=", "<", ">", "+", "&", "|", "^", "!", "?", ":", "(", ")", "="} {
+				if strings.HasPrefix(src[i:], cand) {
+					op = cand
+					break
+				}
+			}
+			if op == "" {
+				return nil, fmt.Errorf("unexpected %q at offset %d", c, i)
+			}
+			toks = append(toks, token{kind: "op", text: op, pos: i})
+			i += len(op)
+		}
+	}
+	return append(toks, token{kind: "end", pos: len(src)}), nil
+}
+
+type parser struct {
+	toks  []token
+	pos   int
+	scope map[string]string // assigned name -> node or input it refers to
+	g     *Graph
+}
+
+func (p *parser) peek() token { return p.toks[p.pos] }
+
+func (p *parser) next() token {
+	t := p.toks[p.pos]
+	if p.pos < len(p.toks)-1 {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) accept(op string) bool {
+	if t := p.peek(); t.kind == "op" && t.text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	if !p.accept(op) {
+		return p.unexpected()
+	}
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	t := p.peek()
+	if t.kind == "end" && t.text == "" {
+		return fmt.Errorf("unexpected end of expression")
+	}
+	return fmt.Errorf("unexpected %q at offset %d", t.text, t.pos)
+}
+
+func (p *parser) atEOF() bool {
+	t := p.peek()
+	return t.kind == "end" && t.text == ""
+}
+
+func (p *parser) program() error {
+	for !p.atEOF() {
+		if p.peek().kind == "end" {
+			p.next()
+			continue
+		}
+		name := p.next()
+		if name.kind != "ident" {
+			return fmt.Errorf("expected assignment at offset %d", name.pos)
+		}
+		if err := p.expect("="); err != nil {
+			return err
+		}
+		ref, err := p.ternary()
+		if err != nil {
+			return err
+		}
+		if t := p.peek(); t.kind != "end" {
+			return p.unexpected()
+		}
+		p.scope[name.text] = ref
+		p.g.Outputs[name.text] = ref
+	}
+	if len(p.g.Outputs) == 0 {
+		return fmt.Errorf("empty expression")
+	}
+	return nil
+}
+
+// node appends an operation and returns its generated id; ids contain '%',
+// so they never collide with identifiers.
+func (p *parser) node(op string, args ...string) (string, error) {
+	if len(p.g.Nodes) >= MaxNodes {
+		return "", fmt.Errorf("expression exceeds %d operations", MaxNodes)
+	}
+	id := "%" + strconv.Itoa(len(p.g.Nodes)+1)
+	p.g.Nodes = append(p.g.Nodes, Node{ID: id, Op: op, Args: args})
+	return id, nil
+}
+
+func (p *parser) ternary() (string, error) {
+	cond, err := p.binary(0)
+	if err != nil || !p.accept("?") {
+		return cond, err
+	}
+	then, err := p.ternary()
+	if err != nil {
+		return "", err
+	}
+	if err := p.expect(":"); err != nil {
+		return "", err
+	}
+	els, err := p.ternary()
+	if err != nil {
+		return "", err
+	}
+	return p.node("if_then_else", cond, then, els)
+}
+
+// levels lists binary operators by increasing precedence.
+var levels = []map[string]string{
+	{"|": "or"},
+	{"^": "xor"},
+	{"&": "and"},
+	{"==": "eq", "!=": "ne", "<": "lt", "<=": "le", ">": "gt", ">=": "ge"},
+	{"+": "add"},
+}
+
+func (p *parser) binary(level int) (string, error) {
+	if level == len(levels) {
+		return p.unary()
+	}
+	lhs, err := p.binary(level + 1)
+	if err != nil {
+		return "", err
+	}
+	for {
+		t := p.peek()
+		op, ok := levels[level][t.text]
+		if t.kind != "op" || !ok {
+			return lhs, nil
+		}
+		p.next()
+		rhs, err := p.binary(level + 1)
+		if err != nil {
+			return "", err
+		}
+		if lhs, err = p.node(op, lhs, rhs); err != nil {
+			return "", err
+		}
+	}
+}
+
+func (p *parser) unary() (string, error) {
+	if p.accept("!") {
+		x, err := p.unary()
+		if err != nil {
+			return "", err
+		}
+		return p.node("not", x)
+	}
+	if p.accept("(") {
+		x, err := p.ternary()
+		if err != nil {
+			return "", err
+		}
+		return x, p.expect(")")
+	}
+	if p.peek().kind != "ident" {
+		return "", p.unexpected()
+	}
+	t := p.next()
+	if ref, ok := p.scope[t.text]; ok {
+		return ref, nil
+	}
+	return t.text, nil
+}
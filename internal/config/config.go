@@ -0,0 +1,686 @@
+// Package config loads the server's YAML configuration file. Every setting
+// mirrors a TFHE_* environment variable, and the file only supplies values
+// for variables that are not already set, so the precedence is flags, then
+// environment, then file, then built-in defaults.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"tfhe-go/internal/features"
+)
+
+// EnvPath names the environment variable holding the config file path.
+const EnvPath = "TFHE_CONFIG"
+
+// ParameterSets lists the parameter sets keys can be generated with.
+var ParameterSets = []string{"default"}
+
+// StorageBackends lists the supported ciphertext store backends.
+var StorageBackends = []string{"memory", "s3", "postgres"}
+
+// S3Encryptions lists the accepted storage.s3.sse values.
+var S3Encryptions = []string{"s3", "kms", "c"}
+
+// Config is the file layout. Omitted settings keep their defaults.
+type Config struct {
+	Server      Server      `yaml:"server"`
+	TLS         TLS         `yaml:"tls"`
+	Auth        Auth        `yaml:"auth"`
+	Keys        Keys        `yaml:"keys"`
+	Limits      Limits      `yaml:"limits"`
+	Storage     Storage     `yaml:"storage"`
+	Postgres    Postgres    `yaml:"postgres"`
+	Vault       Vault       `yaml:"vault"`
+	Metrics     Metrics     `yaml:"metrics"`
+	Debug       Debug       `yaml:"debug"`
+	CORS        CORS        `yaml:"cors"`
+	Compression Compression `yaml:"compression"`
+	Idempotency Idempotency `yaml:"idempotency"`
+	Quotas      Quotas      `yaml:"quotas"`
+	Queue       Queue       `yaml:"queue"`
+	Estimate    Estimate    `yaml:"estimate"`
+	Audit       Audit       `yaml:"audit"`
+	Sessions    Sessions    `yaml:"sessions"`
+	Replay      Replay      `yaml:"replay"`
+	// Features maps a route family to on, off or admin; omitted families
+	// are on.
+	Features map[string]string `yaml:"features"`
+}
+
+// Server configures the listeners.
+type Server struct {
+	Addr              string         `yaml:"addr"`
+	GRPCAddr          string         `yaml:"grpc_addr"`
+	ReadHeaderTimeout *time.Duration `yaml:"read_header_timeout"`
+	ReadTimeout       *time.Duration `yaml:"read_timeout"`
+	WriteTimeout      *time.Duration `yaml:"write_timeout"`
+	IdleTimeout       *time.Duration `yaml:"idle_timeout"`
+	MaxHeaderBytes    *int           `yaml:"max_header_bytes"`
+	ShutdownGrace     *time.Duration `yaml:"shutdown_grace"`
+	Workers           *int           `yaml:"workers"`
+	SelfTestInterval  *time.Duration `yaml:"self_test_interval"`
+	SwaggerUI         *bool          `yaml:"swagger_ui"`
+	// ServerTiming reports per-request timing in a Server-Timing header.
+	ServerTiming *bool `yaml:"server_timing"`
+}
+
+// TLS configures TLS on both listeners.
+type TLS struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	HTTP2    *bool  `yaml:"http2"`
+}
+
+// Auth configures API keys, JWT validation and admin access.
+type Auth struct {
+	APIKeysFile string   `yaml:"api_keys_file"`
+	APIKeys     []string `yaml:"api_keys"`
+	AdminIDs    []string `yaml:"admin_ids"`
+	JWT         JWT      `yaml:"jwt"`
+}
+
+// JWT configures bearer token validation.
+type JWT struct {
+	Issuer      string `yaml:"issuer"`
+	Audience    string `yaml:"audience"`
+	JWKSURL     string `yaml:"jwks_url"`
+	TenantClaim string `yaml:"tenant_claim"`
+	KeyIDClaim  string `yaml:"key_id_claim"`
+}
+
+// Keys configures key generation.
+type Keys struct {
+	ParameterSet string `yaml:"parameter_set"`
+	// Custody is "" or "vault"; see Vault.
+	Custody        string `yaml:"custody"`
+	TrustedDecrypt *bool  `yaml:"trusted_decrypt"`
+	// TrustedReencrypt allows re-encryption under a caller's compact public
+	// key, which decrypts each result on this server.
+	TrustedReencrypt *bool `yaml:"trusted_reencrypt"`
+	// KMS is "" or "aws": persisted keys are encrypted under data keys
+	// wrapped by KMSKeyID.
+	KMS      string `yaml:"kms"`
+	KMSKeyID string `yaml:"kms_key_id"`
+	// KMSMigratePlaintext seals key sets found stored in plaintext instead
+	// of refusing them.
+	KMSMigratePlaintext *bool `yaml:"kms_migrate_plaintext"`
+}
+
+// Vault configures client key custody in Vault. The address and token come
+// from the standard VAULT_* variables.
+type Vault struct {
+	KVMount      string `yaml:"kv_mount"`
+	Prefix       string `yaml:"prefix"`
+	TransitMount string `yaml:"transit_mount"`
+	TransitKey   string `yaml:"transit_key"`
+	Auth         string `yaml:"auth"`
+	Role         string `yaml:"role"`
+	SecretIDFile string `yaml:"secret_id_file"`
+	AuthMount    string `yaml:"auth_mount"`
+}
+
+// Limits configures request and resource limits.
+type Limits struct {
+	RateLimit        *float64 `yaml:"rate_limit"`
+	RateBurst        *int     `yaml:"rate_burst"`
+	ReadyMaxInFlight *int     `yaml:"ready_max_inflight"`
+	// ReadyMaxNativeMemory is the native memory, in bytes, at which the
+	// service reports not ready.
+	ReadyMaxNativeMemory *int `yaml:"ready_max_native_memory"`
+	// OperandCache is how many deserialized operands are kept for reuse.
+	OperandCache *int `yaml:"operand_cache"`
+	// ExpansionCache is how many bytes of expanded compact lists are kept
+	// for reuse, ExpansionCacheTenant how many of them one tenant may hold.
+	ExpansionCache       *int `yaml:"expansion_cache"`
+	ExpansionCacheTenant *int `yaml:"expansion_cache_tenant"`
+	// OpTimeout is how long a request waits for one homomorphic
+	// evaluation; SlowOp is the duration from which evaluations are logged.
+	OpTimeout *time.Duration `yaml:"op_timeout"`
+	SlowOp    *time.Duration `yaml:"slow_op"`
+	// ComputeLimit bounds the native calls running at once,
+	// ComputeLimitTenant those of one tenant; ComputeQueueTimeout is how
+	// long a call queues for a slot.
+	ComputeLimit        *int           `yaml:"compute_limit"`
+	ComputeLimitTenant  *int           `yaml:"compute_limit_tenant"`
+	ComputeQueueTimeout *time.Duration `yaml:"compute_queue_timeout"`
+	// RejectTrivial fails evaluations whose result is a trivial encryption.
+	RejectTrivial *bool `yaml:"reject_trivial"`
+	// IsolateWorkers is how many worker processes deserialize client bytes
+	// before the server does.
+	IsolateWorkers *int `yaml:"isolate_workers"`
+	// MemoryBytes caps the estimated native memory of live objects.
+	MemoryBytes int64 `yaml:"memory_bytes"`
+	// Ciphertext maps a ciphertext type to its largest accepted serialized
+	// size in bytes.
+	Ciphertext map[string]int `yaml:"ciphertext_bytes"`
+}
+
+// Storage selects the ciphertext store.
+type Storage struct {
+	Backend string `yaml:"backend"`
+	Memory  Memory `yaml:"memory"`
+	S3      S3     `yaml:"s3"`
+}
+
+// Memory bounds the memory store; 0 leaves a bound unlimited.
+type Memory struct {
+	// MaxBytes and MaxEntries evict the least recently used handles; TTL
+	// evicts handles not read for that long.
+	MaxBytes   *int           `yaml:"max_bytes"`
+	MaxEntries *int           `yaml:"max_entries"`
+	TTL        *time.Duration `yaml:"ttl"`
+}
+
+// S3 configures the S3 store. Credentials come from the AWS_* or MINIO_*
+// environment variables or the instance role, never from the file.
+type S3 struct {
+	Endpoint string `yaml:"endpoint"`
+	Bucket   string `yaml:"bucket"`
+	Prefix   string `yaml:"prefix"`
+	Region   string `yaml:"region"`
+	Insecure *bool  `yaml:"insecure"`
+	// SSE is "", "s3", "kms" or "c"; see store.S3Options.
+	SSE         string         `yaml:"sse"`
+	KMSKeyID    string         `yaml:"kms_key_id"`
+	SSECKeyFile string         `yaml:"sse_c_key_file"`
+	Timeout     *time.Duration `yaml:"timeout"`
+}
+
+// Postgres configures the database persisting key sets and, with the
+// postgres storage backend, ciphertext handles.
+type Postgres struct {
+	// DSN is a postgres:// URL; leave the password out and set PGPASSWORD.
+	DSN string `yaml:"dsn"`
+}
+
+// Metrics configures the Prometheus listener.
+type Metrics struct {
+	Addr string `yaml:"addr"`
+}
+
+// Debug configures the loopback-only pprof and expvar listener.
+type Debug struct {
+	Addr string `yaml:"addr"`
+}
+
+// CORS configures cross-origin access.
+type CORS struct {
+	Origins     []string `yaml:"origins"`
+	Methods     []string `yaml:"methods"`
+	Headers     []string `yaml:"headers"`
+	Credentials *bool    `yaml:"credentials"`
+}
+
+// Compression configures Content-Encoding negotiation.
+type Compression struct {
+	Enabled *bool `yaml:"enabled"`
+	MinSize *int  `yaml:"min_size"`
+}
+
+// Idempotency configures Idempotency-Key replay.
+type Idempotency struct {
+	TTL *time.Duration `yaml:"ttl"`
+}
+
+// Quotas configures the per-tenant daily and monthly quotas.
+type Quotas struct {
+	Daily   Quota `yaml:"daily"`
+	Monthly Quota `yaml:"monthly"`
+}
+
+// Queue configures the message queue worker.
+type Queue struct {
+	// Backend is "" (off) or "nats".
+	Backend  string `yaml:"backend"`
+	URL      string `yaml:"url"`
+	Requests string `yaml:"requests"`
+	Results  string `yaml:"results"`
+	Group    string `yaml:"group"`
+}
+
+// Estimate configures the calibration behind /estimate.
+type Estimate struct {
+	Profile   string `yaml:"profile"`
+	Calibrate *int   `yaml:"calibrate"`
+}
+
+// Audit configures the audit log.
+type Audit struct {
+	// Sink is a file path, syslog, syslog://host:port,
+	// syslog+tcp://host:port or kafka+http(s)://proxy/topic.
+	Sink    string `yaml:"sink"`
+	KeyFile string `yaml:"key_file"`
+}
+
+// Sessions configures the short-lived session key sets.
+type Sessions struct {
+	// Limit caps the open sessions of one tenant; 0 disables sessions.
+	Limit  *int           `yaml:"limit"`
+	TTL    *time.Duration `yaml:"ttl"`
+	MaxTTL *time.Duration `yaml:"max_ttl"`
+}
+
+// Replay configures replay protection for mutating requests.
+type Replay struct {
+	// KeyFile holds the key clients sign requests with; empty disables
+	// replay protection.
+	KeyFile string         `yaml:"key_file"`
+	Window  *time.Duration `yaml:"window"`
+}
+
+// Quota caps one period's usage; omitted limits are unlimited. Bytes takes
+// a count or a KiB, MiB, GiB or TiB suffix.
+type Quota struct {
+	Operations int64         `yaml:"operations"`
+	Compute    time.Duration `yaml:"compute"`
+	Bytes      string        `yaml:"bytes"`
+}
+
+// spec formats q in the -quota-daily flag syntax.
+func (q Quota) spec() string {
+	var parts []string
+	if q.Operations > 0 {
+		parts = append(parts, "operations="+strconv.FormatInt(q.Operations, 10))
+	}
+	if q.Compute > 0 {
+		parts = append(parts, "compute="+q.Compute.String())
+	}
+	if q.Bytes != "" {
+		parts = append(parts, "bytes="+q.Bytes)
+	}
+	return strings.Join(parts, ",")
+}
+
+var byteSize = regexp.MustCompile(`^[0-9]+ ?(KiB|MiB|GiB|TiB)?$`)
+
+// CiphertextTypes lists the keys accepted in limits.ciphertext_bytes.
+var CiphertextTypes = []string{"boolean", "bool", "uint2", "uint4", "uint8", "uint16", "uint32", "uint64"}
+
+// PathFromArgs returns the value of a -config flag in args, or of
+// TFHE_CONFIG when there is none. The file must be loaded before the other
+// flags are defined, since their defaults come from the environment.
+func PathFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if !strings.HasPrefix(arg, "-") || name != "config" {
+			continue
+		}
+		if hasValue {
+			return value
+		}
+		if i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return os.Getenv(EnvPath)
+}
+
+// Load reads and validates the file at path.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes and validates a YAML configuration. Unknown settings are
+// errors, so typos do not silently fall back to defaults.
+func Parse(r io.Reader) (*Config, error) {
+	var cfg Config
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate reports every invalid setting, each prefixed with its path.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(setting, format string, args ...any) {
+		errs = append(errs, fmt.Errorf("%s: "+format, append([]any{setting}, args...)...))
+	}
+	for setting, addr := range map[string]string{"server.addr": c.Server.Addr, "server.grpc_addr": c.Server.GRPCAddr, "metrics.addr": c.Metrics.Addr, "debug.addr": c.Debug.Addr} {
+		if addr == "" {
+			continue
+		}
+		if _, port, err := net.SplitHostPort(addr); err != nil {
+			fail(setting, "%v (want host:port or :port)", err)
+		} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+			fail(setting, "invalid port %q", port)
+		}
+	}
+	for setting, d := range map[string]*time.Duration{
+		"server.read_header_timeout":   c.Server.ReadHeaderTimeout,
+		"server.read_timeout":          c.Server.ReadTimeout,
+		"server.write_timeout":         c.Server.WriteTimeout,
+		"server.idle_timeout":          c.Server.IdleTimeout,
+		"server.shutdown_grace":        c.Server.ShutdownGrace,
+		"idempotency.ttl":              c.Idempotency.TTL,
+		"storage.s3.timeout":           c.Storage.S3.Timeout,
+		"storage.memory.ttl":           c.Storage.Memory.TTL,
+		"limits.op_timeout":            c.Limits.OpTimeout,
+		"limits.slow_op":               c.Limits.SlowOp,
+		"limits.compute_queue_timeout": c.Limits.ComputeQueueTimeout,
+		"sessions.ttl":                 c.Sessions.TTL,
+		"sessions.max_ttl":             c.Sessions.MaxTTL,
+		"replay.window":                c.Replay.Window,
+	} {
+		if d != nil && *d < 0 {
+			fail(setting, "must not be negative")
+		}
+	}
+	if d := c.Server.SelfTestInterval; d != nil && *d <= 0 {
+		fail("server.self_test_interval", "must be positive")
+	}
+	for setting, n := range map[string]*int{
+		"server.max_header_bytes":        c.Server.MaxHeaderBytes,
+		"server.workers":                 c.Server.Workers,
+		"limits.rate_burst":              c.Limits.RateBurst,
+		"limits.ready_max_inflight":      c.Limits.ReadyMaxInFlight,
+		"limits.ready_max_native_memory": c.Limits.ReadyMaxNativeMemory,
+		"limits.operand_cache":           c.Limits.OperandCache,
+		"limits.expansion_cache":         c.Limits.ExpansionCache,
+		"limits.expansion_cache_tenant":  c.Limits.ExpansionCacheTenant,
+		"limits.compute_limit":           c.Limits.ComputeLimit,
+		"limits.compute_limit_tenant":    c.Limits.ComputeLimitTenant,
+		"limits.isolate_workers":         c.Limits.IsolateWorkers,
+		"compression.min_size":           c.Compression.MinSize,
+		"estimate.calibrate":             c.Estimate.Calibrate,
+		"sessions.limit":                 c.Sessions.Limit,
+		"storage.memory.max_bytes":       c.Storage.Memory.MaxBytes,
+		"storage.memory.max_entries":     c.Storage.Memory.MaxEntries,
+	} {
+		if n != nil && *n < 0 {
+			fail(setting, "must not be negative")
+		}
+	}
+	if c.Server.Workers != nil && *c.Server.Workers == 0 {
+		fail("server.workers", "must be at least 1")
+	}
+	if c.Limits.RateLimit != nil && *c.Limits.RateLimit < 0 {
+		fail("limits.rate_limit", "must not be negative")
+	}
+	if c.Limits.MemoryBytes < 0 {
+		fail("limits.memory_bytes", "must not be negative")
+	}
+	for typ, n := range c.Limits.Ciphertext {
+		if !slices.Contains(CiphertextTypes, typ) {
+			fail("limits.ciphertext_bytes."+typ, "unknown ciphertext type (want one of %s)", strings.Join(CiphertextTypes, ", "))
+		} else if n <= 0 {
+			fail("limits.ciphertext_bytes."+typ, "must be positive")
+		}
+	}
+	for period, q := range map[string]Quota{"quotas.daily": c.Quotas.Daily, "quotas.monthly": c.Quotas.Monthly} {
+		if q.Operations < 0 {
+			fail(period+".operations", "must not be negative")
+		}
+		if q.Compute < 0 {
+			fail(period+".compute", "must not be negative")
+		}
+		if q.Bytes != "" && !byteSize.MatchString(q.Bytes) {
+			fail(period+".bytes", "invalid size %q (want e.g. 1048576 or 10GiB)", q.Bytes)
+		}
+	}
+	for name, access := range c.Features {
+		if err := features.Check(features.Feature(name), features.Access(access)); err != nil {
+			fail("features."+name, "%v", err)
+		}
+	}
+	if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+		fail("tls", "cert_file and key_file must be set together")
+	}
+	for setting, path := range map[string]string{"tls.cert_file": c.TLS.CertFile, "tls.key_file": c.TLS.KeyFile, "auth.api_keys_file": c.Auth.APIKeysFile, "storage.s3.sse_c_key_file": c.Storage.S3.SSECKeyFile, "vault.secret_id_file": c.Vault.SecretIDFile} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); err != nil {
+			fail(setting, "%v", err)
+		}
+	}
+	if c.Auth.JWT.Issuer != "" && c.Auth.JWT.JWKSURL == "" {
+		fail("auth.jwt.jwks_url", "is required with auth.jwt.issuer")
+	}
+	if p := c.Keys.ParameterSet; p != "" && !slices.Contains(ParameterSets, p) {
+		fail("keys.parameter_set", "unsupported parameter set %q (want one of %s)", p, strings.Join(ParameterSets, ", "))
+	}
+	if b := c.Storage.Backend; b != "" && !slices.Contains(StorageBackends, b) {
+		fail("storage.backend", "unsupported backend %q (want one of %s)", b, strings.Join(StorageBackends, ", "))
+	}
+	switch c.Keys.Custody {
+	case "":
+	case "vault":
+		if c.Postgres.DSN == "" {
+			fail("keys.custody", "vault custody needs postgres.dsn to keep the server keys")
+		}
+		if a := c.Vault.Auth; a != "" && a != "token" && a != "approle" && a != "kubernetes" {
+			fail("vault.auth", "unsupported auth method %q (want token, approle or kubernetes)", a)
+		}
+		if c.Vault.Auth == "approle" && (c.Vault.Role == "" || c.Vault.SecretIDFile == "") {
+			fail("vault", "approle auth needs role and secret_id_file")
+		}
+	default:
+		fail("keys.custody", "unsupported custody %q (want vault)", c.Keys.Custody)
+	}
+	switch c.Keys.KMS {
+	case "":
+	case "aws":
+		if c.Postgres.DSN == "" {
+			fail("keys.kms", "kms encryption needs postgres.dsn to keep the keys")
+		}
+		if c.Keys.KMSKeyID == "" {
+			fail("keys.kms_key_id", "is required with kms %q", c.Keys.KMS)
+		}
+	default:
+		fail("keys.kms", "unsupported kms %q (want aws)", c.Keys.KMS)
+	}
+	if b := c.Queue.Backend; b != "" && b != "nats" {
+		fail("queue.backend", "unsupported queue %q (want nats)", b)
+	}
+	if c.Storage.Backend == "postgres" && c.Postgres.DSN == "" {
+		fail("postgres.dsn", "is required with the postgres storage backend")
+	}
+	if s3 := c.Storage.S3; c.Storage.Backend == "s3" {
+		if s3.Endpoint == "" {
+			fail("storage.s3.endpoint", "is required with the s3 backend")
+		}
+		if s3.Bucket == "" {
+			fail("storage.s3.bucket", "is required with the s3 backend")
+		}
+		switch {
+		case s3.SSE != "" && !slices.Contains(S3Encryptions, s3.SSE):
+			fail("storage.s3.sse", "unsupported encryption %q (want one of %s)", s3.SSE, strings.Join(S3Encryptions, ", "))
+		case s3.SSE == "kms" && s3.KMSKeyID == "":
+			fail("storage.s3.kms_key_id", "is required with sse: kms")
+		case s3.SSE == "c" && s3.SSECKeyFile == "":
+			fail("storage.s3.sse_c_key_file", "is required with sse: c")
+		}
+	}
+	if c.CORS.Credentials != nil && *c.CORS.Credentials && slices.Contains(c.CORS.Origins, "*") {
+		fail("cors.credentials", "cannot be combined with the \"*\" origin")
+	}
+	slices.SortFunc(errs, func(a, b error) int { return strings.Compare(a.Error(), b.Error()) })
+	return errors.Join(errs...)
+}
+
+// Env returns the environment variables equivalent to the file's settings.
+func (c *Config) Env() map[string]string {
+	env := make(map[string]string)
+	str := func(name, v string) {
+		if v != "" {
+			env[name] = v
+		}
+	}
+	list := func(name string, v []string) { str(name, strings.Join(v, ",")) }
+	dur := func(name string, v *time.Duration) {
+		if v != nil {
+			env[name] = v.String()
+		}
+	}
+	num := func(name string, v *int) {
+		if v != nil {
+			env[name] = strconv.Itoa(*v)
+		}
+	}
+	toggle := func(name string, v *bool, on, off string) {
+		if v != nil {
+			env[name] = off
+			if *v {
+				env[name] = on
+			}
+		}
+	}
+
+	str("TFHE_ADDR", c.Server.Addr)
+	str("TFHE_GRPC_ADDR", c.Server.GRPCAddr)
+	dur("TFHE_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout)
+	dur("TFHE_READ_TIMEOUT", c.Server.ReadTimeout)
+	dur("TFHE_WRITE_TIMEOUT", c.Server.WriteTimeout)
+	dur("TFHE_IDLE_TIMEOUT", c.Server.IdleTimeout)
+	num("TFHE_MAX_HEADER_BYTES", c.Server.MaxHeaderBytes)
+	dur("TFHE_SHUTDOWN_GRACE", c.Server.ShutdownGrace)
+	num("TFHE_WORKERS", c.Server.Workers)
+	dur("TFHE_SELF_TEST_INTERVAL", c.Server.SelfTestInterval)
+	toggle("TFHE_SWAGGER_UI", c.Server.SwaggerUI, "1", "")
+	toggle("TFHE_SERVER_TIMING", c.Server.ServerTiming, "1", "0")
+
+	str("TFHE_TLS_CERT_FILE", c.TLS.CertFile)
+	str("TFHE_TLS_KEY_FILE", c.TLS.KeyFile)
+	toggle("TFHE_HTTP2", c.TLS.HTTP2, "1", "0")
+
+	str("TFHE_API_KEYS_FILE", c.Auth.APIKeysFile)
+	list("TFHE_API_KEYS", c.Auth.APIKeys)
+	list("TFHE_ADMIN_IDS", c.Auth.AdminIDs)
+	str("TFHE_JWT_ISSUER", c.Auth.JWT.Issuer)
+	str("TFHE_JWT_AUDIENCE", c.Auth.JWT.Audience)
+	str("TFHE_JWT_JWKS_URL", c.Auth.JWT.JWKSURL)
+	str("TFHE_JWT_TENANT_CLAIM", c.Auth.JWT.TenantClaim)
+	str("TFHE_JWT_KEY_ID_CLAIM", c.Auth.JWT.KeyIDClaim)
+
+	if c.Limits.RateLimit != nil {
+		env["TFHE_RATE_LIMIT"] = strconv.FormatFloat(*c.Limits.RateLimit, 'g', -1, 64)
+	}
+	num("TFHE_RATE_BURST", c.Limits.RateBurst)
+	num("TFHE_READY_MAX_INFLIGHT", c.Limits.ReadyMaxInFlight)
+	num("TFHE_READY_MAX_NATIVE_MEMORY", c.Limits.ReadyMaxNativeMemory)
+	num("TFHE_OPERAND_CACHE", c.Limits.OperandCache)
+	num("TFHE_EXPANSION_CACHE", c.Limits.ExpansionCache)
+	num("TFHE_EXPANSION_CACHE_TENANT", c.Limits.ExpansionCacheTenant)
+	dur("TFHE_OP_TIMEOUT", c.Limits.OpTimeout)
+	dur("TFHE_SLOW_OP", c.Limits.SlowOp)
+	num("TFHE_COMPUTE_LIMIT", c.Limits.ComputeLimit)
+	num("TFHE_COMPUTE_LIMIT_TENANT", c.Limits.ComputeLimitTenant)
+	dur("TFHE_COMPUTE_QUEUE_TIMEOUT", c.Limits.ComputeQueueTimeout)
+	toggle("TFHE_REJECT_TRIVIAL", c.Limits.RejectTrivial, "1", "")
+	num("TFHE_ISOLATE_WORKERS", c.Limits.IsolateWorkers)
+
+	str("TFHE_STORAGE_BACKEND", c.Storage.Backend)
+	num("TFHE_MEMORY_STORE_MAX_BYTES", c.Storage.Memory.MaxBytes)
+	num("TFHE_MEMORY_STORE_MAX_ENTRIES", c.Storage.Memory.MaxEntries)
+	dur("TFHE_MEMORY_STORE_TTL", c.Storage.Memory.TTL)
+	str("TFHE_S3_ENDPOINT", c.Storage.S3.Endpoint)
+	str("TFHE_S3_BUCKET", c.Storage.S3.Bucket)
+	str("TFHE_S3_PREFIX", c.Storage.S3.Prefix)
+	str("TFHE_S3_REGION", c.Storage.S3.Region)
+	toggle("TFHE_S3_INSECURE", c.Storage.S3.Insecure, "1", "")
+	str("TFHE_S3_SSE", c.Storage.S3.SSE)
+	str("TFHE_S3_KMS_KEY_ID", c.Storage.S3.KMSKeyID)
+	str("TFHE_S3_SSE_C_KEY_FILE", c.Storage.S3.SSECKeyFile)
+	dur("TFHE_S3_TIMEOUT", c.Storage.S3.Timeout)
+	str("TFHE_POSTGRES_DSN", c.Postgres.DSN)
+	str("TFHE_KEY_CUSTODY", c.Keys.Custody)
+	toggle("TFHE_TRUSTED_DECRYPT", c.Keys.TrustedDecrypt, "1", "")
+	toggle("TFHE_TRUSTED_REENCRYPT", c.Keys.TrustedReencrypt, "1", "")
+	str("TFHE_KMS", c.Keys.KMS)
+	str("TFHE_KMS_KEY_ID", c.Keys.KMSKeyID)
+	toggle("TFHE_KMS_MIGRATE_PLAINTEXT", c.Keys.KMSMigratePlaintext, "1", "")
+	str("TFHE_VAULT_KV_MOUNT", c.Vault.KVMount)
+	str("TFHE_VAULT_PREFIX", c.Vault.Prefix)
+	str("TFHE_VAULT_TRANSIT_MOUNT", c.Vault.TransitMount)
+	str("TFHE_VAULT_TRANSIT_KEY", c.Vault.TransitKey)
+	str("TFHE_VAULT_AUTH", c.Vault.Auth)
+	str("TFHE_VAULT_ROLE", c.Vault.Role)
+	str("TFHE_VAULT_SECRET_ID_FILE", c.Vault.SecretIDFile)
+	str("TFHE_VAULT_AUTH_MOUNT", c.Vault.AuthMount)
+
+	str("TFHE_METRICS_ADDR", c.Metrics.Addr)
+	str("TFHE_DEBUG_ADDR", c.Debug.Addr)
+
+	list("TFHE_CORS_ORIGINS", c.CORS.Origins)
+	list("TFHE_CORS_METHODS", c.CORS.Methods)
+	list("TFHE_CORS_HEADERS", c.CORS.Headers)
+	toggle("TFHE_CORS_CREDENTIALS", c.CORS.Credentials, "1", "")
+
+	toggle("TFHE_COMPRESSION", c.Compression.Enabled, "1", "0")
+	num("TFHE_COMPRESS_MIN_SIZE", c.Compression.MinSize)
+	dur("TFHE_IDEMPOTENCY_TTL", c.Idempotency.TTL)
+	str("TFHE_QUOTA_DAILY", c.Quotas.Daily.spec())
+	str("TFHE_QUOTA_MONTHLY", c.Quotas.Monthly.spec())
+	str("TFHE_QUEUE", c.Queue.Backend)
+	str("TFHE_QUEUE_URL", c.Queue.URL)
+	str("TFHE_QUEUE_REQUESTS", c.Queue.Requests)
+	str("TFHE_QUEUE_RESULTS", c.Queue.Results)
+	str("TFHE_QUEUE_GROUP", c.Queue.Group)
+	str("TFHE_ESTIMATE_PROFILE", c.Estimate.Profile)
+	num("TFHE_ESTIMATE_CALIBRATE", c.Estimate.Calibrate)
+	str("TFHE_AUDIT_LOG", c.Audit.Sink)
+	str("TFHE_AUDIT_KEY_FILE", c.Audit.KeyFile)
+	num("TFHE_SESSION_LIMIT", c.Sessions.Limit)
+	dur("TFHE_SESSION_TTL", c.Sessions.TTL)
+	dur("TFHE_SESSION_MAX_TTL", c.Sessions.MaxTTL)
+	str("TFHE_REPLAY_KEY_FILE", c.Replay.KeyFile)
+	dur("TFHE_REPLAY_WINDOW", c.Replay.Window)
+	policy := make(features.Policy, len(c.Features))
+	for name, access := range c.Features {
+		policy[features.Feature(name)] = features.Access(access)
+	}
+	str("TFHE_FEATURES", policy.String())
+
+	// An empty value means "off" for these switches; drop it so the
+	// variable stays unset.
+	for name, v := range env {
+		if v == "" {
+			delete(env, name)
+		}
+	}
+	return env
+}
+
+// Apply exports the file's settings as environment variables, leaving any
+// variable that is already set untouched.
+func (c *Config) Apply() error {
+	for name, v := range c.Env() {
+		if _, set := os.LookupEnv(name); set {
+			continue
+		}
+		if err := os.Setenv(name, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package counters keeps named encrypted accumulators per tenant. Clients
+// submit encrypted increments that the server adds homomorphically, so
+// totals such as private telemetry can be aggregated without the server
+// seeing any contribution or total.
+package counters
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for a counter the tenant has not created.
+	ErrNotFound = errors.New("counter not found")
+	// ErrExists is returned when creating a counter under a taken name.
+	ErrExists = errors.New("counter already exists")
+	// ErrLimit is returned when a tenant already has MaxPerTenant counters.
+	ErrLimit = errors.New("too many counters")
+	// ErrInvalidName is returned for names outside [A-Za-z0-9._-]{1,128}.
+	ErrInvalidName = errors.New("invalid counter name")
+)
+
+// MaxPerTenant bounds how many counters one tenant may hold.
+const MaxPerTenant = 1000
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Counter is a snapshot of one accumulator: its encrypted total, the key
+// set and key generation it is encrypted under, and how many increments it
+// has absorbed.
+type Counter struct {
+	Name       string
+	Tenant     string
+	Type       string
+	KeySet     string
+	Generation uint64
+	Data       []byte
+	Increments int64
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+type key struct{ tenant, name string }
+
+type entry struct {
+	// update serializes updates of one counter, so concurrent increments
+	// are all applied; mu guards the snapshot only briefly, so reads do not
+	// wait for an addition in progress.
+	update  sync.Mutex
+	mu      sync.Mutex
+	c       Counter
+	deleted bool
+}
+
+// snapshot returns the counter unless it was deleted.
+func (e *entry) snapshot() (Counter, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.deleted {
+		return Counter{}, fmt.Errorf("%w: %s", ErrNotFound, e.c.Name)
+	}
+	return e.c, nil
+}
+
+// Registry holds the counters of every tenant in memory.
+type Registry struct {
+	mu       sync.RWMutex
+	counters map[key]*entry
+	tenants  map[string]int
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{counters: make(map[key]*entry), tenants: make(map[string]int)}
+}
+
+// Create adds c under c.Tenant and c.Name, stamping its times.
+func (r *Registry) Create(c Counter) (Counter, error) {
+	if !validName.MatchString(c.Name) {
+		return Counter{}, fmt.Errorf("%w %q", ErrInvalidName, c.Name)
+	}
+	now := time.Now().UTC()
+	c.CreatedAt, c.UpdatedAt, c.Increments = now, now, 0
+	c.Data = append([]byte(nil), c.Data...)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{c.Tenant, c.Name}
+	if _, ok := r.counters[k]; ok {
+		return Counter{}, fmt.Errorf("%w: %s", ErrExists, c.Name)
+	}
+	if r.tenants[c.Tenant] >= MaxPerTenant {
+		return Counter{}, fmt.Errorf("%w: a tenant may hold %d", ErrLimit, MaxPerTenant)
+	}
+	r.counters[k] = &entry{c: c}
+	r.tenants[c.Tenant]++
+	return c, nil
+}
+
+func (r *Registry) lookup(tenant, name string) (*entry, error) {
+	r.mu.RLock()
+	e, ok := r.counters[key{tenant, name}]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return e, nil
+}
+
+// Get returns a snapshot of tenant's counter name.
+func (r *Registry) Get(tenant, name string) (Counter, error) {
+	e, err := r.lookup(tenant, name)
+	if err != nil {
+		return Counter{}, err
+	}
+	return e.snapshot()
+}
+
+// Update replaces the total of tenant's counter name with what fn computes
+// from the current snapshot, counting n increments. Updates of one counter
+// run one at a time; an error from fn leaves the counter unchanged.
+func (r *Registry) Update(tenant, name string, n int64, fn func(Counter) ([]byte, error)) (Counter, error) {
+	e, err := r.lookup(tenant, name)
+	if err != nil {
+		return Counter{}, err
+	}
+	e.update.Lock()
+	defer e.update.Unlock()
+	c, err := e.snapshot()
+	if err != nil {
+		return Counter{}, err
+	}
+	data, err := fn(c)
+	if err != nil {
+		return Counter{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.deleted {
+		return Counter{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	e.c.Data = data
+	e.c.Increments += n
+	e.c.UpdatedAt = time.Now().UTC()
+	return e.c, nil
+}
+
+// Delete removes tenant's counter name.
+func (r *Registry) Delete(tenant, name string) error {
+	r.mu.Lock()
+	k := key{tenant, name}
+	e, ok := r.counters[k]
+	if ok {
+		delete(r.counters, k)
+		if r.tenants[tenant]--; r.tenants[tenant] == 0 {
+			delete(r.tenants, tenant)
+		}
+	}
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	// An update in flight finds the entry deleted and discards its result.
+	e.mu.Lock()
+	e.deleted = true
+	e.mu.Unlock()
+	return nil
+}
+
+// List returns snapshots of tenant's counters ordered by name, or of every
+// tenant's, ordered by tenant then name, when all is set.
+func (r *Registry) List(tenant string, all bool) []Counter {
+	r.mu.RLock()
+	var entries []*entry
+	for k, e := range r.counters {
+		if all || k.tenant == tenant {
+			entries = append(entries, e)
+		}
+	}
+	r.mu.RUnlock()
+
+	out := make([]Counter, 0, len(entries))
+	for _, e := range entries {
+		if c, err := e.snapshot(); err == nil {
+			out = append(out, c)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Tenant != out[j].Tenant {
+			return out[i].Tenant < out[j].Tenant
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,261 @@
+// Package elections keeps encrypted ballot boxes per tenant. Voters submit
+// one encrypted choice per candidate; the server adds each ballot to
+// encrypted per-candidate tallies, and only the totals of a closed election
+// are ever decrypted.
+package elections
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNotFound is returned for an election the tenant has not created.
+	ErrNotFound = errors.New("election not found")
+	// ErrExists is returned when creating an election under a taken name.
+	ErrExists = errors.New("election already exists")
+	// ErrLimit is returned when a tenant already has MaxPerTenant elections.
+	ErrLimit = errors.New("too many elections")
+	// ErrInvalidName is returned for names outside [A-Za-z0-9._-]{1,128}.
+	ErrInvalidName = errors.New("invalid election name")
+	// ErrInvalidCandidates is returned for a candidate list that is too
+	// short, too long or repeats a candidate.
+	ErrInvalidCandidates = errors.New("invalid candidates")
+	// ErrClosed is returned for a ballot cast in a closed election.
+	ErrClosed = errors.New("election is closed")
+	// ErrOpen is returned when the totals of an open election are asked for.
+	ErrOpen = errors.New("election is still open")
+	// ErrAlreadyVoted is returned for a second ballot from one voter.
+	ErrAlreadyVoted = errors.New("voter has already cast a ballot")
+)
+
+const (
+	// MaxPerTenant bounds how many elections one tenant may hold.
+	MaxPerTenant = 100
+	// MaxCandidates bounds the candidates of one election.
+	MaxCandidates = 64
+)
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Election is a snapshot of one election: its candidates, their encrypted
+// tallies in the same order, the key set and key generation the tallies are
+// encrypted under, and how many ballots were cast.
+type Election struct {
+	Name       string
+	Tenant     string
+	Candidates []string
+	KeySet     string
+	Generation uint64
+	Tallies    [][]byte
+	Ballots    int64
+	Closed     bool
+	CreatedAt  time.Time
+	ClosedAt   time.Time
+}
+
+type key struct{ tenant, name string }
+
+type entry struct {
+	// cast serializes ballots of one election, so concurrent ballots are
+	// all counted; mu guards the snapshot only briefly, so reads do not wait
+	// for a tally in progress.
+	cast    sync.Mutex
+	mu      sync.Mutex
+	e       Election
+	voters  map[string]bool
+	deleted bool
+}
+
+// snapshot returns the election unless it was deleted.
+func (en *entry) snapshot() (Election, error) {
+	en.mu.Lock()
+	defer en.mu.Unlock()
+	if en.deleted {
+		return Election{}, fmt.Errorf("%w: %s", ErrNotFound, en.e.Name)
+	}
+	return en.e, nil
+}
+
+// Registry holds the elections of every tenant in memory.
+type Registry struct {
+	mu        sync.RWMutex
+	elections map[key]*entry
+	tenants   map[string]int
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{elections: make(map[key]*entry), tenants: make(map[string]int)}
+}
+
+// Create adds e, open and without ballots, under e.Tenant and e.Name.
+// e.Tallies must hold one tally per candidate.
+func (r *Registry) Create(e Election) (Election, error) {
+	if !validName.MatchString(e.Name) {
+		return Election{}, fmt.Errorf("%w %q", ErrInvalidName, e.Name)
+	}
+	if len(e.Candidates) < 2 || len(e.Candidates) > MaxCandidates {
+		return Election{}, fmt.Errorf("%w: %d candidates, want 2 to %d", ErrInvalidCandidates, len(e.Candidates), MaxCandidates)
+	}
+	seen := make(map[string]bool, len(e.Candidates))
+	for _, c := range e.Candidates {
+		if c == "" || seen[c] {
+			return Election{}, fmt.Errorf("%w: empty or repeated candidate %q", ErrInvalidCandidates, c)
+		}
+		seen[c] = true
+	}
+	if len(e.Tallies) != len(e.Candidates) {
+		return Election{}, fmt.Errorf("%w: %d tallies for %d candidates", ErrInvalidCandidates, len(e.Tallies), len(e.Candidates))
+	}
+	e.Candidates = append([]string(nil), e.Candidates...)
+	e.Tallies = append([][]byte(nil), e.Tallies...)
+	e.CreatedAt, e.ClosedAt = time.Now().UTC(), time.Time{}
+	e.Ballots, e.Closed = 0, false
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{e.Tenant, e.Name}
+	if _, ok := r.elections[k]; ok {
+		return Election{}, fmt.Errorf("%w: %s", ErrExists, e.Name)
+	}
+	if r.tenants[e.Tenant] >= MaxPerTenant {
+		return Election{}, fmt.Errorf("%w: a tenant may hold %d", ErrLimit, MaxPerTenant)
+	}
+	r.elections[k] = &entry{e: e, voters: make(map[string]bool)}
+	r.tenants[e.Tenant]++
+	return e, nil
+}
+
+func (r *Registry) lookup(tenant, name string) (*entry, error) {
+	r.mu.RLock()
+	en, ok := r.elections[key{tenant, name}]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return en, nil
+}
+
+// Get returns a snapshot of tenant's election name.
+func (r *Registry) Get(tenant, name string) (Election, error) {
+	en, err := r.lookup(tenant, name)
+	if err != nil {
+		return Election{}, err
+	}
+	return en.snapshot()
+}
+
+// Cast replaces the tallies of tenant's election name with what fn computes
+// from the current snapshot and counts one ballot from voter. An empty
+// voter is anonymous and never refused as a repeat. Ballots of one election
+// are tallied one at a time; an error from fn leaves the election
+// unchanged and the voter free to try again.
+func (r *Registry) Cast(tenant, name, voter string, fn func(Election) ([][]byte, error)) (Election, error) {
+	en, err := r.lookup(tenant, name)
+	if err != nil {
+		return Election{}, err
+	}
+	en.cast.Lock()
+	defer en.cast.Unlock()
+	e, err := en.snapshot()
+	if err != nil {
+		return Election{}, err
+	}
+	if e.Closed {
+		return Election{}, fmt.Errorf("%w: %s", ErrClosed, name)
+	}
+	if voter != "" && en.voters[voter] {
+		return Election{}, fmt.Errorf("%w: %s", ErrAlreadyVoted, voter)
+	}
+	tallies, err := fn(e)
+	if err != nil {
+		return Election{}, err
+	}
+	en.mu.Lock()
+	defer en.mu.Unlock()
+	switch {
+	case en.deleted:
+		return Election{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	case en.e.Closed:
+		// Closed while the ballot was being tallied: the ballot missed it.
+		return Election{}, fmt.Errorf("%w: %s", ErrClosed, name)
+	}
+	if voter != "" {
+		en.voters[voter] = true
+	}
+	en.e.Tallies = tallies
+	en.e.Ballots++
+	return en.e, nil
+}
+
+// Close stops tenant's election name from taking ballots; closing a closed
+// election changes nothing.
+func (r *Registry) Close(tenant, name string) (Election, error) {
+	en, err := r.lookup(tenant, name)
+	if err != nil {
+		return Election{}, err
+	}
+	en.mu.Lock()
+	defer en.mu.Unlock()
+	if en.deleted {
+		return Election{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if !en.e.Closed {
+		en.e.Closed, en.e.ClosedAt = true, time.Now().UTC()
+	}
+	return en.e, nil
+}
+
+// Delete removes tenant's election name.
+func (r *Registry) Delete(tenant, name string) error {
+	r.mu.Lock()
+	k := key{tenant, name}
+	en, ok := r.elections[k]
+	if ok {
+		delete(r.elections, k)
+		if r.tenants[tenant]--; r.tenants[tenant] == 0 {
+			delete(r.tenants, tenant)
+		}
+	}
+	r.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	// A ballot in flight finds the entry deleted and discards its result.
+	en.mu.Lock()
+	en.deleted = true
+	en.mu.Unlock()
+	return nil
+}
+
+// List returns snapshots of tenant's elections ordered by name, or of every
+// tenant's, ordered by tenant then name, when all is set.
+func (r *Registry) List(tenant string, all bool) []Election {
+	r.mu.RLock()
+	var entries []*entry
+	for k, en := range r.elections {
+		if all || k.tenant == tenant {
+			entries = append(entries, en)
+		}
+	}
+	r.mu.RUnlock()
+
+	out := make([]Election, 0, len(entries))
+	for _, en := range entries {
+		if e, err := en.snapshot(); err == nil {
+			out = append(out, e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Tenant != out[j].Tenant {
+			return out[i].Tenant < out[j].Tenant
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out
+}
@@ -0,0 +1,350 @@
+// Package estimate predicts what operations and circuits cost before they
+// run: CPU time and ciphertext sizes, from per-operation benchmark results
+// calibrated for the active parameter set, so schedulers can budget and price
+// requests up front.
+package estimate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"tfhe-go/internal/bench"
+	"tfhe-go/internal/circuit"
+	"tfhe-go/internal/tfhe"
+)
+
+// MaxSteps bounds the steps of one pipeline estimate, and MaxCount the
+// repetitions of one step.
+const (
+	MaxSteps = 1024
+	MaxCount = 1 << 20
+)
+
+// ErrUncalibrated reports an operation the model has no cost for: one the
+// benchmarks do not cover, or one the service does not support.
+var ErrUncalibrated = errors.New("operation not calibrated")
+
+// ErrNotReady reports that no calibration has been loaded yet.
+var ErrNotReady = errors.New("cost estimates are not calibrated yet")
+
+// Model holds the measured cost of each operation under one parameter set.
+type Model struct {
+	ParameterSet string
+	// Calibrated is when the costs were measured or loaded.
+	Calibrated time.Time
+	costs      map[string]bench.Result // by case name, e.g. "uint8.add"
+}
+
+// New builds a model from benchmark results, keeping those measured under
+// params; results without a parameter set are taken to belong to it.
+func New(params string, results []bench.Result) (*Model, error) {
+	m := &Model{ParameterSet: params, Calibrated: time.Now(), costs: make(map[string]bench.Result)}
+	for _, r := range results {
+		if (r.ParameterSet == params || r.ParameterSet == "") && r.Iterations > 0 {
+			m.costs[r.Name] = r
+		}
+	}
+	if len(m.costs) == 0 {
+		return nil, fmt.Errorf("no benchmark results for parameter set %s", params)
+	}
+	return m, nil
+}
+
+// Load builds a model from a file of benchmark results written by
+// `tfhe bench -format json`.
+func Load(path, params string) (*Model, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	results, err := bench.ReadJSON(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	m, err := New(params, results)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if info, err := f.Stat(); err == nil {
+		m.Calibrated = info.ModTime()
+	}
+	return m, nil
+}
+
+// Calibrate measures every case other than key generation under params,
+// iterations times each, on this machine. Cases the backend does not support
+// are left out of the model.
+func Calibrate(params string, iterations int) (*Model, error) {
+	env, err := bench.NewEnv(params)
+	if err != nil {
+		return nil, err
+	}
+	defer env.Close()
+	var results []bench.Result
+	for _, c := range bench.Cases() {
+		if c.Keygen {
+			continue
+		}
+		r, err := bench.Measure(env, c, max(iterations, 1))
+		if errors.Is(err, tfhe.ErrUnsupported) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		r.ParameterSet = params
+		results = append(results, r)
+	}
+	return New(params, results)
+}
+
+// OpCost is the calibrated cost of one operation.
+type OpCost struct {
+	Op        string `json:"op"`
+	MeanNanos int64  `json:"mean_ns"`
+	P99Nanos  int64  `json:"p99_ns"`
+}
+
+// Costs lists the calibrated operations by name, and the serialized size
+// of each ciphertext type.
+func (m *Model) Costs() (ops []OpCost, sizes map[string]int) {
+	sizes = make(map[string]int)
+	for name, r := range m.costs {
+		if typ, ok := strings.CutSuffix(name, ".serialize"); ok {
+			sizes[typ] = r.Bytes
+			continue
+		}
+		ops = append(ops, OpCost{Op: name, MeanNanos: r.MeanNanos, P99Nanos: r.P99Nanos})
+	}
+	sort.Slice(ops, func(i, j int) bool { return ops[i].Op < ops[j].Op })
+	return ops, sizes
+}
+
+// Estimate is the predicted cost of a pipeline or circuit.
+type Estimate struct {
+	ParameterSet string `json:"parameter_set"`
+	// Ops is the number of operations run.
+	Ops int64 `json:"ops"`
+	// CPUNanos is the summed mean time of every operation, and
+	// CPUNanosP99 the sum of their 99th percentiles, a pessimistic budget.
+	CPUNanos    int64 `json:"cpu_ns"`
+	CPUNanosP99 int64 `json:"cpu_ns_p99"`
+	// WallNanos is the expected latency: for a circuit, the longer of its
+	// critical path and its CPU time spread over the workers; for a
+	// pipeline, its CPU time.
+	WallNanos int64 `json:"wall_ns"`
+	// InputBytes and OutputBytes are the serialized sizes of the circuit's
+	// inputs and of the ciphertexts returned.
+	InputBytes  int64 `json:"input_bytes,omitempty"`
+	OutputBytes int64 `json:"output_bytes"`
+	// Breakdown is the cost per operation, in first-use order.
+	Breakdown []Line `json:"breakdown"`
+	// Outputs are the circuit's outputs.
+	Outputs map[string]Output `json:"outputs,omitempty"`
+}
+
+// Line is the cost of the runs of one operation.
+type Line struct {
+	Op         string `json:"op"`
+	Count      int64  `json:"count"`
+	EachNanos  int64  `json:"each_ns"`
+	TotalNanos int64  `json:"total_ns"`
+}
+
+// Output is the predicted type and serialized size of a circuit output.
+type Output struct {
+	Type  string `json:"type"`
+	Bytes int64  `json:"bytes"`
+}
+
+// Step is an operation on ciphertexts of Type, such as uint8 add or uint16
+// lt, run Count times.
+type Step struct {
+	Op    string `json:"op"`
+	Type  string `json:"type"`
+	Count int64  `json:"count,omitempty"`
+}
+
+// Steps estimates a pipeline of steps run one after the other. A Count of 0
+// counts as 1.
+func (m *Model) Steps(steps []Step) (Estimate, error) {
+	if len(steps) == 0 || len(steps) > MaxSteps {
+		return Estimate{}, fmt.Errorf("%d steps, want 1 to %d", len(steps), MaxSteps)
+	}
+	est := m.newEstimate()
+	lines := make(map[string]int)
+	for i, s := range steps {
+		count := s.Count
+		if count == 0 {
+			count = 1
+		}
+		if count < 0 || count > MaxCount {
+			return Estimate{}, fmt.Errorf("step %d: count %d out of range 1 to %d", i, s.Count, MaxCount)
+		}
+		name, result := stepCase(s.Op, s.Type)
+		cost, ok := m.costs[name]
+		if !ok {
+			return Estimate{}, fmt.Errorf("step %d: %w: %s", i, ErrUncalibrated, name)
+		}
+		est.add(lines, name, cost, count)
+		if result != "" {
+			est.OutputBytes += count * int64(m.size(result))
+		}
+	}
+	est.WallNanos = est.CPUNanos
+	return est, nil
+}
+
+// stepCase names the benchmark case of op on typ and the type of ciphertext
+// it returns, "" for decryption.
+func stepCase(op, typ string) (name, result string) {
+	op = integerOp(op, typ)
+	switch {
+	case op == "decrypt":
+		return typ + "." + op, ""
+	case slices.Contains(tfhe.Comparisons, tfhe.Comparison(op)):
+		return typ + "." + op, "bool"
+	}
+	return typ + "." + op, typ
+}
+
+// Circuit estimates g, given as in circuit evaluation, over inputs of the
+// named types, with up to workers independent operations running at once.
+func (m *Model) Circuit(g *circuit.Graph, inputs map[string]string, workers int) (Estimate, error) {
+	values := make(map[string]circuit.Value, len(inputs))
+	for name, typ := range inputs {
+		values[name] = circuit.Value{Type: typ}
+	}
+	order, err := g.Order(values)
+	if err != nil {
+		return Estimate{}, err
+	}
+	est := m.newEstimate()
+	lines := make(map[string]int)
+	types := make(map[string]string, len(inputs)+len(g.Nodes))
+	finish := make(map[string]int64, len(g.Nodes))
+	for name, typ := range inputs {
+		types[name] = typ
+		est.InputBytes += int64(m.size(typ))
+	}
+	var critical int64
+	for _, k := range order {
+		n := g.Nodes[k]
+		args := make([]string, len(n.Args))
+		var start int64
+		for i, arg := range n.Args {
+			args[i] = types[arg]
+			start = max(start, finish[arg])
+		}
+		name, result, err := nodeCase(n.Op, args)
+		if err != nil {
+			return Estimate{}, fmt.Errorf("node %q: %w", n.ID, err)
+		}
+		cost, ok := m.costs[name]
+		if !ok {
+			return Estimate{}, fmt.Errorf("node %q: %w: %s", n.ID, ErrUncalibrated, name)
+		}
+		est.add(lines, name, cost, 1)
+		types[n.ID] = result
+		finish[n.ID] = start + cost.MeanNanos
+		critical = max(critical, finish[n.ID])
+	}
+	est.WallNanos = max(critical, est.CPUNanos/int64(max(workers, 1)))
+	est.Outputs = make(map[string]Output, len(g.Outputs))
+	for name, ref := range g.Outputs {
+		out := Output{Type: types[ref], Bytes: int64(m.size(types[ref]))}
+		est.Outputs[name] = out
+		est.OutputBytes += out.Bytes
+	}
+	return est, nil
+}
+
+// nodeCase names the benchmark case of a circuit node and the type it
+// returns, applying the typing rules of circuit evaluation.
+func nodeCase(op string, args []string) (name, result string, err error) {
+	if len(args) == 0 {
+		return "", "", circuit.Invalid(fmt.Errorf("op %q has no operands", op))
+	}
+	operands := args
+	if op == "if_then_else" {
+		if len(args) != 3 {
+			return "", "", circuit.Invalid(fmt.Errorf("op %q expects 3 operands, got %d", op, len(args)))
+		}
+		if args[0] != "bool" {
+			return "", "", circuit.Invalid(fmt.Errorf("if_then_else condition must be bool, got %s", args[0]))
+		}
+		operands = args[1:]
+	}
+	for _, typ := range operands[1:] {
+		if typ != operands[0] {
+			return "", "", circuit.Invalid(fmt.Errorf("operand types differ: %s and %s", operands[0], typ))
+		}
+	}
+	typ := operands[0]
+	if slices.Contains(tfhe.Comparisons, tfhe.Comparison(op)) {
+		return typ + "." + op, "bool", nil
+	}
+	return typ + "." + integerOp(op, typ), typ, nil
+}
+
+// integerOp maps the expression operators & and ^ to the bitwise
+// operations of integer types.
+func integerOp(op, typ string) string {
+	if typ == "boolean" {
+		return op
+	}
+	switch op {
+	case "and":
+		return "bitand"
+	case "xor":
+		return "bitxor"
+	}
+	return op
+}
+
+func (m *Model) newEstimate() Estimate {
+	return Estimate{ParameterSet: m.ParameterSet, Breakdown: []Line{}}
+}
+
+func (e *Estimate) add(lines map[string]int, name string, cost bench.Result, count int64) {
+	i, ok := lines[name]
+	if !ok {
+		i = len(e.Breakdown)
+		lines[name] = i
+		e.Breakdown = append(e.Breakdown, Line{Op: name, EachNanos: cost.MeanNanos})
+	}
+	e.Breakdown[i].Count += count
+	e.Breakdown[i].TotalNanos += count * cost.MeanNanos
+	e.Ops += count
+	e.CPUNanos += count * cost.MeanNanos
+	e.CPUNanosP99 += count * cost.P99Nanos
+}
+
+// size is the serialized size of a ciphertext of typ, 0 when not measured.
+func (m *Model) size(typ string) int {
+	return m.costs[typ+".serialize"].Bytes
+}
+
+// Estimator holds the model in use, which calibration may replace while
+// requests read it.
+type Estimator struct {
+	model atomic.Pointer[Model]
+}
+
+// Set makes m the model in use.
+func (e *Estimator) Set(m *Model) { e.model.Store(m) }
+
+// Model returns the model in use, or ErrNotReady before one is set.
+func (e *Estimator) Model() (*Model, error) {
+	if m := e.model.Load(); m != nil {
+		return m, nil
+	}
+	return nil, ErrNotReady
+}
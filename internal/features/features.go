@@ -0,0 +1,124 @@
+// Package features switches families of API routes off, or restricts them
+// to administrators, so a deployment serves only what it needs: a
+// compute-only server, for one, need not be a decryption oracle.
+package features
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Feature names a family of routes.
+type Feature string
+
+const (
+	// Decrypt covers the decrypt routes of every type, decryption of stored
+	// handles and the admin routes revealing counter totals and election
+	// results.
+	Decrypt Feature = "decrypt"
+	// Encrypt covers encryption under the client key.
+	Encrypt Feature = "encrypt"
+	// PublicEncrypt covers encryption under the public key.
+	PublicEncrypt Feature = "public_encrypt"
+	// Keys covers the public key exports and key set descriptions.
+	Keys Feature = "keys"
+	// Batch covers the batch routes and the WebSocket stream.
+	Batch Feature = "batch"
+	// Evaluate covers circuit evaluation.
+	Evaluate Feature = "evaluate"
+	// Ciphertexts covers the stored handles and re-encryption.
+	Ciphertexts Feature = "ciphertexts"
+	// KeySwitching covers tenant switch keys and proxy re-encryption of
+	// stored handles, which only the pure-Go backend implements.
+	KeySwitching Feature = "key_switching"
+	// Counters, Elections, Machines and Models cover the routes of those
+	// registries.
+	Counters  Feature = "counters"
+	Elections Feature = "elections"
+	Machines  Feature = "machines"
+	Models    Feature = "models"
+	// Sessions covers the short-lived session key sets.
+	Sessions Feature = "sessions"
+)
+
+// All lists every feature.
+var All = []Feature{Decrypt, Encrypt, PublicEncrypt, Keys, Batch, Evaluate, Ciphertexts, KeySwitching, Counters, Elections, Machines, Models, Sessions}
+
+// Access is who may use a feature.
+type Access string
+
+const (
+	// On serves the feature to every authenticated caller.
+	On Access = "on"
+	// Off does not serve the feature at all.
+	Off Access = "off"
+	// Admin serves the feature to administrators only.
+	Admin Access = "admin"
+)
+
+// Accesses lists the accepted access levels.
+var Accesses = []Access{On, Off, Admin}
+
+// Policy maps features to their access; features it omits are on.
+type Policy map[Feature]Access
+
+// Access returns the access to f.
+func (p Policy) Access(f Feature) Access {
+	if a, ok := p[f]; ok {
+		return a
+	}
+	return On
+}
+
+// Parse parses a spec such as "decrypt=off,keys=admin". An empty spec turns
+// every feature on.
+func Parse(spec string) (Policy, error) {
+	p := make(Policy)
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("feature %q: want name=access", part)
+		}
+		f, a := Feature(strings.TrimSpace(name)), Access(strings.TrimSpace(value))
+		if err := Check(f, a); err != nil {
+			return nil, err
+		}
+		p[f] = a
+	}
+	return p, nil
+}
+
+// Check reports whether f and a name a known feature and access level.
+func Check(f Feature, a Access) error {
+	if !slices.Contains(All, f) {
+		return fmt.Errorf("unknown feature %q (want one of %s)", f, join(All))
+	}
+	if !slices.Contains(Accesses, a) {
+		return fmt.Errorf("feature %s: unknown access %q (want one of %s)", f, a, join(Accesses))
+	}
+	return nil
+}
+
+// String formats p in the syntax accepted by Parse, in the order of All.
+func (p Policy) String() string {
+	var parts []string
+	for _, f := range All {
+		if a, ok := p[f]; ok {
+			parts = append(parts, string(f)+"="+string(a))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func join[T ~string](values []T) string {
+	s := make([]string, len(values))
+	for i, v := range values {
+		s[i] = string(v)
+	}
+	return strings.Join(s, ", ")
+}
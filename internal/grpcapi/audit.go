@@ -0,0 +1,69 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"tfhe-go/internal/audit"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/keys"
+)
+
+// AuditInterceptors record every call to l with its caller, key set,
+// message sizes and status code. They must run after authentication.
+func AuditInterceptors(l *audit.Logger) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	record := func(ctx context.Context, method string, start time.Time, reqBytes, respBytes int64, err error) {
+		e := audit.Event{
+			Action:        audit.Compute,
+			Op:            method,
+			KeyID:         keys.DefaultID,
+			RequestBytes:  reqBytes,
+			ResponseBytes: respBytes,
+			Status:        int(status.Code(err)),
+			Outcome:       audit.Success,
+			DurationNanos: int64(time.Since(start)),
+		}
+		if strings.HasSuffix(method, "/Decrypt") {
+			e.Action = audit.Decrypt
+		}
+		if p, ok := peer.FromContext(ctx); ok {
+			e.Remote = p.Addr.String()
+		}
+		if id, ok := auth.FromContext(ctx); ok {
+			e.Actor, e.Tenant = id.ID, id.Tenant
+			if id.KeyID != "" {
+				e.KeyID = id.KeyID
+			}
+		}
+		switch status.Code(err) {
+		case codes.OK:
+		case codes.Unauthenticated, codes.PermissionDenied, codes.ResourceExhausted:
+			e.Outcome, e.Error = audit.Denied, err.Error()
+		default:
+			e.Outcome, e.Error = audit.Failure, err.Error()
+		}
+		_ = l.Record(e)
+	}
+
+	unary := func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		record(ctx, info.FullMethod, start, messageSize(req), messageSize(resp), err)
+		return resp, err
+	}
+	stream := func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		cs := &countingStream{ServerStream: ss, ctx: ss.Context()}
+		err := handler(srv, cs)
+		// A stream's messages in both directions count as request bytes.
+		record(ss.Context(), info.FullMethod, start, cs.bytes, 0, err)
+		return err
+	}
+	return unary, stream
+}
@@ -0,0 +1,56 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"tfhe-go/internal/auth"
+)
+
+// AuthInterceptors authenticate calls carrying an "authorization: Bearer
+// <token>" or "x-api-key" metadata entry, attaching the caller's Identity.
+func AuthInterceptors(authenticators ...auth.TokenAuthenticator) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	authenticate := func(ctx context.Context) (context.Context, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		var secret string
+		if v := md.Get("x-api-key"); len(v) > 0 {
+			secret = v[0]
+		} else if v := md.Get("authorization"); len(v) > 0 {
+			secret, _ = strings.CutPrefix(v[0], "Bearer ")
+		}
+		id, err := auth.Authenticate(secret, authenticators...)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return auth.WithIdentity(ctx, id), nil
+	}
+
+	unary := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+	return unary, stream
+}
+
+// contextStream overrides the stream context, e.g. to carry the caller's Identity.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
@@ -0,0 +1,53 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tfhev1 "tfhe-go/api/tfhe/v1"
+	"tfhe-go/internal/features"
+)
+
+// GetKeyInfo describes the caller's key set. Other IDs are NotFound, as
+// over HTTP, so the method does not reveal which key sets exist.
+func (s *Server) GetKeyInfo(ctx context.Context, req *tfhev1.GetKeyInfoRequest) (*tfhev1.KeyInfo, error) {
+	if err := s.allow(ctx, features.Keys); err != nil {
+		return nil, err
+	}
+	ks, err := s.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if id := req.GetId(); id != "" && id != ks.ID {
+		return nil, status.Errorf(codes.NotFound, "unknown key set %q", id)
+	}
+	d, err := ks.Describe(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &tfhev1.KeyInfo{
+		Id:                  d.ID,
+		ParameterSet:        d.Params,
+		CreatedAt:           d.CreatedAt.Format(time.RFC3339Nano),
+		Generation:          d.Generation,
+		Backend:             d.Backend,
+		SerializationFormat: d.SerializationFormat,
+		Sizes: &tfhev1.KeySizes{
+			BooleanServerKey: uint64(d.Sizes.BooleanServerKey),
+			IntegerServerKey: uint64(d.Sizes.IntegerServerKey),
+			PublicKey:        uint64(d.Sizes.PublicKey),
+			CompactPublicKey: uint64(d.Sizes.CompactPublicKey),
+		},
+		Capabilities: &tfhev1.KeyCapabilities{
+			Boolean:                   d.Capabilities.Boolean,
+			Integer:                   d.Capabilities.Integer,
+			ProgrammableBootstrapping: d.Capabilities.ProgrammableBootstrapping,
+			CompressedCiphertexts:     d.Capabilities.CompressedCiphertexts,
+			PublicKeyEncryption:       d.Capabilities.PublicKeyEncryption,
+			ServerEncryption:          d.Capabilities.ServerEncryption,
+		},
+	}, nil
+}
@@ -0,0 +1,94 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"tfhe-go/internal/quota"
+	"tfhe-go/internal/tfhe"
+)
+
+// QuotaInterceptors charge each call's operations, compute time and message
+// bytes to the caller's tenant, and reject calls from tenants over a quota
+// with ResourceExhausted. Streams are checked when opened and charged when
+// they end. Calls take the tenant's share of the tfhe compute limit. They
+// must run after AuthInterceptors.
+func QuotaInterceptors(t *quota.Tracker) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	admit := func(ctx context.Context) (string, string, error) {
+		var remote string
+		if p, ok := peer.FromContext(ctx); ok {
+			remote = p.Addr.String()
+		}
+		tenant, identity := quota.Subject(ctx, remote)
+		if exceeded := t.Check(tenant, time.Now()); exceeded != nil {
+			return "", "", status.Error(codes.ResourceExhausted, exceeded.Error())
+		}
+		return tenant, identity, nil
+	}
+
+	unary := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		tenant, identity, err := admit(ctx)
+		if err != nil {
+			return nil, err
+		}
+		usage := &tfhe.Usage{}
+		resp, err := handler(tfhe.WithTenant(tfhe.WithUsage(ctx, usage), tenant), req)
+		t.Add(tenant, identity, quota.Usage{
+			Operations:   usage.Ops(),
+			ComputeNanos: usage.Nanos(),
+			Bytes:        messageSize(req) + messageSize(resp),
+		}, time.Now())
+		return resp, err
+	}
+	stream := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		tenant, identity, err := admit(ss.Context())
+		if err != nil {
+			return err
+		}
+		usage := &tfhe.Usage{}
+		cs := &countingStream{ServerStream: ss, ctx: tfhe.WithTenant(tfhe.WithUsage(ss.Context(), usage), tenant)}
+		err = handler(srv, cs)
+		t.Add(tenant, identity, quota.Usage{
+			Operations:   usage.Ops(),
+			ComputeNanos: usage.Nanos(),
+			Bytes:        cs.bytes,
+		}, time.Now())
+		return err
+	}
+	return unary, stream
+}
+
+func messageSize(m any) int64 {
+	if msg, ok := m.(proto.Message); ok {
+		return int64(proto.Size(msg))
+	}
+	return 0
+}
+
+// countingStream carries the usage context and counts message bytes.
+type countingStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	bytes int64
+}
+
+func (s *countingStream) Context() context.Context { return s.ctx }
+
+func (s *countingStream) SendMsg(m any) error {
+	s.bytes += messageSize(m)
+	return s.ServerStream.SendMsg(m)
+}
+
+func (s *countingStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.bytes += messageSize(m)
+	}
+	return err
+}
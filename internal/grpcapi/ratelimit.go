@@ -0,0 +1,42 @@
+package grpcapi
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"tfhe-go/internal/ratelimit"
+)
+
+// RateLimitInterceptors reject calls over the caller's budget with
+// ResourceExhausted. Streams are charged once when opened. They must run
+// after AuthInterceptors so callers are limited per identity.
+func RateLimitInterceptors(l *ratelimit.Limiter) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	allow := func(ctx context.Context) error {
+		var remote string
+		if p, ok := peer.FromContext(ctx); ok {
+			remote = p.Addr.String()
+		}
+		if ok, retry := l.Allow(ratelimit.ClientKey(ctx, remote)); !ok {
+			return status.Errorf(codes.ResourceExhausted, "rate limit exceeded, retry in %s", retry)
+		}
+		return nil
+	}
+
+	unary := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := allow(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := allow(ss.Context()); err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+	return unary, stream
+}
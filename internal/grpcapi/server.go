@@ -0,0 +1,269 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	tfhev1 "tfhe-go/api/tfhe/v1"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// Server implements tfhev1.TfheServiceServer on top of the tfhe services.
+type Server struct {
+	tfhev1.UnimplementedTfheServiceServer
+
+	keys *keys.Registry
+	// features switches method families off or restricts them to admins.
+	features features.Policy
+	admins   map[string]bool
+}
+
+// NewServer builds a gRPC service with dependencies injected.
+func NewServer(registry *keys.Registry) *Server {
+	return &Server{keys: registry}
+}
+
+// SetFeatures applies the HTTP route policy to the matching methods: a
+// family switched off answers Unimplemented, and one restricted to admins
+// answers PermissionDenied to callers whose identity ID is not in admins
+// (any authenticated caller is an admin when admins is empty).
+func (s *Server) SetFeatures(p features.Policy, admins []string) {
+	s.features, s.admins = p, nil
+	if len(admins) > 0 {
+		s.admins = make(map[string]bool, len(admins))
+		for _, id := range admins {
+			s.admins[id] = true
+		}
+	}
+}
+
+// allow checks the caller may use feature f.
+func (s *Server) allow(ctx context.Context, f features.Feature) error {
+	switch s.features.Access(f) {
+	case features.Off:
+		return status.Errorf(codes.Unimplemented, "%s is disabled on this server", f)
+	case features.Admin:
+		if id, ok := auth.FromContext(ctx); s.admins != nil && (!ok || !s.admins[id.ID]) {
+			return status.Errorf(codes.PermissionDenied, "%s requires admin access", f)
+		}
+	}
+	return nil
+}
+
+// Register attaches the service to the provided gRPC server.
+func (s *Server) Register(gs *grpc.Server) {
+	tfhev1.RegisterTfheServiceServer(gs, s)
+}
+
+// keySet returns the key set selected by the caller's identity.
+func (s *Server) keySet(ctx context.Context) (*keys.KeySet, error) {
+	id, _ := auth.FromContext(ctx)
+	ks, err := s.keys.Select(id.KeyID)
+	if err != nil {
+		return nil, status.Error(codes.PermissionDenied, err.Error())
+	}
+	return ks, nil
+}
+
+// MaxMessageBytes allows two operands of the largest accepted size plus framing.
+func MaxMessageBytes() int {
+	return 2*tfhe.CurrentLimits().MaxCiphertext() + 4<<10
+}
+
+// Encrypt encrypts a plaintext value of the requested type.
+func (s *Server) Encrypt(ctx context.Context, req *tfhev1.EncryptRequest) (*tfhev1.EncryptResponse, error) {
+	feature := features.Encrypt
+	if req.GetUsePublicKey() && req.GetType() == tfhev1.CiphertextType_CIPHERTEXT_TYPE_UINT8 {
+		feature = features.PublicEncrypt
+	}
+	if err := s.allow(ctx, feature); err != nil {
+		return nil, err
+	}
+	ks, err := s.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var ct []byte
+	switch req.GetType() {
+	case tfhev1.CiphertextType_CIPHERTEXT_TYPE_BOOLEAN:
+		v, ok := req.GetValue().(*tfhev1.EncryptRequest_BoolValue)
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "boolean encryption requires bool_value")
+		}
+		ct, err = ks.Boolean.EncryptRaw(ctx, v.BoolValue)
+	case tfhev1.CiphertextType_CIPHERTEXT_TYPE_UINT8:
+		v, ok := req.GetValue().(*tfhev1.EncryptRequest_UintValue)
+		if !ok || v.UintValue > 0xff {
+			return nil, status.Error(codes.InvalidArgument, "uint8 encryption requires uint_value in [0, 255]")
+		}
+		if req.GetUsePublicKey() {
+			ct, err = ks.Uint8.EncryptWithPublicRaw(ctx, uint8(v.UintValue))
+		} else {
+			ct, err = ks.Uint8.EncryptRaw(ctx, uint8(v.UintValue))
+		}
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported type %s", req.GetType())
+	}
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &tfhev1.EncryptResponse{Ciphertext: ct}, nil
+}
+
+// Decrypt decrypts a ciphertext of the requested type.
+func (s *Server) Decrypt(ctx context.Context, req *tfhev1.DecryptRequest) (*tfhev1.DecryptResponse, error) {
+	if err := s.allow(ctx, features.Decrypt); err != nil {
+		return nil, err
+	}
+	ks, err := s.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	switch req.GetType() {
+	case tfhev1.CiphertextType_CIPHERTEXT_TYPE_BOOLEAN:
+		v, err := ks.Boolean.DecryptRaw(ctx, req.GetCiphertext())
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return &tfhev1.DecryptResponse{Value: &tfhev1.DecryptResponse_BoolValue{BoolValue: v}}, nil
+	case tfhev1.CiphertextType_CIPHERTEXT_TYPE_UINT8:
+		v, err := ks.Uint8.DecryptRaw(ctx, req.GetCiphertext())
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return &tfhev1.DecryptResponse{Value: &tfhev1.DecryptResponse_UintValue{UintValue: uint32(v)}}, nil
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported type %s", req.GetType())
+	}
+}
+
+// Gate applies a boolean gate.
+func (s *Server) Gate(ctx context.Context, req *tfhev1.GateRequest) (*tfhev1.CiphertextResponse, error) {
+	ks, err := s.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := gate(ctx, ks, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &tfhev1.CiphertextResponse{Ciphertext: ct}, nil
+}
+
+// IntegerOp applies a uint8 operation.
+func (s *Server) IntegerOp(ctx context.Context, req *tfhev1.IntegerOpRequest) (*tfhev1.CiphertextResponse, error) {
+	ks, err := s.keySet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ct, err := integerOp(ctx, ks, req)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &tfhev1.CiphertextResponse{Ciphertext: ct}, nil
+}
+
+// BatchOps evaluates each streamed operation in order. Per-operation failures
+// are reported in the response rather than aborting the stream.
+func (s *Server) BatchOps(stream tfhev1.TfheService_BatchOpsServer) error {
+	if err := s.allow(stream.Context(), features.Batch); err != nil {
+		return err
+	}
+	ks, err := s.keySet(stream.Context())
+	if err != nil {
+		return err
+	}
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var ct []byte
+		switch op := req.GetOp().(type) {
+		case *tfhev1.BatchOpRequest_Gate:
+			ct, err = gate(stream.Context(), ks, op.Gate)
+		case *tfhev1.BatchOpRequest_Integer:
+			ct, err = integerOp(stream.Context(), ks, op.Integer)
+		default:
+			err = errors.New("operation is required")
+		}
+
+		resp := &tfhev1.BatchOpResponse{Id: req.GetId(), Ciphertext: ct}
+		if err != nil {
+			resp.Error = err.Error()
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+}
+
+func gate(ctx context.Context, ks *keys.KeySet, req *tfhev1.GateRequest) ([]byte, error) {
+	switch req.GetOp() {
+	case tfhev1.GateOp_GATE_OP_AND:
+		return ks.Boolean.AndRaw(ctx, req.GetLeft(), req.GetRight())
+	case tfhev1.GateOp_GATE_OP_OR:
+		return ks.Boolean.OrRaw(ctx, req.GetLeft(), req.GetRight())
+	case tfhev1.GateOp_GATE_OP_XOR:
+		return ks.Boolean.XorRaw(ctx, req.GetLeft(), req.GetRight())
+	case tfhev1.GateOp_GATE_OP_NOT:
+		return ks.Boolean.NotRaw(ctx, req.GetLeft())
+	}
+	return nil, errUnsupported(req.GetOp())
+}
+
+func integerOp(ctx context.Context, ks *keys.KeySet, req *tfhev1.IntegerOpRequest) ([]byte, error) {
+	switch req.GetOp() {
+	case tfhev1.IntegerOpKind_INTEGER_OP_KIND_ADD:
+		return ks.Uint8.AddRaw(ctx, req.GetLeft(), req.GetRight())
+	case tfhev1.IntegerOpKind_INTEGER_OP_KIND_BITAND:
+		return ks.Uint8.BitAndRaw(ctx, req.GetLeft(), req.GetRight())
+	case tfhev1.IntegerOpKind_INTEGER_OP_KIND_BITXOR:
+		return ks.Uint8.BitXorRaw(ctx, req.GetLeft(), req.GetRight())
+	}
+	return nil, errUnsupported(req.GetOp())
+}
+
+// unsupportedOpError reports an unknown or unspecified operation enum.
+type unsupportedOpError struct{ op fmt.Stringer }
+
+func (e unsupportedOpError) Error() string { return fmt.Sprintf("unsupported operation %s", e.op) }
+
+func errUnsupported(op fmt.Stringer) error { return unsupportedOpError{op: op} }
+
+// toStatus maps typed tfhe errors to gRPC status codes, mirroring the HTTP mapping.
+func toStatus(err error) error {
+	var unsupported unsupportedOpError
+	switch {
+	case errors.As(err, &unsupported), errors.Is(err, tfhe.ErrInvalidCiphertext), errors.Is(err, tfhe.ErrInvalidKey), errors.Is(err, tfhe.ErrValueOutOfRange),
+		errors.Is(err, tfhe.ErrTrivialCiphertext):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, tfhe.ErrCiphertextTooLarge), errors.Is(err, tfhe.ErrMemoryLimit):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, tfhe.ErrNilKey), errors.Is(err, tfhe.ErrServerKeyNotSet), errors.Is(err, tfhe.ErrOverloaded):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, tfhe.ErrOpTimeout):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, tfhe.ErrUnsupported):
+		return status.Error(codes.Unimplemented, err.Error())
+	case errors.Is(err, tfhe.ErrSwitchKeyNotSet):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, tfhe.ErrClientKeyWithheld), errors.Is(err, tfhe.ErrTrustedReencryptDisabled):
+		return status.Error(codes.PermissionDenied, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
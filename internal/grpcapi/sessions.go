@@ -0,0 +1,47 @@
+package grpcapi
+
+import (
+	"context"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"tfhe-go/internal/sessions"
+)
+
+// SessionInterceptors select the session a call names in its "session-id"
+// metadata entry, as the Session-Id header does over HTTP. They must run
+// after authentication.
+func SessionInterceptors(m *sessions.Manager) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	selectSession := func(ctx context.Context) (context.Context, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		v := md.Get(strings.ToLower(sessions.Header))
+		if len(v) == 0 || v[0] == "" {
+			return ctx, nil
+		}
+		ctx, err := m.Select(ctx, v[0])
+		if err != nil {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		return ctx, nil
+	}
+
+	unary := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := selectSession(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+	stream := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := selectSession(ss.Context())
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+	return unary, stream
+}
@@ -0,0 +1,33 @@
+package grpcapi
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// serverTimingTrailer carries the timing in the HTTP Server-Timing syntax.
+const serverTimingTrailer = "server-timing"
+
+// TimingInterceptors send each call's time waiting for compute slots and
+// deserializing, computing and serializing in tfhe-c, plus its total, as a
+// server-timing trailer in the syntax of the HTTP Server-Timing header.
+func TimingInterceptors() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+	unary := func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start, timing := time.Now(), &tfhe.Timing{}
+		resp, err := handler(tfhe.WithTiming(ctx, timing), req)
+		_ = grpc.SetTrailer(ctx, metadata.Pairs(serverTimingTrailer, timing.ServerTiming(time.Since(start))))
+		return resp, err
+	}
+	stream := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start, timing := time.Now(), &tfhe.Timing{}
+		err := handler(srv, &contextStream{ServerStream: ss, ctx: tfhe.WithTiming(ss.Context(), timing)})
+		ss.SetTrailer(metadata.Pairs(serverTimingTrailer, timing.ServerTiming(time.Since(start))))
+		return err
+	}
+	return unary, stream
+}
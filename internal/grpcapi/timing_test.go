@@ -0,0 +1,85 @@
+package grpcapi
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var serverTimingPattern = regexp.MustCompile(`^queue;dur=\d+\.\d{3}, deserialize;dur=\d+\.\d{3}, compute;dur=\d+\.\d{3}, serialize;dur=\d+\.\d{3}, total;dur=(\d+\.\d{3})$`)
+
+// transportRecorder keeps the trailers a unary call sets.
+type transportRecorder struct{ trailer metadata.MD }
+
+func (r *transportRecorder) Method() string               { return "/tfhe.v1.TfheService/Test" }
+func (r *transportRecorder) SetHeader(metadata.MD) error  { return nil }
+func (r *transportRecorder) SendHeader(metadata.MD) error { return nil }
+func (r *transportRecorder) SetTrailer(md metadata.MD) error {
+	r.trailer = metadata.Join(r.trailer, md)
+	return nil
+}
+
+// streamRecorder keeps the trailers a streaming call sets.
+type streamRecorder struct {
+	grpc.ServerStream
+	ctx     context.Context
+	trailer metadata.MD
+}
+
+func (r *streamRecorder) Context() context.Context  { return r.ctx }
+func (r *streamRecorder) SetTrailer(md metadata.MD) { r.trailer = metadata.Join(r.trailer, md) }
+
+// checkTrailer checks the server-timing trailer counts at least the
+// handler's wait.
+func checkTrailer(t *testing.T, trailer metadata.MD, wait time.Duration) {
+	t.Helper()
+	v := trailer.Get(serverTimingTrailer)
+	if len(v) != 1 {
+		t.Fatalf("server-timing trailer %q, want one value", v)
+	}
+	m := serverTimingPattern.FindStringSubmatch(v[0])
+	if m == nil {
+		t.Fatalf("server-timing %q", v[0])
+	}
+	if total, _ := strconv.ParseFloat(m[1], 64); total < float64(wait)/float64(time.Millisecond) {
+		t.Errorf("total %sms, want at least %s", m[1], wait)
+	}
+}
+
+func TestTimingInterceptors(t *testing.T) {
+	unary, stream := TimingInterceptors()
+	const wait = 5 * time.Millisecond
+
+	t.Run("unary", func(t *testing.T) {
+		rec := &transportRecorder{}
+		ctx := grpc.NewContextWithServerTransportStream(context.Background(), rec)
+		resp, err := unary(ctx, "req", &grpc.UnaryServerInfo{}, func(ctx context.Context, req any) (any, error) {
+			time.Sleep(wait)
+			return req, nil
+		})
+		if err != nil || resp != "req" {
+			t.Fatalf("got %v, %v", resp, err)
+		}
+		checkTrailer(t, rec.trailer, wait)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		rec := &streamRecorder{ctx: context.Background()}
+		err := stream(nil, rec, &grpc.StreamServerInfo{}, func(_ any, ss grpc.ServerStream) error {
+			if ss.Context() == rec.ctx {
+				t.Error("handler stream does not carry the timing context")
+			}
+			time.Sleep(wait)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkTrailer(t, rec.trailer, wait)
+	})
+}
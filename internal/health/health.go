@@ -0,0 +1,246 @@
+// Package health serves liveness and readiness probes.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// Checker decides readiness from three signals: keys have been generated or
+// loaded, a periodic self-test through the native library passes, and the
+// service has spare capacity for more operations; plus native memory below
+// the cap set with SetMaxNativeMemory and any checks added with SetCheck.
+type Checker struct {
+	keysReady       atomic.Bool
+	maxInFlight     int64
+	maxNativeMemory atomic.Int64
+
+	mu       sync.RWMutex
+	selfTest func(ctx context.Context) error
+	lastErr  error
+	lastRun  time.Time
+	checks   map[string]func() error
+}
+
+// New returns a Checker that reports not ready while more than maxInFlight
+// operations are running; maxInFlight <= 0 disables the capacity check.
+func New(maxInFlight int) *Checker {
+	return &Checker{maxInFlight: int64(maxInFlight), lastErr: errors.New("self-test has not run yet")}
+}
+
+// MarkKeysReady records that key generation or loading has finished.
+func (c *Checker) MarkKeysReady() { c.keysReady.Store(true) }
+
+// SetMaxNativeMemory makes the service not ready while native memory
+// reaches bytes: the C heap in use where the allocator reports it, the
+// estimate of live objects otherwise. Zero disables the check.
+func (c *Checker) SetMaxNativeMemory(bytes int64) { c.maxNativeMemory.Store(max(bytes, 0)) }
+
+// SetSelfTest installs the operation Run executes periodically.
+func (c *Checker) SetSelfTest(fn func(ctx context.Context) error) {
+	c.mu.Lock()
+	c.selfTest = fn
+	c.mu.Unlock()
+}
+
+// SetCheck adds a readiness check named name: the service is not ready
+// while fn returns an error.
+func (c *Checker) SetCheck(name string, fn func() error) {
+	c.mu.Lock()
+	if c.checks == nil {
+		c.checks = make(map[string]func() error)
+	}
+	c.checks[name] = fn
+	c.mu.Unlock()
+}
+
+// Run executes the self-test immediately and then every interval until ctx
+// is done. A self-test that exceeds timeout counts as a failure even though
+// the native call cannot be interrupted.
+func (c *Checker) Run(ctx context.Context, interval, timeout time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		c.runSelfTest(ctx, timeout)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) runSelfTest(ctx context.Context, timeout time.Duration) {
+	c.mu.RLock()
+	fn := c.selfTest
+	c.mu.RUnlock()
+	if fn == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- fn(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = fmt.Errorf("self-test did not finish within %s", timeout)
+	}
+	c.mu.Lock()
+	c.lastErr, c.lastRun = err, time.Now()
+	c.mu.Unlock()
+}
+
+// Ready reports readiness and the state of each check.
+func (c *Checker) Ready() (bool, map[string]string) {
+	checks := make(map[string]string, 3)
+	ready := true
+	fail := func(name, msg string) {
+		checks[name] = msg
+		ready = false
+	}
+
+	if c.keysReady.Load() {
+		checks["keys"] = "ok"
+	} else {
+		fail("keys", "keys are still being generated or loaded")
+	}
+
+	c.mu.RLock()
+	lastErr := c.lastErr
+	for name, fn := range c.checks {
+		if err := fn(); err != nil {
+			fail(name, err.Error())
+		} else {
+			checks[name] = "ok"
+		}
+	}
+	c.mu.RUnlock()
+	if lastErr == nil {
+		checks["self_test"] = "ok"
+	} else {
+		fail("self_test", lastErr.Error())
+	}
+
+	if n := tfhe.InFlight(); c.maxInFlight > 0 && n >= c.maxInFlight {
+		fail("capacity", fmt.Sprintf("%d operations in flight, limit %d", n, c.maxInFlight))
+	} else {
+		checks["capacity"] = "ok"
+	}
+
+	if limit := c.maxNativeMemory.Load(); limit > 0 {
+		used, measured := tfhe.NativeHeap()
+		source := "C heap"
+		if !measured {
+			used, source = tfhe.MemoryUsage().Bytes, "estimated native memory"
+		}
+		if used >= limit {
+			fail("native_memory", fmt.Sprintf("%s %d bytes, limit %d", source, used, limit))
+		} else {
+			checks["native_memory"] = "ok"
+		}
+	}
+	return ready, checks
+}
+
+// nativeMemory summarizes native memory for the /readyz body.
+func nativeMemory() map[string]int64 {
+	stats := tfhe.MemoryUsage()
+	out := map[string]int64{"estimated_bytes": stats.Bytes, "limit": stats.Limit}
+	if heap, ok := tfhe.NativeHeap(); ok {
+		out["heap_bytes"] = heap
+	}
+	return out
+}
+
+// Register serves /healthz (the process is up), /readyz (traffic may be
+// routed here) and the legacy /health, which behaves like /healthz.
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", c.live)
+	mux.HandleFunc("/health", c.live)
+	mux.HandleFunc("/readyz", c.ready)
+}
+
+func (c *Checker) live(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (c *Checker) ready(w http.ResponseWriter, r *http.Request) {
+	ready, checks := c.Ready()
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	writeJSON(w, code, map[string]any{"status": status, "checks": checks, "native_memory": nativeMemory()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// Uint8SelfTest returns a self-test that adds two encrypted uint8 values
+// with svc and checks the decrypted sum.
+func Uint8SelfTest(svc *tfhe.Uint8Service) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		lhs, err := svc.EncryptRaw(ctx, 20)
+		if err != nil {
+			return err
+		}
+		rhs, err := svc.EncryptRaw(ctx, 22)
+		if err != nil {
+			return err
+		}
+		sum, err := svc.AddRaw(ctx, lhs, rhs)
+		if err != nil {
+			return err
+		}
+		got, err := svc.DecryptRaw(ctx, sum)
+		if err != nil {
+			return err
+		}
+		if got != 42 {
+			return fmt.Errorf("self-test decrypted 20+22 as %d", got)
+		}
+		return nil
+	}
+}
+
+// BooleanSelfTest returns a self-test that XORs two encrypted booleans with
+// svc and checks the decrypted result. It suits backends without integer
+// support.
+func BooleanSelfTest(svc *tfhe.BooleanService) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		lhs, err := svc.EncryptRaw(ctx, true)
+		if err != nil {
+			return err
+		}
+		rhs, err := svc.EncryptRaw(ctx, false)
+		if err != nil {
+			return err
+		}
+		out, err := svc.XorRaw(ctx, lhs, rhs)
+		if err != nil {
+			return err
+		}
+		got, err := svc.DecryptRaw(ctx, out)
+		if err != nil {
+			return err
+		}
+		if !got {
+			return errors.New("self-test decrypted true XOR false as false")
+		}
+		return nil
+	}
+}
@@ -0,0 +1,126 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"tfhe-go/internal/acl"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/store"
+)
+
+// SetACL lets handle owners grant other principals rights over their
+// handles, kept in reg. Without it only the owning tenant may use a handle.
+func (h *Handler) SetACL(reg *acl.Registry) {
+	h.acl = reg
+}
+
+// SetACL enables the routes managing the grants in reg of any handle in
+// handles.
+func (h *AdminHandler) SetACL(reg *acl.Registry, handles store.Store) {
+	h.acl, h.handles = reg, handles
+}
+
+// openHandle returns the stored handle id if the caller may exercise perm
+// over it: its own tenant may do anything, and other principals what the
+// ACL grants them. Handles the caller holds no rights over are reported
+// missing rather than forbidden, so their IDs cannot be probed.
+func (h *Handler) openHandle(r *http.Request, id string, perm acl.Permission) (store.Entry, error) {
+	entry, err := h.store.Get(id)
+	if err != nil || entry.Tenant == tenantOf(r) {
+		return entry, err
+	}
+	if h.acl != nil {
+		caller, _ := auth.FromContext(r.Context())
+		if known, err := h.acl.Check(id, caller.ID, perm); known {
+			return entry, err
+		}
+	}
+	return store.Entry{}, fmt.Errorf("%w: %s", store.ErrNotFound, id)
+}
+
+// aclInfo is a handle's owner and grants.
+type aclInfo struct {
+	Handle string      `json:"handle"`
+	Tenant string      `json:"tenant"`
+	Grants []acl.Grant `json:"grants"`
+}
+
+// updateACL applies a PUT body, one grant replacing a principal's
+// permissions, to reg and answers with the handle's grants.
+func updateACL(w http.ResponseWriter, r *http.Request, reg *acl.Registry, entry store.Entry) {
+	if r.Method == http.MethodPut {
+		var req acl.Grant
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if err := reg.Set(entry.ID, req.Principal, req.Permissions); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	} else if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, aclInfo{Handle: entry.ID, Tenant: entry.Tenant, Grants: reg.Grants(entry.ID)})
+}
+
+// handleACL handles GET and PUT /ciphertexts/{id}/acl: the owning tenant
+// lists or changes who else may use the handle.
+func (h *Handler) handleACL(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	entry, err := h.store.Get(id)
+	if err == nil && entry.Tenant != tenantOf(r) {
+		err = fmt.Errorf("%w: %s", store.ErrNotFound, id)
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	updateACL(w, r, h.acl, entry)
+}
+
+// ciphertextDecrypt handles POST /ciphertexts/{id}/decrypt: decrypts a
+// stored boolean or uint8 handle for a caller holding the decrypt right.
+func (h *Handler) ciphertextDecrypt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	entry, err := h.openHandle(r, r.PathValue("id"), acl.Decrypt)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	var value any
+	switch entry.Type {
+	case typeBoolean:
+		value, err = ks.Boolean.DecryptRaw(r.Context(), entry.Data)
+	case typeUint8:
+		value, err = ks.Uint8.DecryptRaw(r.Context(), entry.Data)
+	default:
+		err = fmt.Errorf("handle %s has type %s; only boolean and uint8 handles can be decrypted", entry.ID, entry.Type)
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"handle": entry.ID, "type": entry.Type, "value": value})
+}
+
+// handleACL handles GET and PUT /admin/ciphertexts/{id}/acl: an operator
+// lists or changes the grants of any tenant's handle.
+func (h *AdminHandler) handleACL(w http.ResponseWriter, r *http.Request) {
+	entry, err := h.handles.Get(r.PathValue("id"))
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	updateACL(w, r, h.acl, entry)
+}
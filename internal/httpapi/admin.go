@@ -0,0 +1,234 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"tfhe-go/internal/acl"
+	"tfhe-go/internal/audit"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/counters"
+	"tfhe-go/internal/elections"
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/quota"
+	"tfhe-go/internal/rotation"
+	"tfhe-go/internal/store"
+	"tfhe-go/internal/tfhe"
+)
+
+// AdminHandler wires operator-facing endpoints such as key lifecycle
+// management and op metrics.
+type AdminHandler struct {
+	keys      *keys.Registry
+	rotation  *rotation.Manager
+	metrics   *tfhe.Recorder
+	usage     *quota.Tracker
+	counters  *counters.Registry
+	elections *elections.Registry
+	acl       *acl.Registry
+	handles   store.Store
+	reload    func(context.Context) error
+	audit     *audit.Logger
+	admins    map[string]bool
+	features  features.Policy
+}
+
+// NewAdminHandler builds an admin handler with dependencies injected. When
+// admins is non-empty only callers whose identity ID is listed may use the
+// admin routes; otherwise any caller admitted by authentication may.
+func NewAdminHandler(registry *keys.Registry, rotationManager *rotation.Manager, recorder *tfhe.Recorder, admins []string) *AdminHandler {
+	return &AdminHandler{
+		keys:     registry,
+		rotation: rotationManager,
+		metrics:  recorder,
+		admins:   adminSet(admins),
+	}
+}
+
+// adminSet indexes the admin identity IDs; it is nil when there are none.
+func adminSet(admins []string) map[string]bool {
+	if len(admins) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(admins))
+	for _, id := range admins {
+		set[id] = true
+	}
+	return set
+}
+
+// Register attaches admin routes to the provided mux.
+func (h *AdminHandler) Register(mux *http.ServeMux) {
+	handle(mux, "/admin/keys", h.authorize(h.listKeys))
+	handle(mux, "/admin/keys/rotate", h.authorize(h.rotate))
+	handle(mux, "/admin/keys/{id}", h.authorize(h.key))
+	handle(mux, "/admin/memory", h.authorize(h.memory))
+	if h.metrics != nil {
+		handle(mux, "/admin/ops", h.authorize(h.ops))
+	}
+	if h.usage != nil {
+		handle(mux, "/admin/usage", h.authorize(h.usageReport))
+	}
+	if h.counters != nil {
+		handle(mux, "/admin/counters", h.authorize(h.counterList))
+		h.route(mux, features.Decrypt, "/admin/counters/decrypt", h.counterDecrypt)
+	}
+	if h.acl != nil {
+		handle(mux, "/admin/ciphertexts/{id}/acl", h.authorize(h.handleACL))
+	}
+	if h.elections != nil {
+		handle(mux, "/admin/elections", h.authorize(h.electionList))
+		h.route(mux, features.Decrypt, "/admin/elections/results", h.electionResults)
+	}
+	if h.reload != nil {
+		handle(mux, "/admin/reload", h.authorize(h.reloadSettings))
+	}
+	if h.audit != nil {
+		handle(mux, "/admin/audit", h.authorize(h.auditHead))
+	}
+}
+
+// SetReloader enables POST /admin/reload, which calls fn to re-read
+// reloadable settings such as the TLS certificate and API keys; call it
+// before Register.
+func (h *AdminHandler) SetReloader(fn func(context.Context) error) {
+	h.reload = fn
+}
+
+// SetAudit enables GET /admin/audit, which reports the head of l's chain;
+// call it before Register.
+func (h *AdminHandler) SetAudit(l *audit.Logger) {
+	h.audit = l
+}
+
+// auditHead reports the last audit record written, for anchoring elsewhere,
+// and the sink's failures.
+func (h *AdminHandler) auditHead(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, h.audit.Head())
+}
+
+// authorize rejects callers outside the configured admin identities.
+func (h *AdminHandler) authorize(fn http.HandlerFunc) http.HandlerFunc {
+	return requireAdmin(h.admins, fn)
+}
+
+// requireAdmin rejects callers whose identity ID is not in admins; a nil
+// set admits any authenticated caller.
+func requireAdmin(admins map[string]bool, fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if admins != nil {
+			id, ok := auth.FromContext(r.Context())
+			if !ok || !admins[id.ID] {
+				writeError(w, http.StatusForbidden, errors.New("admin access required"))
+				return
+			}
+		}
+		fn(w, r)
+	}
+}
+
+// listKeys reports every registered key set with its metadata.
+func (h *AdminHandler) listKeys(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"keys": h.keys.Infos()})
+}
+
+// key reports one key set's metadata on GET and revokes it on DELETE.
+func (h *AdminHandler) key(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	switch r.Method {
+	case http.MethodGet:
+		info, err := h.keys.Info(id)
+		if err != nil {
+			writeError(w, keyStatus(err), err)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	case http.MethodDelete:
+		if err := h.keys.Revoke(id); err != nil {
+			writeError(w, keyStatus(err), err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// keyStatus maps registry errors: unknown IDs are 404, revoked ones 410 and
+// refusing to revoke the default set 409.
+func keyStatus(err error) int {
+	switch {
+	case errors.Is(err, keys.ErrUnknownKeySet):
+		return http.StatusNotFound
+	case errors.Is(err, keys.ErrRevokedKeySet):
+		return http.StatusGone
+	case errors.Is(err, keys.ErrDefaultKeySet):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// memory reports live native objects and their estimated footprint.
+func (h *AdminHandler) memory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, tfhe.MemoryUsage())
+}
+
+// ops reports per-operation counters and latency quantiles.
+func (h *AdminHandler) ops(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, http.StatusOK, h.metrics.Snapshot())
+}
+
+// rotate starts a rotation on POST and reports progress on GET.
+func (h *AdminHandler) rotate(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, h.rotation.Status())
+	case http.MethodPost:
+		status, err := h.rotation.Start()
+		if errors.Is(err, rotation.ErrInProgress) {
+			writeJSON(w, http.StatusConflict, status)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, status)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// reloadSettings re-reads reloadable settings on POST. Sources that fail to
+// load keep their previous values and are reported in the error.
+func (h *AdminHandler) reloadSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := h.reload(r.Context()); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": "reloaded"})
+}
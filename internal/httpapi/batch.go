@@ -0,0 +1,156 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"slices"
+	"sync"
+
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+const (
+	// maxBatchEntries bounds the number of operations in one /batch request.
+	maxBatchEntries = 1024
+	// maxBatchBodyBytes bounds the /batch request body.
+	maxBatchBodyBytes = 64 << 20
+)
+
+type batchEntry struct {
+	Type     string   `json:"type"`
+	Op       string   `json:"op"`
+	Operands []string `json:"operands"`
+}
+
+type batchResult struct {
+	Ciphertext    string `json:"ciphertext,omitempty"`
+	FormatVersion int    `json:"format_version,omitempty"`
+	Error         string `json:"error,omitempty"`
+	Status        int    `json:"status,omitempty"`
+}
+
+// batch handles POST /batch: independent operations evaluated concurrently
+// with bounded parallelism, answered in request order.
+func (h *Handler) batch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Ops []batchEntry `json:"ops"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if len(req.Ops) > maxBatchEntries {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("batch has %d ops, limit is %d", len(req.Ops), maxBatchEntries))
+		return
+	}
+
+	results := make([]batchResult, len(req.Ops))
+	sem := make(chan struct{}, h.batchConcurrency)
+	var wg sync.WaitGroup
+	for i, entry := range req.Ops {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, entry batchEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = h.runBatchEntry(r.Context(), ks, entry)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	writeJSON(w, http.StatusOK, map[string][]batchResult{"results": results})
+}
+
+func (h *Handler) runBatchEntry(ctx context.Context, ks *keys.KeySet, entry batchEntry) batchResult {
+	fn, err := h.resolveOp(ks, entry.Type, entry.Op, len(entry.Operands))
+	if err != nil {
+		return batchResult{Error: err.Error(), Status: http.StatusBadRequest}
+	}
+	operands := make([][]byte, len(entry.Operands))
+	for i, operand := range entry.Operands {
+		raw, err := bufpool.DecodeBase64(operand)
+		if err != nil {
+			return batchResult{Error: fmt.Sprintf("operand %d: %v", i, err), Status: http.StatusBadRequest}
+		}
+		defer bufpool.Put(raw)
+		operands[i] = *raw
+	}
+	out, err := fn(ctx, operands)
+	if err != nil {
+		return batchResult{Error: err.Error(), Status: statusFor(err)}
+	}
+	return batchResult{Ciphertext: bufpool.EncodeBase64(out), FormatVersion: CiphertextFormatVersion}
+}
+
+type gatePair struct {
+	Left  string `json:"left"`
+	Right string `json:"right"`
+}
+
+// gateBatch handles POST /boolean/batch: one boolean gate applied to many
+// operand pairs in a few native calls, answered in request order.
+func (h *Handler) gateBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Op    string     `json:"op"`
+		Pairs []gatePair `json:"pairs"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	gate := tfhe.Gate(req.Op)
+	if !slices.Contains(tfhe.Gates, gate) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported gate %q", req.Op))
+		return
+	}
+	if len(req.Pairs) > maxBatchEntries {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("batch has %d pairs, limit is %d", len(req.Pairs), maxBatchEntries))
+		return
+	}
+
+	pairs := make([][2][]byte, len(req.Pairs))
+	for i, p := range req.Pairs {
+		for j, operand := range [2]string{p.Left, p.Right} {
+			raw, err := bufpool.DecodeBase64(operand)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("pairs[%d].%s", i, [2]string{"left", "right"}[j]), fmt.Errorf("pair %d: %w", i, err)))
+				return
+			}
+			defer bufpool.Put(raw)
+			pairs[i][j] = *raw
+		}
+	}
+
+	out, err := ks.Boolean.GateManyRaw(r.Context(), gate, pairs, h.batchConcurrency)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	cts := make([]string, len(out))
+	for i, raw := range out {
+		cts[i] = bufpool.EncodeBase64(raw)
+	}
+	writeJSON(w, http.StatusOK, gateBatchResponse{Ciphertexts: cts, FormatVersion: CiphertextFormatVersion})
+}
+
+type gateBatchResponse struct {
+	Ciphertexts   []string `json:"ciphertexts"`
+	FormatVersion int      `json:"format_version"`
+}
@@ -0,0 +1,52 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/tfhe"
+)
+
+// registerBitCountRoutes registers /uint8/count_ones, /uint8/leading_zeros
+// and /uint8/ilog2.
+func (h *Handler) registerBitCountRoutes(mux routes) {
+	for _, op := range tfhe.BitCounts {
+		handle(mux, "/uint8/"+string(op), h.bitCount(op))
+	}
+}
+
+// bitCount counts bits of a uint8 ciphertext, answering with the count as a
+// uint32 ciphertext, re-encrypted under recipient_key when one is given.
+func (h *Handler) bitCount(op tfhe.BitCount) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Ciphertext   string `json:"ciphertext"`
+			RecipientKey string `json:"recipient_key"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.RecipientKey != "" && !h.allow(w, r, features.Decrypt) {
+			return
+		}
+		ks, ok := h.keySet(w, r)
+		if !ok {
+			return
+		}
+		ct, err := ks.Uint8.BitCount(r.Context(), op, req.Ciphertext)
+		err = inputField(err, "ciphertext")
+		if err == nil && req.RecipientKey != "" {
+			ct, err = ks.Int(32).ReencryptFor(r.Context(), ct, req.RecipientKey)
+			err = inputField(err, "recipient_key")
+		}
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCiphertext(w, ct)
+	}
+}
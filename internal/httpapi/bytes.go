@@ -0,0 +1,292 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"unicode/utf8"
+
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/tfhe"
+)
+
+// typeUint8Vector is the Ciphertext-Type of a packed vector of uint8
+// ciphertexts.
+const typeUint8Vector = "uint8_vector"
+
+func (h *Handler) registerBytesRoutes(mux routes) {
+	h.route(mux, features.Encrypt, "/bytes/encrypt", h.bytesEncrypt)
+	h.route(mux, features.Decrypt, "/bytes/decrypt", h.bytesDecrypt)
+	handle(mux, "/bytes/eq", h.bytesEqual)
+	handle(mux, "/bytes/checksum", h.bytesChecksum)
+}
+
+// bytesEncrypt handles POST /bytes/encrypt: encrypts base64 data, or UTF-8
+// text, as one uint8 ciphertext per byte. The ciphertexts are answered as a
+// JSON array, packed into one vector ciphertext with "packed": true, or
+// streamed as a vector with Accept: application/octet-stream.
+func (h *Handler) bytesEncrypt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Data   *string `json:"data"`
+		Text   *string `json:"text"`
+		Packed bool    `json:"packed"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	var plaintext []byte
+	switch {
+	case req.Data != nil && req.Text != nil:
+		writeError(w, http.StatusBadRequest, fieldErr("text", errors.New("data and text are exclusive")))
+		return
+	case req.Data != nil:
+		var err error
+		if plaintext, err = base64.StdEncoding.DecodeString(*req.Data); err != nil {
+			writeError(w, http.StatusBadRequest, fieldErr("data", fmt.Errorf("data: %w", err)))
+			return
+		}
+	case req.Text != nil:
+		plaintext = []byte(*req.Text)
+	default:
+		writeError(w, http.StatusBadRequest, fieldErr("data", errors.New("either data or text is required")))
+		return
+	}
+
+	cts, err := ks.Uint8.EncryptBytesRaw(r.Context(), plaintext)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Accept")); mediaType == "application/octet-stream" {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Ciphertext-Type", typeUint8Vector)
+		w.Header().Set("Ciphertext-Format-Version", strconv.Itoa(CiphertextFormatVersion))
+		_, _ = tfhe.WriteVector(w, cts)
+		return
+	}
+	if req.Packed {
+		writeJSON(w, http.StatusOK, map[string]any{
+			"ciphertext":     base64.StdEncoding.EncodeToString(tfhe.MarshalVector(cts)),
+			"type":           typeUint8Vector,
+			"format_version": CiphertextFormatVersion,
+		})
+		return
+	}
+	out := make([]string, len(cts))
+	for i, ct := range cts {
+		out[i] = bufpool.EncodeBase64(ct)
+	}
+	writeJSON(w, http.StatusOK, gateBatchResponse{Ciphertexts: out, FormatVersion: CiphertextFormatVersion})
+}
+
+// bytesDecrypt handles POST /bytes/decrypt: decrypts uint8 ciphertexts, one
+// per byte, sent as readByteString accepts them. It answers with the bytes
+// in base64 and, when they are valid UTF-8, as text.
+func (h *Handler) bytesDecrypt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cts, _, ok := readByteString(w, r)
+	if !ok {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+
+	data, err := ks.Uint8.DecryptBytesRaw(r.Context(), cts)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	resp := map[string]string{"data": base64.StdEncoding.EncodeToString(data)}
+	if utf8.Valid(data) {
+		resp["text"] = string(data)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// bytesChecksum handles POST /bytes/checksum: folds an encrypted byte
+// string, sent as readByteString accepts it, into one uint8 ciphertext
+// holding the XOR of its bytes ("kind": "xor", the default) or their sum
+// modulo 256 ("kind": "sum").
+func (h *Handler) bytesChecksum(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	cts, kind, ok := readByteString(w, r)
+	if !ok {
+		return
+	}
+	if kind == "" {
+		kind = string(tfhe.ChecksumXor)
+	}
+	if !slices.Contains(tfhe.Checksums, tfhe.Checksum(kind)) {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported checksum %q", kind))
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+
+	out, err := ks.Uint8.ChecksumBytesRaw(r.Context(), tfhe.Checksum(kind), cts, h.batchConcurrency)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeCiphertext(w, bufpool.EncodeBase64(out))
+}
+
+// readByteString reads the body of a request carrying an encrypted byte
+// string: a JSON object with the uint8 ciphertexts as a "ciphertexts" array
+// or one packed vector "ciphertext", or the vector itself in an
+// application/octet-stream body, taking the "kind" query parameter
+// instead. It returns the ciphertexts and the JSON "kind" field, and on
+// failure writes the error response and reports false.
+func readByteString(w http.ResponseWriter, r *http.Request) (cts [][]byte, kind string, ok bool) {
+	maxElem := tfhe.CurrentLimits().MaxUint8Ciphertext
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/octet-stream" {
+		cts, err := tfhe.ReadVector(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes), tfhe.MaxBytesLen, maxElem)
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, err)
+				return nil, "", false
+			}
+			writeError(w, statusFor(err), err)
+			return nil, "", false
+		}
+		return cts, r.URL.Query().Get("kind"), true
+	}
+	var req struct {
+		Ciphertexts []string `json:"ciphertexts"`
+		Ciphertext  string   `json:"ciphertext"`
+		Kind        string   `json:"kind"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return nil, "", false
+	}
+	switch {
+	case req.Ciphertext != "" && req.Ciphertexts != nil:
+		writeError(w, http.StatusBadRequest, fieldErr("ciphertexts", errors.New("ciphertext and ciphertexts are exclusive")))
+		return nil, "", false
+	case req.Ciphertext != "":
+		packed, err := base64.StdEncoding.DecodeString(req.Ciphertext)
+		if err == nil {
+			cts, err = tfhe.UnmarshalVector(packed, tfhe.MaxBytesLen, maxElem)
+		}
+		if err != nil {
+			writeError(w, statusFor(err), fieldErr("ciphertext", fmt.Errorf("ciphertext: %w", err)))
+			return nil, "", false
+		}
+	default:
+		if len(req.Ciphertexts) > tfhe.MaxBytesLen {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%d ciphertexts, limit is %d", len(req.Ciphertexts), tfhe.MaxBytesLen))
+			return nil, "", false
+		}
+		cts = make([][]byte, len(req.Ciphertexts))
+		for i, ct := range req.Ciphertexts {
+			raw, err := base64.StdEncoding.DecodeString(ct)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("ciphertexts[%d]", i), fmt.Errorf("byte %d: %w", i, err)))
+				return nil, "", false
+			}
+			cts[i] = raw
+		}
+	}
+	return cts, req.Kind, true
+}
+
+// bytesEqual handles POST /bytes/eq: compares two encrypted byte strings,
+// each an array of uint8 ciphertexts or one packed vector, and answers with
+// an encrypted flag set when they are equal or, with "prefix": true, when
+// left starts with right. Only the lengths, which the ciphertexts reveal
+// anyway, decide the result without evaluation.
+func (h *Handler) bytesEqual(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Left   json.RawMessage `json:"left"`
+		Right  json.RawMessage `json:"right"`
+		Prefix bool            `json:"prefix"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	left, err := decodeByteString(req.Left)
+	if err != nil {
+		writeError(w, statusFor(err), fieldErr("left", fmt.Errorf("left: %w", err)))
+		return
+	}
+	right, err := decodeByteString(req.Right)
+	if err != nil {
+		writeError(w, statusFor(err), fieldErr("right", fmt.Errorf("right: %w", err)))
+		return
+	}
+
+	var out []byte
+	if req.Prefix {
+		out, err = ks.Uint8.HasPrefixRaw(r.Context(), left, right, h.batchConcurrency)
+	} else {
+		out, err = ks.Uint8.EqualBytesRaw(r.Context(), left, right, h.batchConcurrency)
+	}
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeCiphertext(w, bufpool.EncodeBase64(out))
+}
+
+// decodeByteString decodes an encrypted byte string sent as a JSON array of
+// uint8 ciphertexts or as one packed vector.
+func decodeByteString(raw json.RawMessage) ([][]byte, error) {
+	maxElem := tfhe.CurrentLimits().MaxUint8Ciphertext
+	var packed string
+	if err := json.Unmarshal(raw, &packed); err == nil {
+		data, err := base64.StdEncoding.DecodeString(packed)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", tfhe.ErrInvalidCiphertext, err)
+		}
+		return tfhe.UnmarshalVector(data, tfhe.MaxBytesLen, maxElem)
+	}
+	var cts []string
+	if err := json.Unmarshal(raw, &cts); err != nil {
+		return nil, fmt.Errorf("%w: want an array of ciphertexts or a packed vector", tfhe.ErrInvalidCiphertext)
+	}
+	if len(cts) > tfhe.MaxBytesLen {
+		return nil, fmt.Errorf("%w: %d ciphertexts, limit is %d", tfhe.ErrCiphertextTooLarge, len(cts), tfhe.MaxBytesLen)
+	}
+	out := make([][]byte, len(cts))
+	for i, ct := range cts {
+		data, err := base64.StdEncoding.DecodeString(ct)
+		if err != nil {
+			return nil, fmt.Errorf("%w: byte %d: %v", tfhe.ErrInvalidCiphertext, i, err)
+		}
+		out[i] = data
+	}
+	return out, nil
+}
@@ -0,0 +1,177 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tfhe-go/internal/cbor"
+)
+
+const mediaTypeCBOR = "application/cbor"
+
+// CBOR lets clients exchange application/cbor instead of JSON, with
+// ciphertexts and keys as byte strings rather than base64 text. A CBOR
+// request body is converted to JSON at the edge, its byte strings becoming
+// base64, and a JSON response is converted to CBOR, its ciphertext fields
+// becoming byte strings, when the Accept header prefers application/cbor or,
+// for a CBOR request, does not ask for JSON. Other responses and WebSocket
+// upgrades are passed through. CBOR cannot be combined with a ciphertext
+// encoding other than base64.
+func CBOR(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		request := mediaType == mediaTypeCBOR
+		response := acceptsCBOR(r.Header.Get("Accept"), request)
+		if r.Header.Get("Upgrade") != "" || !request && !response {
+			next.ServeHTTP(w, r)
+			return
+		}
+		out := w
+		if response {
+			cw := &cborWriter{ResponseWriter: w, status: http.StatusOK}
+			defer cw.finish()
+			out = cw
+		}
+		if encoding := requestEncoding(r); encoding != "" && encoding != encodingBase64 {
+			writeError(out, http.StatusBadRequest, fmt.Errorf("ciphertext encoding %q cannot be combined with CBOR", encoding))
+			return
+		}
+		if request {
+			if err := decodeCBOR(w, r); err != nil {
+				status := http.StatusBadRequest
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) {
+					status = http.StatusRequestEntityTooLarge
+				}
+				writeError(out, status, err)
+				return
+			}
+		}
+		next.ServeHTTP(out, r)
+	})
+}
+
+// acceptsCBOR reports whether a response should be CBOR: when accept rates
+// application/cbor at least as high as application/json or, for a CBOR
+// request, does not mention either.
+func acceptsCBOR(accept string, request bool) bool {
+	q := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[strings.ToLower(strings.TrimSpace(name))] = weight
+	}
+	cborQ, cborListed := q[mediaTypeCBOR]
+	jsonQ, jsonListed := q["application/json"]
+	if cborListed {
+		return cborQ > 0 && (!jsonListed || cborQ >= jsonQ)
+	}
+	return request && !jsonListed
+}
+
+// decodeCBOR rewrites a CBOR request body as JSON, with byte strings in
+// base64.
+func decodeCBOR(w http.ResponseWriter, r *http.Request) error {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+	if err != nil || len(raw) == 0 {
+		return err
+	}
+	body, err := cbor.Unmarshal(raw)
+	if err != nil {
+		return err
+	}
+	return replaceBody(r, base64Strings(body))
+}
+
+// base64Strings replaces the byte strings in v with base64 text.
+func base64Strings(v any) any {
+	switch v := v.(type) {
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case map[string]any:
+		for key, child := range v {
+			v[key] = base64Strings(child)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = base64Strings(child)
+		}
+	}
+	return v
+}
+
+// byteStrings replaces the base64 strings under the ciphertext fields of v
+// with their bytes, as transcode does with the other encodings.
+func byteStrings(v any, field string) any {
+	switch v := v.(type) {
+	case string:
+		if !ciphertextFields[field] {
+			return v
+		}
+		if raw, err := base64.StdEncoding.DecodeString(v); err == nil {
+			return raw
+		}
+	case map[string]any:
+		for key, child := range v {
+			v[key] = byteStrings(child, key)
+		}
+	case []any:
+		for i, child := range v {
+			v[i] = byteStrings(child, field)
+		}
+	}
+	return v
+}
+
+// cborWriter buffers a response so it can be converted to CBOR.
+type cborWriter struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (cw *cborWriter) WriteHeader(status int) { cw.status = status }
+
+func (cw *cborWriter) Write(p []byte) (int, error) { return cw.buf.Write(p) }
+
+// finish writes the buffered response, as CBOR when it is JSON. Error
+// responses are converted too, so clients decode a single media type.
+func (cw *cborWriter) finish() {
+	w, data := cw.ResponseWriter, cw.buf.Bytes()
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	var body any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if mediaType != "application/json" || dec.Decode(&body) != nil {
+		w.WriteHeader(cw.status)
+		_, _ = w.Write(data)
+		return
+	}
+	out, err := cbor.Marshal(byteStrings(body, ""))
+	if err != nil {
+		w.WriteHeader(cw.status)
+		_, _ = w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Type", mediaTypeCBOR)
+	w.Header().Set("Content-Length", strconv.Itoa(len(out)))
+	w.WriteHeader(cw.status)
+	_, _ = w.Write(out)
+}
@@ -0,0 +1,365 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tfhe-go/internal/acl"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/rotation"
+	"tfhe-go/internal/store"
+	"tfhe-go/internal/tfhe"
+)
+
+const (
+	typeBoolean = "boolean"
+	typeUint8   = "uint8"
+)
+
+// SetRotation makes writes to the store wait for a key rotation by m, so a
+// handle stored during it is not left under the old keys.
+func (h *Handler) SetRotation(m *rotation.Manager) {
+	h.rotation = m
+}
+
+// ciphertexts handles GET /ciphertexts (list handles) and POST /ciphertexts
+// (create a handle).
+func (h *Handler) ciphertexts(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet {
+		h.listCiphertexts(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if isUpload(r) {
+		h.uploadCiphertext(w, r)
+		return
+	}
+	var req struct {
+		Type       string          `json:"type"`
+		Value      json.RawMessage `json:"value"`
+		Ciphertext string          `json:"ciphertext"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer h.rotation.HoldWrites()()
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if req.Type != typeBoolean && req.Type != typeUint8 {
+		writeError(w, http.StatusBadRequest, fieldErr("type", fmt.Errorf("unsupported type %q", req.Type)))
+		return
+	}
+
+	var data []byte
+	var err error
+	switch {
+	case req.Ciphertext != "":
+		data, err = base64.StdEncoding.DecodeString(req.Ciphertext)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if err := tfhe.CheckSerialized(data, maxCiphertext(req.Type)); err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+	case len(req.Value) == 0:
+		writeError(w, http.StatusBadRequest, fieldErr("value", errors.New("either value or ciphertext is required")))
+		return
+	default:
+		data, err = h.encryptValue(r.Context(), ks, req.Type, req.Value)
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+	}
+
+	h.putCiphertext(w, r, ks, req.Type, data)
+}
+
+// uploadCiphertext creates a handle from a serialized ciphertext sent as
+// binary rather than base64 JSON: an application/octet-stream body with
+// ?type=, or multipart/form-data with a "type" field and a "ciphertext" file.
+func (h *Handler) uploadCiphertext(w http.ResponseWriter, r *http.Request) {
+	fields, blob, ok := readUpload(w, r, "ciphertext", int64(tfhe.CurrentLimits().MaxCiphertext()))
+	if !ok {
+		return
+	}
+	defer blob.Close()
+	defer h.rotation.HoldWrites()()
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	typ := fields["type"]
+	if typ != typeBoolean && typ != typeUint8 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported type %q", typ))
+		return
+	}
+	data, err := blob.Bytes()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if err := tfhe.CheckSerialized(data, maxCiphertext(typ)); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	h.putCiphertext(w, r, ks, typ, data)
+}
+
+func (h *Handler) putCiphertext(w http.ResponseWriter, r *http.Request, ks *keys.KeySet, typ string, data []byte) {
+	entry, err := h.store.Put(tenantOf(r), typ, keyRef(ks), data)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.trackHandle(r, entry.ID)
+	writeJSON(w, http.StatusCreated, map[string]string{"handle": entry.ID, "type": entry.Type})
+}
+
+// keyRef records that a ciphertext is stored under ks's current keys, so a
+// rotation of ks re-encrypts it and a rotation of another set leaves it be.
+func keyRef(ks *keys.KeySet) store.KeyRef {
+	return store.KeyRef{KeySet: ks.ID, Generation: ks.Uint8.KeyGeneration()}
+}
+
+// ciphertext handles GET /ciphertexts/{id}, for callers who may use the
+// handle, and DELETE /ciphertexts/{id}, for its owning tenant.
+func (h *Handler) ciphertext(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	switch r.Method {
+	case http.MethodGet:
+		entry, err := h.openHandle(r, id, acl.Use)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Accept")); mediaType == "application/octet-stream" {
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Header().Set("Ciphertext-Type", entry.Type)
+			w.Header().Set("Ciphertext-Format-Version", strconv.Itoa(CiphertextFormatVersion))
+			_, _ = w.Write(entry.Data)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"handle":         entry.ID,
+			"type":           entry.Type,
+			"ciphertext":     bufpool.EncodeBase64(entry.Data),
+			"format_version": CiphertextFormatVersion,
+			"created_at":     entry.CreatedAt.Format(time.RFC3339),
+		})
+	case http.MethodDelete:
+		entry, err := h.store.Get(id)
+		if err == nil && entry.Tenant != tenantOf(r) {
+			err = fmt.Errorf("%w: %s", store.ErrNotFound, id)
+		}
+		if err == nil {
+			err = h.store.Delete(id)
+		}
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		if h.acl != nil {
+			h.acl.Forget(id)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// ciphertextOp handles POST /ciphertexts/ops: runs op over stored handles and stores the result.
+func (h *Handler) ciphertextOp(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Op       string   `json:"op"`
+		Operands []string `json:"operands"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	defer h.rotation.HoldWrites()()
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if len(req.Operands) == 0 {
+		writeError(w, http.StatusBadRequest, fieldErr("operands", errors.New("operands are required")))
+		return
+	}
+
+	var typ string
+	operands := make([][]byte, 0, len(req.Operands))
+	for _, id := range req.Operands {
+		entry, err := h.openHandle(r, id, acl.Use)
+		if err != nil {
+			writeStoreError(w, err)
+			return
+		}
+		if typ != "" && entry.Type != typ {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("operand %s has type %s, expected %s", id, entry.Type, typ))
+			return
+		}
+		typ = entry.Type
+		operands = append(operands, entry.Data)
+	}
+
+	fn, err := h.resolveOp(ks, typ, req.Op, len(operands))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	out, err := fn(r.Context(), operands)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	entry, err := h.store.Put(tenantOf(r), typ, keyRef(ks), out)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	h.trackHandle(r, entry.ID)
+	writeJSON(w, http.StatusCreated, map[string]string{"handle": entry.ID, "type": entry.Type})
+}
+
+// listCiphertexts handles GET /ciphertexts: handle metadata, oldest first,
+// filtered by tenant, type and creation time and paged by an opaque cursor.
+// Authenticated callers only see their own tenant's handles.
+func (h *Handler) listCiphertexts(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := store.ListOptions{
+		Filter: store.Filter{Tenant: q.Get("tenant"), Type: q.Get("type")},
+		Cursor: q.Get("cursor"),
+	}
+	if id, ok := auth.FromContext(r.Context()); ok {
+		if opts.Tenant != "" && opts.Tenant != id.Tenant {
+			writeError(w, http.StatusForbidden, fmt.Errorf("cannot list handles of tenant %q", opts.Tenant))
+			return
+		}
+		opts.Tenant = id.Tenant
+	}
+	for name, dst := range map[string]*time.Time{"created_after": &opts.CreatedAfter, "created_before": &opts.CreatedBefore} {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("%s: %w", name, err))
+				return
+			}
+			*dst = t
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > store.MaxPageSize {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit must be between 1 and %d", store.MaxPageSize))
+			return
+		}
+		opts.Limit = n
+	}
+
+	page, err := h.store.ListPage(opts)
+	if errors.Is(err, store.ErrInvalidCursor) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	type handleInfo struct {
+		Handle    string `json:"handle"`
+		Type      string `json:"type"`
+		Tenant    string `json:"tenant,omitempty"`
+		Size      int    `json:"size"`
+		CreatedAt string `json:"created_at"`
+	}
+	handles := make([]handleInfo, len(page.Entries))
+	for i, e := range page.Entries {
+		handles[i] = handleInfo{
+			Handle:    e.ID,
+			Type:      e.Type,
+			Tenant:    e.Tenant,
+			Size:      len(e.Data),
+			CreatedAt: e.CreatedAt.Format(time.RFC3339Nano),
+		}
+	}
+	body := map[string]any{"handles": handles}
+	if page.Next != "" {
+		body["next_cursor"] = page.Next
+	}
+	writeJSON(w, http.StatusOK, body)
+}
+
+// tenantOf returns the caller's tenant, or "" for unauthenticated requests.
+func tenantOf(r *http.Request) string {
+	id, _ := auth.FromContext(r.Context())
+	return id.Tenant
+}
+
+func (h *Handler) encryptValue(ctx context.Context, ks *keys.KeySet, typ string, value json.RawMessage) ([]byte, error) {
+	switch typ {
+	case typeBoolean:
+		var v bool
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, err
+		}
+		return ks.Boolean.EncryptRaw(ctx, v)
+	case typeUint8:
+		var v uint8
+		if err := json.Unmarshal(value, &v); err != nil {
+			return nil, err
+		}
+		return ks.Uint8.EncryptRaw(ctx, v)
+	}
+	return nil, fmt.Errorf("unsupported type %q", typ)
+}
+
+// maxCiphertext returns the configured size limit for ciphertexts of typ.
+func maxCiphertext(typ string) int {
+	l := tfhe.CurrentLimits()
+	switch typ {
+	case typeBoolean:
+		return l.MaxBooleanCiphertext
+	case typeBool:
+		return l.MaxFheBoolCiphertext
+	}
+	for _, bits := range tfhe.IntWidths {
+		if typ == intTypeName(bits) {
+			return l.MaxIntCiphertext(bits)
+		}
+	}
+	return l.MaxUint8Ciphertext
+}
+
+func writeStoreError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, store.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, acl.ErrDenied):
+		writeError(w, http.StatusForbidden, err)
+	case errors.Is(err, store.ErrCapacity):
+		writeError(w, http.StatusInsufficientStorage, err)
+	default:
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
@@ -0,0 +1,52 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"net/http"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// maxCompactBody bounds POST /compact/expand: a base64 list of
+// tfhe.MaxCompactList bytes plus the JSON around it.
+const maxCompactBody = tfhe.MaxCompactList/3*4 + 1<<10
+
+type expandedCiphertext struct {
+	Type       string `json:"type"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// expandCompact handles POST /compact/expand: expands a compact ciphertext
+// list, built by the client under the compact public key, into the
+// ciphertexts it holds, in order.
+func (h *Handler) expandCompact(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		List string `json:"list"`
+	}
+	if !readJSONLimit(w, r, &req, maxCompactBody) {
+		return
+	}
+	data, err := base64.StdEncoding.DecodeString(req.List)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	out, err := ks.Uint8.Expand(r.Context(), tenantOf(r), data)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	cts := make([]expandedCiphertext, len(out))
+	for i, ct := range out {
+		cts[i] = expandedCiphertext{Type: ct.Type, Ciphertext: base64.StdEncoding.EncodeToString(ct.Data)}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"ciphertexts": cts, "format_version": CiphertextFormatVersion})
+}
@@ -0,0 +1,229 @@
+package httpapi
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// CompressionOptions configures Content-Encoding support.
+type CompressionOptions struct {
+	// MinSize is the smallest response body compressed; defaults to 1 KiB.
+	MinSize int
+	// Level is the compression level; defaults to flate.DefaultCompression.
+	Level int
+}
+
+// Compress decodes gzip or deflate request bodies and compresses responses of
+// at least MinSize bytes with the best encoding the client accepts. Request
+// size limits apply to the decoded body.
+func Compress(opts CompressionOptions) func(http.Handler) http.Handler {
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1 << 10
+	}
+	if opts.Level == 0 {
+		opts.Level = flate.DefaultCompression
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := decodeRequest(r); err != nil {
+				writeError(w, http.StatusUnsupportedMediaType, err)
+				return
+			}
+			w.Header().Add("Vary", "Accept-Encoding")
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, encoding: encoding, opts: opts, status: http.StatusOK}
+			defer cw.Close()
+			next.ServeHTTP(cw, r)
+		})
+	}
+}
+
+// decodeRequest replaces a compressed request body with its decoded stream.
+func decodeRequest(r *http.Request) error {
+	switch encoding := strings.ToLower(strings.TrimSpace(r.Header.Get("Content-Encoding"))); encoding {
+	case "", "identity":
+		return nil
+	case "gzip", "x-gzip":
+		zr, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return fmt.Errorf("invalid gzip body: %w", err)
+		}
+		r.Body = readCloser{zr, r.Body}
+	case "deflate":
+		// HTTP's deflate is the zlib format, not raw DEFLATE.
+		zr, err := zlib.NewReader(r.Body)
+		if err != nil {
+			return fmt.Errorf("invalid deflate body: %w", err)
+		}
+		r.Body = readCloser{zr, r.Body}
+	default:
+		return fmt.Errorf("unsupported Content-Encoding %q", encoding)
+	}
+	r.Header.Del("Content-Encoding")
+	r.Header.Del("Content-Length")
+	r.ContentLength = -1
+	return nil
+}
+
+type readCloser struct {
+	io.Reader
+	body io.Closer
+}
+
+func (rc readCloser) Close() error { return rc.body.Close() }
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header,
+// preferring gzip on ties. Codings with q=0 are refused, and "*" stands for
+// those not listed.
+func negotiateEncoding(accept string) string {
+	q := make(map[string]float64)
+	for _, part := range strings.Split(accept, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[strings.ToLower(strings.TrimSpace(name))] = weight
+	}
+	if wildcard, ok := q["*"]; ok {
+		for _, name := range []string{"gzip", "deflate"} {
+			if _, listed := q[name]; !listed {
+				q[name] = wildcard
+			}
+		}
+	}
+	switch {
+	case q["gzip"] > 0 && q["gzip"] >= q["deflate"]:
+		return "gzip"
+	case q["deflate"] > 0:
+		return "deflate"
+	}
+	return ""
+}
+
+// compressWriter buffers the start of a response until it knows whether the
+// body reaches MinSize, then either compresses or passes it through.
+type compressWriter struct {
+	http.ResponseWriter
+	encoding    string
+	opts        CompressionOptions
+	status      int
+	wroteHeader bool // WriteHeader was called by the handler
+	decided     bool // headers have been sent downstream
+	buf         []byte
+	enc         io.WriteCloser
+}
+
+func (cw *compressWriter) WriteHeader(status int) {
+	if cw.wroteHeader {
+		return
+	}
+	cw.wroteHeader = true
+	cw.status = status
+	if status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		cw.decide(false)
+	}
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.wroteHeader {
+		cw.WriteHeader(http.StatusOK)
+	}
+	if cw.decided {
+		if cw.enc != nil {
+			return cw.enc.Write(p)
+		}
+		return cw.ResponseWriter.Write(p)
+	}
+	cw.buf = append(cw.buf, p...)
+	if len(cw.buf) >= cw.opts.MinSize {
+		if err := cw.flushBuffer(true); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+// decide sends the headers, compressed or not.
+func (cw *compressWriter) decide(compress bool) {
+	if cw.decided {
+		return
+	}
+	cw.decided = true
+	h := cw.Header()
+	if compress && h.Get("Content-Encoding") == "" {
+		h.Set("Content-Encoding", cw.encoding)
+		h.Del("Content-Length")
+		if cw.encoding == "gzip" {
+			cw.enc, _ = gzip.NewWriterLevel(cw.ResponseWriter, cw.opts.Level)
+		} else {
+			cw.enc, _ = zlib.NewWriterLevel(cw.ResponseWriter, cw.opts.Level)
+		}
+	}
+	cw.ResponseWriter.WriteHeader(cw.status)
+}
+
+func (cw *compressWriter) flushBuffer(compress bool) error {
+	cw.decide(compress)
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if cw.enc != nil {
+		_, err = cw.enc.Write(buf)
+	} else {
+		_, err = cw.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// Close sends a short buffered response uncompressed and finishes the
+// compressed stream.
+func (cw *compressWriter) Close() error {
+	if !cw.wroteHeader {
+		// The handler wrote nothing; let net/http send its default response.
+		return nil
+	}
+	if err := cw.flushBuffer(false); err != nil {
+		return err
+	}
+	if cw.enc != nil {
+		return cw.enc.Close()
+	}
+	return nil
+}
+
+// Flush sends what has been written so far, deciding on compression first.
+func (cw *compressWriter) Flush() {
+	if cw.wroteHeader {
+		_ = cw.flushBuffer(len(cw.buf) >= cw.opts.MinSize)
+	}
+	if f, ok := cw.enc.(interface{ Flush() error }); ok {
+		_ = f.Flush()
+	}
+	_ = http.NewResponseController(cw.ResponseWriter).Flush()
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter { return cw.ResponseWriter }
+
+// Hijack hands the connection to WebSocket upgrades uncompressed.
+func (cw *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	cw.decided = true
+	return http.NewResponseController(cw.ResponseWriter).Hijack()
+}
@@ -0,0 +1,132 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// registerConvertRoutes registers the conversions between uint8 ciphertexts,
+// FheBools and boolean API ciphertexts.
+func (h *Handler) registerConvertRoutes(mux routes) {
+	handle(mux, "/bool/to_uint8", h.convert(func(ctx context.Context, ks *keys.KeySet, ct []byte) ([]byte, error) {
+		return ks.Uint8.BoolToUint8Raw(ctx, ct)
+	}))
+	handle(mux, "/uint8/to_bool", h.convert(func(ctx context.Context, ks *keys.KeySet, ct []byte) ([]byte, error) {
+		return ks.Uint8.Uint8ToBoolRaw(ctx, ct)
+	}))
+	handle(mux, "/boolean/to_bool", h.convert(func(ctx context.Context, ks *keys.KeySet, ct []byte) ([]byte, error) {
+		return tfhe.BooleanToFheBool(ctx, ks.Boolean, ks.Uint8, ct)
+	}))
+	handle(mux, "/bool/to_boolean", h.convert(func(ctx context.Context, ks *keys.KeySet, ct []byte) ([]byte, error) {
+		return tfhe.FheBoolToBoolean(ctx, ks.Uint8, ks.Boolean, ct)
+	}))
+	handle(mux, "/uint8/bits", h.uint8Bits)
+	handle(mux, "/uint8/compose", h.uint8Compose)
+}
+
+// convert serves a conversion of one ciphertext into another type.
+func (h *Handler) convert(fn func(ctx context.Context, ks *keys.KeySet, ct []byte) ([]byte, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Ciphertext string `json:"ciphertext"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		ks, ok := h.keySet(w, r)
+		if !ok {
+			return
+		}
+		raw, err := bufpool.DecodeBase64(req.Ciphertext)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		defer bufpool.Put(raw)
+		out, err := fn(r.Context(), ks, *raw)
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCiphertext(w, bufpool.EncodeBase64(out))
+	}
+}
+
+// uint8Bits handles POST /uint8/bits: splits a uint8 ciphertext into eight
+// FheBools, least significant first.
+func (h *Handler) uint8Bits(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	raw, err := bufpool.DecodeBase64(req.Ciphertext)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	defer bufpool.Put(raw)
+	out, err := ks.Uint8.BitsRaw(r.Context(), *raw)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	cts := make([]string, len(out))
+	for i, b := range out {
+		cts[i] = bufpool.EncodeBase64(b)
+	}
+	writeJSON(w, http.StatusOK, gateBatchResponse{Ciphertexts: cts, FormatVersion: CiphertextFormatVersion})
+}
+
+// uint8Compose handles POST /uint8/compose: packs one to eight FheBools,
+// least significant first, into a uint8 ciphertext.
+func (h *Handler) uint8Compose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Ciphertexts []string `json:"ciphertexts"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	bits := make([][]byte, len(req.Ciphertexts))
+	for i, ct := range req.Ciphertexts {
+		raw, err := bufpool.DecodeBase64(ct)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("ciphertexts[%d]", i), fmt.Errorf("bit %d: %w", i, err)))
+			return
+		}
+		defer bufpool.Put(raw)
+		bits[i] = *raw
+	}
+	out, err := ks.Uint8.ComposeRaw(r.Context(), bits)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeCiphertext(w, bufpool.EncodeBase64(out))
+}
@@ -0,0 +1,91 @@
+package httpapi
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORSOptions configures cross-origin access for browser clients.
+type CORSOptions struct {
+	// AllowedOrigins lists exact origins, or "*" for any origin.
+	AllowedOrigins []string
+	// AllowedMethods defaults to GET, POST, DELETE.
+	AllowedMethods []string
+	// AllowedHeaders defaults to the headers the API reads.
+	AllowedHeaders []string
+	// ExposedHeaders lists response headers readable by scripts.
+	ExposedHeaders []string
+	// AllowCredentials lets browsers send cookies and auth headers; it is
+	// ignored for the "*" origin, which browsers reject with credentials.
+	AllowCredentials bool
+	// MaxAge bounds how long preflight results are cached; defaults to 10 minutes.
+	MaxAge time.Duration
+}
+
+// DefaultCORSHeaders are the request headers the API understands.
+var DefaultCORSHeaders = []string{"Authorization", "Content-Type", "Content-Encoding", "Idempotency-Key", "Session-Id", "Request-Timestamp", "Request-Nonce", "Request-Body-Digest", "Request-Signature", "X-API-Key", "Traceparent", "Tracestate", "Debug-Timing"}
+
+// CORS answers preflight requests and annotates responses to allowed
+// origins. It must wrap authentication, since preflights carry no credentials.
+// Requests from other origins pass through unannotated and are blocked by the
+// browser.
+func CORS(opts CORSOptions) func(http.Handler) http.Handler {
+	if len(opts.AllowedMethods) == 0 {
+		opts.AllowedMethods = []string{http.MethodGet, http.MethodPost, http.MethodDelete}
+	}
+	if len(opts.AllowedHeaders) == 0 {
+		opts.AllowedHeaders = DefaultCORSHeaders
+	}
+	if opts.MaxAge <= 0 {
+		opts.MaxAge = 10 * time.Minute
+	}
+	anyOrigin := slices.Contains(opts.AllowedOrigins, "*")
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			if origin == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			allowed := anyOrigin || slices.Contains(opts.AllowedOrigins, origin)
+			preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+			if allowed {
+				if anyOrigin && !opts.AllowCredentials {
+					h.Set("Access-Control-Allow-Origin", "*")
+				} else {
+					h.Set("Access-Control-Allow-Origin", origin)
+					if opts.AllowCredentials {
+						h.Set("Access-Control-Allow-Credentials", "true")
+					}
+				}
+			}
+			if !preflight {
+				if allowed && exposed != "" {
+					h.Set("Access-Control-Expose-Headers", exposed)
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			if allowed && slices.Contains(opts.AllowedMethods, r.Header.Get("Access-Control-Request-Method")) {
+				h.Set("Access-Control-Allow-Methods", methods)
+				h.Set("Access-Control-Allow-Headers", headers)
+				h.Set("Access-Control-Max-Age", maxAge)
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
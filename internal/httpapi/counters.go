@@ -0,0 +1,368 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/counters"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+const (
+	// maxCounterIncrements bounds the increments of one request.
+	maxCounterIncrements = 1024
+	// defaultCounterType is the width of counters created without a type.
+	defaultCounterType = "uint32"
+)
+
+// errStaleCounter reports a counter encrypted under keys since rotated or
+// under another key set than the caller's.
+var errStaleCounter = errors.New("counter is encrypted under other keys")
+
+// SetCounters enables the encrypted counter routes backed by reg.
+func (h *Handler) SetCounters(reg *counters.Registry) {
+	h.counters = reg
+}
+
+// SetCounters enables the routes decrypting counter totals backed by reg.
+func (h *AdminHandler) SetCounters(reg *counters.Registry) {
+	h.counters = reg
+}
+
+// counterOps are the operations a counter of one type needs.
+type counterOps struct {
+	add     func(ctx context.Context, lhs, rhs []byte) ([]byte, error)
+	zero    func(ctx context.Context) ([]byte, error)
+	decrypt func(ctx context.Context, data []byte) (uint64, error)
+}
+
+// counterService returns the operations for counters of typ, an unsigned
+// integer type, under ks.
+func counterService(ks *keys.KeySet, typ string) (counterOps, error) {
+	if typ == typeUint8 {
+		return counterOps{
+			add:  ks.Uint8.AddRaw,
+			zero: func(ctx context.Context) ([]byte, error) { return ks.Uint8.EncryptWithPublicRaw(ctx, 0) },
+			decrypt: func(ctx context.Context, data []byte) (uint64, error) {
+				v, err := ks.Uint8.DecryptRaw(ctx, data)
+				return uint64(v), err
+			},
+		}, nil
+	}
+	if svc := intService(ks, typ); svc != nil {
+		return counterOps{
+			add:     svc.AddRaw,
+			zero:    func(ctx context.Context) ([]byte, error) { return svc.EncryptWithPublicRaw(ctx, 0) },
+			decrypt: svc.DecryptRaw,
+		}, nil
+	}
+	return counterOps{}, fmt.Errorf("unsupported counter type %q; want uint2, uint4, uint8, uint16, uint32 or uint64", typ)
+}
+
+// counterInfo is a counter's metadata; snapshots add the total.
+type counterInfo struct {
+	Name          string `json:"name"`
+	Tenant        string `json:"tenant,omitempty"`
+	Type          string `json:"type"`
+	Increments    int64  `json:"increments"`
+	CreatedAt     string `json:"created_at"`
+	UpdatedAt     string `json:"updated_at"`
+	Ciphertext    string `json:"ciphertext,omitempty"`
+	FormatVersion int    `json:"format_version,omitempty"`
+}
+
+func newCounterInfo(c counters.Counter) counterInfo {
+	return counterInfo{
+		Name:       c.Name,
+		Type:       c.Type,
+		Increments: c.Increments,
+		CreatedAt:  c.CreatedAt.Format(time.RFC3339),
+		UpdatedAt:  c.UpdatedAt.Format(time.RFC3339),
+	}
+}
+
+func writeCounterError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, counters.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, counters.ErrExists), errors.Is(err, errStaleCounter),
+		errors.Is(err, keys.ErrUnknownKeySet), errors.Is(err, keys.ErrRevokedKeySet):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, counters.ErrInvalidName):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, counters.ErrLimit):
+		writeError(w, http.StatusForbidden, err)
+	default:
+		writeError(w, statusFor(err), err)
+	}
+}
+
+// checkCounterKeys fails with errStaleCounter unless c is encrypted under
+// ks's current keys.
+func checkCounterKeys(ks *keys.KeySet, c counters.Counter) error {
+	if c.KeySet != ks.ID {
+		return fmt.Errorf("%w: counter %s belongs to key set %s", errStaleCounter, c.Name, c.KeySet)
+	}
+	if c.Generation != ks.Uint8.KeyGeneration() {
+		return fmt.Errorf("%w: the keys of counter %s have been rotated since it was created", errStaleCounter, c.Name)
+	}
+	return nil
+}
+
+// counterList handles GET /counters (the caller's tenant's counters) and
+// POST /counters (create one, starting at an encryption of zero or at the
+// given initial ciphertext).
+func (h *Handler) counterList(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantOf(r)
+	if r.Method == http.MethodGet {
+		list := h.counters.List(tenant, false)
+		out := make([]counterInfo, len(list))
+		for i, c := range list {
+			out[i] = newCounterInfo(c)
+		}
+		writeJSON(w, http.StatusOK, map[string][]counterInfo{"counters": out})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Name    string `json:"name"`
+		Type    string `json:"type"`
+		Initial string `json:"initial"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if req.Type == "" {
+		req.Type = defaultCounterType
+	}
+	ops, err := counterService(ks, req.Type)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	// Read the generation first: if the keys rotate before the total is
+	// encrypted, the counter is reported stale rather than silently wrong.
+	c := counters.Counter{Name: req.Name, Tenant: tenant, Type: req.Type, KeySet: ks.ID, Generation: ks.Uint8.KeyGeneration()}
+	if req.Initial != "" {
+		if c.Data, err = decodeCiphertext(req.Initial, req.Type); err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+	} else if c.Data, err = ops.zero(r.Context()); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	if c, err = h.counters.Create(c); err != nil {
+		writeCounterError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newCounterInfo(c))
+}
+
+// decodeCiphertext decodes a base64 ciphertext of typ and checks its size.
+func decodeCiphertext(b64, typ string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", tfhe.ErrInvalidCiphertext, err)
+	}
+	if err := tfhe.CheckSerialized(data, maxCiphertext(typ)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// counter handles GET /counters/{name}, a snapshot of the encrypted total,
+// and DELETE /counters/{name}.
+func (h *Handler) counter(w http.ResponseWriter, r *http.Request) {
+	tenant, name := tenantOf(r), r.PathValue("name")
+	switch r.Method {
+	case http.MethodGet:
+		c, err := h.counters.Get(tenant, name)
+		if err != nil {
+			writeCounterError(w, err)
+			return
+		}
+		info := newCounterInfo(c)
+		info.Ciphertext = bufpool.EncodeBase64(c.Data)
+		info.FormatVersion = CiphertextFormatVersion
+		writeJSON(w, http.StatusOK, info)
+	case http.MethodDelete:
+		if err := h.counters.Delete(tenant, name); err != nil {
+			writeCounterError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// counterIncrement handles POST /counters/{name}/increment: adds one
+// encrypted increment, or a list of them, to the counter. A list is summed
+// first, outside the counter's lock, so only one addition waits on it.
+func (h *Handler) counterIncrement(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Increment  string   `json:"increment"`
+		Increments []string `json:"increments"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	if req.Increment != "" {
+		req.Increments = append(req.Increments, req.Increment)
+	}
+	switch {
+	case len(req.Increments) == 0:
+		writeError(w, http.StatusBadRequest, fieldErr("increment", errors.New("increment or increments is required")))
+		return
+	case len(req.Increments) > maxCounterIncrements:
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%d increments, limit is %d", len(req.Increments), maxCounterIncrements))
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	tenant, name := tenantOf(r), r.PathValue("name")
+	c, err := h.counters.Get(tenant, name)
+	if err == nil {
+		err = checkCounterKeys(ks, c)
+	}
+	if err != nil {
+		writeCounterError(w, err)
+		return
+	}
+	ops, err := counterService(ks, c.Type)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	parts := make([][]byte, len(req.Increments))
+	for i, inc := range req.Increments {
+		if parts[i], err = decodeCiphertext(inc, c.Type); err != nil {
+			writeError(w, statusFor(err), fieldErr(fmt.Sprintf("increments[%d]", i), fmt.Errorf("increment %d: %w", i, err)))
+			return
+		}
+	}
+	sum, err := h.sumIncrements(r.Context(), ops.add, parts)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	c, err = h.counters.Update(tenant, name, int64(len(parts)), func(c counters.Counter) ([]byte, error) {
+		if err := checkCounterKeys(ks, c); err != nil {
+			return nil, err
+		}
+		return ops.add(r.Context(), c.Data, sum)
+	})
+	if err != nil {
+		writeCounterError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, newCounterInfo(c))
+}
+
+// sumIncrements adds parts pairwise, level by level, running the additions
+// of a level in parallel.
+func (h *Handler) sumIncrements(ctx context.Context, add func(ctx context.Context, lhs, rhs []byte) ([]byte, error), parts [][]byte) ([]byte, error) {
+	sem := make(chan struct{}, h.batchConcurrency)
+	for len(parts) > 1 {
+		next := make([][]byte, (len(parts)+1)/2)
+		errs := make([]error, len(next))
+		var wg sync.WaitGroup
+		for i := range next {
+			if 2*i+1 == len(parts) {
+				next[i] = parts[2*i]
+				continue
+			}
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				next[i], errs[i] = add(ctx, parts[2*i], parts[2*i+1])
+			}(i)
+		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			return nil, err
+		}
+		parts = next
+	}
+	return parts[0], nil
+}
+
+// counterList handles GET /admin/counters: every tenant's counters.
+func (h *AdminHandler) counterList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	list := h.counters.List("", true)
+	out := make([]counterInfo, len(list))
+	for i, c := range list {
+		out[i] = newCounterInfo(c)
+		out[i].Tenant = c.Tenant
+	}
+	writeJSON(w, http.StatusOK, map[string][]counterInfo{"counters": out})
+}
+
+// counterDecrypt handles POST /admin/counters/decrypt: decrypts a tenant's
+// counter total with its key set's client key.
+func (h *AdminHandler) counterDecrypt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Tenant string `json:"tenant"`
+		Name   string `json:"name"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	c, err := h.counters.Get(req.Tenant, req.Name)
+	if err != nil {
+		writeCounterError(w, err)
+		return
+	}
+	ks, err := h.keys.Get(c.KeySet)
+	if err == nil {
+		err = checkCounterKeys(ks, c)
+	}
+	if err != nil {
+		writeCounterError(w, err)
+		return
+	}
+	ops, err := counterService(ks, c.Type)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	value, err := ops.decrypt(r.Context(), c.Data)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	info := newCounterInfo(c)
+	info.Tenant = c.Tenant
+	writeJSON(w, http.StatusOK, map[string]any{"counter": info, "value": value})
+}
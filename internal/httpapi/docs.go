@@ -0,0 +1,61 @@
+package httpapi
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+//go:embed openapi.json
+var openAPISpec []byte
+
+// swaggerUIPage loads Swagger UI from a CDN and points it at /openapi.json.
+const swaggerUIPage = `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>tfhe-go API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });</script>
+</body>
+</html>
+`
+
+// DocsHandler serves the OpenAPI document and, optionally, Swagger UI.
+type DocsHandler struct {
+	swaggerUI bool
+}
+
+// NewDocsHandler builds a docs handler; swaggerUI enables the /docs page.
+func NewDocsHandler(swaggerUI bool) *DocsHandler {
+	return &DocsHandler{swaggerUI: swaggerUI}
+}
+
+// Register attaches documentation routes to the provided mux.
+func (h *DocsHandler) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/openapi.json", h.spec)
+	if h.swaggerUI {
+		mux.HandleFunc("/docs", h.ui)
+	}
+}
+
+func (h *DocsHandler) spec(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(openAPISpec)
+}
+
+func (h *DocsHandler) ui(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write([]byte(swaggerUIPage))
+}
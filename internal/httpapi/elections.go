@@ -0,0 +1,311 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/elections"
+	"tfhe-go/internal/keys"
+)
+
+// errStaleElection reports an election tallied under keys since rotated or
+// under another key set than the caller's.
+var errStaleElection = errors.New("election is encrypted under other keys")
+
+// SetElections enables the encrypted voting routes backed by reg.
+func (h *Handler) SetElections(reg *elections.Registry) {
+	h.elections = reg
+}
+
+// SetElections enables the routes decrypting election totals backed by reg.
+func (h *AdminHandler) SetElections(reg *elections.Registry) {
+	h.elections = reg
+}
+
+// electionInfo is an election's metadata; snapshots of closed elections add
+// the encrypted tallies.
+type electionInfo struct {
+	Name          string   `json:"name"`
+	Tenant        string   `json:"tenant,omitempty"`
+	Candidates    []string `json:"candidates"`
+	Ballots       int64    `json:"ballots"`
+	Closed        bool     `json:"closed"`
+	CreatedAt     string   `json:"created_at"`
+	ClosedAt      string   `json:"closed_at,omitempty"`
+	Tallies       []string `json:"tallies,omitempty"`
+	FormatVersion int      `json:"format_version,omitempty"`
+}
+
+func newElectionInfo(e elections.Election) electionInfo {
+	info := electionInfo{
+		Name:       e.Name,
+		Candidates: e.Candidates,
+		Ballots:    e.Ballots,
+		Closed:     e.Closed,
+		CreatedAt:  e.CreatedAt.Format(time.RFC3339),
+	}
+	if e.Closed {
+		info.ClosedAt = e.ClosedAt.Format(time.RFC3339)
+	}
+	return info
+}
+
+func writeElectionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, elections.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, elections.ErrExists), errors.Is(err, elections.ErrClosed),
+		errors.Is(err, elections.ErrOpen), errors.Is(err, elections.ErrAlreadyVoted),
+		errors.Is(err, errStaleElection),
+		errors.Is(err, keys.ErrUnknownKeySet), errors.Is(err, keys.ErrRevokedKeySet):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, elections.ErrInvalidName), errors.Is(err, elections.ErrInvalidCandidates):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, elections.ErrLimit):
+		writeError(w, http.StatusForbidden, err)
+	default:
+		writeError(w, statusFor(err), err)
+	}
+}
+
+// checkElectionKeys fails with errStaleElection unless e is tallied under
+// ks's current keys.
+func checkElectionKeys(ks *keys.KeySet, e elections.Election) error {
+	if e.KeySet != ks.ID {
+		return fmt.Errorf("%w: election %s belongs to key set %s", errStaleElection, e.Name, e.KeySet)
+	}
+	if e.Generation != ks.Uint8.KeyGeneration() {
+		return fmt.Errorf("%w: the keys of election %s have been rotated since it was created", errStaleElection, e.Name)
+	}
+	return nil
+}
+
+// electionList handles GET /elections (the caller's tenant's elections) and
+// POST /elections (create one with encrypted zero tallies).
+func (h *Handler) electionList(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantOf(r)
+	if r.Method == http.MethodGet {
+		list := h.elections.List(tenant, false)
+		out := make([]electionInfo, len(list))
+		for i, e := range list {
+			out[i] = newElectionInfo(e)
+		}
+		writeJSON(w, http.StatusOK, map[string][]electionInfo{"elections": out})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Name       string   `json:"name"`
+		Candidates []string `json:"candidates"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if len(req.Candidates) > elections.MaxCandidates {
+		writeElectionError(w, fmt.Errorf("%w: %d candidates, limit is %d", elections.ErrInvalidCandidates, len(req.Candidates), elections.MaxCandidates))
+		return
+	}
+	// Read the generation first: if the keys rotate before the tallies are
+	// encrypted, the election is reported stale rather than silently wrong.
+	e := elections.Election{Name: req.Name, Tenant: tenant, Candidates: req.Candidates, KeySet: ks.ID, Generation: ks.Uint8.KeyGeneration()}
+	tallies, err := ks.Uint8.NewTalliesRaw(r.Context(), len(req.Candidates))
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	e.Tallies = tallies
+	if e, err = h.elections.Create(e); err != nil {
+		writeElectionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newElectionInfo(e))
+}
+
+// election handles GET /elections/{name}, with the encrypted tallies once
+// the election is closed, and DELETE /elections/{name}.
+func (h *Handler) election(w http.ResponseWriter, r *http.Request) {
+	tenant, name := tenantOf(r), r.PathValue("name")
+	switch r.Method {
+	case http.MethodGet:
+		e, err := h.elections.Get(tenant, name)
+		if err != nil {
+			writeElectionError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, closedElectionInfo(e))
+	case http.MethodDelete:
+		if err := h.elections.Delete(tenant, name); err != nil {
+			writeElectionError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// closedElectionInfo is newElectionInfo plus, for a closed election, the
+// encrypted tallies. The tallies of an open election are withheld, so
+// running totals cannot be decrypted mid-vote by anyone holding the keys.
+func closedElectionInfo(e elections.Election) electionInfo {
+	info := newElectionInfo(e)
+	if e.Closed {
+		info.Tallies = make([]string, len(e.Tallies))
+		for i, t := range e.Tallies {
+			info.Tallies[i] = bufpool.EncodeBase64(t)
+		}
+		info.FormatVersion = CiphertextFormatVersion
+	}
+	return info
+}
+
+// electionClose handles POST /elections/{name}/close: stops the election
+// from taking ballots and answers with its encrypted tallies.
+func (h *Handler) electionClose(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	e, err := h.elections.Close(tenantOf(r), r.PathValue("name"))
+	if err != nil {
+		writeElectionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, closedElectionInfo(e))
+}
+
+// electionBallot handles POST /elections/{name}/ballots: adds a ballot, one
+// encrypted boolean per candidate in candidate order, to the tallies. A
+// ballot that does not choose exactly one candidate counts for nobody; the
+// server cannot tell, since validity is checked homomorphically. An
+// authenticated voter may cast one ballot per election.
+func (h *Handler) electionBallot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Choices []string `json:"choices"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	tenant, name := tenantOf(r), r.PathValue("name")
+	e, err := h.elections.Get(tenant, name)
+	if err == nil {
+		err = checkElectionKeys(ks, e)
+	}
+	if err != nil {
+		writeElectionError(w, err)
+		return
+	}
+	if len(req.Choices) != len(e.Candidates) {
+		writeError(w, http.StatusBadRequest, fieldErr("choices", fmt.Errorf("%d choices for %d candidates", len(req.Choices), len(e.Candidates))))
+		return
+	}
+	ballot := make([][]byte, len(req.Choices))
+	for i, c := range req.Choices {
+		if ballot[i], err = decodeCiphertext(c, typeBool); err != nil {
+			writeError(w, statusFor(err), fieldErr(fmt.Sprintf("choices[%d]", i), fmt.Errorf("choice %d: %w", i, err)))
+			return
+		}
+	}
+	id, _ := auth.FromContext(r.Context())
+	e, err = h.elections.Cast(tenant, name, id.ID, func(e elections.Election) ([][]byte, error) {
+		if err := checkElectionKeys(ks, e); err != nil {
+			return nil, err
+		}
+		return ks.Uint8.TallyBallotRaw(r.Context(), e.Tallies, ballot, h.batchConcurrency)
+	})
+	if err != nil {
+		writeElectionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, newElectionInfo(e))
+}
+
+// electionList handles GET /admin/elections: every tenant's elections.
+func (h *AdminHandler) electionList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	list := h.elections.List("", true)
+	out := make([]electionInfo, len(list))
+	for i, e := range list {
+		out[i] = newElectionInfo(e)
+		out[i].Tenant = e.Tenant
+	}
+	writeJSON(w, http.StatusOK, map[string][]electionInfo{"elections": out})
+}
+
+// electionResults handles POST /admin/elections/results: decrypts the
+// totals of a tenant's closed election with its key set's client key. Only
+// the totals are decrypted; ballots are never stored.
+func (h *AdminHandler) electionResults(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Tenant string `json:"tenant"`
+		Name   string `json:"name"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	e, err := h.elections.Get(req.Tenant, req.Name)
+	if err == nil && !e.Closed {
+		err = fmt.Errorf("%w: %s", elections.ErrOpen, e.Name)
+	}
+	if err != nil {
+		writeElectionError(w, err)
+		return
+	}
+	ks, err := h.keys.Get(e.KeySet)
+	if err == nil {
+		err = checkElectionKeys(ks, e)
+	}
+	if err != nil {
+		writeElectionError(w, err)
+		return
+	}
+	totals, err := ks.Uint8.DecryptTalliesRaw(r.Context(), e.Tallies)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	type result struct {
+		Candidate string `json:"candidate"`
+		Votes     uint64 `json:"votes"`
+	}
+	results := make([]result, len(totals))
+	var counted uint64
+	for i, v := range totals {
+		results[i] = result{e.Candidates[i], v}
+		counted += v
+	}
+	info := newElectionInfo(e)
+	info.Tenant = e.Tenant
+	writeJSON(w, http.StatusOK, map[string]any{
+		"election": info,
+		"results":  results,
+		// Ballots minus counted is how many ballots were invalid.
+		"counted": counted,
+	})
+}
@@ -0,0 +1,276 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Ciphertext encodings a client may choose per request with the encoding
+// query parameter or the Ciphertext-Encoding header.
+const (
+	encodingBase64    = "base64"
+	encodingBase64URL = "base64url"
+	encodingHex       = "hex"
+	encodingRaw       = "raw"
+)
+
+// ciphertextFields names the JSON fields holding serialized ciphertexts or
+// keys, at any depth, alone or in arrays.
+var ciphertextFields = map[string]bool{
+	"ciphertext": true, "ciphertexts": true, "left": true, "right": true,
+	"condition": true, "then": true, "else": true, "operands": true,
+	"list": true, "inputs": true, "query": true, "matches": true, "index": true,
+	"initial": true, "increment": true, "increments": true, "choices": true,
+	"tallies": true, "state": true, "output": true, "features": true,
+	"score": true, "probability": true, "public_key": true,
+	"recipient_key": true, "switch_key": true,
+}
+
+// CiphertextEncoding lets clients exchange ciphertexts as base64 (the
+// default), unpadded base64url or hex instead, transcoding the ciphertext
+// fields of JSON requests and responses at the edge so handlers and
+// services only ever see canonical base64 and bytes. With raw, a request may
+// send a single ciphertext as an application/octet-stream body, its other
+// fields in the query string, and responses carrying one ciphertext return
+// its bytes. Admin routes and WebSocket upgrades are passed through.
+func CiphertextEncoding(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := requestEncoding(r)
+		w.Header().Add("Vary", "Ciphertext-Encoding")
+		switch encoding {
+		case "", encodingBase64:
+			next.ServeHTTP(w, r)
+			return
+		case encodingBase64URL, encodingHex, encodingRaw:
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported ciphertext encoding %q (want base64, base64url, hex or raw)", encoding))
+			return
+		}
+		if strings.Contains(r.URL.Path, "/admin/") || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err := decodeCiphertexts(w, r, encoding); err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeError(w, status, err)
+			return
+		}
+		ew := &encodingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(ew, r)
+		ew.finish(encoding)
+	})
+}
+
+// requestEncoding returns the ciphertext encoding r asks for, or "".
+func requestEncoding(r *http.Request) string {
+	encoding := r.URL.Query().Get("encoding")
+	if encoding == "" {
+		encoding = r.Header.Get("Ciphertext-Encoding")
+	}
+	return strings.ToLower(strings.TrimSpace(encoding))
+}
+
+// decodeCiphertexts rewrites the request body with its ciphertexts in
+// base64.
+func decodeCiphertexts(w http.ResponseWriter, r *http.Request, encoding string) error {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if encoding == encodingRaw {
+		if mediaType != "application/octet-stream" {
+			return nil
+		}
+		data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+		if err != nil {
+			return err
+		}
+		body := map[string]string{}
+		for key, values := range r.URL.Query() {
+			if key != "encoding" {
+				body[key] = values[0]
+			}
+		}
+		body["ciphertext"] = base64.StdEncoding.EncodeToString(data)
+		return replaceBody(r, body)
+	}
+	// Handlers read JSON whatever the declared type, so only binary and
+	// multipart uploads are left alone; a body that is not JSON is passed
+	// on for the handler to reject.
+	if r.Body == nil || r.Body == http.NoBody || mediaType == "application/octet-stream" || strings.HasPrefix(mediaType, "multipart/") {
+		return nil
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+	if err != nil {
+		return err
+	}
+	var body any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		return nil
+	}
+	err = transcode(body, "", func(field, s string) (string, error) {
+		data, err := decodeWire(encoding, s)
+		if err != nil {
+			return "", fieldErr(field, fmt.Errorf("field %s: invalid %s: %w", field, encoding, err))
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	})
+	if err != nil {
+		return err
+	}
+	return replaceBody(r, body)
+}
+
+func replaceBody(r *http.Request, body any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	r.Body = io.NopCloser(bytes.NewReader(data))
+	r.ContentLength = int64(len(data))
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Content-Length", strconv.Itoa(len(data)))
+	return nil
+}
+
+// transcode applies conv to every string under a ciphertext field of v, in
+// place.
+func transcode(v any, field string, conv func(field, s string) (string, error)) error {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if s, ok := child.(string); ok {
+				if !ciphertextFields[key] {
+					continue
+				}
+				out, err := conv(key, s)
+				if err != nil {
+					return err
+				}
+				v[key] = out
+				continue
+			}
+			if err := transcode(child, key, conv); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for i, child := range v {
+			s, ok := child.(string)
+			if !ok {
+				if err := transcode(child, field, conv); err != nil {
+					return err
+				}
+				continue
+			}
+			if !ciphertextFields[field] {
+				continue
+			}
+			out, err := conv(field, s)
+			if err != nil {
+				return err
+			}
+			v[i] = out
+		}
+	}
+	return nil
+}
+
+func decodeWire(encoding, s string) ([]byte, error) {
+	switch encoding {
+	case encodingBase64URL:
+		return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	case encodingHex:
+		return hex.DecodeString(s)
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
+
+func encodeWire(encoding string, data []byte) string {
+	switch encoding {
+	case encodingBase64URL:
+		return base64.RawURLEncoding.EncodeToString(data)
+	case encodingHex:
+		return hex.EncodeToString(data)
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+// encodingWriter buffers a response so its ciphertexts can be re-encoded.
+type encodingWriter struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (ew *encodingWriter) WriteHeader(status int) { ew.status = status }
+
+func (ew *encodingWriter) Write(p []byte) (int, error) { return ew.buf.Write(p) }
+
+// finish writes the buffered response, re-encoded when it is a successful
+// JSON response. Strings that are not base64 are left alone.
+func (ew *encodingWriter) finish(encoding string) {
+	w, data := ew.ResponseWriter, ew.buf.Bytes()
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	var body any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if ew.status >= 300 || mediaType != "application/json" || dec.Decode(&body) != nil {
+		w.WriteHeader(ew.status)
+		_, _ = w.Write(data)
+		return
+	}
+	if encoding == encodingRaw {
+		obj, _ := body.(map[string]any)
+		if ct, ok := obj["ciphertext"].(string); ok {
+			if raw, err := base64.StdEncoding.DecodeString(ct); err == nil {
+				h := w.Header()
+				h.Set("Content-Type", "application/octet-stream")
+				h.Set("Content-Length", strconv.Itoa(len(raw)))
+				h.Set("Ciphertext-Encoding", encodingRaw)
+				if v, ok := obj["format_version"]; ok {
+					h.Set("Ciphertext-Format-Version", fmt.Sprint(v))
+				}
+				if v, ok := obj["type"].(string); ok {
+					h.Set("Ciphertext-Type", v)
+				}
+				w.WriteHeader(ew.status)
+				_, _ = w.Write(raw)
+				return
+			}
+		}
+		w.WriteHeader(ew.status)
+		_, _ = w.Write(data)
+		return
+	}
+	_ = transcode(body, "", func(_, s string) (string, error) {
+		raw, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return s, nil
+		}
+		return encodeWire(encoding, raw), nil
+	})
+	out, err := json.Marshal(body)
+	if err != nil {
+		w.WriteHeader(ew.status)
+		_, _ = w.Write(data)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(out)+1))
+	w.Header().Set("Ciphertext-Encoding", encoding)
+	w.WriteHeader(ew.status)
+	_, _ = w.Write(append(out, '\n'))
+}
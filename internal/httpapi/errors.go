@@ -0,0 +1,116 @@
+package httpapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"tfhe-go/internal/apierror"
+	"tfhe-go/internal/cbor"
+	"tfhe-go/internal/circuit"
+	"tfhe-go/internal/tfhe"
+)
+
+// fieldError ties err to the JSON field of the request it concerns, which
+// error responses report in their details. Its message is that of err.
+type fieldError struct {
+	field string
+	err   error
+}
+
+func (e *fieldError) Error() string { return e.err.Error() }
+
+func (e *fieldError) Unwrap() error { return e.err }
+
+// fieldErr wraps err, if any, as concerning field, e.g. "left" or
+// "inputs.a".
+func fieldErr(field string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &fieldError{field: field, err: err}
+}
+
+// operandFields names the request field of the operand a *tfhe.OperandError
+// in err points at, fields being in the operation's operand order.
+func operandFields(err error, fields ...string) error {
+	var opErr *tfhe.OperandError
+	if errors.As(err, &opErr) && opErr.Index < len(fields) {
+		return fieldErr(fields[opErr.Index], err)
+	}
+	return err
+}
+
+// inputField ties err to field when it is about bad input rather than a
+// fault of the server, for requests whose only ciphertext or key is field.
+func inputField(err error, field string) error {
+	if errors.Is(err, tfhe.ErrInvalidCiphertext) || errors.Is(err, tfhe.ErrCiphertextTooLarge) || errors.Is(err, tfhe.ErrInvalidKey) || errors.Is(err, tfhe.ErrValueOutOfRange) {
+		return fieldErr(field, err)
+	}
+	return err
+}
+
+// codeFor returns the machine-readable code of err, which statusFor or the
+// handler mapped to status.
+func codeFor(err error, status int) string {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		return "body_too_large"
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return "invalid_json"
+	case errors.Is(err, cbor.ErrSyntax):
+		return "invalid_cbor"
+	case errors.Is(err, tfhe.ErrCiphertextTooLarge):
+		return "ciphertext_too_large"
+	case errors.Is(err, tfhe.ErrInvalidCiphertext):
+		return "invalid_ciphertext"
+	case errors.Is(err, tfhe.ErrInvalidKey):
+		return "invalid_key"
+	case errors.Is(err, tfhe.ErrValueOutOfRange):
+		return "value_out_of_range"
+	case errors.Is(err, tfhe.ErrTrivialCiphertext):
+		return "trivial_ciphertext"
+	case errors.Is(err, tfhe.ErrNilKey), errors.Is(err, tfhe.ErrServerKeyNotSet):
+		return "keys_unavailable"
+	case errors.Is(err, tfhe.ErrMemoryLimit):
+		return "memory_limit"
+	case errors.Is(err, tfhe.ErrOpTimeout):
+		return "op_timeout"
+	case errors.Is(err, tfhe.ErrOverloaded):
+		return "overloaded"
+	case errors.Is(err, tfhe.ErrUnsupported):
+		return "unsupported"
+	case errors.Is(err, tfhe.ErrSwitchKeyNotSet):
+		return "switch_key_not_set"
+	case errors.Is(err, tfhe.ErrClientKeyWithheld):
+		return "client_key_withheld"
+	case errors.Is(err, tfhe.ErrTrustedReencryptDisabled):
+		return "trusted_reencrypt_disabled"
+	case errors.Is(err, tfhe.ErrNativePanic):
+		return "native_panic"
+	case errors.Is(err, circuit.ErrInvalid):
+		return "invalid_circuit"
+	}
+	return apierror.CodeFor(status)
+}
+
+// detailsFor points at the request field err concerns, when it is known.
+func detailsFor(err error) []apierror.Detail {
+	var fe *fieldError
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	switch {
+	case errors.As(err, &fe):
+		return []apierror.Detail{{Field: fe.field, Reason: fe.err.Error()}}
+	case errors.As(err, &typeErr):
+		return []apierror.Detail{{Field: typeErr.Field, Reason: fmt.Sprintf("cannot use JSON %s as %s", typeErr.Value, typeErr.Type)}}
+	case errors.As(err, &syntaxErr):
+		return []apierror.Detail{{Reason: fmt.Sprintf("malformed JSON at byte %d", syntaxErr.Offset)}}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package httpapi
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"tfhe-go/internal/circuit"
+	"tfhe-go/internal/estimate"
+)
+
+// SetEstimator serves /estimate from e; call it before Register.
+func (h *Handler) SetEstimator(e *estimate.Estimator) {
+	h.estimator = e
+}
+
+// estimate handles /estimate. GET lists the calibrated cost of every
+// operation and the size of every ciphertext type; POST predicts the cost of
+// a pipeline of steps, or of a circuit given as in /evaluate with the type of
+// each input instead of its ciphertext.
+func (h *Handler) estimate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	m, err := h.estimator.Model()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	if r.Method == http.MethodGet {
+		ops, sizes := m.Costs()
+		writeJSON(w, http.StatusOK, map[string]any{
+			"parameter_set":    m.ParameterSet,
+			"calibrated_at":    m.Calibrated.UTC().Format(time.RFC3339),
+			"ops":              ops,
+			"ciphertext_bytes": sizes,
+		})
+		return
+	}
+
+	var req struct {
+		Steps      []estimate.Step   `json:"steps"`
+		Expression string            `json:"expression"`
+		Graph      *circuit.Graph    `json:"graph"`
+		Inputs     map[string]string `json:"inputs"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	var est estimate.Estimate
+	switch g := req.Graph; {
+	case req.Steps != nil && (req.Expression != "" || g != nil):
+		writeError(w, http.StatusBadRequest, errors.New("give either steps or a circuit, not both"))
+		return
+	case req.Steps != nil:
+		est, err = m.Steps(req.Steps)
+	case req.Expression != "" && g != nil:
+		writeError(w, http.StatusBadRequest, errors.New("give either expression or graph, not both"))
+		return
+	case req.Expression != "":
+		if g, err = circuit.Parse(req.Expression); err == nil {
+			est, err = m.Circuit(g, req.Inputs, h.batchConcurrency)
+		}
+	case g != nil:
+		est, err = m.Circuit(g, req.Inputs, h.batchConcurrency)
+	default:
+		writeError(w, http.StatusBadRequest, errors.New("steps, expression or graph is required"))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, est)
+}
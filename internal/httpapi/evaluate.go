@@ -0,0 +1,199 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/circuit"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// typeBool names FheBool ciphertexts: comparison results under the integer keys.
+const typeBool = "bool"
+
+type evalValue struct {
+	Type          string `json:"type"`
+	Ciphertext    string `json:"ciphertext"`
+	FormatVersion int    `json:"format_version,omitempty"`
+}
+
+// evaluate handles POST /evaluate: runs a circuit, given as an expression or
+// an op graph, over named input ciphertexts and returns the named outputs.
+func (h *Handler) evaluate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Expression string               `json:"expression"`
+		Graph      *circuit.Graph       `json:"graph"`
+		Inputs     map[string]evalValue `json:"inputs"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+
+	g := req.Graph
+	switch {
+	case req.Expression != "" && g != nil:
+		writeError(w, http.StatusBadRequest, errors.New("give either expression or graph, not both"))
+		return
+	case req.Expression != "":
+		var err error
+		if g, err = circuit.Parse(req.Expression); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	case g == nil:
+		writeError(w, http.StatusBadRequest, errors.New("expression or graph is required"))
+		return
+	}
+
+	inputs := make(map[string]circuit.Value, len(req.Inputs))
+	for name, in := range req.Inputs {
+		if !slices.Contains(evalTypes(), in.Type) {
+			writeError(w, http.StatusBadRequest, fieldErr("inputs."+name+".type", fmt.Errorf("input %q: unsupported type %q", name, in.Type)))
+			return
+		}
+		raw, err := bufpool.DecodeBase64(in.Ciphertext)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fieldErr("inputs."+name+".ciphertext", fmt.Errorf("input %q: %v", name, err)))
+			return
+		}
+		defer bufpool.Put(raw)
+		inputs[name] = circuit.Value{Type: in.Type, Data: *raw}
+	}
+
+	outputs, err := circuit.EvaluateParallel(r.Context(), g, inputs, evalOps{h: h, ks: ks}, h.batchConcurrency)
+	if err != nil {
+		status := statusFor(err)
+		if errors.Is(err, circuit.ErrInvalid) {
+			status = http.StatusBadRequest
+		}
+		writeError(w, status, err)
+		return
+	}
+	resp := make(map[string]evalValue, len(outputs))
+	for name, v := range outputs {
+		resp[name] = evalValue{Type: v.Type, Ciphertext: bufpool.EncodeBase64(v.Data), FormatVersion: CiphertextFormatVersion}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"outputs": resp})
+}
+
+// Evaluate runs g over inputs under the key set keyID selects, for callers
+// that do not come through HTTP, such as the queue worker.
+func (h *Handler) Evaluate(ctx context.Context, keyID string, g *circuit.Graph, inputs map[string]circuit.Value) (map[string]circuit.Value, error) {
+	ks, err := h.keys.Select(keyID)
+	if err != nil {
+		return nil, err
+	}
+	for name, in := range inputs {
+		if !slices.Contains(evalTypes(), in.Type) {
+			return nil, circuit.Invalid(fmt.Errorf("input %q: unsupported type %q", name, in.Type))
+		}
+	}
+	return circuit.EvaluateParallel(ctx, g, inputs, evalOps{h: h, ks: ks}, h.batchConcurrency)
+}
+
+// evalTypes lists the ciphertext types a circuit can take as input.
+func evalTypes() []string {
+	types := []string{typeBoolean, typeBool, typeUint8}
+	for _, bits := range tfhe.IntWidths {
+		types = append(types, intTypeName(bits))
+	}
+	return types
+}
+
+// evalOps runs circuit operations against a key set. Operands of one
+// operation must share a type; comparisons yield bool, which if_then_else
+// takes as its condition.
+type evalOps struct {
+	h  *Handler
+	ks *keys.KeySet
+}
+
+func (o evalOps) Apply(ctx context.Context, op string, args []circuit.Value) (circuit.Value, error) {
+	if len(args) == 0 {
+		return circuit.Value{}, circuit.Invalid(fmt.Errorf("op %q has no operands", op))
+	}
+	if op == "if_then_else" {
+		if len(args) != 3 {
+			return circuit.Value{}, circuit.Invalid(fmt.Errorf("op %q expects 3 operands, got %d", op, len(args)))
+		}
+		if args[0].Type != typeBool {
+			return circuit.Value{}, circuit.Invalid(fmt.Errorf("if_then_else condition must be %s, got %s", typeBool, args[0].Type))
+		}
+		svc, err := o.selector(args[1:])
+		if err != nil {
+			return circuit.Value{}, err
+		}
+		out, err := svc.IfThenElseRaw(ctx, args[0].Data, args[1].Data, args[2].Data)
+		return circuit.Value{Type: args[1].Type, Data: out}, err
+	}
+	if slices.Contains(tfhe.Comparisons, tfhe.Comparison(op)) {
+		if len(args) != 2 {
+			return circuit.Value{}, circuit.Invalid(fmt.Errorf("op %q expects 2 operands, got %d", op, len(args)))
+		}
+		svc, err := o.selector(args)
+		if err != nil {
+			return circuit.Value{}, err
+		}
+		out, err := svc.CompareRaw(ctx, tfhe.Comparison(op), args[0].Data, args[1].Data)
+		return circuit.Value{Type: typeBool, Data: out}, err
+	}
+
+	typ, err := sameType(args)
+	if err != nil {
+		return circuit.Value{}, err
+	}
+	if typ != typeBoolean {
+		// The expression operators & and ^ mean the bitwise ops on integers.
+		switch op {
+		case "and":
+			op = "bitand"
+		case "xor":
+			op = "bitxor"
+		}
+	}
+	fn, err := o.h.resolveOp(o.ks, typ, op, len(args))
+	if err != nil {
+		return circuit.Value{}, circuit.Invalid(err)
+	}
+	operands := make([][]byte, len(args))
+	for i, arg := range args {
+		operands[i] = arg.Data
+	}
+	out, err := fn(ctx, operands)
+	return circuit.Value{Type: typ, Data: out}, err
+}
+
+// selector returns the integer service shared by args.
+func (o evalOps) selector(args []circuit.Value) (selector, error) {
+	typ, err := sameType(args)
+	if err != nil {
+		return nil, err
+	}
+	svc := selectorFor(o.ks, typ)
+	if svc == nil {
+		return nil, circuit.Invalid(fmt.Errorf("type %s is not an integer type", typ))
+	}
+	return svc, nil
+}
+
+func sameType(args []circuit.Value) (string, error) {
+	for _, arg := range args[1:] {
+		if arg.Type != args[0].Type {
+			return "", circuit.Invalid(fmt.Errorf("operand types differ: %s and %s", args[0].Type, arg.Type))
+		}
+	}
+	return args[0].Type, nil
+}
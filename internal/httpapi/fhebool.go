@@ -0,0 +1,130 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// The /bool routes serve FheBool ciphertexts: encrypted booleans under the
+// integer keys, as returned by the /uintN comparison routes. They are distinct
+// from the /boolean routes, which use the boolean API's own keys.
+func (h *Handler) registerBoolRoutes(mux routes) {
+	h.route(mux, features.Encrypt, "/bool/encrypt", h.boolEncrypt)
+	h.route(mux, features.Decrypt, "/bool/decrypt", h.boolDecrypt)
+	handle(mux, "/bool/if_then_else", h.boolIfThenElse)
+}
+
+func (h *Handler) boolEncrypt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Value bool `json:"value"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	ct, err := ks.Uint8.EncryptBool(r.Context(), req.Value)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeCiphertext(w, ct)
+}
+
+func (h *Handler) boolDecrypt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	value, err := ks.Uint8.DecryptBool(r.Context(), req.Ciphertext)
+	if err != nil {
+		err = inputField(err, "ciphertext")
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]bool{"value": value})
+}
+
+// selector is the encrypted decision logic implemented by the integer services.
+type selector interface {
+	IfThenElse(ctx context.Context, cond, then, els string) (string, error)
+	IfThenElseRaw(ctx context.Context, cond, then, els []byte) ([]byte, error)
+	CompareRaw(ctx context.Context, cmp tfhe.Comparison, lhs, rhs []byte) ([]byte, error)
+	ReencryptFor(ctx context.Context, ctBase64, recipient string) (string, error)
+}
+
+// boolIfThenElse selects between two integer ciphertexts of the given type
+// (uint8 by default) under an FheBool condition, re-encrypting the result
+// under recipient_key when one is given.
+func (h *Handler) boolIfThenElse(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Type         string `json:"type"`
+		Condition    string `json:"condition"`
+		Then         string `json:"then"`
+		Else         string `json:"else"`
+		RecipientKey string `json:"recipient_key"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.RecipientKey != "" && !h.allow(w, r, features.Decrypt) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	svc := selectorFor(ks, req.Type)
+	if svc == nil {
+		writeError(w, http.StatusBadRequest, fieldErr("type", fmt.Errorf("unsupported type %q", req.Type)))
+		return
+	}
+	ct, err := svc.IfThenElse(r.Context(), req.Condition, req.Then, req.Else)
+	err = operandFields(err, "condition", "then", "else")
+	if err == nil && req.RecipientKey != "" {
+		ct, err = svc.ReencryptFor(r.Context(), ct, req.RecipientKey)
+		err = inputField(err, "recipient_key")
+	}
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeCiphertext(w, ct)
+}
+
+// selectorFor returns the integer service for typ, or nil if typ is not an
+// integer type.
+func selectorFor(ks *keys.KeySet, typ string) selector {
+	if typ == "" || typ == typeUint8 {
+		return ks.Uint8
+	}
+	if svc := intService(ks, typ); svc != nil {
+		return svc
+	}
+	return nil
+}
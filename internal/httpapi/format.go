@@ -0,0 +1,217 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// CiphertextFormatSafe is the format version of tfhe-rs's versioned safe
+// serialization, which tfhe-rs and its JS bindings read and write with
+// safe_serialize and safe_deserialize. Only ciphertexts of the integer API
+// have it; boolean gate ciphertexts and keys are exchanged as in format 1.
+const CiphertextFormatSafe = 2
+
+// ciphertextFormatVersions lists the format versions a client may ask for.
+var ciphertextFormatVersions = []int{CiphertextFormatVersion, CiphertextFormatSafe}
+
+// CiphertextFormat lets a client exchange integer ciphertexts in the safe
+// serialization by asking for format version 2 with the format_version
+// query parameter or the Ciphertext-Format-Version header. Ciphertext fields
+// of JSON requests are converted to tfhe-c's serialization for the handlers,
+// after a check that they conform to the parameters of the caller's keys,
+// and those of responses back. It runs inside CiphertextEncoding, which sees
+// the converted bytes. Admin routes and WebSocket upgrades are passed
+// through.
+func (h *Handler) CiphertextFormat(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		v := r.URL.Query().Get("format_version")
+		if v == "" {
+			v = r.Header.Get("Ciphertext-Format-Version")
+		}
+		w.Header().Add("Vary", "Ciphertext-Format-Version")
+		if v = strings.TrimSpace(v); v == "" || v == strconv.Itoa(CiphertextFormatVersion) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if v != strconv.Itoa(CiphertextFormatSafe) {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported ciphertext format version %q (want 1 or 2)", v))
+			return
+		}
+		if strings.Contains(r.URL.Path, "/admin/") || r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		types, ok := h.fromSafe(w, r)
+		if !ok {
+			return
+		}
+		// The type in the path, as in /v1/uint16/add, and those of the
+		// request's ciphertexts tell apart response ciphertexts whose plain
+		// serialization fits several widths.
+		for _, seg := range strings.Split(r.URL.Path, "/") {
+			if slices.Contains(tfhe.SafeTypes, seg) {
+				types = append([]string{seg}, types...)
+			}
+		}
+		ew := &encodingWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(ew, r)
+		toSafe(ew, types)
+	})
+}
+
+// fromSafe rewrites the ciphertext fields of a JSON request body from the
+// safe serialization to tfhe-c's and returns the types it met. Fields that
+// are not safe-serialized ciphertexts, such as keys, are left alone. On
+// failure it writes the error response and reports false.
+func (h *Handler) fromSafe(w http.ResponseWriter, r *http.Request) ([]string, bool) {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if r.Body == nil || r.Body == http.NoBody || mediaType == "application/octet-stream" || strings.HasPrefix(mediaType, "multipart/") {
+		return nil, true
+	}
+	raw, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, err)
+		return nil, false
+	}
+	var body any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&body); err != nil {
+		r.Body = io.NopCloser(bytes.NewReader(raw))
+		return nil, true
+	}
+	var ks *keys.KeySet
+	var types []string
+	err = transcode(body, "", func(field, s string) (string, error) {
+		data, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return s, nil
+		}
+		if ks == nil {
+			var ok bool
+			if ks, ok = h.keySet(w, r); !ok {
+				return "", errKeySetWritten
+			}
+		}
+		typ, plain, err := ks.Uint8.SafeDeserializeRaw(r.Context(), data)
+		if errors.Is(err, tfhe.ErrInvalidCiphertext) || errors.Is(err, tfhe.ErrCiphertextTooLarge) {
+			return s, nil
+		}
+		if err != nil {
+			return "", fieldErr(field, fmt.Errorf("field %s: %w", field, err))
+		}
+		if !slices.Contains(types, typ) {
+			types = append(types, typ)
+		}
+		return base64.StdEncoding.EncodeToString(plain), nil
+	})
+	if err == nil {
+		err = replaceBody(r, body)
+	}
+	switch {
+	case errors.Is(err, errKeySetWritten):
+		return nil, false
+	case err != nil:
+		writeError(w, statusFor(err), err)
+		return nil, false
+	}
+	return types, true
+}
+
+// errKeySetWritten stops a conversion whose key set lookup already answered
+// the request.
+var errKeySetWritten = errors.New("key set lookup failed")
+
+// toSafe writes the buffered response with the integer ciphertexts of a
+// successful JSON response in the safe serialization, taking the first of
+// types a ciphertext fits when its plain serialization is ambiguous.
+func toSafe(ew *encodingWriter, types []string) {
+	w, data := ew.ResponseWriter, ew.buf.Bytes()
+	mediaType, _, _ := mime.ParseMediaType(w.Header().Get("Content-Type"))
+	var body any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if ew.status >= 300 || mediaType != "application/json" || dec.Decode(&body) != nil {
+		w.WriteHeader(ew.status)
+		_, _ = w.Write(data)
+		return
+	}
+	err := transcode(body, "", func(field, s string) (string, error) {
+		raw, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return s, nil
+		}
+		var fits []string
+		for _, typ := range tfhe.Inspect(raw).Candidates {
+			if slices.Contains(tfhe.SafeTypes, typ) {
+				fits = append(fits, typ)
+			}
+		}
+		typ := ""
+		switch {
+		case len(fits) == 0:
+			return s, nil
+		case len(fits) == 1:
+			typ = fits[0]
+		default:
+			for _, t := range types {
+				if slices.Contains(fits, t) {
+					typ = t
+					break
+				}
+			}
+		}
+		if typ == "" {
+			return "", fmt.Errorf("field %s: cannot tell the type of a %s ciphertext", field, strings.Join(fits, " or "))
+		}
+		safe, err := tfhe.SafeSerialize(typ, raw)
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", field, err)
+		}
+		return base64.StdEncoding.EncodeToString(safe), nil
+	})
+	var out []byte
+	if err == nil {
+		setFormatVersion(body)
+		out, err = json.Marshal(body)
+	}
+	if err != nil {
+		w.Header().Del("Content-Length")
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Length", strconv.Itoa(len(out)+1))
+	w.Header().Set("Ciphertext-Format-Version", strconv.Itoa(CiphertextFormatSafe))
+	w.WriteHeader(ew.status)
+	_, _ = w.Write(append(out, '\n'))
+}
+
+// setFormatVersion marks every format_version of v as the safe format.
+func setFormatVersion(v any) {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, child := range v {
+			if key == "format_version" {
+				v[key] = CiphertextFormatSafe
+				continue
+			}
+			setFormatVersion(child)
+		}
+	case []any:
+		for _, child := range v {
+			setFormatVersion(child)
+		}
+	}
+}
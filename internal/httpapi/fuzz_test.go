@@ -0,0 +1,97 @@
+package httpapi
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// FuzzJSONRoutes posts arbitrary bodies to the routes decoding JSON and
+// ciphertexts from clients, e.g.
+//
+//	go test ./internal/httpapi -run '^$' -fuzz '^FuzzJSONRoutes$' -fuzztime 5m
+//
+// No input may panic a handler or be answered with 500, which would mean
+// malformed input reached native code or was mistaken for a server fault.
+// Failing inputs are kept under testdata/fuzz/FuzzJSONRoutes/ and replayed
+// by later go test runs.
+func FuzzJSONRoutes(f *testing.F) {
+	mux := fuzzMux(f)
+	for _, seed := range []struct {
+		route uint8
+		body  string
+	}{
+		{0, `{"value":true}`},
+		{1, `{"ciphertext":"AAAAAAAAAAA="}`},
+		{2, `{"left":"AAAAAAAAAAA=","right":"AAAAAAAAAAA="}`},
+		{3, `{"ciphertext":"!!"}`},
+		{4, `{"op":"xor","pairs":[{"left":"AAAA","right":"AAAA"}]}`},
+		{5, `{"ops":[{"type":"boolean","op":"and","operands":["AAAA","AAAA"]}]}`},
+		{6, `{"expression":"a & (b | !c)","inputs":{"a":{"type":"boolean","ciphertext":"AAAA"}}}`},
+		{6, `{"graph":{"nodes":[{"id":"x","op":"and","args":["x","x"]}],"outputs":{"out":"x"}}}`},
+		{7, `{"left":"AAAA","right":""}`},
+		{8, `{"type":"uint8","condition":"AAAA","then":"AAAA","else":"AAAA"}`},
+		{9, `{"value":256}`},
+		{10, `{"ciphertext":"AAAA","value":300}`},
+		{0, `{`},
+	} {
+		f.Add(seed.route, []byte(seed.body))
+	}
+	f.Fuzz(func(t *testing.T, route uint8, body []byte) {
+		path := fuzzRoutes[int(route)%len(fuzzRoutes)]
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code == http.StatusInternalServerError {
+			t.Fatalf("POST %s %q: 500 %s", path, body, rec.Body)
+		}
+	})
+}
+
+var fuzzRoutes = []string{
+	"/v1/boolean/encrypt",
+	"/v1/boolean/decrypt",
+	"/v1/boolean/and",
+	"/v1/boolean/not",
+	"/v1/boolean/batch",
+	"/v1/batch",
+	"/v1/evaluate",
+	"/v1/uint8/add",
+	"/v1/bool/if_then_else",
+	"/v1/uint16/encrypt",
+	"/v1/uint8/scalar_lt",
+}
+
+var fuzzHandler struct {
+	once sync.Once
+	mux  *http.ServeMux
+	err  error
+}
+
+// fuzzMux returns routes backed by one key set, generated on first use.
+func fuzzMux(tb testing.TB) *http.ServeMux {
+	fuzzHandler.once.Do(func() {
+		boolean, err := tfhe.NewBooleanService()
+		if err != nil {
+			fuzzHandler.err = err
+			return
+		}
+		uint8Service, err := tfhe.NewUint8Service()
+		if err != nil {
+			fuzzHandler.err = err
+			return
+		}
+		registry := keys.NewRegistry(&keys.KeySet{Boolean: boolean, Uint8: uint8Service})
+		fuzzHandler.mux = http.NewServeMux()
+		NewHandler(registry, nil).Register(fuzzHandler.mux)
+	})
+	if fuzzHandler.err != nil {
+		tb.Fatal(fuzzHandler.err)
+	}
+	return fuzzHandler.mux
+}
@@ -1,45 +1,176 @@
 package httpapi
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"net/http"
+	"runtime"
 
+	"tfhe-go/internal/acl"
+	"tfhe-go/internal/apierror"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/counters"
+	"tfhe-go/internal/elections"
+	"tfhe-go/internal/estimate"
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/machines"
+	"tfhe-go/internal/models"
+	"tfhe-go/internal/quota"
+	"tfhe-go/internal/rotation"
+	"tfhe-go/internal/sessions"
+	"tfhe-go/internal/store"
 	"tfhe-go/internal/tfhe"
 )
 
-// Handler wires HTTP endpoints to the BooleanService.
+// Handler wires HTTP endpoints to the services of the caller's key set.
 type Handler struct {
-	boolean *tfhe.BooleanService
-	uint8   *tfhe.Uint8Service
+	keys  *keys.Registry
+	store store.Store
+	usage *quota.Tracker
+	// acl holds the grants over stored handles; nil lets only the owning
+	// tenant use a handle.
+	acl *acl.Registry
+	// counters holds the encrypted accumulators; nil disables their routes.
+	counters *counters.Registry
+	// elections holds the encrypted ballot boxes; nil disables their routes.
+	elections *elections.Registry
+	// machines holds the state machine definitions; nil disables their
+	// routes.
+	machines *machines.Registry
+	// models holds the scoring models; nil disables their routes.
+	models *models.Registry
+	// estimator predicts operation costs; nil disables /estimate.
+	estimator *estimate.Estimator
+	// rotation re-encrypts the store on key rotation; writes to the store
+	// hold it off. Nil stores without waiting.
+	rotation *rotation.Manager
+	// sessions issues short-lived key sets; nil disables their routes.
+	sessions *sessions.Manager
+	// features switches route families off or restricts them to admins.
+	features features.Policy
+	admins   map[string]bool
+
+	batchConcurrency int
+
+	// prefix, middleware and wrappers are set by the Options of NewHandler.
+	prefix     string
+	middleware []Middleware
+	wrappers   []RouteWrapper
+}
+
+// NewHandler builds a handler with dependencies injected, customized by
+// opts.
+func NewHandler(registry *keys.Registry, ciphertextStore store.Store, opts ...Option) *Handler {
+	h := &Handler{
+		keys:  registry,
+		store: ciphertextStore,
+
+		batchConcurrency: runtime.GOMAXPROCS(0),
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-// NewHandler builds a handler with dependencies injected.
-func NewHandler(booleanService *tfhe.BooleanService, uint8Service *tfhe.Uint8Service) *Handler {
-	return &Handler{
-		boolean: booleanService,
-		uint8:   uint8Service,
+// SetBatchConcurrency sets how many operations of one batch request, or
+// independent nodes of one circuit, run in parallel; n < 1 keeps the default of GOMAXPROCS.
+func (h *Handler) SetBatchConcurrency(n int) {
+	if n >= 1 {
+		h.batchConcurrency = n
 	}
 }
 
-// Register attaches routes to the provided mux.
-func (h *Handler) Register(mux *http.ServeMux) {
-	mux.HandleFunc("/health", h.health)
-	mux.HandleFunc("/boolean/encrypt", h.encrypt)
-	mux.HandleFunc("/boolean/decrypt", h.decrypt)
-	mux.HandleFunc("/boolean/and", h.and)
-	mux.HandleFunc("/boolean/or", h.or)
-	mux.HandleFunc("/boolean/xor", h.xor)
-	mux.HandleFunc("/boolean/not", h.not)
-	mux.HandleFunc("/uint8/encrypt", h.encryptUint8)
-	mux.HandleFunc("/uint8/encrypt/public", h.encryptUint8Public)
-	mux.HandleFunc("/uint8/decrypt", h.decryptUint8)
-	mux.HandleFunc("/uint8/add", h.addUint8)
-	mux.HandleFunc("/uint8/bitand", h.bitAndUint8)
-	mux.HandleFunc("/uint8/bitxor", h.bitXorUint8)
+// Register attaches routes to the provided mux, under the handler's prefix
+// and wrapped as its options ask.
+func (h *Handler) Register(serveMux *http.ServeMux) {
+	mux := h.router(serveMux)
+	h.route(mux, features.Encrypt, "/boolean/encrypt", h.encrypt)
+	h.route(mux, features.Decrypt, "/boolean/decrypt", h.decrypt)
+	handle(mux, "/boolean/and", h.and)
+	handle(mux, "/boolean/or", h.or)
+	handle(mux, "/boolean/xor", h.xor)
+	handle(mux, "/boolean/not", h.not)
+	h.route(mux, features.Batch, "/boolean/batch", h.gateBatch)
+	h.registerIntegerRoutes(mux)
+	handle(mux, "/uint8/sort", h.sort)
+	h.registerBitCountRoutes(mux)
+	h.registerShiftRoutes(mux)
+	h.registerConvertRoutes(mux)
+	h.registerBytesRoutes(mux)
+	handle(mux, "/compact/expand", h.expandCompact)
+	h.registerBoolRoutes(mux)
+	h.registerKeyRoutes(mux)
+	h.route(mux, features.KeySwitching, "/keys/switch", keySwitching(h.switchKey))
+	h.route(mux, features.Decrypt, "/reencrypt/public", h.reencryptPublic)
+	h.route(mux, features.Batch, "/batch", h.batch)
+	h.route(mux, features.Evaluate, "/evaluate", h.evaluate)
+	if h.estimator != nil {
+		handle(mux, "/estimate", h.estimate)
+	}
+	h.route(mux, features.Batch, "/ws", h.ws)
+	handle(mux, "/version", h.version)
+	handle(mux, "/inspect", h.inspect)
+	if h.usage != nil {
+		handle(mux, "/usage", h.usageReport)
+	}
+	if h.store != nil {
+		h.route(mux, features.Ciphertexts, "/ciphertexts", h.ciphertexts)
+		h.route(mux, features.Ciphertexts, "/ciphertexts/ops", h.ciphertextOp)
+		h.route(mux, features.Ciphertexts, "/ciphertexts/search", h.search)
+		h.route(mux, features.Ciphertexts, "/ciphertexts/transactions", h.transaction)
+		h.route(mux, features.Ciphertexts, "/ciphertexts/{id}", h.ciphertext)
+		if h.features.Access(features.Ciphertexts) != features.Off {
+			h.route(mux, features.Decrypt, "/ciphertexts/{id}/decrypt", h.ciphertextDecrypt)
+		}
+		if h.acl != nil {
+			h.route(mux, features.Ciphertexts, "/ciphertexts/{id}/acl", h.handleACL)
+		}
+		if h.features.Access(features.Ciphertexts) != features.Off {
+			h.route(mux, features.KeySwitching, "/reencrypt", keySwitching(h.reencrypt))
+		}
+	}
+	if h.counters != nil {
+		h.route(mux, features.Counters, "/counters", h.counterList)
+		h.route(mux, features.Counters, "/counters/{name}", h.counter)
+		h.route(mux, features.Counters, "/counters/{name}/increment", h.counterIncrement)
+	}
+	if h.elections != nil {
+		h.route(mux, features.Elections, "/elections", h.electionList)
+		h.route(mux, features.Elections, "/elections/{name}", h.election)
+		h.route(mux, features.Elections, "/elections/{name}/ballots", h.electionBallot)
+		h.route(mux, features.Elections, "/elections/{name}/close", h.electionClose)
+	}
+	if h.machines != nil {
+		h.route(mux, features.Machines, "/machines", h.machineList)
+		h.route(mux, features.Machines, "/machines/{name}", h.machine)
+		h.route(mux, features.Machines, "/machines/{name}/advance", h.machineAdvance)
+	}
+	if h.models != nil {
+		h.route(mux, features.Models, "/models", h.modelList)
+		h.route(mux, features.Models, "/models/{name}", h.model)
+		h.route(mux, features.Models, "/models/{name}/score", h.modelScore)
+	}
+	if h.sessions != nil {
+		h.route(mux, features.Sessions, "/sessions", h.sessionList)
+		h.route(mux, features.Sessions, "/sessions/{id}", h.session)
+	}
 }
 
-func (h *Handler) health(w http.ResponseWriter, r *http.Request) {
-	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+// keySet returns the key set selected by the caller's identity, writing a
+// 403 when it names an unregistered set.
+func (h *Handler) keySet(w http.ResponseWriter, r *http.Request) (*keys.KeySet, bool) {
+	id, _ := auth.FromContext(r.Context())
+	ks, err := h.keys.Select(id.KeyID)
+	if err != nil {
+		writeError(w, http.StatusForbidden, err)
+		return nil, false
+	}
+	return ks, true
 }
 
 func (h *Handler) encrypt(w http.ResponseWriter, r *http.Request) {
@@ -50,16 +181,19 @@ func (h *Handler) encrypt(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Value bool `json:"value"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, err)
+	if !readJSON(w, r, &req) {
 		return
 	}
-	ct, err := h.boolean.EncryptBoolToBase64(req.Value)
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	ct, err := ks.Boolean.EncryptBoolToBase64(r.Context(), req.Value)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+		writeError(w, statusFor(err), err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]string{"ciphertext": ct})
+	writeCiphertext(w, ct)
 }
 
 func (h *Handler) decrypt(w http.ResponseWriter, r *http.Request) {
@@ -70,28 +204,31 @@ func (h *Handler) decrypt(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Ciphertext string `json:"ciphertext"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, err)
+	if !readJSON(w, r, &req) {
 		return
 	}
-	value, err := h.boolean.DecryptBoolFromBase64(req.Ciphertext)
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	value, err := ks.Boolean.DecryptBoolFromBase64(r.Context(), req.Ciphertext)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+		writeError(w, statusFor(err), err)
 		return
 	}
 	writeJSON(w, http.StatusOK, map[string]bool{"value": value})
 }
 
 func (h *Handler) and(w http.ResponseWriter, r *http.Request) {
-	h.binaryOp(w, r, h.boolean.AndBase64)
+	h.binaryOp(w, r, (*tfhe.BooleanService).AndBase64)
 }
 
 func (h *Handler) or(w http.ResponseWriter, r *http.Request) {
-	h.binaryOp(w, r, h.boolean.OrBase64)
+	h.binaryOp(w, r, (*tfhe.BooleanService).OrBase64)
 }
 
 func (h *Handler) xor(w http.ResponseWriter, r *http.Request) {
-	h.binaryOp(w, r, h.boolean.XorBase64)
+	h.binaryOp(w, r, (*tfhe.BooleanService).XorBase64)
 }
 
 func (h *Handler) not(w http.ResponseWriter, r *http.Request) {
@@ -102,19 +239,23 @@ func (h *Handler) not(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Ciphertext string `json:"ciphertext"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, err)
+	if !readJSON(w, r, &req) {
 		return
 	}
-	ct, err := h.boolean.NotBase64(req.Ciphertext)
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	ct, err := ks.Boolean.NotBase64(r.Context(), req.Ciphertext)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+		err = inputField(err, "ciphertext")
+		writeError(w, statusFor(err), err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]string{"ciphertext": ct})
+	writeCiphertext(w, ct)
 }
 
-type opFunc func(lhs, rhs string) (string, error)
+type opFunc func(s *tfhe.BooleanService, ctx context.Context, lhs, rhs string) (string, error)
 
 func (h *Handler) binaryOp(w http.ResponseWriter, r *http.Request, fn opFunc) {
 	if r.Method != http.MethodPost {
@@ -125,119 +266,98 @@ func (h *Handler) binaryOp(w http.ResponseWriter, r *http.Request, fn opFunc) {
 		Left  string `json:"left"`
 		Right string `json:"right"`
 	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, err)
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
 		return
 	}
-	ct, err := fn(req.Left, req.Right)
+	ct, err := fn(ks.Boolean, r.Context(), req.Left, req.Right)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+		err = operandFields(err, "left", "right")
+		writeError(w, statusFor(err), err)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]string{"ciphertext": ct})
+	writeCiphertext(w, ct)
 }
 
-func writeJSON(w http.ResponseWriter, status int, body any) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	_ = json.NewEncoder(w).Encode(body)
+// readJSON decodes the request body into v, bounding its size. It writes the
+// error response itself and reports whether decoding succeeded.
+func readJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	return readJSONLimit(w, r, v, maxBodyBytes())
 }
 
-func writeError(w http.ResponseWriter, status int, err error) {
-	writeJSON(w, status, map[string]string{"error": err.Error()})
+// readJSONLimit is readJSON with an explicit body size limit.
+func readJSONLimit(w http.ResponseWriter, r *http.Request, v any, limit int64) bool {
+	r.Body = http.MaxBytesReader(w, r.Body, limit)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err)
+		return false
+	}
+	return true
 }
 
-func (h *Handler) encryptUint8(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-	var req struct {
-		Value uint8 `json:"value"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, err)
-		return
-	}
-	ct, err := h.uint8.Encrypt(req.Value)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
-		return
-	}
-	writeJSON(w, http.StatusOK, map[string]string{"ciphertext": ct})
+// MaxRequestBytes returns the largest request body any handler reads under
+// the current limits, for middleware that reads bodies itself.
+func MaxRequestBytes() int64 {
+	return max(maxBatchBodyBytes, maxBodyBytes(), int64(tfhe.CurrentLimits().MaxCiphertext())+64<<10)
 }
 
-func (h *Handler) encryptUint8Public(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-	var req struct {
-		Value uint8 `json:"value"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, err)
-		return
-	}
-	ct, err := h.uint8.EncryptWithPublic(req.Value)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
-		return
-	}
-	writeJSON(w, http.StatusOK, map[string]string{"ciphertext": ct})
+// maxBodyBytes allows two base64 operands of the largest accepted size plus JSON framing.
+func maxBodyBytes() int64 {
+	return int64(base64.StdEncoding.EncodedLen(2*tfhe.CurrentLimits().MaxCiphertext()) + 4<<10)
 }
 
-func (h *Handler) decryptUint8(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-	var req struct {
-		Ciphertext string `json:"ciphertext"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, err)
-		return
-	}
-	value, err := h.uint8.Decrypt(req.Ciphertext)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	buf := bufpool.GetBuffer()
+	defer bufpool.PutBuffer(buf)
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(buf).Encode(body); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]uint8{"value": value})
-}
-
-func (h *Handler) addUint8(w http.ResponseWriter, r *http.Request) {
-	h.binaryOpUint8(w, r, h.uint8.Add)
-}
-
-func (h *Handler) bitAndUint8(w http.ResponseWriter, r *http.Request) {
-	h.binaryOpUint8(w, r, h.uint8.BitAnd)
+	w.WriteHeader(status)
+	_, _ = w.Write(buf.Bytes())
 }
 
-func (h *Handler) bitXorUint8(w http.ResponseWriter, r *http.Request) {
-	h.binaryOpUint8(w, r, h.uint8.BitXor)
+// writeError writes err in the error envelope, with a code for its kind
+// and the request field it concerns when that is known.
+func writeError(w http.ResponseWriter, status int, err error) {
+	apierror.Write(w, status, codeFor(err, status), err.Error(), detailsFor(err)...)
 }
 
-type uint8OpFunc func(lhs, rhs string) (string, error)
-
-func (h *Handler) binaryOpUint8(w http.ResponseWriter, r *http.Request, fn uint8OpFunc) {
-	if r.Method != http.MethodPost {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
-	}
-	var req struct {
-		Left  string `json:"left"`
-		Right string `json:"right"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		writeError(w, http.StatusBadRequest, err)
-		return
-	}
-	ct, err := fn(req.Left, req.Right)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, err)
-		return
+// statusFor maps typed tfhe errors to HTTP statuses: bad input is a client
+// error, missing keys or exhausted native memory mean the service cannot take
+// the request right now, anything else is a fault.
+func statusFor(err error) int {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, tfhe.ErrCiphertextTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, tfhe.ErrInvalidCiphertext),
+		errors.Is(err, tfhe.ErrInvalidKey),
+		errors.Is(err, tfhe.ErrValueOutOfRange),
+		errors.Is(err, tfhe.ErrTrivialCiphertext),
+		errors.As(err, &syntaxErr),
+		errors.As(err, &typeErr):
+		return http.StatusBadRequest
+	case errors.Is(err, tfhe.ErrNilKey), errors.Is(err, tfhe.ErrServerKeyNotSet), errors.Is(err, tfhe.ErrMemoryLimit),
+		errors.Is(err, tfhe.ErrOpTimeout), errors.Is(err, tfhe.ErrOverloaded):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, tfhe.ErrUnsupported):
+		return http.StatusNotImplemented
+	case errors.Is(err, tfhe.ErrSwitchKeyNotSet):
+		return http.StatusConflict
+	case errors.Is(err, tfhe.ErrClientKeyWithheld), errors.Is(err, tfhe.ErrTrustedReencryptDisabled):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
 	}
-	writeJSON(w, http.StatusOK, map[string]string{"ciphertext": ct})
 }
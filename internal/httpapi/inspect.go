@@ -0,0 +1,58 @@
+package httpapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// inspectResponse is the description of a ciphertext answered by /inspect.
+type inspectResponse struct {
+	tfhe.CiphertextInfo
+	FormatVersion int `json:"format_version"`
+}
+
+// inspect handles POST /inspect: describes a ciphertext, sent as base64
+// "ciphertext" in a JSON body or as an application/octet-stream body,
+// without decrypting it.
+func (h *Handler) inspect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var data []byte
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/octet-stream" {
+		var err error
+		if data, err = io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodyBytes)); err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				writeError(w, http.StatusRequestEntityTooLarge, err)
+				return
+			}
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+	} else {
+		var req struct {
+			Ciphertext string `json:"ciphertext"`
+		}
+		if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+			return
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(req.Ciphertext); err != nil {
+			writeError(w, http.StatusBadRequest, fieldErr("ciphertext", fmt.Errorf("ciphertext: %w", err)))
+			return
+		}
+	}
+	if len(data) == 0 {
+		writeError(w, http.StatusBadRequest, fieldErr("ciphertext", errors.New("ciphertext is required")))
+		return
+	}
+	writeJSON(w, http.StatusOK, inspectResponse{CiphertextInfo: tfhe.Inspect(data), FormatVersion: CiphertextFormatVersion})
+}
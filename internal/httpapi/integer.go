@@ -0,0 +1,196 @@
+package httpapi
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// integerService is the base64 API shared by the unsigned integer services;
+// T is the plaintext type accepted and returned.
+type integerService[T uint8 | uint64] interface {
+	Encrypt(ctx context.Context, value T) (string, error)
+	EncryptWithPublic(ctx context.Context, value T) (string, error)
+	Decrypt(ctx context.Context, ctBase64 string) (T, error)
+	Add(ctx context.Context, lhs, rhs string) (string, error)
+	BitAnd(ctx context.Context, lhs, rhs string) (string, error)
+	BitXor(ctx context.Context, lhs, rhs string) (string, error)
+	Compare(ctx context.Context, cmp tfhe.Comparison, lhs, rhs string) (string, error)
+	CompareScalar(ctx context.Context, cmp tfhe.Comparison, lhs string, rhs T) (string, error)
+	IfThenElse(ctx context.Context, cond, then, els string) (string, error)
+	ReencryptFor(ctx context.Context, ctBase64, recipient string) (string, error)
+	ReencryptBoolFor(ctx context.Context, ctBase64, recipient string) (string, error)
+}
+
+// integerRoutes serves one /uintN route family against the service picked
+// from the caller's key set.
+type integerRoutes[T uint8 | uint64] struct {
+	h       *Handler
+	service func(ks *keys.KeySet) integerService[T]
+}
+
+func (ir integerRoutes[T]) register(mux routes, prefix string) {
+	ir.h.route(mux, features.Encrypt, prefix+"/encrypt", ir.encrypt(integerService[T].Encrypt))
+	ir.h.route(mux, features.PublicEncrypt, prefix+"/encrypt/public", ir.encrypt(integerService[T].EncryptWithPublic))
+	ir.h.route(mux, features.Decrypt, prefix+"/decrypt", ir.decrypt)
+	handle(mux, prefix+"/add", ir.binaryOp(integerService[T].Add, integerService[T].ReencryptFor))
+	handle(mux, prefix+"/bitand", ir.binaryOp(integerService[T].BitAnd, integerService[T].ReencryptFor))
+	handle(mux, prefix+"/bitxor", ir.binaryOp(integerService[T].BitXor, integerService[T].ReencryptFor))
+	for _, cmp := range tfhe.Comparisons {
+		handle(mux, prefix+"/"+string(cmp), ir.binaryOp(func(s integerService[T], ctx context.Context, lhs, rhs string) (string, error) {
+			return s.Compare(ctx, cmp, lhs, rhs)
+		}, integerService[T].ReencryptBoolFor))
+		handle(mux, prefix+"/scalar_"+string(cmp), ir.compareScalar(cmp))
+	}
+}
+
+// registerIntegerRoutes registers the uint2, uint4, uint8, uint16, uint32 and
+// uint64 route families.
+func (h *Handler) registerIntegerRoutes(mux routes) {
+	integerRoutes[uint8]{h: h, service: func(ks *keys.KeySet) integerService[uint8] { return ks.Uint8 }}.register(mux, "/uint8")
+	for _, bits := range tfhe.IntWidths {
+		routes := integerRoutes[uint64]{h: h, service: func(ks *keys.KeySet) integerService[uint64] { return ks.Int(bits) }}
+		routes.register(mux, "/"+intTypeName(bits))
+	}
+}
+
+type encryptFunc[T uint8 | uint64] func(s integerService[T], ctx context.Context, value T) (string, error)
+
+func (ir integerRoutes[T]) encrypt(fn encryptFunc[T]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Value T `json:"value"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		ks, ok := ir.h.keySet(w, r)
+		if !ok {
+			return
+		}
+		ct, err := fn(ir.service(ks), r.Context(), req.Value)
+		if err != nil {
+			err = inputField(err, "value")
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCiphertext(w, ct)
+	}
+}
+
+func (ir integerRoutes[T]) decrypt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := ir.h.keySet(w, r)
+	if !ok {
+		return
+	}
+	value, err := ir.service(ks).Decrypt(r.Context(), req.Ciphertext)
+	if err != nil {
+		err = inputField(err, "ciphertext")
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]T{"value": value})
+}
+
+type integerOpFunc[T uint8 | uint64] func(s integerService[T], ctx context.Context, lhs, rhs string) (string, error)
+
+type reencryptFunc[T uint8 | uint64] func(s integerService[T], ctx context.Context, ctBase64, recipient string) (string, error)
+
+// binaryOp serves fn; with a recipient_key in the request, the result is
+// handed to reencrypt and returned under that key instead.
+func (ir integerRoutes[T]) binaryOp(fn integerOpFunc[T], reencrypt reencryptFunc[T]) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Left         string `json:"left"`
+			Right        string `json:"right"`
+			RecipientKey string `json:"recipient_key"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.RecipientKey != "" && !ir.h.allow(w, r, features.Decrypt) {
+			return
+		}
+		ks, ok := ir.h.keySet(w, r)
+		if !ok {
+			return
+		}
+		svc := ir.service(ks)
+		ct, err := fn(svc, r.Context(), req.Left, req.Right)
+		err = operandFields(err, "left", "right")
+		if err == nil && req.RecipientKey != "" {
+			ct, err = reencrypt(svc, r.Context(), ct, req.RecipientKey)
+			err = inputField(err, "recipient_key")
+		}
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCiphertext(w, ct)
+	}
+}
+
+// compareScalar compares a ciphertext with a plaintext value, e.g. a public
+// threshold, without the caller encrypting it; a recipient_key works as in
+// binaryOp.
+func (ir integerRoutes[T]) compareScalar(cmp tfhe.Comparison) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Ciphertext   string `json:"ciphertext"`
+			Value        T      `json:"value"`
+			RecipientKey string `json:"recipient_key"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.RecipientKey != "" && !ir.h.allow(w, r, features.Decrypt) {
+			return
+		}
+		ks, ok := ir.h.keySet(w, r)
+		if !ok {
+			return
+		}
+		svc := ir.service(ks)
+		ct, err := svc.CompareScalar(r.Context(), cmp, req.Ciphertext, req.Value)
+		if errors.Is(err, tfhe.ErrValueOutOfRange) {
+			err = fieldErr("value", err)
+		} else {
+			err = inputField(err, "ciphertext")
+		}
+		if err == nil && req.RecipientKey != "" {
+			ct, err = svc.ReencryptBoolFor(r.Context(), ct, req.RecipientKey)
+			err = inputField(err, "recipient_key")
+		}
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCiphertext(w, ct)
+	}
+}
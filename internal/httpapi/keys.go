@@ -0,0 +1,108 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// publicKeyMaxAge is how long clients may reuse a fetched public key without
+// revalidating; rotation changes the key and its ETag.
+const publicKeyMaxAge = 300
+
+func (h *Handler) registerKeyRoutes(mux routes) {
+	h.route(mux, features.Keys, "/keys/public", h.publicKey((*tfhe.Uint8Service).PublicKey))
+	h.route(mux, features.Keys, "/keys/public/compact", h.publicKey((*tfhe.Uint8Service).CompactPublicKey))
+	h.route(mux, features.Keys, "/keys/{id}", h.keyInfo)
+}
+
+// keyInfo handles GET /keys/{id}: the parameter set, sizes and capabilities
+// of the caller's key set. Other key sets are not found, so the endpoint
+// does not reveal which IDs exist; operators use /admin/keys/{id}.
+func (h *Handler) keyInfo(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if id := r.PathValue("id"); id != ks.ID {
+		writeError(w, http.StatusNotFound, fmt.Errorf("%w: %q", keys.ErrUnknownKeySet, id))
+		return
+	}
+	desc, err := ks.Describe(r.Context())
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	w.Header().Add("Vary", "Authorization, X-API-Key")
+	writeJSON(w, http.StatusOK, desc)
+}
+
+type publicKeyFunc func(s *tfhe.Uint8Service, ctx context.Context) (tfhe.PublicKeyExport, error)
+
+// publicKey serves the caller's integer public key so clients can encrypt
+// locally. The key's version is its ETag; If-None-Match gets a 304.
+func (h *Handler) publicKey(fn publicKeyFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		ks, ok := h.keySet(w, r)
+		if !ok {
+			return
+		}
+		key, err := fn(ks.Uint8, r.Context())
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+
+		etag := strconv.Quote(key.Version)
+		hdr := w.Header()
+		hdr.Set("ETag", etag)
+		hdr.Set("Cache-Control", "private, max-age="+strconv.Itoa(publicKeyMaxAge))
+		// The key set, and so the key, depends on the caller's credentials.
+		hdr.Add("Vary", "Authorization, X-API-Key, Accept")
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Accept")); mediaType == "application/octet-stream" {
+			hdr.Set("Content-Type", "application/octet-stream")
+			hdr.Set("Key-Set", ks.ID)
+			hdr.Set("Key-Version", key.Version)
+			_, _ = w.Write(key.Data)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{
+			"key_set":        ks.ID,
+			"version":        key.Version,
+			"public_key":     base64.StdEncoding.EncodeToString(key.Data),
+			"format_version": CiphertextFormatVersion,
+		})
+	}
+}
+
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/machines"
+	"tfhe-go/internal/tfhe"
+)
+
+// maxMachineInputs bounds the inputs one advance request feeds a machine.
+const maxMachineInputs = 64
+
+// SetMachines enables the encrypted state machine routes backed by reg.
+func (h *Handler) SetMachines(reg *machines.Registry) {
+	h.machines = reg
+}
+
+// machineInfo is a machine definition as the API shows it. Tables are ints
+// rather than uint8 so they encode as JSON arrays, not base64.
+type machineInfo struct {
+	Name        string  `json:"name"`
+	States      int     `json:"states"`
+	Inputs      int     `json:"inputs"`
+	Transitions [][]int `json:"transitions"`
+	Outputs     []int   `json:"outputs,omitempty"`
+	Initial     int     `json:"initial"`
+	CreatedAt   string  `json:"created_at"`
+}
+
+func newMachineInfo(d machines.Definition) machineInfo {
+	info := machineInfo{
+		Name:        d.Name,
+		States:      len(d.Machine.Transitions),
+		Inputs:      len(d.Machine.Transitions[0]),
+		Transitions: make([][]int, len(d.Machine.Transitions)),
+		Initial:     int(d.Machine.Initial),
+		CreatedAt:   d.CreatedAt.Format(time.RFC3339),
+	}
+	for s, row := range d.Machine.Transitions {
+		info.Transitions[s] = make([]int, len(row))
+		for a, next := range row {
+			info.Transitions[s][a] = int(next)
+		}
+	}
+	for _, v := range d.Machine.Outputs {
+		info.Outputs = append(info.Outputs, int(v))
+	}
+	return info
+}
+
+// toUint8s converts a JSON table row, failing on entries outside uint8.
+func toUint8s(vals []int) ([]uint8, error) {
+	out := make([]uint8, len(vals))
+	for i, v := range vals {
+		if v < 0 || v > 255 {
+			return nil, fmt.Errorf("%w: %d does not fit uint8", tfhe.ErrValueOutOfRange, v)
+		}
+		out[i] = uint8(v)
+	}
+	return out, nil
+}
+
+func writeMachineError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, machines.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, machines.ErrExists):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, machines.ErrInvalidName):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, machines.ErrLimit):
+		writeError(w, http.StatusForbidden, err)
+	default:
+		writeError(w, statusFor(err), err)
+	}
+}
+
+// machineList handles GET /machines (the caller's tenant's machines) and
+// POST /machines (define one).
+func (h *Handler) machineList(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantOf(r)
+	if r.Method == http.MethodGet {
+		list := h.machines.List(tenant)
+		out := make([]machineInfo, len(list))
+		for i, d := range list {
+			out[i] = newMachineInfo(d)
+		}
+		writeJSON(w, http.StatusOK, map[string][]machineInfo{"machines": out})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Name        string  `json:"name"`
+		Transitions [][]int `json:"transitions"`
+		Outputs     []int   `json:"outputs"`
+		Initial     int     `json:"initial"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	d := machines.Definition{Name: req.Name, Tenant: tenant}
+	d.Machine.Transitions = make([][]uint8, len(req.Transitions))
+	var err error
+	for s := 0; err == nil && s < len(req.Transitions); s++ {
+		d.Machine.Transitions[s], err = toUint8s(req.Transitions[s])
+	}
+	if err == nil && req.Outputs != nil {
+		d.Machine.Outputs, err = toUint8s(req.Outputs)
+	}
+	if err == nil && (req.Initial < 0 || req.Initial > 255) {
+		err = fmt.Errorf("%w: initial state %d does not fit uint8", tfhe.ErrValueOutOfRange, req.Initial)
+	}
+	if err == nil {
+		d.Machine.Initial = uint8(req.Initial)
+		d, err = h.machines.Create(d)
+	}
+	if err != nil {
+		writeMachineError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newMachineInfo(d))
+}
+
+// machine handles GET and DELETE /machines/{name}.
+func (h *Handler) machine(w http.ResponseWriter, r *http.Request) {
+	tenant, name := tenantOf(r), r.PathValue("name")
+	switch r.Method {
+	case http.MethodGet:
+		d, err := h.machines.Get(tenant, name)
+		if err != nil {
+			writeMachineError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newMachineInfo(d))
+	case http.MethodDelete:
+		if err := h.machines.Delete(tenant, name); err != nil {
+			writeMachineError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// machineAdvance handles POST /machines/{name}/advance: feeds encrypted
+// uint8 inputs, in order, to an encrypted uint8 state, or to the machine's
+// initial state when none is given, and answers with the encrypted final
+// state and, for machines with outputs, its encrypted output.
+func (h *Handler) machineAdvance(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		State  string   `json:"state"`
+		Inputs []string `json:"inputs"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	if len(req.Inputs) > maxMachineInputs {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%d inputs, limit is %d", len(req.Inputs), maxMachineInputs))
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.machines.Get(tenantOf(r), r.PathValue("name"))
+	if err != nil {
+		writeMachineError(w, err)
+		return
+	}
+	var state []byte
+	if req.State != "" {
+		if state, err = decodeCiphertext(req.State, typeUint8); err != nil {
+			writeError(w, statusFor(err), fieldErr("state", fmt.Errorf("state: %w", err)))
+			return
+		}
+	}
+	inputs := make([][]byte, len(req.Inputs))
+	for i, in := range req.Inputs {
+		if inputs[i], err = decodeCiphertext(in, typeUint8); err != nil {
+			writeError(w, statusFor(err), fieldErr(fmt.Sprintf("inputs[%d]", i), fmt.Errorf("input %d: %w", i, err)))
+			return
+		}
+	}
+	res, err := ks.Uint8.RunMachineRaw(r.Context(), d.Machine, state, inputs, h.batchConcurrency)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	body := map[string]any{
+		"state":          bufpool.EncodeBase64(res.State),
+		"format_version": CiphertextFormatVersion,
+	}
+	if res.Output != nil {
+		body["output"] = bufpool.EncodeBase64(res.Output)
+	}
+	writeJSON(w, http.StatusOK, body)
+}
@@ -0,0 +1,188 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/models"
+	"tfhe-go/internal/tfhe"
+)
+
+const (
+	modelLinear   = "linear"
+	modelLogistic = "logistic"
+)
+
+// SetModels enables the encrypted scoring routes backed by reg.
+func (h *Handler) SetModels(reg *models.Registry) {
+	h.models = reg
+}
+
+// modelInfo is a model as the API shows and accepts it.
+type modelInfo struct {
+	Name          string    `json:"name"`
+	Kind          string    `json:"kind"`
+	Weights       []float64 `json:"weights"`
+	Bias          float64   `json:"bias"`
+	FracBits      uint      `json:"frac_bits"`
+	SigmoidLevels int       `json:"sigmoid_levels,omitempty"`
+	CreatedAt     string    `json:"created_at,omitempty"`
+}
+
+func newModelInfo(d models.Definition) modelInfo {
+	info := modelInfo{
+		Name:      d.Name,
+		Kind:      modelLinear,
+		Weights:   d.Model.Weights,
+		Bias:      d.Model.Bias,
+		FracBits:  d.Model.FracBits,
+		CreatedAt: d.CreatedAt.Format(time.RFC3339),
+	}
+	if d.Model.Logistic {
+		info.Kind = modelLogistic
+		info.SigmoidLevels = d.Model.SigmoidLevels
+		if info.SigmoidLevels == 0 {
+			info.SigmoidLevels = tfhe.DefaultSigmoidLevels
+		}
+	}
+	return info
+}
+
+func writeModelError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, models.ErrExists):
+		writeError(w, http.StatusConflict, err)
+	case errors.Is(err, models.ErrInvalidName):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, models.ErrLimit):
+		writeError(w, http.StatusForbidden, err)
+	default:
+		writeError(w, statusFor(err), err)
+	}
+}
+
+// modelList handles GET /models (the caller's tenant's models) and POST
+// /models (load one).
+func (h *Handler) modelList(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantOf(r)
+	if r.Method == http.MethodGet {
+		list := h.models.List(tenant)
+		out := make([]modelInfo, len(list))
+		for i, d := range list {
+			out[i] = newModelInfo(d)
+		}
+		writeJSON(w, http.StatusOK, map[string][]modelInfo{"models": out})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req modelInfo
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = modelLinear
+	}
+	if req.Kind != modelLinear && req.Kind != modelLogistic {
+		writeError(w, http.StatusBadRequest, fieldErr("kind", fmt.Errorf("unsupported model kind %q; want linear or logistic", req.Kind)))
+		return
+	}
+	d, err := h.models.Create(models.Definition{
+		Name:   req.Name,
+		Tenant: tenant,
+		Model: tfhe.LinearModel{
+			Weights:       req.Weights,
+			Bias:          req.Bias,
+			FracBits:      req.FracBits,
+			Logistic:      req.Kind == modelLogistic,
+			SigmoidLevels: req.SigmoidLevels,
+		},
+	})
+	if err != nil {
+		writeModelError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, newModelInfo(d))
+}
+
+// model handles GET and DELETE /models/{name}.
+func (h *Handler) model(w http.ResponseWriter, r *http.Request) {
+	tenant, name := tenantOf(r), r.PathValue("name")
+	switch r.Method {
+	case http.MethodGet:
+		d, err := h.models.Get(tenant, name)
+		if err != nil {
+			writeModelError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newModelInfo(d))
+	case http.MethodDelete:
+		if err := h.models.Delete(tenant, name); err != nil {
+			writeModelError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// modelScore handles POST /models/{name}/score: evaluates the model on an
+// encrypted feature vector, one uint32 per weight, and answers with the
+// encrypted score and, for logistic models, the encrypted probability.
+func (h *Handler) modelScore(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Features []string `json:"features"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	if len(req.Features) > tfhe.MaxModelFeatures {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%d features, limit is %d", len(req.Features), tfhe.MaxModelFeatures))
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	d, err := h.models.Get(tenantOf(r), r.PathValue("name"))
+	if err != nil {
+		writeModelError(w, err)
+		return
+	}
+	if len(req.Features) != len(d.Model.Weights) {
+		writeError(w, http.StatusBadRequest, fieldErr("features", fmt.Errorf("%d features for %d weights", len(req.Features), len(d.Model.Weights))))
+		return
+	}
+	features := make([][]byte, len(req.Features))
+	for i, f := range req.Features {
+		if features[i], err = decodeCiphertext(f, intTypeName(32)); err != nil {
+			writeError(w, statusFor(err), fieldErr(fmt.Sprintf("features[%d]", i), fmt.Errorf("feature %d: %w", i, err)))
+			return
+		}
+	}
+	res, err := ks.Uint8.ScoreLinearRaw(r.Context(), d.Model, features, h.batchConcurrency)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	body := map[string]any{
+		"score":          bufpool.EncodeBase64(res.Score),
+		"format_version": CiphertextFormatVersion,
+	}
+	if res.Probability != nil {
+		body["probability"] = bufpool.EncodeBase64(res.Probability)
+	}
+	writeJSON(w, http.StatusOK, body)
+}
@@ -0,0 +1,81 @@
+package httpapi
+
+import (
+	"context"
+	"fmt"
+
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// rawOpFunc is a homomorphic operation over serialized operands.
+type rawOpFunc func(ctx context.Context, operands [][]byte) ([]byte, error)
+
+// resolveOp resolves op for ciphertexts of typ under ks, checking the operand count.
+func (h *Handler) resolveOp(ks *keys.KeySet, typ, op string, arity int) (rawOpFunc, error) {
+	var unary func(ctx context.Context, operand []byte) ([]byte, error)
+	var binary func(ctx context.Context, lhs, rhs []byte) ([]byte, error)
+	switch typ + "/" + op {
+	case "boolean/and":
+		binary = ks.Boolean.AndRaw
+	case "boolean/or":
+		binary = ks.Boolean.OrRaw
+	case "boolean/xor":
+		binary = ks.Boolean.XorRaw
+	case "boolean/not":
+		unary = ks.Boolean.NotRaw
+	case "uint8/add":
+		binary = ks.Uint8.AddRaw
+	case "uint8/bitand":
+		binary = ks.Uint8.BitAndRaw
+	case "uint8/bitxor":
+		binary = ks.Uint8.BitXorRaw
+	case "uint8/shl", "uint8/shr", "uint8/rotate_left", "uint8/rotate_right":
+		binary = func(ctx context.Context, value, amount []byte) ([]byte, error) {
+			return ks.Uint8.ShiftRaw(ctx, tfhe.Shift(op), value, amount)
+		}
+	default:
+		if svc := intService(ks, typ); svc != nil {
+			switch op {
+			case "add":
+				binary = svc.AddRaw
+			case "bitand":
+				binary = svc.BitAndRaw
+			case "bitxor":
+				binary = svc.BitXorRaw
+			}
+		}
+		if binary == nil {
+			return nil, fmt.Errorf("unsupported op %q for type %s", op, typ)
+		}
+	}
+
+	if unary != nil {
+		if arity != 1 {
+			return nil, fmt.Errorf("op %q expects 1 operand, got %d", op, arity)
+		}
+		return func(ctx context.Context, operands [][]byte) ([]byte, error) { return unary(ctx, operands[0]) }, nil
+	}
+	if arity != 2 {
+		return nil, fmt.Errorf("op %q expects 2 operands, got %d", op, arity)
+	}
+	return func(ctx context.Context, operands [][]byte) ([]byte, error) {
+		return binary(ctx, operands[0], operands[1])
+	}, nil
+}
+
+// intTypeName names the bits-wide unsigned integer type, e.g. "uint16".
+func intTypeName(bits int) string {
+	return fmt.Sprintf("uint%d", bits)
+}
+
+// intService returns the integer service for typ, or nil if typ is not one
+// of uint2, uint4, uint16, uint32 or uint64.
+func intService(ks *keys.KeySet, typ string) *tfhe.IntService {
+	for _, bits := range tfhe.IntWidths {
+		if typ == intTypeName(bits) {
+			return ks.Int(bits)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package httpapi
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Option customizes a Handler; pass options to NewHandler.
+type Option func(*Handler)
+
+// Middleware wraps an http.Handler, as the rate limiter and audit log do.
+type Middleware func(http.Handler) http.Handler
+
+// RouteWrapper wraps the handler of one route. pattern is the route as the
+// handler defines it, without any WithPrefix prefix, e.g. "/v1/uint8/add" or
+// its deprecated alias "/uint8/add", so a wrapper can pick the routes it
+// applies to; returning next leaves a route as it is.
+type RouteWrapper func(pattern string, next http.Handler) http.Handler
+
+// WithPrefix mounts the routes under prefix, so "/fhe" serves
+// /fhe/v1/boolean/and, for integrators sharing a mux with their own routes.
+func WithPrefix(prefix string) Option {
+	prefix = strings.TrimSuffix(prefix, "/")
+	if prefix != "" && !strings.HasPrefix(prefix, "/") {
+		prefix = "/" + prefix
+	}
+	return func(h *Handler) { h.prefix = prefix }
+}
+
+// WithMiddleware wraps every route in mw, the first outermost, inside the
+// mux rather than around it, so the wrappers see only requests the handler
+// serves. Options given several times add to the chain.
+func WithMiddleware(mw ...Middleware) Option {
+	return func(h *Handler) { h.middleware = append(h.middleware, mw...) }
+}
+
+// WithRouteWrapper adds fn to the wrappers applied to each route, inside
+// the middleware of WithMiddleware; the first one given is outermost.
+func WithRouteWrapper(fn RouteWrapper) Option {
+	return func(h *Handler) { h.wrappers = append(h.wrappers, fn) }
+}
+
+// routes is where route handlers are registered: a ServeMux, or a router
+// applying a Handler's options on its way to one.
+type routes interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// router registers routes on mux under the prefix, wrapped by the route
+// wrappers and middleware of the Handler's options.
+type router struct {
+	mux        *http.ServeMux
+	prefix     string
+	middleware []Middleware
+	wrappers   []RouteWrapper
+}
+
+// router returns where h registers its routes on mux: mux itself without
+// options.
+func (h *Handler) router(mux *http.ServeMux) routes {
+	if h.prefix == "" && len(h.middleware) == 0 && len(h.wrappers) == 0 {
+		return mux
+	}
+	return router{mux: mux, prefix: h.prefix, middleware: h.middleware, wrappers: h.wrappers}
+}
+
+func (rt router) Handle(pattern string, handler http.Handler) {
+	for i := len(rt.wrappers) - 1; i >= 0; i-- {
+		handler = rt.wrappers[i](pattern, handler)
+	}
+	for i := len(rt.middleware) - 1; i >= 0; i-- {
+		handler = rt.middleware[i](handler)
+	}
+	// A pattern may start with a method, as in "GET /v1/version".
+	method, path, ok := strings.Cut(pattern, " ")
+	if !ok {
+		method, path = "", pattern
+	} else {
+		method += " "
+	}
+	rt.mux.Handle(method+rt.prefix+path, handler)
+}
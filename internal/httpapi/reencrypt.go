@@ -0,0 +1,158 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tfhe-go/internal/acl"
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/tfhe"
+)
+
+var errNoKeySwitching = fmt.Errorf("key switching needs a server built with -tags purego: %w", tfhe.ErrUnsupported)
+
+// keySwitching returns fn on backends implementing key switching. Others
+// answer 501 instead, so clients can tell the missing backend support from
+// a feature switched off.
+func keySwitching(fn http.HandlerFunc) http.HandlerFunc {
+	if tfhe.KeySwitching {
+		return fn
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, statusFor(errNoKeySwitching), errNoKeySwitching)
+	}
+}
+
+// maxSwitchKeyBody bounds PUT /keys/switch; a boolean switch key is about
+// 60 KiB, 80 KiB in base64.
+const maxSwitchKeyBody = 1 << 20
+
+// switchKey handles PUT /keys/switch, installing the caller's key switching
+// boolean ciphertexts from the service's client key to the caller's, and
+// DELETE /keys/switch, removing it.
+func (h *Handler) switchKey(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPut && r.Method != http.MethodDelete {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var data []byte
+	if r.Method == http.MethodPut {
+		var req struct {
+			SwitchKey string `json:"switch_key"`
+		}
+		if !readJSONLimit(w, r, &req, maxSwitchKeyBody) {
+			return
+		}
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(req.SwitchKey); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(data) == 0 {
+			writeError(w, http.StatusBadRequest, fieldErr("switch_key", errors.New("switch_key is required")))
+			return
+		}
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if err := ks.Boolean.SetSwitchKey(tenantOf(r), data); err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// reencrypt handles POST /reencrypt: returns a stored boolean ciphertext the
+// caller's tenant owns, or the caller was granted re-encryption of, switched
+// under the caller's own key.
+func (h *Handler) reencrypt(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Handle string `json:"handle"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.Handle == "" {
+		writeError(w, http.StatusBadRequest, fieldErr("handle", errors.New("handle is required")))
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	entry, err := h.openHandle(r, req.Handle, acl.Reencrypt)
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	if entry.Type != typeBoolean {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("handle %s has type %s; only boolean ciphertexts can be re-encrypted", entry.ID, entry.Type))
+		return
+	}
+	out, err := ks.Boolean.ReencryptRaw(r.Context(), tenantOf(r), entry.Data)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"type":           typeBoolean,
+		"ciphertext":     bufpool.EncodeBase64(out),
+		"format_version": CiphertextFormatVersion,
+	})
+}
+
+// reencryptPublic handles POST /reencrypt/public: decrypts a ciphertext of
+// the given type (bool, uint8 or uintN) under the service's client key and
+// returns it encrypted under the caller-supplied compact public key, so
+// only the holder of the matching client key can read it.
+func (h *Handler) reencryptPublic(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Type         string `json:"type"`
+		Ciphertext   string `json:"ciphertext"`
+		RecipientKey string `json:"recipient_key"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	if req.RecipientKey == "" {
+		writeError(w, http.StatusBadRequest, fieldErr("recipient_key", errors.New("recipient_key is required")))
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	var reencrypt func(ctx context.Context, ctBase64, recipient string) (string, error)
+	switch req.Type {
+	case typeBool:
+		reencrypt = ks.Uint8.ReencryptBoolFor
+	case typeUint8:
+		reencrypt = ks.Uint8.ReencryptFor
+	default:
+		svc := intService(ks, req.Type)
+		if svc == nil {
+			writeError(w, http.StatusBadRequest, fieldErr("type", fmt.Errorf("unsupported type %q", req.Type)))
+			return
+		}
+		reencrypt = svc.ReencryptFor
+	}
+	ct, err := reencrypt(r.Context(), req.Ciphertext, req.RecipientKey)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	writeCiphertext(w, ct)
+}
@@ -0,0 +1,62 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/features"
+)
+
+// SetFeatures switches route families off, or restricts them to the
+// identities in admins (any authenticated caller when empty, as on /admin);
+// call it before Register. Switched-off routes are not registered, so they
+// answer 404 like any unknown path.
+func (h *Handler) SetFeatures(p features.Policy, admins []string) {
+	h.features, h.admins = p, adminSet(admins)
+}
+
+// SetFeatures switches off the admin routes of the families p turns off;
+// call it before Register.
+func (h *AdminHandler) SetFeatures(p features.Policy) {
+	h.features = p
+}
+
+// route registers fn at path as part of feature f, as the handler's
+// feature policy allows.
+func (h *Handler) route(mux routes, f features.Feature, path string, fn http.HandlerFunc) {
+	switch h.features.Access(f) {
+	case features.Off:
+		return
+	case features.Admin:
+		fn = requireAdmin(h.admins, fn)
+	}
+	handle(mux, path, fn)
+}
+
+// allow reports whether the caller may use feature f from a route of
+// another family, writing a 403 when the policy forbids it.
+func (h *Handler) allow(w http.ResponseWriter, r *http.Request, f features.Feature) bool {
+	switch h.features.Access(f) {
+	case features.Off:
+		writeError(w, http.StatusForbidden, fmt.Errorf("feature %s is switched off", f))
+		return false
+	case features.Admin:
+		if h.admins != nil {
+			id, ok := auth.FromContext(r.Context())
+			if !ok || !h.admins[id.ID] {
+				writeError(w, http.StatusForbidden, errors.New("admin access required"))
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// route registers the admin route fn at path unless feature f is off.
+func (h *AdminHandler) route(mux *http.ServeMux, f features.Feature, path string, fn http.HandlerFunc) {
+	if h.features.Access(f) != features.Off {
+		handle(mux, path, h.authorize(fn))
+	}
+}
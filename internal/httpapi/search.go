@@ -0,0 +1,108 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"tfhe-go/internal/acl"
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/store"
+)
+
+// maxSearchHandles bounds the handles one search compares against; it is a
+// full store page, so searching a tenant's handles takes one listing.
+const maxSearchHandles = store.MaxPageSize
+
+// search handles POST /ciphertexts/search: compares an encrypted uint8 query
+// with stored uint8 handles for equality, by default every uint8 handle of
+// the caller's tenant, otherwise the listed handles the caller may use, and
+// answers with an encrypted match flag per handle
+// and, with "index": true, an encrypted uint16 holding one plus the
+// position of the match, or zero.
+func (h *Handler) search(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Query   string   `json:"query"`
+		Handles []string `json:"handles"`
+		Index   bool     `json:"index"`
+	}
+	if !readJSON(w, r, &req) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if req.Query == "" {
+		writeError(w, http.StatusBadRequest, fieldErr("query", errors.New("query is required")))
+		return
+	}
+	query, err := decodeCiphertext(req.Query, typeUint8)
+	if err != nil {
+		writeError(w, statusFor(err), fieldErr("query", fmt.Errorf("query: %w", err)))
+		return
+	}
+
+	tenant := tenantOf(r)
+	var entries []store.Entry
+	if req.Handles == nil {
+		page, err := h.store.ListPage(store.ListOptions{
+			Filter: store.Filter{Tenant: tenant, Type: typeUint8},
+			Limit:  maxSearchHandles,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if page.Next != "" {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("more than %d uint8 handles; pass the handles to search", maxSearchHandles))
+			return
+		}
+		entries = page.Entries
+	} else {
+		if len(req.Handles) > maxSearchHandles {
+			writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%d handles, limit is %d", len(req.Handles), maxSearchHandles))
+			return
+		}
+		for _, id := range req.Handles {
+			entry, err := h.openHandle(r, id, acl.Use)
+			if err != nil {
+				writeStoreError(w, err)
+				return
+			}
+			if entry.Type != typeUint8 {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("handle %s has type %s; only uint8 handles can be searched", id, entry.Type))
+				return
+			}
+			entries = append(entries, entry)
+		}
+	}
+
+	handles := make([]string, len(entries))
+	values := make([][]byte, len(entries))
+	for i, e := range entries {
+		handles[i], values[i] = e.ID, e.Data
+	}
+	res, err := ks.Uint8.SearchRaw(r.Context(), query, values, req.Index, h.batchConcurrency)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	matches := make([]string, len(res.Flags))
+	for i, flag := range res.Flags {
+		matches[i] = bufpool.EncodeBase64(flag)
+	}
+	body := map[string]any{
+		"handles":        handles,
+		"matches":        matches,
+		"format_version": CiphertextFormatVersion,
+	}
+	if req.Index {
+		body["index"] = bufpool.EncodeBase64(res.Index)
+	}
+	writeJSON(w, http.StatusOK, body)
+}
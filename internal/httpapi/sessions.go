@@ -0,0 +1,93 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/sessions"
+)
+
+// SetSessions enables the session routes backed by m; call it before
+// Register.
+func (h *Handler) SetSessions(m *sessions.Manager) {
+	h.sessions = m
+}
+
+func writeSessionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, sessions.ErrUnknownSession):
+		writeError(w, http.StatusNotFound, err)
+	case errors.Is(err, sessions.ErrInvalidTTL):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, sessions.ErrTooManySessions):
+		writeError(w, http.StatusTooManyRequests, err)
+	default:
+		writeError(w, statusFor(err), err)
+	}
+}
+
+// sessionList handles GET /sessions (the caller's tenant's open sessions)
+// and POST /sessions (generate the keys of a new one).
+func (h *Handler) sessionList(w http.ResponseWriter, r *http.Request) {
+	tenant := tenantOf(r)
+	if r.Method == http.MethodGet {
+		writeJSON(w, http.StatusOK, map[string][]sessions.Info{"sessions": h.sessions.List(tenant)})
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		TTLSeconds int64 `json:"ttl_seconds"`
+	}
+	if r.ContentLength != 0 && !readJSON(w, r, &req) {
+		return
+	}
+	if req.TTLSeconds < 0 || req.TTLSeconds > int64(time.Duration(1<<63-1)/time.Second) {
+		writeError(w, http.StatusBadRequest, fieldErr("ttl_seconds", fmt.Errorf("%w: ttl_seconds %d", sessions.ErrInvalidTTL, req.TTLSeconds)))
+		return
+	}
+	info, err := h.sessions.Create(tenant, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		writeSessionError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, info)
+}
+
+// session handles GET /sessions/{id} and DELETE /sessions/{id}, which
+// destroys the session's keys and deletes the handles stored under them.
+func (h *Handler) session(w http.ResponseWriter, r *http.Request) {
+	tenant, id := tenantOf(r), r.PathValue("id")
+	switch r.Method {
+	case http.MethodGet:
+		info, err := h.sessions.Get(id, tenant)
+		if err != nil {
+			writeSessionError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, info)
+	case http.MethodDelete:
+		if err := h.sessions.Close(id, tenant); err != nil {
+			writeSessionError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// trackHandle ties a newly stored handle to the caller's session, if any,
+// so it is deleted when the session ends.
+func (h *Handler) trackHandle(r *http.Request, handle string) {
+	if h.sessions == nil {
+		return
+	}
+	id, _ := auth.FromContext(r.Context())
+	h.sessions.Track(id.KeyID, handle)
+}
@@ -0,0 +1,53 @@
+package httpapi
+
+import (
+	"net/http"
+
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/tfhe"
+)
+
+// registerShiftRoutes registers /uint8/shl, /uint8/shr, /uint8/rotate_left
+// and /uint8/rotate_right.
+func (h *Handler) registerShiftRoutes(mux routes) {
+	for _, op := range tfhe.Shifts {
+		handle(mux, "/uint8/"+string(op), h.shift(op))
+	}
+}
+
+// shift shifts or rotates the uint8 ciphertext left by the encrypted amount
+// right, re-encrypting the result under recipient_key when one is given.
+func (h *Handler) shift(op tfhe.Shift) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var req struct {
+			Left         string `json:"left"`
+			Right        string `json:"right"`
+			RecipientKey string `json:"recipient_key"`
+		}
+		if !readJSON(w, r, &req) {
+			return
+		}
+		if req.RecipientKey != "" && !h.allow(w, r, features.Decrypt) {
+			return
+		}
+		ks, ok := h.keySet(w, r)
+		if !ok {
+			return
+		}
+		ct, err := ks.Uint8.Shift(r.Context(), op, req.Left, req.Right)
+		err = operandFields(err, "left", "right")
+		if err == nil && req.RecipientKey != "" {
+			ct, err = ks.Uint8.ReencryptFor(r.Context(), ct, req.RecipientKey)
+			err = inputField(err, "recipient_key")
+		}
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeCiphertext(w, ct)
+	}
+}
@@ -0,0 +1,68 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+
+	"tfhe-go/internal/bufpool"
+)
+
+// maxSortValues bounds the values of one /uint8/sort request; the network
+// for 256 values is about 3,800 compare-exchanges.
+const maxSortValues = 256
+
+// sort handles POST /uint8/sort: sorts encrypted uint8 values with a sorting
+// network, answering with the ciphertexts in ascending order, or descending
+// with "order": "desc".
+func (h *Handler) sort(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Ciphertexts []string `json:"ciphertexts"`
+		Order       string   `json:"order"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	var descending bool
+	switch req.Order {
+	case "", "asc":
+	case "desc":
+		descending = true
+	default:
+		writeError(w, http.StatusBadRequest, fieldErr("order", fmt.Errorf("order must be asc or desc, not %q", req.Order)))
+		return
+	}
+	if len(req.Ciphertexts) > maxSortValues {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("%d values, limit is %d", len(req.Ciphertexts), maxSortValues))
+		return
+	}
+
+	values := make([][]byte, len(req.Ciphertexts))
+	for i, ct := range req.Ciphertexts {
+		raw, err := bufpool.DecodeBase64(ct)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("ciphertexts[%d]", i), fmt.Errorf("value %d: %w", i, err)))
+			return
+		}
+		defer bufpool.Put(raw)
+		values[i] = *raw
+	}
+
+	out, err := ks.Uint8.SortRaw(r.Context(), values, descending, h.batchConcurrency)
+	if err != nil {
+		writeError(w, statusFor(err), err)
+		return
+	}
+	cts := make([]string, len(out))
+	for i, raw := range out {
+		cts[i] = bufpool.EncodeBase64(raw)
+	}
+	writeJSON(w, http.StatusOK, gateBatchResponse{Ciphertexts: cts, FormatVersion: CiphertextFormatVersion})
+}
@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// debugTimingHeader asks for the timing in the body as well.
+const debugTimingHeader = "Debug-Timing"
+
+// maxDebugTimingBody bounds the JSON response held back to add the debug
+// timing field; a longer one is sent as it is written, with the header only.
+const maxDebugTimingBody = 1 << 20
+
+// ServerTiming reports how long each request spent waiting for compute
+// slots and deserializing, computing and serializing in tfhe-c, plus its
+// total time in the server, in a Server-Timing header, so clients can tell
+// network and payload costs from FHE compute. A request with a
+// Debug-Timing: 1 header also gets the figures, in milliseconds, in a
+// "timing" field of a JSON object response of up to maxDebugTimingBody
+// bytes, which is buffered for it; other responses, and ones the handler
+// flushes, stream as usual. WebSocket upgrades are passed through.
+func ServerTiming(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tw := &timingWriter{ResponseWriter: w, start: time.Now(), debug: r.Header.Get(debugTimingHeader) == "1"}
+		r = r.WithContext(tfhe.WithTiming(r.Context(), &tw.timing))
+		if tw.debug {
+			defer tw.finish()
+		}
+		next.ServeHTTP(tw, r)
+	})
+}
+
+// timingWriter sets the Server-Timing header as the response starts or,
+// for the debug field, buffers a short JSON response until it ends.
+type timingWriter struct {
+	http.ResponseWriter
+	start       time.Time
+	timing      tfhe.Timing
+	debug       bool
+	wroteHeader bool
+	buffering   bool
+	status      int
+	buf         bytes.Buffer
+}
+
+func (tw *timingWriter) WriteHeader(status int) {
+	if tw.wroteHeader {
+		return
+	}
+	tw.wroteHeader, tw.status = true, status
+	if tw.debug && bufferable(tw.Header()) {
+		tw.buffering = true
+		return
+	}
+	tw.sendHeader()
+}
+
+func (tw *timingWriter) Write(p []byte) (int, error) {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if tw.buffering {
+		if tw.buf.Len()+len(p) <= maxDebugTimingBody {
+			return tw.buf.Write(p)
+		}
+		if err := tw.stopBuffering(); err != nil {
+			return 0, err
+		}
+	}
+	return tw.ResponseWriter.Write(p)
+}
+
+// Flush sends the response so far, giving up on the debug field.
+func (tw *timingWriter) Flush() {
+	if tw.buffering {
+		_ = tw.stopBuffering()
+	}
+	_ = http.NewResponseController(tw.ResponseWriter).Flush()
+}
+
+func (tw *timingWriter) Unwrap() http.ResponseWriter { return tw.ResponseWriter }
+
+// bufferable reports whether a response with header h may be held back for
+// the debug field: a JSON body not declared longer than maxDebugTimingBody.
+func bufferable(h http.Header) bool {
+	if mediaType, _, _ := mime.ParseMediaType(h.Get("Content-Type")); mediaType != "application/json" {
+		return false
+	}
+	n, err := strconv.ParseInt(h.Get("Content-Length"), 10, 64)
+	return err != nil || n <= maxDebugTimingBody
+}
+
+// sendHeader sends the status with the timing so far.
+func (tw *timingWriter) sendHeader() {
+	tw.Header().Set("Server-Timing", tw.timing.ServerTiming(time.Since(tw.start)))
+	tw.ResponseWriter.WriteHeader(tw.status)
+}
+
+// stopBuffering sends what was held back and passes the rest through.
+func (tw *timingWriter) stopBuffering() error {
+	tw.buffering = false
+	tw.sendHeader()
+	_, err := tw.ResponseWriter.Write(tw.buf.Bytes())
+	tw.buf = bytes.Buffer{}
+	return err
+}
+
+// finish writes a buffered response with the timing added to it.
+func (tw *timingWriter) finish() {
+	if !tw.wroteHeader {
+		tw.WriteHeader(http.StatusOK)
+	}
+	if !tw.buffering {
+		return
+	}
+	total := time.Since(tw.start)
+	w := tw.ResponseWriter
+	w.Header().Set("Server-Timing", tw.timing.ServerTiming(total))
+	w.Header().Del("Content-Length")
+	w.WriteHeader(tw.status)
+	_, _ = w.Write(withTiming(tw.buf.Bytes(), &tw.timing, total))
+}
+
+// withTiming adds a "timing" field to body when it is a JSON object.
+func withTiming(body []byte, t *tfhe.Timing, total time.Duration) []byte {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) < 2 || trimmed[0] != '{' || !json.Valid(trimmed) {
+		return body
+	}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	field := fmt.Sprintf(`"timing":{"queue_ms":%.3f,"deserialize_ms":%.3f,"compute_ms":%.3f,"serialize_ms":%.3f,"total_ms":%.3f}`,
+		ms(t.Queue()), ms(t.Deserialize()), ms(t.Compute()), ms(t.Serialize()), ms(total))
+	inner := bytes.TrimSpace(trimmed[1 : len(trimmed)-1])
+	out := make([]byte, 0, len(trimmed)+len(field)+3)
+	out = append(out, '{')
+	if len(inner) > 0 {
+		out = append(append(out, inner...), ',')
+	}
+	out = append(append(out, field...), "}\n"...)
+	return out
+}
@@ -0,0 +1,160 @@
+package httpapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+var serverTimingPattern = regexp.MustCompile(`^queue;dur=\d+\.\d{3}, deserialize;dur=\d+\.\d{3}, compute;dur=\d+\.\d{3}, serialize;dur=\d+\.\d{3}, total;dur=\d+\.\d{3}$`)
+
+func TestWithTiming(t *testing.T) {
+	var timing tfhe.Timing
+	for _, tc := range []struct {
+		name, body string
+		fields     []string // of the result, besides timing; nil if unchanged
+	}{
+		{name: "object", body: `{"ciphertext":"AAAA"}`, fields: []string{"ciphertext"}},
+		{name: "padded object", body: " {\"a\":1,\"b\":[2]}\n", fields: []string{"a", "b"}},
+		{name: "empty object", body: `{}`, fields: []string{}},
+		{name: "array", body: `[{"a":1}]`},
+		{name: "invalid", body: `{"a":`},
+		{name: "empty", body: ``},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out := withTiming([]byte(tc.body), &timing, 1500*time.Microsecond)
+			if tc.fields == nil {
+				if string(out) != tc.body {
+					t.Fatalf("got %q, want the body unchanged", out)
+				}
+				return
+			}
+			var got map[string]json.RawMessage
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatalf("%q: %v", out, err)
+			}
+			for _, f := range tc.fields {
+				if _, ok := got[f]; !ok {
+					t.Errorf("field %s lost from %q", f, out)
+				}
+			}
+			var ms map[string]float64
+			if err := json.Unmarshal(got["timing"], &ms); err != nil {
+				t.Fatalf("timing in %q: %v", out, err)
+			}
+			for _, k := range []string{"queue_ms", "deserialize_ms", "compute_ms", "serialize_ms"} {
+				if v, ok := ms[k]; !ok || v != 0 {
+					t.Errorf("timing %s = %v, %v, want 0", k, v, ok)
+				}
+			}
+			if ms["total_ms"] != 1.5 {
+				t.Errorf("total_ms = %v, want 1.5", ms["total_ms"])
+			}
+		})
+	}
+}
+
+func TestServerTiming(t *testing.T) {
+	large := `{"ciphertext":"` + strings.Repeat("A", maxDebugTimingBody) + `"}`
+	for _, tc := range []struct {
+		name        string
+		debug       bool
+		contentType string
+		body        string
+		timed       bool // the body gets the timing field
+	}{
+		{name: "json", contentType: "application/json", body: `{"a":1}`},
+		{name: "debug json", debug: true, contentType: "application/json", body: `{"a":1}`, timed: true},
+		{name: "debug json with charset", debug: true, contentType: "application/json; charset=utf-8", body: `{"a":1}`, timed: true},
+		{name: "debug binary", debug: true, contentType: "application/octet-stream", body: "\x00\x01"},
+		{name: "debug large json", debug: true, contentType: "application/json", body: large},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			h := ServerTiming(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.WriteHeader(http.StatusCreated)
+				_, _ = w.Write([]byte(tc.body))
+				if sent := rec.Body.Len() > 0; sent == tc.timed {
+					t.Errorf("body sent before the handler returned: %v, want %v", sent, !tc.timed)
+				}
+			}))
+			req := httptest.NewRequest(http.MethodPost, "/", nil)
+			if tc.debug {
+				req.Header.Set(debugTimingHeader, "1")
+			}
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusCreated {
+				t.Errorf("status %d, want %d", rec.Code, http.StatusCreated)
+			}
+			if v := rec.Header().Get("Server-Timing"); !serverTimingPattern.MatchString(v) {
+				t.Errorf("Server-Timing %q", v)
+			}
+			if timed := bytes.Contains(rec.Body.Bytes(), []byte(`"timing":`)); timed != tc.timed {
+				t.Errorf("timing field in body: %v, want %v", timed, tc.timed)
+			}
+			if !tc.timed && rec.Body.String() != tc.body {
+				t.Errorf("body changed to %.100q", rec.Body.String())
+			}
+		})
+	}
+}
+
+// A handler that flushes, as streaming ones do, reaches the client at once
+// even with the debug field asked for.
+func TestServerTimingFlush(t *testing.T) {
+	rec := httptest.NewRecorder()
+	h := ServerTiming(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"n":1}` + "\n"))
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Fatalf("flush: %v", err)
+		}
+		if !rec.Flushed || rec.Body.String() != `{"n":1}`+"\n" {
+			t.Fatalf("flushed %v with body %q", rec.Flushed, rec.Body)
+		}
+		_, _ = w.Write([]byte(`{"n":2}` + "\n"))
+	}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(debugTimingHeader, "1")
+	h.ServeHTTP(rec, req)
+	if got, want := rec.Body.String(), `{"n":1}`+"\n"+`{"n":2}`+"\n"; got != want {
+		t.Errorf("body %q, want %q", got, want)
+	}
+	if v := rec.Header().Get("Server-Timing"); !serverTimingPattern.MatchString(v) {
+		t.Errorf("Server-Timing %q", v)
+	}
+}
+
+// The timing of a request covers the native calls its handler makes.
+func TestServerTimingHandler(t *testing.T) {
+	mux := fuzzMux(t)
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/boolean/encrypt", strings.NewReader(`{"value":true}`))
+	req.Header.Set(debugTimingHeader, "1")
+	ServerTiming(mux).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var resp struct {
+		Ciphertext string             `json:"ciphertext"`
+		Timing     map[string]float64 `json:"timing"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Ciphertext == "" || resp.Timing == nil {
+		t.Fatalf("response %s lacks the ciphertext or timing", rec.Body)
+	}
+	if resp.Timing["total_ms"] < resp.Timing["compute_ms"]+resp.Timing["serialize_ms"] {
+		t.Errorf("timing %v: phases exceed the total", resp.Timing)
+	}
+}
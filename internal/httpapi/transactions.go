@@ -0,0 +1,176 @@
+package httpapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"tfhe-go/internal/acl"
+	"tfhe-go/internal/store"
+)
+
+// maxTransactionSteps bounds the steps of one /ciphertexts/transactions
+// request.
+const maxTransactionSteps = 256
+
+// transactionStep is one operation of a transaction. Operands name stored
+// handles or, as "$n", the result of step n. A result is kept as a new
+// handle with Keep, written over the caller's handle Into, or otherwise
+// only used by later steps.
+type transactionStep struct {
+	Op       string   `json:"op"`
+	Operands []string `json:"operands"`
+	Keep     bool     `json:"keep"`
+	Into     string   `json:"into"`
+}
+
+// transactionResult reports a step whose result was stored.
+type transactionResult struct {
+	Step   int    `json:"step"`
+	Handle string `json:"handle"`
+	Type   string `json:"type"`
+}
+
+// transaction handles POST /ciphertexts/transactions: runs a pipeline of
+// operations over stored handles and commits every stored result at once.
+// A failing step stores nothing, and other clients see either none of the
+// results or all of them.
+func (h *Handler) transaction(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	var req struct {
+		Steps []transactionStep `json:"steps"`
+	}
+	if !readJSONLimit(w, r, &req, maxBatchBodyBytes) {
+		return
+	}
+	defer h.rotation.HoldWrites()()
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	if len(req.Steps) == 0 {
+		writeError(w, http.StatusBadRequest, fieldErr("steps", errors.New("steps are required")))
+		return
+	}
+	if len(req.Steps) > maxTransactionSteps {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("transaction has %d steps, limit is %d", len(req.Steps), maxTransactionSteps))
+		return
+	}
+
+	tenant := tenantOf(r)
+	type result struct {
+		typ  string
+		data []byte
+	}
+	results := make([]result, len(req.Steps))
+	stored := make(map[string]store.Entry)
+	var writes []store.Write
+	var steps []int
+	targets := make(map[string]bool)
+	for i, step := range req.Steps {
+		if step.Keep && step.Into != "" {
+			writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("steps[%d].into", i), fmt.Errorf("step %d: keep and into are exclusive", i)))
+			return
+		}
+		if len(step.Operands) == 0 {
+			writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("steps[%d].operands", i), fmt.Errorf("step %d: operands are required", i)))
+			return
+		}
+		var typ string
+		operands := make([][]byte, len(step.Operands))
+		for j, operand := range step.Operands {
+			var operandType string
+			if ref, ok := strings.CutPrefix(operand, "$"); ok {
+				n, err := strconv.Atoi(ref)
+				if err != nil || n < 0 || n >= i {
+					writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("steps[%d].operands[%d]", i, j), fmt.Errorf("step %d: operand %q does not name an earlier step", i, operand)))
+					return
+				}
+				operandType, operands[j] = results[n].typ, results[n].data
+			} else {
+				entry, ok := stored[operand]
+				if !ok {
+					var err error
+					if entry, err = h.openHandle(r, operand, acl.Use); err != nil {
+						writeStoreError(w, fmt.Errorf("step %d: %w", i, err))
+						return
+					}
+					stored[operand] = entry
+				}
+				operandType, operands[j] = entry.Type, entry.Data
+			}
+			if typ != "" && operandType != typ {
+				writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("steps[%d].operands[%d]", i, j), fmt.Errorf("step %d: operand %s has type %s, expected %s", i, operand, operandType, typ)))
+				return
+			}
+			typ = operandType
+		}
+
+		fn, err := h.resolveOp(ks, typ, step.Op, len(operands))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fieldErr(fmt.Sprintf("steps[%d].op", i), fmt.Errorf("step %d: %w", i, err)))
+			return
+		}
+		out, err := fn(r.Context(), operands)
+		if err != nil {
+			writeError(w, statusFor(err), fmt.Errorf("step %d: %w", i, err))
+			return
+		}
+		results[i] = result{typ: typ, data: out}
+
+		switch {
+		case step.Keep:
+			writes = append(writes, store.Write{Tenant: tenant, Type: typ, KeyRef: keyRef(ks), Data: out})
+		case step.Into != "":
+			if targets[step.Into] {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("step %d: handle %s is written by an earlier step", i, step.Into))
+				return
+			}
+			targets[step.Into] = true
+			// Only the owning tenant may overwrite a handle, as for DELETE.
+			entry, err := h.store.Get(step.Into)
+			if err == nil && entry.Tenant != tenant {
+				err = fmt.Errorf("%w: %s", store.ErrNotFound, step.Into)
+			}
+			if err != nil {
+				writeStoreError(w, fmt.Errorf("step %d: %w", i, err))
+				return
+			}
+			if entry.Type != typ {
+				writeError(w, http.StatusBadRequest, fmt.Errorf("step %d: handle %s has type %s, result has %s", i, step.Into, entry.Type, typ))
+				return
+			}
+			writes = append(writes, store.Write{ID: step.Into, KeyRef: keyRef(ks), Data: out})
+		default:
+			continue
+		}
+		steps = append(steps, i)
+	}
+	if len(writes) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("no step keeps its result or writes it into a handle"))
+		return
+	}
+
+	entries, err := store.Commit(h.store, writes)
+	if errors.Is(err, store.ErrNotTransactional) {
+		writeError(w, http.StatusNotImplemented, err)
+		return
+	}
+	if err != nil {
+		writeStoreError(w, err)
+		return
+	}
+	out := make([]transactionResult, len(entries))
+	for i, e := range entries {
+		if writes[i].ID == "" {
+			h.trackHandle(r, e.ID)
+		}
+		out[i] = transactionResult{Step: steps[i], Handle: e.ID, Type: e.Type}
+	}
+	writeJSON(w, http.StatusOK, map[string][]transactionResult{"results": out})
+}
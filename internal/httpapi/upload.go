@@ -0,0 +1,153 @@
+package httpapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+)
+
+// uploadMemoryThreshold is the size above which uploads are spooled to a
+// temporary file instead of memory while the request is being received.
+const uploadMemoryThreshold = 4 << 20
+
+// spool holds an uploaded blob in memory or, past uploadMemoryThreshold, in
+// a temporary file. Close removes the file.
+type spool struct {
+	buf  bytes.Buffer
+	file *os.File
+	size int64
+}
+
+// spoolReader copies src into a spool, failing once more than limit bytes arrive.
+func spoolReader(src io.Reader, limit int64) (*spool, error) {
+	s := &spool{}
+	src = io.LimitReader(src, limit+1)
+	n, err := io.CopyN(&s.buf, src, uploadMemoryThreshold)
+	s.size = n
+	if err == io.EOF {
+		if s.size > limit {
+			return nil, &http.MaxBytesError{Limit: limit}
+		}
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if s.file, err = os.CreateTemp("", "tfhe-upload-*"); err != nil {
+		return nil, err
+	}
+	if _, err = s.buf.WriteTo(s.file); err == nil {
+		n, err = io.Copy(s.file, src)
+		s.size += n
+	}
+	if err == nil && s.size > limit {
+		err = &http.MaxBytesError{Limit: limit}
+	}
+	if err != nil {
+		s.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+// Bytes returns the upload's contents; the native API needs them in one buffer.
+func (s *spool) Bytes() ([]byte, error) {
+	if s.file == nil {
+		return s.buf.Bytes(), nil
+	}
+	data := make([]byte, s.size)
+	if _, err := s.file.ReadAt(data, 0); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Close releases the spool, removing its temporary file if any.
+func (s *spool) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	name := s.file.Name()
+	err := s.file.Close()
+	if rmErr := os.Remove(name); err == nil {
+		err = rmErr
+	}
+	s.file = nil
+	return err
+}
+
+// isUpload reports whether r carries a binary upload rather than a JSON body.
+func isUpload(r *http.Request) bool {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	return mediaType == "application/octet-stream" || mediaType == "multipart/form-data"
+}
+
+// readUpload reads a binary upload of at most limit bytes, sent either as an
+// application/octet-stream body (plain or chunked) with fields in the query
+// string, or as multipart/form-data with the blob in the file part named
+// part. Other form values are returned in fields. It writes the error
+// response itself and reports whether reading succeeded.
+func readUpload(w http.ResponseWriter, r *http.Request, part string, limit int64) (fields map[string]string, blob *spool, ok bool) {
+	fail := func(err error) (map[string]string, *spool, bool) {
+		if blob != nil {
+			blob.Close()
+		}
+		status := http.StatusBadRequest
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err)
+		return nil, nil, false
+	}
+
+	// Allow multipart framing and small form fields on top of the blob.
+	r.Body = http.MaxBytesReader(w, r.Body, limit+64<<10)
+	fields = make(map[string]string)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/octet-stream" {
+		for key, values := range r.URL.Query() {
+			fields[key] = values[0]
+		}
+		var err error
+		if blob, err = spoolReader(r.Body, limit); err != nil {
+			return fail(err)
+		}
+		return fields, blob, true
+	}
+
+	mr, err := r.MultipartReader()
+	if err != nil {
+		return fail(err)
+	}
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(err)
+		}
+		switch {
+		case p.FormName() == part && blob == nil:
+			if blob, err = spoolReader(p, limit); err != nil {
+				return fail(err)
+			}
+		case p.FileName() == "":
+			value, err := io.ReadAll(io.LimitReader(p, 4<<10))
+			if err != nil {
+				return fail(err)
+			}
+			fields[p.FormName()] = string(value)
+		}
+		p.Close()
+	}
+	if blob == nil {
+		return fail(fmt.Errorf("multipart body has no %q part", part))
+	}
+	return fields, blob, true
+}
@@ -0,0 +1,54 @@
+package httpapi
+
+import (
+	"net/http"
+	"time"
+
+	"tfhe-go/internal/quota"
+)
+
+// SetUsageTracker serves GET /usage from t; call it before Register.
+func (h *Handler) SetUsageTracker(t *quota.Tracker) {
+	h.usage = t
+}
+
+// usageReport reports the caller's usage and quotas in the current day and month.
+func (h *Handler) usageReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	tenant, _ := quota.Subject(r.Context(), r.RemoteAddr)
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, h.usage.Report(tenant, time.Now()))
+}
+
+// SetUsageTracker serves GET /admin/usage from t; call it before Register.
+func (h *AdminHandler) SetUsageTracker(t *quota.Tracker) {
+	h.usage = t
+}
+
+// usageReport reports every tenant's usage for chargeback: the current windows by
+// default, or the retained day or month named by ?period=YYYY-MM-DD|YYYY-MM.
+func (h *AdminHandler) usageReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if period := r.URL.Query().Get("period"); period != "" {
+		reports, err := h.usage.History(period)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"period": period, "tenants": reports})
+		return
+	}
+	now := time.Now()
+	tenants := h.usage.Tenants(now)
+	reports := make([]quota.Report, len(tenants))
+	for i, tenant := range tenants {
+		reports[i] = h.usage.Report(tenant, now)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"tenants": reports})
+}
@@ -0,0 +1,103 @@
+package httpapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	"tfhe-go/internal/tfhe"
+)
+
+const (
+	// APIVersion is the current major version of the HTTP API; its routes
+	// live under /v1.
+	APIVersion = "1"
+
+	// CiphertextFormatVersion identifies the encoding of ciphertexts in
+	// responses: base64 (standard alphabet) of tfhe-c's serialization. It is
+	// bumped whenever that encoding changes incompatibly.
+	CiphertextFormatVersion = 1
+
+	apiPrefix = "/v" + APIVersion
+
+	// versionHeader lets clients pin the API version they were written
+	// against; the server echoes the version it served.
+	versionHeader = "API-Version"
+)
+
+// handle registers fn under the versioned path and keeps the unversioned
+// path as a deprecated alias.
+func handle(mux routes, path string, fn http.HandlerFunc) {
+	var mount string
+	if rt, ok := mux.(router); ok {
+		mount = rt.prefix
+	}
+	mux.Handle(apiPrefix+path, negotiate(fn))
+	mux.Handle(path, deprecated(mount, negotiate(fn)))
+}
+
+// negotiate rejects requests pinned to an API version this server does not
+// serve, and reports the served version in the response.
+func negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if v := strings.TrimPrefix(r.Header.Get(versionHeader), "v"); v != "" && v != APIVersion {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported %s %q; this server serves version %s", versionHeader, v, APIVersion))
+			return
+		}
+		w.Header().Set(versionHeader, APIVersion)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// deprecated marks responses from a legacy unversioned path and points
+// clients at its successor, under the prefix mount the routes are mounted
+// at.
+func deprecated(mount string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "true")
+		w.Header().Set("Link", fmt.Sprintf("<%s%s%s>; rel=\"successor-version\"", mount, apiPrefix, strings.TrimPrefix(r.URL.Path, mount)))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeCiphertext writes a single-ciphertext response.
+func writeCiphertext(w http.ResponseWriter, ct string) {
+	writeJSON(w, http.StatusOK, ciphertextResponse{Ciphertext: ct, FormatVersion: CiphertextFormatVersion})
+}
+
+type ciphertextResponse struct {
+	Ciphertext    string `json:"ciphertext"`
+	FormatVersion int    `json:"format_version"`
+}
+
+type versionResponse struct {
+	tfhe.VersionInfo
+	APIVersion               string   `json:"api_version"`
+	CiphertextFormatVersion  int      `json:"ciphertext_format_version"`
+	CiphertextFormatVersions []int    `json:"ciphertext_format_versions"`
+	ParameterSets            []string `json:"parameter_sets"`
+}
+
+// version reports the build's library and format versions along with the
+// parameter sets of the registered key sets.
+func (h *Handler) version(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	params := []string{}
+	for _, ks := range h.keys.List() {
+		if !slices.Contains(params, ks.Params) {
+			params = append(params, ks.Params)
+		}
+	}
+	slices.Sort(params)
+	writeJSON(w, http.StatusOK, versionResponse{
+		VersionInfo:              tfhe.Version(),
+		APIVersion:               APIVersion,
+		CiphertextFormatVersion:  CiphertextFormatVersion,
+		CiphertextFormatVersions: ciphertextFormatVersions,
+		ParameterSets:            params,
+	})
+}
@@ -0,0 +1,196 @@
+package httpapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"tfhe-go/internal/bufpool"
+	"tfhe-go/internal/circuit"
+	"tfhe-go/internal/tfhe"
+)
+
+const (
+	// maxSessionHandles bounds the ciphertexts one WebSocket session holds.
+	maxSessionHandles = 1024
+	// wsIdleTimeout closes sessions that send nothing, not even a pong.
+	wsIdleTimeout = 5 * time.Minute
+	// wsPingInterval keeps idle sessions alive through proxies.
+	wsPingInterval = 30 * time.Second
+)
+
+var upgrader = websocket.Upgrader{ReadBufferSize: 64 << 10, WriteBufferSize: 64 << 10}
+
+// wsRequest is one client message. Action is upload, op, download or release.
+type wsRequest struct {
+	ID         string   `json:"id"`
+	Action     string   `json:"action"`
+	Handle     string   `json:"handle"`
+	Type       string   `json:"type"`
+	Ciphertext string   `json:"ciphertext"`
+	Op         string   `json:"op"`
+	Operands   []string `json:"operands"`
+	// Return asks for an op's result ciphertext in addition to its handle.
+	Return bool `json:"return"`
+}
+
+// wsResponse answers the request with the same ID.
+type wsResponse struct {
+	ID            string `json:"id,omitempty"`
+	Handle        string `json:"handle,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Ciphertext    string `json:"ciphertext,omitempty"`
+	FormatVersion int    `json:"format_version,omitempty"`
+	Error         string `json:"error,omitempty"`
+	Status        int    `json:"status,omitempty"`
+}
+
+// wsSession holds the ciphertexts uploaded or computed during one connection.
+type wsSession struct {
+	ops     evalOps
+	handles map[string]circuit.Value
+	seq     int
+}
+
+// ws handles GET /ws: a session in which the client uploads operands once
+// and then streams operations over session-local handles. Requests are
+// processed in order and each is answered as soon as it completes; handles
+// are discarded when the connection closes.
+func (h *Handler) ws(w http.ResponseWriter, r *http.Request) {
+	ks, ok := h.keySet(w, r)
+	if !ok {
+		return
+	}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade has replied
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(context.WithoutCancel(r.Context()))
+	defer cancel()
+	conn.SetReadLimit(int64(tfhe.CurrentLimits().MaxCiphertext())*4/3 + 64<<10)
+	_ = conn.SetReadDeadline(time.Now().Add(wsIdleTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(wsIdleTimeout))
+	})
+	go func() {
+		ticker := time.NewTicker(wsPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(10*time.Second)); err != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	s := &wsSession{ops: evalOps{h: h, ks: ks}, handles: make(map[string]circuit.Value)}
+	for {
+		var req wsRequest
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(wsIdleTimeout))
+		var resp wsResponse
+		if err := json.Unmarshal(data, &req); err != nil {
+			resp = wsResponse{Error: err.Error(), Status: http.StatusBadRequest}
+		} else {
+			resp = s.handle(ctx, req)
+			resp.ID = req.ID
+		}
+		if err := conn.WriteJSON(resp); err != nil {
+			return
+		}
+	}
+}
+
+func (s *wsSession) handle(ctx context.Context, req wsRequest) wsResponse {
+	switch req.Action {
+	case "upload":
+		if !slices.Contains(evalTypes(), req.Type) {
+			return wsError(http.StatusBadRequest, fmt.Errorf("unsupported type %q", req.Type))
+		}
+		raw, err := base64.StdEncoding.DecodeString(req.Ciphertext)
+		if err != nil {
+			return wsError(http.StatusBadRequest, err)
+		}
+		if err := tfhe.CheckSerialized(raw, maxCiphertext(req.Type)); err != nil {
+			return wsError(statusFor(err), err)
+		}
+		return s.store(req.Handle, circuit.Value{Type: req.Type, Data: raw}, false)
+	case "op":
+		args := make([]circuit.Value, len(req.Operands))
+		for i, handle := range req.Operands {
+			v, ok := s.handles[handle]
+			if !ok {
+				return wsError(http.StatusNotFound, fmt.Errorf("unknown handle %q", handle))
+			}
+			args[i] = v
+		}
+		out, err := s.ops.Apply(ctx, req.Op, args)
+		if err != nil {
+			status := statusFor(err)
+			if errors.Is(err, circuit.ErrInvalid) {
+				status = http.StatusBadRequest
+			}
+			return wsError(status, err)
+		}
+		return s.store(req.Handle, out, req.Return)
+	case "download":
+		v, ok := s.handles[req.Handle]
+		if !ok {
+			return wsError(http.StatusNotFound, fmt.Errorf("unknown handle %q", req.Handle))
+		}
+		return ciphertextMessage(req.Handle, v)
+	case "release":
+		if _, ok := s.handles[req.Handle]; !ok {
+			return wsError(http.StatusNotFound, fmt.Errorf("unknown handle %q", req.Handle))
+		}
+		delete(s.handles, req.Handle)
+		return wsResponse{Handle: req.Handle}
+	}
+	return wsError(http.StatusBadRequest, fmt.Errorf("unknown action %q", req.Action))
+}
+
+// store keeps v under handle, generating one when the client gave none.
+func (s *wsSession) store(handle string, v circuit.Value, withCiphertext bool) wsResponse {
+	if _, exists := s.handles[handle]; !exists && len(s.handles) >= maxSessionHandles {
+		return wsError(http.StatusRequestEntityTooLarge, fmt.Errorf("session holds %d handles, release some first", maxSessionHandles))
+	}
+	if handle == "" {
+		s.seq++
+		handle = "%" + strconv.Itoa(s.seq)
+	}
+	s.handles[handle] = v
+	if withCiphertext {
+		return ciphertextMessage(handle, v)
+	}
+	return wsResponse{Handle: handle, Type: v.Type}
+}
+
+func ciphertextMessage(handle string, v circuit.Value) wsResponse {
+	return wsResponse{
+		Handle:        handle,
+		Type:          v.Type,
+		Ciphertext:    bufpool.EncodeBase64(v.Data),
+		FormatVersion: CiphertextFormatVersion,
+	}
+}
+
+func wsError(status int, err error) wsResponse {
+	return wsResponse{Error: err.Error(), Status: status}
+}
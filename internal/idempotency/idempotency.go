@@ -0,0 +1,267 @@
+// Package idempotency replays the stored response of a mutating request when
+// a client retries it with the same Idempotency-Key.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"tfhe-go/internal/apierror"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/ratelimit"
+)
+
+// Header is the request header carrying the client's idempotency key.
+const Header = "Idempotency-Key"
+
+// ReplayedHeader marks a response served from the cache.
+const ReplayedHeader = "Idempotent-Replayed"
+
+// maxKeyLen bounds the header value; keys are typically UUIDs.
+const maxKeyLen = 255
+
+// Options configures a Cache.
+type Options struct {
+	// TTL is how long a response is replayed for its key.
+	TTL time.Duration
+	// MaxBodyBytes is the largest response body cached; larger responses are
+	// not cached, so a retry runs the request again. Defaults to 8 MiB.
+	MaxBodyBytes int
+	// MaxTotalBytes bounds the bodies cached at once; while full, new
+	// responses are not cached. Defaults to 256 MiB.
+	MaxTotalBytes int
+	// MaxRequestBytes returns the largest request body read, whether by the
+	// handler or to fingerprint the request; larger ones get 413. It is
+	// called per request so it can follow reloaded limits. Defaults to
+	// 64 MiB.
+	MaxRequestBytes func() int64
+}
+
+// Cache remembers responses by caller and key.
+type Cache struct {
+	opts Options
+
+	mu        sync.Mutex
+	entries   map[string]*entry
+	total     int
+	lastSweep time.Time
+}
+
+type entry struct {
+	fingerprint [sha256.Size]byte
+	done        bool
+	status      int
+	header      http.Header
+	body        []byte
+	expires     time.Time
+}
+
+// New returns an empty cache.
+func New(opts Options) *Cache {
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 8 << 20
+	}
+	if opts.MaxTotalBytes <= 0 {
+		opts.MaxTotalBytes = 256 << 20
+	}
+	if opts.MaxRequestBytes == nil {
+		opts.MaxRequestBytes = func() int64 { return 64 << 20 }
+	}
+	return &Cache{opts: opts, entries: make(map[string]*entry)}
+}
+
+// Middleware applies to POST, PUT and PATCH requests carrying an
+// Idempotency-Key. The first request runs and its response, unless a 5xx, is
+// stored for the TTL; retries with the same key and an identical method, path
+// and body get the stored response. A retry while the first is still running
+// gets 409, and reusing a key for a different request gets 422. Bodies are
+// read up to MaxRequestBytes; a retry with a longer one gets 413. Keys are
+// scoped to the caller, so this must run inside authentication.
+func (c *Cache) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(Header)
+		if key == "" || auth.IsPublic(r.URL.Path) || !mutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxKeyLen {
+			writeError(w, http.StatusBadRequest, "Idempotency-Key is too long")
+			return
+		}
+		scoped := ratelimit.ClientKey(r.Context(), r.RemoteAddr) + "\x00" + key
+
+		c.mu.Lock()
+		now := time.Now()
+		c.sweep(now)
+		e, ok := c.entries[scoped]
+		if ok {
+			c.mu.Unlock()
+			c.replay(w, r, e)
+			return
+		}
+		e = &entry{}
+		c.entries[scoped] = e
+		c.mu.Unlock()
+
+		h := sha256.New()
+		writeRequestLine(h, r)
+		body := http.MaxBytesReader(w, r.Body, c.opts.MaxRequestBytes())
+		r.Body = teeBody{io.TeeReader(body, h), body}
+		rec := &recorder{ResponseWriter: w, status: http.StatusOK, limit: c.opts.MaxBodyBytes}
+		defer func() {
+			if p := recover(); p != nil {
+				c.forget(scoped)
+				panic(p)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+		// Hash whatever the handler left unread so retries compare whole
+		// bodies. A body too long to hash leaves nothing to compare with.
+		if _, err := io.Copy(io.Discard, r.Body); err != nil {
+			c.forget(scoped)
+			if !rec.wroteHeader {
+				writeBodyError(w, err)
+			}
+			return
+		}
+
+		if rec.status >= http.StatusInternalServerError || rec.overflow {
+			c.forget(scoped)
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if c.total+len(rec.body) > c.opts.MaxTotalBytes {
+			delete(c.entries, scoped)
+			return
+		}
+		copy(e.fingerprint[:], h.Sum(nil))
+		e.done = true
+		e.status = rec.status
+		e.header = w.Header().Clone()
+		// Outer middleware (compression) encodes each response afresh.
+		e.header.Del("Content-Encoding")
+		e.header.Del("Content-Length")
+		e.body = rec.body
+		e.expires = time.Now().Add(c.opts.TTL)
+		c.total += len(e.body)
+	})
+}
+
+// replay serves e to a retry once it is complete and the retry matches.
+func (c *Cache) replay(w http.ResponseWriter, r *http.Request, e *entry) {
+	c.mu.Lock()
+	done := e.done
+	c.mu.Unlock()
+	if !done {
+		writeError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+		return
+	}
+	h := sha256.New()
+	writeRequestLine(h, r)
+	if _, err := io.Copy(h, http.MaxBytesReader(w, r.Body, c.opts.MaxRequestBytes())); err != nil {
+		writeBodyError(w, err)
+		return
+	}
+	if !bytes.Equal(h.Sum(nil), e.fingerprint[:]) {
+		writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used for a different request")
+		return
+	}
+	hdr := w.Header()
+	for k, v := range e.header {
+		hdr[k] = v
+	}
+	hdr.Set(ReplayedHeader, "true")
+	w.WriteHeader(e.status)
+	_, _ = w.Write(e.body)
+}
+
+func (c *Cache) forget(key string) {
+	c.mu.Lock()
+	delete(c.entries, key)
+	c.mu.Unlock()
+}
+
+// sweep drops expired responses at most once a minute. c.mu must be held.
+func (c *Cache) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < time.Minute {
+		return
+	}
+	c.lastSweep = now
+	for key, e := range c.entries {
+		if e.done && now.After(e.expires) {
+			c.total -= len(e.body)
+			delete(c.entries, key)
+		}
+	}
+}
+
+func mutating(method string) bool {
+	return method == http.MethodPost || method == http.MethodPut || method == http.MethodPatch
+}
+
+func writeRequestLine(h hash.Hash, r *http.Request) {
+	_, _ = io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+}
+
+type teeBody struct {
+	io.Reader
+	body io.Closer
+}
+
+func (t teeBody) Close() error { return t.body.Close() }
+
+// recorder copies the response body as it is written, up to limit bytes.
+type recorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        []byte
+	limit       int
+	overflow    bool
+}
+
+func (rec *recorder) WriteHeader(status int) {
+	if !rec.wroteHeader {
+		rec.wroteHeader = true
+		rec.status = status
+	}
+	rec.ResponseWriter.WriteHeader(status)
+}
+
+func (rec *recorder) Write(p []byte) (int, error) {
+	rec.wroteHeader = true
+	if !rec.overflow {
+		if len(rec.body)+len(p) > rec.limit {
+			rec.overflow, rec.body = true, nil
+		} else {
+			rec.body = append(rec.body, p...)
+		}
+	}
+	return rec.ResponseWriter.Write(p)
+}
+
+func (rec *recorder) Flush() { _ = http.NewResponseController(rec.ResponseWriter).Flush() }
+
+func (rec *recorder) Unwrap() http.ResponseWriter { return rec.ResponseWriter }
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	apierror.Write(w, status, apierror.CodeFor(status), msg)
+}
+
+// writeBodyError answers a request whose body could not be read: 413 when
+// it ran past the limit, 400 otherwise.
+func writeBodyError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+		return
+	}
+	writeError(w, http.StatusBadRequest, err.Error())
+}
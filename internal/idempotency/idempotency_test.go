@@ -0,0 +1,71 @@
+package idempotency
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testLimit = 16
+
+func newTestCache() *Cache {
+	return New(Options{TTL: time.Minute, MaxRequestBytes: func() int64 { return testLimit }})
+}
+
+func post(h http.Handler, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/op", strings.NewReader(body))
+	req.Header.Set(Header, "key-1")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestMiddlewareReplay(t *testing.T) {
+	runs := 0
+	h := newTestCache().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs++
+		_, _ = io.Copy(w, r.Body)
+	}))
+	for _, tc := range []struct {
+		name, body string
+		status     int
+		replayed   bool
+	}{
+		{name: "first", body: "hello", status: http.StatusOK},
+		{name: "retry", body: "hello", status: http.StatusOK, replayed: true},
+		{name: "different body", body: "other", status: http.StatusUnprocessableEntity},
+		{name: "retry too long", body: strings.Repeat("x", testLimit+1), status: http.StatusRequestEntityTooLarge},
+	} {
+		rec := post(h, tc.body)
+		if rec.Code != tc.status {
+			t.Fatalf("%s: status %d, want %d", tc.name, rec.Code, tc.status)
+		}
+		if got := rec.Header().Get(ReplayedHeader) == "true"; got != tc.replayed {
+			t.Fatalf("%s: replayed %v, want %v", tc.name, got, tc.replayed)
+		}
+	}
+	if runs != 1 {
+		t.Fatalf("handler ran %d times, want 1", runs)
+	}
+}
+
+// A body the handler leaves unread is hashed only up to the limit; past it
+// the request gets 413 and nothing is cached.
+func TestMiddlewareUnreadBody(t *testing.T) {
+	runs := 0
+	h := newTestCache().Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		runs++
+	}))
+	long := strings.Repeat("x", testLimit+1)
+	for range 2 {
+		if rec := post(h, long); rec.Code != http.StatusRequestEntityTooLarge {
+			t.Fatalf("status %d, want 413", rec.Code)
+		}
+	}
+	if runs != 2 {
+		t.Fatalf("handler ran %d times, want 2 with nothing cached", runs)
+	}
+}
@@ -0,0 +1,130 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// IntegerTypes lists the integer types a key set computes on, narrowest
+// first.
+var IntegerTypes = []string{"uint2", "uint4", "uint8", "uint16", "uint32", "uint64"}
+
+// Description reports a key set's parameters, key sizes and supported
+// operations, so clients can check compatibility before uploading
+// ciphertexts.
+type Description struct {
+	ID        string    `json:"id"`
+	Params    string    `json:"parameter_set"`
+	CreatedAt time.Time `json:"created_at"`
+	// Generation counts the key rotations; ciphertexts of another
+	// generation no longer decrypt.
+	Generation uint64 `json:"generation"`
+	// Backend and SerializationFormat are those of tfhe.Version.
+	Backend             string       `json:"backend"`
+	SerializationFormat string       `json:"serialization_format"`
+	Sizes               KeySizes     `json:"sizes"`
+	Capabilities        Capabilities `json:"capabilities"`
+}
+
+// KeySizes are serialized key sizes in bytes. Sizes the backend cannot
+// serialize, and public key sizes when the client key is withheld, are
+// zero and left out. Client key sizes are not reported.
+type KeySizes struct {
+	BooleanServerKey int `json:"boolean_server_key,omitempty"`
+	IntegerServerKey int `json:"integer_server_key,omitempty"`
+	PublicKey        int `json:"public_key,omitempty"`
+	CompactPublicKey int `json:"compact_public_key,omitempty"`
+}
+
+// Capabilities lists what the server can do with a key set's ciphertexts.
+type Capabilities struct {
+	// Boolean gates evaluate on boolean ciphertexts.
+	Boolean bool `json:"boolean"`
+	// Integer lists the integer types operations accept; empty on the
+	// purego backend.
+	Integer []string `json:"integer"`
+	// ProgrammableBootstrapping reports integer operations evaluated with
+	// programmable bootstrapping, such as comparisons and lookup tables.
+	ProgrammableBootstrapping bool `json:"programmable_bootstrapping"`
+	// CompressedCiphertexts reports operations accepting compressed
+	// ciphertexts, which are only recognized by inspection so far.
+	CompressedCiphertexts bool `json:"compressed_ciphertexts"`
+	// PublicKeyEncryption reports the public and compact public keys being
+	// available for client-side encryption.
+	PublicKeyEncryption bool `json:"public_key_encryption"`
+	// ServerEncryption reports the server holding the client key, and so
+	// encrypting and decrypting on request.
+	ServerEncryption bool `json:"server_encryption"`
+}
+
+// Describe reports the key set's parameters, key sizes and capabilities.
+// The first call serializes the server keys to measure them.
+func (ks *KeySet) Describe(ctx context.Context) (Description, error) {
+	d := Description{
+		ID:                  ks.ID,
+		Params:              ks.Params,
+		CreatedAt:           ks.CreatedAt,
+		Backend:             tfhe.Backend,
+		SerializationFormat: tfhe.Version().SerializationFormat,
+		Capabilities:        Capabilities{Integer: []string{}},
+	}
+	var err error
+	if d.Sizes, err = ks.serverKeySizes(); err != nil {
+		return Description{}, err
+	}
+	if ks.Boolean != nil {
+		d.Capabilities.Boolean = true
+		d.Capabilities.ServerEncryption = !ks.Boolean.ClientKeyWithheld()
+	}
+	if ks.Uint8 == nil {
+		return d, nil
+	}
+	d.Generation = ks.Uint8.KeyGeneration()
+	if tfhe.Backend == "native" {
+		d.Capabilities.Integer = slices.Clone(IntegerTypes)
+		d.Capabilities.ProgrammableBootstrapping = true
+	}
+	if ks.Uint8.ClientKeyWithheld() {
+		d.Capabilities.ServerEncryption = false
+		return d, nil
+	}
+	public, err := ks.Uint8.PublicKey(ctx)
+	if err != nil && !errors.Is(err, tfhe.ErrUnsupported) {
+		return Description{}, err
+	}
+	compact, err := ks.Uint8.CompactPublicKey(ctx)
+	if err != nil && !errors.Is(err, tfhe.ErrUnsupported) {
+		return Description{}, err
+	}
+	d.Sizes.PublicKey, d.Sizes.CompactPublicKey = len(public.Data), len(compact.Data)
+	d.Capabilities.PublicKeyEncryption = d.Sizes.PublicKey > 0
+	return d, nil
+}
+
+// serverKeySizes measures the server keys on first use, leaving out those
+// the backend cannot serialize.
+func (ks *KeySet) serverKeySizes() (KeySizes, error) {
+	ks.sizesMu.Lock()
+	defer ks.sizesMu.Unlock()
+	if ks.sizes != nil {
+		return *ks.sizes, nil
+	}
+	var sizes KeySizes
+	var err error
+	if ks.Boolean != nil {
+		if sizes.BooleanServerKey, err = ks.Boolean.ServerKeySize(); err != nil && !errors.Is(err, tfhe.ErrUnsupported) {
+			return KeySizes{}, err
+		}
+	}
+	if ks.Uint8 != nil {
+		if sizes.IntegerServerKey, err = ks.Uint8.ServerKeySize(); err != nil && !errors.Is(err, tfhe.ErrUnsupported) {
+			return KeySizes{}, err
+		}
+	}
+	ks.sizes = &sizes
+	return sizes, nil
+}
@@ -0,0 +1,154 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// ErrKeySetExists is returned by Store.CreateKeySet when the ID is taken.
+var ErrKeySetExists = errors.New("key set already exists")
+
+// Record is the persisted form of a key set.
+type Record struct {
+	ID        string
+	Params    string
+	CreatedAt time.Time
+	Boolean   tfhe.KeyPair
+	Uint8     tfhe.KeyPair
+}
+
+// Store persists key sets and revocations so a registry survives restarts
+// and can be shared by several instances.
+type Store interface {
+	// CreateKeySet inserts rec, or returns ErrKeySetExists.
+	CreateKeySet(ctx context.Context, rec Record) error
+	// UpdateKeySet replaces the keys of an existing record, e.g. after
+	// rotation.
+	UpdateKeySet(ctx context.Context, rec Record) error
+	// RevokeKeySet marks id revoked and discards its keys.
+	RevokeKeySet(ctx context.Context, id string, at time.Time) error
+	// LoadKeySets returns the active records and when each revoked ID was
+	// revoked.
+	LoadKeySets(ctx context.Context) ([]Record, map[string]time.Time, error)
+}
+
+// Snapshot serializes ks for a Store.
+func Snapshot(ks *KeySet) (Record, error) {
+	rec := Record{ID: ks.ID, Params: ks.Params, CreatedAt: ks.CreatedAt}
+	var err error
+	if rec.Boolean, err = ks.Boolean.ExportKeys(); err != nil {
+		return Record{}, fmt.Errorf("export boolean keys: %w", err)
+	}
+	if rec.Uint8, err = ks.Uint8.ExportKeys(); err != nil {
+		return Record{}, fmt.Errorf("export uint8 keys: %w", err)
+	}
+	return rec, nil
+}
+
+// open rebuilds the key set rec was taken from.
+func (rec Record) open() (*KeySet, error) {
+	booleanService, err := tfhe.NewBooleanServiceFromKeys(rec.Boolean)
+	if err != nil {
+		return nil, fmt.Errorf("key set %q: load boolean keys: %w", rec.ID, err)
+	}
+	uint8Service, err := tfhe.NewUint8ServiceFromKeys(rec.Uint8)
+	if err != nil {
+		_ = booleanService.Close()
+		return nil, fmt.Errorf("key set %q: load uint8 keys: %w", rec.ID, err)
+	}
+	ks := &KeySet{ID: rec.ID, Params: rec.Params, CreatedAt: rec.CreatedAt, Boolean: booleanService, Uint8: uint8Service}
+	ks.fill()
+	return ks, nil
+}
+
+// Load returns the registry persisted in st. When st holds no default key
+// set, generate creates one and it is stored; if another instance stored
+// one first, the generated set is discarded in favour of it. Registrations,
+// revocations and Save calls on the returned registry are written to st.
+func Load(ctx context.Context, st Store, generate func() (*KeySet, error)) (*Registry, error) {
+	recs, revoked, err := st.LoadKeySets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var created *KeySet
+	if findRecord(recs, DefaultID) == nil {
+		ks, err := generate()
+		if err != nil {
+			return nil, err
+		}
+		ks.ID = DefaultID
+		ks.fill()
+		rec, err := Snapshot(ks)
+		if err == nil {
+			err = st.CreateKeySet(ctx, rec)
+		}
+		switch {
+		case err == nil:
+			created = ks
+		case errors.Is(err, ErrKeySetExists):
+			_ = ks.Close()
+			if recs, revoked, err = st.LoadKeySets(ctx); err != nil {
+				return nil, err
+			}
+		default:
+			_ = ks.Close()
+			return nil, err
+		}
+	}
+
+	r := &Registry{sets: make(map[string]*KeySet), revoked: revoked, defaultID: DefaultID, store: st}
+	if r.revoked == nil {
+		r.revoked = make(map[string]time.Time)
+	}
+	if created != nil {
+		r.sets[DefaultID] = created
+	}
+	for _, rec := range recs {
+		if r.sets[rec.ID] != nil {
+			continue
+		}
+		ks, err := rec.open()
+		if err != nil {
+			for _, loaded := range r.sets {
+				_ = loaded.Close()
+			}
+			return nil, err
+		}
+		r.sets[ks.ID] = ks
+	}
+	if r.sets[DefaultID] == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeySet, DefaultID)
+	}
+	return r, nil
+}
+
+func findRecord(recs []Record, id string) *Record {
+	for i := range recs {
+		if recs[i].ID == id {
+			return &recs[i]
+		}
+	}
+	return nil
+}
+
+// Save writes the current keys of the key set id to the registry's store,
+// e.g. after rotation. It does nothing for registries without a store and
+// for ephemeral key sets.
+func (r *Registry) Save(ctx context.Context, id string) error {
+	if r.store == nil {
+		return nil
+	}
+	ks, err := r.Get(id)
+	if err != nil || ks.Ephemeral {
+		return err
+	}
+	rec, err := Snapshot(ks)
+	if err != nil {
+		return err
+	}
+	return r.store.UpdateKeySet(ctx, rec)
+}
@@ -0,0 +1,307 @@
+package keys
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// ErrUnknownKeySet is returned when a key set ID is not registered.
+var ErrUnknownKeySet = errors.New("unknown key set")
+
+// ErrRevokedKeySet is returned when a key set ID has been revoked.
+var ErrRevokedKeySet = errors.New("key set revoked")
+
+// ErrDefaultKeySet is returned when revoking the default key set.
+var ErrDefaultKeySet = errors.New("the default key set cannot be revoked")
+
+// DefaultID names the key set generated at startup.
+const DefaultID = "default"
+
+// DefaultParams names the parameter set of keys generated with the library
+// defaults (boolean DEFAULT_PARAMETERS, integer ConfigBuilder defaults).
+const DefaultParams = "default"
+
+// KeySet bundles the services computing under one set of keys.
+type KeySet struct {
+	ID      string
+	Boolean *tfhe.BooleanService
+	Uint8   *tfhe.Uint8Service
+	// Uint2, Uint4, Uint16, Uint32 and Uint64 share Uint8's keys; the
+	// registry derives them from Uint8 when left nil.
+	Uint2     *tfhe.IntService
+	Uint4     *tfhe.IntService
+	Uint16    *tfhe.IntService
+	Uint32    *tfhe.IntService
+	Uint64    *tfhe.IntService
+	CreatedAt time.Time
+	// Params names the parameter set the keys were generated with.
+	Params string
+	// Ephemeral key sets, such as a session's, are never persisted, and
+	// their IDs are forgotten rather than refused once revoked.
+	Ephemeral bool
+
+	uses     atomic.Int64
+	lastUsed atomic.Int64 // unix nanoseconds
+
+	// Server key sizes depend only on the parameters, so rotation keeps
+	// them and they are measured once.
+	sizesMu sync.Mutex
+	sizes   *KeySizes
+}
+
+// Info describes a key set for operators.
+type Info struct {
+	ID         string     `json:"id"`
+	Default    bool       `json:"default"`
+	Params     string     `json:"parameter_set"`
+	CreatedAt  time.Time  `json:"created_at"`
+	Requests   int64      `json:"requests"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Uses returns how many times the key set has been selected for a request.
+func (ks *KeySet) Uses() int64 { return ks.uses.Load() }
+
+// LastUsed returns when the key set was last selected, or the zero time.
+func (ks *KeySet) LastUsed() time.Time {
+	if n := ks.lastUsed.Load(); n != 0 {
+		return time.Unix(0, n).UTC()
+	}
+	return time.Time{}
+}
+
+func (ks *KeySet) touch() {
+	ks.uses.Add(1)
+	ks.lastUsed.Store(time.Now().UnixNano())
+}
+
+// Close releases the key set's native keys. The other integer services share
+// Uint8's keys and need no separate release.
+func (ks *KeySet) Close() error {
+	var err error
+	if ks.Boolean != nil {
+		err = ks.Boolean.Close()
+	}
+	if ks.Uint8 != nil {
+		if cerr := ks.Uint8.Close(); err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// Int returns the service for bits-wide unsigned integers (2, 4, 16, 32 or
+// 64).
+func (ks *KeySet) Int(bits int) *tfhe.IntService {
+	switch bits {
+	case 2:
+		return ks.Uint2
+	case 4:
+		return ks.Uint4
+	case 16:
+		return ks.Uint16
+	case 32:
+		return ks.Uint32
+	case 64:
+		return ks.Uint64
+	}
+	return nil
+}
+
+// fill defaults the creation time and derives the other integer services.
+func (ks *KeySet) fill() {
+	if ks.CreatedAt.IsZero() {
+		ks.CreatedAt = time.Now().UTC()
+	}
+	if ks.Params == "" {
+		ks.Params = DefaultParams
+	}
+	if ks.Uint8 == nil {
+		return
+	}
+	// NewIntService only fails for unsupported widths.
+	if ks.Uint2 == nil {
+		ks.Uint2, _ = tfhe.NewIntService(ks.Uint8, 2)
+	}
+	if ks.Uint4 == nil {
+		ks.Uint4, _ = tfhe.NewIntService(ks.Uint8, 4)
+	}
+	if ks.Uint16 == nil {
+		ks.Uint16, _ = tfhe.NewIntService(ks.Uint8, 16)
+	}
+	if ks.Uint32 == nil {
+		ks.Uint32, _ = tfhe.NewIntService(ks.Uint8, 32)
+	}
+	if ks.Uint64 == nil {
+		ks.Uint64, _ = tfhe.NewIntService(ks.Uint8, 64)
+	}
+}
+
+// Registry maps key set IDs to their services. Callers select a key set by
+// ID (e.g. from a token claim); an empty ID selects the default set.
+type Registry struct {
+	mu        sync.RWMutex
+	sets      map[string]*KeySet
+	revoked   map[string]time.Time
+	defaultID string
+	store     Store // nil keeps key sets in memory only
+}
+
+// NewRegistry returns a registry whose default key set is defaults.
+func NewRegistry(defaults *KeySet) *Registry {
+	if defaults.ID == "" {
+		defaults.ID = DefaultID
+	}
+	defaults.fill()
+	return &Registry{
+		sets:      map[string]*KeySet{defaults.ID: defaults},
+		revoked:   make(map[string]time.Time),
+		defaultID: defaults.ID,
+	}
+}
+
+// Register adds ks under its ID.
+func (r *Registry) Register(ks *KeySet) error {
+	if ks.ID == "" {
+		return errors.New("key set id is required")
+	}
+	ks.fill()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.sets[ks.ID]; ok {
+		return fmt.Errorf("key set %q already registered", ks.ID)
+	}
+	if _, ok := r.revoked[ks.ID]; ok {
+		return fmt.Errorf("%w: %q cannot be registered again", ErrRevokedKeySet, ks.ID)
+	}
+	if r.store != nil && !ks.Ephemeral {
+		rec, err := Snapshot(ks)
+		if err != nil {
+			return err
+		}
+		if err := r.store.CreateKeySet(context.Background(), rec); err != nil {
+			return fmt.Errorf("persist key set %q: %w", ks.ID, err)
+		}
+	}
+	r.sets[ks.ID] = ks
+	return nil
+}
+
+// Revoke unregisters the key set id and releases its keys once in-flight
+// operations finish. The ID is refused from then on, unless the set is
+// ephemeral, and the default set cannot be revoked.
+func (r *Registry) Revoke(id string) error {
+	r.mu.Lock()
+	if id == r.defaultID {
+		r.mu.Unlock()
+		return fmt.Errorf("%w: %q", ErrDefaultKeySet, id)
+	}
+	ks, ok := r.sets[id]
+	if !ok {
+		r.mu.Unlock()
+		if _, revoked := r.revoked[id]; revoked {
+			return fmt.Errorf("%w: %q", ErrRevokedKeySet, id)
+		}
+		return fmt.Errorf("%w: %q", ErrUnknownKeySet, id)
+	}
+	if !ks.Ephemeral {
+		now := time.Now().UTC()
+		if r.store != nil {
+			if err := r.store.RevokeKeySet(context.Background(), id, now); err != nil {
+				r.mu.Unlock()
+				return fmt.Errorf("persist revocation of %q: %w", id, err)
+			}
+		}
+		r.revoked[id] = now
+	}
+	delete(r.sets, id)
+	r.mu.Unlock()
+	return ks.Close()
+}
+
+// Get returns the key set registered under id.
+func (r *Registry) Get(id string) (*KeySet, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ks, ok := r.sets[id]
+	if !ok {
+		if _, revoked := r.revoked[id]; revoked {
+			return nil, fmt.Errorf("%w: %q", ErrRevokedKeySet, id)
+		}
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKeySet, id)
+	}
+	return ks, nil
+}
+
+// Default returns the default key set.
+func (r *Registry) Default() *KeySet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.sets[r.defaultID]
+}
+
+// Select returns the key set for id, or the default set when id is empty,
+// and counts the selection as a use of that set.
+func (r *Registry) Select(id string) (*KeySet, error) {
+	ks := r.Default()
+	if id != "" {
+		var err error
+		if ks, err = r.Get(id); err != nil {
+			return nil, err
+		}
+	}
+	ks.touch()
+	return ks, nil
+}
+
+// List returns every registered key set ordered by ID.
+func (r *Registry) List() []*KeySet {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]*KeySet, 0, len(r.sets))
+	for _, ks := range r.sets {
+		out = append(out, ks)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// Info describes the key set id.
+func (r *Registry) Info(id string) (Info, error) {
+	ks, err := r.Get(id)
+	if err != nil {
+		return Info{}, err
+	}
+	return r.info(ks), nil
+}
+
+// Infos describes every registered key set ordered by ID.
+func (r *Registry) Infos() []Info {
+	sets := r.List()
+	out := make([]Info, len(sets))
+	for i, ks := range sets {
+		out[i] = r.info(ks)
+	}
+	return out
+}
+
+func (r *Registry) info(ks *KeySet) Info {
+	info := Info{
+		ID:        ks.ID,
+		Default:   ks.ID == r.defaultID,
+		Params:    ks.Params,
+		CreatedAt: ks.CreatedAt,
+		Requests:  ks.Uses(),
+	}
+	if t := ks.LastUsed(); !t.IsZero() {
+		info.LastUsedAt = &t
+	}
+	return info
+}
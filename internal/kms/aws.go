@@ -0,0 +1,78 @@
+package kms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// encryptionContext binds wrapped data keys to this use; KMS refuses to
+// unwrap them under any other context.
+var encryptionContext = map[string]string{"purpose": "tfhe-go/keys-at-rest"}
+
+// AWS is a KEK backed by an AWS KMS symmetric key. Credentials and region
+// come from the SDK's default chain (AWS_* variables, shared config, or the
+// instance or task role).
+type AWS struct {
+	client *kms.Client
+	keyID  string
+}
+
+// NewAWS returns a KEK wrapping under keyID, a key ID, ARN, alias name
+// ("alias/tfhe") or alias ARN. With an alias, pointing it at a new key
+// rotates the KEK: data keys are re-wrapped under the new key as they are
+// next loaded. KMS's own automatic rotation keeps the key ID and needs no
+// re-wrapping.
+func NewAWS(ctx context.Context, keyID string) (*AWS, error) {
+	if keyID == "" {
+		return nil, errors.New("aws kms: key id is required")
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("aws kms: %w", err)
+	}
+	k := &AWS{client: kms.NewFromConfig(cfg), keyID: keyID}
+	if _, err := k.KeyID(ctx); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Wrap encrypts dek under the configured key.
+func (k *AWS) Wrap(ctx context.Context, dek []byte) ([]byte, error) {
+	out, err := k.client.Encrypt(ctx, &kms.EncryptInput{
+		KeyId:             aws.String(k.keyID),
+		Plaintext:         dek,
+		EncryptionContext: encryptionContext,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("aws kms: encrypt: %w", err)
+	}
+	return out.CiphertextBlob, nil
+}
+
+// Unwrap decrypts a data key wrapped by Wrap, under whichever key wrapped
+// it, and returns that key's ARN.
+func (k *AWS) Unwrap(ctx context.Context, wrapped []byte) ([]byte, string, error) {
+	out, err := k.client.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob:    wrapped,
+		EncryptionContext: encryptionContext,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("aws kms: decrypt: %w", err)
+	}
+	return out.Plaintext, aws.ToString(out.KeyId), nil
+}
+
+// KeyID resolves the configured key, following aliases, to its ARN.
+func (k *AWS) KeyID(ctx context.Context) (string, error) {
+	out, err := k.client.DescribeKey(ctx, &kms.DescribeKeyInput{KeyId: aws.String(k.keyID)})
+	if err != nil {
+		return "", fmt.Errorf("aws kms: describe %s: %w", k.keyID, err)
+	}
+	return aws.ToString(out.KeyMetadata.Arn), nil
+}
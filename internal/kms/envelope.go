@@ -0,0 +1,256 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"tfhe-go/internal/keys"
+)
+
+// sealedMagic starts every sealed key; anything else is plaintext, written
+// before envelope encryption was enabled or by whoever can write the store.
+var sealedMagic = []byte("TFE1")
+
+// ErrUnsealed reports a record holding plaintext keys while plaintext
+// migration is off.
+var ErrUnsealed = errors.New("key is not sealed")
+
+// Envelope is a keys.Store encrypting every key of a record with AES-256-GCM
+// under a fresh data key, wrapped by a KEK and stored alongside, before
+// passing the record to an inner store. Loading re-wraps records whose data
+// key was wrapped under a key other than the KEK's current one. Records
+// still stored in plaintext fail with ErrUnsealed, since anyone able to
+// write the store could have planted them, unless plaintext migration is
+// on; they are then sealed and each is logged.
+type Envelope struct {
+	inner            keys.Store
+	kek              KEK
+	migratePlaintext bool
+}
+
+// NewEnvelope returns a store sealing records for inner under kek. With
+// migratePlaintext, plaintext records are trusted and sealed on load.
+func NewEnvelope(inner keys.Store, kek KEK, migratePlaintext bool) *Envelope {
+	return &Envelope{inner: inner, kek: kek, migratePlaintext: migratePlaintext}
+}
+
+// CreateKeySet seals rec and creates it in the inner store.
+func (e *Envelope) CreateKeySet(ctx context.Context, rec keys.Record) error {
+	sealed, err := e.seal(ctx, rec)
+	if err != nil {
+		return err
+	}
+	return e.inner.CreateKeySet(ctx, sealed)
+}
+
+// UpdateKeySet seals rec and updates it in the inner store.
+func (e *Envelope) UpdateKeySet(ctx context.Context, rec keys.Record) error {
+	sealed, err := e.seal(ctx, rec)
+	if err != nil {
+		return err
+	}
+	return e.inner.UpdateKeySet(ctx, sealed)
+}
+
+// RevokeKeySet revokes id in the inner store.
+func (e *Envelope) RevokeKeySet(ctx context.Context, id string, at time.Time) error {
+	return e.inner.RevokeKeySet(ctx, id, at)
+}
+
+// LoadKeySets loads and opens the inner store's records, re-wrapping those
+// that need it.
+func (e *Envelope) LoadKeySets(ctx context.Context) ([]keys.Record, map[string]time.Time, error) {
+	recs, revoked, err := e.inner.LoadKeySets(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	current, err := e.kek.KeyID(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, rec := range recs {
+		opened, stale, plain, err := e.open(ctx, rec, current)
+		if err != nil {
+			return nil, nil, fmt.Errorf("key set %q: %w", rec.ID, err)
+		}
+		if stale {
+			if err := e.UpdateKeySet(ctx, opened); err != nil {
+				return nil, nil, fmt.Errorf("key set %q: re-wrap: %w", rec.ID, err)
+			}
+			logSealed(rec.ID, current, plain)
+		}
+		recs[i] = opened
+	}
+	return recs, revoked, nil
+}
+
+// Rewrap re-wraps every record whose data key was not wrapped under the
+// KEK's current key, e.g. after an alias was pointed at a new key, and
+// returns how many it re-wrapped.
+func (e *Envelope) Rewrap(ctx context.Context) (int, error) {
+	recs, _, err := e.inner.LoadKeySets(ctx)
+	if err != nil {
+		return 0, err
+	}
+	current, err := e.kek.KeyID(ctx)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, rec := range recs {
+		opened, stale, plain, err := e.open(ctx, rec, current)
+		if err != nil {
+			return n, fmt.Errorf("key set %q: %w", rec.ID, err)
+		}
+		if !stale {
+			continue
+		}
+		if err := e.UpdateKeySet(ctx, opened); err != nil {
+			return n, fmt.Errorf("key set %q: re-wrap: %w", rec.ID, err)
+		}
+		logSealed(rec.ID, current, plain)
+		n++
+	}
+	return n, nil
+}
+
+// fields lists the key fields of rec with the names bound into their
+// ciphertexts.
+func fields(rec *keys.Record) map[string]*[]byte {
+	return map[string]*[]byte{
+		"boolean_client": &rec.Boolean.Client,
+		"boolean_server": &rec.Boolean.Server,
+		"uint8_client":   &rec.Uint8.Client,
+		"uint8_server":   &rec.Uint8.Server,
+	}
+}
+
+// seal encrypts every non-empty key of rec under one fresh data key.
+func (e *Envelope) seal(ctx context.Context, rec keys.Record) (keys.Record, error) {
+	dek := make([]byte, 32)
+	if _, err := rand.Read(dek); err != nil {
+		return keys.Record{}, err
+	}
+	wrapped, err := e.kek.Wrap(ctx, dek)
+	if err != nil {
+		return keys.Record{}, err
+	}
+	if len(wrapped) > 0xffff {
+		return keys.Record{}, errors.New("wrapped data key too large")
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return keys.Record{}, err
+	}
+	for name, field := range fields(&rec) {
+		if len(*field) == 0 {
+			continue
+		}
+		var header bytes.Buffer
+		header.Write(sealedMagic)
+		_ = binary.Write(&header, binary.BigEndian, uint16(len(wrapped)))
+		header.Write(wrapped)
+		nonce := make([]byte, aead.NonceSize())
+		if _, err := rand.Read(nonce); err != nil {
+			return keys.Record{}, err
+		}
+		header.Write(nonce)
+		*field = aead.Seal(header.Bytes(), nonce, *field, additionalData(rec.ID, name))
+	}
+	return rec, nil
+}
+
+// logSealed records that the key set id was sealed again under current,
+// naming the fields that were migrated from plaintext.
+func logSealed(id, current string, plain []string) {
+	if len(plain) > 0 {
+		log.Printf("key set %q: plaintext %s sealed under %s", id, strings.Join(plain, ", "), current)
+		return
+	}
+	log.Printf("key set %q re-wrapped under %s", id, current)
+}
+
+// open decrypts every sealed key of rec. stale reports that the record
+// should be sealed again: it holds plaintext keys, listed in plain, or a
+// data key wrapped under a key other than current. Plaintext keys fail
+// with ErrUnsealed unless plaintext migration is on.
+func (e *Envelope) open(ctx context.Context, rec keys.Record, current string) (_ keys.Record, stale bool, plain []string, _ error) {
+	deks := make(map[string][]byte) // by wrapped form; fields usually share one
+	for name, field := range fields(&rec) {
+		data := *field
+		if len(data) == 0 {
+			continue
+		}
+		if !bytes.HasPrefix(data, sealedMagic) {
+			plain = append(plain, name)
+			continue
+		}
+		data = data[len(sealedMagic):]
+		if len(data) < 2 {
+			return keys.Record{}, false, nil, fmt.Errorf("%s: truncated envelope", name)
+		}
+		n := int(binary.BigEndian.Uint16(data))
+		data = data[2:]
+		if len(data) < n {
+			return keys.Record{}, false, nil, fmt.Errorf("%s: truncated envelope", name)
+		}
+		wrapped, data := data[:n], data[n:]
+		dek, ok := deks[string(wrapped)]
+		if !ok {
+			var keyID string
+			var err error
+			if dek, keyID, err = e.kek.Unwrap(ctx, wrapped); err != nil {
+				return keys.Record{}, false, nil, fmt.Errorf("%s: %w", name, err)
+			}
+			deks[string(wrapped)] = dek
+			if keyID != current {
+				stale = true
+			}
+		}
+		aead, err := newAEAD(dek)
+		if err != nil {
+			return keys.Record{}, false, nil, err
+		}
+		if len(data) < aead.NonceSize() {
+			return keys.Record{}, false, nil, fmt.Errorf("%s: truncated envelope", name)
+		}
+		nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+		key, err := aead.Open(nil, nonce, ciphertext, additionalData(rec.ID, name))
+		if err != nil {
+			return keys.Record{}, false, nil, fmt.Errorf("%s: %w", name, err)
+		}
+		*field = key
+	}
+	if len(plain) > 0 {
+		slices.Sort(plain)
+		if !e.migratePlaintext {
+			return keys.Record{}, false, nil, fmt.Errorf("%w: %s", ErrUnsealed, strings.Join(plain, ", "))
+		}
+		stale = true
+	}
+	return rec, stale, plain, nil
+}
+
+func newAEAD(dek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(dek)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// additionalData binds a sealed key to its record and field, so sealed keys
+// cannot be swapped between them.
+func additionalData(id, field string) []byte {
+	return []byte(id + "\x00" + field)
+}
@@ -0,0 +1,134 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/tfhe"
+)
+
+// fakeKEK wraps data keys by prefixing its key ID, which is enough to
+// exercise the envelope without a key management service.
+type fakeKEK struct{ id string }
+
+func (k *fakeKEK) Wrap(_ context.Context, dek []byte) ([]byte, error) {
+	return append([]byte(k.id+":"), dek...), nil
+}
+
+func (k *fakeKEK) Unwrap(_ context.Context, wrapped []byte) ([]byte, string, error) {
+	id, dek, ok := bytes.Cut(wrapped, []byte(":"))
+	if !ok {
+		return nil, "", errors.New("malformed wrapped key")
+	}
+	return dek, string(id), nil
+}
+
+func (k *fakeKEK) KeyID(context.Context) (string, error) { return k.id, nil }
+
+// memKeys is a keys.Store keeping records as written.
+type memKeys map[string]keys.Record
+
+func (m memKeys) CreateKeySet(_ context.Context, rec keys.Record) error {
+	m[rec.ID] = rec
+	return nil
+}
+
+func (m memKeys) UpdateKeySet(_ context.Context, rec keys.Record) error {
+	m[rec.ID] = rec
+	return nil
+}
+
+func (m memKeys) RevokeKeySet(_ context.Context, id string, _ time.Time) error {
+	delete(m, id)
+	return nil
+}
+
+func (m memKeys) LoadKeySets(context.Context) ([]keys.Record, map[string]time.Time, error) {
+	var recs []keys.Record
+	for _, rec := range m {
+		recs = append(recs, rec)
+	}
+	return recs, nil, nil
+}
+
+func testRecord(id string) keys.Record {
+	return keys.Record{
+		ID:      id,
+		Boolean: tfhe.KeyPair{Client: []byte("bool client " + id), Server: []byte("bool server " + id)},
+		Uint8:   tfhe.KeyPair{Server: []byte("uint8 server " + id)},
+	}
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	inner := memKeys{}
+	kek := &fakeKEK{id: "k1"}
+	e := NewEnvelope(inner, kek, false)
+	want := testRecord("a")
+	if err := e.CreateKeySet(ctx, want); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(inner["a"].Boolean.Client, want.Boolean.Client) {
+		t.Fatal("client key stored in the clear")
+	}
+
+	kek.id = "k2"
+	recs, _, err := e.LoadKeySets(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(recs) != 1 || !bytes.Equal(recs[0].Boolean.Client, want.Boolean.Client) || !bytes.Equal(recs[0].Uint8.Server, want.Uint8.Server) {
+		t.Fatalf("loaded %+v, want %+v", recs, want)
+	}
+	if n, err := e.Rewrap(ctx); err != nil || n != 0 {
+		t.Fatalf("rewrapped %d after loading re-wrapped them: %v", n, err)
+	}
+}
+
+// Keys planted in plaintext are refused unless migration is asked for.
+func TestEnvelopePlaintext(t *testing.T) {
+	ctx := context.Background()
+	for _, tc := range []struct {
+		name    string
+		migrate bool
+		planted func(inner memKeys) // writes plaintext past the envelope
+	}{
+		{name: "record", planted: func(inner memKeys) { inner["a"] = testRecord("a") }},
+		{name: "field", planted: func(inner memKeys) {
+			rec := inner["a"]
+			rec.Uint8.Server = []byte("planted")
+			inner["a"] = rec
+		}},
+		{name: "record migrated", migrate: true, planted: func(inner memKeys) { inner["a"] = testRecord("a") }},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			inner := memKeys{}
+			e := NewEnvelope(inner, &fakeKEK{id: "k1"}, tc.migrate)
+			if err := e.CreateKeySet(ctx, testRecord("a")); err != nil {
+				t.Fatal(err)
+			}
+			tc.planted(inner)
+
+			_, _, err := e.LoadKeySets(ctx)
+			if !tc.migrate {
+				if !errors.Is(err, ErrUnsealed) {
+					t.Fatalf("got %v, want ErrUnsealed", err)
+				}
+				if _, err := e.Rewrap(ctx); !errors.Is(err, ErrUnsealed) {
+					t.Fatalf("rewrap: got %v, want ErrUnsealed", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(inner["a"].Boolean.Client, sealedMagic) {
+				t.Fatal("migrated record left in plaintext")
+			}
+		})
+	}
+}
@@ -0,0 +1,19 @@
+// Package kms encrypts key material at rest under data keys that a key
+// management service wraps (envelope encryption). KEK is the extension
+// point: AWS KMS is implemented here, and other services only need Wrap,
+// Unwrap and KeyID.
+package kms
+
+import "context"
+
+// KEK is a key-encryption key held by a key management service.
+type KEK interface {
+	// Wrap encrypts a data key under the current key.
+	Wrap(ctx context.Context, dek []byte) ([]byte, error)
+	// Unwrap decrypts a wrapped data key and reports the ID of the key that
+	// wrapped it.
+	Unwrap(ctx context.Context, wrapped []byte) (dek []byte, keyID string, err error)
+	// KeyID returns the ID of the key Wrap uses now, in the form Unwrap
+	// reports. A data key unwrapped under another ID is due for re-wrapping.
+	KeyID(ctx context.Context) (string, error)
+}
@@ -0,0 +1,127 @@
+// Package machines keeps named finite state machine definitions per tenant.
+// The definitions are plaintext; the states and inputs they are run on stay
+// encrypted, so stateful rules such as rate limits or fraud checks can be
+// evaluated server-side without the server seeing the state.
+package machines
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+var (
+	// ErrNotFound is returned for a machine the tenant has not defined.
+	ErrNotFound = errors.New("machine not found")
+	// ErrExists is returned when defining a machine under a taken name.
+	ErrExists = errors.New("machine already exists")
+	// ErrLimit is returned when a tenant already has MaxPerTenant machines.
+	ErrLimit = errors.New("too many machines")
+	// ErrInvalidName is returned for names outside [A-Za-z0-9._-]{1,128}.
+	ErrInvalidName = errors.New("invalid machine name")
+)
+
+// MaxPerTenant bounds how many machines one tenant may define.
+const MaxPerTenant = 100
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Definition is a named machine of one tenant.
+type Definition struct {
+	Name      string
+	Tenant    string
+	Machine   tfhe.Machine
+	CreatedAt time.Time
+}
+
+type key struct{ tenant, name string }
+
+// Registry holds the machine definitions of every tenant in memory.
+// Definitions are immutable; to change one, delete and define it again.
+type Registry struct {
+	mu       sync.RWMutex
+	machines map[key]Definition
+	tenants  map[string]int
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{machines: make(map[key]Definition), tenants: make(map[string]int)}
+}
+
+// Create validates d and adds it under d.Tenant and d.Name.
+func (r *Registry) Create(d Definition) (Definition, error) {
+	if !validName.MatchString(d.Name) {
+		return Definition{}, fmt.Errorf("%w %q", ErrInvalidName, d.Name)
+	}
+	if err := d.Machine.Validate(); err != nil {
+		return Definition{}, err
+	}
+	m := tfhe.Machine{Initial: d.Machine.Initial, Transitions: make([][]uint8, len(d.Machine.Transitions))}
+	for s, row := range d.Machine.Transitions {
+		m.Transitions[s] = append([]uint8(nil), row...)
+	}
+	if d.Machine.Outputs != nil {
+		m.Outputs = append([]uint8(nil), d.Machine.Outputs...)
+	}
+	d.Machine = m
+	d.CreatedAt = time.Now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{d.Tenant, d.Name}
+	if _, ok := r.machines[k]; ok {
+		return Definition{}, fmt.Errorf("%w: %s", ErrExists, d.Name)
+	}
+	if r.tenants[d.Tenant] >= MaxPerTenant {
+		return Definition{}, fmt.Errorf("%w: a tenant may hold %d", ErrLimit, MaxPerTenant)
+	}
+	r.machines[k] = d
+	r.tenants[d.Tenant]++
+	return d, nil
+}
+
+// Get returns tenant's machine name.
+func (r *Registry) Get(tenant, name string) (Definition, error) {
+	r.mu.RLock()
+	d, ok := r.machines[key{tenant, name}]
+	r.mu.RUnlock()
+	if !ok {
+		return Definition{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return d, nil
+}
+
+// Delete removes tenant's machine name.
+func (r *Registry) Delete(tenant, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{tenant, name}
+	if _, ok := r.machines[k]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(r.machines, k)
+	if r.tenants[tenant]--; r.tenants[tenant] == 0 {
+		delete(r.tenants, tenant)
+	}
+	return nil
+}
+
+// List returns tenant's machines ordered by name.
+func (r *Registry) List(tenant string) []Definition {
+	r.mu.RLock()
+	var out []Definition
+	for k, d := range r.machines {
+		if k.tenant == tenant {
+			out = append(out, d)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,269 @@
+package metrics
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/store"
+	"tfhe-go/internal/tfhe"
+)
+
+// Prometheus collects HTTP, FHE operation, native memory and key metrics in
+// its own registry. It implements tfhe.Metrics.
+type Prometheus struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	opDuration      *prometheus.HistogramVec
+	opErrors        *prometheus.CounterVec
+	opResultBytes   *prometheus.CounterVec
+}
+
+// opBuckets cover FHE operations, which take from a few milliseconds for
+// boolean gates up to seconds for wide integer arithmetic.
+var opBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// NewPrometheus registers the collectors. keySets, when non-nil, is reported
+// as the number of active key sets.
+func NewPrometheus(keySets *keys.Registry) *Prometheus {
+	p := &Prometheus{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tfhe_http_requests_total",
+			Help: "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tfhe_http_request_duration_seconds",
+			Help:    "HTTP request latency by route and method.",
+			Buckets: opBuckets,
+		}, []string{"route", "method"}),
+		opDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "tfhe_op_duration_seconds",
+			Help:    "FHE service operation latency by operation.",
+			Buckets: opBuckets,
+		}, []string{"op"}),
+		opErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tfhe_op_errors_total",
+			Help: "FHE service operations that returned an error.",
+		}, []string{"op"}),
+		opResultBytes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "tfhe_op_result_bytes_total",
+			Help: "Serialized bytes produced by FHE service operations.",
+		}, []string{"op"}),
+	}
+	p.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		p.requests, p.requestDuration, p.opDuration, p.opErrors, p.opResultBytes,
+		nativeCollector{},
+	)
+	if keySets != nil {
+		p.registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "tfhe_key_sets",
+			Help: "Registered key sets.",
+		}, func() float64 { return float64(len(keySets.List())) }))
+	}
+	return p
+}
+
+// ObserveOp implements tfhe.Metrics.
+func (p *Prometheus) ObserveOp(op string, duration time.Duration, resultBytes int, err error) {
+	p.opDuration.WithLabelValues(op).Observe(duration.Seconds())
+	p.opResultBytes.WithLabelValues(op).Add(float64(resultBytes))
+	if err != nil {
+		p.opErrors.WithLabelValues(op).Inc()
+	}
+}
+
+// ObserveStore reports the contents and evictions of the in-memory
+// ciphertext store m.
+func (p *Prometheus) ObserveStore(m *store.Memory) {
+	p.registry.MustRegister(memoryStoreCollector{m})
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func (p *Prometheus) Handler() http.Handler {
+	return promhttp.HandlerFor(p.registry, promhttp.HandlerOpts{Registry: p.registry})
+}
+
+// Instrument records request metrics for next, labelling each request with
+// the mux pattern it matches so path parameters do not explode cardinality.
+func (p *Prometheus) Instrument(mux *http.ServeMux, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if _, pattern := mux.Handler(r); pattern != "" {
+			route = pattern
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		start := time.Now()
+		next.ServeHTTP(rec, r)
+		p.requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(start).Seconds())
+		p.requests.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Inc()
+	})
+}
+
+// statusRecorder captures the status code written by a handler.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// Hijack lets WebSocket upgrades take over the connection; a hijacked
+// request is recorded as 101 Switching Protocols.
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.status = http.StatusSwitchingProtocols
+	}
+	return conn, rw, err
+}
+
+var (
+	nativeObjectsDesc = prometheus.NewDesc("tfhe_native_objects",
+		"Live native tfhe-c objects by kind.", []string{"kind"}, nil)
+	nativeBytesDesc = prometheus.NewDesc("tfhe_native_memory_bytes",
+		"Estimated native memory held by live objects.", nil, nil)
+	nativeHeapDesc = prometheus.NewDesc("tfhe_native_heap_bytes",
+		"Bytes in use on the C heap as its allocator reports them; absent where it is not measured.", nil, nil)
+	nativeLimitDesc = prometheus.NewDesc("tfhe_native_memory_limit_bytes",
+		"Configured native memory cap; 0 means unlimited.", nil, nil)
+	leakedDesc = prometheus.NewDesc("tfhe_leaked_objects_total",
+		"Native objects released by a finalizer instead of Close.", nil, nil)
+	operandCacheEntriesDesc = prometheus.NewDesc("tfhe_operand_cache_entries",
+		"Deserialized operands held by the operand cache.", nil, nil)
+	operandCacheHitsDesc = prometheus.NewDesc("tfhe_operand_cache_hits_total",
+		"Operands served from the operand cache.", nil, nil)
+	operandCacheMissesDesc = prometheus.NewDesc("tfhe_operand_cache_misses_total",
+		"Operands deserialized with the operand cache enabled.", nil, nil)
+	expansionCacheBytesDesc = prometheus.NewDesc("tfhe_expansion_cache_bytes",
+		"Bytes of expanded compact lists held by the expansion cache.", nil, nil)
+	expansionCacheHitsDesc = prometheus.NewDesc("tfhe_expansion_cache_hits_total",
+		"Compact lists served from the expansion cache.", nil, nil)
+	expansionCacheMissesDesc = prometheus.NewDesc("tfhe_expansion_cache_misses_total",
+		"Compact lists expanded with the expansion cache enabled.", nil, nil)
+	nativePanicsDesc = prometheus.NewDesc("tfhe_native_panics_total",
+		"tfhe-c calls that panicked and failed their operation.", nil, nil)
+	slowOpsDesc = prometheus.NewDesc("tfhe_slow_ops_total",
+		"Evaluations that took at least the slow-op threshold, by operation.", []string{"op"}, nil)
+	opTimeoutsDesc = prometheus.NewDesc("tfhe_op_timeouts_total",
+		"Evaluations that ran past the operation budget, by operation.", []string{"op"}, nil)
+	stuckOpsDesc = prometheus.NewDesc("tfhe_stuck_ops",
+		"Evaluations past their budget whose native call is still running.", nil, nil)
+	computeRunningDesc = prometheus.NewDesc("tfhe_compute_running",
+		"Native calls holding a compute slot; only counted while a compute limit is set.", nil, nil)
+	computeQueuedDesc = prometheus.NewDesc("tfhe_compute_queued",
+		"Native calls waiting for a compute slot.", nil, nil)
+	computeWaitsDesc = prometheus.NewDesc("tfhe_compute_waits_total",
+		"Native calls that queued for a compute slot.", nil, nil)
+	computeRejectionsDesc = prometheus.NewDesc("tfhe_compute_rejections_total",
+		"Native calls that failed after queueing longer than the compute queue timeout.", nil, nil)
+	trivialResultsDesc = prometheus.NewDesc("tfhe_trivial_results_total",
+		"Evaluations whose result was a trivial encryption, readable without the key.", nil, nil)
+	trivialUncheckedDesc = prometheus.NewDesc("tfhe_trivial_unchecked_total",
+		"Evaluations whose result the backend cannot check for a trivial encryption; returned even with reject-trivial on.", nil, nil)
+	storeEntriesDesc = prometheus.NewDesc("tfhe_store_entries",
+		"Handles held by the in-memory ciphertext store.", nil, nil)
+	storeBytesDesc = prometheus.NewDesc("tfhe_store_bytes",
+		"Ciphertext bytes held by the in-memory ciphertext store.", nil, nil)
+	storeEvictionsDesc = prometheus.NewDesc("tfhe_store_evictions_total",
+		"Handles evicted from the in-memory ciphertext store, by reason: capacity or ttl.", []string{"reason"}, nil)
+)
+
+// nativeCollector reports tfhe.MemoryUsage, tfhe.OperandCacheUsage,
+// tfhe.ExpansionCacheUsage, tfhe.NativePanics, tfhe.DeadlineUsage and
+// tfhe.ComputeUsage at scrape time.
+type nativeCollector struct{}
+
+func (nativeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- nativeObjectsDesc
+	ch <- nativeBytesDesc
+	ch <- nativeHeapDesc
+	ch <- nativeLimitDesc
+	ch <- leakedDesc
+	ch <- operandCacheEntriesDesc
+	ch <- operandCacheHitsDesc
+	ch <- operandCacheMissesDesc
+	ch <- expansionCacheBytesDesc
+	ch <- expansionCacheHitsDesc
+	ch <- expansionCacheMissesDesc
+	ch <- nativePanicsDesc
+	ch <- slowOpsDesc
+	ch <- opTimeoutsDesc
+	ch <- stuckOpsDesc
+	ch <- computeRunningDesc
+	ch <- computeQueuedDesc
+	ch <- computeWaitsDesc
+	ch <- computeRejectionsDesc
+	ch <- trivialResultsDesc
+	ch <- trivialUncheckedDesc
+}
+
+func (nativeCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := tfhe.MemoryUsage()
+	for kind, n := range stats.Objects {
+		ch <- prometheus.MustNewConstMetric(nativeObjectsDesc, prometheus.GaugeValue, float64(n), kind)
+	}
+	ch <- prometheus.MustNewConstMetric(nativeBytesDesc, prometheus.GaugeValue, float64(stats.Bytes))
+	if heap, ok := tfhe.NativeHeap(); ok {
+		ch <- prometheus.MustNewConstMetric(nativeHeapDesc, prometheus.GaugeValue, float64(heap))
+	}
+	ch <- prometheus.MustNewConstMetric(nativeLimitDesc, prometheus.GaugeValue, float64(stats.Limit))
+	ch <- prometheus.MustNewConstMetric(leakedDesc, prometheus.CounterValue, float64(stats.Leaked))
+	operands := tfhe.OperandCacheUsage()
+	ch <- prometheus.MustNewConstMetric(operandCacheEntriesDesc, prometheus.GaugeValue, float64(operands.Entries))
+	ch <- prometheus.MustNewConstMetric(operandCacheHitsDesc, prometheus.CounterValue, float64(operands.Hits))
+	ch <- prometheus.MustNewConstMetric(operandCacheMissesDesc, prometheus.CounterValue, float64(operands.Misses))
+	expansions := tfhe.ExpansionCacheUsage()
+	ch <- prometheus.MustNewConstMetric(expansionCacheBytesDesc, prometheus.GaugeValue, float64(expansions.Bytes))
+	ch <- prometheus.MustNewConstMetric(expansionCacheHitsDesc, prometheus.CounterValue, float64(expansions.Hits))
+	ch <- prometheus.MustNewConstMetric(expansionCacheMissesDesc, prometheus.CounterValue, float64(expansions.Misses))
+	ch <- prometheus.MustNewConstMetric(nativePanicsDesc, prometheus.CounterValue, float64(tfhe.NativePanics()))
+	deadlines := tfhe.DeadlineUsage()
+	for op, n := range deadlines.Slow {
+		ch <- prometheus.MustNewConstMetric(slowOpsDesc, prometheus.CounterValue, float64(n), op)
+	}
+	for op, n := range deadlines.TimedOut {
+		ch <- prometheus.MustNewConstMetric(opTimeoutsDesc, prometheus.CounterValue, float64(n), op)
+	}
+	ch <- prometheus.MustNewConstMetric(stuckOpsDesc, prometheus.GaugeValue, float64(deadlines.Stuck))
+	compute := tfhe.ComputeUsage()
+	ch <- prometheus.MustNewConstMetric(computeRunningDesc, prometheus.GaugeValue, float64(compute.Running))
+	ch <- prometheus.MustNewConstMetric(computeQueuedDesc, prometheus.GaugeValue, float64(compute.Queued))
+	ch <- prometheus.MustNewConstMetric(computeWaitsDesc, prometheus.CounterValue, float64(compute.Waited))
+	ch <- prometheus.MustNewConstMetric(computeRejectionsDesc, prometheus.CounterValue, float64(compute.Rejected))
+	ch <- prometheus.MustNewConstMetric(trivialResultsDesc, prometheus.CounterValue, float64(tfhe.TrivialResults()))
+	ch <- prometheus.MustNewConstMetric(trivialUncheckedDesc, prometheus.CounterValue, float64(tfhe.UncheckedResults()))
+}
+
+// memoryStoreCollector reports store.Memory.Stats at scrape time.
+type memoryStoreCollector struct{ m *store.Memory }
+
+func (memoryStoreCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- storeEntriesDesc
+	ch <- storeBytesDesc
+	ch <- storeEvictionsDesc
+}
+
+func (c memoryStoreCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.m.Stats()
+	ch <- prometheus.MustNewConstMetric(storeEntriesDesc, prometheus.GaugeValue, float64(stats.Entries))
+	ch <- prometheus.MustNewConstMetric(storeBytesDesc, prometheus.GaugeValue, float64(stats.Bytes))
+	ch <- prometheus.MustNewConstMetric(storeEvictionsDesc, prometheus.CounterValue, float64(stats.EvictedCapacity), "capacity")
+	ch <- prometheus.MustNewConstMetric(storeEvictionsDesc, prometheus.CounterValue, float64(stats.EvictedExpired), "ttl")
+}
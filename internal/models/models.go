@@ -0,0 +1,120 @@
+// Package models keeps named linear and logistic scoring models per tenant.
+// The weights are plaintext; the feature vectors they score and the scores
+// stay encrypted, so the server can score records it cannot read.
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"tfhe-go/internal/tfhe"
+)
+
+var (
+	// ErrNotFound is returned for a model the tenant has not loaded.
+	ErrNotFound = errors.New("model not found")
+	// ErrExists is returned when loading a model under a taken name.
+	ErrExists = errors.New("model already exists")
+	// ErrLimit is returned when a tenant already has MaxPerTenant models.
+	ErrLimit = errors.New("too many models")
+	// ErrInvalidName is returned for names outside [A-Za-z0-9._-]{1,128}.
+	ErrInvalidName = errors.New("invalid model name")
+)
+
+// MaxPerTenant bounds how many models one tenant may load.
+const MaxPerTenant = 100
+
+var validName = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
+// Definition is a named model of one tenant.
+type Definition struct {
+	Name      string
+	Tenant    string
+	Model     tfhe.LinearModel
+	CreatedAt time.Time
+}
+
+type key struct{ tenant, name string }
+
+// Registry holds the models of every tenant in memory. Models are
+// immutable; to retrain one, delete and load it again.
+type Registry struct {
+	mu      sync.RWMutex
+	models  map[key]Definition
+	tenants map[string]int
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{models: make(map[key]Definition), tenants: make(map[string]int)}
+}
+
+// Create validates d and adds it under d.Tenant and d.Name.
+func (r *Registry) Create(d Definition) (Definition, error) {
+	if !validName.MatchString(d.Name) {
+		return Definition{}, fmt.Errorf("%w %q", ErrInvalidName, d.Name)
+	}
+	if err := d.Model.Validate(); err != nil {
+		return Definition{}, err
+	}
+	d.Model.Weights = slices.Clone(d.Model.Weights)
+	d.CreatedAt = time.Now().UTC()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{d.Tenant, d.Name}
+	if _, ok := r.models[k]; ok {
+		return Definition{}, fmt.Errorf("%w: %s", ErrExists, d.Name)
+	}
+	if r.tenants[d.Tenant] >= MaxPerTenant {
+		return Definition{}, fmt.Errorf("%w: a tenant may hold %d", ErrLimit, MaxPerTenant)
+	}
+	r.models[k] = d
+	r.tenants[d.Tenant]++
+	return d, nil
+}
+
+// Get returns tenant's model name.
+func (r *Registry) Get(tenant, name string) (Definition, error) {
+	r.mu.RLock()
+	d, ok := r.models[key{tenant, name}]
+	r.mu.RUnlock()
+	if !ok {
+		return Definition{}, fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return d, nil
+}
+
+// Delete removes tenant's model name.
+func (r *Registry) Delete(tenant, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := key{tenant, name}
+	if _, ok := r.models[k]; !ok {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	delete(r.models, k)
+	if r.tenants[tenant]--; r.tenants[tenant] == 0 {
+		delete(r.tenants, tenant)
+	}
+	return nil
+}
+
+// List returns tenant's models ordered by name.
+func (r *Registry) List(tenant string) []Definition {
+	r.mu.RLock()
+	var out []Definition
+	for k, d := range r.models {
+		if k.tenant == tenant {
+			out = append(out, d)
+		}
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,238 @@
+// Package paramsets profiles the supported parameter sets on the current
+// hardware, running a standard operation mix under each, and recommends one
+// that meets an operator's security, latency and key size requirements.
+package paramsets
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"tfhe-go/internal/bench"
+	"tfhe-go/internal/keys"
+)
+
+// SecurityBits is the security level each parameter set's authors document
+// for it. Sets missing here are reported with an unknown level.
+var SecurityBits = map[string]int{
+	// tfhe-rs documents its boolean DEFAULT_PARAMETERS and integer
+	// ConfigBuilder defaults as 128-bit secure.
+	keys.DefaultParams: 128,
+}
+
+// Weighted is a bench case and its share of the mix.
+type Weighted struct {
+	Case   string
+	Weight int
+}
+
+// Mix is the standard operation mix, modelled on the circuits the service
+// evaluates: mostly uint8 arithmetic and comparisons, some wider additions
+// and a few boolean gates.
+var Mix = []Weighted{
+	{"uint8.add", 4},
+	{"uint8.bitxor", 2},
+	{"uint8.lt", 2},
+	{"uint16.add", 1},
+	{"uint32.add", 1},
+	{"boolean.and", 2},
+	{"boolean.not", 1},
+}
+
+// sizeCases measure the serialized size of each ciphertext type.
+var sizeCases = regexp.MustCompile(`\.serialize$`)
+
+// Profile is how one parameter set performed.
+type Profile struct {
+	ParameterSet string `json:"parameter_set"`
+	// SecurityBits is 0 when the level is not known.
+	SecurityBits int `json:"security_bits,omitempty"`
+	// MixNanos is the weighted mean latency of one operation of the mix.
+	MixNanos int64 `json:"mix_ns"`
+	// OpsPerSecond is the mix throughput of a single worker.
+	OpsPerSecond float64 `json:"ops_per_second"`
+	// KeyBytes and CiphertextBytes are serialized sizes, by key name and
+	// ciphertext type.
+	KeyBytes        map[string]int `json:"key_bytes"`
+	CiphertextBytes map[string]int `json:"ciphertext_bytes"`
+	Ops             []bench.Result `json:"ops"`
+}
+
+// ServerKeyBytes is the size of the server keys every instance loads.
+func (p Profile) ServerKeyBytes() int {
+	return p.KeyBytes["boolean_server"] + p.KeyBytes["uint8_server"]
+}
+
+// Options configures Run.
+type Options struct {
+	// ParameterSets to profile; defaults to all of bench.ParameterSets.
+	ParameterSets []string
+	// Iterations per operation, after one warm-up run. Defaults to 10.
+	Iterations int
+	// Progress, if set, is called after each measured operation.
+	Progress func(params string, r bench.Result)
+}
+
+// Run profiles each parameter set: it generates keys, records their sizes
+// and times the mix and serialization cases.
+func Run(opts Options) ([]Profile, error) {
+	if len(opts.ParameterSets) == 0 {
+		opts.ParameterSets = bench.ParameterSets
+	}
+	if opts.Iterations <= 0 {
+		opts.Iterations = 10
+	}
+	weights := make(map[string]int, len(Mix))
+	for _, w := range Mix {
+		weights[w.Case] = w.Weight
+	}
+	var profiles []Profile
+	for _, params := range opts.ParameterSets {
+		p, err := profile(params, weights, opts)
+		if err != nil {
+			return profiles, fmt.Errorf("parameter set %s: %w", params, err)
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles, nil
+}
+
+func profile(params string, weights map[string]int, opts Options) (Profile, error) {
+	env, err := bench.NewEnv(params)
+	if err != nil {
+		return Profile{}, err
+	}
+	defer env.Close()
+
+	p := Profile{
+		ParameterSet:    params,
+		SecurityBits:    SecurityBits[params],
+		KeyBytes:        make(map[string]int),
+		CiphertextBytes: make(map[string]int),
+	}
+	for name, serialize := range map[string]func() ([]byte, error){
+		"boolean_client": env.BoolClient.Serialize,
+		"boolean_server": env.BoolServer.Serialize,
+		"uint8_client":   env.Client.Serialize,
+		"uint8_server":   env.Server.Serialize,
+		"uint8_public":   env.Public.Serialize,
+	} {
+		data, err := serialize()
+		if err != nil {
+			return Profile{}, fmt.Errorf("%s key: %w", name, err)
+		}
+		p.KeyBytes[name] = len(data)
+	}
+
+	var weighted, total int64
+	for _, c := range bench.Cases() {
+		weight, inMix := weights[c.Name]
+		if !inMix && !sizeCases.MatchString(c.Name) {
+			continue
+		}
+		r, err := bench.Measure(env, c, opts.Iterations)
+		if err != nil {
+			return Profile{}, fmt.Errorf("%s: %w", c.Name, err)
+		}
+		r.ParameterSet = params
+		if opts.Progress != nil {
+			opts.Progress(params, r)
+		}
+		if inMix {
+			weighted += r.MeanNanos * int64(weight)
+			total += int64(weight)
+			p.Ops = append(p.Ops, r)
+		} else {
+			p.CiphertextBytes[strings.TrimSuffix(c.Name, ".serialize")] = r.Bytes
+		}
+	}
+	if total > 0 && weighted > 0 {
+		p.MixNanos = weighted / total
+		p.OpsPerSecond = float64(time.Second) / float64(p.MixNanos)
+	}
+	return p, nil
+}
+
+// Requirements bound the parameter sets an operator accepts. Zero values
+// impose no bound.
+type Requirements struct {
+	MinSecurityBits   int
+	MaxMixLatency     time.Duration
+	MaxServerKeyBytes int
+}
+
+// ErrNoneSuitable reports that no profiled parameter set meets the
+// requirements.
+var ErrNoneSuitable = errors.New("no parameter set meets the requirements")
+
+// Recommend returns the fastest profile meeting req, and for every other
+// profile the reason it was not chosen.
+func Recommend(profiles []Profile, req Requirements) (Profile, []string, error) {
+	var best *Profile
+	var rejected []string
+	for i := range profiles {
+		p := &profiles[i]
+		if reason := unsuitable(*p, req); reason != "" {
+			rejected = append(rejected, fmt.Sprintf("%s: %s", p.ParameterSet, reason))
+			continue
+		}
+		if best == nil || p.MixNanos < best.MixNanos {
+			if best != nil {
+				rejected = append(rejected, fmt.Sprintf("%s: slower than %s", best.ParameterSet, p.ParameterSet))
+			}
+			best = p
+		} else {
+			rejected = append(rejected, fmt.Sprintf("%s: slower than %s", p.ParameterSet, best.ParameterSet))
+		}
+	}
+	if best == nil {
+		return Profile{}, rejected, ErrNoneSuitable
+	}
+	return *best, rejected, nil
+}
+
+func unsuitable(p Profile, req Requirements) string {
+	switch {
+	case req.MinSecurityBits > 0 && p.SecurityBits == 0:
+		return "security level unknown"
+	case p.SecurityBits < req.MinSecurityBits:
+		return fmt.Sprintf("%d-bit security is below %d", p.SecurityBits, req.MinSecurityBits)
+	case req.MaxMixLatency > 0 && time.Duration(p.MixNanos) > req.MaxMixLatency:
+		return fmt.Sprintf("mix latency %v exceeds %v", time.Duration(p.MixNanos), req.MaxMixLatency)
+	case req.MaxServerKeyBytes > 0 && p.ServerKeyBytes() > req.MaxServerKeyBytes:
+		return fmt.Sprintf("server keys of %s exceed %s", formatBytes(p.ServerKeyBytes()), formatBytes(req.MaxServerKeyBytes))
+	}
+	return ""
+}
+
+// WriteTable writes one row per profile: security, mix latency and
+// throughput, and key and ciphertext sizes.
+func WriteTable(w io.Writer, profiles []Profile) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PARAMS\tSECURITY\tMIX LATENCY\tOPS/S\tSERVER KEYS\tPUBLIC KEY\tUINT8 CT\tUINT64 CT")
+	for _, p := range profiles {
+		security := "unknown"
+		if p.SecurityBits > 0 {
+			security = fmt.Sprintf("%d-bit", p.SecurityBits)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%v\t%.1f\t%s\t%s\t%s\t%s\n",
+			p.ParameterSet, security, time.Duration(p.MixNanos).Round(time.Microsecond), p.OpsPerSecond,
+			formatBytes(p.ServerKeyBytes()), formatBytes(p.KeyBytes["uint8_public"]),
+			formatBytes(p.CiphertextBytes["uint8"]), formatBytes(p.CiphertextBytes["uint64"]))
+	}
+	return tw.Flush()
+}
+
+func formatBytes(n int) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MiB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KiB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
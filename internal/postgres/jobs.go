@@ -0,0 +1,148 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// ErrJobNotFound is returned when a job ID does not exist, or is no longer
+// held by the worker finishing it.
+var ErrJobNotFound = errors.New("job not found")
+
+// JobState is the lifecycle state of a job.
+type JobState string
+
+const (
+	JobQueued    JobState = "queued"
+	JobRunning   JobState = "running"
+	JobSucceeded JobState = "succeeded"
+	JobFailed    JobState = "failed"
+)
+
+// Job is a unit of asynchronous work. Payload and Result are opaque to the
+// queue; Kind tells workers how to interpret them.
+type Job struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Tenant    string    `json:"tenant"`
+	Payload   []byte    `json:"-"`
+	State     JobState  `json:"state"`
+	Attempts  int       `json:"attempts"`
+	Result    []byte    `json:"-"`
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Jobs is a durable job queue over the jobs table. Any number of workers,
+// in any number of instances, may claim from it concurrently.
+type Jobs struct {
+	db *sql.DB
+	// MaxAttempts bounds how often a job whose worker died is retried
+	// before it is failed; zero means 3.
+	MaxAttempts int
+}
+
+// NewJobs returns a job queue over db, which must be migrated.
+func NewJobs(db *sql.DB) *Jobs {
+	return &Jobs{db: db}
+}
+
+const jobColumns = `id, kind, tenant, payload, state, attempts, result, COALESCE(error, ''), created_at, updated_at`
+
+func scanJob(row interface{ Scan(...any) error }) (Job, error) {
+	var j Job
+	err := row.Scan(&j.ID, &j.Kind, &j.Tenant, &j.Payload, &j.State, &j.Attempts, &j.Result, &j.Error, &j.CreatedAt, &j.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Job{}, ErrJobNotFound
+	}
+	if err != nil {
+		return Job{}, err
+	}
+	j.CreatedAt, j.UpdatedAt = j.CreatedAt.UTC(), j.UpdatedAt.UTC()
+	return j, nil
+}
+
+// Enqueue adds a job of kind for tenant.
+func (q *Jobs) Enqueue(ctx context.Context, kind, tenant string, payload []byte) (Job, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return Job{}, err
+	}
+	if payload == nil {
+		payload = []byte{}
+	}
+	return scanJob(q.db.QueryRowContext(ctx, `INSERT INTO jobs (id, kind, tenant, payload)
+		VALUES ($1, $2, $3, $4) RETURNING `+jobColumns, hex.EncodeToString(b[:]), kind, tenant, payload))
+}
+
+// Get returns the job id.
+func (q *Jobs) Get(ctx context.Context, id string) (Job, error) {
+	return scanJob(q.db.QueryRowContext(ctx, `SELECT `+jobColumns+` FROM jobs WHERE id = $1`, id))
+}
+
+// Claim leases the oldest queued job, or running job whose lease expired, to
+// worker for lease. It returns ErrJobNotFound when no job is pending. The
+// worker must call Complete or Fail before the lease runs out, or the job
+// is handed to another worker.
+func (q *Jobs) Claim(ctx context.Context, worker string, lease time.Duration) (Job, error) {
+	maxAttempts := q.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 3
+	}
+	// Jobs whose workers died too often are failed rather than retried.
+	if _, err := q.db.ExecContext(ctx, `UPDATE jobs
+		SET state = 'failed', error = 'lease expired too many times', locked_by = NULL, locked_until = NULL, updated_at = now()
+		WHERE state = 'running' AND locked_until < now() AND attempts >= $1`, maxAttempts); err != nil {
+		return Job{}, err
+	}
+	return scanJob(q.db.QueryRowContext(ctx, `UPDATE jobs
+		SET state = 'running', attempts = attempts + 1, locked_by = $1,
+			locked_until = now() + $2 * interval '1 millisecond', updated_at = now()
+		WHERE id = (
+			SELECT id FROM jobs
+			WHERE state = 'queued' OR (state = 'running' AND locked_until < now())
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING `+jobColumns, worker, lease.Milliseconds()))
+}
+
+// Complete records the result of a job claimed by worker.
+func (q *Jobs) Complete(ctx context.Context, id, worker string, result []byte) error {
+	return q.finish(ctx, id, worker, JobSucceeded, result, "")
+}
+
+// Fail records why a job claimed by worker failed. Failed jobs are not
+// retried.
+func (q *Jobs) Fail(ctx context.Context, id, worker string, cause error) error {
+	return q.finish(ctx, id, worker, JobFailed, nil, cause.Error())
+}
+
+func (q *Jobs) finish(ctx context.Context, id, worker string, state JobState, result []byte, msg string) error {
+	res, err := q.db.ExecContext(ctx, `UPDATE jobs
+		SET state = $3, result = $4, error = NULLIF($5, ''), locked_by = NULL, locked_until = NULL, updated_at = now()
+		WHERE id = $1 AND state = 'running' AND locked_by = $2`, id, worker, state, result, msg)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrJobNotFound
+	}
+	return nil
+}
+
+// Purge deletes finished jobs last updated before cutoff and returns how
+// many were removed.
+func (q *Jobs) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	res, err := q.db.ExecContext(ctx, `DELETE FROM jobs WHERE state IN ('succeeded', 'failed') AND updated_at < $1`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
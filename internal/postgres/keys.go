@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"tfhe-go/internal/keys"
+)
+
+// Keys is a keys.Store over the key_sets table. Client keys are stored as
+// given, so the database must be protected like the keys themselves.
+type Keys struct {
+	db *sql.DB
+}
+
+// NewKeys returns a key store over db, which must be migrated.
+func NewKeys(db *sql.DB) *Keys {
+	return &Keys{db: db}
+}
+
+// CreateKeySet inserts rec, or returns keys.ErrKeySetExists.
+func (k *Keys) CreateKeySet(ctx context.Context, rec keys.Record) error {
+	_, err := k.db.ExecContext(ctx, `INSERT INTO key_sets
+		(id, params, created_at, boolean_client, boolean_server, uint8_client, uint8_server)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		rec.ID, rec.Params, rec.CreatedAt, rec.Boolean.Client, rec.Boolean.Server, rec.Uint8.Client, rec.Uint8.Server)
+	if uniqueViolation(err) {
+		return fmt.Errorf("%w: %q", keys.ErrKeySetExists, rec.ID)
+	}
+	return err
+}
+
+// UpdateKeySet replaces the keys of the active key set rec.ID.
+func (k *Keys) UpdateKeySet(ctx context.Context, rec keys.Record) error {
+	res, err := k.db.ExecContext(ctx, `UPDATE key_sets
+		SET boolean_client = $2, boolean_server = $3, uint8_client = $4, uint8_server = $5, updated_at = now()
+		WHERE id = $1 AND revoked_at IS NULL`,
+		rec.ID, rec.Boolean.Client, rec.Boolean.Server, rec.Uint8.Client, rec.Uint8.Server)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return fmt.Errorf("%w: %q", keys.ErrUnknownKeySet, rec.ID)
+	}
+	return nil
+}
+
+// RevokeKeySet marks id revoked and discards its keys.
+func (k *Keys) RevokeKeySet(ctx context.Context, id string, at time.Time) error {
+	_, err := k.db.ExecContext(ctx, `UPDATE key_sets
+		SET revoked_at = $2, updated_at = now(),
+			boolean_client = NULL, boolean_server = NULL, uint8_client = NULL, uint8_server = NULL
+		WHERE id = $1 AND revoked_at IS NULL`, id, at)
+	return err
+}
+
+// LoadKeySets returns the active key sets and the revoked IDs.
+func (k *Keys) LoadKeySets(ctx context.Context) ([]keys.Record, map[string]time.Time, error) {
+	rows, err := k.db.QueryContext(ctx, `SELECT id, params, created_at, revoked_at,
+		boolean_client, boolean_server, uint8_client, uint8_server
+		FROM key_sets ORDER BY id`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	var recs []keys.Record
+	revoked := make(map[string]time.Time)
+	for rows.Next() {
+		var rec keys.Record
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&rec.ID, &rec.Params, &rec.CreatedAt, &revokedAt,
+			&rec.Boolean.Client, &rec.Boolean.Server, &rec.Uint8.Client, &rec.Uint8.Server); err != nil {
+			return nil, nil, err
+		}
+		if revokedAt.Valid {
+			revoked[rec.ID] = revokedAt.Time.UTC()
+			continue
+		}
+		rec.CreatedAt = rec.CreatedAt.UTC()
+		recs = append(recs, rec)
+	}
+	return recs, revoked, rows.Err()
+}
@@ -0,0 +1,45 @@
+-- Key sets. Revoked sets keep their row, with the keys discarded, so the ID
+-- is never registered again.
+CREATE TABLE key_sets (
+    id             text PRIMARY KEY,
+    params         text NOT NULL,
+    created_at     timestamptz NOT NULL,
+    updated_at     timestamptz NOT NULL DEFAULT now(),
+    revoked_at     timestamptz,
+    boolean_client bytea,
+    boolean_server bytea,
+    uint8_client   bytea,
+    uint8_server   bytea
+);
+
+-- Ciphertext handles. data is NULL when the ciphertext is kept in a blob
+-- store and only its metadata lives here.
+CREATE TABLE ciphertexts (
+    id         text PRIMARY KEY,
+    tenant     text NOT NULL,
+    type       text NOT NULL,
+    created_at timestamptz NOT NULL,
+    data       bytea
+);
+
+CREATE INDEX ciphertexts_created ON ciphertexts (created_at, id);
+CREATE INDEX ciphertexts_tenant ON ciphertexts (tenant, created_at, id);
+
+-- Asynchronous jobs. Workers claim queued jobs, or running jobs whose lease
+-- expired, with FOR UPDATE SKIP LOCKED.
+CREATE TABLE jobs (
+    id           text PRIMARY KEY,
+    kind         text NOT NULL,
+    tenant       text NOT NULL,
+    payload      bytea NOT NULL,
+    state        text NOT NULL DEFAULT 'queued',
+    attempts     integer NOT NULL DEFAULT 0,
+    result       bytea,
+    error        text,
+    locked_by    text,
+    locked_until timestamptz,
+    created_at   timestamptz NOT NULL DEFAULT now(),
+    updated_at   timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX jobs_pending ON jobs (created_at) WHERE state IN ('queued', 'running');
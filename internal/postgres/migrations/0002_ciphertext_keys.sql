@@ -0,0 +1,6 @@
+-- The key set each ciphertext is encrypted under and the generation of its
+-- keys. Handles stored before this migration have an empty key_set and are
+-- taken to be under the default key set.
+ALTER TABLE ciphertexts
+    ADD COLUMN key_set    text NOT NULL DEFAULT '',
+    ADD COLUMN generation bigint NOT NULL DEFAULT 0;
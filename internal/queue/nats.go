@@ -0,0 +1,85 @@
+package queue
+
+import (
+	"context"
+	"errors"
+
+	"github.com/nats-io/nats.go"
+)
+
+// natsBuffer bounds the requests a NATS worker holds before it has fetched
+// them; beyond it the server drops messages for this slow consumer.
+const natsBuffer = 1024
+
+// NATS is a Broker on core NATS. Workers sharing a queue group split the
+// requests between them. Results go to a request's reply subject when it
+// has one, and to the results subject otherwise. Core NATS does not persist
+// messages, so requests in flight when a worker dies are lost.
+type NATS struct {
+	conn    *nats.Conn
+	sub     *nats.Subscription
+	msgs    chan *nats.Msg
+	results string
+}
+
+// NewNATS connects to url and subscribes to requests in queue group.
+func NewNATS(url, requests, group, results string) (*NATS, error) {
+	conn, err := nats.Connect(url, nats.Name("tfhe-go"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, err
+	}
+	n := &NATS{conn: conn, msgs: make(chan *nats.Msg, natsBuffer), results: results}
+	if n.sub, err = conn.ChanQueueSubscribe(requests, group, n.msgs); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// Fetch waits for a request and takes up to max of those already received.
+func (n *NATS) Fetch(ctx context.Context, max int) ([]Delivery, error) {
+	var batch []Delivery
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case msg := <-n.msgs:
+		batch = append(batch, Delivery{Data: msg.Data, ref: msg})
+	}
+	for len(batch) < max {
+		select {
+		case msg := <-n.msgs:
+			batch = append(batch, Delivery{Data: msg.Data, ref: msg})
+		default:
+			return batch, nil
+		}
+	}
+	return batch, nil
+}
+
+// Publish replies to d, or publishes to the results subject.
+func (n *NATS) Publish(ctx context.Context, d Delivery, result []byte) error {
+	msg := d.ref.(*nats.Msg)
+	switch {
+	case msg.Reply != "":
+		return n.conn.Publish(msg.Reply, result)
+	case n.results != "":
+		return n.conn.Publish(n.results, result)
+	default:
+		return errors.New("nats: request has no reply subject and no results subject is set")
+	}
+}
+
+// Ack flushes the published results; core NATS has nothing to acknowledge.
+func (n *NATS) Ack(ctx context.Context, batch []Delivery) error {
+	if len(batch) == 0 {
+		return nil
+	}
+	return n.conn.FlushWithContext(ctx)
+}
+
+// Close unsubscribes and closes the connection.
+func (n *NATS) Close() error {
+	err := n.sub.Unsubscribe()
+	n.conn.Close()
+	return err
+}
@@ -0,0 +1,215 @@
+// Package queue evaluates circuits requested over a message queue and
+// publishes their results, for batch workloads that do not fit HTTP's
+// request/response shape.
+package queue
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"runtime"
+	"sync"
+	"time"
+
+	"tfhe-go/internal/circuit"
+)
+
+// Request is one queued evaluation: a circuit, given as an expression or an
+// op graph, over named input ciphertexts, as for POST /evaluate.
+type Request struct {
+	// ID is echoed in the result so callers can correlate them.
+	ID         string           `json:"id"`
+	KeyID      string           `json:"key_id,omitempty"`
+	Expression string           `json:"expression,omitempty"`
+	Graph      *circuit.Graph   `json:"graph,omitempty"`
+	Inputs     map[string]Value `json:"inputs"`
+}
+
+// Value is a base64 ciphertext and its type name, e.g. "uint8".
+type Value struct {
+	Type       string `json:"type"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// Result answers the request with the same ID, with either its outputs or
+// an error.
+type Result struct {
+	ID      string           `json:"id"`
+	Outputs map[string]Value `json:"outputs,omitempty"`
+	Error   string           `json:"error,omitempty"`
+}
+
+// Evaluator runs circuits under the key set keyID selects.
+type Evaluator interface {
+	Evaluate(ctx context.Context, keyID string, g *circuit.Graph, inputs map[string]circuit.Value) (map[string]circuit.Value, error)
+}
+
+// Delivery is a request message taken from a broker.
+type Delivery struct {
+	Data []byte
+	// ref is the broker's own message, used to reply and acknowledge.
+	ref any
+}
+
+// Broker is the queue a worker consumes from and publishes to. NATS is the
+// implementation shipped; others plug in here.
+type Broker interface {
+	// Fetch blocks until at least one request is available and returns up
+	// to max of them.
+	Fetch(ctx context.Context, max int) ([]Delivery, error)
+	// Publish sends the result of d.
+	Publish(ctx context.Context, d Delivery, result []byte) error
+	// Ack marks a fetched batch as answered. It is called after every
+	// Fetch, once each delivery's result has been published.
+	Ack(ctx context.Context, batch []Delivery) error
+	Close() error
+}
+
+// Worker consumes requests from a broker and evaluates them concurrently.
+type Worker struct {
+	broker      Broker
+	eval        Evaluator
+	concurrency int
+}
+
+// NewWorker returns a worker evaluating requests from b with e.
+func NewWorker(b Broker, e Evaluator) *Worker {
+	return &Worker{broker: b, eval: e, concurrency: runtime.GOMAXPROCS(0)}
+}
+
+// SetConcurrency sets how many requests are evaluated in parallel; n < 1
+// keeps the default of GOMAXPROCS. Call it before Run.
+func (w *Worker) SetConcurrency(n int) {
+	if n >= 1 {
+		w.concurrency = n
+	}
+}
+
+// retryDelay is how long the worker waits after a broker error.
+const retryDelay = time.Second
+
+// Run processes requests until ctx is done. A batch already fetched is
+// evaluated and answered even if ctx ends meanwhile, so requests are not
+// lost on shutdown; a result that cannot be published by then is left
+// unacknowledged for the broker to redeliver.
+func (w *Worker) Run(ctx context.Context) error {
+	for {
+		batch, err := w.broker.Fetch(ctx, w.concurrency)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Printf("queue: fetch: %v", err)
+			if !sleep(ctx, retryDelay) {
+				return nil
+			}
+			continue
+		}
+		results := w.process(context.WithoutCancel(ctx), batch)
+		published := true
+		for i, d := range batch {
+			if !w.publish(ctx, d, results[i]) {
+				published = false
+				break
+			}
+		}
+		if !published {
+			return nil
+		}
+		if err := w.broker.Ack(context.WithoutCancel(ctx), batch); err != nil {
+			log.Printf("queue: ack: %v", err)
+		}
+	}
+}
+
+// process evaluates batch with bounded parallelism and encodes the results
+// in order.
+func (w *Worker) process(ctx context.Context, batch []Delivery) [][]byte {
+	results := make([][]byte, len(batch))
+	sem := make(chan struct{}, w.concurrency)
+	var wg sync.WaitGroup
+	for i, d := range batch {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, d Delivery) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i], _ = json.Marshal(w.handle(ctx, d.Data))
+		}(i, d)
+	}
+	wg.Wait()
+	return results
+}
+
+// publish sends one result, retrying until it succeeds or ctx is done.
+func (w *Worker) publish(ctx context.Context, d Delivery, result []byte) bool {
+	for {
+		err := w.broker.Publish(context.WithoutCancel(ctx), d, result)
+		if err == nil {
+			return true
+		}
+		log.Printf("queue: publish: %v", err)
+		if !sleep(ctx, retryDelay) {
+			return false
+		}
+	}
+}
+
+func (w *Worker) handle(ctx context.Context, data []byte) Result {
+	var req Request
+	if err := json.Unmarshal(data, &req); err != nil {
+		return Result{Error: err.Error()}
+	}
+	outputs, err := w.evaluate(ctx, req)
+	if err != nil {
+		return Result{ID: req.ID, Error: err.Error()}
+	}
+	return Result{ID: req.ID, Outputs: outputs}
+}
+
+func (w *Worker) evaluate(ctx context.Context, req Request) (map[string]Value, error) {
+	g := req.Graph
+	switch {
+	case req.Expression != "" && g != nil:
+		return nil, errors.New("give either expression or graph, not both")
+	case req.Expression != "":
+		var err error
+		if g, err = circuit.Parse(req.Expression); err != nil {
+			return nil, err
+		}
+	case g == nil:
+		return nil, errors.New("expression or graph is required")
+	}
+	inputs := make(map[string]circuit.Value, len(req.Inputs))
+	for name, in := range req.Inputs {
+		raw, err := base64.StdEncoding.DecodeString(in.Ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("input %q: %v", name, err)
+		}
+		inputs[name] = circuit.Value{Type: in.Type, Data: raw}
+	}
+	outputs, err := w.eval.Evaluate(ctx, req.KeyID, g, inputs)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]Value, len(outputs))
+	for name, v := range outputs {
+		out[name] = Value{Type: v.Type, Ciphertext: base64.StdEncoding.EncodeToString(v.Data)}
+	}
+	return out, nil
+}
+
+// sleep waits for d, reporting false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
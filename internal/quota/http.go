@@ -0,0 +1,123 @@
+package quota
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"tfhe-go/internal/apierror"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/ratelimit"
+	"tfhe-go/internal/tfhe"
+)
+
+// Subject returns the tenant and identity usage is charged to: the caller's
+// tenant and ID when authenticated, otherwise its rate-limit client key.
+func Subject(ctx context.Context, remoteAddr string) (tenant, identity string) {
+	if id, ok := auth.FromContext(ctx); ok && id.Tenant != "" {
+		return id.Tenant, id.ID
+	}
+	key := ratelimit.ClientKey(ctx, remoteAddr)
+	return key, key
+}
+
+// Middleware charges each request's operations, compute time and body bytes
+// to the caller's tenant, and rejects requests from tenants over a quota with
+// 429, Retry-After and X-Quota-* headers. Requests that are admitted get the
+// headers too, describing the quota before the request. The tenant is also
+// the one whose share of the tfhe compute limit the request's operations
+// take. It must run inside authentication. WebSocket sessions are charged their operations when they
+// close; frames sent over the hijacked connection are not counted as bytes.
+func (t *Tracker) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.IsPublic(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		tenant, identity := Subject(r.Context(), r.RemoteAddr)
+		now := time.Now()
+		t.setHeaders(w.Header(), tenant, now)
+		if exceeded := t.Check(tenant, now); exceeded != nil {
+			retry := math.Ceil(time.Until(exceeded.Reset).Seconds())
+			w.Header().Set("Retry-After", strconv.Itoa(int(max(retry, 1))))
+			apierror.Write(w, http.StatusTooManyRequests, apierror.CodeQuotaExceeded, exceeded.Error())
+			return
+		}
+
+		usage := &tfhe.Usage{}
+		body := &countingBody{ReadCloser: r.Body}
+		r.Body = body
+		cw := &countingWriter{ResponseWriter: w}
+		next.ServeHTTP(cw, r.WithContext(tfhe.WithTenant(tfhe.WithUsage(r.Context(), usage), tenant)))
+
+		t.Add(tenant, identity, Usage{
+			Operations:   usage.Ops(),
+			ComputeNanos: usage.Nanos(),
+			Bytes:        body.n + cw.n,
+		}, time.Now())
+	})
+}
+
+// setHeaders describes each configured quota of tenant as
+// X-Quota-<Period>-<Limit>-Limit and -Remaining, plus X-Quota-<Period>-Reset.
+func (t *Tracker) setHeaders(h http.Header, tenant string, now time.Time) {
+	r := t.Report(tenant, now)
+	for _, w := range []struct {
+		name string
+		w    *WindowReport
+	}{{"Daily", r.Daily}, {"Monthly", r.Monthly}} {
+		if w.w == nil || w.w.Limits == nil {
+			continue
+		}
+		prefix := "X-Quota-" + w.name + "-"
+		for _, v := range []struct {
+			name             string
+			limit, remaining int64
+		}{
+			{"Operations", w.w.Limits.Operations, w.w.Remaining.Operations},
+			{"Compute-Ms", w.w.Limits.ComputeNanos / 1e6, w.w.Remaining.ComputeNanos / 1e6},
+			{"Bytes", w.w.Limits.Bytes, w.w.Remaining.Bytes},
+		} {
+			if v.limit > 0 {
+				h.Set(prefix+v.name+"-Limit", strconv.FormatInt(v.limit, 10))
+				h.Set(prefix+v.name+"-Remaining", strconv.FormatInt(v.remaining, 10))
+			}
+		}
+		h.Set(prefix+"Reset", w.w.Reset.Format(time.RFC3339))
+	}
+}
+
+type countingBody struct {
+	io.ReadCloser
+	n int64
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+type countingWriter struct {
+	http.ResponseWriter
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+func (w *countingWriter) Flush() { _ = http.NewResponseController(w.ResponseWriter).Flush() }
+
+func (w *countingWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+func (w *countingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
@@ -0,0 +1,353 @@
+// Package quota accounts operations, compute time and bytes per tenant and
+// enforces daily and monthly limits on them.
+package quota
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Usage is what a tenant consumed: service operations, the wall time spent
+// in them, and request plus response bytes.
+type Usage struct {
+	Operations   int64 `json:"operations"`
+	ComputeNanos int64 `json:"compute_ns"`
+	Bytes        int64 `json:"bytes"`
+}
+
+func (u *Usage) add(v Usage) {
+	u.Operations += v.Operations
+	u.ComputeNanos += v.ComputeNanos
+	u.Bytes += v.Bytes
+}
+
+// Limits caps a tenant's usage within one period; zero fields are unlimited.
+type Limits Usage
+
+// IsZero reports whether l limits nothing.
+func (l Limits) IsZero() bool { return l == Limits{} }
+
+// ParseLimits parses a spec such as "operations=100000,compute=2h,bytes=10GiB".
+// Compute takes a Go duration; bytes accept a KiB, MiB, GiB or TiB suffix.
+// An empty spec means unlimited.
+func ParseLimits(spec string) (Limits, error) {
+	var l Limits
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return Limits{}, fmt.Errorf("quota %q: want name=value", part)
+		}
+		var err error
+		switch strings.TrimSpace(name) {
+		case "operations", "ops":
+			l.Operations, err = strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		case "compute":
+			var d time.Duration
+			d, err = time.ParseDuration(strings.TrimSpace(value))
+			l.ComputeNanos = int64(d)
+		case "bytes":
+			l.Bytes, err = parseBytes(strings.TrimSpace(value))
+		default:
+			return Limits{}, fmt.Errorf("quota %q: unknown limit %q (want operations, compute or bytes)", part, name)
+		}
+		if err != nil {
+			return Limits{}, fmt.Errorf("quota %q: %w", part, err)
+		}
+		if l.Operations < 0 || l.ComputeNanos < 0 || l.Bytes < 0 {
+			return Limits{}, fmt.Errorf("quota %q: must not be negative", part)
+		}
+	}
+	return l, nil
+}
+
+// String formats l in the syntax accepted by ParseLimits.
+func (l Limits) String() string {
+	var parts []string
+	if l.Operations > 0 {
+		parts = append(parts, "operations="+strconv.FormatInt(l.Operations, 10))
+	}
+	if l.ComputeNanos > 0 {
+		parts = append(parts, "compute="+time.Duration(l.ComputeNanos).String())
+	}
+	if l.Bytes > 0 {
+		parts = append(parts, "bytes="+strconv.FormatInt(l.Bytes, 10))
+	}
+	return strings.Join(parts, ",")
+}
+
+func parseBytes(v string) (int64, error) {
+	mult := int64(1)
+	for _, unit := range []struct {
+		suffix string
+		mult   int64
+	}{{"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if num, ok := strings.CutSuffix(v, unit.suffix); ok {
+			v, mult = strings.TrimSpace(num), unit.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return n * mult, nil
+}
+
+// Period names a quota window. Periods are calendar days and months in UTC.
+type Period string
+
+const (
+	Daily   Period = "daily"
+	Monthly Period = "monthly"
+)
+
+// Periods lists the quota windows in the order they are checked.
+var Periods = []Period{Daily, Monthly}
+
+// window returns the label of the window containing t, e.g. "2026-10-16" or
+// "2026-10", and when it ends.
+func (p Period) window(t time.Time) (string, time.Time) {
+	t = t.UTC()
+	if p == Daily {
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return start.Format(time.DateOnly), start.AddDate(0, 0, 1)
+	}
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start.Format("2006-01"), start.AddDate(0, 1, 0)
+}
+
+// Config sets the limits every tenant gets per period.
+type Config struct {
+	Daily   Limits
+	Monthly Limits
+}
+
+func (c Config) limits(p Period) Limits {
+	if p == Daily {
+		return c.Daily
+	}
+	return c.Monthly
+}
+
+// Exceeded describes a quota that has been used up.
+type Exceeded struct {
+	Period Period
+	Limit  string // "operations", "compute" or "bytes"
+	Reset  time.Time
+}
+
+func (e *Exceeded) Error() string {
+	return fmt.Sprintf("%s %s quota exceeded; resets at %s", e.Period, e.Limit, e.Reset.Format(time.RFC3339))
+}
+
+// Tracker keeps usage per tenant and caller identity in memory. Daily
+// windows are retained for 31 days and monthly ones for 13 months.
+type Tracker struct {
+	cfg Config
+
+	mu      sync.Mutex
+	windows map[Period]map[string]map[string]map[string]*Usage // period -> window -> tenant -> identity
+}
+
+// New returns a tracker enforcing cfg.
+func New(cfg Config) *Tracker {
+	return &Tracker{
+		cfg: cfg,
+		windows: map[Period]map[string]map[string]map[string]*Usage{
+			Daily:   {},
+			Monthly: {},
+		},
+	}
+}
+
+// Config returns the limits the tracker enforces.
+func (t *Tracker) Config() Config { return t.cfg }
+
+// Check returns the first quota tenant has used up at now, or nil.
+func (t *Tracker) Check(tenant string, now time.Time) *Exceeded {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range Periods {
+		limits := t.cfg.limits(p)
+		if limits.IsZero() {
+			continue
+		}
+		label, reset := p.window(now)
+		used := t.total(p, label, tenant)
+		switch {
+		case limits.Operations > 0 && used.Operations >= limits.Operations:
+			return &Exceeded{Period: p, Limit: "operations", Reset: reset}
+		case limits.ComputeNanos > 0 && used.ComputeNanos >= limits.ComputeNanos:
+			return &Exceeded{Period: p, Limit: "compute", Reset: reset}
+		case limits.Bytes > 0 && used.Bytes >= limits.Bytes:
+			return &Exceeded{Period: p, Limit: "bytes", Reset: reset}
+		}
+	}
+	return nil
+}
+
+// Add charges u to tenant and identity id in the windows containing now.
+func (t *Tracker) Add(tenant, id string, u Usage, now time.Time) {
+	if u == (Usage{}) {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, p := range Periods {
+		label, _ := p.window(now)
+		windows := t.windows[p]
+		tenants, ok := windows[label]
+		if !ok {
+			tenants = make(map[string]map[string]*Usage)
+			windows[label] = tenants
+			t.prune(p, now)
+		}
+		ids, ok := tenants[tenant]
+		if !ok {
+			ids = make(map[string]*Usage)
+			tenants[tenant] = ids
+		}
+		acc, ok := ids[id]
+		if !ok {
+			acc = &Usage{}
+			ids[id] = acc
+		}
+		acc.add(u)
+	}
+}
+
+// prune drops windows past retention. t.mu must be held.
+func (t *Tracker) prune(p Period, now time.Time) {
+	cutoff, _ := p.window(now.AddDate(0, 0, -31))
+	if p == Monthly {
+		cutoff, _ = p.window(now.AddDate(0, -13, 0))
+	}
+	for label := range t.windows[p] {
+		// Labels are ISO dates, so they sort chronologically as strings.
+		if label < cutoff {
+			delete(t.windows[p], label)
+		}
+	}
+}
+
+// total sums a tenant's usage in one window. t.mu must be held.
+func (t *Tracker) total(p Period, label, tenant string) Usage {
+	var sum Usage
+	for _, u := range t.windows[p][label][tenant] {
+		sum.add(*u)
+	}
+	return sum
+}
+
+// WindowReport is a tenant's usage within one window.
+type WindowReport struct {
+	Window    string           `json:"window"`
+	Usage     Usage            `json:"usage"`
+	Limits    *Limits          `json:"limits,omitempty"`
+	Remaining *Usage           `json:"remaining,omitempty"`
+	Reset     *time.Time       `json:"reset,omitempty"`
+	ByKey     map[string]Usage `json:"by_key,omitempty"`
+}
+
+// Report is a tenant's usage in the current windows, or in the windows
+// labelled by a requested period.
+type Report struct {
+	Tenant  string        `json:"tenant"`
+	Daily   *WindowReport `json:"daily,omitempty"`
+	Monthly *WindowReport `json:"monthly,omitempty"`
+}
+
+// Report describes tenant's usage in the windows containing now.
+func (t *Tracker) Report(tenant string, now time.Time) Report {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	r := Report{Tenant: tenant}
+	for _, p := range Periods {
+		label, reset := p.window(now)
+		w := t.window(p, label, tenant)
+		if limits := t.cfg.limits(p); !limits.IsZero() {
+			remaining := remaining(limits, w.Usage)
+			w.Limits, w.Remaining, w.Reset = &limits, &remaining, &reset
+		}
+		if p == Daily {
+			r.Daily = w
+		} else {
+			r.Monthly = w
+		}
+	}
+	return r
+}
+
+// History returns every tenant's usage in the retained window labelled
+// label: a day such as "2026-10-16" or a month such as "2026-10". Reports
+// are ordered by tenant.
+func (t *Tracker) History(label string) ([]Report, error) {
+	p := Daily
+	if _, err := time.Parse(time.DateOnly, label); err != nil {
+		if _, err := time.Parse("2006-01", label); err != nil {
+			return nil, fmt.Errorf("period %q: want YYYY-MM-DD or YYYY-MM", label)
+		}
+		p = Monthly
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var out []Report
+	for tenant := range t.windows[p][label] {
+		r := Report{Tenant: tenant}
+		if p == Daily {
+			r.Daily = t.window(p, label, tenant)
+		} else {
+			r.Monthly = t.window(p, label, tenant)
+		}
+		out = append(out, r)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tenant < out[j].Tenant })
+	return out, nil
+}
+
+// Tenants lists the tenants with usage in the current month.
+func (t *Tracker) Tenants(now time.Time) []string {
+	label, _ := Monthly.window(now)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	out := make([]string, 0, len(t.windows[Monthly][label]))
+	for tenant := range t.windows[Monthly][label] {
+		out = append(out, tenant)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// window builds a report without limits. t.mu must be held.
+func (t *Tracker) window(p Period, label, tenant string) *WindowReport {
+	w := &WindowReport{Window: label, Usage: t.total(p, label, tenant)}
+	if ids := t.windows[p][label][tenant]; len(ids) > 0 {
+		w.ByKey = make(map[string]Usage, len(ids))
+		for id, u := range ids {
+			w.ByKey[id] = *u
+		}
+	}
+	return w
+}
+
+func remaining(l Limits, used Usage) Usage {
+	left := func(limit, used int64) int64 {
+		if limit == 0 {
+			return 0
+		}
+		return max(limit-used, 0)
+	}
+	return Usage{
+		Operations:   left(l.Operations, used.Operations),
+		ComputeNanos: left(l.ComputeNanos, used.ComputeNanos),
+		Bytes:        left(l.Bytes, used.Bytes),
+	}
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"tfhe-go/internal/apierror"
+	"tfhe-go/internal/auth"
+)
+
+// idleTTL is how long an untouched client bucket is kept before it is
+// dropped; a dropped bucket is recreated full, which is what it would have
+// refilled to anyway.
+const idleTTL = 10 * time.Minute
+
+// Limiter keeps one token bucket per client.
+type Limiter struct {
+	limit rate.Limit
+	burst int
+
+	mu        sync.Mutex
+	clients   map[string]*client
+	lastSweep time.Time
+}
+
+type client struct {
+	bucket   *rate.Limiter
+	lastSeen time.Time
+}
+
+// New returns a limiter refilling each client's bucket at rps requests per
+// second up to burst.
+func New(rps float64, burst int) *Limiter {
+	if burst < 1 {
+		burst = int(math.Max(1, math.Ceil(rps)))
+	}
+	return &Limiter{
+		limit:   rate.Limit(rps),
+		burst:   burst,
+		clients: make(map[string]*client),
+	}
+}
+
+// Allow takes a token from key's bucket. When the bucket is empty it reports
+// how long until the next token is available.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+	now := time.Now()
+	l.mu.Lock()
+	l.sweep(now)
+	c, ok := l.clients[key]
+	if !ok {
+		c = &client{bucket: rate.NewLimiter(l.limit, l.burst)}
+		l.clients[key] = c
+	}
+	c.lastSeen = now
+	l.mu.Unlock()
+
+	res := c.bucket.ReserveN(now, 1)
+	if !res.OK() {
+		return false, time.Second
+	}
+	if delay := res.DelayFrom(now); delay > 0 {
+		res.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// sweep drops idle clients at most once per TTL. l.mu must be held.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.lastSweep) < idleTTL {
+		return
+	}
+	l.lastSweep = now
+	for key, c := range l.clients {
+		if now.Sub(c.lastSeen) > idleTTL {
+			delete(l.clients, key)
+		}
+	}
+}
+
+// Middleware rejects requests over the caller's budget with 429 and a
+// Retry-After header. Authenticated callers are limited per identity,
+// anonymous ones per remote IP; public paths are not limited.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.IsPublic(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ok, retry := l.Allow(ClientKey(r.Context(), r.RemoteAddr))
+		if !ok {
+			writeTooManyRequests(w, retry)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ClientKey identifies a caller for rate limiting: by authenticated identity
+// when ctx carries one, otherwise by the host part of remoteAddr.
+func ClientKey(ctx context.Context, remoteAddr string) string {
+	if id, ok := auth.FromContext(ctx); ok && id.ID != "" {
+		return "id:" + id.ID
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	return "ip:" + host
+}
+
+func writeTooManyRequests(w http.ResponseWriter, retry time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retry.Seconds()))))
+	apierror.Write(w, http.StatusTooManyRequests, apierror.CodeRateLimited, "rate limit exceeded")
+}
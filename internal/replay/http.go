@@ -0,0 +1,121 @@
+package replay
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"tfhe-go/internal/apierror"
+	"tfhe-go/internal/auth"
+)
+
+// Middleware requires a valid signature on every POST, PUT, PATCH and
+// DELETE request to a non-public path. Missing or malformed headers get
+// 400, stale timestamps and bad signatures 403, reused nonces 409. JSON and
+// form bodies are read and checked against their digest before the request
+// runs; binary uploads are checked as the handler reads them and fail at
+// the end of the body.
+func (g *Guard) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.IsPublic(r.URL.Path) || !mutating(r.Method) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		digest, err := g.Check(r.Method, r.URL.RequestURI(), r.Header, time.Now())
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		if streamed(r.Header.Get("Content-Type")) {
+			r.Body = &digestBody{ReadCloser: r.Body, h: sha256.New(), want: digest}
+			next.ServeHTTP(w, r)
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, g.opts.MaxBodyBytes))
+		if err != nil {
+			status := http.StatusBadRequest
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				status = http.StatusRequestEntityTooLarge
+			}
+			writeError(w, status, err)
+			return
+		}
+		if sum := sha256.Sum256(body); !bytes.Equal(sum[:], digest) {
+			writeError(w, http.StatusBadRequest, ErrDigest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next.ServeHTTP(w, r)
+	})
+}
+
+func statusFor(err error) int {
+	switch {
+	case errors.Is(err, ErrStale), errors.Is(err, ErrSignature):
+		return http.StatusForbidden
+	case errors.Is(err, ErrReplayed):
+		return http.StatusConflict
+	case errors.Is(err, ErrFull):
+		return http.StatusServiceUnavailable
+	}
+	return http.StatusBadRequest
+}
+
+// codeFor returns the error code of err, which statusFor mapped to status.
+func codeFor(err error, status int) string {
+	switch {
+	case errors.Is(err, ErrMissing):
+		return "signature_missing"
+	case errors.Is(err, ErrStale):
+		return "signature_stale"
+	case errors.Is(err, ErrSignature):
+		return "signature_invalid"
+	case errors.Is(err, ErrReplayed):
+		return "request_replayed"
+	case errors.Is(err, ErrDigest):
+		return "digest_mismatch"
+	}
+	return apierror.CodeFor(status)
+}
+
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// streamed reports whether a body of contentType may be too large to
+// buffer: binary and multipart uploads.
+func streamed(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	return mediaType == "application/octet-stream" || mediaType == "multipart/form-data"
+}
+
+// digestBody hashes a body as it is read and fails the read that reaches
+// its end when the hash differs from want.
+type digestBody struct {
+	io.ReadCloser
+	h    hash.Hash
+	want []byte
+}
+
+func (b *digestBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.h.Write(p[:n])
+	if err == io.EOF && !bytes.Equal(b.h.Sum(nil), b.want) {
+		return n, ErrDigest
+	}
+	return n, err
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	apierror.Write(w, status, codeFor(err, status), err.Error())
+}
@@ -0,0 +1,179 @@
+// Package replay rejects mutating requests that were captured and sent
+// again. Clients sign each request, with a shared key, over its method,
+// URI, a timestamp, a single-use nonce and the SHA-256 of its body; the
+// server refuses stale timestamps, bad signatures and nonces it has seen.
+package replay
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Headers carrying a request's signature.
+const (
+	// TimestampHeader is the signing time in Unix seconds.
+	TimestampHeader = "Request-Timestamp"
+	// NonceHeader is a value the client never reuses, e.g. 16 random bytes
+	// in hex.
+	NonceHeader = "Request-Nonce"
+	// DigestHeader is the hex SHA-256 of the body as sent, empty bodies
+	// included.
+	DigestHeader = "Request-Body-Digest"
+	// SignatureHeader is the hex HMAC-SHA-256 of Canonical.
+	SignatureHeader = "Request-Signature"
+)
+
+// Nonce lengths accepted.
+const (
+	minNonce = 16
+	maxNonce = 128
+)
+
+var (
+	// ErrMissing is returned for requests without valid signature headers.
+	ErrMissing = errors.New("missing or malformed request signature")
+	// ErrStale is returned for timestamps outside the window.
+	ErrStale = errors.New("request timestamp outside the replay window")
+	// ErrSignature is returned for signatures that do not verify.
+	ErrSignature = errors.New("request signature does not verify")
+	// ErrReplayed is returned for nonces seen within the window.
+	ErrReplayed = errors.New("request nonce already used")
+	// ErrDigest is returned when the body does not match its digest.
+	ErrDigest = errors.New("request body does not match its digest")
+	// ErrFull is returned while the guard remembers Options.MaxNonces
+	// nonces that have not expired.
+	ErrFull = errors.New("too many recent requests to track nonces")
+)
+
+// Options configures a Guard.
+type Options struct {
+	// Key signs requests; it is required.
+	Key []byte
+	// Window is how far a timestamp may be from the server's clock, either
+	// way; defaults to 5 minutes.
+	Window time.Duration
+	// MaxNonces bounds the nonces remembered; defaults to 1<<20.
+	MaxNonces int
+	// MaxBodyBytes bounds the bodies buffered to check their digest before
+	// the request runs; defaults to 64 MiB.
+	MaxBodyBytes int64
+}
+
+// Guard checks request signatures and remembers nonces until their
+// timestamps leave the window.
+type Guard struct {
+	opts Options
+
+	mu        sync.Mutex
+	seen      map[string]time.Time // nonce to expiry
+	lastSweep time.Time
+}
+
+// New returns a Guard.
+func New(opts Options) (*Guard, error) {
+	if len(opts.Key) == 0 {
+		return nil, errors.New("replay: a signing key is required")
+	}
+	if opts.Window <= 0 {
+		opts.Window = 5 * time.Minute
+	}
+	if opts.MaxNonces <= 0 {
+		opts.MaxNonces = 1 << 20
+	}
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 64 << 20
+	}
+	return &Guard{opts: opts, seen: make(map[string]time.Time)}, nil
+}
+
+// Canonical returns the string a request's signature covers: the method,
+// the request URI (path and query, as sent), the timestamp, the nonce and
+// the body digest, each followed by a newline.
+func Canonical(method, uri, timestamp, nonce, digest string) []byte {
+	var b bytes.Buffer
+	for _, s := range []string{method, uri, timestamp, nonce, digest} {
+		b.WriteString(s)
+		b.WriteByte('\n')
+	}
+	return b.Bytes()
+}
+
+// Sign sets the signature headers on r for body at now; nonce must never
+// be reused with key.
+func Sign(r *http.Request, key, body []byte, nonce string, now time.Time) {
+	sum := sha256.Sum256(body)
+	digest := hex.EncodeToString(sum[:])
+	ts := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, key)
+	mac.Write(Canonical(r.Method, r.URL.RequestURI(), ts, nonce, digest))
+	r.Header.Set(TimestampHeader, ts)
+	r.Header.Set(NonceHeader, nonce)
+	r.Header.Set(DigestHeader, digest)
+	r.Header.Set(SignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+}
+
+// Check verifies the signature headers of a request for uri at now and
+// consumes its nonce. It returns the body digest the caller must still
+// compare with the body.
+func (g *Guard) Check(method, uri string, h http.Header, now time.Time) ([]byte, error) {
+	ts, nonce, digestHex := h.Get(TimestampHeader), h.Get(NonceHeader), h.Get(DigestHeader)
+	digest, err := hex.DecodeString(digestHex)
+	if err != nil || len(digest) != sha256.Size {
+		return nil, fmt.Errorf("%w: %s must be a hex SHA-256", ErrMissing, DigestHeader)
+	}
+	sig, err := hex.DecodeString(h.Get(SignatureHeader))
+	if err != nil || len(sig) != sha256.Size {
+		return nil, fmt.Errorf("%w: %s must be a hex HMAC-SHA-256", ErrMissing, SignatureHeader)
+	}
+	if len(nonce) < minNonce || len(nonce) > maxNonce {
+		return nil, fmt.Errorf("%w: %s must be %d to %d characters", ErrMissing, NonceHeader, minNonce, maxNonce)
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s must be Unix seconds", ErrMissing, TimestampHeader)
+	}
+	signed := time.Unix(unix, 0)
+	if signed.Before(now.Add(-g.opts.Window)) || signed.After(now.Add(g.opts.Window)) {
+		return nil, fmt.Errorf("%w of %s", ErrStale, g.opts.Window)
+	}
+	mac := hmac.New(sha256.New, g.opts.Key)
+	mac.Write(Canonical(method, uri, ts, nonce, digestHex))
+	if !hmac.Equal(mac.Sum(nil), sig) {
+		return nil, ErrSignature
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if expires, ok := g.seen[nonce]; ok && now.Before(expires) {
+		return nil, ErrReplayed
+	}
+	if len(g.seen) >= g.opts.MaxNonces {
+		g.sweep(now)
+		if len(g.seen) >= g.opts.MaxNonces {
+			return nil, ErrFull
+		}
+	} else if now.Sub(g.lastSweep) >= time.Minute {
+		g.sweep(now)
+	}
+	// Past the window the timestamp check rejects the request anyway.
+	g.seen[nonce] = signed.Add(g.opts.Window + time.Second)
+	return digest, nil
+}
+
+// sweep forgets expired nonces. g.mu must be held.
+func (g *Guard) sweep(now time.Time) {
+	g.lastSweep = now
+	for nonce, expires := range g.seen {
+		if !now.Before(expires) {
+			delete(g.seen, nonce)
+		}
+	}
+}
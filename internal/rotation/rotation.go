@@ -0,0 +1,229 @@
+package rotation
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"tfhe-go/internal/audit"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/store"
+	"tfhe-go/internal/tfhe"
+)
+
+// ErrInProgress is returned when a rotation is requested while one is running.
+var ErrInProgress = errors.New("key rotation already in progress")
+
+// State describes the lifecycle of a rotation run.
+type State string
+
+const (
+	StateIdle      State = "idle"
+	StateRunning   State = "running"
+	StateCompleted State = "completed"
+	StateFailed    State = "failed"
+)
+
+// Status reports the progress of the current or last rotation.
+type Status struct {
+	State      State     `json:"state"`
+	Phase      string    `json:"phase,omitempty"`
+	Total      int       `json:"total"`
+	Done       int       `json:"done"`
+	Failed     int       `json:"failed"`
+	StartedAt  time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Manager rotates the keys of one key set and re-encrypts the stored
+// ciphertexts under that set with the new keys.
+type Manager struct {
+	keySet  string
+	boolean *tfhe.BooleanService
+	uint8   *tfhe.Uint8Service
+	store   store.Store
+	persist func() error
+	audit   *audit.Logger
+
+	// writes is held by writers to the store for reading and by a rotation
+	// for writing, so nothing is stored between its listing of the store
+	// and the key swap.
+	writes sync.RWMutex
+
+	mu     sync.Mutex
+	status Status
+}
+
+// NewManager builds a rotation manager over the services of the key set
+// with ID keySet and the store.
+func NewManager(keySet string, booleanService *tfhe.BooleanService, uint8Service *tfhe.Uint8Service, ciphertextStore store.Store) *Manager {
+	return &Manager{
+		keySet:  keySet,
+		boolean: booleanService,
+		uint8:   uint8Service,
+		store:   ciphertextStore,
+		status:  Status{State: StateIdle},
+	}
+}
+
+// SetPersist installs fn to run after the keys rotate, e.g. to write them to
+// a key store. If it fails the rotation is reported failed, although the new
+// keys stay in use. Call it before Start.
+func (m *Manager) SetPersist(fn func() error) {
+	m.persist = fn
+}
+
+// SetAudit records the outcome of each rotation to l. Call it before Start.
+func (m *Manager) SetAudit(l *audit.Logger) {
+	m.audit = l
+}
+
+// Start launches a rotation in the background. Progress is available via Status.
+func (m *Manager) Start() (Status, error) {
+	m.mu.Lock()
+	if m.status.State == StateRunning {
+		st := m.status
+		m.mu.Unlock()
+		return st, ErrInProgress
+	}
+	m.status = Status{State: StateRunning, StartedAt: time.Now().UTC()}
+	st := m.status
+	m.mu.Unlock()
+
+	go m.run()
+	return st, nil
+}
+
+// HoldWrites keeps a rotation from starting until the returned func is
+// called, and waits for a running one to finish. Writers to the store hold
+// it from the moment they read keys or stored operands until their result
+// is stored: anything stored while a rotation lists the store and swaps the
+// keys would stay under the old keys, undecryptable under the new ones.
+// It is a no-op on a nil Manager.
+func (m *Manager) HoldWrites() (release func()) {
+	if m == nil {
+		return func() {}
+	}
+	m.writes.RLock()
+	return m.writes.RUnlock
+}
+
+// Status returns a snapshot of the current or last rotation.
+func (m *Manager) Status() Status {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.status
+}
+
+func (m *Manager) run() {
+	err := m.rotate()
+
+	m.mu.Lock()
+	m.status.FinishedAt = time.Now().UTC()
+	m.status.Phase = ""
+	e := audit.Event{
+		Action:        audit.KeyRotate,
+		Op:            "rotation",
+		Outcome:       audit.Success,
+		DurationNanos: int64(m.status.FinishedAt.Sub(m.status.StartedAt)),
+	}
+	if err != nil {
+		m.status.State = StateFailed
+		m.status.Error = err.Error()
+		e.Outcome, e.Error = audit.Failure, err.Error()
+	} else {
+		m.status.State = StateCompleted
+	}
+	m.mu.Unlock()
+	_ = m.audit.Record(e)
+}
+
+func (m *Manager) rotate() error {
+	if err := m.swapKeys(); err != nil {
+		return err
+	}
+	if m.persist != nil {
+		if err := m.persist(); err != nil {
+			return fmt.Errorf("persist rotated keys: %w", err)
+		}
+	}
+	return nil
+}
+
+// swapKeys rotates both services with writes to the store held off.
+func (m *Manager) swapKeys() error {
+	m.writes.Lock()
+	defer m.writes.Unlock()
+	// The key set's generation is its uint8 service's, which counts this
+	// rotation once the service has swapped its keys.
+	next := store.KeyRef{KeySet: m.keySet, Generation: m.uint8.KeyGeneration() + 1}
+	if err := m.boolean.Rotate(m.migrate("boolean", next)); err != nil {
+		return fmt.Errorf("rotate boolean keys: %w", err)
+	}
+	if err := m.uint8.Rotate(m.migrate("uint8", next)); err != nil {
+		return fmt.Errorf("rotate uint8 keys: %w", err)
+	}
+	return nil
+}
+
+// migrate re-encrypts every stored entry of typ under the manager's key set
+// with sw, recording them under next. It lists the store
+// while the service is paused and writers holding HoldWrites are kept out,
+// so no new entry of that type is missed, and
+// only writes back once all entries have been switched, so a failed rotation
+// leaves the store untouched. Entries of other key sets are left alone: sw
+// could not decrypt them, and re-encrypting them would lose them.
+func (m *Manager) migrate(typ string, next store.KeyRef) func(tfhe.KeySwitchFunc) error {
+	return func(sw tfhe.KeySwitchFunc) error {
+		all, err := m.store.List()
+		if err != nil {
+			return fmt.Errorf("list ciphertexts: %w", err)
+		}
+		var entries []store.Entry
+		for _, e := range all {
+			if e.Type == typ && m.owns(e) {
+				entries = append(entries, e)
+			}
+		}
+		m.update(func(st *Status) {
+			st.Phase = typ
+			st.Total += len(entries)
+		})
+
+		switched := make(map[string][]byte, len(entries))
+		for _, e := range entries {
+			data, err := sw(e.Data)
+			if err != nil {
+				// Undecryptable entries cannot be recovered under either key.
+				m.update(func(st *Status) { st.Failed++ })
+				continue
+			}
+			switched[e.ID] = data
+			m.update(func(st *Status) { st.Done++ })
+		}
+		for id, data := range switched {
+			if err := m.store.Replace(id, next, data); err != nil && !errors.Is(err, store.ErrNotFound) {
+				return fmt.Errorf("store %s: %w", id, err)
+			}
+		}
+		return nil
+	}
+}
+
+// owns reports whether e is encrypted under the manager's key set. Entries
+// stored before their key set was recorded are taken to be under the
+// default set, which rotation has always re-encrypted them with.
+func (m *Manager) owns(e store.Entry) bool {
+	if e.KeySet == "" {
+		return m.keySet == keys.DefaultID
+	}
+	return e.KeySet == m.keySet
+}
+
+func (m *Manager) update(fn func(*Status)) {
+	m.mu.Lock()
+	fn(&m.status)
+	m.mu.Unlock()
+}
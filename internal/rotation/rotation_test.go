@@ -0,0 +1,190 @@
+package rotation
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/store"
+	"tfhe-go/internal/tfhe"
+)
+
+// pausingStore stops the first List until resume is closed, holding a
+// rotation between its listing of the store and the key swap.
+type pausingStore struct {
+	store.Store
+	once   sync.Once
+	listed chan struct{}
+	resume chan struct{}
+}
+
+func (s *pausingStore) List() ([]store.Entry, error) {
+	s.once.Do(func() {
+		close(s.listed)
+		<-s.resume
+	})
+	return s.Store.List()
+}
+
+var defaultKeys = store.KeyRef{KeySet: keys.DefaultID}
+
+// wait waits for m's rotation to complete and checks it migrated done
+// entries.
+func wait(t *testing.T, m *Manager, done int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Minute)
+	for m.Status().State == StateRunning {
+		if time.Now().After(deadline) {
+			t.Fatal("rotation did not finish")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if st := m.Status(); st.State != StateCompleted || st.Done != done || st.Failed != 0 {
+		t.Fatalf("rotation status %+v, want completed with %d entries migrated", st, done)
+	}
+}
+
+func TestUploadDuringRotation(t *testing.T) {
+	ctx := context.Background()
+	boolean, err := tfhe.NewBooleanService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boolean.Close()
+	uint8Service, err := tfhe.NewUint8Service()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer uint8Service.Close()
+
+	mem := store.NewMemory(store.MemoryOptions{})
+	data, err := boolean.EncryptRaw(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := mem.Put("tenant", "boolean", defaultKeys, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	st := &pausingStore{Store: mem, listed: make(chan struct{}), resume: make(chan struct{})}
+	m := NewManager(keys.DefaultID, boolean, uint8Service, st)
+	if _, err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	<-st.listed
+
+	// An upload as the handlers make it: keys are read and the result
+	// stored with writes held.
+	uploaded := make(chan error, 1)
+	go func() {
+		defer m.HoldWrites()()
+		data, err := boolean.EncryptRaw(ctx, false)
+		if err == nil {
+			_, err = mem.Put("tenant", "boolean", defaultKeys, data)
+		}
+		uploaded <- err
+	}()
+	select {
+	case err := <-uploaded:
+		t.Fatalf("upload finished while the rotation was listing the store: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(st.resume)
+	if err := <-uploaded; err != nil {
+		t.Fatal(err)
+	}
+
+	wait(t, m, 1)
+
+	entries, err := mem.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("store holds %d entries, want 2", len(entries))
+	}
+	for _, e := range entries {
+		v, err := boolean.DecryptRaw(ctx, e.Data)
+		if err != nil {
+			t.Fatalf("entry %s under the new keys: %v", e.ID, err)
+		}
+		if want := e.ID == before.ID; v != want {
+			t.Errorf("entry %s decrypts to %v, want %v", e.ID, v, want)
+		}
+	}
+}
+
+// Rotating one key set re-encrypts its handles, and those stored before key
+// sets were recorded, but leaves another set's handles decryptable under
+// that set's keys.
+func TestRotationKeepsOtherKeySets(t *testing.T) {
+	ctx := context.Background()
+	boolean, err := tfhe.NewBooleanService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer boolean.Close()
+	uint8Service, err := tfhe.NewUint8Service()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer uint8Service.Close()
+	session, err := tfhe.NewBooleanService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+
+	mem := store.NewMemory(store.MemoryOptions{})
+	put := func(s *tfhe.BooleanService, key store.KeyRef, v bool) store.Entry {
+		t.Helper()
+		data, err := s.EncryptRaw(ctx, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := mem.Put("tenant", "boolean", key, data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	owned := put(boolean, defaultKeys, true)
+	legacy := put(boolean, store.KeyRef{}, false)
+	other := put(session, store.KeyRef{KeySet: "session"}, true)
+
+	m := NewManager(keys.DefaultID, boolean, uint8Service, mem)
+	if _, err := m.Start(); err != nil {
+		t.Fatal(err)
+	}
+	wait(t, m, 2)
+
+	for _, tc := range []struct {
+		name    string
+		entry   store.Entry
+		keys    *tfhe.BooleanService
+		want    bool
+		rotated bool
+	}{
+		{name: "owned", entry: owned, keys: boolean, want: true, rotated: true},
+		{name: "legacy", entry: legacy, keys: boolean, want: false, rotated: true},
+		{name: "other key set", entry: other, keys: session, want: true},
+	} {
+		e, err := mem.Get(tc.entry.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		v, err := tc.keys.DecryptRaw(ctx, e.Data)
+		if err != nil || v != tc.want {
+			t.Errorf("%s: decrypts to %v, %v, want %v", tc.name, v, err, tc.want)
+		}
+		want := tc.entry.KeyRef
+		if tc.rotated {
+			want = store.KeyRef{KeySet: keys.DefaultID, Generation: 1}
+		}
+		if e.KeyRef != want {
+			t.Errorf("%s: stored under %+v, want %+v", tc.name, e.KeyRef, want)
+		}
+	}
+}
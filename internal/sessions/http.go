@@ -0,0 +1,48 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"tfhe-go/internal/apierror"
+	"tfhe-go/internal/auth"
+)
+
+// Header names the session a request computes under.
+const Header = "Session-Id"
+
+// Select returns ctx with the caller's identity switched to the key set of
+// session id. It must run after authentication, so that only the tenant
+// that created a session may use it.
+func (m *Manager) Select(ctx context.Context, id string) (context.Context, error) {
+	caller, _ := auth.FromContext(ctx)
+	if _, err := m.Get(id, caller.Tenant); err != nil {
+		return nil, err
+	}
+	caller.KeyID = id
+	return auth.WithIdentity(ctx, caller), nil
+}
+
+// Middleware selects the session a request names in the Session-Id header,
+// rejecting unknown, expired and foreign sessions with 403. It must run
+// inside authentication.
+func (m *Manager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(Header)
+		if id == "" || auth.IsPublic(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ctx, err := m.Select(r.Context(), id)
+		if err != nil {
+			code := apierror.CodeForbidden
+			if errors.Is(err, ErrUnknownSession) {
+				code = "unknown_session"
+			}
+			apierror.Write(w, http.StatusForbidden, code, err.Error())
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
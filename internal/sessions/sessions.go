@@ -0,0 +1,290 @@
+// Package sessions issues short-lived key sets: a session generates its own
+// keys, is selected per request, and on close or expiry destroys the keys
+// and deletes every handle stored under them.
+package sessions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/store"
+)
+
+// IDPrefix starts every session ID, which is also its key set's ID.
+const IDPrefix = "session-"
+
+var (
+	// ErrUnknownSession is returned for sessions that do not exist, have
+	// closed or expired, or belong to another tenant.
+	ErrUnknownSession = errors.New("unknown session")
+	// ErrTooManySessions is returned when a tenant already holds
+	// Options.MaxPerTenant sessions.
+	ErrTooManySessions = errors.New("too many open sessions")
+	// ErrInvalidTTL is returned for a TTL that is negative or above
+	// Options.MaxTTL.
+	ErrInvalidTTL = errors.New("invalid session ttl")
+)
+
+// Options bounds sessions.
+type Options struct {
+	// DefaultTTL applies when a session is created without one; defaults
+	// to 15 minutes.
+	DefaultTTL time.Duration
+	// MaxTTL caps a session's TTL; defaults to 24 hours.
+	MaxTTL time.Duration
+	// MaxPerTenant caps the open sessions of one tenant; 0 is unlimited.
+	MaxPerTenant int
+}
+
+// Info describes a session.
+type Info struct {
+	ID        string    `json:"id"`
+	Tenant    string    `json:"tenant,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Handles counts the stored handles deleted when the session ends.
+	Handles int `json:"handles"`
+}
+
+type session struct {
+	Info
+	handles map[string]struct{}
+}
+
+// Manager creates sessions, registering their key sets in a registry, and
+// ends them.
+type Manager struct {
+	keys     *keys.Registry
+	generate func() (*keys.KeySet, error)
+	store    store.Store
+	opts     Options
+	// forget drops the ACL of a deleted handle; nil when there is none.
+	forget func(handle string)
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	pending  map[string]int // sessions being generated, by tenant
+}
+
+// New returns a manager generating session keys with generate and deleting
+// their handles from ciphertextStore, which may be nil.
+func New(registry *keys.Registry, generate func() (*keys.KeySet, error), ciphertextStore store.Store, opts Options) *Manager {
+	if opts.DefaultTTL <= 0 {
+		opts.DefaultTTL = 15 * time.Minute
+	}
+	if opts.MaxTTL <= 0 {
+		opts.MaxTTL = 24 * time.Hour
+	}
+	opts.DefaultTTL = min(opts.DefaultTTL, opts.MaxTTL)
+	return &Manager{
+		keys:     registry,
+		generate: generate,
+		store:    ciphertextStore,
+		opts:     opts,
+		sessions: make(map[string]*session),
+		pending:  make(map[string]int),
+	}
+}
+
+// SetForget registers fn to be called for every handle a session deletes,
+// e.g. to drop its ACL.
+func (m *Manager) SetForget(fn func(handle string)) { m.forget = fn }
+
+// Create generates a key set for a new session of tenant lasting ttl, or
+// Options.DefaultTTL when ttl is zero.
+func (m *Manager) Create(tenant string, ttl time.Duration) (Info, error) {
+	if ttl == 0 {
+		ttl = m.opts.DefaultTTL
+	}
+	if ttl < 0 || ttl > m.opts.MaxTTL {
+		return Info{}, fmt.Errorf("%w: %s (maximum %s)", ErrInvalidTTL, ttl, m.opts.MaxTTL)
+	}
+	// Generation is slow, so the slot is reserved while it runs.
+	m.mu.Lock()
+	if m.opts.MaxPerTenant > 0 && m.count(tenant)+m.pending[tenant] >= m.opts.MaxPerTenant {
+		m.mu.Unlock()
+		return Info{}, fmt.Errorf("%w: tenant holds %d", ErrTooManySessions, m.opts.MaxPerTenant)
+	}
+	m.pending[tenant]++
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		if m.pending[tenant]--; m.pending[tenant] == 0 {
+			delete(m.pending, tenant)
+		}
+		m.mu.Unlock()
+	}()
+
+	id, err := newID()
+	if err != nil {
+		return Info{}, err
+	}
+	ks, err := m.generate()
+	if err != nil {
+		return Info{}, err
+	}
+	ks.ID, ks.Ephemeral = id, true
+	if err := m.keys.Register(ks); err != nil {
+		_ = ks.Close()
+		return Info{}, err
+	}
+	now := time.Now().UTC()
+	s := &session{
+		Info:    Info{ID: id, Tenant: tenant, CreatedAt: now, ExpiresAt: now.Add(ttl)},
+		handles: make(map[string]struct{}),
+	}
+	m.mu.Lock()
+	m.sessions[id] = s
+	m.mu.Unlock()
+	return s.Info, nil
+}
+
+// count returns the open sessions of tenant; m.mu must be held.
+func (m *Manager) count(tenant string) int {
+	n := 0
+	for _, s := range m.sessions {
+		if s.Tenant == tenant {
+			n++
+		}
+	}
+	return n
+}
+
+// Get returns the open session id of tenant.
+func (m *Manager) Get(id, tenant string) (Info, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s, ok := m.sessions[id]
+	if !ok || s.Tenant != tenant || !time.Now().Before(s.ExpiresAt) {
+		return Info{}, fmt.Errorf("%w: %q", ErrUnknownSession, id)
+	}
+	return s.info(), nil
+}
+
+// List returns the open sessions of tenant, oldest first.
+func (m *Manager) List(tenant string) []Info {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	out := []Info{}
+	for _, s := range m.sessions {
+		if s.Tenant == tenant && now.Before(s.ExpiresAt) {
+			out = append(out, s.info())
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out
+}
+
+func (s *session) info() Info {
+	info := s.Info
+	info.Handles = len(s.handles)
+	return info
+}
+
+// Track records that handle was stored under the key set keySetID, so it is
+// deleted with the session. Handles of other key sets are ignored.
+func (m *Manager) Track(keySetID, handle string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if s, ok := m.sessions[keySetID]; ok {
+		s.handles[handle] = struct{}{}
+	}
+}
+
+// Close ends the session id of tenant.
+func (m *Manager) Close(id, tenant string) error {
+	m.mu.Lock()
+	s, ok := m.sessions[id]
+	if !ok || s.Tenant != tenant {
+		m.mu.Unlock()
+		return fmt.Errorf("%w: %q", ErrUnknownSession, id)
+	}
+	delete(m.sessions, id)
+	m.mu.Unlock()
+	return m.end(s)
+}
+
+// end revokes the session's key set, destroying its keys once in-flight
+// operations finish, and deletes its handles.
+func (m *Manager) end(s *session) error {
+	err := m.keys.Revoke(s.ID)
+	if errors.Is(err, keys.ErrUnknownKeySet) {
+		// An operator revoked the key set already.
+		err = nil
+	}
+	if m.store == nil {
+		return err
+	}
+	for handle := range s.handles {
+		derr := m.store.Delete(handle)
+		if derr != nil && !errors.Is(derr, store.ErrNotFound) {
+			err = errors.Join(err, derr)
+			continue
+		}
+		if m.forget != nil {
+			m.forget(handle)
+		}
+	}
+	return err
+}
+
+// Expire ends every session past its TTL at now and returns how many it
+// ended.
+func (m *Manager) Expire(now time.Time) int {
+	return m.endWhere(func(s *session) bool { return !now.Before(s.ExpiresAt) })
+}
+
+// CloseAll ends every session, e.g. at shutdown, so stored handles do not
+// outlive their keys.
+func (m *Manager) CloseAll() int {
+	return m.endWhere(func(*session) bool { return true })
+}
+
+// Run expires sessions every interval until ctx is done.
+func (m *Manager) Run(ctx context.Context, interval time.Duration) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			m.Expire(now)
+		}
+	}
+}
+
+func (m *Manager) endWhere(match func(*session) bool) int {
+	m.mu.Lock()
+	var ended []*session
+	for id, s := range m.sessions {
+		if match(s) {
+			ended = append(ended, s)
+			delete(m.sessions, id)
+		}
+	}
+	m.mu.Unlock()
+	for _, s := range ended {
+		if err := m.end(s); err != nil {
+			log.Printf("session %s: %v", s.ID, err)
+		}
+	}
+	return len(ended)
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return IDPrefix + hex.EncodeToString(b), nil
+}
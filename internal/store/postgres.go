@@ -0,0 +1,304 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Postgres is a Store keeping handles in the ciphertexts table created by
+// the postgres package's migrations. With a Blobs store the ciphertext
+// bytes are kept there and the table holds only their metadata.
+type Postgres struct {
+	db      *sql.DB
+	blobs   Blobs
+	timeout time.Duration
+}
+
+// NewPostgres returns a store over db, keeping ciphertext bytes in blobs
+// when it is non-nil.
+func NewPostgres(db *sql.DB, blobs Blobs) *Postgres {
+	return &Postgres{db: db, blobs: blobs, timeout: 30 * time.Second}
+}
+
+func (p *Postgres) callContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), p.timeout)
+}
+
+func blobName(id string) string { return "ciphertexts/" + id }
+
+func (p *Postgres) putData(ctx context.Context, id string, data []byte) error {
+	return p.blobs.PutBlob(ctx, blobName(id), bytes.NewReader(data), int64(len(data)))
+}
+
+// Put stores data, owned by tenant and encrypted under key, under a freshly
+// generated handle.
+func (p *Postgres) Put(tenant, typ string, key KeyRef, data []byte) (Entry, error) {
+	id, err := newID()
+	if err != nil {
+		return Entry{}, err
+	}
+	// timestamptz keeps microseconds; truncate so cursors round-trip.
+	e := Entry{ID: id, Tenant: tenant, Type: typ, KeyRef: key, Data: data, CreatedAt: time.Now().UTC().Truncate(time.Microsecond)}
+	ctx, cancel := p.callContext()
+	defer cancel()
+	inline := data
+	if p.blobs != nil {
+		// Write the bytes first so a listed handle always has them.
+		if err := p.putData(ctx, id, data); err != nil {
+			return Entry{}, err
+		}
+		inline = nil
+	}
+	if _, err := p.db.ExecContext(ctx, insertEntry, e.ID, e.Tenant, e.Type, e.KeySet, int64(e.Generation), e.CreatedAt, inline); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// load fills in e.Data from the blob store when it is kept there.
+func (p *Postgres) load(ctx context.Context, e *Entry) error {
+	if p.blobs == nil || e.Data != nil {
+		return nil
+	}
+	r, err := p.blobs.OpenBlob(ctx, blobName(e.ID))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+	e.Data, err = io.ReadAll(r)
+	return err
+}
+
+const (
+	entryColumns = `id, tenant, type, key_set, generation, created_at, data`
+	insertEntry  = `INSERT INTO ciphertexts (` + entryColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7)`
+)
+
+func scanEntry(row interface{ Scan(...any) error }) (Entry, error) {
+	var e Entry
+	var generation int64
+	if err := row.Scan(&e.ID, &e.Tenant, &e.Type, &e.KeySet, &generation, &e.CreatedAt, &e.Data); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Entry{}, ErrNotFound
+		}
+		return Entry{}, err
+	}
+	e.Generation = uint64(generation)
+	e.CreatedAt = e.CreatedAt.UTC()
+	return e, nil
+}
+
+// Get returns the entry stored under id.
+func (p *Postgres) Get(id string) (Entry, error) {
+	ctx, cancel := p.callContext()
+	defer cancel()
+	e, err := scanEntry(p.db.QueryRowContext(ctx, `SELECT `+entryColumns+` FROM ciphertexts WHERE id = $1`, id))
+	if err != nil {
+		return Entry{}, err
+	}
+	if err := p.load(ctx, &e); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// Delete removes the entry stored under id.
+func (p *Postgres) Delete(id string) error {
+	ctx, cancel := p.callContext()
+	defer cancel()
+	res, err := p.db.ExecContext(ctx, `DELETE FROM ciphertexts WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	if p.blobs != nil {
+		// The handle is gone either way; a leftover blob is only garbage.
+		_ = p.blobs.DeleteBlob(ctx, blobName(id))
+	}
+	return nil
+}
+
+// query runs a listing query and loads each entry's data.
+func (p *Postgres) query(ctx context.Context, q string, args ...any) ([]Entry, error) {
+	rows, err := p.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	var out []Entry
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i := range out {
+		if err := p.load(ctx, &out[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// List returns every stored entry.
+func (p *Postgres) List() ([]Entry, error) {
+	return p.query(context.Background(), `SELECT `+entryColumns+` FROM ciphertexts ORDER BY created_at, id`)
+}
+
+// ListPage returns the page of entries matching opts.Filter that follows
+// opts.Cursor.
+func (p *Postgres) ListPage(opts ListOptions) (Page, error) {
+	after, err := parseCursor(opts.Cursor)
+	if err != nil {
+		return Page{}, err
+	}
+	var where []string
+	var args []any
+	arg := func(cond string, v any) {
+		args = append(args, v)
+		where = append(where, strings.ReplaceAll(cond, "?", fmt.Sprintf("$%d", len(args))))
+	}
+	if opts.Tenant != "" {
+		arg("tenant = ?", opts.Tenant)
+	}
+	if opts.Type != "" {
+		arg("type = ?", opts.Type)
+	}
+	if !opts.CreatedAfter.IsZero() {
+		arg("created_at > ?", opts.CreatedAfter)
+	}
+	if !opts.CreatedBefore.IsZero() {
+		arg("created_at < ?", opts.CreatedBefore)
+	}
+	if opts.Cursor != "" {
+		// Handles are created with microsecond timestamps, so the cursor's
+		// converts back exactly.
+		args = append(args, time.Unix(0, after.createdAt).UTC(), after.id)
+		where = append(where, fmt.Sprintf("(created_at, id) > ($%d, $%d)", len(args)-1, len(args)))
+	}
+	q := `SELECT ` + entryColumns + ` FROM ciphertexts`
+	if len(where) > 0 {
+		q += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	limit := clampLimit(opts.Limit)
+	args = append(args, limit+1)
+	q += fmt.Sprintf(` ORDER BY created_at, id LIMIT $%d`, len(args))
+	ctx, cancel := p.callContext()
+	defer cancel()
+	entries, err := p.query(ctx, q, args...)
+	if err != nil {
+		return Page{}, err
+	}
+	return paginate(entries, limit), nil
+}
+
+// Replace swaps the data stored under id.
+func (p *Postgres) Replace(id string, key KeyRef, data []byte) error {
+	ctx, cancel := p.callContext()
+	defer cancel()
+	if p.blobs != nil {
+		var exists bool
+		if err := p.db.QueryRowContext(ctx, `SELECT true FROM ciphertexts WHERE id = $1`, id).Scan(&exists); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return ErrNotFound
+			}
+			return err
+		}
+		if err := p.putData(ctx, id, data); err != nil {
+			return err
+		}
+		_, err := p.db.ExecContext(ctx, `UPDATE ciphertexts SET key_set = $2, generation = $3 WHERE id = $1`, id, key.KeySet, int64(key.Generation))
+		return err
+	}
+	res, err := p.db.ExecContext(ctx, `UPDATE ciphertexts SET key_set = $2, generation = $3, data = $4 WHERE id = $1`,
+		id, key.KeySet, int64(key.Generation), data)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Commit implements Transactional with one database transaction. With a
+// blob store only new handles can be committed atomically, as their bytes
+// are written before their rows; replacing a handle's blob is not.
+func (p *Postgres) Commit(writes []Write) (_ []Entry, err error) {
+	ctx, cancel := p.callContext()
+	defer cancel()
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	out := make([]Entry, len(writes))
+	var blobs []string
+	defer func() {
+		if err != nil {
+			for _, id := range blobs {
+				_ = p.blobs.DeleteBlob(ctx, blobName(id))
+			}
+		}
+	}()
+	for i, wr := range writes {
+		if wr.ID != "" {
+			if p.blobs != nil {
+				return nil, fmt.Errorf("%w: handles keep their bytes in the blob store", ErrNotTransactional)
+			}
+			continue
+		}
+		id, err := newID()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = Entry{ID: id, Tenant: wr.Tenant, Type: wr.Type, KeyRef: wr.KeyRef, Data: wr.Data, CreatedAt: now}
+		if p.blobs != nil {
+			if err := p.putData(ctx, id, wr.Data); err != nil {
+				return nil, err
+			}
+			blobs = append(blobs, id)
+		}
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+	for i, wr := range writes {
+		if wr.ID == "" {
+			inline := wr.Data
+			if p.blobs != nil {
+				inline = nil
+			}
+			e := out[i]
+			if _, err := tx.ExecContext(ctx, insertEntry, e.ID, e.Tenant, e.Type, e.KeySet, int64(e.Generation), e.CreatedAt, inline); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		e, err := scanEntry(tx.QueryRowContext(ctx, `UPDATE ciphertexts SET key_set = $2, generation = $3, data = $4 WHERE id = $1 RETURNING `+entryColumns,
+			wr.ID, wr.KeySet, int64(wr.Generation), wr.Data))
+		if err != nil {
+			if errors.Is(err, ErrNotFound) {
+				err = fmt.Errorf("%w: %s", ErrNotFound, wr.ID)
+			}
+			return nil, err
+		}
+		out[i] = e
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,336 @@
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+)
+
+// S3Options configures an S3 or S3-compatible (MinIO, R2, ...) store.
+type S3Options struct {
+	// Endpoint is the service host, e.g. "s3.amazonaws.com" or "minio:9000".
+	Endpoint string
+	Bucket   string
+	// Prefix is prepended to every object key, e.g. "prod/".
+	Prefix string
+	Region string
+	// Insecure talks plain HTTP, for local MinIO.
+	Insecure bool
+	// AccessKey and SecretKey are static credentials; when empty they come
+	// from AWS_* or MINIO_* environment variables or the instance role.
+	AccessKey string
+	SecretKey string
+	// SSE selects server-side encryption: "" (bucket default), "s3"
+	// (SSE-S3), "kms" (SSE-KMS with KMSKeyID) or "c" (SSE-C with SSECKey).
+	SSE      string
+	KMSKeyID string
+	// SSECKey is the 32-byte customer key for SSE-C. Objects written with it
+	// cannot be read without it.
+	SSECKey []byte
+	// Timeout bounds each call made through the context-free Store
+	// methods; zero means 30 seconds.
+	Timeout time.Duration
+}
+
+// S3 is a Store keeping each ciphertext as one object with its tenant, type
+// and creation time in object metadata, and a Blobs store for larger
+// objects such as serialized keys. Listing stats every object, so it costs
+// one request per handle; keep handle counts modest or index them elsewhere.
+type S3 struct {
+	client  *minio.Client
+	bucket  string
+	prefix  string
+	sse     encrypt.ServerSide
+	timeout time.Duration
+}
+
+// Object metadata keys, as minio-go canonicalizes them.
+const (
+	metaTenant     = "Tenant"
+	metaType       = "Type"
+	metaKeySet     = "Key-Set"
+	metaGeneration = "Generation"
+	metaCreated    = "Created"
+)
+
+// NewS3 connects to the bucket described by opts and checks that it exists.
+func NewS3(ctx context.Context, opts S3Options) (*S3, error) {
+	if opts.Endpoint == "" || opts.Bucket == "" {
+		return nil, errors.New("s3 store: endpoint and bucket are required")
+	}
+	creds := credentials.NewChainCredentials([]credentials.Provider{
+		&credentials.EnvAWS{},
+		&credentials.EnvMinio{},
+		&credentials.IAM{Client: &http.Client{Transport: http.DefaultTransport}},
+	})
+	if opts.AccessKey != "" {
+		creds = credentials.NewStaticV4(opts.AccessKey, opts.SecretKey, "")
+	}
+	client, err := minio.New(opts.Endpoint, &minio.Options{
+		Creds:  creds,
+		Secure: !opts.Insecure,
+		Region: opts.Region,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("s3 store: %w", err)
+	}
+	s := &S3{client: client, bucket: opts.Bucket, prefix: opts.Prefix, timeout: opts.Timeout}
+	if s.timeout <= 0 {
+		s.timeout = 30 * time.Second
+	}
+	switch opts.SSE {
+	case "":
+	case "s3":
+		s.sse = encrypt.NewSSE()
+	case "kms":
+		if s.sse, err = encrypt.NewSSEKMS(opts.KMSKeyID, nil); err != nil {
+			return nil, fmt.Errorf("s3 store: %w", err)
+		}
+	case "c":
+		if s.sse, err = encrypt.NewSSEC(opts.SSECKey); err != nil {
+			return nil, fmt.Errorf("s3 store: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("s3 store: unknown server-side encryption %q (want s3, kms or c)", opts.SSE)
+	}
+	ok, err := client.BucketExists(ctx, opts.Bucket)
+	if err != nil {
+		return nil, fmt.Errorf("s3 store: %w", err)
+	}
+	if !ok {
+		return nil, fmt.Errorf("s3 store: bucket %q does not exist", opts.Bucket)
+	}
+	return s, nil
+}
+
+func (s *S3) ciphertextKey(id string) string { return s.prefix + "ciphertexts/" + id }
+func (s *S3) blobKey(name string) string     { return s.prefix + "blobs/" + name }
+
+// readSSE returns the encryption to send when reading: only SSE-C needs the
+// key again, while SSE-S3 and SSE-KMS reject the headers on GET.
+func (s *S3) readSSE() encrypt.ServerSide {
+	if s.sse != nil && s.sse.Type() == encrypt.SSEC {
+		return s.sse
+	}
+	return nil
+}
+
+func (s *S3) callContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), s.timeout)
+}
+
+// notFound maps missing objects to ErrNotFound.
+func notFound(err error) error {
+	if code := minio.ToErrorResponse(err).Code; code == "NoSuchKey" || code == "NotFound" {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *S3) put(ctx context.Context, e Entry) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.ciphertextKey(e.ID), bytes.NewReader(e.Data), int64(len(e.Data)), minio.PutObjectOptions{
+		ContentType: "application/octet-stream",
+		UserMetadata: map[string]string{
+			metaTenant:     e.Tenant,
+			metaType:       e.Type,
+			metaKeySet:     e.KeySet,
+			metaGeneration: strconv.FormatUint(e.Generation, 10),
+			metaCreated:    e.CreatedAt.Format(time.RFC3339Nano),
+		},
+		ServerSideEncryption: s.sse,
+	})
+	return err
+}
+
+// Put stores data, owned by tenant and encrypted under key, under a freshly
+// generated handle.
+func (s *S3) Put(tenant, typ string, key KeyRef, data []byte) (Entry, error) {
+	id, err := newID()
+	if err != nil {
+		return Entry{}, err
+	}
+	e := Entry{ID: id, Tenant: tenant, Type: typ, KeyRef: key, Data: data, CreatedAt: time.Now().UTC()}
+	ctx, cancel := s.callContext()
+	defer cancel()
+	if err := s.put(ctx, e); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// stat returns the entry stored under id without its data.
+func (s *S3) stat(ctx context.Context, id string) (Entry, error) {
+	info, err := s.client.StatObject(ctx, s.bucket, s.ciphertextKey(id), minio.StatObjectOptions{ServerSideEncryption: s.readSSE()})
+	if err != nil {
+		return Entry{}, notFound(err)
+	}
+	return entryOf(id, info), nil
+}
+
+func entryOf(id string, info minio.ObjectInfo) Entry {
+	e := Entry{
+		ID:        id,
+		Tenant:    info.UserMetadata[metaTenant],
+		Type:      info.UserMetadata[metaType],
+		KeyRef:    KeyRef{KeySet: info.UserMetadata[metaKeySet]},
+		CreatedAt: info.LastModified.UTC(),
+	}
+	if n, err := strconv.ParseUint(info.UserMetadata[metaGeneration], 10, 64); err == nil {
+		e.Generation = n
+	}
+	if t, err := time.Parse(time.RFC3339Nano, info.UserMetadata[metaCreated]); err == nil {
+		e.CreatedAt = t
+	}
+	return e
+}
+
+func (s *S3) get(ctx context.Context, id string) (Entry, error) {
+	obj, err := s.client.GetObject(ctx, s.bucket, s.ciphertextKey(id), minio.GetObjectOptions{ServerSideEncryption: s.readSSE()})
+	if err != nil {
+		return Entry{}, notFound(err)
+	}
+	defer obj.Close()
+	info, err := obj.Stat()
+	if err != nil {
+		return Entry{}, notFound(err)
+	}
+	e := entryOf(id, info)
+	if e.Data, err = io.ReadAll(obj); err != nil {
+		return Entry{}, notFound(err)
+	}
+	return e, nil
+}
+
+// Get returns the entry stored under id.
+func (s *S3) Get(id string) (Entry, error) {
+	ctx, cancel := s.callContext()
+	defer cancel()
+	return s.get(ctx, id)
+}
+
+// Delete removes the entry stored under id.
+func (s *S3) Delete(id string) error {
+	ctx, cancel := s.callContext()
+	defer cancel()
+	// RemoveObject succeeds for missing keys, so check first.
+	if _, err := s.stat(ctx, id); err != nil {
+		return err
+	}
+	return s.client.RemoveObject(ctx, s.bucket, s.ciphertextKey(id), minio.RemoveObjectOptions{})
+}
+
+// entries lists every stored entry, with its data when withData is set.
+func (s *S3) entries(ctx context.Context, withData bool) ([]Entry, error) {
+	prefix := s.ciphertextKey("")
+	var out []Entry
+	for obj := range s.client.ListObjects(ctx, s.bucket, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if obj.Err != nil {
+			return nil, obj.Err
+		}
+		id := strings.TrimPrefix(obj.Key, prefix)
+		var e Entry
+		var err error
+		if withData {
+			e, err = s.get(ctx, id)
+		} else {
+			e, err = s.stat(ctx, id)
+		}
+		if errors.Is(err, ErrNotFound) {
+			continue // deleted since listed
+		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+// List returns every stored entry. It reads every object, so its timeout
+// scales with the number of handles.
+func (s *S3) List() ([]Entry, error) {
+	return s.entries(context.Background(), true)
+}
+
+// ListPage returns the page of entries matching opts.Filter that follows
+// opts.Cursor.
+func (s *S3) ListPage(opts ListOptions) (Page, error) {
+	after, err := parseCursor(opts.Cursor)
+	if err != nil {
+		return Page{}, err
+	}
+	all, err := s.entries(context.Background(), false)
+	if err != nil {
+		return Page{}, err
+	}
+	var matched []Entry
+	for _, e := range all {
+		if opts.Match(e) && (opts.Cursor == "" || after.before(e)) {
+			matched = append(matched, e)
+		}
+	}
+	page := paginate(matched, opts.Limit)
+	// Fetch the data of the page's entries only.
+	ctx, cancel := s.callContext()
+	defer cancel()
+	for i, e := range page.Entries {
+		full, err := s.get(ctx, e.ID)
+		if err != nil {
+			return Page{}, err
+		}
+		page.Entries[i] = full
+	}
+	return page, nil
+}
+
+// Replace swaps the data stored under id, keeping its other metadata.
+func (s *S3) Replace(id string, key KeyRef, data []byte) error {
+	ctx, cancel := s.callContext()
+	defer cancel()
+	e, err := s.stat(ctx, id)
+	if err != nil {
+		return err
+	}
+	e.KeyRef, e.Data = key, data
+	return s.put(ctx, e)
+}
+
+// PutBlob streams r into the blob stored under name. With size -1 the
+// upload is split into multipart chunks as it is read.
+func (s *S3) PutBlob(ctx context.Context, name string, r io.Reader, size int64) error {
+	_, err := s.client.PutObject(ctx, s.bucket, s.blobKey(path.Clean(name)), r, size, minio.PutObjectOptions{
+		ContentType:          "application/octet-stream",
+		ServerSideEncryption: s.sse,
+	})
+	return err
+}
+
+// OpenBlob streams the blob stored under name.
+func (s *S3) OpenBlob(ctx context.Context, name string) (io.ReadCloser, error) {
+	key := s.blobKey(path.Clean(name))
+	// GetObject is lazy; stat first so a missing blob fails here.
+	if _, err := s.client.StatObject(ctx, s.bucket, key, minio.StatObjectOptions{ServerSideEncryption: s.readSSE()}); err != nil {
+		return nil, notFound(err)
+	}
+	obj, err := s.client.GetObject(ctx, s.bucket, key, minio.GetObjectOptions{ServerSideEncryption: s.readSSE()})
+	if err != nil {
+		return nil, notFound(err)
+	}
+	return obj, nil
+}
+
+// DeleteBlob removes the blob stored under name.
+func (s *S3) DeleteBlob(ctx context.Context, name string) error {
+	return s.client.RemoveObject(ctx, s.bucket, s.blobKey(path.Clean(name)), minio.RemoveObjectOptions{})
+}
@@ -0,0 +1,473 @@
+package store
+
+import (
+	"container/list"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrNotFound is returned when a handle does not exist in the store.
+var ErrNotFound = errors.New("handle not found")
+
+// ErrInvalidCursor is returned when a page cursor was not issued by the store.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// DefaultPageSize and MaxPageSize bound ListOptions.Limit.
+const (
+	DefaultPageSize = 100
+	MaxPageSize     = 1000
+)
+
+// Entry is a serialized ciphertext kept server-side under an opaque handle.
+type Entry struct {
+	ID     string
+	Tenant string
+	Type   string
+	KeyRef
+	Data      []byte
+	CreatedAt time.Time
+}
+
+// KeyRef names the key set a ciphertext is encrypted under and the
+// generation of its keys at the time. Entries stored before it was recorded
+// have an empty KeySet.
+type KeyRef struct {
+	KeySet     string
+	Generation uint64
+}
+
+// Filter selects entries in a listing; zero fields match every entry.
+type Filter struct {
+	Tenant        string
+	Type          string
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+}
+
+// Match reports whether e passes the filter.
+func (f Filter) Match(e Entry) bool {
+	switch {
+	case f.Tenant != "" && e.Tenant != f.Tenant,
+		f.Type != "" && e.Type != f.Type,
+		!f.CreatedAfter.IsZero() && !e.CreatedAt.After(f.CreatedAfter),
+		!f.CreatedBefore.IsZero() && !e.CreatedAt.Before(f.CreatedBefore):
+		return false
+	}
+	return true
+}
+
+// ListOptions requests one page of a filtered listing. Cursor is empty for
+// the first page and Page.Next for the following ones.
+type ListOptions struct {
+	Filter
+	Cursor string
+	Limit  int
+}
+
+// Page is one page of entries ordered by creation time, then ID. Next is
+// empty on the last page.
+type Page struct {
+	Entries []Entry
+	Next    string
+}
+
+// Store keeps serialized ciphertexts referenced by handle IDs.
+type Store interface {
+	Put(tenant, typ string, key KeyRef, data []byte) (Entry, error)
+	Get(id string) (Entry, error)
+	Delete(id string) error
+	List() ([]Entry, error)
+	ListPage(opts ListOptions) (Page, error)
+	// Replace swaps the data stored under id for data encrypted under key.
+	Replace(id string, key KeyRef, data []byte) error
+}
+
+// ErrNotTransactional is returned by Commit for stores that cannot apply
+// several writes atomically.
+var ErrNotTransactional = errors.New("store does not support transactions")
+
+// Write is one change of a transaction: a new handle owned by Tenant when
+// ID is empty, otherwise new data for the existing handle ID.
+type Write struct {
+	ID     string
+	Tenant string
+	Type   string
+	KeyRef
+	Data []byte
+}
+
+// Transactional is implemented by stores that apply several writes at once.
+type Transactional interface {
+	// Commit applies every write or, when one fails, none of them, so
+	// readers never see part of the transaction. It returns the written
+	// entries in order.
+	Commit(writes []Write) ([]Entry, error)
+}
+
+// Commit applies writes to s atomically, failing with ErrNotTransactional
+// when s cannot.
+func Commit(s Store, writes []Write) ([]Entry, error) {
+	tx, ok := s.(Transactional)
+	if !ok {
+		return nil, ErrNotTransactional
+	}
+	return tx.Commit(writes)
+}
+
+// Blobs keeps large opaque objects, such as serialized server keys and
+// ciphertext lists, that are streamed rather than held in memory.
+type Blobs interface {
+	// PutBlob stores r under name, replacing any previous blob; size is -1
+	// when unknown.
+	PutBlob(ctx context.Context, name string, r io.Reader, size int64) error
+	// OpenBlob streams the blob stored under name.
+	OpenBlob(ctx context.Context, name string) (io.ReadCloser, error)
+	DeleteBlob(ctx context.Context, name string) error
+}
+
+// MemoryOptions bounds a Memory store; zero fields are unlimited.
+type MemoryOptions struct {
+	// MaxBytes bounds the ciphertext bytes held; storing past it evicts
+	// the least recently used handles.
+	MaxBytes int64
+	// MaxEntries bounds the handles held, evicting likewise.
+	MaxEntries int
+	// TTL evicts handles not read for this long.
+	TTL time.Duration
+}
+
+// MemoryStats reports a Memory store's contents and evictions.
+type MemoryStats struct {
+	Entries int   `json:"entries"`
+	Bytes   int64 `json:"bytes"`
+	// EvictedCapacity counts handles evicted to make room, EvictedExpired
+	// those evicted for their TTL.
+	EvictedCapacity uint64 `json:"evicted_capacity"`
+	EvictedExpired  uint64 `json:"evicted_expired"`
+}
+
+// ErrCapacity is returned when one ciphertext exceeds MemoryOptions.MaxBytes.
+var ErrCapacity = errors.New("ciphertext exceeds the store's capacity")
+
+// Memory is an in-process Store guarded by a mutex, optionally bounded with
+// LRU and TTL eviction.
+type Memory struct {
+	opts    MemoryOptions
+	onEvict func(id string)
+
+	mu      sync.Mutex
+	entries map[string]*list.Element // of *memoryEntry
+	lru     *list.List               // most recently used first
+	bytes   int64
+	stats   MemoryStats
+}
+
+type memoryEntry struct {
+	Entry
+	lastUsed time.Time
+}
+
+// NewMemory returns an empty in-memory store bounded by opts.
+func NewMemory(opts MemoryOptions) *Memory {
+	return &Memory{opts: opts, entries: make(map[string]*list.Element), lru: list.New()}
+}
+
+// SetOnEvict registers fn to be called with the ID of every evicted handle,
+// e.g. to drop its ACL. It is called without the store's lock held.
+func (m *Memory) SetOnEvict(fn func(id string)) { m.onEvict = fn }
+
+// Put stores a copy of data, owned by tenant and encrypted under key, under
+// a freshly generated handle, evicting the least recently used handles to
+// make room.
+func (m *Memory) Put(tenant, typ string, key KeyRef, data []byte) (Entry, error) {
+	if m.opts.MaxBytes > 0 && int64(len(data)) > m.opts.MaxBytes {
+		return Entry{}, fmt.Errorf("%w: %d bytes", ErrCapacity, len(data))
+	}
+	id, err := newID()
+	if err != nil {
+		return Entry{}, err
+	}
+	now := time.Now().UTC()
+	e := Entry{
+		ID:        id,
+		Tenant:    tenant,
+		Type:      typ,
+		KeyRef:    key,
+		Data:      append([]byte(nil), data...),
+		CreatedAt: now,
+	}
+	m.mu.Lock()
+	evicted := m.expire(now)
+	for m.lru.Len() > 0 && (m.opts.MaxEntries > 0 && m.lru.Len() >= m.opts.MaxEntries ||
+		m.opts.MaxBytes > 0 && m.bytes+int64(len(data)) > m.opts.MaxBytes) {
+		evicted = append(evicted, m.remove(m.lru.Back()))
+		m.stats.EvictedCapacity++
+	}
+	m.entries[id] = m.lru.PushFront(&memoryEntry{Entry: e, lastUsed: now})
+	m.bytes += int64(len(data))
+	m.mu.Unlock()
+	m.evicted(evicted)
+	return e, nil
+}
+
+// Get returns the entry stored under id, marking it used.
+func (m *Memory) Get(id string) (Entry, error) {
+	now := time.Now()
+	m.mu.Lock()
+	evicted := m.expire(now)
+	el, ok := m.entries[id]
+	if ok {
+		el.Value.(*memoryEntry).lastUsed = now
+		m.lru.MoveToFront(el)
+	}
+	m.mu.Unlock()
+	m.evicted(evicted)
+	if !ok {
+		return Entry{}, ErrNotFound
+	}
+	return el.Value.(*memoryEntry).Entry, nil
+}
+
+// Delete removes the entry stored under id.
+func (m *Memory) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[id]
+	if !ok {
+		return ErrNotFound
+	}
+	m.remove(el)
+	return nil
+}
+
+// List returns a snapshot of every stored entry.
+func (m *Memory) List() ([]Entry, error) {
+	m.mu.Lock()
+	evicted := m.expire(time.Now())
+	out := make([]Entry, 0, len(m.entries))
+	for el := m.lru.Front(); el != nil; el = el.Next() {
+		out = append(out, el.Value.(*memoryEntry).Entry)
+	}
+	m.mu.Unlock()
+	m.evicted(evicted)
+	return out, nil
+}
+
+// ListPage returns the page of entries matching opts.Filter that follows
+// opts.Cursor.
+func (m *Memory) ListPage(opts ListOptions) (Page, error) {
+	after, err := parseCursor(opts.Cursor)
+	if err != nil {
+		return Page{}, err
+	}
+
+	m.mu.Lock()
+	evicted := m.expire(time.Now())
+	var matched []Entry
+	for el := m.lru.Front(); el != nil; el = el.Next() {
+		e := el.Value.(*memoryEntry).Entry
+		if opts.Match(e) && (opts.Cursor == "" || after.before(e)) {
+			matched = append(matched, e)
+		}
+	}
+	m.mu.Unlock()
+	m.evicted(evicted)
+	return paginate(matched, opts.Limit), nil
+}
+
+// Commit implements Transactional. The writes are applied under the store's
+// lock once every replaced handle is known to exist and the transaction's
+// entries fit the store together, so eviction never removes part of it.
+func (m *Memory) Commit(writes []Write) ([]Entry, error) {
+	now := time.Now().UTC()
+	out := make([]Entry, len(writes))
+	var size int64
+	for i, wr := range writes {
+		size += int64(len(wr.Data))
+		if wr.ID != "" {
+			continue
+		}
+		id, err := newID()
+		if err != nil {
+			return nil, err
+		}
+		out[i] = Entry{ID: id, Tenant: wr.Tenant, Type: wr.Type, KeyRef: wr.KeyRef, Data: append([]byte(nil), wr.Data...), CreatedAt: now}
+	}
+	if m.opts.MaxBytes > 0 && size > m.opts.MaxBytes || m.opts.MaxEntries > 0 && len(writes) > m.opts.MaxEntries {
+		return nil, fmt.Errorf("%w: %d writes of %d bytes", ErrCapacity, len(writes), size)
+	}
+
+	m.mu.Lock()
+	evicted := m.expire(now)
+	for _, wr := range writes {
+		if _, ok := m.entries[wr.ID]; wr.ID != "" && !ok {
+			m.mu.Unlock()
+			m.evicted(evicted)
+			return nil, fmt.Errorf("%w: %s", ErrNotFound, wr.ID)
+		}
+	}
+	for i, wr := range writes {
+		if wr.ID == "" {
+			continue
+		}
+		el := m.entries[wr.ID]
+		e := el.Value.(*memoryEntry)
+		m.bytes += int64(len(wr.Data) - len(e.Data))
+		e.KeyRef = wr.KeyRef
+		e.Data = append([]byte(nil), wr.Data...)
+		e.lastUsed = now
+		m.lru.MoveToFront(el)
+		out[i] = e.Entry
+	}
+	for i, wr := range writes {
+		if wr.ID != "" {
+			continue
+		}
+		for m.lru.Len() > 0 && (m.opts.MaxEntries > 0 && m.lru.Len() >= m.opts.MaxEntries ||
+			m.opts.MaxBytes > 0 && m.bytes+int64(len(wr.Data)) > m.opts.MaxBytes) {
+			evicted = append(evicted, m.remove(m.lru.Back()))
+			m.stats.EvictedCapacity++
+		}
+		m.entries[out[i].ID] = m.lru.PushFront(&memoryEntry{Entry: out[i], lastUsed: now})
+		m.bytes += int64(len(wr.Data))
+	}
+	m.mu.Unlock()
+	m.evicted(evicted)
+	return out, nil
+}
+
+// Stats evicts expired handles and returns the store's contents and
+// eviction counts.
+func (m *Memory) Stats() MemoryStats {
+	m.mu.Lock()
+	evicted := m.expire(time.Now())
+	stats := m.stats
+	stats.Entries, stats.Bytes = len(m.entries), m.bytes
+	m.mu.Unlock()
+	m.evicted(evicted)
+	return stats
+}
+
+// expire removes the entries unused for the TTL, which sit at the back of
+// the LRU list, and returns their IDs. m.mu must be held.
+func (m *Memory) expire(now time.Time) []string {
+	if m.opts.TTL <= 0 {
+		return nil
+	}
+	var ids []string
+	for el := m.lru.Back(); el != nil && now.Sub(el.Value.(*memoryEntry).lastUsed) >= m.opts.TTL; el = m.lru.Back() {
+		ids = append(ids, m.remove(el))
+		m.stats.EvictedExpired++
+	}
+	return ids
+}
+
+// remove drops el and returns its ID. m.mu must be held.
+func (m *Memory) remove(el *list.Element) string {
+	e := m.lru.Remove(el).(*memoryEntry)
+	delete(m.entries, e.ID)
+	m.bytes -= int64(len(e.Data))
+	return e.ID
+}
+
+func (m *Memory) evicted(ids []string) {
+	if m.onEvict == nil {
+		return
+	}
+	for _, id := range ids {
+		m.onEvict(id)
+	}
+}
+
+// paginate sorts the entries that follow the cursor and cuts the first page.
+func paginate(matched []Entry, limit int) Page {
+	limit = clampLimit(limit)
+	sort.Slice(matched, func(i, j int) bool { return keyOf(matched[i]).before(matched[j]) })
+	var page Page
+	if len(matched) > limit {
+		matched = matched[:limit]
+		page.Next = keyOf(matched[limit-1]).String()
+	}
+	page.Entries = matched
+	return page
+}
+
+func clampLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultPageSize
+	case limit > MaxPageSize:
+		return MaxPageSize
+	}
+	return limit
+}
+
+// cursor is the sort key of the last entry on a page.
+type cursor struct {
+	createdAt int64 // unix nanoseconds
+	id        string
+}
+
+func keyOf(e Entry) cursor { return cursor{createdAt: e.CreatedAt.UnixNano(), id: e.ID} }
+
+// before reports whether c sorts before e.
+func (c cursor) before(e Entry) bool {
+	k := keyOf(e)
+	return c.createdAt < k.createdAt || c.createdAt == k.createdAt && c.id < k.id
+}
+
+func (c cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(c.createdAt, 10) + "." + c.id))
+}
+
+func parseCursor(s string) (cursor, error) {
+	if s == "" {
+		return cursor{}, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), ".")
+	if !ok {
+		return cursor{}, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	return cursor{createdAt: n, id: id}, nil
+}
+
+// Replace swaps the data stored under id without marking it used.
+func (m *Memory) Replace(id string, key KeyRef, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[id]
+	if !ok {
+		return ErrNotFound
+	}
+	e := el.Value.(*memoryEntry)
+	m.bytes += int64(len(data) - len(e.Data))
+	e.KeyRef = key
+	e.Data = append([]byte(nil), data...)
+	return nil
+}
+
+func newID() (string, error) {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b[:]), nil
+}
@@ -0,0 +1,78 @@
+package tfhe
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// Arena collects native objects so a group of them, such as the
+// intermediates of one evaluation, is freed by a single Close instead of a
+// defer per object. Objects that outlive the group are taken back with
+// Detach. The zero value is an empty arena; it is safe for concurrent use,
+// so parallel workers may track their results in it.
+type Arena struct {
+	mu     sync.Mutex
+	objs   []io.Closer
+	closed bool
+}
+
+// Track hands objs to the arena. Nil objects are ignored by Close, so
+// results may be tracked before their error is checked. Objects tracked
+// after Close are closed at once.
+func (a *Arena) Track(objs ...io.Closer) {
+	a.mu.Lock()
+	if !a.closed {
+		a.objs = append(a.objs, objs...)
+		a.mu.Unlock()
+		return
+	}
+	a.mu.Unlock()
+	for _, o := range objs {
+		if o != nil {
+			_ = o.Close()
+		}
+	}
+}
+
+// TrackAll hands every object of objs to a, as Track does.
+func TrackAll[P io.Closer](a *Arena, objs []P) {
+	closers := make([]io.Closer, len(objs))
+	for i, o := range objs {
+		closers[i] = o
+	}
+	a.Track(closers...)
+}
+
+// Detach takes obj back from the arena, so Close leaves it to the caller.
+// It reports whether obj was tracked.
+func (a *Arena) Detach(obj io.Closer) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for i := len(a.objs) - 1; i >= 0; i-- {
+		if a.objs[i] == obj {
+			a.objs = append(a.objs[:i], a.objs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Close closes every tracked object, the most recent first, and returns
+// their errors joined. Closing an arena twice is a no-op.
+func (a *Arena) Close() error {
+	a.mu.Lock()
+	objs := a.objs
+	a.objs, a.closed = nil, true
+	a.mu.Unlock()
+	var errs []error
+	for i := len(objs) - 1; i >= 0; i-- {
+		if objs[i] == nil {
+			continue
+		}
+		if err := objs[i].Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -1,60 +1,78 @@
+//go:build !purego
+
 package tfhe
 
 /*
 #cgo CFLAGS: -I${SRCDIR}/../../tfhe-c/release
-#cgo LDFLAGS: -L${SRCDIR}/../../tfhe-c/release -ltfhe -lm -ldl -lpthread -Wl,-rpath,${SRCDIR}/../../tfhe-c/release
 #include "tfhe.h"
 */
 import "C"
 import (
-	"errors"
-	"fmt"
 	"runtime"
 	"unsafe"
 )
 
+// Backend names the implementation the package was built with.
+const Backend = "native"
+
+// serializationFormat identifies the ciphertext encoding: tfhe-c's own,
+// which follows the library release.
+func serializationFormat() string { return "tfhe-c/" + LibraryVersion }
+
 // ClientKey wraps a BooleanClientKey pointer from the C API.
 // Close must be called to release the underlying memory.
 type ClientKey struct {
+	lifecycle
 	ptr *C.struct_BooleanClientKey
 }
 
 // ServerKey wraps a BooleanServerKey pointer from the C API.
 type ServerKey struct {
+	lifecycle
 	ptr *C.struct_BooleanServerKey
 }
 
 // Ciphertext wraps a BooleanCiphertext pointer from the C API.
 type Ciphertext struct {
-	ptr *C.struct_BooleanCiphertext
+	lifecycle
+	ptr    *C.struct_BooleanCiphertext
+	origin []uintptr // creation stack, recorded in leak-detection mode
 }
 
 // Uint8ClientKey wraps the generic ClientKey for integer operations.
 type Uint8ClientKey struct {
+	lifecycle
 	ptr *C.struct_ClientKey
 }
 
 // Uint8ServerKey wraps the generic ServerKey for integer operations.
 type Uint8ServerKey struct {
+	lifecycle
 	ptr *C.struct_ServerKey
 }
 
 // Uint8PublicKey wraps the PublicKey for integer operations.
 type Uint8PublicKey struct {
+	lifecycle
 	ptr *C.struct_PublicKey
 }
 
 // Uint8Ciphertext wraps FheUint8 pointer from the C API.
 type Uint8Ciphertext struct {
-	ptr *C.struct_FheUint8
+	lifecycle
+	ptr    *C.struct_FheUint8
+	origin []uintptr // creation stack, recorded in leak-detection mode
 }
 
 // withServerKey pins the current goroutine to an OS thread, sets the server key
 // for that thread, runs fn, then unsets and unlocks. This avoids the panic
 // "server key was not properly initialized" when Go reschedules goroutines.
 func withServerKey(sk *Uint8ServerKey, fn func() error) error {
+	if sk != nil && sk.isClosed() {
+		return errServerKeyClosed
+	}
 	if sk == nil || sk.ptr == nil {
-		return errors.New("server key is nil")
+		return ErrServerKeyNotSet
 	}
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
@@ -67,10 +85,24 @@ func withServerKey(sk *Uint8ServerKey, fn func() error) error {
 	return fn()
 }
 
+// withBuffer serializes into a C buffer with serialize and passes fn a view
+// of it, freeing the buffer once fn returns.
+func withBuffer(op string, serialize func(*C.struct_DynamicBuffer) C.int, fn func([]byte) error) error {
+	var buf C.struct_DynamicBuffer
+	if err := check(serialize(&buf), op); err != nil {
+		return err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	if buf.length == 0 {
+		return fn([]byte{})
+	}
+	return fn(unsafe.Slice((*byte)(unsafe.Pointer(buf.pointer)), int(buf.length)))
+}
+
 // check converts non-zero TFHE return codes into Go errors.
 func check(code C.int, context string) error {
 	if code != 0 {
-		return fmt.Errorf("%s: tfhe error code %d", context, int(code))
+		return &ErrCAPI{Op: context, Code: int(code)}
 	}
 	return nil
 }
@@ -86,6 +118,8 @@ func GenerateBooleanKeys() (*ClientKey, *ServerKey, error) {
 
 	client := &ClientKey{ptr: ck}
 	server := &ServerKey{ptr: sk}
+	trackObject(objBooleanClientKey)
+	trackObject(objBooleanServerKey)
 
 	runtime.SetFinalizer(client, func(c *ClientKey) { _ = c.Close() })
 	runtime.SetFinalizer(server, func(s *ServerKey) { _ = s.Close() })
@@ -95,61 +129,99 @@ func GenerateBooleanKeys() (*ClientKey, *ServerKey, error) {
 
 // Close releases the underlying BooleanClientKey.
 func (c *ClientKey) Close() error {
-	if c == nil || c.ptr == nil {
+	if c == nil || !c.markClosed() || c.ptr == nil {
 		return nil
 	}
 	if err := check(C.boolean_destroy_client_key(c.ptr), "destroy client key"); err != nil {
 		return err
 	}
 	c.ptr = nil
+	untrackObject(objBooleanClientKey)
 	return nil
 }
 
+func (c *ClientKey) usable() error {
+	if c == nil {
+		return errClientKeyNil
+	}
+	return c.state(c.ptr != nil, errClientKeyNil, errClientKeyClosed)
+}
+
 // Close releases the underlying BooleanServerKey.
 func (s *ServerKey) Close() error {
-	if s == nil || s.ptr == nil {
+	if s == nil || !s.markClosed() || s.ptr == nil {
 		return nil
 	}
 	if err := check(C.boolean_destroy_server_key(s.ptr), "destroy server key"); err != nil {
 		return err
 	}
 	s.ptr = nil
+	untrackObject(objBooleanServerKey)
 	return nil
 }
 
+func (s *ServerKey) usable() error {
+	if s == nil {
+		return errServerKeyNil
+	}
+	return s.state(s.ptr != nil, errServerKeyNil, errServerKeyClosed)
+}
+
 // Close releases the underlying BooleanCiphertext.
 func (c *Ciphertext) Close() error {
-	if c == nil || c.ptr == nil {
+	if c == nil || !c.markClosed() || c.ptr == nil {
 		return nil
 	}
 	if err := check(C.boolean_destroy_ciphertext(c.ptr), "destroy ciphertext"); err != nil {
 		return err
 	}
 	c.ptr = nil
+	untrackObject(objBooleanCiphertext)
 	return nil
 }
 
+func (c *Ciphertext) usable() error {
+	if c == nil {
+		return errCiphertextNil
+	}
+	return c.state(c.ptr != nil, errCiphertextNil, errCiphertextClosed)
+}
+
+// newCiphertext wraps ptr, registering a finalizer and memory accounting.
+func newCiphertext(ptr *C.struct_BooleanCiphertext) *Ciphertext {
+	ct := &Ciphertext{ptr: ptr, origin: captureOrigin()}
+	trackObject(objBooleanCiphertext)
+	runtime.SetFinalizer(ct, func(c *Ciphertext) {
+		if c.ptr != nil {
+			reportLeak(objBooleanCiphertext, c.origin)
+		}
+		_ = c.Close()
+	})
+	return ct
+}
+
 // EncryptBool encrypts a boolean using the provided client key.
 func EncryptBool(client *ClientKey, value bool) (*Ciphertext, error) {
-	if client == nil || client.ptr == nil {
-		return nil, errors.New("client key is nil")
+	if err := client.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
 	}
 	var ct *C.struct_BooleanCiphertext
 	if err := check(C.boolean_client_key_encrypt(client.ptr, C.bool(value), &ct), "encrypt bool"); err != nil {
 		return nil, err
 	}
-	cipher := &Ciphertext{ptr: ct}
-	runtime.SetFinalizer(cipher, func(c *Ciphertext) { _ = c.Close() })
-	return cipher, nil
+	return newCiphertext(ct), nil
 }
 
 // DecryptBool decrypts a ciphertext with the provided client key.
 func DecryptBool(client *ClientKey, ct *Ciphertext) (bool, error) {
-	if client == nil || client.ptr == nil {
-		return false, errors.New("client key is nil")
+	if err := client.usable(); err != nil {
+		return false, err
 	}
-	if ct == nil || ct.ptr == nil {
-		return false, errors.New("ciphertext is nil")
+	if err := ct.usable(); err != nil {
+		return false, err
 	}
 	var result C.bool
 	if err := check(C.boolean_client_key_decrypt(client.ptr, ct.ptr, &result), "decrypt bool"); err != nil {
@@ -160,76 +232,80 @@ func DecryptBool(client *ClientKey, ct *Ciphertext) (bool, error) {
 
 // And performs a homomorphic AND on two ciphertexts.
 func (s *ServerKey) And(lhs, rhs *Ciphertext) (*Ciphertext, error) {
-	if s == nil || s.ptr == nil {
-		return nil, errors.New("server key is nil")
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
 	}
-	if lhs == nil || lhs.ptr == nil || rhs == nil || rhs.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
 	}
 	var out *C.struct_BooleanCiphertext
 	if err := check(C.boolean_server_key_and(s.ptr, lhs.ptr, rhs.ptr, &out), "boolean AND"); err != nil {
 		return nil, err
 	}
-	ct := &Ciphertext{ptr: out}
-	runtime.SetFinalizer(ct, func(c *Ciphertext) { _ = c.Close() })
-	return ct, nil
+	return newCiphertext(out), nil
 }
 
 // Or performs a homomorphic OR on two ciphertexts.
 func (s *ServerKey) Or(lhs, rhs *Ciphertext) (*Ciphertext, error) {
-	if s == nil || s.ptr == nil {
-		return nil, errors.New("server key is nil")
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
 	}
-	if lhs == nil || lhs.ptr == nil || rhs == nil || rhs.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
 	}
 	var out *C.struct_BooleanCiphertext
 	if err := check(C.boolean_server_key_or(s.ptr, lhs.ptr, rhs.ptr, &out), "boolean OR"); err != nil {
 		return nil, err
 	}
-	ct := &Ciphertext{ptr: out}
-	runtime.SetFinalizer(ct, func(c *Ciphertext) { _ = c.Close() })
-	return ct, nil
+	return newCiphertext(out), nil
 }
 
 // Xor performs a homomorphic XOR on two ciphertexts.
 func (s *ServerKey) Xor(lhs, rhs *Ciphertext) (*Ciphertext, error) {
-	if s == nil || s.ptr == nil {
-		return nil, errors.New("server key is nil")
+	if err := s.usable(); err != nil {
+		return nil, err
 	}
-	if lhs == nil || lhs.ptr == nil || rhs == nil || rhs.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
 	}
 	var out *C.struct_BooleanCiphertext
 	if err := check(C.boolean_server_key_xor(s.ptr, lhs.ptr, rhs.ptr, &out), "boolean XOR"); err != nil {
 		return nil, err
 	}
-	ct := &Ciphertext{ptr: out}
-	runtime.SetFinalizer(ct, func(c *Ciphertext) { _ = c.Close() })
-	return ct, nil
+	return newCiphertext(out), nil
 }
 
 // Not performs a homomorphic NOT on a ciphertext.
 func (s *ServerKey) Not(input *Ciphertext) (*Ciphertext, error) {
-	if s == nil || s.ptr == nil {
-		return nil, errors.New("server key is nil")
+	if err := s.usable(); err != nil {
+		return nil, err
 	}
-	if input == nil || input.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+	if err := input.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
 	}
 	var out *C.struct_BooleanCiphertext
 	if err := check(C.boolean_server_key_not(s.ptr, input.ptr, &out), "boolean NOT"); err != nil {
 		return nil, err
 	}
-	ct := &Ciphertext{ptr: out}
-	runtime.SetFinalizer(ct, func(c *Ciphertext) { _ = c.Close() })
-	return ct, nil
+	return newCiphertext(out), nil
 }
 
 // Serialize returns a copy of the ciphertext bytes and frees the C buffer.
 func (c *Ciphertext) Serialize() ([]byte, error) {
-	if c == nil || c.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+	if err := c.usable(); err != nil {
+		return nil, err
 	}
 	var buf C.struct_DynamicBuffer
 	if err := check(C.boolean_serialize_ciphertext(c.ptr, &buf), "serialize ciphertext"); err != nil {
@@ -245,27 +321,44 @@ func (c *Ciphertext) Serialize() ([]byte, error) {
 	return data, nil
 }
 
+// WithBytes serializes the ciphertext and calls fn with the bytes in place,
+// without copying them into Go memory. They are freed when fn returns, so fn
+// must not modify or retain them.
+func (c *Ciphertext) WithBytes(fn func([]byte) error) error {
+	if err := c.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize ciphertext", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.boolean_serialize_ciphertext(c.ptr, buf)
+	}, fn)
+}
+
 // DeserializeCiphertext reconstructs a ciphertext from serialized bytes.
 func DeserializeCiphertext(data []byte) (*Ciphertext, error) {
-	if len(data) == 0 {
-		return nil, errors.New("ciphertext data is empty")
+	if err := checkSerialized(data, CurrentLimits().MaxBooleanCiphertext); err != nil {
+		return nil, err
+	}
+	if err := isolate("boolean", data); err != nil {
+		return nil, err
 	}
 	view := C.struct_DynamicBufferView{
 		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
 		length:  C.size_t(len(data)),
 	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
+	}
 	var ct *C.struct_BooleanCiphertext
 	if err := check(C.boolean_deserialize_ciphertext(view, &ct), "deserialize ciphertext"); err != nil {
-		return nil, err
+		return nil, invalidCiphertext(err)
 	}
-	out := &Ciphertext{ptr: ct}
-	runtime.SetFinalizer(out, func(c *Ciphertext) { _ = c.Close() })
+	out := newCiphertext(ct)
 	runtime.KeepAlive(data)
 	return out, nil
 }
 
-// GenerateUint8Keys builds default config and returns client/server keys set for computations.
-func GenerateUint8Keys() (*Uint8ClientKey, *Uint8ServerKey, error) {
+// generateUint8Keys creates a keypair with the default config.
+func generateUint8Keys() (*Uint8ClientKey, *Uint8ServerKey, error) {
 	var builder *C.struct_ConfigBuilder
 	if err := check(C.config_builder_default(&builder), "config builder default"); err != nil {
 		return nil, nil, err
@@ -282,14 +375,10 @@ func GenerateUint8Keys() (*Uint8ClientKey, *Uint8ServerKey, error) {
 		return nil, nil, err
 	}
 
-	// Set server key for subsequent FHE ops.
-	if err := check(C.set_server_key(sk), "set server key"); err != nil {
-		return nil, nil, err
-	}
-
 	client := &Uint8ClientKey{ptr: ck}
 	server := &Uint8ServerKey{ptr: sk}
-	setServerKeyHolder(server)
+	trackObject(objUint8ClientKey)
+	trackObject(objUint8ServerKey)
 	runtime.SetFinalizer(client, func(c *Uint8ClientKey) { _ = c.Close() })
 	runtime.SetFinalizer(server, func(s *Uint8ServerKey) { _ = s.Close() })
 	return client, server, nil
@@ -297,19 +386,27 @@ func GenerateUint8Keys() (*Uint8ClientKey, *Uint8ServerKey, error) {
 
 // Close releases the underlying ClientKey.
 func (c *Uint8ClientKey) Close() error {
-	if c == nil || c.ptr == nil {
+	if c == nil || !c.markClosed() || c.ptr == nil {
 		return nil
 	}
 	if err := check(C.client_key_destroy(c.ptr), "destroy client key"); err != nil {
 		return err
 	}
 	c.ptr = nil
+	untrackObject(objUint8ClientKey)
 	return nil
 }
 
+func (c *Uint8ClientKey) usable() error {
+	if c == nil {
+		return errClientKeyNil
+	}
+	return c.state(c.ptr != nil, errClientKeyNil, errClientKeyClosed)
+}
+
 // Close releases the underlying ServerKey and unsets thread-local server key if set.
 func (s *Uint8ServerKey) Close() error {
-	if s == nil || s.ptr == nil {
+	if s == nil || !s.markClosed() || s.ptr == nil {
 		return nil
 	}
 	// Unset to drop thread-local reference count; ignore errors on unset.
@@ -318,70 +415,102 @@ func (s *Uint8ServerKey) Close() error {
 		return err
 	}
 	s.ptr = nil
+	untrackObject(objUint8ServerKey)
 	return nil
 }
 
+func (s *Uint8ServerKey) usable() error {
+	if s == nil {
+		return errServerKeyNil
+	}
+	return s.state(s.ptr != nil, errServerKeyNil, errServerKeyClosed)
+}
+
 // NewUint8PublicKey derives a PublicKey from a client key.
 func NewUint8PublicKey(client *Uint8ClientKey) (*Uint8PublicKey, error) {
-	if client == nil || client.ptr == nil {
-		return nil, errors.New("client key is nil")
+	if err := client.usable(); err != nil {
+		return nil, err
 	}
 	var pk *C.struct_PublicKey
 	if err := check(C.public_key_new(client.ptr, &pk), "new public key"); err != nil {
 		return nil, err
 	}
 	pub := &Uint8PublicKey{ptr: pk}
+	trackObject(objUint8PublicKey)
 	runtime.SetFinalizer(pub, func(p *Uint8PublicKey) { _ = p.Close() })
 	return pub, nil
 }
 
 // Close releases the underlying PublicKey.
 func (p *Uint8PublicKey) Close() error {
-	if p == nil || p.ptr == nil {
+	if p == nil || !p.markClosed() || p.ptr == nil {
 		return nil
 	}
 	if err := check(C.public_key_destroy(p.ptr), "destroy public key"); err != nil {
 		return err
 	}
 	p.ptr = nil
+	untrackObject(objUint8PublicKey)
 	return nil
 }
 
+func (p *Uint8PublicKey) usable() error {
+	if p == nil {
+		return errPublicKeyNil
+	}
+	return p.state(p.ptr != nil, errPublicKeyNil, errPublicKeyClosed)
+}
+
+// newUint8Ciphertext wraps ptr, registering a finalizer and memory accounting.
+func newUint8Ciphertext(ptr *C.struct_FheUint8) *Uint8Ciphertext {
+	ct := &Uint8Ciphertext{ptr: ptr, origin: captureOrigin()}
+	trackObject(objUint8Ciphertext)
+	runtime.SetFinalizer(ct, func(c *Uint8Ciphertext) {
+		if c.ptr != nil {
+			reportLeak(objUint8Ciphertext, c.origin)
+		}
+		_ = c.Close()
+	})
+	return ct
+}
+
 // EncryptUint8 encrypts a uint8 with the client key.
 func EncryptUint8(client *Uint8ClientKey, value uint8) (*Uint8Ciphertext, error) {
-	if client == nil || client.ptr == nil {
-		return nil, errors.New("client key is nil")
+	if err := client.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
 	}
 	var ct *C.struct_FheUint8
 	if err := check(C.fhe_uint8_try_encrypt_with_client_key_u8(C.uchar(value), client.ptr, &ct), "encrypt uint8"); err != nil {
 		return nil, err
 	}
-	out := &Uint8Ciphertext{ptr: ct}
-	runtime.SetFinalizer(out, func(c *Uint8Ciphertext) { _ = c.Close() })
-	return out, nil
+	return newUint8Ciphertext(ct), nil
 }
 
 // EncryptUint8Public encrypts a uint8 with the public key.
 func EncryptUint8Public(pub *Uint8PublicKey, value uint8) (*Uint8Ciphertext, error) {
-	if pub == nil || pub.ptr == nil {
-		return nil, errors.New("public key is nil")
+	if err := pub.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
 	}
 	var ct *C.struct_FheUint8
 	if err := check(C.fhe_uint8_try_encrypt_with_public_key_u8(C.uchar(value), pub.ptr, &ct), "encrypt uint8 with public key"); err != nil {
 		return nil, err
 	}
-	out := &Uint8Ciphertext{ptr: ct}
-	runtime.SetFinalizer(out, func(c *Uint8Ciphertext) { _ = c.Close() })
-	return out, nil
+	return newUint8Ciphertext(ct), nil
 }
 
 // DecryptUint8 decrypts a uint8 ciphertext with the client key.
 func DecryptUint8(client *Uint8ClientKey, ct *Uint8Ciphertext) (uint8, error) {
-	if client == nil || client.ptr == nil {
-		return 0, errors.New("client key is nil")
+	if err := client.usable(); err != nil {
+		return 0, err
 	}
-	if ct == nil || ct.ptr == nil {
-		return 0, errors.New("ciphertext is nil")
+	if err := ct.usable(); err != nil {
+		return 0, err
 	}
 	var result C.uchar
 	if err := check(C.fhe_uint8_decrypt(ct.ptr, client.ptr, &result), "decrypt uint8"); err != nil {
@@ -392,80 +521,79 @@ func DecryptUint8(client *Uint8ClientKey, ct *Uint8Ciphertext) (uint8, error) {
 
 // Close releases the underlying FheUint8 ciphertext.
 func (c *Uint8Ciphertext) Close() error {
-	if c == nil || c.ptr == nil {
+	if c == nil || !c.markClosed() || c.ptr == nil {
 		return nil
 	}
 	if err := check(C.fhe_uint8_destroy(c.ptr), "destroy uint8 ciphertext"); err != nil {
 		return err
 	}
 	c.ptr = nil
+	untrackObject(objUint8Ciphertext)
 	return nil
 }
 
-// Uint8Add performs homomorphic addition (requires server key to be set).
-func Uint8Add(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
-	if lhs == nil || lhs.ptr == nil || rhs == nil || rhs.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+func (c *Uint8Ciphertext) usable() error {
+	if c == nil {
+		return errCiphertextNil
+	}
+	return c.state(c.ptr != nil, errCiphertextNil, errCiphertextClosed)
+}
+
+// Add performs homomorphic addition under sk.
+func (sk *Uint8ServerKey) Add(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
 	}
 	var out *C.struct_FheUint8
-	if err := withServerKey(defaultUint8ServerKey(), func() error {
+	if err := withServerKey(sk, func() error {
 		return check(C.fhe_uint8_add(lhs.ptr, rhs.ptr, &out), "uint8 add")
 	}); err != nil {
 		return nil, err
 	}
-	ct := &Uint8Ciphertext{ptr: out}
-	runtime.SetFinalizer(ct, func(c *Uint8Ciphertext) { _ = c.Close() })
-	return ct, nil
+	return newUint8Ciphertext(out), nil
 }
 
-// Uint8BitAnd performs homomorphic bitwise AND.
-func Uint8BitAnd(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
-	if lhs == nil || lhs.ptr == nil || rhs == nil || rhs.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+// BitAnd performs homomorphic bitwise AND under sk.
+func (sk *Uint8ServerKey) BitAnd(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
 	}
 	var out *C.struct_FheUint8
-	if err := withServerKey(defaultUint8ServerKey(), func() error {
+	if err := withServerKey(sk, func() error {
 		return check(C.fhe_uint8_bitand(lhs.ptr, rhs.ptr, &out), "uint8 bitand")
 	}); err != nil {
 		return nil, err
 	}
-	ct := &Uint8Ciphertext{ptr: out}
-	runtime.SetFinalizer(ct, func(c *Uint8Ciphertext) { _ = c.Close() })
-	return ct, nil
+	return newUint8Ciphertext(out), nil
 }
 
-// Uint8BitXor performs homomorphic bitwise XOR.
-func Uint8BitXor(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
-	if lhs == nil || lhs.ptr == nil || rhs == nil || rhs.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+// BitXor performs homomorphic bitwise XOR under sk.
+func (sk *Uint8ServerKey) BitXor(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
 	}
 	var out *C.struct_FheUint8
-	if err := withServerKey(defaultUint8ServerKey(), func() error {
+	if err := withServerKey(sk, func() error {
 		return check(C.fhe_uint8_bitxor(lhs.ptr, rhs.ptr, &out), "uint8 bitxor")
 	}); err != nil {
 		return nil, err
 	}
-	ct := &Uint8Ciphertext{ptr: out}
-	runtime.SetFinalizer(ct, func(c *Uint8Ciphertext) { _ = c.Close() })
-	return ct, nil
-}
-
-// defaultUint8ServerKey holds the current service server key set at init.
-// It must be initialized by GenerateUint8Keys via setServerKeyHolder.
-var defaultUint8ServerKeyHolder *Uint8ServerKey
-
-func setServerKeyHolder(sk *Uint8ServerKey) {
-	defaultUint8ServerKeyHolder = sk
-}
-
-func defaultUint8ServerKey() *Uint8ServerKey {
-	return defaultUint8ServerKeyHolder
+	return newUint8Ciphertext(out), nil
 }
 
 // Uint8Serialize serializes ciphertext and frees C buffer.
 func (c *Uint8Ciphertext) Uint8Serialize() ([]byte, error) {
-	if c == nil || c.ptr == nil {
-		return nil, errors.New("ciphertext is nil")
+	if err := c.usable(); err != nil {
+		return nil, err
 	}
 	var buf C.struct_DynamicBuffer
 	if err := check(C.fhe_uint8_serialize(c.ptr, &buf), "serialize uint8 ciphertext"); err != nil {
@@ -481,21 +609,38 @@ func (c *Uint8Ciphertext) Uint8Serialize() ([]byte, error) {
 	return data, nil
 }
 
+// WithBytes serializes the ciphertext and calls fn with the bytes in place,
+// without copying them into Go memory. They are freed when fn returns, so fn
+// must not modify or retain them.
+func (c *Uint8Ciphertext) WithBytes(fn func([]byte) error) error {
+	if err := c.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize uint8 ciphertext", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.fhe_uint8_serialize(c.ptr, buf)
+	}, fn)
+}
+
 // Uint8Deserialize reconstructs a Uint8 ciphertext from bytes.
 func Uint8Deserialize(data []byte) (*Uint8Ciphertext, error) {
-	if len(data) == 0 {
-		return nil, errors.New("ciphertext data is empty")
+	if err := checkSerialized(data, CurrentLimits().MaxUint8Ciphertext); err != nil {
+		return nil, err
+	}
+	if err := isolate(TypeUint8, data); err != nil {
+		return nil, err
 	}
 	view := C.struct_DynamicBufferView{
 		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
 		length:  C.size_t(len(data)),
 	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
+	}
 	var ct *C.struct_FheUint8
 	if err := check(C.fhe_uint8_deserialize(view, &ct), "deserialize uint8 ciphertext"); err != nil {
-		return nil, err
+		return nil, invalidCiphertext(err)
 	}
-	out := &Uint8Ciphertext{ptr: ct}
-	runtime.SetFinalizer(out, func(c *Uint8Ciphertext) { _ = c.Close() })
+	out := newUint8Ciphertext(ct)
 	runtime.KeepAlive(data)
 	return out, nil
 }
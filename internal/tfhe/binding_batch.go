@@ -0,0 +1,78 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#cgo CFLAGS: -I${SRCDIR}/../../tfhe-c/release
+#include <stddef.h>
+#include "tfhe.h"
+
+// boolean_gate_many applies gate 0 (AND), 1 (OR) or 2 (XOR) to n operand
+// pairs. On failure it destroys the results it produced.
+static int boolean_gate_many(int gate, const struct BooleanServerKey *sk,
+		struct BooleanCiphertext *const *lhs, struct BooleanCiphertext *const *rhs,
+		struct BooleanCiphertext **out, size_t n) {
+	for (size_t i = 0; i < n; i++) {
+		int rc;
+		switch (gate) {
+		case 0:
+			rc = boolean_server_key_and(sk, lhs[i], rhs[i], &out[i]);
+			break;
+		case 1:
+			rc = boolean_server_key_or(sk, lhs[i], rhs[i], &out[i]);
+			break;
+		default:
+			rc = boolean_server_key_xor(sk, lhs[i], rhs[i], &out[i]);
+			break;
+		}
+		if (rc != 0) {
+			for (size_t j = 0; j < i; j++) {
+				boolean_destroy_ciphertext(out[j]);
+				out[j] = NULL;
+			}
+			return rc;
+		}
+	}
+	return 0;
+}
+*/
+import "C"
+import "runtime"
+
+// gateBatch is how many gates one native call evaluates, bounding how long
+// a call holds its thread outside the Go scheduler.
+const gateBatch = 256
+
+var gateCodes = map[Gate]C.int{GateAnd: 0, GateOr: 1, GateXor: 2}
+
+// gateRun applies gate to pairs, storing the results in out.
+func (s *ServerKey) gateRun(gate Gate, pairs []Pair, out []*Ciphertext) error {
+	if err := s.usable(); err != nil {
+		return err
+	}
+	lhs := make([]*C.struct_BooleanCiphertext, len(pairs))
+	rhs := make([]*C.struct_BooleanCiphertext, len(pairs))
+	for i, p := range pairs {
+		if err := checkUsable(p.LHS, p.RHS); err != nil {
+			return err
+		}
+		lhs[i], rhs[i] = p.LHS.ptr, p.RHS.ptr
+	}
+	defer runtime.KeepAlive(pairs)
+
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	res := make([]*C.struct_BooleanCiphertext, gateBatch)
+	for lo := 0; lo < len(pairs); lo += gateBatch {
+		n := min(gateBatch, len(pairs)-lo)
+		code := C.boolean_gate_many(gateCodes[gate], s.ptr, &lhs[lo], &rhs[lo], &res[0], C.size_t(n))
+		if err := check(code, "boolean "+string(gate)+" batch"); err != nil {
+			return err
+		}
+		for i, ptr := range res[:n] {
+			out[lo+i] = newCiphertext(ptr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+import "unsafe"
+
+// BitCount runs the bit-counting operation op on ct under sk. The count is
+// returned as an encrypted uint32, the width tfhe-c uses for all of them.
+func (sk *Uint8ServerKey) BitCount(op BitCount, ct *Uint8Ciphertext) (*IntCiphertext, error) {
+	if err := checkUsable(ct); err != nil {
+		return nil, err
+	}
+	if err := checkBitCount(op); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(intObjectKind(32)); err != nil {
+		return nil, err
+	}
+	var out *C.struct_FheUint32
+	if err := withServerKey(sk, func() error {
+		var code C.int
+		switch op {
+		case BitCountOnes:
+			code = C.fhe_uint8_count_ones(ct.ptr, &out)
+		case BitCountLeadingZeros:
+			code = C.fhe_uint8_leading_zeros(ct.ptr, &out)
+		case BitCountIlog2:
+			code = C.fhe_uint8_ilog2(ct.ptr, &out)
+		}
+		return check(code, "uint8 "+string(op))
+	}); err != nil {
+		return nil, err
+	}
+	return newIntCiphertext(32, unsafe.Pointer(out)), nil
+}
@@ -0,0 +1,379 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// FheBool wraps an encrypted boolean of the high-level integer API. It is
+// produced by integer comparisons, is encrypted under the uint8 keys, and is
+// not interchangeable with the boolean API's Ciphertext.
+type FheBool struct {
+	lifecycle
+	ptr    *C.struct_FheBool
+	origin []uintptr // creation stack, recorded in leak-detection mode
+}
+
+// newFheBool wraps ptr, registering a finalizer and memory accounting.
+func newFheBool(ptr *C.struct_FheBool) *FheBool {
+	ct := &FheBool{ptr: ptr, origin: captureOrigin()}
+	trackObject(objFheBoolCiphertext)
+	runtime.SetFinalizer(ct, func(c *FheBool) {
+		if c.ptr != nil {
+			reportLeak(objFheBoolCiphertext, c.origin)
+		}
+		_ = c.Close()
+	})
+	return ct
+}
+
+// EncryptFheBool encrypts a boolean with the integer client key.
+func EncryptFheBool(client *Uint8ClientKey, value bool) (*FheBool, error) {
+	if err := client.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	var ct *C.struct_FheBool
+	if err := check(C.fhe_bool_try_encrypt_with_client_key_bool(C.bool(value), client.ptr, &ct), "encrypt fhe bool"); err != nil {
+		return nil, err
+	}
+	return newFheBool(ct), nil
+}
+
+// EncryptFheBoolPublic encrypts a boolean with the integer public key.
+func EncryptFheBoolPublic(pub *Uint8PublicKey, value bool) (*FheBool, error) {
+	if err := pub.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	var ct *C.struct_FheBool
+	if err := check(C.fhe_bool_try_encrypt_with_public_key_bool(C.bool(value), pub.ptr, &ct), "encrypt fhe bool with public key"); err != nil {
+		return nil, err
+	}
+	return newFheBool(ct), nil
+}
+
+// DecryptFheBool decrypts an FheBool with the integer client key.
+func DecryptFheBool(client *Uint8ClientKey, ct *FheBool) (bool, error) {
+	if err := client.usable(); err != nil {
+		return false, err
+	}
+	if err := ct.usable(); err != nil {
+		return false, err
+	}
+	var result C.bool
+	if err := check(C.fhe_bool_decrypt(ct.ptr, client.ptr, &result), "decrypt fhe bool"); err != nil {
+		return false, err
+	}
+	return bool(result), nil
+}
+
+// Close releases the underlying FheBool.
+func (c *FheBool) Close() error {
+	if c == nil || !c.markClosed() || c.ptr == nil {
+		return nil
+	}
+	if err := check(C.fhe_bool_destroy(c.ptr), "destroy fhe bool"); err != nil {
+		return err
+	}
+	c.ptr = nil
+	untrackObject(objFheBoolCiphertext)
+	return nil
+}
+
+func (c *FheBool) usable() error {
+	if c == nil {
+		return errCiphertextNil
+	}
+	return c.state(c.ptr != nil, errCiphertextNil, errCiphertextClosed)
+}
+
+// Serialize serializes the FheBool and frees the C buffer.
+func (c *FheBool) Serialize() ([]byte, error) {
+	if err := c.usable(); err != nil {
+		return nil, err
+	}
+	var buf C.struct_DynamicBuffer
+	if err := check(C.fhe_bool_serialize(c.ptr, &buf), "serialize fhe bool"); err != nil {
+		return nil, err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+
+	length := int(buf.length)
+	if length == 0 {
+		return []byte{}, nil
+	}
+	return C.GoBytes(unsafe.Pointer(buf.pointer), C.int(length)), nil
+}
+
+// WithBytes serializes the FheBool and calls fn with the bytes in place,
+// without copying them into Go memory. They are freed when fn returns, so fn
+// must not modify or retain them.
+func (c *FheBool) WithBytes(fn func([]byte) error) error {
+	if err := c.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize fhe bool", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.fhe_bool_serialize(c.ptr, buf)
+	}, fn)
+}
+
+// DeserializeFheBool reconstructs an FheBool from bytes.
+func DeserializeFheBool(data []byte) (*FheBool, error) {
+	if err := checkSerialized(data, CurrentLimits().MaxFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	if err := isolate(TypeBool, data); err != nil {
+		return nil, err
+	}
+	view := C.struct_DynamicBufferView{
+		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
+		length:  C.size_t(len(data)),
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	var ct *C.struct_FheBool
+	if err := check(C.fhe_bool_deserialize(view, &ct), "deserialize fhe bool"); err != nil {
+		return nil, invalidCiphertext(err)
+	}
+	out := newFheBool(ct)
+	runtime.KeepAlive(data)
+	return out, nil
+}
+
+// Compare compares two uint8 ciphertexts under sk, returning an encrypted
+// boolean.
+func (sk *Uint8ServerKey) Compare(cmp Comparison, lhs, rhs *Uint8Ciphertext) (*FheBool, error) {
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if err := checkComparison(cmp); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	var out *C.struct_FheBool
+	if err := withServerKey(sk, func() error {
+		var code C.int
+		switch cmp {
+		case CompareEq:
+			code = C.fhe_uint8_eq(lhs.ptr, rhs.ptr, &out)
+		case CompareNe:
+			code = C.fhe_uint8_ne(lhs.ptr, rhs.ptr, &out)
+		case CompareLt:
+			code = C.fhe_uint8_lt(lhs.ptr, rhs.ptr, &out)
+		case CompareLe:
+			code = C.fhe_uint8_le(lhs.ptr, rhs.ptr, &out)
+		case CompareGt:
+			code = C.fhe_uint8_gt(lhs.ptr, rhs.ptr, &out)
+		case CompareGe:
+			code = C.fhe_uint8_ge(lhs.ptr, rhs.ptr, &out)
+		}
+		return check(code, "uint8 "+string(cmp))
+	}); err != nil {
+		return nil, err
+	}
+	return newFheBool(out), nil
+}
+
+// IfThenElse selects then when cond decrypts to true and otherwise els,
+// without revealing which, under sk.
+func (sk *Uint8ServerKey) IfThenElse(cond *FheBool, then, els *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	if err := checkUsable(cond, then, els); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
+	}
+	var out *C.struct_FheUint8
+	if err := withServerKey(sk, func() error {
+		return check(C.fhe_uint8_if_then_else(cond.ptr, then.ptr, els.ptr, &out), "uint8 if_then_else")
+	}); err != nil {
+		return nil, err
+	}
+	return newUint8Ciphertext(out), nil
+}
+
+// BoolAnd returns the encrypted conjunction of two encrypted booleans under
+// sk.
+func (sk *Uint8ServerKey) BoolAnd(lhs, rhs *FheBool) (*FheBool, error) {
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	var out *C.struct_FheBool
+	if err := withServerKey(sk, func() error {
+		return check(C.fhe_bool_bitand(lhs.ptr, rhs.ptr, &out), "bool and")
+	}); err != nil {
+		return nil, err
+	}
+	return newFheBool(out), nil
+}
+
+// IntCompare compares two integer ciphertexts of the same width under sk,
+// returning an encrypted boolean.
+func (sk *Uint8ServerKey) IntCompare(cmp Comparison, lhs, rhs *IntCiphertext) (*FheBool, error) {
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if lhs.bits != rhs.bits {
+		return nil, invalidCiphertext(fmt.Errorf("operand widths differ: uint%d and uint%d", lhs.bits, rhs.bits))
+	}
+	if err := checkComparison(cmp); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	bits := lhs.bits
+	var out *C.struct_FheBool
+	if err := withServerKey(sk, func() error {
+		var code C.int
+		switch bits {
+		case 2:
+			a, b := (*C.struct_FheUint2)(lhs.ptr), (*C.struct_FheUint2)(rhs.ptr)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint2_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint2_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint2_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint2_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint2_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint2_ge(a, b, &out)
+			}
+		case 4:
+			a, b := (*C.struct_FheUint4)(lhs.ptr), (*C.struct_FheUint4)(rhs.ptr)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint4_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint4_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint4_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint4_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint4_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint4_ge(a, b, &out)
+			}
+		case 16:
+			a, b := (*C.struct_FheUint16)(lhs.ptr), (*C.struct_FheUint16)(rhs.ptr)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint16_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint16_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint16_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint16_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint16_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint16_ge(a, b, &out)
+			}
+		case 32:
+			a, b := (*C.struct_FheUint32)(lhs.ptr), (*C.struct_FheUint32)(rhs.ptr)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint32_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint32_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint32_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint32_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint32_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint32_ge(a, b, &out)
+			}
+		case 64:
+			a, b := (*C.struct_FheUint64)(lhs.ptr), (*C.struct_FheUint64)(rhs.ptr)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint64_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint64_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint64_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint64_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint64_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint64_ge(a, b, &out)
+			}
+		}
+		return check(code, fmt.Sprintf("uint%d %s", bits, cmp))
+	}); err != nil {
+		return nil, err
+	}
+	return newFheBool(out), nil
+}
+
+// IntIfThenElse selects then when cond decrypts to true and otherwise els,
+// without revealing which, under sk. Both branches must have the same width.
+func (sk *Uint8ServerKey) IntIfThenElse(cond *FheBool, then, els *IntCiphertext) (*IntCiphertext, error) {
+	if err := checkUsable(cond, then, els); err != nil {
+		return nil, err
+	}
+	if then.bits != els.bits {
+		return nil, invalidCiphertext(fmt.Errorf("branch widths differ: uint%d and uint%d", then.bits, els.bits))
+	}
+	bits := then.bits
+	if err := checkMemory(intObjectKind(bits)); err != nil {
+		return nil, err
+	}
+	var ptr unsafe.Pointer
+	if err := withServerKey(sk, func() error {
+		var code C.int
+		switch bits {
+		case 2:
+			var out *C.struct_FheUint2
+			code = C.fhe_uint2_if_then_else(cond.ptr, (*C.struct_FheUint2)(then.ptr), (*C.struct_FheUint2)(els.ptr), &out)
+			ptr = unsafe.Pointer(out)
+		case 4:
+			var out *C.struct_FheUint4
+			code = C.fhe_uint4_if_then_else(cond.ptr, (*C.struct_FheUint4)(then.ptr), (*C.struct_FheUint4)(els.ptr), &out)
+			ptr = unsafe.Pointer(out)
+		case 16:
+			var out *C.struct_FheUint16
+			code = C.fhe_uint16_if_then_else(cond.ptr, (*C.struct_FheUint16)(then.ptr), (*C.struct_FheUint16)(els.ptr), &out)
+			ptr = unsafe.Pointer(out)
+		case 32:
+			var out *C.struct_FheUint32
+			code = C.fhe_uint32_if_then_else(cond.ptr, (*C.struct_FheUint32)(then.ptr), (*C.struct_FheUint32)(els.ptr), &out)
+			ptr = unsafe.Pointer(out)
+		case 64:
+			var out *C.struct_FheUint64
+			code = C.fhe_uint64_if_then_else(cond.ptr, (*C.struct_FheUint64)(then.ptr), (*C.struct_FheUint64)(els.ptr), &out)
+			ptr = unsafe.Pointer(out)
+		}
+		return check(code, fmt.Sprintf("uint%d if_then_else", bits))
+	}); err != nil {
+		return nil, err
+	}
+	return newIntCiphertext(bits, ptr), nil
+}
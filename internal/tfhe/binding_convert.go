@@ -0,0 +1,137 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+import "fmt"
+
+// BoolToUint8 casts an FheBool to a uint8 ciphertext of 0 or 1 under sk.
+func (sk *Uint8ServerKey) BoolToUint8(b *FheBool) (*Uint8Ciphertext, error) {
+	if err := checkUsable(b); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
+	}
+	var out *C.struct_FheUint8
+	if err := withServerKey(sk, func() error {
+		return check(C.fhe_bool_cast_into_fhe_uint8(b.ptr, &out), "cast bool to uint8")
+	}); err != nil {
+		return nil, err
+	}
+	return newUint8Ciphertext(out), nil
+}
+
+// Uint8ToBool returns an FheBool that is true when ct is nonzero, under sk.
+func (sk *Uint8ServerKey) Uint8ToBool(ct *Uint8Ciphertext) (*FheBool, error) {
+	if err := checkUsable(ct); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	var out *C.struct_FheBool
+	if err := withServerKey(sk, func() error {
+		return check(C.fhe_uint8_scalar_ne(ct.ptr, 0, &out), "cast uint8 to bool")
+	}); err != nil {
+		return nil, err
+	}
+	return newFheBool(out), nil
+}
+
+// Uint8Bits splits ct into its eight bits as FheBools, least significant
+// first, under sk.
+func (sk *Uint8ServerKey) Uint8Bits(ct *Uint8Ciphertext) ([]*FheBool, error) {
+	if err := checkUsable(ct); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	ptrs := make([]*C.struct_FheBool, 0, 8)
+	err := withServerKey(sk, func() error {
+		for i := range 8 {
+			var masked *C.struct_FheUint8
+			if err := check(C.fhe_uint8_scalar_bitand(ct.ptr, C.uchar(1<<i), &masked), "uint8 bit mask"); err != nil {
+				return err
+			}
+			var bit *C.struct_FheBool
+			code := C.fhe_uint8_scalar_ne(masked, 0, &bit)
+			C.fhe_uint8_destroy(masked)
+			if err := check(code, "uint8 bit test"); err != nil {
+				return err
+			}
+			ptrs = append(ptrs, bit)
+		}
+		return nil
+	})
+	if err != nil {
+		for _, p := range ptrs {
+			C.fhe_bool_destroy(p)
+		}
+		return nil, err
+	}
+	bits := make([]*FheBool, len(ptrs))
+	for i, p := range ptrs {
+		bits[i] = newFheBool(p)
+	}
+	return bits, nil
+}
+
+// ComposeUint8 packs up to eight FheBools, least significant first, into a
+// uint8 ciphertext under sk; missing high bits are zero.
+func (sk *Uint8ServerKey) ComposeUint8(bits []*FheBool) (*Uint8Ciphertext, error) {
+	if len(bits) == 0 || len(bits) > 8 {
+		return nil, fmt.Errorf("compose uint8: %d bits, want 1 to 8", len(bits))
+	}
+	for _, b := range bits {
+		if err := checkUsable(b); err != nil {
+			return nil, err
+		}
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
+	}
+	var acc *C.struct_FheUint8
+	err := withServerKey(sk, func() error {
+		for i, b := range bits {
+			var v *C.struct_FheUint8
+			if err := check(C.fhe_bool_cast_into_fhe_uint8(b.ptr, &v), "cast bool to uint8"); err != nil {
+				return err
+			}
+			if i > 0 {
+				var shifted *C.struct_FheUint8
+				code := C.fhe_uint8_scalar_shl(v, C.uchar(i), &shifted)
+				C.fhe_uint8_destroy(v)
+				if err := check(code, "uint8 shl"); err != nil {
+					return err
+				}
+				v = shifted
+			}
+			if acc == nil {
+				acc = v
+				continue
+			}
+			var next *C.struct_FheUint8
+			code := C.fhe_uint8_bitor(acc, v, &next)
+			C.fhe_uint8_destroy(v)
+			C.fhe_uint8_destroy(acc)
+			acc = nil
+			if err := check(code, "uint8 bitor"); err != nil {
+				return err
+			}
+			acc = next
+		}
+		return nil
+	})
+	if err != nil {
+		if acc != nil {
+			C.fhe_uint8_destroy(acc)
+		}
+		return nil, err
+	}
+	return newUint8Ciphertext(acc), nil
+}
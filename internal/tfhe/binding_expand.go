@@ -0,0 +1,169 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// ExpandCompactList expands a serialized compact ciphertext list, as built
+// by a client under a compact public key, into serialized ciphertexts that
+// sk can evaluate on.
+func (sk *Uint8ServerKey) ExpandCompactList(data []byte) ([]ExpandedCiphertext, error) {
+	if err := checkSerialized(data, MaxCompactList); err != nil {
+		return nil, err
+	}
+	if err := isolate("compact_list", data); err != nil {
+		return nil, err
+	}
+	list, err := deserializeCompactList(data)
+	if err != nil {
+		return nil, err
+	}
+	defer C.compact_ciphertext_list_destroy(list)
+
+	var out []ExpandedCiphertext
+	err = withServerKey(sk, func() error {
+		var expander *C.struct_CompactCiphertextListExpander
+		if err := check(C.compact_ciphertext_list_expand(list, &expander), "expand compact ciphertext list"); err != nil {
+			return invalidCiphertext(err)
+		}
+		defer C.compact_ciphertext_list_expander_destroy(expander)
+		var n C.size_t
+		if err := check(C.compact_ciphertext_list_expander_len(expander, &n), "compact ciphertext list length"); err != nil {
+			return err
+		}
+		if n > MaxCompactListLen {
+			return invalidCiphertext(fmt.Errorf("compact list of %d ciphertexts exceeds limit of %d", n, MaxCompactListLen))
+		}
+		out = make([]ExpandedCiphertext, 0, int(n))
+		for i := C.size_t(0); i < n; i++ {
+			ct, err := expandElement(expander, i)
+			if err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+			out = append(out, ct)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func deserializeCompactList(data []byte) (*C.struct_CompactCiphertextList, error) {
+	view := C.struct_DynamicBufferView{
+		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
+		length:  C.size_t(len(data)),
+	}
+	var list *C.struct_CompactCiphertextList
+	if err := check(C.compact_ciphertext_list_deserialize(view, &list), "deserialize compact ciphertext list"); err != nil {
+		return nil, invalidCiphertext(err)
+	}
+	runtime.KeepAlive(data)
+	return list, nil
+}
+
+// checkCompactList deserializes data as a compact ciphertext list, for an
+// isolated worker.
+func checkCompactList(data []byte) error {
+	if err := checkSerialized(data, MaxCompactList); err != nil {
+		return err
+	}
+	list, err := deserializeCompactList(data)
+	if err != nil {
+		return err
+	}
+	C.compact_ciphertext_list_destroy(list)
+	return nil
+}
+
+// expandElement takes element i out of expander and serializes it.
+func expandElement(expander *C.struct_CompactCiphertextListExpander, i C.size_t) (ExpandedCiphertext, error) {
+	var kind C.FheTypes
+	if err := check(C.compact_ciphertext_list_expander_get_kind_of(expander, i, &kind), "compact ciphertext kind"); err != nil {
+		return ExpandedCiphertext{}, err
+	}
+	switch kind {
+	case C.Type_FheBool:
+		if err := checkMemory(objFheBoolCiphertext); err != nil {
+			return ExpandedCiphertext{}, err
+		}
+		var ct *C.struct_FheBool
+		if err := check(C.compact_ciphertext_list_expander_get_fhe_bool(expander, i, &ct), "expand bool"); err != nil {
+			return ExpandedCiphertext{}, err
+		}
+		b := newFheBool(ct)
+		defer b.Close()
+		data, err := b.Serialize()
+		return ExpandedCiphertext{Type: "bool", Data: data}, err
+	case C.Type_FheUint8:
+		if err := checkMemory(objUint8Ciphertext); err != nil {
+			return ExpandedCiphertext{}, err
+		}
+		var ct *C.struct_FheUint8
+		if err := check(C.compact_ciphertext_list_expander_get_fhe_uint8(expander, i, &ct), "expand uint8"); err != nil {
+			return ExpandedCiphertext{}, err
+		}
+		v := newUint8Ciphertext(ct)
+		defer v.Close()
+		data, err := v.Uint8Serialize()
+		return ExpandedCiphertext{Type: "uint8", Data: data}, err
+	}
+	var bits int
+	switch kind {
+	case C.Type_FheUint2:
+		bits = 2
+	case C.Type_FheUint4:
+		bits = 4
+	case C.Type_FheUint16:
+		bits = 16
+	case C.Type_FheUint32:
+		bits = 32
+	case C.Type_FheUint64:
+		bits = 64
+	default:
+		return ExpandedCiphertext{}, invalidCiphertext(fmt.Errorf("unsupported ciphertext kind %d", kind))
+	}
+	if err := checkMemory(intObjectKind(bits)); err != nil {
+		return ExpandedCiphertext{}, err
+	}
+	var ptr unsafe.Pointer
+	var code C.int
+	switch bits {
+	case 2:
+		var ct *C.struct_FheUint2
+		code = C.compact_ciphertext_list_expander_get_fhe_uint2(expander, i, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 4:
+		var ct *C.struct_FheUint4
+		code = C.compact_ciphertext_list_expander_get_fhe_uint4(expander, i, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 16:
+		var ct *C.struct_FheUint16
+		code = C.compact_ciphertext_list_expander_get_fhe_uint16(expander, i, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 32:
+		var ct *C.struct_FheUint32
+		code = C.compact_ciphertext_list_expander_get_fhe_uint32(expander, i, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 64:
+		var ct *C.struct_FheUint64
+		code = C.compact_ciphertext_list_expander_get_fhe_uint64(expander, i, &ct)
+		ptr = unsafe.Pointer(ct)
+	}
+	if err := check(code, fmt.Sprintf("expand uint%d", bits)); err != nil {
+		return ExpandedCiphertext{}, err
+	}
+	v := newIntCiphertext(bits, ptr)
+	defer v.Close()
+	data, err := v.Serialize()
+	return ExpandedCiphertext{Type: fmt.Sprintf("uint%d", bits), Data: data}, err
+}
@@ -0,0 +1,95 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+
+// compressed_kind returns the width of the compressed integer ciphertext v
+// deserializes as, 1 for a compressed FheBool, or 0 if it is none of them.
+static int compressed_kind(struct DynamicBufferView v) {
+	struct CompressedFheBool *b;
+	if (compressed_fhe_bool_deserialize(v, &b) == 0) {
+		compressed_fhe_bool_destroy(b);
+		return 1;
+	}
+#define TRY(bits) { \
+	struct CompressedFheUint##bits *ct; \
+	if (compressed_fhe_uint##bits##_deserialize(v, &ct) == 0) { \
+		compressed_fhe_uint##bits##_destroy(ct); \
+		return bits; \
+	} \
+}
+	TRY(2) TRY(4) TRY(8) TRY(16) TRY(32) TRY(64)
+#undef TRY
+	return 0;
+}
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// probeCiphertext reports whether data deserializes as a ciphertext of typ
+// and, when tfhe-c can tell, whether it is a trivial encryption. tfhe-c
+// cannot for boolean API ciphertexts.
+func probeCiphertext(typ string, data []byte) (ok bool, trivial *bool) {
+	switch typ {
+	case TypeBoolean:
+		ct, err := DeserializeCiphertext(data)
+		if err != nil {
+			return false, nil
+		}
+		_ = ct.Close()
+		return true, nil
+	case TypeBool:
+		ct, err := DeserializeFheBool(data)
+		if err != nil {
+			return false, nil
+		}
+		defer ct.Close()
+		return true, trivialOf(ct)
+	case TypeUint8:
+		ct, err := Uint8Deserialize(data)
+		if err != nil {
+			return false, nil
+		}
+		defer ct.Close()
+		return true, trivialOf(ct)
+	}
+	for _, bits := range IntWidths {
+		if typ != fmt.Sprintf("uint%d", bits) {
+			continue
+		}
+		ct, err := DeserializeInt(bits, data)
+		if err != nil {
+			return false, nil
+		}
+		defer ct.Close()
+		return true, trivialOf(ct)
+	}
+	return false, nil
+}
+
+// probeCompressed returns the type of the compressed ciphertext data holds,
+// or "" if it holds none.
+func probeCompressed(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	view := C.struct_DynamicBufferView{
+		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
+		length:  C.size_t(len(data)),
+	}
+	kind := C.compressed_kind(view)
+	runtime.KeepAlive(data)
+	switch kind {
+	case 0:
+		return ""
+	case 1:
+		return TypeBool
+	}
+	return fmt.Sprintf("uint%d", kind)
+}
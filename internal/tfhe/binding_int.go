@@ -0,0 +1,413 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// IntCiphertext wraps an FheUint2, FheUint4, FheUint16, FheUint32 or
+// FheUint64 pointer from the C API. These integer types share the high-level
+// client, server and public keys used for uint8.
+type IntCiphertext struct {
+	lifecycle
+	bits   int
+	ptr    unsafe.Pointer
+	origin []uintptr // creation stack, recorded in leak-detection mode
+}
+
+// Bits returns the ciphertext's bit width.
+func (c *IntCiphertext) Bits() int { return c.bits }
+
+// newIntCiphertext wraps ptr, registering a finalizer and memory accounting.
+func newIntCiphertext(bits int, ptr unsafe.Pointer) *IntCiphertext {
+	ct := &IntCiphertext{bits: bits, ptr: ptr, origin: captureOrigin()}
+	trackObject(intObjectKind(bits))
+	runtime.SetFinalizer(ct, func(c *IntCiphertext) {
+		if c.ptr != nil {
+			reportLeak(intObjectKind(c.bits), c.origin)
+		}
+		_ = c.Close()
+	})
+	return ct
+}
+
+// EncryptInt encrypts value as an unsigned integer of the given width with the client key.
+func EncryptInt(client *Uint8ClientKey, bits int, value uint64) (*IntCiphertext, error) {
+	if err := client.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	if err := checkIntValue(bits, value); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(intObjectKind(bits)); err != nil {
+		return nil, err
+	}
+	var ptr unsafe.Pointer
+	var code C.int
+	switch bits {
+	case 2:
+		var ct *C.struct_FheUint2
+		code = C.fhe_uint2_try_encrypt_with_client_key_u8(C.uint8_t(value), client.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 4:
+		var ct *C.struct_FheUint4
+		code = C.fhe_uint4_try_encrypt_with_client_key_u8(C.uint8_t(value), client.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 16:
+		var ct *C.struct_FheUint16
+		code = C.fhe_uint16_try_encrypt_with_client_key_u16(C.uint16_t(value), client.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 32:
+		var ct *C.struct_FheUint32
+		code = C.fhe_uint32_try_encrypt_with_client_key_u32(C.uint32_t(value), client.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 64:
+		var ct *C.struct_FheUint64
+		code = C.fhe_uint64_try_encrypt_with_client_key_u64(C.uint64_t(value), client.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	}
+	if err := check(code, fmt.Sprintf("encrypt uint%d", bits)); err != nil {
+		return nil, err
+	}
+	return newIntCiphertext(bits, ptr), nil
+}
+
+// EncryptIntPublic encrypts value as an unsigned integer of the given width with the public key.
+func EncryptIntPublic(pub *Uint8PublicKey, bits int, value uint64) (*IntCiphertext, error) {
+	if err := pub.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	if err := checkIntValue(bits, value); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(intObjectKind(bits)); err != nil {
+		return nil, err
+	}
+	var ptr unsafe.Pointer
+	var code C.int
+	switch bits {
+	case 2:
+		var ct *C.struct_FheUint2
+		code = C.fhe_uint2_try_encrypt_with_public_key_u8(C.uint8_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 4:
+		var ct *C.struct_FheUint4
+		code = C.fhe_uint4_try_encrypt_with_public_key_u8(C.uint8_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 16:
+		var ct *C.struct_FheUint16
+		code = C.fhe_uint16_try_encrypt_with_public_key_u16(C.uint16_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 32:
+		var ct *C.struct_FheUint32
+		code = C.fhe_uint32_try_encrypt_with_public_key_u32(C.uint32_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 64:
+		var ct *C.struct_FheUint64
+		code = C.fhe_uint64_try_encrypt_with_public_key_u64(C.uint64_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	}
+	if err := check(code, fmt.Sprintf("encrypt uint%d with public key", bits)); err != nil {
+		return nil, err
+	}
+	return newIntCiphertext(bits, ptr), nil
+}
+
+// DecryptInt decrypts an integer ciphertext with the client key.
+func DecryptInt(client *Uint8ClientKey, ct *IntCiphertext) (uint64, error) {
+	if err := client.usable(); err != nil {
+		return 0, err
+	}
+	if err := ct.usable(); err != nil {
+		return 0, err
+	}
+	var value uint64
+	var code C.int
+	switch ct.bits {
+	case 2:
+		var out C.uint8_t
+		code = C.fhe_uint2_decrypt((*C.struct_FheUint2)(ct.ptr), client.ptr, &out)
+		value = uint64(out)
+	case 4:
+		var out C.uint8_t
+		code = C.fhe_uint4_decrypt((*C.struct_FheUint4)(ct.ptr), client.ptr, &out)
+		value = uint64(out)
+	case 16:
+		var out C.uint16_t
+		code = C.fhe_uint16_decrypt((*C.struct_FheUint16)(ct.ptr), client.ptr, &out)
+		value = uint64(out)
+	case 32:
+		var out C.uint32_t
+		code = C.fhe_uint32_decrypt((*C.struct_FheUint32)(ct.ptr), client.ptr, &out)
+		value = uint64(out)
+	case 64:
+		var out C.uint64_t
+		code = C.fhe_uint64_decrypt((*C.struct_FheUint64)(ct.ptr), client.ptr, &out)
+		value = uint64(out)
+	}
+	if err := check(code, fmt.Sprintf("decrypt uint%d", ct.bits)); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+// Close releases the underlying ciphertext.
+func (c *IntCiphertext) Close() error {
+	if c == nil || !c.markClosed() || c.ptr == nil {
+		return nil
+	}
+	var code C.int
+	switch c.bits {
+	case 2:
+		code = C.fhe_uint2_destroy((*C.struct_FheUint2)(c.ptr))
+	case 4:
+		code = C.fhe_uint4_destroy((*C.struct_FheUint4)(c.ptr))
+	case 16:
+		code = C.fhe_uint16_destroy((*C.struct_FheUint16)(c.ptr))
+	case 32:
+		code = C.fhe_uint32_destroy((*C.struct_FheUint32)(c.ptr))
+	case 64:
+		code = C.fhe_uint64_destroy((*C.struct_FheUint64)(c.ptr))
+	}
+	if err := check(code, fmt.Sprintf("destroy uint%d ciphertext", c.bits)); err != nil {
+		return err
+	}
+	c.ptr = nil
+	untrackObject(intObjectKind(c.bits))
+	return nil
+}
+
+func (c *IntCiphertext) usable() error {
+	if c == nil {
+		return errCiphertextNil
+	}
+	return c.state(c.ptr != nil, errCiphertextNil, errCiphertextClosed)
+}
+
+// intOp identifies a binary integer operation.
+type intOp int
+
+const (
+	intAdd intOp = iota
+	intBitAnd
+	intBitXor
+)
+
+var intOpNames = [...]string{intAdd: "add", intBitAnd: "bitand", intBitXor: "bitxor"}
+
+// intBinary runs op on two ciphertexts of the same width under sk.
+func intBinary(sk *Uint8ServerKey, op intOp, lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if lhs.bits != rhs.bits {
+		return nil, invalidCiphertext(fmt.Errorf("operand widths differ: uint%d and uint%d", lhs.bits, rhs.bits))
+	}
+	bits := lhs.bits
+	if err := checkMemory(intObjectKind(bits)); err != nil {
+		return nil, err
+	}
+	var ptr unsafe.Pointer
+	err := withServerKey(sk, func() error {
+		var code C.int
+		switch bits {
+		case 2:
+			a, b := (*C.struct_FheUint2)(lhs.ptr), (*C.struct_FheUint2)(rhs.ptr)
+			var out *C.struct_FheUint2
+			switch op {
+			case intAdd:
+				code = C.fhe_uint2_add(a, b, &out)
+			case intBitAnd:
+				code = C.fhe_uint2_bitand(a, b, &out)
+			case intBitXor:
+				code = C.fhe_uint2_bitxor(a, b, &out)
+			}
+			ptr = unsafe.Pointer(out)
+		case 4:
+			a, b := (*C.struct_FheUint4)(lhs.ptr), (*C.struct_FheUint4)(rhs.ptr)
+			var out *C.struct_FheUint4
+			switch op {
+			case intAdd:
+				code = C.fhe_uint4_add(a, b, &out)
+			case intBitAnd:
+				code = C.fhe_uint4_bitand(a, b, &out)
+			case intBitXor:
+				code = C.fhe_uint4_bitxor(a, b, &out)
+			}
+			ptr = unsafe.Pointer(out)
+		case 16:
+			a, b := (*C.struct_FheUint16)(lhs.ptr), (*C.struct_FheUint16)(rhs.ptr)
+			var out *C.struct_FheUint16
+			switch op {
+			case intAdd:
+				code = C.fhe_uint16_add(a, b, &out)
+			case intBitAnd:
+				code = C.fhe_uint16_bitand(a, b, &out)
+			case intBitXor:
+				code = C.fhe_uint16_bitxor(a, b, &out)
+			}
+			ptr = unsafe.Pointer(out)
+		case 32:
+			a, b := (*C.struct_FheUint32)(lhs.ptr), (*C.struct_FheUint32)(rhs.ptr)
+			var out *C.struct_FheUint32
+			switch op {
+			case intAdd:
+				code = C.fhe_uint32_add(a, b, &out)
+			case intBitAnd:
+				code = C.fhe_uint32_bitand(a, b, &out)
+			case intBitXor:
+				code = C.fhe_uint32_bitxor(a, b, &out)
+			}
+			ptr = unsafe.Pointer(out)
+		case 64:
+			a, b := (*C.struct_FheUint64)(lhs.ptr), (*C.struct_FheUint64)(rhs.ptr)
+			var out *C.struct_FheUint64
+			switch op {
+			case intAdd:
+				code = C.fhe_uint64_add(a, b, &out)
+			case intBitAnd:
+				code = C.fhe_uint64_bitand(a, b, &out)
+			case intBitXor:
+				code = C.fhe_uint64_bitxor(a, b, &out)
+			}
+			ptr = unsafe.Pointer(out)
+		}
+		return check(code, fmt.Sprintf("uint%d %s", bits, intOpNames[op]))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newIntCiphertext(bits, ptr), nil
+}
+
+// IntAdd performs homomorphic addition under sk, wrapping on overflow.
+func (sk *Uint8ServerKey) IntAdd(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return intBinary(sk, intAdd, lhs, rhs)
+}
+
+// IntBitAnd performs homomorphic bitwise AND under sk.
+func (sk *Uint8ServerKey) IntBitAnd(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return intBinary(sk, intBitAnd, lhs, rhs)
+}
+
+// IntBitXor performs homomorphic bitwise XOR under sk.
+func (sk *Uint8ServerKey) IntBitXor(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return intBinary(sk, intBitXor, lhs, rhs)
+}
+
+// Serialize serializes the ciphertext and frees the C buffer.
+func (c *IntCiphertext) Serialize() ([]byte, error) {
+	if err := c.usable(); err != nil {
+		return nil, err
+	}
+	var buf C.struct_DynamicBuffer
+	var code C.int
+	switch c.bits {
+	case 2:
+		code = C.fhe_uint2_serialize((*C.struct_FheUint2)(c.ptr), &buf)
+	case 4:
+		code = C.fhe_uint4_serialize((*C.struct_FheUint4)(c.ptr), &buf)
+	case 16:
+		code = C.fhe_uint16_serialize((*C.struct_FheUint16)(c.ptr), &buf)
+	case 32:
+		code = C.fhe_uint32_serialize((*C.struct_FheUint32)(c.ptr), &buf)
+	case 64:
+		code = C.fhe_uint64_serialize((*C.struct_FheUint64)(c.ptr), &buf)
+	}
+	if err := check(code, fmt.Sprintf("serialize uint%d ciphertext", c.bits)); err != nil {
+		return nil, err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+
+	length := int(buf.length)
+	if length == 0 {
+		return []byte{}, nil
+	}
+	return C.GoBytes(unsafe.Pointer(buf.pointer), C.int(length)), nil
+}
+
+// WithBytes serializes the ciphertext and calls fn with the bytes in place,
+// without copying them into Go memory. They are freed when fn returns, so fn
+// must not modify or retain them.
+func (c *IntCiphertext) WithBytes(fn func([]byte) error) error {
+	if err := c.usable(); err != nil {
+		return err
+	}
+	return withBuffer(fmt.Sprintf("serialize uint%d ciphertext", c.bits), func(buf *C.struct_DynamicBuffer) C.int {
+		switch c.bits {
+		case 2:
+			return C.fhe_uint2_serialize((*C.struct_FheUint2)(c.ptr), buf)
+		case 4:
+			return C.fhe_uint4_serialize((*C.struct_FheUint4)(c.ptr), buf)
+		case 16:
+			return C.fhe_uint16_serialize((*C.struct_FheUint16)(c.ptr), buf)
+		case 32:
+			return C.fhe_uint32_serialize((*C.struct_FheUint32)(c.ptr), buf)
+		default:
+			return C.fhe_uint64_serialize((*C.struct_FheUint64)(c.ptr), buf)
+		}
+	}, fn)
+}
+
+// DeserializeInt reconstructs an integer ciphertext of the given width from bytes.
+func DeserializeInt(bits int, data []byte) (*IntCiphertext, error) {
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	if err := checkSerialized(data, CurrentLimits().MaxIntCiphertext(bits)); err != nil {
+		return nil, err
+	}
+	if err := isolate(fmt.Sprintf("uint%d", bits), data); err != nil {
+		return nil, err
+	}
+	view := C.struct_DynamicBufferView{
+		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
+		length:  C.size_t(len(data)),
+	}
+	if err := checkMemory(intObjectKind(bits)); err != nil {
+		return nil, err
+	}
+	var ptr unsafe.Pointer
+	var code C.int
+	switch bits {
+	case 2:
+		var ct *C.struct_FheUint2
+		code = C.fhe_uint2_deserialize(view, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 4:
+		var ct *C.struct_FheUint4
+		code = C.fhe_uint4_deserialize(view, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 16:
+		var ct *C.struct_FheUint16
+		code = C.fhe_uint16_deserialize(view, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 32:
+		var ct *C.struct_FheUint32
+		code = C.fhe_uint32_deserialize(view, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 64:
+		var ct *C.struct_FheUint64
+		code = C.fhe_uint64_deserialize(view, &ct)
+		ptr = unsafe.Pointer(ct)
+	}
+	if err := check(code, fmt.Sprintf("deserialize uint%d ciphertext", bits)); err != nil {
+		return nil, invalidCiphertext(err)
+	}
+	out := newIntCiphertext(bits, ptr)
+	runtime.KeepAlive(data)
+	return out, nil
+}
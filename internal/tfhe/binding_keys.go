@@ -0,0 +1,234 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// goBytes copies a C buffer into Go memory; the caller still destroys buf.
+func goBytes(buf *C.struct_DynamicBuffer) []byte {
+	if buf.length == 0 {
+		return []byte{}
+	}
+	return C.GoBytes(unsafe.Pointer(buf.pointer), C.int(buf.length))
+}
+
+// keyView checks serialized key material and returns a view over it. Keys
+// come from the operator rather than clients, so no size limit applies.
+func keyView(data []byte) (C.struct_DynamicBufferView, error) {
+	if len(data) < minSerializedLen {
+		return C.struct_DynamicBufferView{}, fmt.Errorf("%w: %d bytes is too short", ErrInvalidKey, len(data))
+	}
+	return C.struct_DynamicBufferView{
+		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
+		length:  C.size_t(len(data)),
+	}, nil
+}
+
+// Serialize serializes the boolean client key and frees the C buffer.
+func (c *ClientKey) Serialize() ([]byte, error) {
+	if err := c.usable(); err != nil {
+		return nil, err
+	}
+	var buf C.struct_DynamicBuffer
+	if err := check(C.boolean_serialize_client_key(c.ptr, &buf), "serialize client key"); err != nil {
+		return nil, err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	return goBytes(&buf), nil
+}
+
+// WithBytes serializes the key and calls fn with the bytes in place, without
+// copying them into Go memory. They are freed when fn returns, so fn must not
+// modify or retain them.
+func (c *ClientKey) WithBytes(fn func([]byte) error) error {
+	if err := c.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize client key", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.boolean_serialize_client_key(c.ptr, buf)
+	}, fn)
+}
+
+// DeserializeClientKey reconstructs a boolean client key from bytes.
+func DeserializeClientKey(data []byte) (*ClientKey, error) {
+	view, err := keyView(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanClientKey); err != nil {
+		return nil, err
+	}
+	var ck *C.struct_BooleanClientKey
+	if err := check(C.boolean_deserialize_client_key(view, &ck), "deserialize client key"); err != nil {
+		return nil, invalidKey(err)
+	}
+	runtime.KeepAlive(data)
+	client := &ClientKey{ptr: ck}
+	trackObject(objBooleanClientKey)
+	runtime.SetFinalizer(client, func(c *ClientKey) { _ = c.Close() })
+	return client, nil
+}
+
+// Serialize serializes the boolean server key and frees the C buffer.
+func (s *ServerKey) Serialize() ([]byte, error) {
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	var buf C.struct_DynamicBuffer
+	if err := check(C.boolean_serialize_server_key(s.ptr, &buf), "serialize server key"); err != nil {
+		return nil, err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	return goBytes(&buf), nil
+}
+
+// WithBytes serializes the key and calls fn with the bytes in place, without
+// copying them into Go memory. They are freed when fn returns, so fn must not
+// modify or retain them.
+func (s *ServerKey) WithBytes(fn func([]byte) error) error {
+	if err := s.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize server key", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.boolean_serialize_server_key(s.ptr, buf)
+	}, fn)
+}
+
+// DeserializeServerKey reconstructs a boolean server key from bytes.
+func DeserializeServerKey(data []byte) (*ServerKey, error) {
+	view, err := keyView(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanServerKey); err != nil {
+		return nil, err
+	}
+	var sk *C.struct_BooleanServerKey
+	if err := check(C.boolean_deserialize_server_key(view, &sk), "deserialize server key"); err != nil {
+		return nil, invalidKey(err)
+	}
+	runtime.KeepAlive(data)
+	server := &ServerKey{ptr: sk}
+	trackObject(objBooleanServerKey)
+	runtime.SetFinalizer(server, func(s *ServerKey) { _ = s.Close() })
+	return server, nil
+}
+
+// Serialize serializes the integer client key and frees the C buffer.
+func (c *Uint8ClientKey) Serialize() ([]byte, error) {
+	if err := c.usable(); err != nil {
+		return nil, err
+	}
+	var buf C.struct_DynamicBuffer
+	if err := check(C.client_key_serialize(c.ptr, &buf), "serialize integer client key"); err != nil {
+		return nil, err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	return goBytes(&buf), nil
+}
+
+// WithBytes serializes the key and calls fn with the bytes in place, without
+// copying them into Go memory. They are freed when fn returns, so fn must not
+// modify or retain them.
+func (c *Uint8ClientKey) WithBytes(fn func([]byte) error) error {
+	if err := c.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize integer client key", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.client_key_serialize(c.ptr, buf)
+	}, fn)
+}
+
+// DeserializeUint8ClientKey reconstructs an integer client key from bytes.
+func DeserializeUint8ClientKey(data []byte) (*Uint8ClientKey, error) {
+	view, err := keyView(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8ClientKey); err != nil {
+		return nil, err
+	}
+	var ck *C.struct_ClientKey
+	if err := check(C.client_key_deserialize(view, &ck), "deserialize integer client key"); err != nil {
+		return nil, invalidKey(err)
+	}
+	runtime.KeepAlive(data)
+	client := &Uint8ClientKey{ptr: ck}
+	trackObject(objUint8ClientKey)
+	runtime.SetFinalizer(client, func(c *Uint8ClientKey) { _ = c.Close() })
+	return client, nil
+}
+
+// Serialize serializes the integer server key and frees the C buffer.
+func (s *Uint8ServerKey) Serialize() ([]byte, error) {
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	var buf C.struct_DynamicBuffer
+	if err := check(C.server_key_serialize(s.ptr, &buf), "serialize integer server key"); err != nil {
+		return nil, err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	return goBytes(&buf), nil
+}
+
+// WithBytes serializes the key and calls fn with the bytes in place, without
+// copying them into Go memory. They are freed when fn returns, so fn must not
+// modify or retain them.
+func (s *Uint8ServerKey) WithBytes(fn func([]byte) error) error {
+	if err := s.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize integer server key", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.server_key_serialize(s.ptr, buf)
+	}, fn)
+}
+
+// DeserializeUint8ServerKey reconstructs an integer server key from bytes.
+// Operations take it explicitly, as in sk.Add(lhs, rhs).
+func DeserializeUint8ServerKey(data []byte) (*Uint8ServerKey, error) {
+	view, err := keyView(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8ServerKey); err != nil {
+		return nil, err
+	}
+	var sk *C.struct_ServerKey
+	if err := check(C.server_key_deserialize(view, &sk), "deserialize integer server key"); err != nil {
+		return nil, invalidKey(err)
+	}
+	runtime.KeepAlive(data)
+	server := &Uint8ServerKey{ptr: sk}
+	trackObject(objUint8ServerKey)
+	runtime.SetFinalizer(server, func(s *Uint8ServerKey) { _ = s.Close() })
+	return server, nil
+}
+
+// DeserializeUint8PublicKey reconstructs an integer public key from bytes.
+func DeserializeUint8PublicKey(data []byte) (*Uint8PublicKey, error) {
+	view, err := keyView(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8PublicKey); err != nil {
+		return nil, err
+	}
+	var pk *C.struct_PublicKey
+	if err := check(C.public_key_deserialize(view, &pk), "deserialize public key"); err != nil {
+		return nil, invalidKey(err)
+	}
+	runtime.KeepAlive(data)
+	pub := &Uint8PublicKey{ptr: pk}
+	trackObject(objUint8PublicKey)
+	runtime.SetFinalizer(pub, func(p *Uint8PublicKey) { _ = p.Close() })
+	return pub, nil
+}
@@ -0,0 +1,208 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+// Uint8CompactPublicKey wraps a CompactPublicKey: a much smaller public key
+// for the integer types, suited to distribution to browsers.
+type Uint8CompactPublicKey struct {
+	lifecycle
+	ptr *C.struct_CompactPublicKey
+}
+
+// Serialize serializes the public key and frees the C buffer.
+func (p *Uint8PublicKey) Serialize() ([]byte, error) {
+	if err := p.usable(); err != nil {
+		return nil, err
+	}
+	var buf C.struct_DynamicBuffer
+	if err := check(C.public_key_serialize(p.ptr, &buf), "serialize public key"); err != nil {
+		return nil, err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	return C.GoBytes(unsafe.Pointer(buf.pointer), C.int(buf.length)), nil
+}
+
+// WithBytes serializes the key and calls fn with the bytes in place, without
+// copying them into Go memory. They are freed when fn returns, so fn must not
+// modify or retain them.
+func (p *Uint8PublicKey) WithBytes(fn func([]byte) error) error {
+	if err := p.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize public key", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.public_key_serialize(p.ptr, buf)
+	}, fn)
+}
+
+// NewUint8CompactPublicKey derives a CompactPublicKey from a client key.
+func NewUint8CompactPublicKey(client *Uint8ClientKey) (*Uint8CompactPublicKey, error) {
+	if err := client.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8CompactPublicKey); err != nil {
+		return nil, err
+	}
+	var pk *C.struct_CompactPublicKey
+	if err := check(C.compact_public_key_new(client.ptr, &pk), "new compact public key"); err != nil {
+		return nil, err
+	}
+	pub := &Uint8CompactPublicKey{ptr: pk}
+	trackObject(objUint8CompactPublicKey)
+	runtime.SetFinalizer(pub, func(p *Uint8CompactPublicKey) { _ = p.Close() })
+	return pub, nil
+}
+
+// Serialize serializes the compact public key and frees the C buffer.
+func (p *Uint8CompactPublicKey) Serialize() ([]byte, error) {
+	if err := p.usable(); err != nil {
+		return nil, err
+	}
+	var buf C.struct_DynamicBuffer
+	if err := check(C.compact_public_key_serialize(p.ptr, &buf), "serialize compact public key"); err != nil {
+		return nil, err
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	return C.GoBytes(unsafe.Pointer(buf.pointer), C.int(buf.length)), nil
+}
+
+// WithBytes serializes the key and calls fn with the bytes in place, without
+// copying them into Go memory. They are freed when fn returns, so fn must not
+// modify or retain them.
+func (p *Uint8CompactPublicKey) WithBytes(fn func([]byte) error) error {
+	if err := p.usable(); err != nil {
+		return err
+	}
+	return withBuffer("serialize compact public key", func(buf *C.struct_DynamicBuffer) C.int {
+		return C.compact_public_key_serialize(p.ptr, buf)
+	}, fn)
+}
+
+// Close releases the underlying CompactPublicKey.
+func (p *Uint8CompactPublicKey) Close() error {
+	if p == nil || !p.markClosed() || p.ptr == nil {
+		return nil
+	}
+	if err := check(C.compact_public_key_destroy(p.ptr), "destroy compact public key"); err != nil {
+		return err
+	}
+	p.ptr = nil
+	untrackObject(objUint8CompactPublicKey)
+	return nil
+}
+
+func (p *Uint8CompactPublicKey) usable() error {
+	if p == nil {
+		return errPublicKeyNil
+	}
+	return p.state(p.ptr != nil, errPublicKeyNil, errPublicKeyClosed)
+}
+
+// DeserializeUint8CompactPublicKey reconstructs a compact public key from
+// bytes, such as one a client derived from its own client key.
+func DeserializeUint8CompactPublicKey(data []byte) (*Uint8CompactPublicKey, error) {
+	view, err := keyView(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := isolate("compact_public_key", data); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8CompactPublicKey); err != nil {
+		return nil, err
+	}
+	var pk *C.struct_CompactPublicKey
+	if err := check(C.compact_public_key_deserialize(view, &pk), "deserialize compact public key"); err != nil {
+		return nil, invalidKey(err)
+	}
+	runtime.KeepAlive(data)
+	pub := &Uint8CompactPublicKey{ptr: pk}
+	trackObject(objUint8CompactPublicKey)
+	runtime.SetFinalizer(pub, func(p *Uint8CompactPublicKey) { _ = p.Close() })
+	return pub, nil
+}
+
+// EncryptUint8Compact encrypts a uint8 under a compact public key.
+func EncryptUint8Compact(pub *Uint8CompactPublicKey, value uint8) (*Uint8Ciphertext, error) {
+	if err := pub.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
+	}
+	var ct *C.struct_FheUint8
+	if err := check(C.fhe_uint8_try_encrypt_with_compact_public_key_u8(C.uchar(value), pub.ptr, &ct), "encrypt uint8 with compact public key"); err != nil {
+		return nil, err
+	}
+	return newUint8Ciphertext(ct), nil
+}
+
+// EncryptFheBoolCompact encrypts a boolean of the integer API under a
+// compact public key.
+func EncryptFheBoolCompact(pub *Uint8CompactPublicKey, value bool) (*FheBool, error) {
+	if err := pub.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	var ct *C.struct_FheBool
+	if err := check(C.fhe_bool_try_encrypt_with_compact_public_key_bool(C.bool(value), pub.ptr, &ct), "encrypt fhe bool with compact public key"); err != nil {
+		return nil, err
+	}
+	return newFheBool(ct), nil
+}
+
+// EncryptIntCompact encrypts a bits-wide unsigned integer under a compact
+// public key.
+func EncryptIntCompact(pub *Uint8CompactPublicKey, bits int, value uint64) (*IntCiphertext, error) {
+	if err := pub.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	if err := checkIntValue(bits, value); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(intObjectKind(bits)); err != nil {
+		return nil, err
+	}
+	var ptr unsafe.Pointer
+	var code C.int
+	switch bits {
+	case 2:
+		var ct *C.struct_FheUint2
+		code = C.fhe_uint2_try_encrypt_with_compact_public_key_u8(C.uint8_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 4:
+		var ct *C.struct_FheUint4
+		code = C.fhe_uint4_try_encrypt_with_compact_public_key_u8(C.uint8_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 16:
+		var ct *C.struct_FheUint16
+		code = C.fhe_uint16_try_encrypt_with_compact_public_key_u16(C.uint16_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 32:
+		var ct *C.struct_FheUint32
+		code = C.fhe_uint32_try_encrypt_with_compact_public_key_u32(C.uint32_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	case 64:
+		var ct *C.struct_FheUint64
+		code = C.fhe_uint64_try_encrypt_with_compact_public_key_u64(C.uint64_t(value), pub.ptr, &ct)
+		ptr = unsafe.Pointer(ct)
+	}
+	if err := check(code, fmt.Sprintf("encrypt uint%d with compact public key", bits)); err != nil {
+		return nil, err
+	}
+	return newIntCiphertext(bits, ptr), nil
+}
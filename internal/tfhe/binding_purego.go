@@ -0,0 +1,314 @@
+//go:build purego
+
+package tfhe
+
+import (
+	"fmt"
+	"runtime"
+
+	"tfhe-go/internal/tfhe/purego"
+)
+
+// Backend names the implementation the package was built with.
+const Backend = "purego"
+
+// serializationFormat identifies the ciphertext encoding, which is not
+// compatible with tfhe-c's.
+func serializationFormat() string { return fmt.Sprintf("purego/%d", purego.FormatVersion) }
+
+// ClientKey wraps a pure-Go boolean secret key.
+type ClientKey struct {
+	lifecycle
+	sk *purego.SecretKey
+}
+
+// ServerKey wraps a pure-Go boolean cloud key.
+type ServerKey struct {
+	lifecycle
+	ck *purego.CloudKey
+}
+
+// Ciphertext wraps a pure-Go boolean ciphertext.
+type Ciphertext struct {
+	lifecycle
+	ct     *purego.Ciphertext
+	origin []uintptr // creation stack, recorded in leak-detection mode
+}
+
+// GenerateBooleanKeys produces a client/server keypair using default TFHE parameters.
+func GenerateBooleanKeys() (*ClientKey, *ServerKey, error) {
+	sk, ck, err := purego.GenerateKeys(purego.DefaultParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	client := &ClientKey{sk: sk}
+	server := &ServerKey{ck: ck}
+	trackObject(objBooleanClientKey)
+	trackObject(objBooleanServerKey)
+	runtime.SetFinalizer(client, func(c *ClientKey) { _ = c.Close() })
+	runtime.SetFinalizer(server, func(s *ServerKey) { _ = s.Close() })
+	return client, server, nil
+}
+
+// Close drops the secret key.
+func (c *ClientKey) Close() error {
+	if c == nil || !c.markClosed() || c.sk == nil {
+		return nil
+	}
+	c.sk = nil
+	untrackObject(objBooleanClientKey)
+	return nil
+}
+
+func (c *ClientKey) usable() error {
+	if c == nil {
+		return errClientKeyNil
+	}
+	return c.state(c.sk != nil, errClientKeyNil, errClientKeyClosed)
+}
+
+// Close drops the cloud key.
+func (s *ServerKey) Close() error {
+	if s == nil || !s.markClosed() || s.ck == nil {
+		return nil
+	}
+	s.ck = nil
+	untrackObject(objBooleanServerKey)
+	return nil
+}
+
+func (s *ServerKey) usable() error {
+	if s == nil {
+		return errServerKeyNil
+	}
+	return s.state(s.ck != nil, errServerKeyNil, errServerKeyClosed)
+}
+
+// Close drops the ciphertext.
+func (c *Ciphertext) Close() error {
+	if c == nil || !c.markClosed() || c.ct == nil {
+		return nil
+	}
+	c.ct = nil
+	untrackObject(objBooleanCiphertext)
+	return nil
+}
+
+func (c *Ciphertext) usable() error {
+	if c == nil {
+		return errCiphertextNil
+	}
+	return c.state(c.ct != nil, errCiphertextNil, errCiphertextClosed)
+}
+
+// newCiphertext wraps ct, registering a finalizer and memory accounting.
+func newCiphertext(ct *purego.Ciphertext) *Ciphertext {
+	out := &Ciphertext{ct: ct, origin: captureOrigin()}
+	trackObject(objBooleanCiphertext)
+	runtime.SetFinalizer(out, func(c *Ciphertext) {
+		if c.ct != nil {
+			reportLeak(objBooleanCiphertext, c.origin)
+		}
+		_ = c.Close()
+	})
+	return out
+}
+
+// EncryptBool encrypts a boolean using the provided client key.
+func EncryptBool(client *ClientKey, value bool) (*Ciphertext, error) {
+	if err := client.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
+	}
+	ct, err := client.sk.Encrypt(value)
+	if err != nil {
+		return nil, err
+	}
+	return newCiphertext(ct), nil
+}
+
+// DecryptBool decrypts a ciphertext with the provided client key.
+func DecryptBool(client *ClientKey, ct *Ciphertext) (bool, error) {
+	if err := client.usable(); err != nil {
+		return false, err
+	}
+	if err := ct.usable(); err != nil {
+		return false, err
+	}
+	v, err := client.sk.Decrypt(ct.ct)
+	if err != nil {
+		return false, invalidCiphertext(err)
+	}
+	return v, nil
+}
+
+// gate runs a binary gate after the checks the native binding makes.
+func (s *ServerKey) gate(fn func(x, y *purego.Ciphertext) (*purego.Ciphertext, error), lhs, rhs *Ciphertext) (*Ciphertext, error) {
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkUsable(lhs, rhs); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
+	}
+	out, err := fn(lhs.ct, rhs.ct)
+	if err != nil {
+		return nil, invalidCiphertext(err)
+	}
+	return newCiphertext(out), nil
+}
+
+// And performs a homomorphic AND on two ciphertexts.
+func (s *ServerKey) And(lhs, rhs *Ciphertext) (*Ciphertext, error) {
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	return s.gate(s.ck.And, lhs, rhs)
+}
+
+// Or performs a homomorphic OR on two ciphertexts.
+func (s *ServerKey) Or(lhs, rhs *Ciphertext) (*Ciphertext, error) {
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	return s.gate(s.ck.Or, lhs, rhs)
+}
+
+// Xor performs a homomorphic XOR on two ciphertexts.
+func (s *ServerKey) Xor(lhs, rhs *Ciphertext) (*Ciphertext, error) {
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	return s.gate(s.ck.Xor, lhs, rhs)
+}
+
+// Not performs a homomorphic NOT on a ciphertext.
+func (s *ServerKey) Not(input *Ciphertext) (*Ciphertext, error) {
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	if err := input.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
+	}
+	out, err := s.ck.Not(input.ct)
+	if err != nil {
+		return nil, invalidCiphertext(err)
+	}
+	return newCiphertext(out), nil
+}
+
+// Serialize returns the ciphertext bytes.
+func (c *Ciphertext) Serialize() ([]byte, error) {
+	if err := c.usable(); err != nil {
+		return nil, err
+	}
+	return c.ct.MarshalBinary()
+}
+
+// WithBytes serializes the ciphertext and calls fn with the bytes. fn must
+// not modify or retain them, as with the native backend.
+func (c *Ciphertext) WithBytes(fn func([]byte) error) error {
+	data, err := c.Serialize()
+	if err != nil {
+		return err
+	}
+	return fn(data)
+}
+
+// IsTrivial reports whether c is a trivial encryption, whose value anyone
+// can read without the secret key.
+func (c *Ciphertext) IsTrivial() (bool, error) {
+	if err := c.usable(); err != nil {
+		return false, err
+	}
+	return c.ct.Trivial(), nil
+}
+
+// DeserializeCiphertext reconstructs a ciphertext from serialized bytes.
+func DeserializeCiphertext(data []byte) (*Ciphertext, error) {
+	if err := checkSerialized(data, CurrentLimits().MaxBooleanCiphertext); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
+	}
+	ct, err := purego.UnmarshalCiphertext(data)
+	if err != nil {
+		return nil, invalidCiphertext(err)
+	}
+	return newCiphertext(ct), nil
+}
+
+// Serialize serializes the boolean client key.
+func (c *ClientKey) Serialize() ([]byte, error) {
+	if err := c.usable(); err != nil {
+		return nil, err
+	}
+	return c.sk.MarshalBinary()
+}
+
+// WithBytes serializes the key and calls fn with the bytes. fn must not
+// modify or retain them, as with the native backend.
+func (c *ClientKey) WithBytes(fn func([]byte) error) error {
+	data, err := c.Serialize()
+	if err != nil {
+		return err
+	}
+	return fn(data)
+}
+
+// DeserializeClientKey reconstructs a boolean client key from bytes.
+func DeserializeClientKey(data []byte) (*ClientKey, error) {
+	if err := checkMemory(objBooleanClientKey); err != nil {
+		return nil, err
+	}
+	sk, err := purego.UnmarshalSecretKey(data)
+	if err != nil {
+		return nil, invalidKey(err)
+	}
+	client := &ClientKey{sk: sk}
+	trackObject(objBooleanClientKey)
+	runtime.SetFinalizer(client, func(c *ClientKey) { _ = c.Close() })
+	return client, nil
+}
+
+// Serialize serializes the boolean server key.
+func (s *ServerKey) Serialize() ([]byte, error) {
+	if err := s.usable(); err != nil {
+		return nil, err
+	}
+	return s.ck.MarshalBinary()
+}
+
+// WithBytes serializes the key and calls fn with the bytes. fn must not
+// modify or retain them, as with the native backend.
+func (s *ServerKey) WithBytes(fn func([]byte) error) error {
+	data, err := s.Serialize()
+	if err != nil {
+		return err
+	}
+	return fn(data)
+}
+
+// DeserializeServerKey reconstructs a boolean server key from bytes. The
+// key is expanded on load, which takes about as long as generating one.
+func DeserializeServerKey(data []byte) (*ServerKey, error) {
+	if err := checkMemory(objBooleanServerKey); err != nil {
+		return nil, err
+	}
+	ck, err := purego.UnmarshalCloudKey(data)
+	if err != nil {
+		return nil, invalidKey(err)
+	}
+	server := &ServerKey{ck: ck}
+	trackObject(objBooleanServerKey)
+	runtime.SetFinalizer(server, func(s *ServerKey) { _ = s.Close() })
+	return server, nil
+}
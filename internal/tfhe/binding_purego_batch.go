@@ -0,0 +1,28 @@
+//go:build purego
+
+package tfhe
+
+import "tfhe-go/internal/tfhe/purego"
+
+// gateRun applies gate to pairs, storing the results in out.
+func (s *ServerKey) gateRun(gate Gate, pairs []Pair, out []*Ciphertext) error {
+	if err := s.usable(); err != nil {
+		return err
+	}
+	fn := map[Gate]func(x, y *purego.Ciphertext) (*purego.Ciphertext, error){
+		GateAnd: s.ck.And,
+		GateOr:  s.ck.Or,
+		GateXor: s.ck.Xor,
+	}[gate]
+	for i, p := range pairs {
+		if err := checkUsable(p.LHS, p.RHS); err != nil {
+			return err
+		}
+		ct, err := fn(p.LHS.ct, p.RHS.ct)
+		if err != nil {
+			return invalidCiphertext(err)
+		}
+		out[i] = newCiphertext(ct)
+	}
+	return nil
+}
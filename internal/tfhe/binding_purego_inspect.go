@@ -0,0 +1,22 @@
+//go:build purego
+
+package tfhe
+
+// probeCiphertext reports whether data deserializes as a ciphertext of typ
+// and whether it is a trivial encryption. Only boolean API ciphertexts
+// exist in the pure-Go backend.
+func probeCiphertext(typ string, data []byte) (ok bool, trivial *bool) {
+	if typ != TypeBoolean {
+		return false, nil
+	}
+	ct, err := DeserializeCiphertext(data)
+	if err != nil {
+		return false, nil
+	}
+	defer ct.Close()
+	return true, trivialOf(ct)
+}
+
+// probeCompressed returns "": the pure-Go backend has no compressed
+// ciphertexts.
+func probeCompressed(data []byte) string { return "" }
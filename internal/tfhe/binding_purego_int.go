@@ -0,0 +1,406 @@
+//go:build purego
+
+package tfhe
+
+import "fmt"
+
+// The pure-Go backend implements only the boolean gates. The integer types
+// exist so the services build and start, but every operation on them
+// reports ErrUnsupported.
+
+// Uint8ClientKey stands in for the integer client key.
+type Uint8ClientKey struct{}
+
+// Uint8ServerKey stands in for the integer server key.
+type Uint8ServerKey struct{}
+
+// Uint8PublicKey stands in for the integer public key.
+type Uint8PublicKey struct{}
+
+// Uint8CompactPublicKey stands in for the compact integer public key.
+type Uint8CompactPublicKey struct{}
+
+// Uint8Ciphertext stands in for an FheUint8 ciphertext.
+type Uint8Ciphertext struct{}
+
+// IntCiphertext stands in for an FheUint2, FheUint4, FheUint16, FheUint32 or
+// FheUint64 ciphertext.
+type IntCiphertext struct {
+	bits int
+}
+
+// FheBool stands in for an encrypted boolean of the integer API.
+type FheBool struct{}
+
+// Bits returns the ciphertext's bit width.
+func (c *IntCiphertext) Bits() int { return c.bits }
+
+func unsupported(op string) error {
+	return fmt.Errorf("%s: %w", op, ErrUnsupported)
+}
+
+// generateUint8Keys returns placeholder keys so callers can start; they
+// cannot encrypt or compute.
+func generateUint8Keys() (*Uint8ClientKey, *Uint8ServerKey, error) {
+	return &Uint8ClientKey{}, &Uint8ServerKey{}, nil
+}
+
+// NewUint8PublicKey returns a placeholder public key.
+func NewUint8PublicKey(client *Uint8ClientKey) (*Uint8PublicKey, error) {
+	if client == nil {
+		return nil, errClientKeyNil
+	}
+	return &Uint8PublicKey{}, nil
+}
+
+// NewUint8CompactPublicKey returns a placeholder compact public key.
+func NewUint8CompactPublicKey(client *Uint8ClientKey) (*Uint8CompactPublicKey, error) {
+	if client == nil {
+		return nil, errClientKeyNil
+	}
+	return &Uint8CompactPublicKey{}, nil
+}
+
+// Close is a no-op.
+func (c *Uint8ClientKey) Close() error { return nil }
+
+// Close is a no-op.
+func (s *Uint8ServerKey) Close() error { return nil }
+
+// Close is a no-op.
+func (p *Uint8PublicKey) Close() error { return nil }
+
+// Close is a no-op.
+func (p *Uint8CompactPublicKey) Close() error { return nil }
+
+// Close is a no-op.
+func (c *Uint8Ciphertext) Close() error { return nil }
+
+// Close is a no-op.
+func (c *IntCiphertext) Close() error { return nil }
+
+// Close is a no-op.
+func (c *FheBool) Close() error { return nil }
+
+// Serialize reports ErrUnsupported.
+func (c *Uint8ClientKey) Serialize() ([]byte, error) {
+	return nil, unsupported("serialize integer client key")
+}
+
+// Serialize reports ErrUnsupported.
+func (s *Uint8ServerKey) Serialize() ([]byte, error) {
+	return nil, unsupported("serialize integer server key")
+}
+
+// Serialize reports ErrUnsupported.
+func (p *Uint8PublicKey) Serialize() ([]byte, error) {
+	return nil, unsupported("serialize public key")
+}
+
+// Serialize reports ErrUnsupported.
+func (p *Uint8CompactPublicKey) Serialize() ([]byte, error) {
+	return nil, unsupported("serialize compact public key")
+}
+
+// Uint8Serialize reports ErrUnsupported.
+func (c *Uint8Ciphertext) Uint8Serialize() ([]byte, error) {
+	return nil, unsupported("serialize uint8 ciphertext")
+}
+
+// Serialize reports ErrUnsupported.
+func (c *IntCiphertext) Serialize() ([]byte, error) {
+	return nil, unsupported(fmt.Sprintf("serialize uint%d ciphertext", c.bits))
+}
+
+// Serialize reports ErrUnsupported.
+func (c *FheBool) Serialize() ([]byte, error) {
+	return nil, unsupported("serialize fhe bool")
+}
+
+// IsTrivial reports ErrUnsupported.
+func (c *Uint8Ciphertext) IsTrivial() (bool, error) {
+	return false, unsupported("uint8 is_trivial")
+}
+
+// IsTrivial reports ErrUnsupported.
+func (c *IntCiphertext) IsTrivial() (bool, error) {
+	return false, unsupported(fmt.Sprintf("uint%d is_trivial", c.bits))
+}
+
+// IsTrivial reports ErrUnsupported.
+func (c *FheBool) IsTrivial() (bool, error) {
+	return false, unsupported("fhe bool is_trivial")
+}
+
+// WithBytes reports ErrUnsupported.
+func (c *Uint8ClientKey) WithBytes(fn func([]byte) error) error {
+	return unsupported("serialize integer client key")
+}
+
+// WithBytes reports ErrUnsupported.
+func (s *Uint8ServerKey) WithBytes(fn func([]byte) error) error {
+	return unsupported("serialize integer server key")
+}
+
+// WithBytes reports ErrUnsupported.
+func (p *Uint8PublicKey) WithBytes(fn func([]byte) error) error {
+	return unsupported("serialize public key")
+}
+
+// WithBytes reports ErrUnsupported.
+func (p *Uint8CompactPublicKey) WithBytes(fn func([]byte) error) error {
+	return unsupported("serialize compact public key")
+}
+
+// WithBytes reports ErrUnsupported.
+func (c *Uint8Ciphertext) WithBytes(fn func([]byte) error) error {
+	return unsupported("serialize uint8 ciphertext")
+}
+
+// WithBytes reports ErrUnsupported.
+func (c *IntCiphertext) WithBytes(fn func([]byte) error) error {
+	return unsupported(fmt.Sprintf("serialize uint%d ciphertext", c.bits))
+}
+
+// WithBytes reports ErrUnsupported.
+func (c *FheBool) WithBytes(fn func([]byte) error) error {
+	return unsupported("serialize fhe bool")
+}
+
+// DeserializeUint8ClientKey reports ErrUnsupported.
+func DeserializeUint8ClientKey(data []byte) (*Uint8ClientKey, error) {
+	return nil, unsupported("deserialize integer client key")
+}
+
+// DeserializeUint8ServerKey reports ErrUnsupported.
+func DeserializeUint8ServerKey(data []byte) (*Uint8ServerKey, error) {
+	return nil, unsupported("deserialize integer server key")
+}
+
+// DeserializeUint8PublicKey reports ErrUnsupported.
+func DeserializeUint8PublicKey(data []byte) (*Uint8PublicKey, error) {
+	return nil, unsupported("deserialize public key")
+}
+
+// DeserializeUint8CompactPublicKey reports ErrUnsupported.
+func DeserializeUint8CompactPublicKey(data []byte) (*Uint8CompactPublicKey, error) {
+	return nil, unsupported("deserialize compact public key")
+}
+
+// Uint8Deserialize reports ErrUnsupported.
+func Uint8Deserialize(data []byte) (*Uint8Ciphertext, error) {
+	return nil, unsupported("deserialize uint8 ciphertext")
+}
+
+// DeserializeInt reports ErrUnsupported.
+func DeserializeInt(bits int, data []byte) (*IntCiphertext, error) {
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	return nil, unsupported(fmt.Sprintf("deserialize uint%d ciphertext", bits))
+}
+
+// DeserializeFheBool reports ErrUnsupported.
+func DeserializeFheBool(data []byte) (*FheBool, error) {
+	return nil, unsupported("deserialize fhe bool")
+}
+
+// EncryptUint8 reports ErrUnsupported.
+func EncryptUint8(client *Uint8ClientKey, value uint8) (*Uint8Ciphertext, error) {
+	return nil, unsupported("encrypt uint8")
+}
+
+// EncryptUint8Public reports ErrUnsupported.
+func EncryptUint8Public(pub *Uint8PublicKey, value uint8) (*Uint8Ciphertext, error) {
+	return nil, unsupported("encrypt uint8 with public key")
+}
+
+// EncryptUint8Compact reports ErrUnsupported.
+func EncryptUint8Compact(pub *Uint8CompactPublicKey, value uint8) (*Uint8Ciphertext, error) {
+	return nil, unsupported("encrypt uint8 with compact public key")
+}
+
+// DecryptUint8 reports ErrUnsupported.
+func DecryptUint8(client *Uint8ClientKey, ct *Uint8Ciphertext) (uint8, error) {
+	return 0, unsupported("decrypt uint8")
+}
+
+// EncryptInt reports ErrUnsupported.
+func EncryptInt(client *Uint8ClientKey, bits int, value uint64) (*IntCiphertext, error) {
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	return nil, unsupported(fmt.Sprintf("encrypt uint%d", bits))
+}
+
+// EncryptIntPublic reports ErrUnsupported.
+func EncryptIntPublic(pub *Uint8PublicKey, bits int, value uint64) (*IntCiphertext, error) {
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	return nil, unsupported(fmt.Sprintf("encrypt uint%d with public key", bits))
+}
+
+// EncryptIntCompact reports ErrUnsupported.
+func EncryptIntCompact(pub *Uint8CompactPublicKey, bits int, value uint64) (*IntCiphertext, error) {
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	return nil, unsupported(fmt.Sprintf("encrypt uint%d with compact public key", bits))
+}
+
+// DecryptInt reports ErrUnsupported.
+func DecryptInt(client *Uint8ClientKey, ct *IntCiphertext) (uint64, error) {
+	return 0, unsupported("decrypt integer")
+}
+
+// EncryptFheBool reports ErrUnsupported.
+func EncryptFheBool(client *Uint8ClientKey, value bool) (*FheBool, error) {
+	return nil, unsupported("encrypt fhe bool")
+}
+
+// EncryptFheBoolPublic reports ErrUnsupported.
+func EncryptFheBoolPublic(pub *Uint8PublicKey, value bool) (*FheBool, error) {
+	return nil, unsupported("encrypt fhe bool with public key")
+}
+
+// EncryptFheBoolCompact reports ErrUnsupported.
+func EncryptFheBoolCompact(pub *Uint8CompactPublicKey, value bool) (*FheBool, error) {
+	return nil, unsupported("encrypt fhe bool with compact public key")
+}
+
+// DecryptFheBool reports ErrUnsupported.
+func DecryptFheBool(client *Uint8ClientKey, ct *FheBool) (bool, error) {
+	return false, unsupported("decrypt fhe bool")
+}
+
+// Add reports ErrUnsupported.
+func (sk *Uint8ServerKey) Add(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	return nil, unsupported("uint8 add")
+}
+
+// BitAnd reports ErrUnsupported.
+func (sk *Uint8ServerKey) BitAnd(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	return nil, unsupported("uint8 bitand")
+}
+
+// BitXor reports ErrUnsupported.
+func (sk *Uint8ServerKey) BitXor(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	return nil, unsupported("uint8 bitxor")
+}
+
+// Compare reports ErrUnsupported.
+func (sk *Uint8ServerKey) Compare(cmp Comparison, lhs, rhs *Uint8Ciphertext) (*FheBool, error) {
+	if err := checkComparison(cmp); err != nil {
+		return nil, err
+	}
+	return nil, unsupported("uint8 " + string(cmp))
+}
+
+// CompareScalar reports ErrUnsupported.
+func (sk *Uint8ServerKey) CompareScalar(cmp Comparison, lhs *Uint8Ciphertext, rhs uint8) (*FheBool, error) {
+	if err := checkComparison(cmp); err != nil {
+		return nil, err
+	}
+	return nil, unsupported("uint8 scalar_" + string(cmp))
+}
+
+// IfThenElse reports ErrUnsupported.
+func (sk *Uint8ServerKey) IfThenElse(cond *FheBool, then, els *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	return nil, unsupported("uint8 if_then_else")
+}
+
+// BoolAnd reports ErrUnsupported.
+func (sk *Uint8ServerKey) BoolAnd(lhs, rhs *FheBool) (*FheBool, error) {
+	return nil, unsupported("bool and")
+}
+
+// BitCount reports ErrUnsupported.
+func (sk *Uint8ServerKey) BitCount(op BitCount, ct *Uint8Ciphertext) (*IntCiphertext, error) {
+	if err := checkBitCount(op); err != nil {
+		return nil, err
+	}
+	return nil, unsupported("uint8 " + string(op))
+}
+
+// Shift reports ErrUnsupported.
+func (sk *Uint8ServerKey) Shift(op Shift, value, amount *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	if err := checkShift(op); err != nil {
+		return nil, err
+	}
+	return nil, unsupported("uint8 " + string(op))
+}
+
+// BoolToUint8 reports ErrUnsupported.
+func (sk *Uint8ServerKey) BoolToUint8(b *FheBool) (*Uint8Ciphertext, error) {
+	return nil, unsupported("cast bool to uint8")
+}
+
+// Uint8ToBool reports ErrUnsupported.
+func (sk *Uint8ServerKey) Uint8ToBool(ct *Uint8Ciphertext) (*FheBool, error) {
+	return nil, unsupported("cast uint8 to bool")
+}
+
+// Uint8Bits reports ErrUnsupported.
+func (sk *Uint8ServerKey) Uint8Bits(ct *Uint8Ciphertext) ([]*FheBool, error) {
+	return nil, unsupported("uint8 bits")
+}
+
+// ComposeUint8 reports ErrUnsupported.
+func (sk *Uint8ServerKey) ComposeUint8(bits []*FheBool) (*Uint8Ciphertext, error) {
+	return nil, unsupported("compose uint8")
+}
+
+// ExpandCompactList reports ErrUnsupported.
+func (sk *Uint8ServerKey) ExpandCompactList(data []byte) ([]ExpandedCiphertext, error) {
+	return nil, unsupported("expand compact ciphertext list")
+}
+
+// checkCompactList reports ErrUnsupported.
+func checkCompactList(data []byte) error {
+	return unsupported("deserialize compact ciphertext list")
+}
+
+// IntAdd reports ErrUnsupported.
+func (sk *Uint8ServerKey) IntAdd(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return nil, unsupported("integer add")
+}
+
+// IntBitAnd reports ErrUnsupported.
+func (sk *Uint8ServerKey) IntBitAnd(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return nil, unsupported("integer bitand")
+}
+
+// IntBitXor reports ErrUnsupported.
+func (sk *Uint8ServerKey) IntBitXor(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return nil, unsupported("integer bitxor")
+}
+
+// IntCompare reports ErrUnsupported.
+func (sk *Uint8ServerKey) IntCompare(cmp Comparison, lhs, rhs *IntCiphertext) (*FheBool, error) {
+	if err := checkComparison(cmp); err != nil {
+		return nil, err
+	}
+	return nil, unsupported("integer " + string(cmp))
+}
+
+// IntCompareScalar reports ErrUnsupported.
+func (sk *Uint8ServerKey) IntCompareScalar(cmp Comparison, lhs *IntCiphertext, rhs uint64) (*FheBool, error) {
+	if err := checkComparison(cmp); err != nil {
+		return nil, err
+	}
+	return nil, unsupported("integer scalar_" + string(cmp))
+}
+
+// IntIfThenElse reports ErrUnsupported.
+func (sk *Uint8ServerKey) IntIfThenElse(cond *FheBool, then, els *IntCiphertext) (*IntCiphertext, error) {
+	return nil, unsupported("integer if_then_else")
+}
+
+func toSafe(kind int, data []byte) ([]byte, error) {
+	return nil, unsupported("safe serialize " + safeType(kind))
+}
+
+func fromSafe(sk *Uint8ServerKey, data []byte) (string, []byte, error) {
+	return "", nil, unsupported("safe deserialize")
+}
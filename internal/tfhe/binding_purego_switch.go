@@ -0,0 +1,98 @@
+//go:build purego
+
+package tfhe
+
+import (
+	"runtime"
+
+	"tfhe-go/internal/tfhe/purego"
+)
+
+// SwitchKey re-encrypts boolean ciphertexts from one client key to another
+// without decrypting them. Generating one takes both client keys; holding
+// one reveals neither.
+type SwitchKey struct {
+	lifecycle
+	k *purego.SwitchKey
+}
+
+// KeySwitching reports whether the backend implements SwitchKey.
+const KeySwitching = true
+
+func newSwitchKey(k *purego.SwitchKey) *SwitchKey {
+	out := &SwitchKey{k: k}
+	trackObject(objBooleanSwitchKey)
+	runtime.SetFinalizer(out, func(k *SwitchKey) { _ = k.Close() })
+	return out
+}
+
+// GenerateSwitchKey creates a key switching ciphertexts encrypted under from
+// into ciphertexts encrypted under to.
+func GenerateSwitchKey(from, to *ClientKey) (*SwitchKey, error) {
+	if err := checkUsable(from, to); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanSwitchKey); err != nil {
+		return nil, err
+	}
+	k, err := purego.GenerateSwitchKey(from.sk, to.sk)
+	if err != nil {
+		return nil, invalidKey(err)
+	}
+	return newSwitchKey(k), nil
+}
+
+// Close drops the switch key.
+func (k *SwitchKey) Close() error {
+	if k == nil || !k.markClosed() || k.k == nil {
+		return nil
+	}
+	k.k = nil
+	untrackObject(objBooleanSwitchKey)
+	return nil
+}
+
+func (k *SwitchKey) usable() error {
+	if k == nil {
+		return errSwitchKeyNil
+	}
+	return k.state(k.k != nil, errSwitchKeyNil, errSwitchKeyClosed)
+}
+
+// Switch re-encrypts ct under the switch key's target key.
+func (k *SwitchKey) Switch(ct *Ciphertext) (*Ciphertext, error) {
+	if err := k.usable(); err != nil {
+		return nil, err
+	}
+	if err := ct.usable(); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objBooleanCiphertext); err != nil {
+		return nil, err
+	}
+	out, err := k.k.Switch(ct.ct)
+	if err != nil {
+		return nil, invalidCiphertext(err)
+	}
+	return newCiphertext(out), nil
+}
+
+// Serialize serializes the switch key.
+func (k *SwitchKey) Serialize() ([]byte, error) {
+	if err := k.usable(); err != nil {
+		return nil, err
+	}
+	return k.k.MarshalBinary()
+}
+
+// DeserializeSwitchKey reconstructs a switch key from bytes.
+func DeserializeSwitchKey(data []byte) (*SwitchKey, error) {
+	if err := checkMemory(objBooleanSwitchKey); err != nil {
+		return nil, err
+	}
+	k, err := purego.UnmarshalSwitchKey(data)
+	if err != nil {
+		return nil, invalidKey(err)
+	}
+	return newSwitchKey(k), nil
+}
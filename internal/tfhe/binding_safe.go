@@ -0,0 +1,98 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+
+// to_safe deserializes raw as a ciphertext of kind, 1 for FheBool or else
+// the width, and writes its safe serialization to out.
+static int to_safe(int kind, struct DynamicBufferView raw, uint64_t limit, struct DynamicBuffer *out) {
+#define CONVERT(T, t) { \
+	struct T *ct; \
+	int rc = t##_deserialize(raw, &ct); \
+	if (rc != 0) return rc; \
+	rc = t##_safe_serialize(ct, out, limit); \
+	t##_destroy(ct); \
+	return rc; \
+}
+	switch (kind) {
+	case 1: CONVERT(FheBool, fhe_bool)
+	case 2: CONVERT(FheUint2, fhe_uint2)
+	case 4: CONVERT(FheUint4, fhe_uint4)
+	case 8: CONVERT(FheUint8, fhe_uint8)
+	case 16: CONVERT(FheUint16, fhe_uint16)
+	case 32: CONVERT(FheUint32, fhe_uint32)
+	case 64: CONVERT(FheUint64, fhe_uint64)
+	}
+#undef CONVERT
+	return -1;
+}
+
+// from_safe reads data as a safe-serialized ciphertext conforming to the
+// parameters of sk, writes it to out in the plain serialization and returns
+// its kind, 0 if data is no such ciphertext or -1 if it cannot be written.
+static int from_safe(struct DynamicBufferView data, uint64_t limit, const struct ServerKey *sk, struct DynamicBuffer *out) {
+#define TRY(T, t, kind) { \
+	struct T *ct; \
+	if (t##_safe_deserialize_conformant(data, limit, sk, &ct) == 0) { \
+		int rc = t##_serialize(ct, out); \
+		t##_destroy(ct); \
+		return rc == 0 ? kind : -1; \
+	} \
+}
+	TRY(FheBool, fhe_bool, 1) TRY(FheUint2, fhe_uint2, 2) TRY(FheUint4, fhe_uint4, 4)
+	TRY(FheUint8, fhe_uint8, 8) TRY(FheUint16, fhe_uint16, 16)
+	TRY(FheUint32, fhe_uint32, 32) TRY(FheUint64, fhe_uint64, 64)
+#undef TRY
+	return 0;
+}
+*/
+import "C"
+import (
+	"fmt"
+	"runtime"
+	"unsafe"
+)
+
+func toSafe(kind int, data []byte) ([]byte, error) {
+	if err := isolate(safeType(kind), data); err != nil {
+		return nil, err
+	}
+	view := C.struct_DynamicBufferView{
+		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
+		length:  C.size_t(len(data)),
+	}
+	var buf C.struct_DynamicBuffer
+	code := C.to_safe(C.int(kind), view, C.uint64_t(CurrentLimits().MaxCiphertext()), &buf)
+	runtime.KeepAlive(data)
+	if code != 0 {
+		return nil, fmt.Errorf("%w: not a %s ciphertext", ErrInvalidCiphertext, safeType(kind))
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	return C.GoBytes(unsafe.Pointer(buf.pointer), C.int(buf.length)), nil
+}
+
+func fromSafe(sk *Uint8ServerKey, data []byte) (string, []byte, error) {
+	if sk != nil && sk.isClosed() {
+		return "", nil, errServerKeyClosed
+	}
+	if sk == nil || sk.ptr == nil {
+		return "", nil, ErrServerKeyNotSet
+	}
+	view := C.struct_DynamicBufferView{
+		pointer: (*C.uchar)(unsafe.Pointer(&data[0])),
+		length:  C.size_t(len(data)),
+	}
+	var buf C.struct_DynamicBuffer
+	kind := C.from_safe(view, C.uint64_t(len(data)), sk.ptr, &buf)
+	runtime.KeepAlive(data)
+	switch {
+	case kind == 0:
+		return "", nil, fmt.Errorf("%w: not a safe-serialized ciphertext under these keys' parameters", ErrInvalidCiphertext)
+	case kind < 0:
+		return "", nil, &ErrCAPI{Op: "serialize converted ciphertext", Code: int(kind)}
+	}
+	defer C.destroy_dynamic_buffer(&buf)
+	return safeType(int(kind)), C.GoBytes(unsafe.Pointer(buf.pointer), C.int(buf.length)), nil
+}
@@ -0,0 +1,156 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+import "fmt"
+
+// CompareScalar compares lhs with the plaintext rhs under sk, returning an
+// encrypted boolean. Only lhs is encrypted, so the constant needs no
+// encryption and the comparison is cheaper than Compare.
+func (sk *Uint8ServerKey) CompareScalar(cmp Comparison, lhs *Uint8Ciphertext, rhs uint8) (*FheBool, error) {
+	if err := checkUsable(lhs); err != nil {
+		return nil, err
+	}
+	if err := checkComparison(cmp); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	var out *C.struct_FheBool
+	if err := withServerKey(sk, func() error {
+		var code C.int
+		b := C.uchar(rhs)
+		switch cmp {
+		case CompareEq:
+			code = C.fhe_uint8_scalar_eq(lhs.ptr, b, &out)
+		case CompareNe:
+			code = C.fhe_uint8_scalar_ne(lhs.ptr, b, &out)
+		case CompareLt:
+			code = C.fhe_uint8_scalar_lt(lhs.ptr, b, &out)
+		case CompareLe:
+			code = C.fhe_uint8_scalar_le(lhs.ptr, b, &out)
+		case CompareGt:
+			code = C.fhe_uint8_scalar_gt(lhs.ptr, b, &out)
+		case CompareGe:
+			code = C.fhe_uint8_scalar_ge(lhs.ptr, b, &out)
+		}
+		return check(code, "uint8 scalar_"+string(cmp))
+	}); err != nil {
+		return nil, err
+	}
+	return newFheBool(out), nil
+}
+
+// IntCompareScalar compares the integer ciphertext lhs with the plaintext
+// rhs under sk, returning an encrypted boolean. rhs must fit the width of
+// lhs.
+func (sk *Uint8ServerKey) IntCompareScalar(cmp Comparison, lhs *IntCiphertext, rhs uint64) (*FheBool, error) {
+	if err := checkUsable(lhs); err != nil {
+		return nil, err
+	}
+	if err := checkIntValue(lhs.bits, rhs); err != nil {
+		return nil, err
+	}
+	if err := checkComparison(cmp); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objFheBoolCiphertext); err != nil {
+		return nil, err
+	}
+	bits := lhs.bits
+	var out *C.struct_FheBool
+	if err := withServerKey(sk, func() error {
+		var code C.int
+		switch bits {
+		case 2:
+			a, b := (*C.struct_FheUint2)(lhs.ptr), C.uchar(rhs)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint2_scalar_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint2_scalar_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint2_scalar_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint2_scalar_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint2_scalar_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint2_scalar_ge(a, b, &out)
+			}
+		case 4:
+			a, b := (*C.struct_FheUint4)(lhs.ptr), C.uchar(rhs)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint4_scalar_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint4_scalar_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint4_scalar_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint4_scalar_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint4_scalar_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint4_scalar_ge(a, b, &out)
+			}
+		case 16:
+			a, b := (*C.struct_FheUint16)(lhs.ptr), C.ushort(rhs)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint16_scalar_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint16_scalar_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint16_scalar_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint16_scalar_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint16_scalar_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint16_scalar_ge(a, b, &out)
+			}
+		case 32:
+			a, b := (*C.struct_FheUint32)(lhs.ptr), C.uint32_t(rhs)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint32_scalar_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint32_scalar_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint32_scalar_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint32_scalar_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint32_scalar_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint32_scalar_ge(a, b, &out)
+			}
+		case 64:
+			a, b := (*C.struct_FheUint64)(lhs.ptr), C.uint64_t(rhs)
+			switch cmp {
+			case CompareEq:
+				code = C.fhe_uint64_scalar_eq(a, b, &out)
+			case CompareNe:
+				code = C.fhe_uint64_scalar_ne(a, b, &out)
+			case CompareLt:
+				code = C.fhe_uint64_scalar_lt(a, b, &out)
+			case CompareLe:
+				code = C.fhe_uint64_scalar_le(a, b, &out)
+			case CompareGt:
+				code = C.fhe_uint64_scalar_gt(a, b, &out)
+			case CompareGe:
+				code = C.fhe_uint64_scalar_ge(a, b, &out)
+			}
+		}
+		return check(code, fmt.Sprintf("uint%d scalar_%s", bits, cmp))
+	}); err != nil {
+		return nil, err
+	}
+	return newFheBool(out), nil
+}
@@ -0,0 +1,40 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include "tfhe.h"
+*/
+import "C"
+
+// Shift shifts or rotates value by the encrypted amount under sk, so the
+// distance stays hidden. The amount wraps modulo 8.
+func (sk *Uint8ServerKey) Shift(op Shift, value, amount *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	if err := checkUsable(value, amount); err != nil {
+		return nil, err
+	}
+	if err := checkShift(op); err != nil {
+		return nil, err
+	}
+	if err := checkMemory(objUint8Ciphertext); err != nil {
+		return nil, err
+	}
+	var out *C.struct_FheUint8
+	if err := withServerKey(sk, func() error {
+		var code C.int
+		switch op {
+		case ShiftLeft:
+			code = C.fhe_uint8_shl(value.ptr, amount.ptr, &out)
+		case ShiftRight:
+			code = C.fhe_uint8_shr(value.ptr, amount.ptr, &out)
+		case RotateLeft:
+			code = C.fhe_uint8_rotate_left(value.ptr, amount.ptr, &out)
+		case RotateRight:
+			code = C.fhe_uint8_rotate_right(value.ptr, amount.ptr, &out)
+		}
+		return check(code, "uint8 "+string(op))
+	}); err != nil {
+		return nil, err
+	}
+	return newUint8Ciphertext(out), nil
+}
@@ -0,0 +1,31 @@
+//go:build !purego
+
+package tfhe
+
+import "fmt"
+
+// SwitchKey would re-encrypt boolean ciphertexts from one client key to
+// another without decrypting them. tfhe-c exposes no key switching keys for
+// these ciphertext types, so with the native backend every operation on it
+// reports ErrUnsupported; the pure-Go backend implements it.
+type SwitchKey struct{}
+
+// KeySwitching reports whether the backend implements SwitchKey.
+const KeySwitching = false
+
+var errSwitchUnsupported = fmt.Errorf("key switching: %w", ErrUnsupported)
+
+// GenerateSwitchKey reports ErrUnsupported.
+func GenerateSwitchKey(from, to *ClientKey) (*SwitchKey, error) { return nil, errSwitchUnsupported }
+
+// Close does nothing.
+func (k *SwitchKey) Close() error { return nil }
+
+// Switch reports ErrUnsupported.
+func (k *SwitchKey) Switch(ct *Ciphertext) (*Ciphertext, error) { return nil, errSwitchUnsupported }
+
+// Serialize reports ErrUnsupported.
+func (k *SwitchKey) Serialize() ([]byte, error) { return nil, errSwitchUnsupported }
+
+// DeserializeSwitchKey reports ErrUnsupported.
+func DeserializeSwitchKey(data []byte) (*SwitchKey, error) { return nil, errSwitchUnsupported }
@@ -0,0 +1,62 @@
+//go:build !purego
+
+package tfhe
+
+/*
+#include <stdint.h>
+#include "tfhe.h"
+
+// int_is_trivial reports whether the integer ciphertext ct of the given
+// width is a trivial encryption.
+static int int_is_trivial(int bits, const void *ct) {
+	int rc = -1;
+	switch (bits) {
+	case 2: { uint8_t v; rc = fhe_uint2_try_decrypt_trivial(ct, &v); break; }
+	case 4: { uint8_t v; rc = fhe_uint4_try_decrypt_trivial(ct, &v); break; }
+	case 16: { uint16_t v; rc = fhe_uint16_try_decrypt_trivial(ct, &v); break; }
+	case 32: { uint32_t v; rc = fhe_uint32_try_decrypt_trivial(ct, &v); break; }
+	case 64: { uint64_t v; rc = fhe_uint64_try_decrypt_trivial(ct, &v); break; }
+	}
+	return rc == 0;
+}
+*/
+import "C"
+import "fmt"
+
+// IsTrivial reports whether c is a trivial encryption, whose value anyone
+// can read without the client key.
+func (c *Uint8Ciphertext) IsTrivial() (bool, error) {
+	if err := c.usable(); err != nil {
+		return false, err
+	}
+	var v C.uint8_t
+	return C.fhe_uint8_try_decrypt_trivial(c.ptr, &v) == 0, nil
+}
+
+// IsTrivial reports whether c is a trivial encryption, whose value anyone
+// can read without the client key.
+func (c *IntCiphertext) IsTrivial() (bool, error) {
+	if err := c.usable(); err != nil {
+		return false, err
+	}
+	return C.int_is_trivial(C.int(c.bits), c.ptr) != 0, nil
+}
+
+// IsTrivial reports whether c is a trivial encryption, whose value anyone
+// can read without the client key.
+func (c *FheBool) IsTrivial() (bool, error) {
+	if err := c.usable(); err != nil {
+		return false, err
+	}
+	var v C.bool
+	return C.fhe_bool_try_decrypt_trivial(c.ptr, &v) == 0, nil
+}
+
+// IsTrivial reports ErrUnsupported: tfhe-c cannot tell for ciphertexts of
+// the boolean gate API.
+func (c *Ciphertext) IsTrivial() (bool, error) {
+	if err := c.usable(); err != nil {
+		return false, err
+	}
+	return false, fmt.Errorf("boolean is_trivial: %w", ErrUnsupported)
+}
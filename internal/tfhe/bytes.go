@@ -0,0 +1,105 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+
+	"tfhe-go/internal/bufpool"
+)
+
+// MaxBytesLen bounds the plaintexts EncryptBytes accepts. Each byte becomes
+// its own uint8 ciphertext, so the helpers suit short strings and tokens
+// rather than documents.
+const MaxBytesLen = 4096
+
+// EncryptBytes encrypts data byte by byte with the client key and returns
+// one base64 uint8 ciphertext per byte.
+func (s *Uint8Service) EncryptBytes(ctx context.Context, data []byte) ([]string, error) {
+	raw, err := s.EncryptBytesRaw(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]string, len(raw))
+	for i, ct := range raw {
+		out[i] = bufpool.EncodeBase64(ct)
+	}
+	return out, nil
+}
+
+// DecryptBytes decrypts base64 uint8 ciphertexts, one per byte, back to the
+// bytes they hold.
+func (s *Uint8Service) DecryptBytes(ctx context.Context, cts []string) ([]byte, error) {
+	raw := make([][]byte, len(cts))
+	for i, ct := range cts {
+		buf, err := decodeBase64(ct, CurrentLimits().MaxUint8Ciphertext)
+		if err != nil {
+			return nil, fmt.Errorf("byte %d: %w", i, err)
+		}
+		defer bufpool.Put(buf)
+		raw[i] = *buf
+	}
+	return s.DecryptBytesRaw(ctx, raw)
+}
+
+// EncryptBytesRaw encrypts data byte by byte with the client key and returns
+// one serialized uint8 ciphertext per byte; MarshalVector packs them into
+// one blob.
+func (s *Uint8Service) EncryptBytesRaw(ctx context.Context, data []byte) (out [][]byte, err error) {
+	if len(data) > MaxBytesLen {
+		return nil, fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrValueOutOfRange, len(data), MaxBytesLen)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return nil, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "uint8.encrypt_bytes", nil, &err)
+	defer end()
+
+	out = make([][]byte, len(data))
+	for i, b := range data {
+		ct, err := native(ctx, phaseCompute, "uint8.encrypt", func() (*Uint8Ciphertext, error) { return EncryptUint8(s.client, b) })
+		if err != nil {
+			return nil, err
+		}
+		out[i], err = native(ctx, phaseSerialize, "uint8.serialize", ct.Uint8Serialize)
+		_ = ct.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// DecryptBytesRaw decrypts serialized uint8 ciphertexts, one per byte, back
+// to the bytes they hold.
+func (s *Uint8Service) DecryptBytesRaw(ctx context.Context, cts [][]byte) (out []byte, err error) {
+	if len(cts) > MaxBytesLen {
+		return nil, fmt.Errorf("%w: %d ciphertexts exceeds limit of %d", ErrCiphertextTooLarge, len(cts), MaxBytesLen)
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return nil, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "uint8.decrypt_bytes", nil, &err)
+	defer end()
+
+	maxLen := CurrentLimits().MaxUint8Ciphertext
+	out = make([]byte, len(cts))
+	for i, data := range cts {
+		if err := checkSerialized(data, maxLen); err != nil {
+			return nil, fmt.Errorf("byte %d: %w", i, err)
+		}
+		ct, release, err := deserializeOperand(ctx, "uint8", data, Uint8Deserialize)
+		if err != nil {
+			return nil, fmt.Errorf("byte %d: %w", i, err)
+		}
+		out[i], err = native(ctx, phaseCompute, "uint8.decrypt", func() (uint8, error) { return DecryptUint8(s.client, ct) })
+		release()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,121 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+	"slices"
+)
+
+// ChecksumBytes folds a byte string, one uint8 ciphertext per byte, into one
+// encrypted byte: the XOR of all bytes for ChecksumXor or their sum modulo
+// 256 for ChecksumSum. The bytes are combined pairwise, level by level, each
+// level spread across up to workers goroutines. values are neither modified
+// nor closed; the caller must close the result.
+func (sk *Uint8ServerKey) ChecksumBytes(kind Checksum, values []*Uint8Ciphertext, workers int) (*Uint8Ciphertext, error) {
+	if err := checkChecksum(kind); err != nil {
+		return nil, err
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("%w: checksum of an empty byte string", ErrValueOutOfRange)
+	}
+	combine := sk.BitXor
+	if kind == ChecksumSum {
+		combine = sk.Add
+	}
+
+	level := slices.Clone(values)
+	owned := make([]bool, len(level))
+	release := func() {
+		for i, ct := range level {
+			if owned[i] {
+				_ = ct.Close()
+			}
+		}
+	}
+	for len(level) > 1 {
+		next := make([]*Uint8Ciphertext, (len(level)+1)/2)
+		nextOwned := make([]bool, len(next))
+		err := parallel(len(level)/2, workers, "uint8.checksum", func(i int) error {
+			ct, err := combine(level[2*i], level[2*i+1])
+			if err != nil {
+				return err
+			}
+			next[i], nextOwned[i] = ct, true
+			return nil
+		})
+		if last := len(level) - 1; len(level)%2 == 1 {
+			next[len(next)-1], nextOwned[len(next)-1] = level[last], owned[last]
+			owned[last] = false
+		}
+		release()
+		level, owned = next, nextOwned
+		if err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	// A single byte meets no combination; copy it so the caller owns the
+	// result.
+	if !owned[0] {
+		data, err := level[0].Uint8Serialize()
+		if err != nil {
+			return nil, err
+		}
+		return Uint8Deserialize(data)
+	}
+	return level[0], nil
+}
+
+// ChecksumBytesRaw folds serialized uint8 ciphertexts, one per byte, with
+// ChecksumBytes and returns the serialized checksum. The checksum of an
+// empty string is a fresh encryption of zero under the public key.
+func (s *Uint8Service) ChecksumBytesRaw(ctx context.Context, kind Checksum, cts [][]byte, workers int) ([]byte, error) {
+	if err := checkChecksum(kind); err != nil {
+		return nil, err
+	}
+	if len(cts) > MaxBytesLen {
+		return nil, fmt.Errorf("%w: %d ciphertexts exceeds limit of %d", ErrCiphertextTooLarge, len(cts), MaxBytesLen)
+	}
+	if opBudget.Load() > 0 {
+		owned := make([][]byte, len(cts))
+		for i, v := range cts {
+			owned[i] = detach(v)
+		}
+		cts = owned
+	}
+	return bounded(ctx, "bytes.checksum", func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "bytes.checksum", &out, &err)
+		defer end()
+
+		var sum *Uint8Ciphertext
+		if len(cts) == 0 {
+			if s.withheld {
+				return nil, errClientKeyWithheld
+			}
+			sum, err = native(ctx, phaseCompute, "uint8.encrypt_public", func() (*Uint8Ciphertext, error) { return EncryptUint8Public(s.public, 0) })
+		} else {
+			maxLen := CurrentLimits().MaxUint8Ciphertext
+			values := make([]*Uint8Ciphertext, len(cts))
+			for i, data := range cts {
+				if err := checkSerialized(data, maxLen); err != nil {
+					return nil, fmt.Errorf("byte %d: %w", i, err)
+				}
+				ct, release, err := deserializeOperand(ctx, "uint8", data, Uint8Deserialize)
+				if err != nil {
+					return nil, fmt.Errorf("byte %d: %w", i, err)
+				}
+				defer release()
+				values[i] = ct
+			}
+			sum, err = nativeFanout(ctx, phaseCompute, "bytes.checksum", workers, func(workers int) (*Uint8Ciphertext, error) { return s.server.ChecksumBytes(kind, values, workers) })
+		}
+		if err != nil {
+			return nil, err
+		}
+		defer sum.Close()
+		return native(ctx, phaseSerialize, "uint8.serialize", sum.Uint8Serialize)
+	})
+}
@@ -0,0 +1,145 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+)
+
+// EqualBytes compares two byte strings of equal length, one uint8
+// ciphertext per byte, and returns an encrypted flag set when every byte
+// matches. The byte comparisons and the AND tree folding them run across up
+// to workers goroutines. The caller must close the result.
+func (sk *Uint8ServerKey) EqualBytes(lhs, rhs []*Uint8Ciphertext, workers int) (*FheBool, error) {
+	if len(lhs) != len(rhs) || len(lhs) == 0 {
+		return nil, fmt.Errorf("%w: byte strings of %d and %d bytes", ErrValueOutOfRange, len(lhs), len(rhs))
+	}
+	flags := make([]*FheBool, len(lhs))
+	release := func() {
+		for _, ct := range flags {
+			if ct != nil {
+				_ = ct.Close()
+			}
+		}
+	}
+	if err := parallel(len(lhs), workers, "bytes.eq", func(i int) (err error) {
+		flags[i], err = sk.Compare(CompareEq, lhs[i], rhs[i])
+		return err
+	}); err != nil {
+		release()
+		return nil, err
+	}
+
+	// AND pairwise, level by level.
+	for len(flags) > 1 {
+		next := make([]*FheBool, (len(flags)+1)/2)
+		if err := parallel(len(flags)/2, workers, "bytes.eq", func(i int) (err error) {
+			next[i], err = sk.BoolAnd(flags[2*i], flags[2*i+1])
+			return err
+		}); err != nil {
+			release()
+			for _, ct := range next {
+				if ct != nil {
+					_ = ct.Close()
+				}
+			}
+			return nil, err
+		}
+		if len(flags)%2 == 1 {
+			next[len(next)-1], flags[len(flags)-1] = flags[len(flags)-1], nil
+		}
+		release()
+		flags = next
+	}
+	return flags[0], nil
+}
+
+// HasPrefix reports, encrypted, whether value starts with prefix, both one
+// uint8 ciphertext per byte; prefix must be non-empty and no longer than
+// value. The caller must close the result.
+func (sk *Uint8ServerKey) HasPrefix(value, prefix []*Uint8Ciphertext, workers int) (*FheBool, error) {
+	if len(prefix) > len(value) {
+		return nil, fmt.Errorf("%w: prefix of %d bytes is longer than the %d-byte value", ErrValueOutOfRange, len(prefix), len(value))
+	}
+	return sk.EqualBytes(value[:len(prefix)], prefix, workers)
+}
+
+// EqualBytesRaw compares two byte strings of serialized uint8 ciphertexts
+// with EqualBytes and returns the serialized FheBool. Their lengths are
+// public, so strings of different lengths, or two empty strings, are
+// answered with a fresh encryption of the known verdict under the public
+// key.
+func (s *Uint8Service) EqualBytesRaw(ctx context.Context, lhs, rhs [][]byte, workers int) ([]byte, error) {
+	if len(lhs) != len(rhs) || len(lhs) == 0 {
+		return s.matchBytesRaw(ctx, "bytes.eq", nil, nil, len(lhs) == len(rhs), workers)
+	}
+	return s.matchBytesRaw(ctx, "bytes.eq", lhs, rhs, false, workers)
+}
+
+// HasPrefixRaw reports, as a serialized FheBool, whether the serialized
+// byte string value starts with prefix, as HasPrefix does. A prefix longer
+// than value, or an empty one, is answered like EqualBytesRaw answers
+// strings of different lengths.
+func (s *Uint8Service) HasPrefixRaw(ctx context.Context, value, prefix [][]byte, workers int) ([]byte, error) {
+	if len(prefix) > len(value) || len(prefix) == 0 {
+		return s.matchBytesRaw(ctx, "bytes.prefix", nil, nil, len(prefix) == 0, workers)
+	}
+	return s.matchBytesRaw(ctx, "bytes.prefix", value[:len(prefix)], prefix, false, workers)
+}
+
+// matchBytesRaw compares lhs and rhs with EqualBytes or, when they are
+// empty, encrypts known.
+func (s *Uint8Service) matchBytesRaw(ctx context.Context, op string, lhs, rhs [][]byte, known bool, workers int) ([]byte, error) {
+	if len(lhs) > MaxBytesLen {
+		return nil, fmt.Errorf("%w: %d ciphertexts exceeds limit of %d", ErrCiphertextTooLarge, len(lhs), MaxBytesLen)
+	}
+	if opBudget.Load() > 0 {
+		owned := make([][]byte, 0, len(lhs)+len(rhs))
+		for _, v := range lhs {
+			owned = append(owned, detach(v))
+		}
+		for _, v := range rhs {
+			owned = append(owned, detach(v))
+		}
+		lhs, rhs = owned[:len(lhs)], owned[len(lhs):]
+	}
+	return bounded(ctx, op, func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, op, &out, &err)
+		defer end()
+
+		if len(lhs) == 0 {
+			if s.withheld {
+				return nil, errClientKeyWithheld
+			}
+			flag, err := native(ctx, phaseCompute, "bool.encrypt_public", func() (*FheBool, error) { return EncryptFheBoolPublic(s.public, known) })
+			if err != nil {
+				return nil, err
+			}
+			defer flag.Close()
+			return native(ctx, phaseSerialize, "bool.serialize", flag.Serialize)
+		}
+
+		maxLen := CurrentLimits().MaxUint8Ciphertext
+		operands := [2][]*Uint8Ciphertext{make([]*Uint8Ciphertext, len(lhs)), make([]*Uint8Ciphertext, len(rhs))}
+		for side, data := range [2][][]byte{lhs, rhs} {
+			for i, v := range data {
+				if err := checkSerialized(v, maxLen); err != nil {
+					return nil, fmt.Errorf("operand %d byte %d: %w", side, i, err)
+				}
+				ct, release, err := deserializeOperand(ctx, "uint8", v, Uint8Deserialize)
+				if err != nil {
+					return nil, fmt.Errorf("operand %d byte %d: %w", side, i, err)
+				}
+				defer release()
+				operands[side][i] = ct
+			}
+		}
+		flag, err := nativeFanout(ctx, phaseCompute, op, workers, func(workers int) (*FheBool, error) { return s.server.EqualBytes(operands[0], operands[1], workers) })
+		if err != nil {
+			return nil, err
+		}
+		defer flag.Close()
+		return native(ctx, phaseSerialize, "bool.serialize", flag.Serialize)
+	})
+}
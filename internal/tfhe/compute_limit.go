@@ -0,0 +1,214 @@
+package tfhe
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A compute limit bounds the tfhe-c calls running at once, across the
+// process and per tenant, so a burst of requests queues instead of pinning
+// more OS threads than there are cores and slowing every evaluation down.
+// Calls take slots in arrival order, except that a call held back only by
+// its tenant's share does not hold up other tenants. A call that waits
+// longer than the limit allows fails with ErrOverloaded. The tenant of a
+// call comes from WithTenant; calls without one share the empty tenant.
+
+// ComputeLimit configures SetComputeLimit.
+type ComputeLimit struct {
+	// Global bounds the native calls running at once; 0 is unbounded.
+	Global int
+	// PerTenant bounds the native calls one tenant runs at once; 0 is
+	// unbounded.
+	PerTenant int
+	// Wait is how long a call queues for a slot before failing with
+	// ErrOverloaded; 0 waits until the caller's context ends.
+	Wait time.Duration
+}
+
+// ComputeStats describes the compute limit and its queue.
+type ComputeStats struct {
+	Global    int `json:"global"`
+	PerTenant int `json:"per_tenant"`
+	Running   int `json:"running"`
+	Queued    int `json:"queued"`
+	// Waited counts the calls that queued for a slot, Rejected those that
+	// gave up with ErrOverloaded, since the process started.
+	Waited   int64 `json:"waited"`
+	Rejected int64 `json:"rejected"`
+}
+
+type computeSlots struct {
+	mu      sync.Mutex
+	limit   ComputeLimit
+	running int
+	tenants map[string]int // running calls by tenant
+	queue   list.List      // *slotWaiter, oldest first
+}
+
+type slotWaiter struct {
+	tenant  string
+	granted chan struct{} // closed, under mu, when the slot is taken for it
+}
+
+var (
+	slots                     atomic.Pointer[computeSlots]
+	slotWaits, slotRejections atomic.Int64
+)
+
+// SetComputeLimit installs l for calls starting from now; calls already
+// running or queued finish under the previous limit. A limit with neither
+// bound set lifts it, the default.
+func SetComputeLimit(l ComputeLimit) {
+	l.Global, l.PerTenant, l.Wait = max(l.Global, 0), max(l.PerTenant, 0), max(l.Wait, 0)
+	if l.Global == 0 && l.PerTenant == 0 {
+		slots.Store(nil)
+		return
+	}
+	slots.Store(&computeSlots{limit: l, tenants: make(map[string]int)})
+}
+
+// ComputeUsage returns a snapshot of the compute limit.
+func ComputeUsage() ComputeStats {
+	stats := ComputeStats{Waited: slotWaits.Load(), Rejected: slotRejections.Load()}
+	if s := slots.Load(); s != nil {
+		s.mu.Lock()
+		stats.Global, stats.PerTenant = s.limit.Global, s.limit.PerTenant
+		stats.Running, stats.Queued = s.running, s.queue.Len()
+		s.mu.Unlock()
+	}
+	return stats
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx whose native calls count towards
+// tenant's share of the compute limit.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// acquireSlot waits for a compute slot for the tenant of ctx and returns
+// the function releasing it.
+func acquireSlot(ctx context.Context, name string) (func(), error) {
+	_, release, err := acquireSlots(ctx, name, 1)
+	return release, err
+}
+
+// acquireSlots waits for a compute slot for the tenant of ctx, then takes
+// up to n-1 more that are free with nobody queued for them. It returns how
+// many it holds, n when there is no limit, and the function releasing
+// them.
+func acquireSlots(ctx context.Context, name string, n int) (int, func(), error) {
+	s := slots.Load()
+	if s == nil {
+		return n, func() {}, nil
+	}
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	if err := s.acquire(ctx, name, tenant); err != nil {
+		return 0, nil, err
+	}
+	held := 1
+	s.mu.Lock()
+	for ; held < n && s.queue.Len() == 0 && s.fits(tenant); held++ {
+		s.take(tenant)
+	}
+	s.mu.Unlock()
+	return held, func() { s.release(tenant, held) }, nil
+}
+
+// acquire waits for a compute slot for tenant.
+func (s *computeSlots) acquire(ctx context.Context, name, tenant string) error {
+	s.mu.Lock()
+	if s.fits(tenant) {
+		s.take(tenant)
+		s.mu.Unlock()
+		return nil
+	}
+	w := &slotWaiter{tenant: tenant, granted: make(chan struct{})}
+	e := s.queue.PushBack(w)
+	s.mu.Unlock()
+	slotWaits.Add(1)
+
+	var timeout <-chan time.Time
+	if s.limit.Wait > 0 {
+		timer := time.NewTimer(s.limit.Wait)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	var err error
+	select {
+	case <-w.granted:
+		return nil
+	case <-timeout:
+		err = &kindError{msg: fmt.Sprintf("%s waited %s for a compute slot", name, s.limit.Wait), kind: ErrOverloaded}
+	case <-ctx.Done():
+		err = slotAbandoned(ctx, name)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	select {
+	case <-w.granted:
+		// The slot was taken for the call as it gave up; use it.
+		return nil
+	default:
+	}
+	s.queue.Remove(e)
+	if errors.Is(err, ErrOverloaded) {
+		slotRejections.Add(1)
+	}
+	return err
+}
+
+// slotAbandoned reports a call whose context ended while it queued for a
+// slot: past its deadline it timed out, cancelled it gave up on a server
+// too busy to serve it in time.
+func slotAbandoned(ctx context.Context, name string) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &kindError{msg: fmt.Sprintf("%s: deadline passed while waiting for a compute slot", name), kind: ErrOpTimeout}
+	}
+	return &kindError{msg: fmt.Sprintf("%s: cancelled while waiting for a compute slot", name), kind: ErrOverloaded}
+}
+
+// fits reports whether a call of tenant may start now; s.mu is held.
+func (s *computeSlots) fits(tenant string) bool {
+	return (s.limit.Global == 0 || s.running < s.limit.Global) &&
+		(s.limit.PerTenant == 0 || s.tenants[tenant] < s.limit.PerTenant)
+}
+
+// take counts a call of tenant as running; s.mu is held.
+func (s *computeSlots) take(tenant string) {
+	s.running++
+	s.tenants[tenant]++
+}
+
+// release frees n slots of tenant and hands the free slots to the oldest
+// queued calls that fit.
+func (s *computeSlots) release(tenant string, n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for range n {
+		s.releaseLocked(tenant)
+	}
+}
+
+// releaseLocked frees one slot of tenant, as release, with s.mu held.
+func (s *computeSlots) releaseLocked(tenant string) {
+	s.running--
+	if s.tenants[tenant]--; s.tenants[tenant] == 0 {
+		delete(s.tenants, tenant)
+	}
+	for e := s.queue.Front(); e != nil && (s.limit.Global == 0 || s.running < s.limit.Global); {
+		next := e.Next()
+		if w := e.Value.(*slotWaiter); s.fits(w.tenant) {
+			s.queue.Remove(e)
+			s.take(w.tenant)
+			close(w.granted)
+		}
+		e = next
+	}
+}
@@ -0,0 +1,317 @@
+package tfhe
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeCall is a native call, of a fake fn, that runs until finished.
+type fakeCall struct {
+	started chan struct{}
+	finish  chan struct{}
+	done    chan error
+}
+
+func startCall(ctx context.Context, tenant string) *fakeCall {
+	c := &fakeCall{started: make(chan struct{}), finish: make(chan struct{}), done: make(chan error, 1)}
+	go func() {
+		_, err := native(WithTenant(ctx, tenant), phaseCompute, "test.op", func() (struct{}, error) {
+			close(c.started)
+			<-c.finish
+			return struct{}{}, nil
+		})
+		c.done <- err
+	}()
+	return c
+}
+
+func (c *fakeCall) isStarted() bool {
+	select {
+	case <-c.started:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *fakeCall) end(t *testing.T) {
+	t.Helper()
+	close(c.finish)
+	if err := <-c.done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (c *fakeCall) waitStarted(t *testing.T) {
+	t.Helper()
+	select {
+	case <-c.started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("call did not get a slot")
+	}
+}
+
+func setComputeLimit(t *testing.T, l ComputeLimit) {
+	t.Helper()
+	SetComputeLimit(l)
+	t.Cleanup(func() { SetComputeLimit(ComputeLimit{}) })
+}
+
+// waitUsage waits until running calls hold slots and queued ones wait.
+func waitUsage(t *testing.T, running, queued int) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		u := ComputeUsage()
+		if u.Running == running && u.Queued == queued {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d running and %d queued, want %d and %d", u.Running, u.Queued, running, queued)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestComputeLimitGlobal(t *testing.T) {
+	setComputeLimit(t, ComputeLimit{Global: 2})
+	ctx := context.Background()
+	a, b := startCall(ctx, "a"), startCall(ctx, "b")
+	a.waitStarted(t)
+	b.waitStarted(t)
+	c := startCall(ctx, "c")
+	waitUsage(t, 2, 1)
+	if c.isStarted() {
+		t.Fatal("third call ran past a global limit of 2")
+	}
+	a.end(t)
+	c.waitStarted(t)
+	waitUsage(t, 2, 0)
+	b.end(t)
+	c.end(t)
+	waitUsage(t, 0, 0)
+}
+
+func TestComputeLimitPerTenant(t *testing.T) {
+	setComputeLimit(t, ComputeLimit{PerTenant: 1})
+	ctx := context.Background()
+	a1 := startCall(ctx, "a")
+	a1.waitStarted(t)
+	a2 := startCall(ctx, "a")
+	waitUsage(t, 1, 1)
+	// A call held back only by its tenant's share does not hold up others.
+	b := startCall(ctx, "b")
+	b.waitStarted(t)
+	if a2.isStarted() {
+		t.Fatal("second call of a tenant ran past its share of 1")
+	}
+	a1.end(t)
+	a2.waitStarted(t)
+	a2.end(t)
+	b.end(t)
+	waitUsage(t, 0, 0)
+}
+
+func TestComputeLimitFIFO(t *testing.T) {
+	setComputeLimit(t, ComputeLimit{Global: 1})
+	ctx := context.Background()
+	holder := startCall(ctx, "")
+	holder.waitStarted(t)
+	var queued []*fakeCall
+	for i := range 3 {
+		queued = append(queued, startCall(ctx, ""))
+		waitUsage(t, 1, i+1)
+	}
+	holder.end(t)
+	for i, c := range queued {
+		c.waitStarted(t)
+		for _, later := range queued[i+1:] {
+			if later.isStarted() {
+				t.Fatalf("call %d ran before call %d, queued ahead of it", i+1, i)
+			}
+		}
+		c.end(t)
+	}
+	waitUsage(t, 0, 0)
+}
+
+func TestComputeLimitGiveUp(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		wait     time.Duration
+		timeout  time.Duration // of the caller's context; 0 has none
+		cancel   bool          // cancels the caller's context once queued
+		kind     error
+		rejected int64
+	}{
+		{name: "queue timeout", wait: 20 * time.Millisecond, kind: ErrOverloaded, rejected: 1},
+		{name: "deadline", timeout: 20 * time.Millisecond, kind: ErrOpTimeout},
+		{name: "deadline before queue timeout", wait: time.Minute, timeout: 20 * time.Millisecond, kind: ErrOpTimeout},
+		{name: "cancelled", cancel: true, kind: ErrOverloaded, rejected: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setComputeLimit(t, ComputeLimit{Global: 1, Wait: tc.wait})
+			holder := startCall(context.Background(), "")
+			holder.waitStarted(t)
+			defer holder.end(t)
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if tc.timeout > 0 {
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+			rejected := ComputeUsage().Rejected
+			c := startCall(ctx, "")
+			waitUsage(t, 1, 1)
+			if tc.cancel {
+				cancel()
+			}
+			err := <-c.done
+			if !errors.Is(err, tc.kind) {
+				t.Fatalf("got %v, want %v", err, tc.kind)
+			}
+			if c.isStarted() {
+				t.Fatal("call ran after giving up")
+			}
+			waitUsage(t, 1, 0)
+			if got := ComputeUsage().Rejected - rejected; got != tc.rejected {
+				t.Fatalf("%d calls rejected, want %d", got, tc.rejected)
+			}
+		})
+	}
+}
+
+// A slot handed to a call as it gives up is used rather than leaked.
+func TestComputeLimitGrantedWhileGivingUp(t *testing.T) {
+	setComputeLimit(t, ComputeLimit{Global: 1})
+	for range 50 {
+		if _, err := acquireSlot(context.Background(), "holder"); err != nil {
+			t.Fatal(err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		type result struct {
+			release func()
+			err     error
+		}
+		got := make(chan result, 1)
+		go func() {
+			release, err := acquireSlot(ctx, "waiter")
+			got <- result{release, err}
+		}()
+		waitUsage(t, 1, 1)
+
+		// Cancel and hand the holder's slot over at once, so the waiter
+		// sees both and may pick either.
+		s := slots.Load()
+		s.mu.Lock()
+		cancel()
+		s.releaseLocked("")
+		s.mu.Unlock()
+
+		r := <-got
+		if r.err != nil {
+			t.Fatalf("granted waiter failed: %v", r.err)
+		}
+		waitUsage(t, 1, 0)
+		r.release()
+		waitUsage(t, 0, 0)
+	}
+}
+
+// fanout runs n fake native calls through parallel under nativeFanout, as
+// the batch operations do, and returns the most that ran at once.
+func fanout(ctx context.Context, t *testing.T, n, workers int) int {
+	t.Helper()
+	var running, peak atomic.Int64
+	_, err := nativeFanout(ctx, phaseCompute, "test.fanout", workers, func(workers int) (struct{}, error) {
+		return struct{}{}, parallel(n, workers, "test.fanout", func(int) error {
+			r := running.Add(1)
+			for p := peak.Load(); r > p && !peak.CompareAndSwap(p, r); p = peak.Load() {
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return int(peak.Load())
+}
+
+func TestComputeLimitFanout(t *testing.T) {
+	for _, tc := range []struct {
+		limit   ComputeLimit
+		workers int
+		peak    int
+	}{
+		{limit: ComputeLimit{Global: 1}, workers: 8, peak: 1},
+		{limit: ComputeLimit{Global: 3}, workers: 8, peak: 3},
+		{limit: ComputeLimit{PerTenant: 2}, workers: 8, peak: 2},
+		{limit: ComputeLimit{Global: 8}, workers: 2, peak: 2},
+	} {
+		setComputeLimit(t, tc.limit)
+		if peak := fanout(context.Background(), t, 32, tc.workers); peak != tc.peak {
+			t.Errorf("%+v with %d workers: %d calls at once, want %d", tc.limit, tc.workers, peak, tc.peak)
+		}
+		waitUsage(t, 0, 0)
+	}
+}
+
+// A fan-out takes no more slots than the one it waited for while other
+// calls queue.
+func TestComputeLimitFanoutQueued(t *testing.T) {
+	setComputeLimit(t, ComputeLimit{Global: 4, PerTenant: 2})
+	ctx := context.Background()
+	a1, a2 := startCall(ctx, "a"), startCall(ctx, "a")
+	a1.waitStarted(t)
+	a2.waitStarted(t)
+	a3 := startCall(ctx, "a")
+	waitUsage(t, 2, 1)
+	if peak := fanout(WithTenant(ctx, "b"), t, 16, 8); peak != 1 {
+		t.Fatalf("fan-out ran %d calls at once with a call queued, want 1", peak)
+	}
+	a1.end(t)
+	a3.waitStarted(t)
+	a2.end(t)
+	a3.end(t)
+	waitUsage(t, 0, 0)
+}
+
+// A batch of gates with more workers than slots stays within the limit and
+// still completes.
+func TestComputeLimitGateMany(t *testing.T) {
+	setComputeLimit(t, ComputeLimit{Global: 1})
+	s, err := NewBooleanService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	pairs := make([][2][]byte, 4)
+	for i := range pairs {
+		for j := range pairs[i] {
+			if pairs[i][j], err = s.EncryptRaw(ctx, (i>>j)&1 == 1); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	out, err := s.GateManyRaw(ctx, GateXor, pairs, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ct := range out {
+		v, err := s.DecryptRaw(ctx, ct)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i == 1 || i == 2; v != want {
+			t.Errorf("pair %d: xor %v, want %v", i, v, want)
+		}
+	}
+	waitUsage(t, 0, 0)
+}
@@ -0,0 +1,124 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// An operation budget bounds how long a caller waits for a homomorphic
+// evaluation. Native calls cannot be interrupted, so an evaluation past its
+// budget fails with ErrOpTimeout while it keeps running on its own goroutine
+// and OS thread until tfhe-c returns. It holds its operands and the
+// service's read lock until then, and its result is discarded. Evaluations
+// slower than the slow-op threshold are logged and counted either way.
+
+var (
+	opBudget, slowOpThreshold atomic.Int64 // time.Duration; 0 disables
+	stuckOps                  atomic.Int64
+	slowOps, timedOutOps      sync.Map // op name -> *atomic.Int64
+)
+
+// SetOpTimeout sets the budget of each evaluation; d <= 0, the default,
+// waits for evaluations however long they take.
+func SetOpTimeout(d time.Duration) { opBudget.Store(int64(max(d, 0))) }
+
+// SetSlowOpThreshold logs and counts evaluations taking d or longer; d <= 0,
+// the default, disables the report.
+func SetSlowOpThreshold(d time.Duration) { slowOpThreshold.Store(int64(max(d, 0))) }
+
+// DeadlineStats counts, by operation, evaluations that were slow or ran past
+// their budget, and how many of the latter are still running.
+type DeadlineStats struct {
+	Slow     map[string]int64 `json:"slow"`
+	TimedOut map[string]int64 `json:"timed_out"`
+	Stuck    int64            `json:"stuck"`
+}
+
+// DeadlineUsage returns a snapshot of the slow and timed-out evaluations
+// since the process started.
+func DeadlineUsage() DeadlineStats {
+	return DeadlineStats{Slow: snapshotCounts(&slowOps), TimedOut: snapshotCounts(&timedOutOps), Stuck: stuckOps.Load()}
+}
+
+func snapshotCounts(m *sync.Map) map[string]int64 {
+	out := make(map[string]int64)
+	m.Range(func(k, v any) bool {
+		out[k.(string)] = v.(*atomic.Int64).Load()
+		return true
+	})
+	return out
+}
+
+func countOp(m *sync.Map, op string) {
+	c, ok := m.Load(op)
+	if !ok {
+		c, _ = m.LoadOrStore(op, new(atomic.Int64))
+	}
+	c.(*atomic.Int64).Add(1)
+}
+
+// detach copies data when a budget is set, since an evaluation that runs
+// past it outlives the caller's buffers.
+func detach(data []byte) []byte {
+	if opBudget.Load() <= 0 {
+		return data
+	}
+	return append([]byte(nil), data...)
+}
+
+// bounded runs fn, the body of evaluation op, and waits for it at most the
+// operation budget. Inputs fn reads from the caller must be detached.
+func bounded[T any](ctx context.Context, op string, fn func(context.Context) (T, error)) (T, error) {
+	start := time.Now()
+	budget := time.Duration(opBudget.Load())
+	if budget <= 0 {
+		v, err := fn(ctx)
+		reportSlow(op, time.Since(start))
+		return v, err
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := fn(ctx)
+		done <- result{v, err}
+	}()
+	timer := time.NewTimer(budget)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		reportSlow(op, time.Since(start))
+		return r.v, r.err
+	case <-timer.C:
+	}
+
+	countOp(&timedOutOps, op)
+	stuckOps.Add(1)
+	log.Printf("tfhe: %s exceeded its %s budget; its thread stays busy until the native call returns", op, budget)
+	go func() {
+		<-done
+		stuckOps.Add(-1)
+		elapsed := time.Since(start)
+		reportSlow(op, elapsed)
+		log.Printf("tfhe: %s finished after %s, %s past its budget", op, elapsed.Round(time.Millisecond), (elapsed - budget).Round(time.Millisecond))
+	}()
+	var zero T
+	return zero, &kindError{msg: fmt.Sprintf("%s did not finish within %s", op, budget), kind: ErrOpTimeout}
+}
+
+// reportSlow logs and counts op if it took at least the slow-op threshold.
+func reportSlow(op string, elapsed time.Duration) {
+	threshold := time.Duration(slowOpThreshold.Load())
+	if threshold <= 0 || elapsed < threshold {
+		return
+	}
+	countOp(&slowOps, op)
+	log.Printf("tfhe: slow operation %s took %s", op, elapsed.Round(time.Millisecond))
+}
@@ -0,0 +1,104 @@
+package tfhe
+
+import "sync/atomic"
+
+// The package-level integer operations below predate the methods on
+// Uint8ServerKey and run under a process-wide default key, which cannot
+// serve several key sets at once. They remain for existing callers.
+
+var defaultUint8ServerKey atomic.Pointer[Uint8ServerKey]
+
+// GenerateUint8Keys returns a new integer keypair and installs its server key
+// as the default for the package-level operations.
+func GenerateUint8Keys() (*Uint8ClientKey, *Uint8ServerKey, error) {
+	client, server, err := generateUint8Keys()
+	if err != nil {
+		return nil, nil, err
+	}
+	defaultUint8ServerKey.Store(server)
+	return client, server, nil
+}
+
+// UseUint8ServerKey installs sk as the default key of the package-level
+// integer operations.
+//
+// Deprecated: call the operations on sk instead, e.g. sk.Add(lhs, rhs).
+func UseUint8ServerKey(sk *Uint8ServerKey) error {
+	if sk == nil {
+		return errServerKeyNil
+	}
+	defaultUint8ServerKey.Store(sk)
+	return nil
+}
+
+// Uint8Add performs homomorphic addition under the default key.
+//
+// Deprecated: use (*Uint8ServerKey).Add.
+func Uint8Add(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	return defaultUint8ServerKey.Load().Add(lhs, rhs)
+}
+
+// Uint8BitAnd performs homomorphic bitwise AND under the default key.
+//
+// Deprecated: use (*Uint8ServerKey).BitAnd.
+func Uint8BitAnd(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	return defaultUint8ServerKey.Load().BitAnd(lhs, rhs)
+}
+
+// Uint8BitXor performs homomorphic bitwise XOR under the default key.
+//
+// Deprecated: use (*Uint8ServerKey).BitXor.
+func Uint8BitXor(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	return defaultUint8ServerKey.Load().BitXor(lhs, rhs)
+}
+
+// Uint8Compare compares two uint8 ciphertexts under the default key.
+//
+// Deprecated: use (*Uint8ServerKey).Compare.
+func Uint8Compare(cmp Comparison, lhs, rhs *Uint8Ciphertext) (*FheBool, error) {
+	return defaultUint8ServerKey.Load().Compare(cmp, lhs, rhs)
+}
+
+// Uint8IfThenElse selects between two uint8 ciphertexts under the default
+// key.
+//
+// Deprecated: use (*Uint8ServerKey).IfThenElse.
+func Uint8IfThenElse(cond *FheBool, then, els *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+	return defaultUint8ServerKey.Load().IfThenElse(cond, then, els)
+}
+
+// IntAdd performs homomorphic addition under the default key.
+//
+// Deprecated: use (*Uint8ServerKey).IntAdd.
+func IntAdd(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return defaultUint8ServerKey.Load().IntAdd(lhs, rhs)
+}
+
+// IntBitAnd performs homomorphic bitwise AND under the default key.
+//
+// Deprecated: use (*Uint8ServerKey).IntBitAnd.
+func IntBitAnd(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return defaultUint8ServerKey.Load().IntBitAnd(lhs, rhs)
+}
+
+// IntBitXor performs homomorphic bitwise XOR under the default key.
+//
+// Deprecated: use (*Uint8ServerKey).IntBitXor.
+func IntBitXor(lhs, rhs *IntCiphertext) (*IntCiphertext, error) {
+	return defaultUint8ServerKey.Load().IntBitXor(lhs, rhs)
+}
+
+// IntCompare compares two integer ciphertexts under the default key.
+//
+// Deprecated: use (*Uint8ServerKey).IntCompare.
+func IntCompare(cmp Comparison, lhs, rhs *IntCiphertext) (*FheBool, error) {
+	return defaultUint8ServerKey.Load().IntCompare(cmp, lhs, rhs)
+}
+
+// IntIfThenElse selects between two integer ciphertexts under the default
+// key.
+//
+// Deprecated: use (*Uint8ServerKey).IntIfThenElse.
+func IntIfThenElse(cond *FheBool, then, els *IntCiphertext) (*IntCiphertext, error) {
+	return defaultUint8ServerKey.Load().IntIfThenElse(cond, then, els)
+}
@@ -0,0 +1,137 @@
+package tfhe
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Sentinel errors let callers tell bad input from server faults with errors.Is.
+var (
+	// ErrNilKey reports a missing or already released key.
+	ErrNilKey = errors.New("key is nil")
+	// ErrInvalidCiphertext reports a missing, empty or malformed ciphertext.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+	// ErrInvalidKey reports serialized key material that cannot be loaded.
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrCiphertextTooLarge reports a ciphertext above the configured Limits.
+	ErrCiphertextTooLarge = errors.New("ciphertext too large")
+	// ErrMemoryLimit reports that the native memory cap would be exceeded.
+	ErrMemoryLimit = errors.New("native memory limit exceeded")
+	// ErrServerKeyNotSet reports an integer operation run without a server key.
+	ErrServerKeyNotSet = errors.New("server key is not set")
+	// ErrValueOutOfRange reports a plaintext that does not fit the ciphertext type.
+	ErrValueOutOfRange = errors.New("value out of range")
+	// ErrUnsupported reports an operation the backend the package was built
+	// with does not implement, such as integer types in the pure-Go backend.
+	ErrUnsupported = errors.New("not supported by this backend")
+	// ErrClientKeyWithheld reports an encrypt, decrypt or public key
+	// operation on a service loaded without its client key, which is kept
+	// in custody elsewhere.
+	ErrClientKeyWithheld = errors.New("client key is not held by this server")
+	// ErrTrustedReencryptDisabled reports a re-encryption for a recipient
+	// while SetTrustedReencrypt is off.
+	ErrTrustedReencryptDisabled = errors.New("trusted re-encryption is disabled")
+	// ErrNativePanic reports a panic raised while calling into tfhe-c, or
+	// an isolated worker dying on the operation's input (see SetIsolation),
+	// which fails the operation instead of the process.
+	ErrNativePanic = errors.New("native call panicked")
+	// ErrClosed reports an operation on a key or ciphertext after its Close.
+	// It is matched alongside ErrNilKey or ErrInvalidCiphertext, as for a
+	// missing object.
+	ErrClosed = errors.New("object is closed")
+	// ErrOpTimeout reports an evaluation that ran past the budget set with
+	// SetOpTimeout, or a call whose context deadline passed while it
+	// queued for a compute slot. The native call itself cannot be stopped
+	// and finishes in the background.
+	ErrOpTimeout = errors.New("operation timed out")
+	// ErrOverloaded reports a native call that waited longer than the
+	// ComputeLimit allows for a compute slot, or was cancelled while it
+	// waited.
+	ErrOverloaded = errors.New("server overloaded")
+	// ErrSwitchKeyNotSet reports a re-encryption for a tenant that has not
+	// uploaded a switch key for the current keys.
+	ErrSwitchKeyNotSet = errors.New("switch key is not set")
+	// ErrTrivialCiphertext reports an evaluation whose result is a trivial
+	// encryption, readable without the key, refused under SetRejectTrivial.
+	// Its operands were trivial too.
+	ErrTrivialCiphertext = errors.New("trivial ciphertext")
+)
+
+var (
+	errClientKeyNil        = &kindError{msg: "client key is nil", kind: ErrNilKey}
+	errServerKeyNil        = &kindError{msg: "server key is nil", kind: ErrNilKey}
+	errPublicKeyNil        = &kindError{msg: "public key is nil", kind: ErrNilKey}
+	errSwitchKeyNil        = &kindError{msg: "switch key is nil", kind: ErrNilKey}
+	errClientKeyWithheld   = &kindError{msg: "client key is held in custody and trusted decryption is disabled", kind: ErrClientKeyWithheld}
+	errTrustedReencryptOff = &kindError{msg: "re-encryption for a recipient decrypts on the server and trusted re-encryption is disabled", kind: ErrTrustedReencryptDisabled}
+	errCiphertextNil       = &kindError{msg: "ciphertext is nil", kind: ErrInvalidCiphertext}
+	errCiphertextEmpty     = &kindError{msg: "ciphertext data is empty", kind: ErrInvalidCiphertext}
+
+	errClientKeyClosed  = &closedError{msg: "client key is closed", kind: ErrNilKey}
+	errServerKeyClosed  = &closedError{msg: "server key is closed", kind: ErrNilKey}
+	errPublicKeyClosed  = &closedError{msg: "public key is closed", kind: ErrNilKey}
+	errSwitchKeyClosed  = &closedError{msg: "switch key is closed", kind: ErrNilKey}
+	errCiphertextClosed = &closedError{msg: "ciphertext is closed", kind: ErrInvalidCiphertext}
+)
+
+// OperandError reports which operand of an operation was rejected, counting
+// from 0 in the order the operation takes them, so callers can point at the
+// input at fault. Its message is that of Err.
+type OperandError struct {
+	Index int
+	Err   error
+}
+
+func (e *OperandError) Error() string { return e.Err.Error() }
+
+func (e *OperandError) Unwrap() error { return e.Err }
+
+// operandError wraps err, if any, as an OperandError for operand i.
+func operandError(i int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &OperandError{Index: i, Err: err}
+}
+
+// ErrCAPI reports a non-zero return code from the TFHE C API.
+type ErrCAPI struct {
+	Op   string
+	Code int
+}
+
+func (e *ErrCAPI) Error() string {
+	return fmt.Sprintf("%s: tfhe error code %d", e.Op, e.Code)
+}
+
+// kindError keeps a specific message while matching a sentinel via errors.Is.
+type kindError struct {
+	msg  string
+	kind error
+}
+
+func (e *kindError) Error() string { return e.msg }
+
+func (e *kindError) Unwrap() error { return e.kind }
+
+// closedError matches ErrClosed as well as kind, the sentinel for a missing
+// object of the same type.
+type closedError struct {
+	msg  string
+	kind error
+}
+
+func (e *closedError) Error() string { return e.msg }
+
+func (e *closedError) Unwrap() []error { return []error{ErrClosed, e.kind} }
+
+// invalidCiphertext marks err, typically a failed decode or deserialization,
+// as bad input.
+func invalidCiphertext(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidCiphertext, err)
+}
+
+// invalidKey marks err, typically a failed key deserialization, as bad input.
+func invalidKey(err error) error {
+	return fmt.Errorf("%w: %w", ErrInvalidKey, err)
+}
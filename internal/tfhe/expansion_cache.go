@@ -0,0 +1,181 @@
+package tfhe
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// MaxCompactList bounds a serialized compact ciphertext list.
+	MaxCompactList = 16 << 20
+	// MaxCompactListLen bounds the ciphertexts of one compact list.
+	MaxCompactListLen = 1024
+)
+
+// ExpandedCiphertext is one ciphertext of an expanded compact list.
+type ExpandedCiphertext struct {
+	// Type is the ciphertext type: bool, uint2, uint4, uint8, uint16,
+	// uint32 or uint64.
+	Type string
+	// Data is the serialized ciphertext.
+	Data []byte
+}
+
+// The expansion cache keeps the serialized ciphertexts of recently expanded
+// compact lists, keyed by the SHA-256 of the list, so a list uploaded again
+// is not expanded again; expansion dominates the cost of compact uploads.
+// Entries belong to the tenant that uploaded the list and count towards its
+// byte budget as well as the cache's, so one tenant cannot evict everyone
+// else's lists. Entries are tied to the service and key generation that
+// expanded them and simply age out after a rotation.
+
+type expansionKey struct {
+	tenant     string
+	service    *Uint8Service
+	generation uint64
+	sum        [sha256.Size]byte
+}
+
+type expansionEntry struct {
+	key   expansionKey
+	value []ExpandedCiphertext
+	bytes int64
+}
+
+type expansionCache struct {
+	mu          sync.Mutex
+	max, tenant int64
+	bytes       int64
+	perTenant   map[string]int64
+	order       *list.List // front is most recently used
+	entries     map[expansionKey]*list.Element
+}
+
+var (
+	expansions                     atomic.Pointer[expansionCache]
+	expansionHits, expansionMisses atomic.Int64
+)
+
+// SetExpansionCache keeps up to maxBytes of expanded compact lists, and at
+// most tenantBytes for any one tenant (no per-tenant bound when
+// tenantBytes <= 0); maxBytes <= 0 disables the cache, which is the default.
+func SetExpansionCache(maxBytes, tenantBytes int64) {
+	var c *expansionCache
+	if maxBytes > 0 {
+		if tenantBytes <= 0 || tenantBytes > maxBytes {
+			tenantBytes = maxBytes
+		}
+		c = &expansionCache{
+			max:       maxBytes,
+			tenant:    tenantBytes,
+			perTenant: make(map[string]int64),
+			order:     list.New(),
+			entries:   make(map[expansionKey]*list.Element),
+		}
+	}
+	expansions.Store(c)
+}
+
+// ExpansionCacheStats reports the expansion cache's size and effectiveness.
+type ExpansionCacheStats struct {
+	Entries     int   `json:"entries"`
+	Bytes       int64 `json:"bytes"`
+	MaxBytes    int64 `json:"max_bytes"`
+	TenantBytes int64 `json:"tenant_bytes"`
+	Tenants     int   `json:"tenants"`
+	Hits        int64 `json:"hits"`
+	Misses      int64 `json:"misses"`
+}
+
+// ExpansionCacheUsage returns a snapshot of the expansion cache.
+func ExpansionCacheUsage() ExpansionCacheStats {
+	stats := ExpansionCacheStats{Hits: expansionHits.Load(), Misses: expansionMisses.Load()}
+	if c := expansions.Load(); c != nil {
+		c.mu.Lock()
+		stats.Entries, stats.Bytes, stats.Tenants = c.order.Len(), c.bytes, len(c.perTenant)
+		stats.MaxBytes, stats.TenantBytes = c.max, c.tenant
+		c.mu.Unlock()
+	}
+	return stats
+}
+
+// Expand expands a serialized compact ciphertext list uploaded by tenant,
+// through the expansion cache when it is enabled. The returned ciphertexts
+// may be shared with other callers and must not be modified.
+func (s *Uint8Service) Expand(ctx context.Context, tenant string, data []byte) (out []ExpandedCiphertext, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	c := expansions.Load()
+	var key expansionKey
+	if c != nil {
+		key = expansionKey{tenant: tenant, service: s, generation: s.generation, sum: sha256.Sum256(data)}
+		if v, ok := c.get(key); ok {
+			expansionHits.Add(1)
+			return v, nil
+		}
+		expansionMisses.Add(1)
+	}
+	ctx, end := begin(ctx, s.metrics, "compact_list.expand", nil, &err)
+	defer end()
+
+	out, err = native(ctx, phaseCompute, "compact_list.expand", func() ([]ExpandedCiphertext, error) { return s.server.ExpandCompactList(data) })
+	if err != nil {
+		return nil, err
+	}
+	if c != nil {
+		c.put(key, out)
+	}
+	return out, nil
+}
+
+func (c *expansionCache) get(key expansionKey) ([]ExpandedCiphertext, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*expansionEntry).value, true
+}
+
+// put caches v under key, evicting the tenant's least recently used lists
+// beyond its budget and then everyone's beyond the cache's. A list larger
+// than the tenant budget is not cached.
+func (c *expansionCache) put(key expansionKey, v []ExpandedCiphertext) {
+	var n int64
+	for _, ct := range v {
+		n += int64(len(ct.Data))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.entries[key]; ok || n > c.tenant {
+		return
+	}
+	for el := c.order.Back(); el != nil && c.perTenant[key.tenant]+n > c.tenant; {
+		prev := el.Prev()
+		if el.Value.(*expansionEntry).key.tenant == key.tenant {
+			c.remove(el)
+		}
+		el = prev
+	}
+	for c.bytes+n > c.max {
+		c.remove(c.order.Back())
+	}
+	c.entries[key] = c.order.PushFront(&expansionEntry{key: key, value: v, bytes: n})
+	c.bytes += n
+	c.perTenant[key.tenant] += n
+}
+
+// remove drops el. c.mu must be held.
+func (c *expansionCache) remove(el *list.Element) {
+	e := c.order.Remove(el).(*expansionEntry)
+	delete(c.entries, e.key)
+	c.bytes -= e.bytes
+	if c.perTenant[e.key.tenant] -= e.bytes; c.perTenant[e.key.tenant] == 0 {
+		delete(c.perTenant, e.key.tenant)
+	}
+}
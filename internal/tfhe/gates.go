@@ -0,0 +1,82 @@
+package tfhe
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Gate is a binary boolean gate applied by GateMany.
+type Gate string
+
+const (
+	GateAnd Gate = "and"
+	GateOr  Gate = "or"
+	GateXor Gate = "xor"
+)
+
+// Gates lists the gates GateMany applies.
+var Gates = []Gate{GateAnd, GateOr, GateXor}
+
+// Pair is the two operands of one gate.
+type Pair struct {
+	LHS, RHS *Ciphertext
+}
+
+// AndMany performs a homomorphic AND on every pair; see GateMany.
+func (s *ServerKey) AndMany(pairs []Pair) ([]*Ciphertext, error) {
+	return s.GateMany(GateAnd, pairs, 1)
+}
+
+// OrMany performs a homomorphic OR on every pair; see GateMany.
+func (s *ServerKey) OrMany(pairs []Pair) ([]*Ciphertext, error) {
+	return s.GateMany(GateOr, pairs, 1)
+}
+
+// XorMany performs a homomorphic XOR on every pair; see GateMany.
+func (s *ServerKey) XorMany(pairs []Pair) ([]*Ciphertext, error) {
+	return s.GateMany(GateXor, pairs, 1)
+}
+
+// GateMany applies gate to every pair and returns the results in order. The
+// pairs are split into contiguous runs across up to workers goroutines, each
+// evaluating its run on one locked OS thread in a few native calls rather
+// than one per gate. On error no results are returned; those already
+// computed are released.
+func (s *ServerKey) GateMany(gate Gate, pairs []Pair, workers int) ([]*Ciphertext, error) {
+	if !slices.Contains(Gates, gate) {
+		return nil, fmt.Errorf("unknown gate %q", gate)
+	}
+	if err := checkMemoryN(objBooleanCiphertext, len(pairs)); err != nil {
+		return nil, err
+	}
+	out := make([]*Ciphertext, len(pairs))
+	if len(pairs) == 0 {
+		return out, nil
+	}
+	workers = min(max(workers, 1), len(pairs))
+	run := (len(pairs) + workers - 1) / workers
+	errs := make([]error, workers)
+	done := make(chan struct{})
+	for w := range workers {
+		lo, hi := w*run, min((w+1)*run, len(pairs))
+		go func() {
+			defer func() { done <- struct{}{} }()
+			defer recoverNative("boolean."+string(gate)+"_many", &errs[w])
+			if lo < hi {
+				errs[w] = s.gateRun(gate, pairs[lo:hi], out[lo:hi])
+			}
+		}()
+	}
+	for range workers {
+		<-done
+	}
+	for _, err := range errs {
+		if err != nil {
+			for _, ct := range out {
+				_ = ct.Close()
+			}
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,80 @@
+package tfhe
+
+// Type names of serialized ciphertexts, as Inspect reports them.
+const (
+	// TypeBoolean is a ciphertext of the boolean gate API.
+	TypeBoolean = "boolean"
+	// TypeBool is an FheBool under the integer keys.
+	TypeBool = "bool"
+	// TypeUint8 is a uint8 ciphertext; the other widths are "uint2",
+	// "uint4", "uint16", "uint32" and "uint64".
+	TypeUint8 = "uint8"
+	// TypeUint8Vector is a vector of uint8 ciphertexts, as MarshalVector
+	// writes it.
+	TypeUint8Vector = "uint8_vector"
+)
+
+// CiphertextInfo describes a serialized ciphertext without decrypting it.
+type CiphertextInfo struct {
+	// Type is the type the data deserializes as, or empty when it
+	// deserializes as none or as several of them.
+	Type string `json:"type,omitempty"`
+	// Candidates lists every type the data deserializes as. tfhe-c does not
+	// tag its serializations, so integer ciphertexts of different widths
+	// may be indistinguishable.
+	Candidates []string `json:"candidates"`
+	// Size is the length of the serialized data in bytes.
+	Size int `json:"size"`
+	// SerializationFormat is the format this build reads; ciphertexts only
+	// deserialize under the format they were written in.
+	SerializationFormat string `json:"serialization_format"`
+	// Compressed is set for the seeded form of an integer or FheBool
+	// ciphertext, which must be decompressed before evaluation.
+	Compressed bool `json:"compressed"`
+	// Trivial reports whether the ciphertext is a trivial encryption, whose
+	// value is readable without the key; it is nil when the backend cannot
+	// tell.
+	Trivial *bool `json:"trivial,omitempty"`
+	// Elements is the number of ciphertexts of a uint8_vector.
+	Elements int `json:"elements,omitempty"`
+}
+
+// inspectTypes lists the types Inspect tries, in the order it reports them.
+var inspectTypes = []string{TypeBoolean, TypeBool, "uint2", "uint4", TypeUint8, "uint16", "uint32", "uint64"}
+
+// Inspect describes serialized ciphertext data by deserializing it as every
+// supported type, within the limits in effect. Nothing is decrypted and no
+// key is needed, so it helps trace "wrong ciphertext type" errors to the
+// data sent.
+func Inspect(data []byte) CiphertextInfo {
+	info := CiphertextInfo{
+		Candidates:          []string{},
+		Size:                len(data),
+		SerializationFormat: serializationFormat(),
+	}
+	if len(data) >= len(vectorMagic) && string(data[:len(vectorMagic)]) == vectorMagic {
+		if elems, err := UnmarshalVector(data, MaxBytesLen, CurrentLimits().MaxUint8Ciphertext); err == nil {
+			info.Type, info.Candidates, info.Elements = TypeUint8Vector, []string{TypeUint8Vector}, len(elems)
+			return info
+		}
+	}
+	for _, typ := range inspectTypes {
+		ok, trivial := probeCiphertext(typ, data)
+		if !ok {
+			continue
+		}
+		info.Candidates = append(info.Candidates, typ)
+		if info.Trivial == nil {
+			info.Trivial = trivial
+		}
+	}
+	if len(info.Candidates) == 0 {
+		if typ := probeCompressed(data); typ != "" {
+			info.Candidates, info.Compressed = []string{typ}, true
+		}
+	}
+	if len(info.Candidates) == 1 {
+		info.Type = info.Candidates[0]
+	}
+	return info
+}
@@ -0,0 +1,193 @@
+//go:build !purego
+
+package tfhe_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// The interop fixtures are ciphertexts in the safe serialization written by
+// tfhe-rs and node-tfhe under shared keys, regenerated with
+// scripts/gen-interop-fixtures.sh whenever the tfhe-c release changes. The
+// test reads them back and, with -interop.update, writes ciphertexts for the
+// script's verify mode to read in turn.
+var interopUpdate = flag.Bool("interop.update", false, "write Go-produced fixtures to testdata/interop/tfhe-go")
+
+const interopDir = "testdata/interop"
+
+type interopManifest struct {
+	Producer    string              `json:"producer"`
+	Ciphertexts []interopCiphertext `json:"ciphertexts"`
+}
+
+// interopCiphertext is a fixture file holding value, booleans as 0 or 1.
+type interopCiphertext struct {
+	Type  string `json:"type"`
+	Value uint64 `json:"value"`
+	File  string `json:"file"`
+}
+
+func TestInteropFixtures(t *testing.T) {
+	svc := interopService(t)
+	ctx := context.Background()
+	for _, producer := range []string{"tfhe-rs", "node-tfhe"} {
+		t.Run(producer, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join(interopDir, producer, "manifest.json"))
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Skipf("no %s fixtures; run scripts/gen-interop-fixtures.sh", producer)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var m interopManifest
+			if err := json.Unmarshal(data, &m); err != nil {
+				t.Fatal(err)
+			}
+			for _, c := range m.Ciphertexts {
+				safe, err := os.ReadFile(filepath.Join(interopDir, producer, c.File))
+				if err != nil {
+					t.Fatal(err)
+				}
+				typ, plain, err := svc.SafeDeserializeRaw(ctx, safe)
+				if err != nil {
+					t.Fatalf("%s: %v", c.File, err)
+				}
+				if typ != c.Type {
+					t.Fatalf("%s: read as %s, want %s", c.File, typ, c.Type)
+				}
+				if got := interopDecrypt(t, svc, typ, plain); got != c.Value {
+					t.Errorf("%s %s: decrypted %d, want %d", m.Producer, typ, got, c.Value)
+				}
+
+				// Converting back must round-trip.
+				again, err := tfhe.SafeSerialize(typ, plain)
+				if err != nil {
+					t.Fatalf("%s: %v", c.File, err)
+				}
+				if _, plain, err = svc.SafeDeserializeRaw(ctx, again); err != nil {
+					t.Fatalf("%s: %v", c.File, err)
+				}
+				if got := interopDecrypt(t, svc, typ, plain); got != c.Value {
+					t.Errorf("%s %s after round trip: decrypted %d, want %d", m.Producer, typ, got, c.Value)
+				}
+			}
+		})
+	}
+}
+
+func TestInteropWrite(t *testing.T) {
+	if !*interopUpdate {
+		t.Skip("run with -interop.update")
+	}
+	svc := interopService(t)
+	ctx := context.Background()
+	dir := filepath.Join(interopDir, "tfhe-go")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	m := interopManifest{Producer: "tfhe-go " + tfhe.LibraryVersion}
+	values := map[string]uint64{"bool": 1, "uint2": 2, "uint4": 9, "uint8": 77, "uint16": 40000, "uint32": 4000000000, "uint64": 987654321098}
+	for _, typ := range tfhe.SafeTypes {
+		value := values[typ]
+		var plain []byte
+		var err error
+		switch typ {
+		case "bool":
+			plain, err = svc.EncryptBoolRaw(ctx, value == 1)
+		case "uint8":
+			plain, err = svc.EncryptRaw(ctx, uint8(value))
+		default:
+			plain, err = interopInt(t, svc, typ).EncryptRaw(ctx, value)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		safe, err := tfhe.SafeSerialize(typ, plain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := typ + ".bin"
+		if err := os.WriteFile(filepath.Join(dir, file), safe, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		m.Ciphertexts = append(m.Ciphertexts, interopCiphertext{Type: typ, Value: value, File: file})
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "manifest.json"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// interopService loads the fixture keys, skipping the test without them.
+func interopService(t *testing.T) *tfhe.Uint8Service {
+	t.Helper()
+	var keys tfhe.KeyPair
+	var err error
+	keys.Client, err = os.ReadFile(filepath.Join(interopDir, "keys", "client_key.bin"))
+	if errors.Is(err, fs.ErrNotExist) {
+		t.Skip("no interop keys; run scripts/gen-interop-fixtures.sh")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if keys.Server, err = os.ReadFile(filepath.Join(interopDir, "keys", "server_key.bin")); err != nil {
+		t.Fatal(err)
+	}
+	svc, err := tfhe.NewUint8ServiceFromKeys(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = svc.Close() })
+	return svc
+}
+
+func interopInt(t *testing.T, svc *tfhe.Uint8Service, typ string) *tfhe.IntService {
+	t.Helper()
+	bits, err := strconv.Atoi(strings.TrimPrefix(typ, "uint"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := tfhe.NewIntService(svc, bits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func interopDecrypt(t *testing.T, svc *tfhe.Uint8Service, typ string, plain []byte) uint64 {
+	t.Helper()
+	ctx := context.Background()
+	var v uint64
+	var err error
+	switch typ {
+	case "bool":
+		var b bool
+		if b, err = svc.DecryptBoolRaw(ctx, plain); b {
+			v = 1
+		}
+	case "uint8":
+		var u uint8
+		u, err = svc.DecryptRaw(ctx, plain)
+		v = uint64(u)
+	default:
+		v, err = interopInt(t, svc, typ).DecryptRaw(ctx, plain)
+	}
+	if err != nil {
+		t.Fatalf("decrypt %s: %v", typ, err)
+	}
+	return v
+}
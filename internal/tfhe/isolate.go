@@ -0,0 +1,225 @@
+package tfhe
+
+import (
+	"bufio"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync/atomic"
+	"time"
+)
+
+// Bytes from clients reach tfhe-c first in its deserializers, and a
+// malformed or hostile ciphertext or key can abort the process there: an
+// allocation failure, or a panic tfhe-c cannot unwind, ends it before any
+// Go recover runs. Under SetIsolation the native deserializers first decode
+// their input in a worker process, the same executable started with
+// isolatedWorkerEnv set, and only decode it in-process once the worker has
+// survived doing so. A worker that dies or hangs fails that one operation
+// with ErrNativePanic and is replaced by a fresh one on the next call.
+
+// isolatedWorkerEnv marks a process started as an isolated worker.
+const isolatedWorkerEnv = "TFHE_ISOLATED_WORKER"
+
+// isolatedTimeout bounds one check; a worker taking longer is killed.
+var isolatedTimeout = 30 * time.Second
+
+// isolatedChecks are the checks a worker runs, by name: each decodes data
+// as one kind of serialized object and frees it.
+var isolatedChecks = map[string]func(data []byte) error{
+	"boolean":            func(data []byte) error { return closing(DeserializeCiphertext(data)) },
+	TypeBool:             func(data []byte) error { return closing(DeserializeFheBool(data)) },
+	"uint2":              func(data []byte) error { return closing(DeserializeInt(2, data)) },
+	"uint4":              func(data []byte) error { return closing(DeserializeInt(4, data)) },
+	TypeUint8:            func(data []byte) error { return closing(Uint8Deserialize(data)) },
+	"uint16":             func(data []byte) error { return closing(DeserializeInt(16, data)) },
+	"uint32":             func(data []byte) error { return closing(DeserializeInt(32, data)) },
+	"uint64":             func(data []byte) error { return closing(DeserializeInt(64, data)) },
+	"compact_list":       checkCompactList,
+	"compact_public_key": func(data []byte) error { return closing(DeserializeUint8CompactPublicKey(data)) },
+}
+
+func closing[T interface{ Close() error }](v T, err error) error {
+	if err != nil {
+		return err
+	}
+	return v.Close()
+}
+
+type isolatedRequest struct {
+	Check string
+	// Limits are the parent's, which the worker's deserializers apply.
+	Limits Limits
+	Data   []byte
+}
+
+type isolatedResponse struct {
+	Err string
+}
+
+var isolation atomic.Pointer[isolator]
+
+// SetIsolation decodes client bytes in up to n worker processes before the
+// native deserializers decode them in-process; 0 turns it off. The workers
+// run this executable, which must call RunIsolatedWorker first thing in
+// main. It costs a copy of the bytes to a worker and a second decoding, but
+// no key material leaves the process.
+func SetIsolation(n int) error {
+	var next *isolator
+	if n > 0 {
+		exe, err := os.Executable()
+		if err != nil {
+			return fmt.Errorf("isolation: %w", err)
+		}
+		next = &isolator{exe: exe, n: n, workers: make(chan *isolatedWorker, n)}
+		for range n {
+			next.workers <- nil
+		}
+	}
+	if prev := isolation.Swap(next); prev != nil {
+		go prev.close()
+	}
+	return nil
+}
+
+// RunIsolatedWorker serves the checks of the parent process and exits if
+// the process was started as an isolated worker, and returns at once
+// otherwise. Programs using SetIsolation call it before parsing flags or
+// writing to stdout.
+func RunIsolatedWorker() {
+	if os.Getenv(isolatedWorkerEnv) == "" {
+		return
+	}
+	if err := serveIsolated(os.Stdin, os.Stdout); err != nil {
+		log.Fatalf("tfhe: isolated worker: %v", err)
+	}
+	os.Exit(0)
+}
+
+// serveIsolated runs the checks requested on r, answering on w, until r
+// ends.
+func serveIsolated(r io.Reader, w io.Writer) error {
+	dec, enc := gob.NewDecoder(bufio.NewReader(r)), gob.NewEncoder(w)
+	for {
+		var req isolatedRequest
+		if err := dec.Decode(&req); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if req.Limits != CurrentLimits() {
+			SetLimits(req.Limits)
+		}
+		var resp isolatedResponse
+		if check, ok := isolatedChecks[req.Check]; !ok {
+			resp.Err = "unknown check " + req.Check
+		} else if err := check(req.Data); err != nil {
+			resp.Err = err.Error()
+		}
+		if err := enc.Encode(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// isolate runs check on data in an isolated worker under SetIsolation and
+// reports ErrNativePanic if the worker dies or hangs. Data failing the
+// check is not an error here: decoding it in-process fails the same way
+// and reports why.
+func isolate(check string, data []byte) error {
+	iso := isolation.Load()
+	if iso == nil {
+		return nil
+	}
+	return iso.run(check, data)
+}
+
+// isolator hands checks to its workers, starting them as needed.
+type isolator struct {
+	exe string
+	n   int
+	// workers holds the idle workers, nil for one not started.
+	workers chan *isolatedWorker
+}
+
+type isolatedWorker struct {
+	cmd *exec.Cmd
+	in  io.WriteCloser
+	enc *gob.Encoder
+	dec *gob.Decoder
+}
+
+func (iso *isolator) run(check string, data []byte) error {
+	w := <-iso.workers
+	if w == nil {
+		var err error
+		if w, err = iso.start(); err != nil {
+			iso.workers <- nil
+			return fmt.Errorf("%s: start isolated worker: %w", check, err)
+		}
+	}
+	if err := w.do(isolatedRequest{Check: check, Limits: CurrentLimits(), Data: data}); err != nil {
+		iso.workers <- nil
+		nativePanics.Add(1)
+		log.Printf("tfhe: isolated worker died checking %s (%d bytes): %v", check, len(data), err)
+		return &kindError{msg: fmt.Sprintf("%s: isolated worker died: %v", check, err), kind: ErrNativePanic}
+	}
+	iso.workers <- w
+	return nil
+}
+
+func (iso *isolator) start() (*isolatedWorker, error) {
+	cmd := exec.Command(iso.exe)
+	cmd.Env = append(os.Environ(), isolatedWorkerEnv+"=1")
+	cmd.Stderr = os.Stderr
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &isolatedWorker{cmd: cmd, in: in, enc: gob.NewEncoder(in), dec: gob.NewDecoder(bufio.NewReader(out))}, nil
+}
+
+// do runs req, stopping the worker if it fails to answer within
+// isolatedTimeout.
+func (w *isolatedWorker) do(req isolatedRequest) error {
+	t := time.AfterFunc(isolatedTimeout, func() { _ = w.cmd.Process.Kill() })
+	defer t.Stop()
+	var resp isolatedResponse
+	if err := w.enc.Encode(req); err != nil {
+		return w.stop(err)
+	}
+	if err := w.dec.Decode(&resp); err != nil {
+		return w.stop(err)
+	}
+	return nil
+}
+
+// stop ends the worker and returns how it exited, or cause if it exited
+// cleanly.
+func (w *isolatedWorker) stop(cause error) error {
+	_ = w.in.Close()
+	_ = w.cmd.Process.Kill()
+	if err := w.cmd.Wait(); err != nil {
+		return err
+	}
+	return cause
+}
+
+// close stops the workers once they are idle.
+func (iso *isolator) close() {
+	for range iso.n {
+		if w := <-iso.workers; w != nil {
+			_ = w.stop(nil)
+		}
+	}
+}
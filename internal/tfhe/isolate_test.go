@@ -0,0 +1,63 @@
+package tfhe
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	// The checks below stand in for a deserializer aborting or hanging
+	// inside tfhe-c; they are registered before the test binary, started
+	// again as a worker, serves them.
+	isolatedChecks["test.ok"] = func([]byte) error { return nil }
+	isolatedChecks["test.crash"] = func([]byte) error {
+		go func() { panic("abort") }()
+		time.Sleep(time.Hour)
+		return nil
+	}
+	isolatedChecks["test.hang"] = func([]byte) error {
+		time.Sleep(time.Hour)
+		return nil
+	}
+	RunIsolatedWorker()
+	os.Exit(m.Run())
+}
+
+func TestIsolation(t *testing.T) {
+	if err := SetIsolation(1); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetIsolation(0) })
+	timeout := isolatedTimeout
+	isolatedTimeout = time.Second
+	t.Cleanup(func() { isolatedTimeout = timeout })
+
+	for _, tc := range []struct {
+		check string
+		err   error
+	}{
+		{check: "test.ok"},
+		// Bytes failing the check are left for the in-process decoding
+		// to reject.
+		{check: "boolean"},
+		{check: "test.crash", err: ErrNativePanic},
+		// A fresh worker replaces the one that died.
+		{check: "test.ok"},
+		{check: "test.hang", err: ErrNativePanic},
+		{check: "test.ok"},
+	} {
+		before := NativePanics()
+		if err := isolate(tc.check, []byte("not a ciphertext")); !errors.Is(err, tc.err) {
+			t.Fatalf("%s: got %v, want %v", tc.check, err, tc.err)
+		}
+		want := int64(0)
+		if tc.err != nil {
+			want = 1
+		}
+		if got := NativePanics() - before; got != want {
+			t.Errorf("%s: %d native panics counted, want %d", tc.check, got, want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package tfhe
+
+import (
+	"log"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// leakStackDepth bounds the frames recorded per wrapper in leak-detection mode.
+const leakStackDepth = 32
+
+var (
+	leakDetection atomic.Bool
+	leakedObjects atomic.Int64
+)
+
+func init() {
+	if os.Getenv("TFHE_LEAK_DETECT") != "" {
+		leakDetection.Store(true)
+	}
+}
+
+// SetLeakDetection toggles recording of creation stacks for ciphertext
+// wrappers. When enabled, wrappers released by their finalizer instead of an
+// explicit Close are logged together with the stack that created them.
+// It can also be enabled with TFHE_LEAK_DETECT=1 or the tfhe_debug build tag.
+func SetLeakDetection(on bool) {
+	leakDetection.Store(on)
+}
+
+// LeakedObjects returns how many ciphertexts were released by a finalizer
+// rather than Close since the process started.
+func LeakedObjects() int64 {
+	return leakedObjects.Load()
+}
+
+// captureOrigin records the caller's stack when leak detection is enabled.
+func captureOrigin() []uintptr {
+	if !leakDetection.Load() {
+		return nil
+	}
+	pcs := make([]uintptr, leakStackDepth)
+	// Skip runtime.Callers, captureOrigin and the wrapper constructor.
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// reportLeak counts a wrapper reaching its finalizer unclosed and logs where it was created.
+func reportLeak(kind objectKind, origin []uintptr) {
+	leakedObjects.Add(1)
+	if len(origin) == 0 {
+		return
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(origin)
+	for {
+		f, more := frames.Next()
+		b.WriteString("\n\t")
+		b.WriteString(f.Function)
+		b.WriteString("\n\t\t")
+		b.WriteString(f.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(f.Line))
+		if !more {
+			break
+		}
+	}
+	log.Printf("tfhe: %s collected without Close, created at:%s", objectNames[kind], b.String())
+}
@@ -0,0 +1,7 @@
+//go:build tfhe_debug
+
+package tfhe
+
+func init() {
+	leakDetection.Store(true)
+}
@@ -0,0 +1,48 @@
+package tfhe
+
+import "sync/atomic"
+
+// lifecycle is embedded in every wrapper of a native object. Close claims it
+// once, so repeated or concurrent Close calls, including a finalizer racing
+// an explicit Close, release the object exactly once; operations check it
+// and fail with ErrClosed instead of passing a freed pointer to native code.
+// It is a plain word rather than an atomic.Bool so unmarshalInto may still
+// move a whole wrapper by assignment.
+type lifecycle struct {
+	closed uint32
+}
+
+// markClosed reports whether the caller is the one closing the object.
+func (l *lifecycle) markClosed() bool {
+	return atomic.CompareAndSwapUint32(&l.closed, 0, 1)
+}
+
+func (l *lifecycle) isClosed() bool {
+	return atomic.LoadUint32(&l.closed) != 0
+}
+
+// state returns closedErr once the object is closed, nilErr if it holds no
+// native object, and nil if it may be used.
+func (l *lifecycle) state(held bool, nilErr, closedErr error) error {
+	switch {
+	case l.isClosed():
+		return closedErr
+	case !held:
+		return nilErr
+	}
+	return nil
+}
+
+// usable is implemented by the wrappers; it fails for a nil, empty or closed
+// object with the matching typed error.
+type usable interface{ usable() error }
+
+// checkUsable returns the first error among objs' usable checks.
+func checkUsable(objs ...usable) error {
+	for _, o := range objs {
+		if err := o.usable(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
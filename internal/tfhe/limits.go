@@ -0,0 +1,131 @@
+package tfhe
+
+import (
+	"encoding/base64"
+	"fmt"
+	"sync/atomic"
+)
+
+// minSerializedLen is the smallest plausible serialized ciphertext: every
+// format starts with at least one 64-bit length or version prefix.
+const minSerializedLen = 8
+
+// Limits bounds the serialized ciphertext sizes accepted for deserialization.
+type Limits struct {
+	MaxBooleanCiphertext int
+	MaxUint2Ciphertext   int
+	MaxUint4Ciphertext   int
+	MaxUint8Ciphertext   int
+	MaxUint16Ciphertext  int
+	MaxUint32Ciphertext  int
+	MaxUint64Ciphertext  int
+	MaxFheBoolCiphertext int
+}
+
+// DefaultLimits leaves generous headroom over ciphertexts produced with the
+// default parameter sets (a few KiB for boolean, well under 1 MiB for uint8);
+// integer ciphertexts grow linearly with the bit width, and an encrypted
+// comparison result (FheBool) is a single block.
+var DefaultLimits = Limits{
+	MaxBooleanCiphertext: 64 << 10,
+	MaxUint2Ciphertext:   256 << 10,
+	MaxUint4Ciphertext:   512 << 10,
+	MaxUint8Ciphertext:   1 << 20,
+	MaxUint16Ciphertext:  2 << 20,
+	MaxUint32Ciphertext:  4 << 20,
+	MaxUint64Ciphertext:  8 << 20,
+	MaxFheBoolCiphertext: 256 << 10,
+}
+
+var limits atomic.Pointer[Limits]
+
+func init() {
+	l := DefaultLimits
+	limits.Store(&l)
+}
+
+// SetLimits replaces the process-wide deserialization limits.
+// Non-positive fields fall back to DefaultLimits.
+func SetLimits(l Limits) {
+	if l.MaxBooleanCiphertext <= 0 {
+		l.MaxBooleanCiphertext = DefaultLimits.MaxBooleanCiphertext
+	}
+	if l.MaxUint2Ciphertext <= 0 {
+		l.MaxUint2Ciphertext = DefaultLimits.MaxUint2Ciphertext
+	}
+	if l.MaxUint4Ciphertext <= 0 {
+		l.MaxUint4Ciphertext = DefaultLimits.MaxUint4Ciphertext
+	}
+	if l.MaxUint8Ciphertext <= 0 {
+		l.MaxUint8Ciphertext = DefaultLimits.MaxUint8Ciphertext
+	}
+	if l.MaxUint16Ciphertext <= 0 {
+		l.MaxUint16Ciphertext = DefaultLimits.MaxUint16Ciphertext
+	}
+	if l.MaxUint32Ciphertext <= 0 {
+		l.MaxUint32Ciphertext = DefaultLimits.MaxUint32Ciphertext
+	}
+	if l.MaxUint64Ciphertext <= 0 {
+		l.MaxUint64Ciphertext = DefaultLimits.MaxUint64Ciphertext
+	}
+	if l.MaxFheBoolCiphertext <= 0 {
+		l.MaxFheBoolCiphertext = DefaultLimits.MaxFheBoolCiphertext
+	}
+	limits.Store(&l)
+}
+
+// CurrentLimits returns the limits in effect.
+func CurrentLimits() Limits {
+	return *limits.Load()
+}
+
+// MaxCiphertext returns the largest accepted serialized ciphertext of any type.
+func (l Limits) MaxCiphertext() int {
+	return max(l.MaxBooleanCiphertext, l.MaxUint2Ciphertext, l.MaxUint4Ciphertext, l.MaxUint8Ciphertext, l.MaxUint16Ciphertext, l.MaxUint32Ciphertext, l.MaxUint64Ciphertext, l.MaxFheBoolCiphertext)
+}
+
+// MaxIntCiphertext returns the limit for unsigned integer ciphertexts of the given bit width.
+func (l Limits) MaxIntCiphertext(bits int) int {
+	switch bits {
+	case 2:
+		return l.MaxUint2Ciphertext
+	case 4:
+		return l.MaxUint4Ciphertext
+	case 8:
+		return l.MaxUint8Ciphertext
+	case 16:
+		return l.MaxUint16Ciphertext
+	case 32:
+		return l.MaxUint32Ciphertext
+	default:
+		return l.MaxUint64Ciphertext
+	}
+}
+
+// CheckSerialized validates data against maxLen without deserializing it.
+func CheckSerialized(data []byte, maxLen int) error {
+	return checkSerialized(data, maxLen)
+}
+
+// checkSerialized performs cheap structural checks before data reaches the C
+// deserializers, which trust their input.
+func checkSerialized(data []byte, maxLen int) error {
+	switch {
+	case len(data) == 0:
+		return errCiphertextEmpty
+	case len(data) > maxLen:
+		return fmt.Errorf("%w: %d bytes exceeds limit of %d", ErrCiphertextTooLarge, len(data), maxLen)
+	case len(data) < minSerializedLen:
+		return fmt.Errorf("%w: %d bytes is too short", ErrInvalidCiphertext, len(data))
+	}
+	return nil
+}
+
+// checkEncodedLen rejects base64 input whose decoded form would exceed maxLen
+// before spending memory on decoding it.
+func checkEncodedLen(ctBase64 string, maxLen int) error {
+	if n := base64.StdEncoding.DecodedLen(len(ctBase64)); n > maxLen+2 {
+		return fmt.Errorf("%w: ~%d bytes exceeds limit of %d", ErrCiphertextTooLarge, n, maxLen)
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !purego && !tfhe_static
+
+package tfhe
+
+// Link libtfhe dynamically, finding it at run time through an rpath to the
+// source tree. Build with -tags tfhe_static for a self-contained binary.
+
+/*
+#cgo LDFLAGS: -L${SRCDIR}/../../tfhe-c/release -ltfhe -lm -ldl -lpthread -Wl,-rpath,${SRCDIR}/../../tfhe-c/release
+*/
+import "C"
@@ -0,0 +1,13 @@
+//go:build !purego && tfhe_static
+
+package tfhe
+
+// Link the libtfhe archive into the binary so it runs without tfhe-c/release
+// alongside it. See scripts/build-static.sh for a fully static build.
+
+/*
+#cgo LDFLAGS: ${SRCDIR}/../../tfhe-c/release/libtfhe.a -lm
+#cgo linux LDFLAGS: -ldl -lpthread
+#cgo darwin LDFLAGS: -framework Security -framework CoreFoundation
+*/
+import "C"
@@ -0,0 +1,329 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+)
+
+// MaxMachineTable bounds the transition table of a Machine, states times
+// input symbols, since every entry may cost a selection per step.
+const MaxMachineTable = 4096
+
+// Machine is a deterministic finite state machine over uint8 states and
+// input symbols, evaluated on encrypted states and inputs by RunMachine.
+type Machine struct {
+	// Transitions[s][a] is the state after state s reads symbol a. Every
+	// row has one entry per symbol of the input alphabet.
+	Transitions [][]uint8
+	// Outputs, when set, holds one output per state, as in a Moore machine.
+	Outputs []uint8
+	// Initial is the state a run starts from when none is given.
+	Initial uint8
+}
+
+// Validate reports a machine whose table is empty, ragged or too large, or
+// that names a state it does not have.
+func (m Machine) Validate() error {
+	states := len(m.Transitions)
+	if states == 0 || states > 256 {
+		return fmt.Errorf("%w: %d states, want 1 to 256", ErrValueOutOfRange, states)
+	}
+	symbols := len(m.Transitions[0])
+	if symbols == 0 || symbols > 256 {
+		return fmt.Errorf("%w: %d input symbols, want 1 to 256", ErrValueOutOfRange, symbols)
+	}
+	if states*symbols > MaxMachineTable {
+		return fmt.Errorf("%w: %d transitions, limit is %d", ErrValueOutOfRange, states*symbols, MaxMachineTable)
+	}
+	for s, row := range m.Transitions {
+		if len(row) != symbols {
+			return fmt.Errorf("%w: state %d has %d transitions, want %d", ErrValueOutOfRange, s, len(row), symbols)
+		}
+		for a, next := range row {
+			if int(next) >= states {
+				return fmt.Errorf("%w: state %d on symbol %d moves to state %d of %d", ErrValueOutOfRange, s, a, next, states)
+			}
+		}
+	}
+	if m.Outputs != nil && len(m.Outputs) != states {
+		return fmt.Errorf("%w: %d outputs for %d states", ErrValueOutOfRange, len(m.Outputs), states)
+	}
+	if int(m.Initial) >= states {
+		return fmt.Errorf("%w: initial state %d of %d", ErrValueOutOfRange, m.Initial, states)
+	}
+	return nil
+}
+
+// machineRun holds the encrypted constants of one RunMachine call; each
+// value is encrypted once and shared by every selection that needs it. The
+// arena frees them when the run ends.
+type machineRun struct {
+	sk      *Uint8ServerKey
+	pub     *Uint8PublicKey
+	workers int
+	consts  [256]*Uint8Ciphertext
+	arena   Arena
+}
+
+// constant returns the shared encryption of v. It is called before the
+// parallel phases, so it needs no lock.
+func (r *machineRun) constant(v uint8) (*Uint8Ciphertext, error) {
+	if r.consts[v] == nil {
+		ct, err := EncryptUint8Public(r.pub, v)
+		if err != nil {
+			return nil, err
+		}
+		r.consts[v] = ct
+		r.arena.Track(ct)
+	}
+	return r.consts[v], nil
+}
+
+// flags returns Compare(CompareEq, x, k) for k below n-1. The last value
+// needs no flag: it is what a selection falls through to.
+func (r *machineRun) flags(x *Uint8Ciphertext, n int) ([]*FheBool, error) {
+	out := make([]*FheBool, n-1)
+	err := parallel(len(out), r.workers, "uint8.machine", func(k int) (err error) {
+		out[k], err = r.sk.Compare(CompareEq, x, r.consts[k])
+		return err
+	})
+	if err != nil {
+		closeFlags(out)
+		return nil, err
+	}
+	return out, nil
+}
+
+func closeFlags(flags []*FheBool) {
+	for _, f := range flags {
+		if f != nil {
+			_ = f.Close()
+		}
+	}
+}
+
+// choose returns vals[k] for the first set flag k, or the last value when
+// no flag is set, as a chain of selections from the last value back.
+// Neighbouring equal values are the same ciphertext and need no selection.
+// owned reports whether the result is a new ciphertext rather than one of
+// vals.
+func (r *machineRun) choose(flags []*FheBool, vals []*Uint8Ciphertext) (out *Uint8Ciphertext, owned bool, err error) {
+	out = vals[len(vals)-1]
+	for k := len(vals) - 2; k >= 0; k-- {
+		if vals[k] == out {
+			continue
+		}
+		next, err := r.sk.IfThenElse(flags[k], vals[k], out)
+		if owned {
+			_ = out.Close()
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		out, owned = next, true
+	}
+	return out, owned, nil
+}
+
+// step returns the state after state reads input under m; the result is a
+// new ciphertext or one of the constants.
+func (r *machineRun) step(m Machine, state, input *Uint8Ciphertext) (*Uint8Ciphertext, bool, error) {
+	var arena Arena
+	defer arena.Close()
+	inputs, err := r.flags(input, len(m.Transitions[0]))
+	if err != nil {
+		return nil, false, err
+	}
+	TrackAll(&arena, inputs)
+	states, err := r.flags(state, len(m.Transitions))
+	if err != nil {
+		return nil, false, err
+	}
+	TrackAll(&arena, states)
+
+	// One row per state selects its next state by input, then the rows
+	// are selected by state.
+	rows := make([]*Uint8Ciphertext, len(m.Transitions))
+	owned := make([]bool, len(rows))
+	err = parallel(len(rows), r.workers, "uint8.machine", func(s int) (err error) {
+		vals := make([]*Uint8Ciphertext, len(m.Transitions[s]))
+		for a, next := range m.Transitions[s] {
+			vals[a] = r.consts[next]
+		}
+		rows[s], owned[s], err = r.choose(inputs, vals)
+		if owned[s] {
+			arena.Track(rows[s])
+		}
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	next, nextOwned, err := r.choose(states, rows)
+	if err != nil {
+		return nil, false, err
+	}
+	// The result is handed on; it must not be closed with the rows.
+	if arena.Detach(next) {
+		nextOwned = true
+	}
+	return next, nextOwned, nil
+}
+
+// RunMachine advances state through inputs under m and returns the final
+// state and, when m has outputs, the output of that state. Each step costs
+// up to one comparison per state and per symbol, and one selection per
+// transition, with the selections of each step spread across up to workers
+// goroutines. Every transition is evaluated, so the run reveals nothing
+// about the path taken. A state or input beyond the machine's states or
+// symbols acts as the last state or symbol. The constants it needs are
+// encrypted under pub. state and inputs are neither modified nor closed;
+// the results are new ciphertexts the caller must close.
+func (sk *Uint8ServerKey) RunMachine(pub *Uint8PublicKey, m Machine, state *Uint8Ciphertext, inputs []*Uint8Ciphertext, workers int) (next, output *Uint8Ciphertext, err error) {
+	if err := m.Validate(); err != nil {
+		return nil, nil, err
+	}
+	r := &machineRun{sk: sk, pub: pub, workers: workers}
+	defer r.arena.Close()
+	for v := range max(len(m.Transitions), len(m.Transitions[0])) {
+		if _, err := r.constant(uint8(v)); err != nil {
+			return nil, nil, err
+		}
+	}
+	for _, v := range m.Outputs {
+		if _, err := r.constant(v); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	cur, owned := state, false
+	release := func() {
+		if owned {
+			_ = cur.Close()
+		}
+	}
+	for _, in := range inputs {
+		next, nextOwned, err := r.step(m, cur, in)
+		release()
+		if err != nil {
+			return nil, nil, err
+		}
+		cur, owned = next, nextOwned
+	}
+	defer release()
+
+	if next, err = r.own(cur, owned); err != nil {
+		return nil, nil, err
+	}
+	owned = false
+	if m.Outputs == nil {
+		return next, nil, nil
+	}
+	states, err := r.flags(next, len(m.Transitions))
+	if err == nil {
+		defer closeFlags(states)
+		vals := make([]*Uint8Ciphertext, len(m.Outputs))
+		for s, v := range m.Outputs {
+			vals[s] = r.consts[v]
+		}
+		var outOwned bool
+		if output, outOwned, err = r.choose(states, vals); err == nil {
+			output, err = r.own(output, outOwned)
+		}
+	}
+	if err != nil {
+		_ = next.Close()
+		return nil, nil, err
+	}
+	return next, output, nil
+}
+
+// own returns ct if the caller already owns it and a copy otherwise, so a
+// result never aliases a constant or an argument.
+func (r *machineRun) own(ct *Uint8Ciphertext, owned bool) (*Uint8Ciphertext, error) {
+	if owned {
+		return ct, nil
+	}
+	data, err := ct.Uint8Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return Uint8Deserialize(data)
+}
+
+// MachineResult is the outcome of RunMachineRaw: the serialized final state
+// and, for machines with outputs, the serialized output of that state.
+type MachineResult struct {
+	State  []byte
+	Output []byte
+}
+
+// RunMachineRaw advances a serialized uint8 state through serialized uint8
+// inputs with RunMachine. A nil state starts the run from m.Initial,
+// encrypted under the service's public key.
+func (s *Uint8Service) RunMachineRaw(ctx context.Context, m Machine, state []byte, inputs [][]byte, workers int) (MachineResult, error) {
+	state = detach(state)
+	if opBudget.Load() > 0 {
+		owned := make([][]byte, len(inputs))
+		for i, v := range inputs {
+			owned[i] = detach(v)
+		}
+		inputs = owned
+	}
+	return bounded(ctx, "uint8.machine", func(ctx context.Context) (res MachineResult, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint8.machine", nil, &err)
+		defer end()
+		if s.withheld {
+			return MachineResult{}, errClientKeyWithheld
+		}
+
+		var cur *Uint8Ciphertext
+		if state == nil {
+			if err := m.Validate(); err != nil {
+				return MachineResult{}, err
+			}
+			if cur, err = native(ctx, phaseCompute, "uint8.encrypt_public", func() (*Uint8Ciphertext, error) { return EncryptUint8Public(s.public, m.Initial) }); err != nil {
+				return MachineResult{}, err
+			}
+			defer cur.Close()
+		} else {
+			ct, release, err := deserializeOperand(ctx, "uint8", state, Uint8Deserialize)
+			if err != nil {
+				return MachineResult{}, fmt.Errorf("state: %w", err)
+			}
+			defer release()
+			cur = ct
+		}
+		operands := make([]*Uint8Ciphertext, len(inputs))
+		for i, v := range inputs {
+			ct, release, err := deserializeOperand(ctx, "uint8", v, Uint8Deserialize)
+			if err != nil {
+				return MachineResult{}, fmt.Errorf("input %d: %w", i, err)
+			}
+			defer release()
+			operands[i] = ct
+		}
+
+		type run struct{ next, output *Uint8Ciphertext }
+		out, err := nativeFanout(ctx, phaseCompute, "uint8.machine", workers, func(workers int) (run, error) {
+			next, output, err := s.server.RunMachine(s.public, m, cur, operands, workers)
+			return run{next, output}, err
+		})
+		if err != nil {
+			return MachineResult{}, err
+		}
+		defer out.next.Close()
+		if res.State, err = native(ctx, phaseSerialize, "uint8.serialize", out.next.Uint8Serialize); err != nil {
+			return MachineResult{}, err
+		}
+		if out.output != nil {
+			defer out.output.Close()
+			if res.Output, err = native(ctx, phaseSerialize, "uint8.serialize", out.output.Uint8Serialize); err != nil {
+				return MachineResult{}, err
+			}
+		}
+		return res, nil
+	})
+}
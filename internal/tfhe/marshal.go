@@ -0,0 +1,206 @@
+package tfhe
+
+import (
+	"encoding"
+	"encoding/json"
+	"runtime"
+)
+
+// The ciphertext and key types implement encoding.BinaryMarshaler and
+// BinaryUnmarshaler over their serialized form, and json.Marshaler and
+// Unmarshaler as a base64 string, so they work with gob, JSON and other
+// encoders directly.
+//
+// UnmarshalBinary replaces whatever the receiver held, closing it first. The
+// receiver then owns a native object like one returned by the Deserialize
+// functions: Close releases it, and a finalizer does if Close is not called.
+// It must therefore be allocated on its own, such as by new or as a pointer
+// field decoded by encoding/json or gob, not embedded in another struct.
+
+var (
+	_ encoding.BinaryMarshaler   = (*Ciphertext)(nil)
+	_ encoding.BinaryUnmarshaler = (*Ciphertext)(nil)
+	_ json.Marshaler             = (*Ciphertext)(nil)
+	_ json.Unmarshaler           = (*Ciphertext)(nil)
+	_ encoding.BinaryMarshaler   = (*Uint8Ciphertext)(nil)
+	_ encoding.BinaryUnmarshaler = (*Uint8Ciphertext)(nil)
+	_ encoding.BinaryMarshaler   = (*FheBool)(nil)
+	_ encoding.BinaryUnmarshaler = (*FheBool)(nil)
+	_ encoding.BinaryMarshaler   = (*ClientKey)(nil)
+	_ encoding.BinaryUnmarshaler = (*ClientKey)(nil)
+	_ encoding.BinaryMarshaler   = (*ServerKey)(nil)
+	_ encoding.BinaryUnmarshaler = (*ServerKey)(nil)
+	_ encoding.BinaryMarshaler   = (*Uint8ClientKey)(nil)
+	_ encoding.BinaryUnmarshaler = (*Uint8ClientKey)(nil)
+	_ encoding.BinaryMarshaler   = (*Uint8ServerKey)(nil)
+	_ encoding.BinaryUnmarshaler = (*Uint8ServerKey)(nil)
+	_ encoding.BinaryMarshaler   = (*Uint8PublicKey)(nil)
+	_ encoding.BinaryUnmarshaler = (*Uint8PublicKey)(nil)
+)
+
+// closer is a pointer to a native object.
+type closer[T any] interface {
+	*T
+	Close() error
+}
+
+// unmarshalInto deserializes data and moves the result into dst, along with
+// the finalizer that releases it.
+func unmarshalInto[T any, P closer[T]](dst P, data []byte, deserialize func([]byte) (P, error)) error {
+	fresh, err := deserialize(data)
+	if err != nil {
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		_ = fresh.Close()
+		return err
+	}
+	runtime.SetFinalizer(fresh, nil)
+	runtime.SetFinalizer(dst, nil)
+	*dst = *fresh
+	runtime.SetFinalizer(dst, func(p P) { _ = p.Close() })
+	return nil
+}
+
+// marshalJSON encodes serialized data as a base64 JSON string.
+func marshalJSON(data []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+// marshalBase64JSON encodes a ciphertext as a base64 JSON string straight
+// from its serialized form.
+func marshalBase64JSON(appendBase64 func([]byte) ([]byte, error)) ([]byte, error) {
+	b, err := appendBase64([]byte{'"'})
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '"'), nil
+}
+
+// unmarshalJSON decodes a base64 JSON string with unmarshal. JSON null
+// leaves the receiver unchanged, as encoding/json expects.
+func unmarshalJSON(b []byte, unmarshal func([]byte) error) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var data []byte
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	return unmarshal(data)
+}
+
+// MarshalBinary returns the serialized ciphertext.
+func (c *Ciphertext) MarshalBinary() ([]byte, error) { return c.Serialize() }
+
+// UnmarshalBinary deserializes data into c.
+func (c *Ciphertext) UnmarshalBinary(data []byte) error {
+	return unmarshalInto(c, data, DeserializeCiphertext)
+}
+
+// MarshalJSON returns the serialized ciphertext as a base64 string.
+func (c *Ciphertext) MarshalJSON() ([]byte, error) { return marshalBase64JSON(c.AppendBase64) }
+
+// UnmarshalJSON deserializes a base64 string into c.
+func (c *Ciphertext) UnmarshalJSON(b []byte) error { return unmarshalJSON(b, c.UnmarshalBinary) }
+
+// MarshalBinary returns the serialized ciphertext.
+func (c *Uint8Ciphertext) MarshalBinary() ([]byte, error) { return c.Uint8Serialize() }
+
+// UnmarshalBinary deserializes data into c.
+func (c *Uint8Ciphertext) UnmarshalBinary(data []byte) error {
+	return unmarshalInto(c, data, Uint8Deserialize)
+}
+
+// MarshalJSON returns the serialized ciphertext as a base64 string.
+func (c *Uint8Ciphertext) MarshalJSON() ([]byte, error) { return marshalBase64JSON(c.AppendBase64) }
+
+// UnmarshalJSON deserializes a base64 string into c.
+func (c *Uint8Ciphertext) UnmarshalJSON(b []byte) error { return unmarshalJSON(b, c.UnmarshalBinary) }
+
+// MarshalBinary returns the serialized ciphertext.
+func (c *FheBool) MarshalBinary() ([]byte, error) { return c.Serialize() }
+
+// UnmarshalBinary deserializes data into c.
+func (c *FheBool) UnmarshalBinary(data []byte) error {
+	return unmarshalInto(c, data, DeserializeFheBool)
+}
+
+// MarshalJSON returns the serialized ciphertext as a base64 string.
+func (c *FheBool) MarshalJSON() ([]byte, error) { return marshalBase64JSON(c.AppendBase64) }
+
+// UnmarshalJSON deserializes a base64 string into c.
+func (c *FheBool) UnmarshalJSON(b []byte) error { return unmarshalJSON(b, c.UnmarshalBinary) }
+
+// MarshalBinary returns the serialized key.
+func (c *ClientKey) MarshalBinary() ([]byte, error) { return c.Serialize() }
+
+// UnmarshalBinary deserializes data into c.
+func (c *ClientKey) UnmarshalBinary(data []byte) error {
+	return unmarshalInto(c, data, DeserializeClientKey)
+}
+
+// MarshalJSON returns the serialized key as a base64 string.
+func (c *ClientKey) MarshalJSON() ([]byte, error) { return marshalJSON(c.Serialize()) }
+
+// UnmarshalJSON deserializes a base64 string into c.
+func (c *ClientKey) UnmarshalJSON(b []byte) error { return unmarshalJSON(b, c.UnmarshalBinary) }
+
+// MarshalBinary returns the serialized key.
+func (s *ServerKey) MarshalBinary() ([]byte, error) { return s.Serialize() }
+
+// UnmarshalBinary deserializes data into s.
+func (s *ServerKey) UnmarshalBinary(data []byte) error {
+	return unmarshalInto(s, data, DeserializeServerKey)
+}
+
+// MarshalJSON returns the serialized key as a base64 string.
+func (s *ServerKey) MarshalJSON() ([]byte, error) { return marshalJSON(s.Serialize()) }
+
+// UnmarshalJSON deserializes a base64 string into s.
+func (s *ServerKey) UnmarshalJSON(b []byte) error { return unmarshalJSON(b, s.UnmarshalBinary) }
+
+// MarshalBinary returns the serialized key.
+func (c *Uint8ClientKey) MarshalBinary() ([]byte, error) { return c.Serialize() }
+
+// UnmarshalBinary deserializes data into c.
+func (c *Uint8ClientKey) UnmarshalBinary(data []byte) error {
+	return unmarshalInto(c, data, DeserializeUint8ClientKey)
+}
+
+// MarshalJSON returns the serialized key as a base64 string.
+func (c *Uint8ClientKey) MarshalJSON() ([]byte, error) { return marshalJSON(c.Serialize()) }
+
+// UnmarshalJSON deserializes a base64 string into c.
+func (c *Uint8ClientKey) UnmarshalJSON(b []byte) error { return unmarshalJSON(b, c.UnmarshalBinary) }
+
+// MarshalBinary returns the serialized key.
+func (s *Uint8ServerKey) MarshalBinary() ([]byte, error) { return s.Serialize() }
+
+// UnmarshalBinary deserializes data into s.
+func (s *Uint8ServerKey) UnmarshalBinary(data []byte) error {
+	return unmarshalInto(s, data, DeserializeUint8ServerKey)
+}
+
+// MarshalJSON returns the serialized key as a base64 string.
+func (s *Uint8ServerKey) MarshalJSON() ([]byte, error) { return marshalJSON(s.Serialize()) }
+
+// UnmarshalJSON deserializes a base64 string into s.
+func (s *Uint8ServerKey) UnmarshalJSON(b []byte) error { return unmarshalJSON(b, s.UnmarshalBinary) }
+
+// MarshalBinary returns the serialized key.
+func (p *Uint8PublicKey) MarshalBinary() ([]byte, error) { return p.Serialize() }
+
+// UnmarshalBinary deserializes data into p.
+func (p *Uint8PublicKey) UnmarshalBinary(data []byte) error {
+	return unmarshalInto(p, data, DeserializeUint8PublicKey)
+}
+
+// MarshalJSON returns the serialized key as a base64 string.
+func (p *Uint8PublicKey) MarshalJSON() ([]byte, error) { return marshalJSON(p.Serialize()) }
+
+// UnmarshalJSON deserializes a base64 string into p.
+func (p *Uint8PublicKey) UnmarshalJSON(b []byte) error { return unmarshalJSON(b, p.UnmarshalBinary) }
@@ -0,0 +1,149 @@
+package tfhe
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// objectKind identifies a native object type for memory accounting.
+type objectKind int
+
+const (
+	objBooleanCiphertext objectKind = iota
+	objUint8Ciphertext
+	objBooleanClientKey
+	objBooleanServerKey
+	objUint8ClientKey
+	objUint8ServerKey
+	objUint8PublicKey
+	objUint16Ciphertext
+	objUint32Ciphertext
+	objUint64Ciphertext
+	objFheBoolCiphertext
+	objUint8CompactPublicKey
+	objBooleanSwitchKey
+	objUint2Ciphertext
+	objUint4Ciphertext
+	numObjectKinds
+)
+
+var objectNames = [numObjectKinds]string{
+	objBooleanCiphertext:     "boolean_ciphertext",
+	objUint8Ciphertext:       "uint8_ciphertext",
+	objBooleanClientKey:      "boolean_client_key",
+	objBooleanServerKey:      "boolean_server_key",
+	objUint8ClientKey:        "uint8_client_key",
+	objUint8ServerKey:        "uint8_server_key",
+	objUint8PublicKey:        "uint8_public_key",
+	objUint16Ciphertext:      "uint16_ciphertext",
+	objUint32Ciphertext:      "uint32_ciphertext",
+	objUint64Ciphertext:      "uint64_ciphertext",
+	objFheBoolCiphertext:     "fhe_bool_ciphertext",
+	objUint8CompactPublicKey: "uint8_compact_public_key",
+	objBooleanSwitchKey:      "boolean_switch_key",
+	objUint2Ciphertext:       "uint2_ciphertext",
+	objUint4Ciphertext:       "uint4_ciphertext",
+}
+
+// objectSizes are approximate native footprints for the default parameter
+// sets. They are estimates for accounting, not exact allocations.
+var objectSizes = [numObjectKinds]int64{
+	objBooleanCiphertext:     4 << 10,
+	objUint8Ciphertext:       72 << 10,
+	objBooleanClientKey:      16 << 10,
+	objBooleanServerKey:      32 << 20,
+	objUint8ClientKey:        64 << 10,
+	objUint8ServerKey:        128 << 20,
+	objUint8PublicKey:        64 << 20,
+	objUint16Ciphertext:      144 << 10,
+	objUint32Ciphertext:      288 << 10,
+	objUint64Ciphertext:      576 << 10,
+	objFheBoolCiphertext:     18 << 10,
+	objUint8CompactPublicKey: 32 << 10,
+	objBooleanSwitchKey:      40 << 20,
+	objUint2Ciphertext:       18 << 10,
+	objUint4Ciphertext:       36 << 10,
+}
+
+var (
+	liveObjects [numObjectKinds]atomic.Int64
+	liveBytes   atomic.Int64
+	memoryLimit atomic.Int64
+)
+
+// MemoryStats reports live native objects and their estimated footprint.
+type MemoryStats struct {
+	Objects map[string]int64 `json:"objects"`
+	Bytes   int64            `json:"bytes"`
+	Limit   int64            `json:"limit"`
+	Leaked  int64            `json:"leaked"`
+	// HeapBytes is what the C allocator reports in use, measured rather than
+	// estimated, so it includes tfhe-c's scratch memory and anything leaked
+	// inside it. It is zero where the allocator is not queried: the pure-Go
+	// backend and builds not linked against glibc.
+	HeapBytes int64 `json:"heap_bytes,omitempty"`
+}
+
+// MemoryUsage returns a snapshot of live native objects.
+func MemoryUsage() MemoryStats {
+	stats := MemoryStats{
+		Objects: make(map[string]int64, numObjectKinds),
+		Bytes:   liveBytes.Load(),
+		Limit:   memoryLimit.Load(),
+		Leaked:  leakedObjects.Load(),
+	}
+	if n, ok := nativeHeapBytes(); ok {
+		stats.HeapBytes = n
+	}
+	for k := objectKind(0); k < numObjectKinds; k++ {
+		stats.Objects[objectNames[k]] = liveObjects[k].Load()
+	}
+	return stats
+}
+
+// NativeHeap returns the bytes in use on the C heap and whether the
+// allocator reports them; see MemoryStats.HeapBytes.
+func NativeHeap() (int64, bool) {
+	return nativeHeapBytes()
+}
+
+// SetMemoryLimit caps the estimated native memory used by live objects.
+// Creating a ciphertext beyond the cap fails with ErrMemoryLimit; keys are
+// always admitted. Zero or negative disables the cap.
+func SetMemoryLimit(bytes int64) {
+	memoryLimit.Store(max(bytes, 0))
+}
+
+// checkMemory rejects creating another object of kind when it would exceed the limit.
+func checkMemory(kind objectKind) error {
+	limit := memoryLimit.Load()
+	if limit == 0 {
+		return nil
+	}
+	if used := liveBytes.Load(); used+objectSizes[kind] > limit {
+		return fmt.Errorf("%w: %s would exceed %d bytes (in use %d)", ErrMemoryLimit, objectNames[kind], limit, used)
+	}
+	return nil
+}
+
+// checkMemoryN is checkMemory for n objects of kind created together.
+func checkMemoryN(kind objectKind, n int) error {
+	limit := memoryLimit.Load()
+	if limit == 0 {
+		return nil
+	}
+	if used, need := liveBytes.Load(), objectSizes[kind]*int64(n); used+need > limit {
+		return fmt.Errorf("%w: %d %s would exceed %d bytes (in use %d)", ErrMemoryLimit, n, objectNames[kind], limit, used)
+	}
+	return nil
+}
+
+func trackObject(kind objectKind) {
+	liveObjects[kind].Add(1)
+	liveBytes.Add(objectSizes[kind])
+}
+
+func untrackObject(kind objectKind) {
+	liveObjects[kind].Add(-1)
+	liveBytes.Add(-objectSizes[kind])
+}
@@ -0,0 +1,111 @@
+package tfhe
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Metrics receives a measurement for every service operation. Implementations
+// must be safe for concurrent use.
+type Metrics interface {
+	ObserveOp(op string, duration time.Duration, resultBytes int, err error)
+}
+
+type nopMetrics struct{}
+
+func (nopMetrics) ObserveOp(string, time.Duration, int, error) {}
+
+// multiMetrics forwards each measurement to several sinks.
+type multiMetrics []Metrics
+
+func (m multiMetrics) ObserveOp(op string, duration time.Duration, resultBytes int, err error) {
+	for _, sink := range m {
+		sink.ObserveOp(op, duration, resultBytes, err)
+	}
+}
+
+// MultiMetrics returns a Metrics that forwards to every sink in order.
+func MultiMetrics(sinks ...Metrics) Metrics {
+	return multiMetrics(sinks)
+}
+
+// recorderSamples bounds the latency samples kept per operation.
+const recorderSamples = 1024
+
+// OpStats summarizes the measurements recorded for one operation.
+type OpStats struct {
+	Count       uint64        `json:"count"`
+	Errors      uint64        `json:"errors"`
+	ResultBytes uint64        `json:"result_bytes"`
+	P50         time.Duration `json:"p50_ns"`
+	P95         time.Duration `json:"p95_ns"`
+	P99         time.Duration `json:"p99_ns"`
+}
+
+// Recorder is an in-memory Metrics implementation keeping counters and a
+// sliding window of latencies per operation.
+type Recorder struct {
+	mu  sync.Mutex
+	ops map[string]*opRecord
+}
+
+type opRecord struct {
+	count, errors, bytes uint64
+	samples              []time.Duration
+	next                 int
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{ops: make(map[string]*opRecord)}
+}
+
+// ObserveOp records one operation.
+func (r *Recorder) ObserveOp(op string, duration time.Duration, resultBytes int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec, ok := r.ops[op]
+	if !ok {
+		rec = &opRecord{}
+		r.ops[op] = rec
+	}
+	rec.count++
+	rec.bytes += uint64(resultBytes)
+	if err != nil {
+		rec.errors++
+	}
+	if len(rec.samples) < recorderSamples {
+		rec.samples = append(rec.samples, duration)
+		return
+	}
+	rec.samples[rec.next] = duration
+	rec.next = (rec.next + 1) % recorderSamples
+}
+
+// Snapshot returns the current statistics keyed by operation name.
+func (r *Recorder) Snapshot() map[string]OpStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := make(map[string]OpStats, len(r.ops))
+	for op, rec := range r.ops {
+		sorted := append([]time.Duration(nil), rec.samples...)
+		sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+		out[op] = OpStats{
+			Count:       rec.count,
+			Errors:      rec.errors,
+			ResultBytes: rec.bytes,
+			P50:         quantile(sorted, 0.50),
+			P95:         quantile(sorted, 0.95),
+			P99:         quantile(sorted, 0.99),
+		}
+	}
+	return out
+}
+
+func quantile(sorted []time.Duration, q float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(q*float64(len(sorted)-1))]
+}
@@ -0,0 +1,365 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/bits"
+)
+
+const (
+	// modelBits is the width of the features and scores of a LinearModel.
+	modelBits = 32
+	// MaxModelFeatures bounds the weights of a LinearModel.
+	MaxModelFeatures = 256
+	// MaxModelFracBits bounds LinearModel.FracBits, so a product of a
+	// feature and a weight keeps 8 integer bits below the sign.
+	MaxModelFracBits = 11
+	// DefaultSigmoidLevels is the resolution of the approximated sigmoid of
+	// a logistic model that sets none.
+	DefaultSigmoidLevels = 64
+	// ProbabilityScale is the encrypted probability of certainty.
+	ProbabilityScale = 65535
+)
+
+// LinearModel is a linear or logistic regression model with plaintext
+// weights, evaluated on encrypted features by ScoreLinear. Features are
+// uint32 ciphertexts of two's complement fixed-point numbers with FracBits
+// fractional bits; the score is the encrypted int32 bias + Σ weight·feature
+// with 2·FracBits fractional bits. The sum wraps on overflow, so features
+// and weights must keep it within int32.
+type LinearModel struct {
+	Weights  []float64
+	Bias     float64
+	FracBits uint
+	// Logistic adds an encrypted probability, sigmoid(score) scaled to
+	// ProbabilityScale.
+	Logistic bool
+	// SigmoidLevels is how many steps approximate the sigmoid, 2 to 256;
+	// each costs a comparison, a selection and an addition per score. Zero
+	// means DefaultSigmoidLevels.
+	SigmoidLevels int
+}
+
+// Validate reports a model without weights or with too many, or whose
+// weights, bias or sigmoid do not fit the encrypted arithmetic.
+func (m LinearModel) Validate() error {
+	if len(m.Weights) == 0 || len(m.Weights) > MaxModelFeatures {
+		return fmt.Errorf("%w: %d weights, want 1 to %d", ErrValueOutOfRange, len(m.Weights), MaxModelFeatures)
+	}
+	if m.FracBits > MaxModelFracBits {
+		return fmt.Errorf("%w: %d fractional bits, limit is %d", ErrValueOutOfRange, m.FracBits, MaxModelFracBits)
+	}
+	for i, w := range m.Weights {
+		if q := math.Round(w * math.Ldexp(1, int(m.FracBits))); math.IsNaN(q) || q < math.MinInt32 || q > math.MaxInt32 {
+			return fmt.Errorf("%w: weight %d does not fit int32 with %d fractional bits", ErrValueOutOfRange, i, m.FracBits)
+		}
+	}
+	if q := math.Round(m.Bias * math.Ldexp(1, 2*int(m.FracBits))); math.IsNaN(q) || q < math.MinInt32 || q > math.MaxInt32 {
+		return fmt.Errorf("%w: bias does not fit int32 with %d fractional bits", ErrValueOutOfRange, 2*m.FracBits)
+	}
+	if m.Logistic && m.SigmoidLevels != 0 && (m.SigmoidLevels < 2 || m.SigmoidLevels > 256) {
+		return fmt.Errorf("%w: %d sigmoid levels, want 2 to 256", ErrValueOutOfRange, m.SigmoidLevels)
+	}
+	return nil
+}
+
+// quantize returns the fixed-point weights and bias of a valid model.
+func (m LinearModel) quantize() (weights []int64, bias int64) {
+	weights = make([]int64, len(m.Weights))
+	for i, w := range m.Weights {
+		weights[i] = int64(math.Round(w * math.Ldexp(1, int(m.FracBits))))
+	}
+	return weights, int64(math.Round(m.Bias * math.Ldexp(1, 2*int(m.FracBits))))
+}
+
+// sigmoidSteps returns the thresholds and increments of the step function
+// approximating ProbabilityScale·sigmoid on scores with 2·FracBits
+// fractional bits: the probability is the sum of the increments of the
+// thresholds the score reaches. Threshold j is where the sigmoid crosses
+// the middle of level j, so the approximation is off by at most half a
+// level. Thresholds are biased by 2³¹ so signed scores compare as unsigned.
+func (m LinearModel) sigmoidSteps() (thresholds, increments []uint64) {
+	levels := m.SigmoidLevels
+	if levels == 0 {
+		levels = DefaultSigmoidLevels
+	}
+	scale := math.Ldexp(1, 2*int(m.FracBits))
+	level := func(j int) uint64 { return uint64(math.Round(ProbabilityScale * float64(j) / float64(levels))) }
+	for j := range levels {
+		p := (float64(j) + 0.5) / float64(levels)
+		t := math.Ceil(math.Log(p/(1-p)) * scale)
+		t = math.Max(math.MinInt32, math.Min(math.MaxInt32, t))
+		thresholds = append(thresholds, uint64(uint32(int32(t)))^1<<31)
+		increments = append(increments, level(j+1)-level(j))
+	}
+	return thresholds, increments
+}
+
+// modelEval holds the encrypted constants of one ScoreLinear call, which
+// its arena frees when the call returns.
+type modelEval struct {
+	sk      *Uint8ServerKey
+	pub     *Uint8PublicKey
+	arena   *Arena
+	ones    *IntCiphertext // all bits set
+	one     *IntCiphertext
+	zero    *IntCiphertext
+	signBit *IntCiphertext
+}
+
+// constant encrypts v under the public key into the arena.
+func (ev *modelEval) constant(v uint64) (*IntCiphertext, error) {
+	ct, err := EncryptIntPublic(ev.pub, modelBits, v)
+	if err != nil {
+		return nil, err
+	}
+	ev.arena.Track(ct)
+	return ct, nil
+}
+
+// mulConst returns an encryption of x·w modulo 2³², by Horner's rule over
+// the bits of |w| and two's complement negation for a negative w, in at
+// most 2·bits.Len(|w|) additions. owned is false when the result is x
+// itself, for w = 1. A zero w yields nil.
+func (ev *modelEval) mulConst(x *IntCiphertext, w int64) (out *IntCiphertext, owned bool, err error) {
+	m := uint64(w)
+	if w < 0 {
+		m = uint64(-w)
+	}
+	if m == 0 {
+		return nil, false, nil
+	}
+	out = x
+	apply := func(op func(lhs, rhs *IntCiphertext) (*IntCiphertext, error), lhs, rhs *IntCiphertext) error {
+		next, err := op(lhs, rhs)
+		if owned {
+			_ = out.Close()
+		}
+		if err != nil {
+			return err
+		}
+		out, owned = next, true
+		return nil
+	}
+	for i := bits.Len64(m) - 2; i >= 0; i-- {
+		if err := apply(ev.sk.IntAdd, out, out); err != nil {
+			return nil, false, err
+		}
+		if m>>i&1 == 1 {
+			if err := apply(ev.sk.IntAdd, out, x); err != nil {
+				return nil, false, err
+			}
+		}
+	}
+	if w < 0 {
+		// -y = ^y + 1.
+		if err := apply(ev.sk.IntBitXor, out, ev.ones); err != nil {
+			return nil, false, err
+		}
+		if err := apply(ev.sk.IntAdd, out, ev.one); err != nil {
+			return nil, false, err
+		}
+	}
+	return out, owned, nil
+}
+
+// sum adds terms to start, closing the owned terms as it goes, and returns
+// a new ciphertext.
+func (ev *modelEval) sum(start *IntCiphertext, terms []*IntCiphertext, owned []bool) (*IntCiphertext, error) {
+	total, totalOwned := start, false
+	var err error
+	for i, t := range terms {
+		if t == nil {
+			continue
+		}
+		if err == nil {
+			var next *IntCiphertext
+			if next, err = ev.sk.IntAdd(total, t); err == nil {
+				if totalOwned {
+					_ = total.Close()
+				}
+				total, totalOwned = next, true
+			}
+		}
+		if owned[i] {
+			_ = t.Close()
+		}
+	}
+	if err != nil {
+		if totalOwned {
+			_ = total.Close()
+		}
+		return nil, err
+	}
+	if !totalOwned {
+		// Nothing was added: add zero for a result of its own.
+		return ev.sk.IntAdd(total, ev.zero)
+	}
+	return total, nil
+}
+
+// ScoreLinear evaluates m on encrypted uint32 features and returns the
+// encrypted score and, for a logistic model, the encrypted probability.
+// Weights multiply the features by repeated doubling and addition, since
+// the integer keys have no scalar multiplication, with the products spread
+// across up to workers goroutines. The sigmoid is approximated by a step
+// function of m.SigmoidLevels levels, one encrypted comparison per level,
+// standing in for programmable bootstrapping, which the bindings do not
+// expose. Constants are encrypted under pub. features are neither modified
+// nor closed; the results are new ciphertexts the caller must close.
+func (sk *Uint8ServerKey) ScoreLinear(pub *Uint8PublicKey, m LinearModel, features []*IntCiphertext, workers int) (score, probability *IntCiphertext, err error) {
+	if err := m.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if len(features) != len(m.Weights) {
+		return nil, nil, fmt.Errorf("%w: %d features for %d weights", ErrValueOutOfRange, len(features), len(m.Weights))
+	}
+	for i, f := range features {
+		if f.Bits() != modelBits {
+			return nil, nil, fmt.Errorf("%w: feature %d is uint%d, want uint%d", ErrInvalidCiphertext, i, f.Bits(), modelBits)
+		}
+	}
+
+	var arena Arena
+	defer arena.Close()
+	ev := &modelEval{sk: sk, pub: pub, arena: &arena}
+	weights, bias := m.quantize()
+	for _, c := range []struct {
+		dst **IntCiphertext
+		v   uint64
+	}{{&ev.ones, math.MaxUint32}, {&ev.one, 1}, {&ev.zero, 0}, {&ev.signBit, 1 << 31}} {
+		if *c.dst, err = ev.constant(c.v); err != nil {
+			return nil, nil, err
+		}
+	}
+	start, err := ev.constant(uint64(uint32(int32(bias))))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	terms := make([]*IntCiphertext, len(features))
+	owned := make([]bool, len(features))
+	if err := parallel(len(features), workers, "uint32.score", func(i int) (err error) {
+		terms[i], owned[i], err = ev.mulConst(features[i], weights[i])
+		return err
+	}); err != nil {
+		for i, t := range terms {
+			if owned[i] {
+				_ = t.Close()
+			}
+		}
+		return nil, nil, err
+	}
+	if score, err = ev.sum(start, terms, owned); err != nil {
+		return nil, nil, err
+	}
+	if !m.Logistic {
+		return score, nil, nil
+	}
+	if probability, err = ev.sigmoid(m, score, workers); err != nil {
+		_ = score.Close()
+		return nil, nil, err
+	}
+	return score, probability, nil
+}
+
+// sigmoid evaluates the step function of m.sigmoidSteps on score.
+func (ev *modelEval) sigmoid(m LinearModel, score *IntCiphertext, workers int) (*IntCiphertext, error) {
+	thresholds, increments := m.sigmoidSteps()
+	limits := make([]*IntCiphertext, len(thresholds))
+	steps := make([]*IntCiphertext, len(increments))
+	for j := range thresholds {
+		var err error
+		if limits[j], err = ev.constant(thresholds[j]); err != nil {
+			return nil, err
+		}
+		if steps[j], err = ev.constant(increments[j]); err != nil {
+			return nil, err
+		}
+	}
+	biased, err := ev.sk.IntBitXor(score, ev.signBit)
+	if err != nil {
+		return nil, err
+	}
+	ev.arena.Track(biased)
+
+	terms := make([]*IntCiphertext, len(thresholds))
+	owned := make([]bool, len(thresholds))
+	err = parallel(len(thresholds), workers, "uint32.sigmoid", func(j int) error {
+		reached, err := ev.sk.IntCompare(CompareGe, biased, limits[j])
+		if err != nil {
+			return err
+		}
+		defer reached.Close()
+		terms[j], err = ev.sk.IntIfThenElse(reached, steps[j], ev.zero)
+		owned[j] = err == nil
+		return err
+	})
+	if err != nil {
+		for j, t := range terms {
+			if owned[j] {
+				_ = t.Close()
+			}
+		}
+		return nil, err
+	}
+	return ev.sum(ev.zero, terms, owned)
+}
+
+// ScoreResult is the outcome of ScoreLinearRaw: the serialized encrypted
+// score and, for logistic models, the serialized encrypted probability.
+type ScoreResult struct {
+	Score       []byte
+	Probability []byte
+}
+
+// ScoreLinearRaw evaluates m on serialized uint32 features with ScoreLinear.
+func (s *Uint8Service) ScoreLinearRaw(ctx context.Context, m LinearModel, features [][]byte, workers int) (ScoreResult, error) {
+	if opBudget.Load() > 0 {
+		owned := make([][]byte, len(features))
+		for i, v := range features {
+			owned[i] = detach(v)
+		}
+		features = owned
+	}
+	return bounded(ctx, "uint32.score", func(ctx context.Context) (res ScoreResult, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint32.score", nil, &err)
+		defer end()
+		if s.withheld {
+			return ScoreResult{}, errClientKeyWithheld
+		}
+
+		operands := make([]*IntCiphertext, len(features))
+		for i, data := range features {
+			ct, err := native(ctx, phaseDeserialize, "uint32.deserialize", func() (*IntCiphertext, error) { return DeserializeInt(modelBits, data) })
+			if err != nil {
+				return ScoreResult{}, fmt.Errorf("feature %d: %w", i, err)
+			}
+			defer ct.Close()
+			operands[i] = ct
+		}
+
+		type scored struct{ score, probability *IntCiphertext }
+		out, err := nativeFanout(ctx, phaseCompute, "uint32.score", workers, func(workers int) (scored, error) {
+			score, probability, err := s.server.ScoreLinear(s.public, m, operands, workers)
+			return scored{score, probability}, err
+		})
+		if err != nil {
+			return ScoreResult{}, err
+		}
+		defer out.score.Close()
+		if res.Score, err = native(ctx, phaseSerialize, "uint32.serialize", out.score.Serialize); err != nil {
+			return ScoreResult{}, err
+		}
+		if out.probability != nil {
+			defer out.probability.Close()
+			if res.Probability, err = native(ctx, phaseSerialize, "uint32.serialize", out.probability.Serialize); err != nil {
+				return ScoreResult{}, err
+			}
+		}
+		return res, nil
+	})
+}
@@ -0,0 +1,32 @@
+//go:build !purego && linux
+
+package tfhe
+
+/*
+#include <stdint.h>
+#ifdef __GLIBC__
+#include <malloc.h>
+#endif
+
+// heap_in_use returns the bytes the C allocator has handed out and not yet
+// freed, counting chunks served by mmap, or -1 when it cannot tell.
+static int64_t heap_in_use(void) {
+#if defined(__GLIBC__) && (__GLIBC__ > 2 || (__GLIBC__ == 2 && __GLIBC_MINOR__ >= 33))
+	struct mallinfo2 mi = mallinfo2();
+	return (int64_t)(mi.uordblks + mi.hblkhd);
+#elif defined(__GLIBC__)
+	struct mallinfo mi = mallinfo();
+	return (int64_t)(unsigned int)mi.uordblks + (int64_t)(unsigned int)mi.hblkhd;
+#else
+	return -1;
+#endif
+}
+*/
+import "C"
+
+// nativeHeapBytes returns the bytes in use on the C heap, which tfhe-c
+// allocates from, and whether the allocator reports them.
+func nativeHeapBytes() (int64, bool) {
+	n := int64(C.heap_in_use())
+	return n, n >= 0
+}
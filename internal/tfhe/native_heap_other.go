@@ -0,0 +1,7 @@
+//go:build purego || !linux
+
+package tfhe
+
+// nativeHeapBytes reports that the C heap is not measured: the pure-Go
+// backend allocates nothing there, and only glibc's allocator is queried.
+func nativeHeapBytes() (int64, bool) { return 0, false }
@@ -0,0 +1,52 @@
+package tfhe
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// NativeStat accumulates the wall time spent in one kind of tfhe-c call.
+type NativeStat struct {
+	Calls int64 `json:"calls"`
+	Nanos int64 `json:"nanos"`
+}
+
+type nativeCounter struct {
+	calls atomic.Int64
+	nanos atomic.Int64
+}
+
+var (
+	nativeCounters sync.Map // call name -> *nativeCounter
+	opNanos        atomic.Int64
+)
+
+func observeNative(name string, d time.Duration) {
+	c, ok := nativeCounters.Load(name)
+	if !ok {
+		c, _ = nativeCounters.LoadOrStore(name, new(nativeCounter))
+	}
+	counter := c.(*nativeCounter)
+	counter.calls.Add(1)
+	counter.nanos.Add(int64(d))
+}
+
+// NativeStats returns the calls and time spent in each kind of tfhe-c call
+// (e.g. "uint8.add") since the process started.
+func NativeStats() map[string]NativeStat {
+	out := make(map[string]NativeStat)
+	nativeCounters.Range(func(k, v any) bool {
+		c := v.(*nativeCounter)
+		out[k.(string)] = NativeStat{Calls: c.calls.Load(), Nanos: c.nanos.Load()}
+		return true
+	})
+	return out
+}
+
+// OpNanos returns the total wall time of service operations. Compared with
+// the sum of NativeStats it shows how much time is Go overhead (decoding,
+// locking, serialization glue) rather than native FHE work.
+func OpNanos() int64 {
+	return opNanos.Load()
+}
@@ -0,0 +1,184 @@
+package tfhe
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"sync"
+	"sync/atomic"
+)
+
+// The operand cache keeps recently deserialized ciphertexts alive, keyed by
+// the SHA-256 of their serialized form, so an operand reused across the
+// requests of a pipeline is deserialized once. Entries are reference
+// counted: an evicted entry is released only when the last operation using
+// it finishes. Cached objects count towards the native memory limit.
+
+type operandKey struct {
+	typ string
+	sum [sha256.Size]byte
+}
+
+type operandEntry struct {
+	key     operandKey
+	value   interface{ Close() error }
+	refs    int
+	evicted bool
+}
+
+type operandCache struct {
+	mu      sync.Mutex
+	max     int
+	order   *list.List // front is most recently used
+	entries map[operandKey]*list.Element
+	closed  bool // set by purge; later inserts are not kept
+}
+
+var (
+	operands                   atomic.Pointer[operandCache]
+	operandHits, operandMisses atomic.Int64
+)
+
+// SetOperandCache caches up to n deserialized operands; n <= 0 disables the
+// cache, which is the default. Entries of a replaced cache are released
+// once no operation uses them.
+func SetOperandCache(n int) {
+	var c *operandCache
+	if n > 0 {
+		c = &operandCache{max: n, order: list.New(), entries: make(map[operandKey]*list.Element)}
+	}
+	if old := operands.Swap(c); old != nil {
+		old.purge()
+	}
+}
+
+// OperandCacheStats reports the operand cache's size and effectiveness.
+type OperandCacheStats struct {
+	Entries    int   `json:"entries"`
+	MaxEntries int   `json:"max_entries"`
+	Hits       int64 `json:"hits"`
+	Misses     int64 `json:"misses"`
+}
+
+// OperandCacheUsage returns a snapshot of the operand cache.
+func OperandCacheUsage() OperandCacheStats {
+	stats := OperandCacheStats{Hits: operandHits.Load(), Misses: operandMisses.Load()}
+	if c := operands.Load(); c != nil {
+		c.mu.Lock()
+		stats.Entries, stats.MaxEntries = c.order.Len(), c.max
+		c.mu.Unlock()
+	}
+	return stats
+}
+
+// deserializeOperand deserializes data of type typ, through the operand
+// cache when it is enabled. The caller must not close the result; it calls
+// release once done with it instead.
+func deserializeOperand[T interface{ Close() error }](ctx context.Context, typ string, data []byte, deserialize func([]byte) (T, error)) (T, func(), error) {
+	c := operands.Load()
+	if c == nil {
+		v, err := native(ctx, phaseDeserialize, typ+".deserialize", func() (T, error) { return deserialize(data) })
+		if err != nil {
+			return v, nil, err
+		}
+		return v, func() { _ = v.Close() }, nil
+	}
+	key := operandKey{typ: typ, sum: sha256.Sum256(data)}
+	if e := c.acquire(key); e != nil {
+		operandHits.Add(1)
+		return e.value.(T), func() { c.release(e) }, nil
+	}
+	operandMisses.Add(1)
+	v, err := native(ctx, phaseDeserialize, typ+".deserialize", func() (T, error) { return deserialize(data) })
+	if err != nil {
+		return v, nil, err
+	}
+	e := c.insert(key, v)
+	return e.value.(T), func() { c.release(e) }, nil
+}
+
+// acquire returns the entry for key with a reference taken, or nil.
+func (c *operandCache) acquire(key operandKey) *operandEntry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil
+	}
+	c.order.MoveToFront(el)
+	e := el.Value.(*operandEntry)
+	e.refs++
+	return e
+}
+
+// insert adds v under key with a reference taken and evicts the least
+// recently used entries beyond the limit. If another operation cached the
+// same operand meanwhile, v is closed and that entry returned instead.
+func (c *operandCache) insert(key operandKey, v interface{ Close() error }) *operandEntry {
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		e := el.Value.(*operandEntry)
+		e.refs++
+		c.mu.Unlock()
+		_ = v.Close()
+		return e
+	}
+	e := &operandEntry{key: key, value: v, refs: 1}
+	if c.closed {
+		e.evicted = true
+		c.mu.Unlock()
+		return e
+	}
+	c.entries[key] = c.order.PushFront(e)
+	var closing []*operandEntry
+	for c.order.Len() > c.max {
+		if old := c.evict(c.order.Back()); old != nil {
+			closing = append(closing, old)
+		}
+	}
+	c.mu.Unlock()
+	for _, old := range closing {
+		_ = old.value.Close()
+	}
+	return e
+}
+
+// evict removes el and returns its entry if it is unused and can be closed
+// right away. c.mu must be held.
+func (c *operandCache) evict(el *list.Element) *operandEntry {
+	e := c.order.Remove(el).(*operandEntry)
+	delete(c.entries, e.key)
+	e.evicted = true
+	if e.refs == 0 {
+		return e
+	}
+	return nil
+}
+
+// release drops a reference taken by acquire or insert.
+func (c *operandCache) release(e *operandEntry) {
+	c.mu.Lock()
+	e.refs--
+	done := e.evicted && e.refs == 0
+	c.mu.Unlock()
+	if done {
+		_ = e.value.Close()
+	}
+}
+
+// purge evicts every entry and stops keeping new ones.
+func (c *operandCache) purge() {
+	c.mu.Lock()
+	c.closed = true
+	var closing []*operandEntry
+	for c.order.Len() > 0 {
+		if e := c.evict(c.order.Back()); e != nil {
+			closing = append(closing, e)
+		}
+	}
+	c.mu.Unlock()
+	for _, e := range closing {
+		_ = e.value.Close()
+	}
+}
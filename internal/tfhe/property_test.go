@@ -0,0 +1,544 @@
+package tfhe_test
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/bits"
+	"math/rand"
+	"slices"
+	"testing"
+	"testing/quick"
+
+	"tfhe-go/internal/bench"
+	"tfhe-go/internal/tfhe"
+)
+
+// The property tests encrypt random operands, run each homomorphic operation
+// and check the decrypted result against Go arithmetic on the plaintexts,
+// under every parameter set, to catch bindings that swap arguments or call
+// the wrong native function. Operations are slow, so each property checks a
+// few cases by default:
+//
+//	go test ./internal/tfhe -run Property -property.count 50 -property.seed 7
+var (
+	propertyCount = flag.Int("property.count", 4, "random cases per property (1 with -short)")
+	propertySeed  = flag.Int64("property.seed", 0, "seed for the random operands; 0 picks one and logs it")
+)
+
+func quickConfig(t *testing.T) *quick.Config {
+	t.Helper()
+	seed := *propertySeed
+	if seed == 0 {
+		seed = rand.Int63()
+	}
+	t.Logf("seed %d", seed)
+	n := *propertyCount
+	if testing.Short() {
+		n = 1
+	}
+	return &quick.Config{MaxCount: n, Rand: rand.New(rand.NewSource(seed))}
+}
+
+// check runs quick.Check, failing t with the counterexample or with the
+// error a must in property hit.
+func check(t *testing.T, property any) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(mustError); ok {
+				t.Fatal(err.error)
+			}
+			panic(r)
+		}
+	}()
+	if err := quick.Check(property, quickConfig(t)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// mustError carries an operation's error out of a property to check.
+type mustError struct{ error }
+
+// must returns v, or aborts the property under check if err is set.
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(mustError{err})
+	}
+	return v
+}
+
+// comparisons evaluates each Comparison on plaintexts.
+var comparisons = map[tfhe.Comparison]func(a, b uint64) bool{
+	tfhe.CompareEq: func(a, b uint64) bool { return a == b },
+	tfhe.CompareNe: func(a, b uint64) bool { return a != b },
+	tfhe.CompareLt: func(a, b uint64) bool { return a < b },
+	tfhe.CompareLe: func(a, b uint64) bool { return a <= b },
+	tfhe.CompareGt: func(a, b uint64) bool { return a > b },
+	tfhe.CompareGe: func(a, b uint64) bool { return a >= b },
+}
+
+func TestPropertyParameterSets(t *testing.T) {
+	for _, params := range bench.ParameterSets {
+		t.Run(params, func(t *testing.T) {
+			env, err := bench.NewEnv(params)
+			if errors.Is(err, tfhe.ErrUnsupported) {
+				// The pure-Go backend has boolean keys only.
+				ck, sk, err := tfhe.GenerateBooleanKeys()
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer ck.Close()
+				defer sk.Close()
+				testBoolean(t, ck, sk)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer env.Close()
+			testBoolean(t, env.BoolClient, env.BoolServer)
+			testUint8(t, env)
+			for _, bits := range tfhe.IntWidths {
+				t.Run(fmt.Sprintf("uint%d", bits), func(t *testing.T) { testInt(t, env, bits) })
+			}
+		})
+	}
+}
+
+func testBoolean(t *testing.T, ck *tfhe.ClientKey, sk *tfhe.ServerKey) {
+	encrypt := func(v bool) *tfhe.Ciphertext { return must(tfhe.EncryptBool(ck, v)) }
+	decrypt := func(ct *tfhe.Ciphertext) bool {
+		defer ct.Close()
+		return must(tfhe.DecryptBool(ck, ct))
+	}
+	gates := map[string]struct {
+		fn    func(lhs, rhs *tfhe.Ciphertext) (*tfhe.Ciphertext, error)
+		plain func(a, b bool) bool
+	}{
+		"and": {sk.And, func(a, b bool) bool { return a && b }},
+		"or":  {sk.Or, func(a, b bool) bool { return a || b }},
+		"xor": {sk.Xor, func(a, b bool) bool { return a != b }},
+	}
+	for name, g := range gates {
+		t.Run("boolean."+name, func(t *testing.T) {
+			check(t, func(a, b bool) bool {
+				x, y := encrypt(a), encrypt(b)
+				defer x.Close()
+				defer y.Close()
+				return decrypt(must(g.fn(x, y))) == g.plain(a, b)
+			})
+		})
+		t.Run("boolean."+name+"_many", func(t *testing.T) {
+			check(t, func(as, bs [5]bool) bool {
+				pairs := make([]tfhe.Pair, len(as))
+				for i := range pairs {
+					pairs[i] = tfhe.Pair{LHS: encrypt(as[i]), RHS: encrypt(bs[i])}
+					defer pairs[i].LHS.Close()
+					defer pairs[i].RHS.Close()
+				}
+				out := must(sk.GateMany(tfhe.Gate(name), pairs, 2))
+				for i, ct := range out {
+					if decrypt(ct) != g.plain(as[i], bs[i]) {
+						return false
+					}
+				}
+				return true
+			})
+		})
+	}
+	t.Run("boolean.not", func(t *testing.T) {
+		check(t, func(a bool) bool {
+			x := encrypt(a)
+			defer x.Close()
+			return decrypt(must(sk.Not(x))) == !a
+		})
+	})
+	t.Run("boolean.serialize", func(t *testing.T) {
+		check(t, func(a bool) bool {
+			x := encrypt(a)
+			defer x.Close()
+			return decrypt(must(tfhe.DeserializeCiphertext(must(x.Serialize())))) == a
+		})
+	})
+}
+
+func testUint8(t *testing.T, env *bench.Env) {
+	ck, sk := env.Client, env.Server
+	encrypt := func(v uint8) *tfhe.Uint8Ciphertext { return must(tfhe.EncryptUint8(ck, v)) }
+	decrypt := func(ct *tfhe.Uint8Ciphertext) uint8 {
+		defer ct.Close()
+		return must(tfhe.DecryptUint8(ck, ct))
+	}
+	ops := map[string]struct {
+		fn    func(lhs, rhs *tfhe.Uint8Ciphertext) (*tfhe.Uint8Ciphertext, error)
+		plain func(a, b uint8) uint8
+	}{
+		// Addition wraps modulo 256, as Go's uint8 does.
+		"add":    {sk.Add, func(a, b uint8) uint8 { return a + b }},
+		"bitand": {sk.BitAnd, func(a, b uint8) uint8 { return a & b }},
+		"bitxor": {sk.BitXor, func(a, b uint8) uint8 { return a ^ b }},
+	}
+	for name, op := range ops {
+		t.Run("uint8."+name, func(t *testing.T) {
+			check(t, func(a, b uint8) bool {
+				x, y := encrypt(a), encrypt(b)
+				defer x.Close()
+				defer y.Close()
+				return decrypt(must(op.fn(x, y))) == op.plain(a, b)
+			})
+		})
+	}
+	for cmp, plain := range comparisons {
+		t.Run("uint8."+string(cmp), func(t *testing.T) {
+			check(t, func(a, b uint8, same bool) bool {
+				if same {
+					b = a // random pairs are almost never equal
+				}
+				x, y := encrypt(a), encrypt(b)
+				defer x.Close()
+				defer y.Close()
+				res := must(sk.Compare(cmp, x, y))
+				defer res.Close()
+				return must(tfhe.DecryptFheBool(ck, res)) == plain(uint64(a), uint64(b))
+			})
+		})
+	}
+	for cmp, plain := range comparisons {
+		t.Run("uint8.scalar_"+string(cmp), func(t *testing.T) {
+			check(t, func(a, b uint8, same bool) bool {
+				if same {
+					b = a
+				}
+				x := encrypt(a)
+				defer x.Close()
+				res := must(sk.CompareScalar(cmp, x, b))
+				defer res.Close()
+				return must(tfhe.DecryptFheBool(ck, res)) == plain(uint64(a), uint64(b))
+			})
+		})
+	}
+	bitCounts := map[tfhe.BitCount]func(uint8) uint64{
+		tfhe.BitCountOnes:         func(a uint8) uint64 { return uint64(bits.OnesCount8(a)) },
+		tfhe.BitCountLeadingZeros: func(a uint8) uint64 { return uint64(bits.LeadingZeros8(a)) },
+		tfhe.BitCountIlog2:        func(a uint8) uint64 { return uint64(bits.Len8(a) - 1) },
+	}
+	for op, plain := range bitCounts {
+		t.Run("uint8."+string(op), func(t *testing.T) {
+			check(t, func(a uint8) bool {
+				if op == tfhe.BitCountIlog2 && a == 0 {
+					a = 1 // ilog2 is undefined for zero
+				}
+				x := encrypt(a)
+				defer x.Close()
+				res := must(sk.BitCount(op, x))
+				defer res.Close()
+				return must(tfhe.DecryptInt(ck, res)) == plain(a)
+			})
+		})
+	}
+	t.Run("uint8.to_bool", func(t *testing.T) {
+		check(t, func(a uint8, zero bool) bool {
+			if zero {
+				a = 0 // random values are almost never zero
+			}
+			x := encrypt(a)
+			defer x.Close()
+			res := must(sk.Uint8ToBool(x))
+			defer res.Close()
+			return must(tfhe.DecryptFheBool(ck, res)) == (a != 0)
+		})
+	})
+	t.Run("bool.to_uint8", func(t *testing.T) {
+		check(t, func(c bool) bool {
+			b := must(tfhe.EncryptFheBool(ck, c))
+			defer b.Close()
+			want := uint8(0)
+			if c {
+				want = 1
+			}
+			return decrypt(must(sk.BoolToUint8(b))) == want
+		})
+	})
+	t.Run("uint8.bits", func(t *testing.T) {
+		check(t, func(a uint8) bool {
+			x := encrypt(a)
+			defer x.Close()
+			bs := must(sk.Uint8Bits(x))
+			for _, b := range bs {
+				defer b.Close()
+			}
+			for i, b := range bs {
+				if must(tfhe.DecryptFheBool(ck, b)) != (a>>i&1 == 1) {
+					return false
+				}
+			}
+			return decrypt(must(sk.ComposeUint8(bs))) == a
+		})
+	})
+	t.Run("uint8.if_then_else", func(t *testing.T) {
+		check(t, func(c bool, a, b uint8) bool {
+			cond := must(tfhe.EncryptFheBool(ck, c))
+			defer cond.Close()
+			x, y := encrypt(a), encrypt(b)
+			defer x.Close()
+			defer y.Close()
+			want := b
+			if c {
+				want = a
+			}
+			return decrypt(must(sk.IfThenElse(cond, x, y))) == want
+		})
+	})
+	t.Run("uint8.sort", func(t *testing.T) {
+		check(t, func(vs [5]uint8, descending bool) bool {
+			cts := make([]*tfhe.Uint8Ciphertext, len(vs))
+			for i, v := range vs {
+				cts[i] = encrypt(v)
+				defer cts[i].Close()
+			}
+			want := slices.Clone(vs[:])
+			slices.Sort(want)
+			if descending {
+				slices.Reverse(want)
+			}
+			var got []uint8
+			for _, ct := range must(sk.SortEncrypted(cts, descending, 2)) {
+				got = append(got, decrypt(ct))
+			}
+			return slices.Equal(got, want)
+		})
+	})
+	t.Run("uint8.search", func(t *testing.T) {
+		check(t, func(vs [4]uint8, q uint8, pick uint8) bool {
+			vs[pick%4] = q // random values almost never match
+			query := encrypt(q)
+			defer query.Close()
+			cts := make([]*tfhe.Uint8Ciphertext, len(vs))
+			for i, v := range vs {
+				cts[i] = encrypt(v)
+				defer cts[i].Close()
+			}
+			flags := must(sk.MatchEncrypted(query, cts, 2))
+			want := uint64(0)
+			for i, flag := range flags {
+				defer flag.Close()
+				if must(tfhe.DecryptFheBool(ck, flag)) != (vs[i] == q) {
+					return false
+				}
+				if vs[i] == q {
+					want += uint64(i) + 1
+				}
+			}
+			idx := must(sk.IndexEncrypted(env.Public, flags, 2))
+			defer idx.Close()
+			return must(tfhe.DecryptInt(ck, idx)) == want
+		})
+	})
+	t.Run("uint8.tally", func(t *testing.T) {
+		check(t, func(start [3]uint16, choices [3]bool) bool {
+			tallies := make([]*tfhe.IntCiphertext, len(start))
+			ballot := make([]*tfhe.FheBool, len(choices))
+			valid := 0
+			for i := range start {
+				tallies[i] = must(tfhe.EncryptInt(ck, 32, uint64(start[i])))
+				defer tallies[i].Close()
+				ballot[i] = must(tfhe.EncryptFheBool(ck, choices[i]))
+				defer ballot[i].Close()
+				if choices[i] {
+					valid++
+				}
+			}
+			out := must(sk.TallyBallot(env.Public, tallies, ballot, 2))
+			for i, ct := range out {
+				defer ct.Close()
+				want := uint64(start[i])
+				if valid == 1 && choices[i] {
+					want++
+				}
+				if must(tfhe.DecryptInt(ck, ct)) != want {
+					return false
+				}
+			}
+			return true
+		})
+	})
+	t.Run("uint8.machine", func(t *testing.T) {
+		check(t, func(table [3][2]uint8, outputs [3]uint8, start uint8, inputs [3]uint8) bool {
+			m := tfhe.Machine{Outputs: outputs[:]}
+			for _, row := range table {
+				m.Transitions = append(m.Transitions, []uint8{row[0] % 3, row[1] % 3})
+			}
+			start %= 4 // state 3 is out of range and acts as state 2
+			state := encrypt(start)
+			defer state.Close()
+			cts := make([]*tfhe.Uint8Ciphertext, len(inputs))
+			want := min(start, 2)
+			for i, in := range inputs {
+				in %= 3 // symbol 2 is out of range and acts as symbol 1
+				cts[i] = encrypt(in)
+				defer cts[i].Close()
+				want = m.Transitions[want][min(in, 1)]
+			}
+			next, output, err := sk.RunMachine(env.Public, m, state, cts, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			return decrypt(next) == want && decrypt(output) == outputs[want]
+		})
+	})
+	t.Run("uint32.score", func(t *testing.T) {
+		check(t, func(ws, xs [3]int8, bias int16, logistic bool) bool {
+			// Integer weights and features, no fractional bits, keep the
+			// expected score exact.
+			m := tfhe.LinearModel{Bias: float64(bias), Logistic: logistic, SigmoidLevels: 4}
+			want := int32(bias)
+			features := make([]*tfhe.IntCiphertext, len(xs))
+			for i := range xs {
+				m.Weights = append(m.Weights, float64(ws[i]))
+				want += int32(ws[i]) * int32(xs[i])
+				features[i] = must(tfhe.EncryptInt(ck, 32, uint64(uint32(int32(xs[i])))))
+				defer features[i].Close()
+			}
+			score, probability, err := sk.ScoreLinear(env.Public, m, features, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer score.Close()
+			if int32(uint32(must(tfhe.DecryptInt(ck, score)))) != want {
+				return false
+			}
+			if !logistic {
+				return probability == nil
+			}
+			defer probability.Close()
+			// Four levels: the sigmoid crosses 1/8, 3/8, 5/8 and 7/8 at
+			// about -1.95, -0.51, 0.51 and 1.95.
+			level := uint64(0)
+			for _, threshold := range []int32{-1, 0, 1, 2} {
+				if want >= threshold {
+					level++
+				}
+			}
+			return must(tfhe.DecryptInt(ck, probability)) == []uint64{0, 16384, 32768, 49151, 65535}[level]
+		})
+	})
+	t.Run("uint8.encrypt_public", func(t *testing.T) {
+		check(t, func(a uint8) bool {
+			return decrypt(must(tfhe.EncryptUint8Public(env.Public, a))) == a
+		})
+	})
+	t.Run("uint8.serialize", func(t *testing.T) {
+		check(t, func(a uint8) bool {
+			x := encrypt(a)
+			defer x.Close()
+			return decrypt(must(tfhe.Uint8Deserialize(must(x.Uint8Serialize())))) == a
+		})
+	})
+}
+
+func testInt(t *testing.T, env *bench.Env, bits int) {
+	ck, sk := env.Client, env.Server
+	mask := uint64(1)<<bits - 1
+	if bits == 64 {
+		mask = ^uint64(0)
+	}
+	encrypt := func(v uint64) *tfhe.IntCiphertext { return must(tfhe.EncryptInt(ck, bits, v)) }
+	decrypt := func(ct *tfhe.IntCiphertext) uint64 {
+		defer ct.Close()
+		if ct.Bits() != bits {
+			t.Fatalf("result is uint%d, want uint%d", ct.Bits(), bits)
+		}
+		return must(tfhe.DecryptInt(ck, ct))
+	}
+	// operand draws values of the width, favouring the extremes where
+	// overflow and sign mixups show.
+	operand := func(r uint64) uint64 {
+		switch r % 8 {
+		case 0:
+			return 0
+		case 1:
+			return mask
+		}
+		return r & mask
+	}
+	ops := map[string]struct {
+		fn    func(lhs, rhs *tfhe.IntCiphertext) (*tfhe.IntCiphertext, error)
+		plain func(a, b uint64) uint64
+	}{
+		"add":    {sk.IntAdd, func(a, b uint64) uint64 { return (a + b) & mask }},
+		"bitand": {sk.IntBitAnd, func(a, b uint64) uint64 { return a & b }},
+		"bitxor": {sk.IntBitXor, func(a, b uint64) uint64 { return a ^ b }},
+	}
+	for name, op := range ops {
+		t.Run(name, func(t *testing.T) {
+			check(t, func(ra, rb uint64) bool {
+				a, b := operand(ra), operand(rb)
+				x, y := encrypt(a), encrypt(b)
+				defer x.Close()
+				defer y.Close()
+				return decrypt(must(op.fn(x, y))) == op.plain(a, b)
+			})
+		})
+	}
+	for cmp, plain := range comparisons {
+		t.Run(string(cmp), func(t *testing.T) {
+			check(t, func(ra, rb uint64, same bool) bool {
+				a, b := operand(ra), operand(rb)
+				if same {
+					b = a
+				}
+				x, y := encrypt(a), encrypt(b)
+				defer x.Close()
+				defer y.Close()
+				res := must(sk.IntCompare(cmp, x, y))
+				defer res.Close()
+				return must(tfhe.DecryptFheBool(ck, res)) == plain(a, b)
+			})
+		})
+	}
+	for cmp, plain := range comparisons {
+		t.Run("scalar_"+string(cmp), func(t *testing.T) {
+			check(t, func(ra, rb uint64, same bool) bool {
+				a, b := operand(ra), operand(rb)
+				if same {
+					b = a
+				}
+				x := encrypt(a)
+				defer x.Close()
+				res := must(sk.IntCompareScalar(cmp, x, b))
+				defer res.Close()
+				return must(tfhe.DecryptFheBool(ck, res)) == plain(a, b)
+			})
+		})
+	}
+	t.Run("if_then_else", func(t *testing.T) {
+		check(t, func(c bool, ra, rb uint64) bool {
+			a, b := operand(ra), operand(rb)
+			cond := must(tfhe.EncryptFheBool(ck, c))
+			defer cond.Close()
+			x, y := encrypt(a), encrypt(b)
+			defer x.Close()
+			defer y.Close()
+			want := b
+			if c {
+				want = a
+			}
+			return decrypt(must(sk.IntIfThenElse(cond, x, y))) == want
+		})
+	})
+	t.Run("encrypt_public", func(t *testing.T) {
+		check(t, func(ra uint64) bool {
+			a := operand(ra)
+			return decrypt(must(tfhe.EncryptIntPublic(env.Public, bits, a))) == a
+		})
+	})
+	t.Run("serialize", func(t *testing.T) {
+		check(t, func(ra uint64) bool {
+			a := operand(ra)
+			x := encrypt(a)
+			defer x.Close()
+			return decrypt(must(tfhe.DeserializeInt(bits, must(x.Serialize())))) == a
+		})
+	})
+}
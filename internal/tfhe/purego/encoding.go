@@ -0,0 +1,260 @@
+package purego
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Serialized objects start with an 8-byte header: magic, format version,
+// object kind and two reserved bytes. Integers are little-endian.
+const (
+	magic     = "TFPG"
+	headerLen = 8
+
+	kindCiphertext = 1
+	kindSecretKey  = 2
+	kindCloudKey   = 3
+	kindSwitchKey  = 4
+)
+
+// FormatVersion is the version of the serialization format, bumped
+// whenever it changes incompatibly.
+const FormatVersion = 1
+
+// ErrFormat reports data that is not a serialized object of the expected kind.
+var ErrFormat = errors.New("malformed serialized data")
+
+func appendHeader(buf []byte, kind byte) []byte {
+	return append(append(buf, magic...), FormatVersion, kind, 0, 0)
+}
+
+func readHeader(data []byte, kind byte) ([]byte, error) {
+	switch {
+	case len(data) < headerLen || string(data[:4]) != magic:
+		return nil, fmt.Errorf("%w: missing header", ErrFormat)
+	case data[4] != FormatVersion:
+		return nil, fmt.Errorf("%w: unsupported format version %d", ErrFormat, data[4])
+	case data[5] != kind:
+		return nil, fmt.Errorf("%w: object kind %d, want %d", ErrFormat, data[5], kind)
+	}
+	return data[headerLen:], nil
+}
+
+// reader consumes little-endian fields, remembering the first short read.
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || len(r.data) < n {
+		r.err = fmt.Errorf("%w: truncated", ErrFormat)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *reader) u32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.LittleEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) u32s(out []uint32) {
+	b := r.next(4 * len(out))
+	if b == nil {
+		return
+	}
+	for i := range out {
+		out[i] = binary.LittleEndian.Uint32(b[4*i:])
+	}
+}
+
+func (r *reader) done() error {
+	if r.err == nil && len(r.data) != 0 {
+		r.err = fmt.Errorf("%w: %d trailing bytes", ErrFormat, len(r.data))
+	}
+	return r.err
+}
+
+func appendU32s(buf []byte, vs []uint32) []byte {
+	for _, v := range vs {
+		buf = binary.LittleEndian.AppendUint32(buf, v)
+	}
+	return buf
+}
+
+func appendParams(buf []byte, p Params) []byte {
+	for _, v := range []int{p.LWEDimension, p.PolyDegree, p.DecompLevels, p.DecompBaseLog, p.KSLevels, p.KSBaseLog} {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(v))
+	}
+	buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(p.LWEStdDev))
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(p.TLWEStdDev))
+}
+
+func (r *reader) params() (Params, error) {
+	var p Params
+	for _, v := range []*int{&p.LWEDimension, &p.PolyDegree, &p.DecompLevels, &p.DecompBaseLog, &p.KSLevels, &p.KSBaseLog} {
+		*v = int(r.u32())
+	}
+	if b := r.next(16); b != nil {
+		p.LWEStdDev = math.Float64frombits(binary.LittleEndian.Uint64(b))
+		p.TLWEStdDev = math.Float64frombits(binary.LittleEndian.Uint64(b[8:]))
+	}
+	if r.err != nil {
+		return Params{}, r.err
+	}
+	if err := p.Validate(); err != nil {
+		return Params{}, fmt.Errorf("%w: %w", ErrFormat, err)
+	}
+	return p, nil
+}
+
+// MarshalBinary encodes the ciphertext.
+func (c *Ciphertext) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, headerLen+4*(len(c.a)+2))
+	buf = appendHeader(buf, kindCiphertext)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(c.a)))
+	buf = appendU32s(buf, c.a)
+	return binary.LittleEndian.AppendUint32(buf, c.b), nil
+}
+
+// UnmarshalCiphertext decodes a ciphertext encoded by MarshalBinary.
+func UnmarshalCiphertext(data []byte) (*Ciphertext, error) {
+	body, err := readHeader(data, kindCiphertext)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{data: body}
+	n := r.u32()
+	if n == 0 || int(n) > len(body)/4 {
+		return nil, fmt.Errorf("%w: dimension %d", ErrFormat, n)
+	}
+	c := &Ciphertext{a: make([]uint32, n)}
+	r.u32s(c.a)
+	c.b = r.u32()
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// MarshalBinary encodes the secret key.
+func (k *SecretKey) MarshalBinary() ([]byte, error) {
+	buf := appendParams(appendHeader(nil, kindSecretKey), k.params)
+	for _, bits := range [][]uint32{k.lwe, k.tlwe} {
+		for _, b := range bits {
+			buf = append(buf, byte(b))
+		}
+	}
+	return buf, nil
+}
+
+// UnmarshalSecretKey decodes a secret key encoded by MarshalBinary.
+func UnmarshalSecretKey(data []byte) (*SecretKey, error) {
+	body, err := readHeader(data, kindSecretKey)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{data: body}
+	p, err := r.params()
+	if err != nil {
+		return nil, err
+	}
+	k := &SecretKey{params: p, lwe: make([]uint32, p.LWEDimension), tlwe: make([]uint32, p.PolyDegree)}
+	for _, bits := range [][]uint32{k.lwe, k.tlwe} {
+		for i, b := range r.next(len(bits)) {
+			if b > 1 {
+				return nil, fmt.Errorf("%w: key coefficient %d is not a bit", ErrFormat, b)
+			}
+			bits[i] = uint32(b)
+		}
+	}
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// MarshalBinary encodes the cloud key: its seed and the non-uniform halves
+// of its samples.
+func (k *CloudKey) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, headerLen+64+32+4*(len(k.bskB)+len(k.kskB)))
+	buf = appendParams(appendHeader(buf, kindCloudKey), k.params)
+	buf = append(buf, k.seed[:]...)
+	buf = appendU32s(buf, k.bskB)
+	return appendU32s(buf, k.kskB), nil
+}
+
+// UnmarshalCloudKey decodes a cloud key encoded by MarshalBinary and
+// expands it, which takes about as long as generating one.
+func UnmarshalCloudKey(data []byte) (*CloudKey, error) {
+	body, err := readHeader(data, kindCloudKey)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{data: body}
+	p, err := r.params()
+	if err != nil {
+		return nil, err
+	}
+	k := &CloudKey{params: p}
+	copy(k.seed[:], r.next(len(k.seed)))
+	// Check the length before allocating what a corrupt header could make huge.
+	want := 4 * (p.LWEDimension*p.rows()*p.PolyDegree + p.kskLen())
+	if r.err == nil && len(r.data) != want {
+		return nil, fmt.Errorf("%w: %d bytes of key material, want %d", ErrFormat, len(r.data), want)
+	}
+	k.bskB = make([]uint32, p.LWEDimension*p.rows()*p.PolyDegree)
+	k.kskB = make([]uint32, p.kskLen())
+	r.u32s(k.bskB)
+	r.u32s(k.kskB)
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	k.build(nil, nil)
+	return k, nil
+}
+
+// MarshalBinary encodes the switch key: its seed and the non-uniform halves
+// of its samples.
+func (k *SwitchKey) MarshalBinary() ([]byte, error) {
+	buf := make([]byte, 0, headerLen+64+32+4*len(k.b))
+	buf = appendParams(appendHeader(buf, kindSwitchKey), k.params)
+	buf = append(buf, k.seed[:]...)
+	return appendU32s(buf, k.b), nil
+}
+
+// UnmarshalSwitchKey decodes a switch key encoded by MarshalBinary and
+// expands it.
+func UnmarshalSwitchKey(data []byte) (*SwitchKey, error) {
+	body, err := readHeader(data, kindSwitchKey)
+	if err != nil {
+		return nil, err
+	}
+	r := &reader{data: body}
+	p, err := r.params()
+	if err != nil {
+		return nil, err
+	}
+	k := &SwitchKey{params: p}
+	copy(k.seed[:], r.next(len(k.seed)))
+	if want := 4 * p.switchKeyLen(); r.err == nil && len(r.data) != want {
+		return nil, fmt.Errorf("%w: %d bytes of key material, want %d", ErrFormat, len(r.data), want)
+	}
+	k.b = make([]uint32, p.switchKeyLen())
+	r.u32s(k.b)
+	if err := r.done(); err != nil {
+		return nil, err
+	}
+	k.build(nil, nil, nil)
+	return k, nil
+}
@@ -0,0 +1,103 @@
+package purego
+
+import (
+	"math"
+	"math/bits"
+	"math/cmplx"
+)
+
+// fftPlan multiplies polynomials modulo X^N + 1 by evaluating them at the
+// primitive 2N-th roots of unity. A real polynomial's evaluations come in
+// conjugate pairs, so N/2 complex values describe it: folding coefficient j
+// with j+N/2 and twisting by ζ^j, where ζ = e^{iπ/N}, turns evaluation at
+// ζ^{4k+1} into a plain FFT of size N/2.
+type fftPlan struct {
+	n, m    int
+	twist   []complex128 // ζ^j
+	untwist []complex128 // ζ^-j / m
+	roots   []complex128 // e^{2πik/m}, k < m/2
+	rev     []int
+}
+
+func newFFTPlan(n int) *fftPlan {
+	m := n / 2
+	p := &fftPlan{
+		n:       n,
+		m:       m,
+		twist:   make([]complex128, m),
+		untwist: make([]complex128, m),
+		roots:   make([]complex128, m/2),
+		rev:     make([]int, m),
+	}
+	for j := 0; j < m; j++ {
+		z := cmplx.Rect(1, math.Pi*float64(j)/float64(n))
+		p.twist[j] = z
+		p.untwist[j] = cmplx.Conj(z) / complex(float64(m), 0)
+	}
+	for k := range p.roots {
+		p.roots[k] = cmplx.Rect(1, 2*math.Pi*float64(k)/float64(m))
+	}
+	shift := 64 - bits.Len(uint(m-1))
+	for j := range p.rev {
+		p.rev[j] = int(bits.Reverse64(uint64(j)) >> shift)
+	}
+	return p
+}
+
+// transform runs an in-place radix-2 FFT of size m, with e^{+2πi/m} as the
+// root, or its conjugate when inverse is set. The 1/m scale is applied by
+// the caller.
+func (p *fftPlan) transform(a []complex128, inverse bool) {
+	for i, j := range p.rev {
+		if i < j {
+			a[i], a[j] = a[j], a[i]
+		}
+	}
+	for size := 2; size <= p.m; size <<= 1 {
+		half, step := size/2, p.m/size
+		for start := 0; start < p.m; start += size {
+			for k := 0; k < half; k++ {
+				w := p.roots[k*step]
+				if inverse {
+					w = cmplx.Conj(w)
+				}
+				u, v := a[start+k], a[start+k+half]*w
+				a[start+k], a[start+k+half] = u+v, u-v
+			}
+		}
+	}
+}
+
+// forwardInt evaluates a polynomial with small signed coefficients.
+func (p *fftPlan) forwardInt(out []complex128, in []int32) {
+	for j := 0; j < p.m; j++ {
+		out[j] = complex(float64(in[j]), float64(in[j+p.m])) * p.twist[j]
+	}
+	p.transform(out, false)
+}
+
+// forwardTorus evaluates a torus polynomial, reading coefficients as signed.
+func (p *fftPlan) forwardTorus(out []complex128, in []uint32) {
+	for j := 0; j < p.m; j++ {
+		out[j] = complex(float64(int32(in[j])), float64(int32(in[j+p.m]))) * p.twist[j]
+	}
+	p.transform(out, false)
+}
+
+// inverseTorusAdd interpolates in, which it overwrites, and adds the
+// coefficients modulo 2^32 to out.
+func (p *fftPlan) inverseTorusAdd(out []uint32, in []complex128) {
+	p.transform(in, true)
+	for j := 0; j < p.m; j++ {
+		c := in[j] * p.untwist[j]
+		out[j] += uint32(int64(math.Round(real(c))))
+		out[j+p.m] += uint32(int64(math.Round(imag(c))))
+	}
+}
+
+// mulAdd sets acc += a * b pointwise.
+func mulAdd(acc, a, b []complex128) {
+	for i := range acc {
+		acc[i] += a[i] * b[i]
+	}
+}
@@ -0,0 +1,231 @@
+package purego
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Ciphertext is an LWE sample (a, b) whose phase b - <a, s> is ±1/8 plus noise.
+type Ciphertext struct {
+	a []uint32
+	b uint32
+}
+
+var errNilCiphertext = errors.New("ciphertext is nil")
+
+// Encrypt encrypts v under k.
+func (k *SecretKey) Encrypt(v bool) (*Ciphertext, error) {
+	r, err := newRand()
+	if err != nil {
+		return nil, err
+	}
+	ct := &Ciphertext{a: make([]uint32, k.params.LWEDimension)}
+	for i := range ct.a {
+		ct.a[i] = r.Uint32()
+	}
+	m := mu
+	if !v {
+		m = minusMu
+	}
+	ct.b = dot(ct.a, k.lwe) + m + gaussian(r, k.params.LWEStdDev)
+	return ct, nil
+}
+
+// Trivial reports whether ct is a trivial encryption, an all-zero mask
+// that leaves its value readable without the key.
+func (ct *Ciphertext) Trivial() bool {
+	for _, a := range ct.a {
+		if a != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Decrypt returns the boolean ct encrypts under k.
+func (k *SecretKey) Decrypt(ct *Ciphertext) (bool, error) {
+	if err := k.params.check(ct); err != nil {
+		return false, err
+	}
+	return int32(ct.b-dot(ct.a, k.lwe)) > 0, nil
+}
+
+func (p Params) check(cts ...*Ciphertext) error {
+	for _, ct := range cts {
+		if ct == nil {
+			return errNilCiphertext
+		}
+		if len(ct.a) != p.LWEDimension {
+			return fmt.Errorf("%w: ciphertext dimension %d, key dimension %d", ErrParams, len(ct.a), p.LWEDimension)
+		}
+	}
+	return nil
+}
+
+// And returns an encryption of x AND y.
+func (k *CloudKey) And(x, y *Ciphertext) (*Ciphertext, error) {
+	return k.gate(minusMu, 1, x, y)
+}
+
+// Or returns an encryption of x OR y.
+func (k *CloudKey) Or(x, y *Ciphertext) (*Ciphertext, error) {
+	return k.gate(mu, 1, x, y)
+}
+
+// Xor returns an encryption of x XOR y.
+func (k *CloudKey) Xor(x, y *Ciphertext) (*Ciphertext, error) {
+	return k.gate(2*mu, 2, x, y)
+}
+
+// Nand returns an encryption of NOT (x AND y).
+func (k *CloudKey) Nand(x, y *Ciphertext) (*Ciphertext, error) {
+	return k.gate(mu, 1<<32-1, x, y)
+}
+
+// Not returns an encryption of NOT x. It needs no bootstrap.
+func (k *CloudKey) Not(x *Ciphertext) (*Ciphertext, error) {
+	if err := k.params.check(x); err != nil {
+		return nil, err
+	}
+	out := &Ciphertext{a: make([]uint32, len(x.a)), b: -x.b}
+	for i, v := range x.a {
+		out.a[i] = -v
+	}
+	return out, nil
+}
+
+// gate bootstraps (0, offset) + scale·(x + y): the combination lands on the
+// positive half of the torus exactly when the gate's output is true.
+func (k *CloudKey) gate(offset, scale uint32, x, y *Ciphertext) (*Ciphertext, error) {
+	if err := k.params.check(x, y); err != nil {
+		return nil, err
+	}
+	in := &Ciphertext{a: make([]uint32, len(x.a)), b: offset + scale*(x.b+y.b)}
+	for i := range in.a {
+		in.a[i] = scale * (x.a[i] + y.a[i])
+	}
+	return k.bootstrap(in), nil
+}
+
+// bootstrap returns a fresh encryption of ±1/8 under the LWE key, with the
+// sign of in's phase, by rotating a test polynomial by the phase under
+// encryption and extracting its constant coefficient.
+func (k *CloudKey) bootstrap(in *Ciphertext) *Ciphertext {
+	p := k.params
+	bigN, logTwoN := p.PolyDegree, p.logTwoN()
+	accA, accB := make([]uint32, bigN), make([]uint32, bigN)
+	test := make([]uint32, bigN)
+	for j := range test {
+		test[j] = mu
+	}
+	mulByXai(accB, test, (2*bigN-modSwitch(in.b, logTwoN))%(2*bigN))
+
+	ep := newExternalProduct(p)
+	tmpA, tmpB := make([]uint32, bigN), make([]uint32, bigN)
+	for i, ai := range in.a {
+		bar := modSwitch(ai, logTwoN)
+		if bar == 0 {
+			continue
+		}
+		// CMux: acc += BSK_i ⊡ ((X^bar - 1)·acc), i.e. rotate by bar when s_i = 1.
+		mulByXaiMinusOne(tmpA, accA, bar)
+		mulByXaiMinusOne(tmpB, accB, bar)
+		ep.add(k, i, accA, accB, tmpA, tmpB)
+	}
+
+	// The constant coefficient of acc is an LWE sample under the polynomial
+	// key's coefficients.
+	ext := make([]uint32, bigN)
+	ext[0] = accA[0]
+	for j := 1; j < bigN; j++ {
+		ext[j] = -accA[bigN-j]
+	}
+	return k.keySwitch(ext, accB[0])
+}
+
+// externalProduct holds the scratch space of one bootstrap.
+type externalProduct struct {
+	offset     uint32
+	digits     []int32
+	dec        [][]complex128
+	outA, outB []complex128
+}
+
+func newExternalProduct(p Params) *externalProduct {
+	half := p.PolyDegree / 2
+	ep := &externalProduct{
+		digits: make([]int32, p.PolyDegree),
+		dec:    make([][]complex128, p.rows()),
+		outA:   make([]complex128, half),
+		outB:   make([]complex128, half),
+	}
+	for r := range ep.dec {
+		ep.dec[r] = make([]complex128, half)
+	}
+	// Centre every digit on zero and round at the last one.
+	halfBg := uint32(1) << (p.DecompBaseLog - 1)
+	for q := 0; q < p.DecompLevels; q++ {
+		ep.offset += halfBg << (32 - (q+1)*p.DecompBaseLog)
+	}
+	if used := p.DecompLevels * p.DecompBaseLog; used < 32 {
+		ep.offset += 1 << (32 - used - 1)
+	}
+	return ep
+}
+
+// add sets acc += BSK_i ⊡ (a, b), decomposing a and b into signed digits
+// and multiplying them with the TGSW rows in the evaluation domain.
+func (ep *externalProduct) add(k *CloudKey, i int, accA, accB, a, b []uint32) {
+	p := k.params
+	l, bgbit := p.DecompLevels, p.DecompBaseLog
+	halfBg, mask := int32(1)<<(bgbit-1), uint32(1)<<bgbit-1
+	for poly, src := range [2][]uint32{a, b} {
+		for q := 0; q < l; q++ {
+			shift := 32 - (q+1)*bgbit
+			for j, v := range src {
+				ep.digits[j] = int32(((v+ep.offset)>>shift)&mask) - halfBg
+			}
+			k.plan.forwardInt(ep.dec[poly*l+q], ep.digits)
+		}
+	}
+	clear(ep.outA)
+	clear(ep.outB)
+	rows := p.rows()
+	for r, d := range ep.dec {
+		mulAdd(ep.outA, d, k.bsk[(i*rows+r)*2])
+		mulAdd(ep.outB, d, k.bsk[(i*rows+r)*2+1])
+	}
+	k.plan.inverseTorusAdd(accA, ep.outA)
+	k.plan.inverseTorusAdd(accB, ep.outB)
+}
+
+// keySwitch turns an LWE sample (a, b) under the polynomial key's
+// coefficients into one under the LWE key.
+func (k *CloudKey) keySwitch(a []uint32, b uint32) *Ciphertext {
+	return keySwitch(k.params, k.kskA, k.kskB, a, b)
+}
+
+// keySwitch turns an LWE sample (a, b) into one under the LWE key the
+// samples (ka, kb) encrypt the digits of a's key under, n = LWEDimension
+// entries of ka per entry of kb.
+func keySwitch(p Params, ka, kb []uint32, a []uint32, b uint32) *Ciphertext {
+	n, t, baseLog := p.LWEDimension, p.KSLevels, p.KSBaseLog
+	base := p.ksBase()
+	round := uint32(1) << (32 - (1 + baseLog*t))
+	out := &Ciphertext{a: make([]uint32, n), b: b}
+	for i, ai := range a {
+		abar := ai + round
+		for j := 0; j < t; j++ {
+			v := int(abar>>(32-(j+1)*baseLog)) & (base - 1)
+			if v == 0 {
+				continue
+			}
+			idx := (i*t+j)*(base-1) + v - 1
+			for q, x := range ka[idx*n : (idx+1)*n] {
+				out.a[q] -= x
+			}
+			out.b -= kb[idx]
+		}
+	}
+	return out
+}
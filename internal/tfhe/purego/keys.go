@@ -0,0 +1,160 @@
+package purego
+
+import (
+	"math/bits"
+	"math/rand/v2"
+)
+
+// SecretKey decrypts: the binary LWE key ciphertexts are encrypted under,
+// and the binary polynomial key the bootstrapping key is encrypted under.
+type SecretKey struct {
+	params Params
+	lwe    []uint32 // n bits
+	tlwe   []uint32 // N bits
+}
+
+// CloudKey evaluates gates: a bootstrapping key, encrypting each LWE key bit
+// as a TGSW sample under the polynomial key, and a key switching key from
+// the polynomial key's coefficients back to the LWE key.
+//
+// The uniform halves of all samples are expanded from seed, so only the
+// other halves are serialized.
+type CloudKey struct {
+	params Params
+	plan   *fftPlan
+	seed   [32]byte
+
+	bskB []uint32       // n × rows × N, torus domain
+	bsk  [][]complex128 // (i·rows + r)·2 + {0: a, 1: b}, evaluation domain
+	kskB []uint32       // N × KSLevels × (base-1)
+	kskA []uint32       // the a vectors of kskB, n each
+}
+
+// Params returns the parameters the key was generated with.
+func (k *SecretKey) Params() Params { return k.params }
+
+// Params returns the parameters the key was generated with.
+func (k *CloudKey) Params() Params { return k.params }
+
+func (p Params) rows() int   { return 2 * p.DecompLevels }
+func (p Params) ksBase() int { return 1 << p.KSBaseLog }
+func (p Params) kskLen() int { return p.PolyDegree * p.KSLevels * (p.ksBase() - 1) }
+func (p Params) logTwoN() uint {
+	return uint(bits.Len(uint(p.PolyDegree)))
+}
+
+// GenerateKeys creates a secret key and the matching cloud key.
+func GenerateKeys(p Params) (*SecretKey, *CloudKey, error) {
+	if err := p.Validate(); err != nil {
+		return nil, nil, err
+	}
+	r, err := newRand()
+	if err != nil {
+		return nil, nil, err
+	}
+	sk := &SecretKey{
+		params: p,
+		lwe:    make([]uint32, p.LWEDimension),
+		tlwe:   make([]uint32, p.PolyDegree),
+	}
+	for i := range sk.lwe {
+		sk.lwe[i] = r.Uint32() & 1
+	}
+	for i := range sk.tlwe {
+		sk.tlwe[i] = r.Uint32() & 1
+	}
+
+	ck := &CloudKey{params: p}
+	for i := range ck.seed {
+		ck.seed[i] = byte(r.Uint32())
+	}
+	ck.bskB = make([]uint32, p.LWEDimension*p.rows()*p.PolyDegree)
+	ck.kskB = make([]uint32, p.kskLen())
+	ck.build(sk, r)
+	return sk, ck, nil
+}
+
+// build expands the seed into the uniform halves of the key samples and
+// precomputes the bootstrapping key's evaluations. With sk set, it first
+// fills bskB and kskB, drawing noise from noise.
+func (k *CloudKey) build(sk *SecretKey, noise *rand.Rand) {
+	p := k.params
+	n, bigN, rows, l := p.LWEDimension, p.PolyDegree, p.rows(), p.DecompLevels
+	k.plan = newFFTPlan(bigN)
+	uniform := rand.New(rand.NewChaCha8(k.seed))
+
+	var keyFFT []complex128
+	if sk != nil {
+		keyFFT = make([]complex128, bigN/2)
+		s := make([]int32, bigN)
+		for j, bit := range sk.tlwe {
+			s[j] = int32(bit)
+		}
+		k.plan.forwardInt(keyFFT, s)
+	}
+	k.bsk = make([][]complex128, n*rows*2)
+	a := make([]uint32, bigN)
+	prod := make([]complex128, bigN/2)
+	for i := 0; i < n; i++ {
+		for r := 0; r < rows; r++ {
+			for j := range a {
+				a[j] = uniform.Uint32()
+			}
+			b := k.bskB[(i*rows+r)*bigN:][:bigN]
+			if sk != nil {
+				// b = a·s + e + m·h, or - m·h·s for the rows decomposing the
+				// mask, so that the sample's phase b - a·s is m·h times 1 or
+				// -s. This is the usual TGSW sample with its mask shifted,
+				// which keeps the mask uniform and expandable from the seed.
+				for j := range b {
+					b[j] = gaussian(noise, p.TLWEStdDev)
+				}
+				k.plan.forwardTorus(prod, a)
+				for j := range prod {
+					prod[j] *= keyFFT[j]
+				}
+				k.plan.inverseTorusAdd(b, prod)
+				if m := sk.lwe[i]; m != 0 {
+					h := uint32(1) << (32 - (r%l+1)*p.DecompBaseLog)
+					if r < l {
+						for j, bit := range sk.tlwe {
+							b[j] -= h * bit
+						}
+					} else {
+						b[0] += h
+					}
+				}
+			}
+			fa, fb := make([]complex128, bigN/2), make([]complex128, bigN/2)
+			k.plan.forwardTorus(fa, a)
+			k.plan.forwardTorus(fb, b)
+			k.bsk[(i*rows+r)*2], k.bsk[(i*rows+r)*2+1] = fa, fb
+		}
+	}
+
+	k.kskA = make([]uint32, len(k.kskB)*n)
+	base := p.ksBase()
+	for i := 0; i < bigN; i++ {
+		for j := 0; j < p.KSLevels; j++ {
+			for v := 1; v < base; v++ {
+				idx := (i*p.KSLevels+j)*(base-1) + v - 1
+				ka := k.kskA[idx*n:][:n]
+				for t := range ka {
+					ka[t] = uniform.Uint32()
+				}
+				if sk != nil {
+					msg := sk.tlwe[i] * uint32(v) << (32 - (j+1)*p.KSBaseLog)
+					k.kskB[idx] = dot(ka, sk.lwe) + msg + gaussian(noise, p.LWEStdDev)
+				}
+			}
+		}
+	}
+}
+
+func dot(a, s []uint32) uint32 {
+	var sum uint32
+	for i, v := range a {
+		sum += v * s[i]
+	}
+	return sum
+}
@@ -0,0 +1,73 @@
+// Package purego is a cgo-free implementation of TFHE gate bootstrapping
+// for boolean ciphertexts. It is much slower than tfhe-c and supports only
+// the boolean gates, but needs no native library.
+//
+// Values live on the torus T = R/Z, represented as uint32 with wrapping
+// arithmetic. A boolean is an LWE sample encrypting ±1/8; every binary gate
+// is a linear combination of its inputs followed by a bootstrap, which
+// resets the noise, and a key switch back to the input key.
+package purego
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Params selects the security and noise parameters.
+type Params struct {
+	// LWEDimension (n) is the size of the key ciphertexts are encrypted under.
+	LWEDimension int
+	// PolyDegree (N) is the ring dimension of the bootstrapping key; a power of two.
+	PolyDegree int
+	// DecompLevels and DecompBaseLog decompose the accumulator in external
+	// products: l digits of DecompBaseLog bits each.
+	DecompLevels  int
+	DecompBaseLog int
+	// KSLevels and KSBaseLog decompose coefficients during key switching.
+	KSLevels  int
+	KSBaseLog int
+	// LWEStdDev and TLWEStdDev are the noise standard deviations, as
+	// fractions of the torus, of fresh LWE samples and of the bootstrapping key.
+	LWEStdDev  float64
+	TLWEStdDev float64
+}
+
+// DefaultParams are the 128-bit gate bootstrapping parameters of the
+// reference TFHE library.
+var DefaultParams = Params{
+	LWEDimension:  630,
+	PolyDegree:    1024,
+	DecompLevels:  3,
+	DecompBaseLog: 7,
+	KSLevels:      8,
+	KSBaseLog:     2,
+	LWEStdDev:     math.Exp2(-15),
+	TLWEStdDev:    math.Exp2(-25),
+}
+
+// ErrParams reports keys or ciphertexts made with incompatible parameters.
+var ErrParams = errors.New("parameters do not match")
+
+// Validate checks that p describes a usable parameter set.
+func (p Params) Validate() error {
+	switch {
+	case p.LWEDimension <= 0 || p.LWEDimension > 1<<16:
+		return fmt.Errorf("lwe dimension %d out of range", p.LWEDimension)
+	case p.PolyDegree < 8 || p.PolyDegree > 1<<16 || p.PolyDegree&(p.PolyDegree-1) != 0:
+		return fmt.Errorf("polynomial degree %d is not a power of two in [8, 65536]", p.PolyDegree)
+	case p.DecompLevels <= 0 || p.DecompBaseLog <= 0 || p.DecompLevels*p.DecompBaseLog > 32:
+		return fmt.Errorf("decomposition %d x %d bits exceeds 32 bits", p.DecompLevels, p.DecompBaseLog)
+	case p.KSLevels <= 0 || p.KSBaseLog <= 0 || p.KSLevels*p.KSBaseLog >= 32:
+		return fmt.Errorf("key switching decomposition %d x %d bits exceeds 31 bits", p.KSLevels, p.KSBaseLog)
+	case !(p.LWEStdDev > 0 && p.LWEStdDev < 1) || !(p.TLWEStdDev > 0 && p.TLWEStdDev < 1):
+		return errors.New("noise standard deviations must be in (0, 1)")
+	}
+	return nil
+}
+
+// mu is the torus encoding of true, 1/8; false is -mu.
+const (
+	mu      = uint32(1 << 29)
+	minusMu = uint32(1<<32 - 1<<29)
+)
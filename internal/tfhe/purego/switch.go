@@ -0,0 +1,78 @@
+package purego
+
+import (
+	"fmt"
+	"math/rand/v2"
+)
+
+// SwitchKey re-encrypts ciphertexts from one secret key to another without
+// decrypting them. Like the key switching key inside a CloudKey, it holds,
+// for every bit of the source LWE key and every digit of the decomposition,
+// an LWE sample under the target key encrypting the bit times the digit.
+// Generating one takes both secret keys; holding one reveals neither.
+//
+// The uniform halves of the samples are expanded from seed, so only the
+// other halves are serialized.
+type SwitchKey struct {
+	params Params
+	seed   [32]byte
+	b      []uint32 // n × KSLevels × (base-1)
+	a      []uint32 // the a vectors of b, n each
+}
+
+func (p Params) switchKeyLen() int { return p.LWEDimension * p.KSLevels * (p.ksBase() - 1) }
+
+// Params returns the parameters of the keys the switch key connects.
+func (k *SwitchKey) Params() Params { return k.params }
+
+// GenerateSwitchKey creates a key switching ciphertexts encrypted under from
+// into ciphertexts encrypted under to. Both keys must share parameters.
+func GenerateSwitchKey(from, to *SecretKey) (*SwitchKey, error) {
+	if from.params != to.params {
+		return nil, fmt.Errorf("%w: switch key between different parameter sets", ErrParams)
+	}
+	r, err := newRand()
+	if err != nil {
+		return nil, err
+	}
+	k := &SwitchKey{params: from.params, b: make([]uint32, from.params.switchKeyLen())}
+	for i := range k.seed {
+		k.seed[i] = byte(r.Uint32())
+	}
+	k.build(from, to, r)
+	return k, nil
+}
+
+// build expands the seed into the uniform halves of the samples. With from
+// and to set, it first fills b, drawing noise from noise.
+func (k *SwitchKey) build(from, to *SecretKey, noise *rand.Rand) {
+	p := k.params
+	n, base := p.LWEDimension, p.ksBase()
+	uniform := rand.New(rand.NewChaCha8(k.seed))
+	k.a = make([]uint32, len(k.b)*n)
+	for i := 0; i < n; i++ {
+		for j := 0; j < p.KSLevels; j++ {
+			for v := 1; v < base; v++ {
+				idx := (i*p.KSLevels+j)*(base-1) + v - 1
+				ka := k.a[idx*n:][:n]
+				for t := range ka {
+					ka[t] = uniform.Uint32()
+				}
+				if from != nil {
+					msg := from.lwe[i] * uint32(v) << (32 - (j+1)*p.KSBaseLog)
+					k.b[idx] = dot(ka, to.lwe) + msg + gaussian(noise, p.LWEStdDev)
+				}
+			}
+		}
+	}
+}
+
+// Switch returns ct re-encrypted under the switch key's target key. The
+// result carries the noise of ct plus that of one key switch, about what
+// the key switch ending every gate adds.
+func (k *SwitchKey) Switch(ct *Ciphertext) (*Ciphertext, error) {
+	if err := k.params.check(ct); err != nil {
+		return nil, err
+	}
+	return keySwitch(k.params, k.a, k.b, ct.a, ct.b), nil
+}
@@ -0,0 +1,56 @@
+package purego
+
+import (
+	crand "crypto/rand"
+	"math"
+	"math/rand/v2"
+)
+
+// newRand returns a cryptographically secure generator seeded from the OS.
+func newRand() (*rand.Rand, error) {
+	var seed [32]byte
+	if _, err := crand.Read(seed[:]); err != nil {
+		return nil, err
+	}
+	return rand.New(rand.NewChaCha8(seed)), nil
+}
+
+// gaussian samples torus noise with the given standard deviation.
+func gaussian(r *rand.Rand, stddev float64) uint32 {
+	return uint32(int64(math.Round(r.NormFloat64() * stddev * (1 << 32))))
+}
+
+// modSwitch rounds a torus value to the nearest multiple of 1/(2N) and
+// returns it as an exponent in [0, 2N).
+func modSwitch(x uint32, logTwoN uint) int {
+	return int((x + 1<<(31-logTwoN)) >> (32 - logTwoN))
+}
+
+// mulByXai sets out = X^a * in modulo X^N + 1, for a in [0, 2N).
+func mulByXai(out, in []uint32, a int) {
+	n := len(in)
+	if a < n {
+		for i := 0; i < a; i++ {
+			out[i] = -in[i-a+n]
+		}
+		for i := a; i < n; i++ {
+			out[i] = in[i-a]
+		}
+		return
+	}
+	a -= n
+	for i := 0; i < a; i++ {
+		out[i] = in[i-a+n]
+	}
+	for i := a; i < n; i++ {
+		out[i] = -in[i-a]
+	}
+}
+
+// mulByXaiMinusOne sets out = (X^a - 1) * in modulo X^N + 1.
+func mulByXaiMinusOne(out, in []uint32, a int) {
+	mulByXai(out, in, a)
+	for i := range out {
+		out[i] -= in[i]
+	}
+}
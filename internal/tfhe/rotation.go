@@ -0,0 +1,112 @@
+package tfhe
+
+// KeySwitchFunc re-encrypts a serialized ciphertext from the current key to a new one.
+//
+// The C API does not expose key-switching keys for these ciphertext types, so
+// switching decrypts under the old client key and re-encrypts under the new
+// one; the plaintext never leaves the process.
+type KeySwitchFunc func(data []byte) ([]byte, error)
+
+// Rotate generates a fresh keypair and calls migrate with a switch function
+// from the current key to the new one. Operations are paused while migrate
+// runs. If migrate succeeds the new keys replace the old ones, otherwise the
+// new keys are discarded and the service keeps its current keys.
+func (s *BooleanService) Rotate(migrate func(KeySwitchFunc) error) error {
+	if s.withheld {
+		return errClientKeyWithheld
+	}
+	ck, sk, err := GenerateBooleanKeys()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldClient := s.client
+	sw := func(data []byte) ([]byte, error) {
+		ct, err := DeserializeCiphertext(data)
+		if err != nil {
+			return nil, err
+		}
+		defer ct.Close()
+		value, err := DecryptBool(oldClient, ct)
+		if err != nil {
+			return nil, err
+		}
+		out, err := EncryptBool(ck, value)
+		if err != nil {
+			return nil, err
+		}
+		defer out.Close()
+		return out.Serialize()
+	}
+	if err := migrate(sw); err != nil {
+		_ = ck.Close()
+		_ = sk.Close()
+		return err
+	}
+
+	oldServer := s.server
+	s.client, s.server = ck, sk
+	s.dropSwitchKeys()
+	_ = oldClient.Close()
+	_ = oldServer.Close()
+	return nil
+}
+
+// Rotate generates fresh client/server/public keys and calls migrate with a
+// switch function from the current key to the new one, with the same
+// semantics as BooleanService.Rotate.
+func (s *Uint8Service) Rotate(migrate func(KeySwitchFunc) error) error {
+	if s.withheld {
+		return errClientKeyWithheld
+	}
+	ck, sk, err := generateUint8Keys()
+	if err != nil {
+		return err
+	}
+	pk, err := NewUint8PublicKey(ck)
+	if err != nil {
+		_ = ck.Close()
+		_ = sk.Close()
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	oldClient := s.client
+	sw := func(data []byte) ([]byte, error) {
+		ct, err := Uint8Deserialize(data)
+		if err != nil {
+			return nil, err
+		}
+		defer ct.Close()
+		value, err := DecryptUint8(oldClient, ct)
+		if err != nil {
+			return nil, err
+		}
+		out, err := EncryptUint8(ck, value)
+		if err != nil {
+			return nil, err
+		}
+		defer out.Close()
+		return out.Uint8Serialize()
+	}
+	if err := migrate(sw); err != nil {
+		_ = pk.Close()
+		_ = ck.Close()
+		_ = sk.Close()
+		return err
+	}
+
+	oldServer, oldPublic := s.server, s.public
+	s.client, s.server, s.public = ck, sk, pk
+	s.generation++
+	s.exports.reset()
+	_ = oldPublic.Close()
+	_ = oldClient.Close()
+	_ = oldServer.Close()
+	return nil
+}
@@ -0,0 +1,80 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Ciphertexts of the integer API, FheBool and the unsigned integers, have
+// two serializations. The plain one, which the rest of this package reads
+// and writes, is the in-memory layout of one tfhe-rs release: it carries no
+// version or type, so it only round-trips between builds against the same
+// release. The safe serialization of tfhe-rs is versioned and names its
+// type; tfhe-rs and its JS bindings read it with safe_deserialize across
+// releases. SafeSerialize and SafeDeserializeRaw convert at the edge.
+
+// SafeSerializationFormat names the safe serialization.
+const SafeSerializationFormat = "tfhe-rs-safe"
+
+// SafeTypes lists the ciphertext types with a safe serialization; ciphertexts
+// of the boolean gate API have none.
+var SafeTypes = []string{TypeBool, "uint2", "uint4", TypeUint8, "uint16", "uint32", "uint64"}
+
+// safeKind is the kind the native conversions take for typ: 1 for FheBool,
+// the width for an integer, or 0 if typ has no safe serialization.
+func safeKind(typ string) int {
+	switch typ {
+	case TypeBool:
+		return 1
+	case "uint2", "uint4", TypeUint8, "uint16", "uint32", "uint64":
+		bits, _ := strconv.Atoi(strings.TrimPrefix(typ, "uint"))
+		return bits
+	}
+	return 0
+}
+
+func safeType(kind int) string {
+	if kind == 1 {
+		return TypeBool
+	}
+	return fmt.Sprintf("uint%d", kind)
+}
+
+// SafeSerialize converts data, a ciphertext of typ in the plain
+// serialization, to the safe serialization.
+func SafeSerialize(typ string, data []byte) ([]byte, error) {
+	kind := safeKind(typ)
+	if kind == 0 {
+		return nil, fmt.Errorf("%w: %s has no safe serialization", ErrInvalidCiphertext, typ)
+	}
+	if err := checkSerialized(data, CurrentLimits().MaxCiphertext()); err != nil {
+		return nil, err
+	}
+	return toSafe(kind, data)
+}
+
+// SafeDeserializeRaw converts data, a safe-serialized ciphertext, to the
+// plain serialization and returns its type. The ciphertext must conform to
+// the parameters of the service's keys, so one encrypted under other
+// parameters is refused here instead of failing in evaluation.
+func (s *Uint8Service) SafeDeserializeRaw(ctx context.Context, data []byte) (typ string, out []byte, err error) {
+	if err := checkSerialized(data, CurrentLimits().MaxCiphertext()); err != nil {
+		return "", nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ctx, end := begin(ctx, s.metrics, "safe_deserialize", &out, &err)
+	defer end()
+
+	type converted struct {
+		typ  string
+		data []byte
+	}
+	res, err := native(ctx, phaseDeserialize, "safe_deserialize", func() (converted, error) {
+		typ, data, err := fromSafe(s.server, data)
+		return converted{typ, data}, err
+	})
+	return res.typ, res.data, err
+}
@@ -0,0 +1,175 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+)
+
+// indexBits is the width of the encrypted index IndexEncrypted returns.
+const indexBits = 16
+
+// MatchEncrypted compares query with every value for equality and returns
+// one encrypted flag per value, in order, with the comparisons spread
+// across up to workers goroutines. Every value is compared, so the
+// evaluation reveals nothing about which match. The flags are new
+// ciphertexts the caller must close; on error none are returned.
+func (sk *Uint8ServerKey) MatchEncrypted(query *Uint8Ciphertext, values []*Uint8Ciphertext, workers int) ([]*FheBool, error) {
+	out := make([]*FheBool, len(values))
+	err := parallel(len(values), workers, "uint8.match", func(i int) (err error) {
+		out[i], err = sk.Compare(CompareEq, query, values[i])
+		return err
+	})
+	if err != nil {
+		for _, ct := range out {
+			if ct != nil {
+				_ = ct.Close()
+			}
+		}
+		return nil, err
+	}
+	return out, nil
+}
+
+// IndexEncrypted folds match flags into an encrypted uint16 holding one
+// plus the position of the set flag, or zero when no flag is set. Each flag
+// selects its encrypted position or zero, and the selections are summed, so
+// with several flags set the result is the sum of their positions plus
+// their count. The positions are encrypted under pub; flags must number
+// fewer than 65,535. The caller must close the result.
+func (sk *Uint8ServerKey) IndexEncrypted(pub *Uint8PublicKey, flags []*FheBool, workers int) (*IntCiphertext, error) {
+	if len(flags) >= 1<<indexBits-1 {
+		return nil, fmt.Errorf("%w: %d flags do not fit a uint%d index", ErrValueOutOfRange, len(flags), indexBits)
+	}
+	zero, err := EncryptIntPublic(pub, indexBits, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer zero.Close()
+
+	terms := make([]*IntCiphertext, len(flags))
+	release := func() {
+		for _, ct := range terms {
+			if ct != nil {
+				_ = ct.Close()
+			}
+		}
+	}
+	if err := parallel(len(flags), workers, "uint8.index", func(i int) error {
+		pos, err := EncryptIntPublic(pub, indexBits, uint64(i+1))
+		if err != nil {
+			return err
+		}
+		defer pos.Close()
+		terms[i], err = sk.IntIfThenElse(flags[i], pos, zero)
+		return err
+	}); err != nil {
+		release()
+		return nil, err
+	}
+
+	// Sum pairwise, level by level.
+	for len(terms) > 1 {
+		next := make([]*IntCiphertext, (len(terms)+1)/2)
+		if err := parallel(len(terms)/2, workers, "uint8.index", func(i int) (err error) {
+			next[i], err = sk.IntAdd(terms[2*i], terms[2*i+1])
+			return err
+		}); err != nil {
+			release()
+			for _, ct := range next {
+				if ct != nil {
+					_ = ct.Close()
+				}
+			}
+			return nil, err
+		}
+		if len(terms)%2 == 1 {
+			next[len(next)-1], terms[len(terms)-1] = terms[len(terms)-1], nil
+		}
+		release()
+		terms = next
+	}
+	if len(terms) == 0 {
+		// No flags: the index is an encryption of zero of its own.
+		return EncryptIntPublic(pub, indexBits, 0)
+	}
+	return terms[0], nil
+}
+
+// SearchResult is the outcome of SearchRaw: serialized FheBool match flags in
+// the order of the values and, when requested, a serialized uint16 index as
+// IndexEncrypted computes it.
+type SearchResult struct {
+	Flags [][]byte
+	Index []byte
+}
+
+// SearchRaw compares a serialized uint8 query with serialized uint8 values
+// for equality; with index set it also folds the flags into an encrypted
+// index, which needs the service's public key.
+func (s *Uint8Service) SearchRaw(ctx context.Context, query []byte, values [][]byte, index bool, workers int) (SearchResult, error) {
+	query = detach(query)
+	if opBudget.Load() > 0 {
+		owned := make([][]byte, len(values))
+		for i, v := range values {
+			owned[i] = detach(v)
+		}
+		values = owned
+	}
+	return bounded(ctx, "uint8.search", func(ctx context.Context) (res SearchResult, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint8.search", nil, &err)
+		defer end()
+		if index && s.withheld {
+			return SearchResult{}, errClientKeyWithheld
+		}
+
+		q, releaseQuery, err := deserializeOperand(ctx, "uint8", query, Uint8Deserialize)
+		if err != nil {
+			return SearchResult{}, fmt.Errorf("query: %w", err)
+		}
+		defer releaseQuery()
+		operands := make([]*Uint8Ciphertext, len(values))
+		for i, v := range values {
+			ct, release, err := deserializeOperand(ctx, "uint8", v, Uint8Deserialize)
+			if err != nil {
+				return SearchResult{}, fmt.Errorf("value %d: %w", i, err)
+			}
+			defer release()
+			operands[i] = ct
+		}
+
+		flags, err := nativeFanout(ctx, phaseCompute, "uint8.match", workers, func(workers int) ([]*FheBool, error) {
+			return s.server.MatchEncrypted(q, operands, workers)
+		})
+		if err != nil {
+			return SearchResult{}, err
+		}
+		defer func() {
+			for _, ct := range flags {
+				_ = ct.Close()
+			}
+		}()
+
+		res.Flags = make([][]byte, len(flags))
+		for i, ct := range flags {
+			if res.Flags[i], err = native(ctx, phaseSerialize, "bool.serialize", ct.Serialize); err != nil {
+				return SearchResult{}, err
+			}
+		}
+		if !index {
+			return res, nil
+		}
+		idx, err := nativeFanout(ctx, phaseCompute, "uint8.index", workers, func(workers int) (*IntCiphertext, error) {
+			return s.server.IndexEncrypted(s.public, flags, workers)
+		})
+		if err != nil {
+			return SearchResult{}, err
+		}
+		defer idx.Close()
+		if res.Index, err = native(ctx, phaseSerialize, "uint16.serialize", idx.Serialize); err != nil {
+			return SearchResult{}, err
+		}
+		return res, nil
+	})
+}
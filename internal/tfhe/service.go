@@ -1,21 +1,38 @@
 package tfhe
 
 import (
-	"encoding/base64"
-	"errors"
+	"context"
+	"fmt"
+	"sync"
+
+	"tfhe-go/internal/bufpool"
 )
 
 // BooleanService exposes high-level helpers around the low-level bindings.
 type BooleanService struct {
-	client *ClientKey
-	server *ServerKey
+	mu      sync.RWMutex // guards keys against rotation
+	client  *ClientKey
+	server  *ServerKey
+	metrics Metrics
+	// withheld is set when the service was loaded without its client key.
+	withheld bool
+	// switchKeys holds the tenants' switch keys for proxy re-encryption.
+	switchKeys map[string]*SwitchKey
 }
 
 // Uint8Service exposes helpers for 8-bit unsigned integers.
 type Uint8Service struct {
-	client *Uint8ClientKey
-	server *Uint8ServerKey
-	public *Uint8PublicKey
+	mu      sync.RWMutex // guards keys against rotation
+	client  *Uint8ClientKey
+	server  *Uint8ServerKey
+	public  *Uint8PublicKey
+	metrics Metrics
+	exports keyExports // serialized public keys, reset on rotation
+	// generation counts rotations, telling ciphertexts of different keys apart.
+	generation uint64
+	// withheld is set when the service was loaded without its client key,
+	// and so without a public key either.
+	withheld bool
 }
 
 // NewBooleanService generates a fresh keypair and returns a ready-to-use service.
@@ -25,69 +42,209 @@ func NewBooleanService() (*BooleanService, error) {
 		return nil, err
 	}
 	return &BooleanService{
-		client: ck,
-		server: sk,
+		client:  ck,
+		server:  sk,
+		metrics: nopMetrics{},
 	}, nil
 }
 
 // EncryptBoolToBase64 encrypts a boolean and returns a base64 ciphertext.
-func (s *BooleanService) EncryptBoolToBase64(value bool) (string, error) {
-	ct, err := EncryptBool(s.client, value)
-	if err != nil {
-		return "", err
-	}
-	defer ct.Close()
-
-	bytes, err := ct.Serialize()
+func (s *BooleanService) EncryptBoolToBase64(ctx context.Context, value bool) (string, error) {
+	raw, err := s.EncryptRaw(ctx, value)
 	if err != nil {
 		return "", err
 	}
-	return base64.StdEncoding.EncodeToString(bytes), nil
+	return bufpool.EncodeBase64(raw), nil
 }
 
 // DecryptBoolFromBase64 decrypts a base64 ciphertext back to bool.
-func (s *BooleanService) DecryptBoolFromBase64(ctBase64 string) (bool, error) {
-	ct, err := deserialize(ctBase64)
+func (s *BooleanService) DecryptBoolFromBase64(ctx context.Context, ctBase64 string) (bool, error) {
+	raw, err := decodeBase64(ctBase64, CurrentLimits().MaxBooleanCiphertext)
 	if err != nil {
 		return false, err
 	}
-	defer ct.Close()
-	return DecryptBool(s.client, ct)
+	defer bufpool.Put(raw)
+	return s.DecryptRaw(ctx, *raw)
 }
 
 // AndBase64 performs homomorphic AND on two base64 ciphertexts.
-func (s *BooleanService) AndBase64(lhs, rhs string) (string, error) {
-	return s.binaryOp(lhs, rhs, s.server.And)
+func (s *BooleanService) AndBase64(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxBooleanCiphertext, s.AndRaw)
 }
 
 // OrBase64 performs homomorphic OR on two base64 ciphertexts.
-func (s *BooleanService) OrBase64(lhs, rhs string) (string, error) {
-	return s.binaryOp(lhs, rhs, s.server.Or)
+func (s *BooleanService) OrBase64(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxBooleanCiphertext, s.OrRaw)
 }
 
 // XorBase64 performs homomorphic XOR on two base64 ciphertexts.
-func (s *BooleanService) XorBase64(lhs, rhs string) (string, error) {
-	return s.binaryOp(lhs, rhs, s.server.Xor)
+func (s *BooleanService) XorBase64(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxBooleanCiphertext, s.XorRaw)
 }
 
 // NotBase64 performs homomorphic NOT on a base64 ciphertext.
-func (s *BooleanService) NotBase64(input string) (string, error) {
-	ct, err := deserialize(input)
+func (s *BooleanService) NotBase64(ctx context.Context, input string) (string, error) {
+	raw, err := decodeBase64(input, CurrentLimits().MaxBooleanCiphertext)
+	if err != nil {
+		return "", err
+	}
+	defer bufpool.Put(raw)
+	out, err := s.NotRaw(ctx, *raw)
 	if err != nil {
 		return "", err
 	}
+	return bufpool.EncodeBase64(out), nil
+}
+
+// EncryptRaw encrypts a boolean and returns the serialized ciphertext.
+func (s *BooleanService) EncryptRaw(ctx context.Context, value bool) (out []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return nil, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "boolean.encrypt", &out, &err)
+	defer end()
+
+	ct, err := native(ctx, phaseCompute, "boolean.encrypt", func() (*Ciphertext, error) { return EncryptBool(s.client, value) })
+	if err != nil {
+		return nil, err
+	}
 	defer ct.Close()
+	return native(ctx, phaseSerialize, "boolean.serialize", ct.Serialize)
+}
+
+// DecryptRaw decrypts a serialized ciphertext back to bool.
+func (s *BooleanService) DecryptRaw(ctx context.Context, data []byte) (value bool, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return false, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "boolean.decrypt", nil, &err)
+	defer end()
 
-	out, err := s.server.Not(ct)
+	ct, release, err := deserializeOperand(ctx, "boolean", data, DeserializeCiphertext)
 	if err != nil {
-		return "", err
+		return false, err
+	}
+	defer release()
+	return native(ctx, phaseCompute, "boolean.decrypt", func() (bool, error) { return DecryptBool(s.client, ct) })
+}
+
+// AndRaw performs homomorphic AND on two serialized ciphertexts.
+func (s *BooleanService) AndRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binaryOp(ctx, "boolean.and", lhs, rhs, (*ServerKey).And)
+}
+
+// OrRaw performs homomorphic OR on two serialized ciphertexts.
+func (s *BooleanService) OrRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binaryOp(ctx, "boolean.or", lhs, rhs, (*ServerKey).Or)
+}
+
+// XorRaw performs homomorphic XOR on two serialized ciphertexts.
+func (s *BooleanService) XorRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binaryOp(ctx, "boolean.xor", lhs, rhs, (*ServerKey).Xor)
+}
+
+// NotRaw performs homomorphic NOT on a serialized ciphertext.
+func (s *BooleanService) NotRaw(ctx context.Context, input []byte) ([]byte, error) {
+	input = detach(input)
+	return bounded(ctx, "boolean.not", func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "boolean.not", &out, &err)
+		defer end()
+
+		ct, release, err := deserializeOperand(ctx, "boolean", input, DeserializeCiphertext)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+
+		res, err := native(ctx, phaseCompute, "boolean.not", func() (*Ciphertext, error) { return s.server.Not(ct) })
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+		if err := checkResult("boolean.not", res); err != nil {
+			return nil, err
+		}
+		return native(ctx, phaseSerialize, "boolean.serialize", res.Serialize)
+	})
+}
+
+// GateManyRaw applies gate to every pair of serialized ciphertexts, spread
+// across up to workers threads, and returns the serialized results in order.
+func (s *BooleanService) GateManyRaw(ctx context.Context, gate Gate, pairs [][2][]byte, workers int) ([][]byte, error) {
+	name := "boolean." + string(gate) + "_many"
+	if opBudget.Load() > 0 {
+		owned := make([][2][]byte, len(pairs))
+		for i, p := range pairs {
+			owned[i] = [2][]byte{detach(p[0]), detach(p[1])}
+		}
+		pairs = owned
+	}
+	return bounded(ctx, name, func(ctx context.Context) (out [][]byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, name, nil, &err)
+		defer end()
+
+		operands := make([]Pair, len(pairs))
+		for i, p := range pairs {
+			lhs, releaseLHS, err := deserializeOperand(ctx, "boolean", p[0], DeserializeCiphertext)
+			if err != nil {
+				return nil, fmt.Errorf("pair %d: %w", i, err)
+			}
+			defer releaseLHS()
+			rhs, releaseRHS, err := deserializeOperand(ctx, "boolean", p[1], DeserializeCiphertext)
+			if err != nil {
+				return nil, fmt.Errorf("pair %d: %w", i, err)
+			}
+			defer releaseRHS()
+			operands[i] = Pair{LHS: lhs, RHS: rhs}
+		}
+
+		res, err := nativeFanout(ctx, phaseCompute, name, workers, func(workers int) ([]*Ciphertext, error) { return s.server.GateMany(gate, operands, workers) })
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for _, ct := range res {
+				_ = ct.Close()
+			}
+		}()
+
+		out = make([][]byte, len(res))
+		for i, ct := range res {
+			if err := checkResult(name, ct); err != nil {
+				return nil, fmt.Errorf("pair %d: %w", i, err)
+			}
+			if out[i], err = native(ctx, phaseSerialize, "boolean.serialize", ct.Serialize); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	})
+}
+
+// SetMetrics installs the sink receiving per-operation measurements.
+func (s *BooleanService) SetMetrics(m Metrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m == nil {
+		m = nopMetrics{}
 	}
-	defer out.Close()
-	return serializeToBase64(out)
+	s.metrics = m
 }
 
 // Close releases underlying key material.
 func (s *BooleanService) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.dropSwitchKeys()
 	var err error
 	if s.client != nil {
 		err = s.client.Close()
@@ -102,52 +259,82 @@ func (s *BooleanService) Close() error {
 	return err
 }
 
-type binaryOpFn func(lhs, rhs *Ciphertext) (*Ciphertext, error)
+type binaryOpFn func(sk *ServerKey, lhs, rhs *Ciphertext) (*Ciphertext, error)
 
-func (s *BooleanService) binaryOp(lhsBase64, rhsBase64 string, op binaryOpFn) (string, error) {
-	lhs, err := deserialize(lhsBase64)
-	if err != nil {
-		return "", err
-	}
-	defer lhs.Close()
+func (s *BooleanService) binaryOp(ctx context.Context, name string, lhsRaw, rhsRaw []byte, op binaryOpFn) ([]byte, error) {
+	lhsRaw, rhsRaw = detach(lhsRaw), detach(rhsRaw)
+	return bounded(ctx, name, func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, name, &out, &err)
+		defer end()
 
-	rhs, err := deserialize(rhsBase64)
-	if err != nil {
-		return "", err
-	}
-	defer rhs.Close()
+		lhs, releaseLHS, err := deserializeOperand(ctx, "boolean", lhsRaw, DeserializeCiphertext)
+		if err != nil {
+			return nil, operandError(0, err)
+		}
+		defer releaseLHS()
 
-	out, err := op(lhs, rhs)
-	if err != nil {
-		return "", err
-	}
-	defer out.Close()
+		rhs, releaseRHS, err := deserializeOperand(ctx, "boolean", rhsRaw, DeserializeCiphertext)
+		if err != nil {
+			return nil, operandError(1, err)
+		}
+		defer releaseRHS()
+
+		res, err := native(ctx, phaseCompute, name, func() (*Ciphertext, error) { return op(s.server, lhs, rhs) })
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+		if err := checkResult(name, res); err != nil {
+			return nil, err
+		}
 
-	return serializeToBase64(out)
+		return native(ctx, phaseSerialize, "boolean.serialize", res.Serialize)
+	})
 }
 
-func serializeToBase64(ct *Ciphertext) (string, error) {
-	bytes, err := ct.Serialize()
+// rawBinaryFn is a homomorphic operation over two serialized ciphertexts.
+type rawBinaryFn func(ctx context.Context, lhs, rhs []byte) ([]byte, error)
+
+// base64Binary decodes both operands, runs op and encodes the result.
+func base64Binary(ctx context.Context, lhsBase64, rhsBase64 string, maxLen int, op rawBinaryFn) (string, error) {
+	lhs, err := decodeBase64(lhsBase64, maxLen)
+	if err != nil {
+		return "", operandError(0, err)
+	}
+	defer bufpool.Put(lhs)
+	rhs, err := decodeBase64(rhsBase64, maxLen)
+	if err != nil {
+		return "", operandError(1, err)
+	}
+	defer bufpool.Put(rhs)
+	out, err := op(ctx, *lhs, *rhs)
 	if err != nil {
 		return "", err
 	}
-	return base64.StdEncoding.EncodeToString(bytes), nil
+	return bufpool.EncodeBase64(out), nil
 }
 
-func deserialize(ctBase64 string) (*Ciphertext, error) {
+// decodeBase64 decodes a ciphertext into a pooled buffer, which the caller
+// hands back with bufpool.Put once the operation is done with it.
+func decodeBase64(ctBase64 string, maxLen int) (*[]byte, error) {
 	if ctBase64 == "" {
-		return nil, errors.New("ciphertext is empty")
+		return nil, errCiphertextEmpty
 	}
-	raw, err := base64.StdEncoding.DecodeString(ctBase64)
-	if err != nil {
+	if err := checkEncodedLen(ctBase64, maxLen); err != nil {
 		return nil, err
 	}
-	return DeserializeCiphertext(raw)
+	raw, err := bufpool.DecodeBase64(ctBase64)
+	if err != nil {
+		return nil, invalidCiphertext(err)
+	}
+	return raw, nil
 }
 
 // NewUint8Service generates keys for uint8 operations (client/server/public) and sets server key.
 func NewUint8Service() (*Uint8Service, error) {
-	ck, sk, err := GenerateUint8Keys()
+	ck, sk, err := generateUint8Keys()
 	if err != nil {
 		return nil, err
 	}
@@ -156,59 +343,140 @@ func NewUint8Service() (*Uint8Service, error) {
 		return nil, err
 	}
 	return &Uint8Service{
-		client: ck,
-		server: sk,
-		public: pk,
+		client:  ck,
+		server:  sk,
+		public:  pk,
+		metrics: nopMetrics{},
 	}, nil
 }
 
 // Encrypt encrypts with client key and returns base64.
-func (s *Uint8Service) Encrypt(value uint8) (string, error) {
-	ct, err := EncryptUint8(s.client, value)
+func (s *Uint8Service) Encrypt(ctx context.Context, value uint8) (string, error) {
+	raw, err := s.EncryptRaw(ctx, value)
 	if err != nil {
 		return "", err
 	}
-	defer ct.Close()
-	return serializeUint8ToBase64(ct)
+	return bufpool.EncodeBase64(raw), nil
 }
 
 // EncryptWithPublic encrypts with public key and returns base64.
-func (s *Uint8Service) EncryptWithPublic(value uint8) (string, error) {
-	ct, err := EncryptUint8Public(s.public, value)
+func (s *Uint8Service) EncryptWithPublic(ctx context.Context, value uint8) (string, error) {
+	raw, err := s.EncryptWithPublicRaw(ctx, value)
 	if err != nil {
 		return "", err
 	}
-	defer ct.Close()
-	return serializeUint8ToBase64(ct)
+	return bufpool.EncodeBase64(raw), nil
 }
 
 // Decrypt decrypts base64 ciphertext to uint8.
-func (s *Uint8Service) Decrypt(ctBase64 string) (uint8, error) {
-	ct, err := deserializeUint8(ctBase64)
+func (s *Uint8Service) Decrypt(ctx context.Context, ctBase64 string) (uint8, error) {
+	raw, err := decodeBase64(ctBase64, CurrentLimits().MaxUint8Ciphertext)
 	if err != nil {
 		return 0, err
 	}
-	defer ct.Close()
-	return DecryptUint8(s.client, ct)
+	defer bufpool.Put(raw)
+	return s.DecryptRaw(ctx, *raw)
 }
 
 // Add performs homomorphic addition (requires server key already set).
-func (s *Uint8Service) Add(lhs, rhs string) (string, error) {
-	return s.binaryUint8(lhs, rhs, Uint8Add)
+func (s *Uint8Service) Add(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxUint8Ciphertext, s.AddRaw)
 }
 
 // BitAnd performs homomorphic bitwise AND.
-func (s *Uint8Service) BitAnd(lhs, rhs string) (string, error) {
-	return s.binaryUint8(lhs, rhs, Uint8BitAnd)
+func (s *Uint8Service) BitAnd(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxUint8Ciphertext, s.BitAndRaw)
 }
 
 // BitXor performs homomorphic bitwise XOR.
-func (s *Uint8Service) BitXor(lhs, rhs string) (string, error) {
-	return s.binaryUint8(lhs, rhs, Uint8BitXor)
+func (s *Uint8Service) BitXor(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxUint8Ciphertext, s.BitXorRaw)
+}
+
+// EncryptRaw encrypts with client key and returns the serialized ciphertext.
+func (s *Uint8Service) EncryptRaw(ctx context.Context, value uint8) (out []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return nil, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "uint8.encrypt", &out, &err)
+	defer end()
+
+	ct, err := native(ctx, phaseCompute, "uint8.encrypt", func() (*Uint8Ciphertext, error) { return EncryptUint8(s.client, value) })
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+	return native(ctx, phaseSerialize, "uint8.serialize", ct.Uint8Serialize)
+}
+
+// EncryptWithPublicRaw encrypts with public key and returns the serialized ciphertext.
+func (s *Uint8Service) EncryptWithPublicRaw(ctx context.Context, value uint8) (out []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return nil, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "uint8.encrypt_public", &out, &err)
+	defer end()
+
+	ct, err := native(ctx, phaseCompute, "uint8.encrypt_public", func() (*Uint8Ciphertext, error) { return EncryptUint8Public(s.public, value) })
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+	return native(ctx, phaseSerialize, "uint8.serialize", ct.Uint8Serialize)
+}
+
+// DecryptRaw decrypts a serialized ciphertext to uint8.
+func (s *Uint8Service) DecryptRaw(ctx context.Context, data []byte) (value uint8, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return 0, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "uint8.decrypt", nil, &err)
+	defer end()
+
+	ct, release, err := deserializeOperand(ctx, "uint8", data, Uint8Deserialize)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return native(ctx, phaseCompute, "uint8.decrypt", func() (uint8, error) { return DecryptUint8(s.client, ct) })
+}
+
+// AddRaw performs homomorphic addition on serialized ciphertexts.
+func (s *Uint8Service) AddRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binaryUint8(ctx, "uint8.add", lhs, rhs, (*Uint8ServerKey).Add)
+}
+
+// BitAndRaw performs homomorphic bitwise AND on serialized ciphertexts.
+func (s *Uint8Service) BitAndRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binaryUint8(ctx, "uint8.bitand", lhs, rhs, (*Uint8ServerKey).BitAnd)
+}
+
+// BitXorRaw performs homomorphic bitwise XOR on serialized ciphertexts.
+func (s *Uint8Service) BitXorRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binaryUint8(ctx, "uint8.bitxor", lhs, rhs, (*Uint8ServerKey).BitXor)
+}
+
+// SetMetrics installs the sink receiving per-operation measurements.
+func (s *Uint8Service) SetMetrics(m Metrics) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if m == nil {
+		m = nopMetrics{}
+	}
+	s.metrics = m
 }
 
 // Close releases keys.
 func (s *Uint8Service) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	var err error
 	if s.public != nil {
 		err = s.public.Close()
@@ -229,45 +497,37 @@ func (s *Uint8Service) Close() error {
 	return err
 }
 
-type uint8Op func(lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error)
+type uint8Op func(sk *Uint8ServerKey, lhs, rhs *Uint8Ciphertext) (*Uint8Ciphertext, error)
 
-func (s *Uint8Service) binaryUint8(lhsBase64, rhsBase64 string, op uint8Op) (string, error) {
-	lhs, err := deserializeUint8(lhsBase64)
-	if err != nil {
-		return "", err
-	}
-	defer lhs.Close()
-
-	rhs, err := deserializeUint8(rhsBase64)
-	if err != nil {
-		return "", err
-	}
-	defer rhs.Close()
+func (s *Uint8Service) binaryUint8(ctx context.Context, name string, lhsRaw, rhsRaw []byte, op uint8Op) ([]byte, error) {
+	lhsRaw, rhsRaw = detach(lhsRaw), detach(rhsRaw)
+	return bounded(ctx, name, func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, name, &out, &err)
+		defer end()
 
-	out, err := op(lhs, rhs)
-	if err != nil {
-		return "", err
-	}
-	defer out.Close()
+		lhs, releaseLHS, err := deserializeOperand(ctx, "uint8", lhsRaw, Uint8Deserialize)
+		if err != nil {
+			return nil, operandError(0, err)
+		}
+		defer releaseLHS()
 
-	return serializeUint8ToBase64(out)
-}
+		rhs, releaseRHS, err := deserializeOperand(ctx, "uint8", rhsRaw, Uint8Deserialize)
+		if err != nil {
+			return nil, operandError(1, err)
+		}
+		defer releaseRHS()
 
-func serializeUint8ToBase64(ct *Uint8Ciphertext) (string, error) {
-	bytes, err := ct.Uint8Serialize()
-	if err != nil {
-		return "", err
-	}
-	return base64.StdEncoding.EncodeToString(bytes), nil
-}
+		res, err := native(ctx, phaseCompute, name, func() (*Uint8Ciphertext, error) { return op(s.server, lhs, rhs) })
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+		if err := checkResult(name, res); err != nil {
+			return nil, err
+		}
 
-func deserializeUint8(ctBase64 string) (*Uint8Ciphertext, error) {
-	if ctBase64 == "" {
-		return nil, errors.New("ciphertext is empty")
-	}
-	raw, err := base64.StdEncoding.DecodeString(ctBase64)
-	if err != nil {
-		return nil, err
-	}
-	return Uint8Deserialize(raw)
+		return native(ctx, phaseSerialize, "uint8.serialize", res.Uint8Serialize)
+	})
 }
@@ -0,0 +1,39 @@
+package tfhe
+
+import "context"
+
+// BitCount runs a bit-counting operation on a base64 uint8 ciphertext and
+// returns the count as a base64 uint32 ciphertext.
+func (s *Uint8Service) BitCount(ctx context.Context, op BitCount, ctBase64 string) (string, error) {
+	return base64Unary(ctx, ctBase64, CurrentLimits().MaxUint8Ciphertext, func(ctx context.Context, data []byte) ([]byte, error) {
+		return s.BitCountRaw(ctx, op, data)
+	})
+}
+
+// BitCountRaw runs a bit-counting operation on a serialized uint8
+// ciphertext and returns the count as a serialized uint32 ciphertext.
+func (s *Uint8Service) BitCountRaw(ctx context.Context, op BitCount, data []byte) ([]byte, error) {
+	name := "uint8." + string(op)
+	data = detach(data)
+	return bounded(ctx, name, func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, name, &out, &err)
+		defer end()
+
+		ct, release, err := deserializeOperand(ctx, "uint8", data, Uint8Deserialize)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		res, err := native(ctx, phaseCompute, name, func() (*IntCiphertext, error) { return s.server.BitCount(op, ct) })
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+		if err := checkResult(name, res); err != nil {
+			return nil, err
+		}
+		return native(ctx, phaseSerialize, "uint32.serialize", res.Serialize)
+	})
+}
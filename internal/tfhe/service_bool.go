@@ -0,0 +1,239 @@
+package tfhe
+
+import (
+	"context"
+
+	"tfhe-go/internal/bufpool"
+)
+
+// EncryptBool encrypts an FheBool with the client key and returns base64.
+// Unlike BooleanService, the result can drive IfThenElse.
+func (s *Uint8Service) EncryptBool(ctx context.Context, value bool) (string, error) {
+	raw, err := s.EncryptBoolRaw(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return bufpool.EncodeBase64(raw), nil
+}
+
+// DecryptBool decrypts a base64 FheBool, such as a comparison result.
+func (s *Uint8Service) DecryptBool(ctx context.Context, ctBase64 string) (bool, error) {
+	raw, err := decodeBase64(ctBase64, CurrentLimits().MaxFheBoolCiphertext)
+	if err != nil {
+		return false, err
+	}
+	defer bufpool.Put(raw)
+	return s.DecryptBoolRaw(ctx, *raw)
+}
+
+// Compare compares two base64 uint8 ciphertexts and returns a base64 FheBool.
+func (s *Uint8Service) Compare(ctx context.Context, cmp Comparison, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxUint8Ciphertext, func(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+		return s.CompareRaw(ctx, cmp, lhs, rhs)
+	})
+}
+
+// IfThenElse selects between two base64 uint8 ciphertexts under a base64 FheBool.
+func (s *Uint8Service) IfThenElse(ctx context.Context, cond, then, els string) (string, error) {
+	return base64Select(ctx, cond, then, els, CurrentLimits().MaxUint8Ciphertext, s.IfThenElseRaw)
+}
+
+// EncryptBoolRaw encrypts an FheBool with the client key and returns it serialized.
+func (s *Uint8Service) EncryptBoolRaw(ctx context.Context, value bool) (out []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return nil, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "bool.encrypt", &out, &err)
+	defer end()
+
+	ct, err := native(ctx, phaseCompute, "bool.encrypt", func() (*FheBool, error) { return EncryptFheBool(s.client, value) })
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+	return native(ctx, phaseSerialize, "bool.serialize", ct.Serialize)
+}
+
+// DecryptBoolRaw decrypts a serialized FheBool.
+func (s *Uint8Service) DecryptBoolRaw(ctx context.Context, data []byte) (value bool, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return false, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.metrics, "bool.decrypt", nil, &err)
+	defer end()
+
+	ct, release, err := deserializeOperand(ctx, "bool", data, DeserializeFheBool)
+	if err != nil {
+		return false, err
+	}
+	defer release()
+	return native(ctx, phaseCompute, "bool.decrypt", func() (bool, error) { return DecryptFheBool(s.client, ct) })
+}
+
+// CompareRaw compares two serialized uint8 ciphertexts and returns a serialized FheBool.
+func (s *Uint8Service) CompareRaw(ctx context.Context, cmp Comparison, lhsRaw, rhsRaw []byte) ([]byte, error) {
+	name := "uint8." + string(cmp)
+	lhsRaw, rhsRaw = detach(lhsRaw), detach(rhsRaw)
+	return bounded(ctx, name, func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, name, &out, &err)
+		defer end()
+
+		return compareSerialized(ctx, "uint8", name, lhsRaw, rhsRaw, Uint8Deserialize, func(lhs, rhs *Uint8Ciphertext) (*FheBool, error) {
+			return s.server.Compare(cmp, lhs, rhs)
+		})
+	})
+}
+
+// IfThenElseRaw selects between two serialized uint8 ciphertexts under a serialized FheBool.
+func (s *Uint8Service) IfThenElseRaw(ctx context.Context, cond, then, els []byte) ([]byte, error) {
+	cond, then, els = detach(cond), detach(then), detach(els)
+	return bounded(ctx, "uint8.if_then_else", func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint8.if_then_else", &out, &err)
+		defer end()
+
+		return selectSerialized(ctx, "uint8", cond, then, els, Uint8Deserialize, s.server.IfThenElse, (*Uint8Ciphertext).Uint8Serialize)
+	})
+}
+
+// Compare compares two base64 ciphertexts and returns a base64 FheBool.
+func (s *IntService) Compare(ctx context.Context, cmp Comparison, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxIntCiphertext(s.bits), func(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+		return s.CompareRaw(ctx, cmp, lhs, rhs)
+	})
+}
+
+// IfThenElse selects between two base64 ciphertexts under a base64 FheBool.
+func (s *IntService) IfThenElse(ctx context.Context, cond, then, els string) (string, error) {
+	return base64Select(ctx, cond, then, els, CurrentLimits().MaxIntCiphertext(s.bits), s.IfThenElseRaw)
+}
+
+// CompareRaw compares two serialized ciphertexts and returns a serialized FheBool.
+func (s *IntService) CompareRaw(ctx context.Context, cmp Comparison, lhsRaw, rhsRaw []byte) ([]byte, error) {
+	name := s.name + "." + string(cmp)
+	lhsRaw, rhsRaw = detach(lhsRaw), detach(rhsRaw)
+	return bounded(ctx, name, func(ctx context.Context) (out []byte, err error) {
+		s.keys.mu.RLock()
+		defer s.keys.mu.RUnlock()
+		ctx, end := begin(ctx, s.keys.metrics, name, &out, &err)
+		defer end()
+
+		return compareSerialized(ctx, s.name, name, lhsRaw, rhsRaw, s.deserialize, func(lhs, rhs *IntCiphertext) (*FheBool, error) {
+			return s.keys.server.IntCompare(cmp, lhs, rhs)
+		})
+	})
+}
+
+// IfThenElseRaw selects between two serialized ciphertexts under a serialized FheBool.
+func (s *IntService) IfThenElseRaw(ctx context.Context, cond, then, els []byte) ([]byte, error) {
+	cond, then, els = detach(cond), detach(then), detach(els)
+	return bounded(ctx, s.name+".if_then_else", func(ctx context.Context) (out []byte, err error) {
+		s.keys.mu.RLock()
+		defer s.keys.mu.RUnlock()
+		ctx, end := begin(ctx, s.keys.metrics, s.name+".if_then_else", &out, &err)
+		defer end()
+
+		return selectSerialized(ctx, s.name, cond, then, els, s.deserialize, s.keys.server.IntIfThenElse, (*IntCiphertext).Serialize)
+	})
+}
+
+func (s *IntService) deserialize(data []byte) (*IntCiphertext, error) {
+	return DeserializeInt(s.bits, data)
+}
+
+// compareSerialized deserializes both operands of type typ, compares them
+// and serializes the resulting FheBool.
+func compareSerialized[T interface{ Close() error }](ctx context.Context, typ, name string, lhsRaw, rhsRaw []byte, deserialize func([]byte) (T, error), cmp func(lhs, rhs T) (*FheBool, error)) ([]byte, error) {
+	lhs, releaseLHS, err := deserializeOperand(ctx, typ, lhsRaw, deserialize)
+	if err != nil {
+		return nil, operandError(0, err)
+	}
+	defer releaseLHS()
+
+	rhs, releaseRHS, err := deserializeOperand(ctx, typ, rhsRaw, deserialize)
+	if err != nil {
+		return nil, operandError(1, err)
+	}
+	defer releaseRHS()
+
+	res, err := native(ctx, phaseCompute, name, func() (*FheBool, error) { return cmp(lhs, rhs) })
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	if err := checkResult(name, res); err != nil {
+		return nil, err
+	}
+
+	return native(ctx, phaseSerialize, "bool.serialize", res.Serialize)
+}
+
+// selectSerialized deserializes the condition and both branches of type typ,
+// runs the encrypted selection and serializes the result.
+func selectSerialized[T interface {
+	Close() error
+	trivialCiphertext
+}](ctx context.Context, typ string, condRaw, thenRaw, elsRaw []byte, deserialize func([]byte) (T, error), sel func(cond *FheBool, then, els T) (T, error), serialize func(T) ([]byte, error)) ([]byte, error) {
+	cond, releaseCond, err := deserializeOperand(ctx, "bool", condRaw, DeserializeFheBool)
+	if err != nil {
+		return nil, operandError(0, err)
+	}
+	defer releaseCond()
+
+	then, releaseThen, err := deserializeOperand(ctx, typ, thenRaw, deserialize)
+	if err != nil {
+		return nil, operandError(1, err)
+	}
+	defer releaseThen()
+
+	els, releaseEls, err := deserializeOperand(ctx, typ, elsRaw, deserialize)
+	if err != nil {
+		return nil, operandError(2, err)
+	}
+	defer releaseEls()
+
+	res, err := native(ctx, phaseCompute, typ+".if_then_else", func() (T, error) { return sel(cond, then, els) })
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	if err := checkResult(typ+".if_then_else", res); err != nil {
+		return nil, err
+	}
+
+	return native(ctx, phaseSerialize, typ+".serialize", func() ([]byte, error) { return serialize(res) })
+}
+
+// rawSelectFn is an encrypted selection over a serialized condition and branches.
+type rawSelectFn func(ctx context.Context, cond, then, els []byte) ([]byte, error)
+
+// base64Select decodes the condition and both branches, runs op and encodes the result.
+func base64Select(ctx context.Context, condBase64, thenBase64, elsBase64 string, maxLen int, op rawSelectFn) (string, error) {
+	cond, err := decodeBase64(condBase64, CurrentLimits().MaxFheBoolCiphertext)
+	if err != nil {
+		return "", operandError(0, err)
+	}
+	defer bufpool.Put(cond)
+	then, err := decodeBase64(thenBase64, maxLen)
+	if err != nil {
+		return "", operandError(1, err)
+	}
+	defer bufpool.Put(then)
+	els, err := decodeBase64(elsBase64, maxLen)
+	if err != nil {
+		return "", operandError(2, err)
+	}
+	defer bufpool.Put(els)
+	out, err := op(ctx, *cond, *then, *els)
+	if err != nil {
+		return "", err
+	}
+	return bufpool.EncodeBase64(out), nil
+}
@@ -0,0 +1,176 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+
+	"tfhe-go/internal/bufpool"
+)
+
+// maxComposeBits is the number of FheBools ComposeRaw packs at most.
+const maxComposeBits = 8
+
+// BoolToUint8 casts a base64 FheBool to a base64 uint8 ciphertext of 0 or 1.
+func (s *Uint8Service) BoolToUint8(ctx context.Context, ctBase64 string) (string, error) {
+	return base64Unary(ctx, ctBase64, CurrentLimits().MaxFheBoolCiphertext, s.BoolToUint8Raw)
+}
+
+// Uint8ToBool returns a base64 FheBool that is true when the base64 uint8
+// ciphertext is nonzero.
+func (s *Uint8Service) Uint8ToBool(ctx context.Context, ctBase64 string) (string, error) {
+	return base64Unary(ctx, ctBase64, CurrentLimits().MaxUint8Ciphertext, s.Uint8ToBoolRaw)
+}
+
+// BoolToUint8Raw casts a serialized FheBool to a serialized uint8 ciphertext.
+func (s *Uint8Service) BoolToUint8Raw(ctx context.Context, data []byte) ([]byte, error) {
+	data = detach(data)
+	return bounded(ctx, "bool.to_uint8", func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "bool.to_uint8", &out, &err)
+		defer end()
+
+		b, release, err := deserializeOperand(ctx, "bool", data, DeserializeFheBool)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		res, err := native(ctx, phaseCompute, "bool.to_uint8", func() (*Uint8Ciphertext, error) { return s.server.BoolToUint8(b) })
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+		return native(ctx, phaseSerialize, "uint8.serialize", res.Uint8Serialize)
+	})
+}
+
+// Uint8ToBoolRaw returns a serialized FheBool that is true when the
+// serialized uint8 ciphertext is nonzero.
+func (s *Uint8Service) Uint8ToBoolRaw(ctx context.Context, data []byte) ([]byte, error) {
+	data = detach(data)
+	return bounded(ctx, "uint8.to_bool", func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint8.to_bool", &out, &err)
+		defer end()
+
+		ct, release, err := deserializeOperand(ctx, "uint8", data, Uint8Deserialize)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		res, err := native(ctx, phaseCompute, "uint8.to_bool", func() (*FheBool, error) { return s.server.Uint8ToBool(ct) })
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+		return native(ctx, phaseSerialize, "bool.serialize", res.Serialize)
+	})
+}
+
+// BitsRaw splits a serialized uint8 ciphertext into eight serialized
+// FheBools, least significant first.
+func (s *Uint8Service) BitsRaw(ctx context.Context, data []byte) ([][]byte, error) {
+	data = detach(data)
+	return bounded(ctx, "uint8.bits", func(ctx context.Context) (out [][]byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint8.bits", nil, &err)
+		defer end()
+
+		ct, release, err := deserializeOperand(ctx, "uint8", data, Uint8Deserialize)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		bits, err := native(ctx, phaseCompute, "uint8.bits", func() ([]*FheBool, error) { return s.server.Uint8Bits(ct) })
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for _, b := range bits {
+				b.Close()
+			}
+		}()
+		out = make([][]byte, len(bits))
+		for i, b := range bits {
+			if out[i], err = native(ctx, phaseSerialize, "bool.serialize", b.Serialize); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	})
+}
+
+// ComposeRaw packs one to eight serialized FheBools, least significant
+// first, into a serialized uint8 ciphertext.
+func (s *Uint8Service) ComposeRaw(ctx context.Context, bits [][]byte) ([]byte, error) {
+	if len(bits) == 0 || len(bits) > maxComposeBits {
+		return nil, invalidCiphertext(fmt.Errorf("%d bits, want 1 to %d", len(bits), maxComposeBits))
+	}
+	for i := range bits {
+		bits[i] = detach(bits[i])
+	}
+	return bounded(ctx, "uint8.compose", func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint8.compose", &out, &err)
+		defer end()
+
+		cts := make([]*FheBool, len(bits))
+		for i, data := range bits {
+			b, release, err := deserializeOperand(ctx, "bool", data, DeserializeFheBool)
+			if err != nil {
+				return nil, err
+			}
+			defer release()
+			cts[i] = b
+		}
+		res, err := native(ctx, phaseCompute, "uint8.compose", func() (*Uint8Ciphertext, error) { return s.server.ComposeUint8(cts) })
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+		return native(ctx, phaseSerialize, "uint8.serialize", res.Uint8Serialize)
+	})
+}
+
+// The boolean API and the integer API use unrelated keys and parameters,
+// and tfhe-c cannot switch a ciphertext from one to the other. BooleanToFheBool
+// and FheBoolToBoolean therefore convert in the trusted way: the value is
+// decrypted under the source service's client key and encrypted again under
+// the target's. Both services must hold their client keys; the caller only
+// ever sees ciphertexts.
+
+// BooleanToFheBool converts a serialized boolean API ciphertext of from into
+// a serialized FheBool of to.
+func BooleanToFheBool(ctx context.Context, from *BooleanService, to *Uint8Service, data []byte) ([]byte, error) {
+	value, err := from.DecryptRaw(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return to.EncryptBoolRaw(ctx, value)
+}
+
+// FheBoolToBoolean converts a serialized FheBool of from into a serialized
+// boolean API ciphertext of to.
+func FheBoolToBoolean(ctx context.Context, from *Uint8Service, to *BooleanService, data []byte) ([]byte, error) {
+	value, err := from.DecryptBoolRaw(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return to.EncryptRaw(ctx, value)
+}
+
+func base64Unary(ctx context.Context, ctBase64 string, maxLen int, op func(context.Context, []byte) ([]byte, error)) (string, error) {
+	raw, err := decodeBase64(ctBase64, maxLen)
+	if err != nil {
+		return "", err
+	}
+	defer bufpool.Put(raw)
+	out, err := op(ctx, *raw)
+	if err != nil {
+		return "", err
+	}
+	return bufpool.EncodeBase64(out), nil
+}
@@ -0,0 +1,178 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+
+	"tfhe-go/internal/bufpool"
+)
+
+// IntService exposes helpers for one of the unsigned integer types besides
+// uint8.
+// It computes under the keys of the Uint8Service it was created from, so key
+// rotation and metrics follow that service.
+type IntService struct {
+	bits int
+	keys *Uint8Service
+	name string // operation name prefix, e.g. "uint16"
+}
+
+// NewIntService returns a service for bits-wide unsigned integers (2, 4, 16,
+// 32 or 64) sharing keys with the given Uint8Service.
+func NewIntService(keys *Uint8Service, bits int) (*IntService, error) {
+	if err := checkBits(bits); err != nil {
+		return nil, err
+	}
+	return &IntService{bits: bits, keys: keys, name: fmt.Sprintf("uint%d", bits)}, nil
+}
+
+// Bits returns the integer width handled by the service.
+func (s *IntService) Bits() int { return s.bits }
+
+// Encrypt encrypts with the client key and returns base64.
+func (s *IntService) Encrypt(ctx context.Context, value uint64) (string, error) {
+	raw, err := s.EncryptRaw(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return bufpool.EncodeBase64(raw), nil
+}
+
+// EncryptWithPublic encrypts with the public key and returns base64.
+func (s *IntService) EncryptWithPublic(ctx context.Context, value uint64) (string, error) {
+	raw, err := s.EncryptWithPublicRaw(ctx, value)
+	if err != nil {
+		return "", err
+	}
+	return bufpool.EncodeBase64(raw), nil
+}
+
+// Decrypt decrypts a base64 ciphertext.
+func (s *IntService) Decrypt(ctx context.Context, ctBase64 string) (uint64, error) {
+	raw, err := decodeBase64(ctBase64, CurrentLimits().MaxIntCiphertext(s.bits))
+	if err != nil {
+		return 0, err
+	}
+	defer bufpool.Put(raw)
+	return s.DecryptRaw(ctx, *raw)
+}
+
+// Add performs homomorphic addition, wrapping on overflow.
+func (s *IntService) Add(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxIntCiphertext(s.bits), s.AddRaw)
+}
+
+// BitAnd performs homomorphic bitwise AND.
+func (s *IntService) BitAnd(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxIntCiphertext(s.bits), s.BitAndRaw)
+}
+
+// BitXor performs homomorphic bitwise XOR.
+func (s *IntService) BitXor(ctx context.Context, lhs, rhs string) (string, error) {
+	return base64Binary(ctx, lhs, rhs, CurrentLimits().MaxIntCiphertext(s.bits), s.BitXorRaw)
+}
+
+// EncryptRaw encrypts with the client key and returns the serialized ciphertext.
+func (s *IntService) EncryptRaw(ctx context.Context, value uint64) (out []byte, err error) {
+	s.keys.mu.RLock()
+	defer s.keys.mu.RUnlock()
+	if s.keys.withheld {
+		return nil, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.keys.metrics, s.name+".encrypt", &out, &err)
+	defer end()
+
+	ct, err := native(ctx, phaseCompute, s.name+".encrypt", func() (*IntCiphertext, error) { return EncryptInt(s.keys.client, s.bits, value) })
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+	return native(ctx, phaseSerialize, s.name+".serialize", ct.Serialize)
+}
+
+// EncryptWithPublicRaw encrypts with the public key and returns the serialized ciphertext.
+func (s *IntService) EncryptWithPublicRaw(ctx context.Context, value uint64) (out []byte, err error) {
+	s.keys.mu.RLock()
+	defer s.keys.mu.RUnlock()
+	if s.keys.withheld {
+		return nil, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.keys.metrics, s.name+".encrypt_public", &out, &err)
+	defer end()
+
+	ct, err := native(ctx, phaseCompute, s.name+".encrypt_public", func() (*IntCiphertext, error) { return EncryptIntPublic(s.keys.public, s.bits, value) })
+	if err != nil {
+		return nil, err
+	}
+	defer ct.Close()
+	return native(ctx, phaseSerialize, s.name+".serialize", ct.Serialize)
+}
+
+// DecryptRaw decrypts a serialized ciphertext.
+func (s *IntService) DecryptRaw(ctx context.Context, data []byte) (value uint64, err error) {
+	s.keys.mu.RLock()
+	defer s.keys.mu.RUnlock()
+	if s.keys.withheld {
+		return 0, errClientKeyWithheld
+	}
+	ctx, end := begin(ctx, s.keys.metrics, s.name+".decrypt", nil, &err)
+	defer end()
+
+	ct, release, err := deserializeOperand(ctx, s.name, data, s.deserialize)
+	if err != nil {
+		return 0, err
+	}
+	defer release()
+	return native(ctx, phaseCompute, s.name+".decrypt", func() (uint64, error) { return DecryptInt(s.keys.client, ct) })
+}
+
+// AddRaw performs homomorphic addition on serialized ciphertexts.
+func (s *IntService) AddRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binary(ctx, "add", lhs, rhs, (*Uint8ServerKey).IntAdd)
+}
+
+// BitAndRaw performs homomorphic bitwise AND on serialized ciphertexts.
+func (s *IntService) BitAndRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binary(ctx, "bitand", lhs, rhs, (*Uint8ServerKey).IntBitAnd)
+}
+
+// BitXorRaw performs homomorphic bitwise XOR on serialized ciphertexts.
+func (s *IntService) BitXorRaw(ctx context.Context, lhs, rhs []byte) ([]byte, error) {
+	return s.binary(ctx, "bitxor", lhs, rhs, (*Uint8ServerKey).IntBitXor)
+}
+
+type intBinaryFn func(sk *Uint8ServerKey, lhs, rhs *IntCiphertext) (*IntCiphertext, error)
+
+func (s *IntService) binary(ctx context.Context, op string, lhsRaw, rhsRaw []byte, fn intBinaryFn) ([]byte, error) {
+	name := s.name + "." + op
+	lhsRaw, rhsRaw = detach(lhsRaw), detach(rhsRaw)
+	return bounded(ctx, name, func(ctx context.Context) (out []byte, err error) {
+		s.keys.mu.RLock()
+		defer s.keys.mu.RUnlock()
+		ctx, end := begin(ctx, s.keys.metrics, name, &out, &err)
+		defer end()
+
+		lhs, releaseLHS, err := deserializeOperand(ctx, s.name, lhsRaw, s.deserialize)
+		if err != nil {
+			return nil, operandError(0, err)
+		}
+		defer releaseLHS()
+
+		rhs, releaseRHS, err := deserializeOperand(ctx, s.name, rhsRaw, s.deserialize)
+		if err != nil {
+			return nil, operandError(1, err)
+		}
+		defer releaseRHS()
+
+		res, err := native(ctx, phaseCompute, name, func() (*IntCiphertext, error) { return fn(s.keys.server, lhs, rhs) })
+		if err != nil {
+			return nil, err
+		}
+		defer res.Close()
+		if err := checkResult(name, res); err != nil {
+			return nil, err
+		}
+
+		return native(ctx, phaseSerialize, s.name+".serialize", res.Serialize)
+	})
+}
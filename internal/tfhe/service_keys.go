@@ -0,0 +1,156 @@
+package tfhe
+
+// KeyPair is a serialized client/server keypair, as exported by a service
+// for persistence. Client is empty for a service loaded without it.
+type KeyPair struct {
+	Client []byte
+	Server []byte
+}
+
+// ExportKeys serializes the service's current keypair.
+func (s *BooleanService) ExportKeys() (KeyPair, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys KeyPair
+	var err error
+	if !s.withheld {
+		if keys.Client, err = s.client.Serialize(); err != nil {
+			return KeyPair{}, err
+		}
+	}
+	if keys.Server, err = s.server.Serialize(); err != nil {
+		return KeyPair{}, err
+	}
+	return keys, nil
+}
+
+// NewBooleanServiceFromKeys returns a service computing under a keypair
+// previously exported with ExportKeys. Without keys.Client the service can
+// only evaluate gates; encrypt and decrypt report ErrClientKeyWithheld.
+func NewBooleanServiceFromKeys(keys KeyPair) (*BooleanService, error) {
+	s := &BooleanService{metrics: nopMetrics{}, withheld: len(keys.Client) == 0}
+	if !s.withheld {
+		ck, err := DeserializeClientKey(keys.Client)
+		if err != nil {
+			return nil, err
+		}
+		s.client = ck
+	}
+	sk, err := DeserializeServerKey(keys.Server)
+	if err != nil {
+		_ = s.client.Close()
+		return nil, err
+	}
+	s.server = sk
+	return s, nil
+}
+
+// ExportKeys serializes the service's current client and server keys. The
+// public key is derived from the client key and not exported.
+func (s *Uint8Service) ExportKeys() (KeyPair, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var keys KeyPair
+	var err error
+	if !s.withheld {
+		if keys.Client, err = s.client.Serialize(); err != nil {
+			return KeyPair{}, err
+		}
+	}
+	if keys.Server, err = s.server.Serialize(); err != nil {
+		return KeyPair{}, err
+	}
+	return keys, nil
+}
+
+// NewUint8ServiceFromKeys returns a service computing under keys previously
+// exported with ExportKeys, and installs its server key like NewUint8Service.
+// Without keys.Client there is no public key either, and encryption,
+// decryption and the public key exports report ErrClientKeyWithheld.
+func NewUint8ServiceFromKeys(keys KeyPair) (*Uint8Service, error) {
+	s := &Uint8Service{metrics: nopMetrics{}, withheld: len(keys.Client) == 0}
+	if !s.withheld {
+		ck, err := DeserializeUint8ClientKey(keys.Client)
+		if err != nil {
+			return nil, err
+		}
+		pk, err := NewUint8PublicKey(ck)
+		if err != nil {
+			_ = ck.Close()
+			return nil, err
+		}
+		s.client, s.public = ck, pk
+	}
+	sk, err := DeserializeUint8ServerKey(keys.Server)
+	if err != nil {
+		_ = s.public.Close()
+		_ = s.client.Close()
+		return nil, err
+	}
+	s.server = sk
+	return s, nil
+}
+
+// WithholdClientKey releases the service's client key, after which it only
+// evaluates gates. It is a no-op when the key is already withheld.
+func (s *BooleanService) WithholdClientKey() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.withheld {
+		return nil
+	}
+	s.withheld = true
+	err := s.client.Close()
+	s.client = nil
+	return err
+}
+
+// WithholdClientKey releases the service's client and public keys, after
+// which it only evaluates operations. It is a no-op when the keys are
+// already withheld.
+func (s *Uint8Service) WithholdClientKey() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.withheld {
+		return nil
+	}
+	s.withheld = true
+	s.exports.reset()
+	err := s.public.Close()
+	if cerr := s.client.Close(); err == nil {
+		err = cerr
+	}
+	s.client, s.public = nil, nil
+	return err
+}
+
+// ClientKeyWithheld reports whether the service runs without its client key.
+func (s *BooleanService) ClientKeyWithheld() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.withheld
+}
+
+// ClientKeyWithheld reports whether the service runs without its client and
+// public keys.
+func (s *Uint8Service) ClientKeyWithheld() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.withheld
+}
+
+// ServerKeySize returns the size in bytes of the serialized server key.
+func (s *BooleanService) ServerKeySize() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, err := s.server.Serialize()
+	return len(data), err
+}
+
+// ServerKeySize returns the size in bytes of the serialized server key.
+func (s *Uint8Service) ServerKeySize() (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	data, err := s.server.Serialize()
+	return len(data), err
+}
@@ -0,0 +1,95 @@
+package tfhe
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// PublicKeyExport is a serialized public key with a version derived from its
+// contents, suitable as an HTTP ETag.
+type PublicKeyExport struct {
+	Data    []byte
+	Version string
+}
+
+// keyExports caches serialized public keys; serializing the full public key
+// is expensive and its bytes only change on rotation.
+type keyExports struct {
+	mu      sync.Mutex
+	public  *PublicKeyExport
+	compact *PublicKeyExport
+}
+
+func (e *keyExports) reset() {
+	e.mu.Lock()
+	e.public, e.compact = nil, nil
+	e.mu.Unlock()
+}
+
+func newPublicKeyExport(data []byte) *PublicKeyExport {
+	sum := sha256.Sum256(data)
+	return &PublicKeyExport{Data: data, Version: hex.EncodeToString(sum[:16])}
+}
+
+// KeyGeneration counts the service's key rotations. Ciphertexts kept
+// outside the store, which rotation does not re-encrypt, are only
+// compatible with the service while it stays at their generation.
+func (s *Uint8Service) KeyGeneration() uint64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.generation
+}
+
+// PublicKey returns the serialized public key clients can encrypt under.
+func (s *Uint8Service) PublicKey(ctx context.Context) (out PublicKeyExport, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return PublicKeyExport{}, errClientKeyWithheld
+	}
+	s.exports.mu.Lock()
+	defer s.exports.mu.Unlock()
+	if s.exports.public != nil {
+		return *s.exports.public, nil
+	}
+	ctx, end := begin(ctx, s.metrics, "uint8.public_key", &out.Data, &err)
+	defer end()
+
+	data, err := native(ctx, phaseSerialize, "public_key.serialize", s.public.Serialize)
+	if err != nil {
+		return PublicKeyExport{}, err
+	}
+	s.exports.public = newPublicKeyExport(data)
+	return *s.exports.public, nil
+}
+
+// CompactPublicKey returns a serialized compact public key derived from the
+// client key. It is far smaller than PublicKey and encrypts the same types.
+func (s *Uint8Service) CompactPublicKey(ctx context.Context) (out PublicKeyExport, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.withheld {
+		return PublicKeyExport{}, errClientKeyWithheld
+	}
+	s.exports.mu.Lock()
+	defer s.exports.mu.Unlock()
+	if s.exports.compact != nil {
+		return *s.exports.compact, nil
+	}
+	ctx, end := begin(ctx, s.metrics, "uint8.compact_public_key", &out.Data, &err)
+	defer end()
+
+	pk, err := native(ctx, phaseCompute, "compact_public_key.new", func() (*Uint8CompactPublicKey, error) { return NewUint8CompactPublicKey(s.client) })
+	if err != nil {
+		return PublicKeyExport{}, err
+	}
+	defer pk.Close()
+	data, err := native(ctx, phaseSerialize, "compact_public_key.serialize", pk.Serialize)
+	if err != nil {
+		return PublicKeyExport{}, err
+	}
+	s.exports.compact = newPublicKeyExport(data)
+	return *s.exports.compact, nil
+}
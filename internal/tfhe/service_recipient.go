@@ -0,0 +1,203 @@
+package tfhe
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"sync/atomic"
+
+	"tfhe-go/internal/bufpool"
+)
+
+// maxRecipientKey bounds a serialized compact public key supplied by a
+// caller; real keys are a few tens of KiB.
+const maxRecipientKey = 1 << 20
+
+// Re-encryption for a recipient decrypts a ciphertext under the service's
+// client key and encrypts the value again under a compact public key the
+// caller supplies, so the result can only be read by whoever holds the
+// matching client key. The plaintext passes through the server on the way:
+// this is trusted re-encryption, as revealing as decryption to anyone who
+// owns a recipient key, unlike the key switching in service_switch.go.
+// It is therefore off unless enabled with SetTrustedReencrypt.
+var trustedReencrypt atomic.Bool
+
+// SetTrustedReencrypt allows re-encryption for a recipient, which decrypts
+// every result with the service's client key on the way. Off, the default,
+// the ReencryptFor methods fail with ErrTrustedReencryptDisabled.
+func SetTrustedReencrypt(on bool) { trustedReencrypt.Store(on) }
+
+// TrustedReencrypt reports whether re-encryption for a recipient is allowed.
+func TrustedReencrypt() bool { return trustedReencrypt.Load() }
+
+// ReencryptFor re-encrypts a base64 uint8 ciphertext under the base64
+// compact public key recipient.
+func (s *Uint8Service) ReencryptFor(ctx context.Context, ctBase64, recipient string) (string, error) {
+	return base64Reencrypt(ctx, ctBase64, recipient, CurrentLimits().MaxUint8Ciphertext, s.ReencryptForRaw)
+}
+
+// ReencryptBoolFor re-encrypts a base64 FheBool under the base64 compact
+// public key recipient.
+func (s *Uint8Service) ReencryptBoolFor(ctx context.Context, ctBase64, recipient string) (string, error) {
+	return base64Reencrypt(ctx, ctBase64, recipient, CurrentLimits().MaxFheBoolCiphertext, s.ReencryptBoolForRaw)
+}
+
+// ReencryptFor re-encrypts a base64 ciphertext under the base64 compact
+// public key recipient.
+func (s *IntService) ReencryptFor(ctx context.Context, ctBase64, recipient string) (string, error) {
+	return base64Reencrypt(ctx, ctBase64, recipient, CurrentLimits().MaxIntCiphertext(s.bits), s.ReencryptForRaw)
+}
+
+// ReencryptBoolFor re-encrypts a base64 FheBool, such as a comparison
+// result, under the base64 compact public key recipient.
+func (s *IntService) ReencryptBoolFor(ctx context.Context, ctBase64, recipient string) (string, error) {
+	return s.keys.ReencryptBoolFor(ctx, ctBase64, recipient)
+}
+
+// ReencryptForRaw re-encrypts a serialized uint8 ciphertext under the
+// serialized compact public key recipient.
+func (s *Uint8Service) ReencryptForRaw(ctx context.Context, data, recipient []byte) (out []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := checkReencrypt(s.withheld); err != nil {
+		return nil, err
+	}
+	ctx, end := begin(ctx, s.metrics, "uint8.reencrypt_for", &out, &err)
+	defer end()
+
+	pub, err := recipientKey(ctx, recipient)
+	if err != nil {
+		return nil, err
+	}
+	defer pub.Close()
+	ct, release, err := deserializeOperand(ctx, "uint8", data, Uint8Deserialize)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	value, err := native(ctx, phaseCompute, "uint8.decrypt", func() (uint8, error) { return DecryptUint8(s.client, ct) })
+	if err != nil {
+		return nil, err
+	}
+	res, err := native(ctx, phaseCompute, "uint8.encrypt_compact", func() (*Uint8Ciphertext, error) { return EncryptUint8Compact(pub, value) })
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return native(ctx, phaseSerialize, "uint8.serialize", res.Uint8Serialize)
+}
+
+// ReencryptBoolForRaw re-encrypts a serialized FheBool under the serialized
+// compact public key recipient.
+func (s *Uint8Service) ReencryptBoolForRaw(ctx context.Context, data, recipient []byte) (out []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if err := checkReencrypt(s.withheld); err != nil {
+		return nil, err
+	}
+	ctx, end := begin(ctx, s.metrics, "bool.reencrypt_for", &out, &err)
+	defer end()
+
+	pub, err := recipientKey(ctx, recipient)
+	if err != nil {
+		return nil, err
+	}
+	defer pub.Close()
+	ct, release, err := deserializeOperand(ctx, "bool", data, DeserializeFheBool)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	value, err := native(ctx, phaseCompute, "bool.decrypt", func() (bool, error) { return DecryptFheBool(s.client, ct) })
+	if err != nil {
+		return nil, err
+	}
+	res, err := native(ctx, phaseCompute, "bool.encrypt_compact", func() (*FheBool, error) { return EncryptFheBoolCompact(pub, value) })
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return native(ctx, phaseSerialize, "bool.serialize", res.Serialize)
+}
+
+// ReencryptForRaw re-encrypts a serialized ciphertext under the serialized
+// compact public key recipient.
+func (s *IntService) ReencryptForRaw(ctx context.Context, data, recipient []byte) (out []byte, err error) {
+	s.keys.mu.RLock()
+	defer s.keys.mu.RUnlock()
+	if err := checkReencrypt(s.keys.withheld); err != nil {
+		return nil, err
+	}
+	ctx, end := begin(ctx, s.keys.metrics, s.name+".reencrypt_for", &out, &err)
+	defer end()
+
+	pub, err := recipientKey(ctx, recipient)
+	if err != nil {
+		return nil, err
+	}
+	defer pub.Close()
+	ct, release, err := deserializeOperand(ctx, s.name, data, s.deserialize)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	value, err := native(ctx, phaseCompute, s.name+".decrypt", func() (uint64, error) { return DecryptInt(s.keys.client, ct) })
+	if err != nil {
+		return nil, err
+	}
+	res, err := native(ctx, phaseCompute, s.name+".encrypt_compact", func() (*IntCiphertext, error) { return EncryptIntCompact(pub, s.bits, value) })
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return native(ctx, phaseSerialize, s.name+".serialize", res.Serialize)
+}
+
+// checkReencrypt refuses re-encryption for a recipient unless it is enabled
+// and the service holds its client key.
+func checkReencrypt(withheld bool) error {
+	if !trustedReencrypt.Load() {
+		return errTrustedReencryptOff
+	}
+	if withheld {
+		return errClientKeyWithheld
+	}
+	return nil
+}
+
+func recipientKey(ctx context.Context, recipient []byte) (*Uint8CompactPublicKey, error) {
+	return native(ctx, phaseDeserialize, "compact_public_key.deserialize", func() (*Uint8CompactPublicKey, error) {
+		return DeserializeUint8CompactPublicKey(recipient)
+	})
+}
+
+func base64Reencrypt(ctx context.Context, ctBase64, recipientBase64 string, maxLen int, op rawBinaryFn) (string, error) {
+	recipient, err := decodeRecipient(recipientBase64)
+	if err != nil {
+		return "", err
+	}
+	ct, err := decodeBase64(ctBase64, maxLen)
+	if err != nil {
+		return "", err
+	}
+	defer bufpool.Put(ct)
+	out, err := op(ctx, *ct, recipient)
+	if err != nil {
+		return "", err
+	}
+	return bufpool.EncodeBase64(out), nil
+}
+
+func decodeRecipient(keyBase64 string) ([]byte, error) {
+	if keyBase64 == "" {
+		return nil, fmt.Errorf("%w: recipient key is empty", ErrInvalidKey)
+	}
+	if base64.StdEncoding.DecodedLen(len(keyBase64)) > maxRecipientKey {
+		return nil, fmt.Errorf("%w: recipient key exceeds %d bytes", ErrInvalidKey, maxRecipientKey)
+	}
+	data, err := base64.StdEncoding.DecodeString(keyBase64)
+	if err != nil {
+		return nil, invalidKey(err)
+	}
+	return data, nil
+}
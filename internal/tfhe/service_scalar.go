@@ -0,0 +1,76 @@
+package tfhe
+
+import "context"
+
+// CompareScalar compares a base64 uint8 ciphertext with a plaintext
+// constant and returns a base64 FheBool.
+func (s *Uint8Service) CompareScalar(ctx context.Context, cmp Comparison, lhs string, rhs uint8) (string, error) {
+	return base64Unary(ctx, lhs, CurrentLimits().MaxUint8Ciphertext, func(ctx context.Context, lhs []byte) ([]byte, error) {
+		return s.CompareScalarRaw(ctx, cmp, lhs, rhs)
+	})
+}
+
+// CompareScalarRaw compares a serialized uint8 ciphertext with a plaintext
+// constant and returns a serialized FheBool.
+func (s *Uint8Service) CompareScalarRaw(ctx context.Context, cmp Comparison, lhsRaw []byte, rhs uint8) ([]byte, error) {
+	name := "uint8.scalar_" + string(cmp)
+	lhsRaw = detach(lhsRaw)
+	return bounded(ctx, name, func(ctx context.Context) (out []byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, name, &out, &err)
+		defer end()
+
+		return compareScalarSerialized(ctx, "uint8", name, lhsRaw, Uint8Deserialize, func(lhs *Uint8Ciphertext) (*FheBool, error) {
+			return s.server.CompareScalar(cmp, lhs, rhs)
+		})
+	})
+}
+
+// CompareScalar compares a base64 ciphertext with a plaintext constant,
+// which must fit the width, and returns a base64 FheBool.
+func (s *IntService) CompareScalar(ctx context.Context, cmp Comparison, lhs string, rhs uint64) (string, error) {
+	return base64Unary(ctx, lhs, CurrentLimits().MaxIntCiphertext(s.bits), func(ctx context.Context, lhs []byte) ([]byte, error) {
+		return s.CompareScalarRaw(ctx, cmp, lhs, rhs)
+	})
+}
+
+// CompareScalarRaw compares a serialized ciphertext with a plaintext
+// constant, which must fit the width, and returns a serialized FheBool.
+func (s *IntService) CompareScalarRaw(ctx context.Context, cmp Comparison, lhsRaw []byte, rhs uint64) ([]byte, error) {
+	if err := checkIntValue(s.bits, rhs); err != nil {
+		return nil, err
+	}
+	name := s.name + ".scalar_" + string(cmp)
+	lhsRaw = detach(lhsRaw)
+	return bounded(ctx, name, func(ctx context.Context) (out []byte, err error) {
+		s.keys.mu.RLock()
+		defer s.keys.mu.RUnlock()
+		ctx, end := begin(ctx, s.keys.metrics, name, &out, &err)
+		defer end()
+
+		return compareScalarSerialized(ctx, s.name, name, lhsRaw, s.deserialize, func(lhs *IntCiphertext) (*FheBool, error) {
+			return s.keys.server.IntCompareScalar(cmp, lhs, rhs)
+		})
+	})
+}
+
+// compareScalarSerialized deserializes an operand of type typ, compares it
+// with a constant and serializes the resulting FheBool.
+func compareScalarSerialized[T interface{ Close() error }](ctx context.Context, typ, name string, lhsRaw []byte, deserialize func([]byte) (T, error), cmp func(lhs T) (*FheBool, error)) ([]byte, error) {
+	lhs, release, err := deserializeOperand(ctx, typ, lhsRaw, deserialize)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	res, err := native(ctx, phaseCompute, name, func() (*FheBool, error) { return cmp(lhs) })
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	if err := checkResult(name, res); err != nil {
+		return nil, err
+	}
+	return native(ctx, phaseSerialize, "bool.serialize", res.Serialize)
+}
@@ -0,0 +1,22 @@
+package tfhe
+
+import "context"
+
+// Shift shifts or rotates a base64 uint8 ciphertext by the encrypted amount
+// in a second one.
+func (s *Uint8Service) Shift(ctx context.Context, op Shift, value, amount string) (string, error) {
+	return base64Binary(ctx, value, amount, CurrentLimits().MaxUint8Ciphertext, func(ctx context.Context, value, amount []byte) ([]byte, error) {
+		return s.ShiftRaw(ctx, op, value, amount)
+	})
+}
+
+// ShiftRaw shifts or rotates a serialized uint8 ciphertext by the encrypted
+// amount in a second one.
+func (s *Uint8Service) ShiftRaw(ctx context.Context, op Shift, value, amount []byte) ([]byte, error) {
+	if err := checkShift(op); err != nil {
+		return nil, err
+	}
+	return s.binaryUint8(ctx, "uint8."+string(op), value, amount, func(sk *Uint8ServerKey, value, amount *Uint8Ciphertext) (*Uint8Ciphertext, error) {
+		return sk.Shift(op, value, amount)
+	})
+}
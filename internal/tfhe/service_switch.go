@@ -0,0 +1,70 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+)
+
+// Proxy re-encryption: a tenant uploads a switch key from the service's
+// boolean client key to a key of its own, and results are switched under it
+// before delivery, so the server never decrypts them. Switch keys are held
+// in memory only and dropped when the keys rotate, since they no longer
+// match; tenants then upload new ones.
+
+// SetSwitchKey installs tenant's serialized switch key, replacing any
+// earlier one; nil data removes it.
+func (s *BooleanService) SetSwitchKey(tenant string, data []byte) error {
+	var k *SwitchKey
+	if data != nil {
+		var err error
+		if k, err = DeserializeSwitchKey(data); err != nil {
+			return err
+		}
+	}
+	s.mu.Lock()
+	old := s.switchKeys[tenant]
+	if k == nil {
+		delete(s.switchKeys, tenant)
+	} else {
+		if s.switchKeys == nil {
+			s.switchKeys = make(map[string]*SwitchKey)
+		}
+		s.switchKeys[tenant] = k
+	}
+	s.mu.Unlock()
+	return old.Close()
+}
+
+// ReencryptRaw switches a serialized ciphertext under the service's key to
+// the key tenant's switch key targets.
+func (s *BooleanService) ReencryptRaw(ctx context.Context, tenant string, data []byte) (out []byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ctx, end := begin(ctx, s.metrics, "boolean.reencrypt", &out, &err)
+	defer end()
+
+	k, ok := s.switchKeys[tenant]
+	if !ok {
+		return nil, &kindError{msg: fmt.Sprintf("no switch key uploaded for tenant %q", tenant), kind: ErrSwitchKeyNotSet}
+	}
+	ct, release, err := deserializeOperand(ctx, "boolean", data, DeserializeCiphertext)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	res, err := native(ctx, phaseCompute, "boolean.reencrypt", func() (*Ciphertext, error) { return k.Switch(ct) })
+	if err != nil {
+		return nil, err
+	}
+	defer res.Close()
+	return native(ctx, phaseSerialize, "boolean.serialize", res.Serialize)
+}
+
+// dropSwitchKeys releases every switch key. s.mu must be held for writing.
+func (s *BooleanService) dropSwitchKeys() {
+	for _, k := range s.switchKeys {
+		_ = k.Close()
+	}
+	s.switchKeys = nil
+}
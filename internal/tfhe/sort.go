@@ -0,0 +1,185 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// oddEvenMergeNetwork returns the comparators of Batcher's odd-even merge
+// sort over n positions, grouped into layers whose comparators touch
+// disjoint positions. Each comparator (i, j), i < j, orders positions i and
+// j. For n not a power of two it is the network for the next power of two
+// with the comparators reaching past n dropped, which is as if the input
+// were padded with maximal values.
+func oddEvenMergeNetwork(n int) [][][2]int {
+	var layers [][][2]int
+	for p := 1; p < n; p <<= 1 {
+		for k := p; k >= 1; k >>= 1 {
+			var layer [][2]int
+			for j := k % p; j+k < n; j += 2 * k {
+				for i := 0; i < min(k, n-j-k); i++ {
+					if (i+j)/(2*p) == (i+j+k)/(2*p) {
+						layer = append(layer, [2]int{i + j, i + j + k})
+					}
+				}
+			}
+			if len(layer) > 0 {
+				layers = append(layers, layer)
+			}
+		}
+	}
+	return layers
+}
+
+// parallel calls fn for 0 through n-1 on up to workers goroutines and
+// returns the first error by index. A panic in fn, i.e. in native code, is
+// recovered as ErrNativePanic attributed to op.
+func parallel(n, workers int, op string, fn func(i int) error) error {
+	errs := make([]error, n)
+	sem := make(chan struct{}, max(workers, 1))
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			defer recoverNative(op, &errs[i])
+			errs[i] = fn(i)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SortEncrypted returns values sorted in ascending order, or descending
+// with descending set, without decrypting them. It evaluates Batcher's
+// odd-even merge sorting network: O(n log² n) compare-exchanges, each one
+// comparison and two selections, with the comparators of each layer spread
+// across up to workers goroutines. The network is fixed by len(values), so
+// the evaluation reveals nothing about the order. values are neither
+// modified nor closed; the results are new ciphertexts the caller must
+// close. On error no results are returned.
+func (sk *Uint8ServerKey) SortEncrypted(values []*Uint8Ciphertext, descending bool, workers int) ([]*Uint8Ciphertext, error) {
+	out := slices.Clone(values)
+	owned := make([]bool, len(out))
+	release := func() {
+		for i, ct := range out {
+			if owned[i] {
+				_ = ct.Close()
+			}
+		}
+	}
+
+	// swapIf is true when the value at j belongs before the one at i.
+	swapIf := CompareLt
+	if descending {
+		swapIf = CompareGt
+	}
+	exchange := func(i, j int) error {
+		swap, err := sk.Compare(swapIf, out[j], out[i])
+		if err != nil {
+			return err
+		}
+		defer swap.Close()
+		first, err := sk.IfThenElse(swap, out[j], out[i])
+		if err != nil {
+			return err
+		}
+		second, err := sk.IfThenElse(swap, out[i], out[j])
+		if err != nil {
+			_ = first.Close()
+			return err
+		}
+		for _, k := range [2]int{i, j} {
+			if owned[k] {
+				_ = out[k].Close()
+			}
+		}
+		out[i], out[j] = first, second
+		owned[i], owned[j] = true, true
+		return nil
+	}
+
+	for _, layer := range oddEvenMergeNetwork(len(out)) {
+		if err := parallel(len(layer), workers, "uint8.sort", func(c int) error {
+			return exchange(layer[c][0], layer[c][1])
+		}); err != nil {
+			release()
+			return nil, err
+		}
+	}
+
+	// Fewer than two values meet no comparator; copy them so the caller
+	// owns every result.
+	for i, ct := range out {
+		if owned[i] {
+			continue
+		}
+		data, err := ct.Uint8Serialize()
+		if err == nil {
+			out[i], err = Uint8Deserialize(data)
+		}
+		if err != nil {
+			release()
+			return nil, err
+		}
+		owned[i] = true
+	}
+	return out, nil
+}
+
+// SortRaw sorts serialized uint8 ciphertexts with SortEncrypted and returns
+// the serialized results in order.
+func (s *Uint8Service) SortRaw(ctx context.Context, values [][]byte, descending bool, workers int) ([][]byte, error) {
+	if opBudget.Load() > 0 {
+		owned := make([][]byte, len(values))
+		for i, v := range values {
+			owned[i] = detach(v)
+		}
+		values = owned
+	}
+	return bounded(ctx, "uint8.sort", func(ctx context.Context) (out [][]byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint8.sort", nil, &err)
+		defer end()
+
+		operands := make([]*Uint8Ciphertext, len(values))
+		for i, v := range values {
+			ct, release, err := deserializeOperand(ctx, "uint8", v, Uint8Deserialize)
+			if err != nil {
+				return nil, fmt.Errorf("value %d: %w", i, err)
+			}
+			defer release()
+			operands[i] = ct
+		}
+
+		res, err := nativeFanout(ctx, phaseCompute, "uint8.sort", workers, func(workers int) ([]*Uint8Ciphertext, error) {
+			return s.server.SortEncrypted(operands, descending, workers)
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for _, ct := range res {
+				_ = ct.Close()
+			}
+		}()
+
+		out = make([][]byte, len(res))
+		for i, ct := range res {
+			if out[i], err = native(ctx, phaseSerialize, "uint8.serialize", ct.Uint8Serialize); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	})
+}
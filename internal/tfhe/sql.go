@@ -0,0 +1,88 @@
+package tfhe
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// The ciphertext types implement driver.Valuer and sql.Scanner, storing
+// their serialized bytes, so encrypted columns can be read and written with
+// database/sql directly. A nil ciphertext is stored as NULL. Scan into a
+// **Ciphertext (or the other types) to read nullable columns: database/sql
+// then sets NULL to nil and allocates a ciphertext otherwise, which also
+// meets UnmarshalBinary's allocation requirement.
+
+var (
+	_ driver.Valuer = (*Ciphertext)(nil)
+	_ sql.Scanner   = (*Ciphertext)(nil)
+	_ driver.Valuer = (*Uint8Ciphertext)(nil)
+	_ sql.Scanner   = (*Uint8Ciphertext)(nil)
+	_ driver.Valuer = (*FheBool)(nil)
+	_ sql.Scanner   = (*FheBool)(nil)
+)
+
+// scanBytes returns the serialized bytes held by a column value.
+func scanBytes(src any, typ string) ([]byte, error) {
+	switch v := src.(type) {
+	case []byte:
+		return v, nil
+	case string:
+		return []byte(v), nil
+	case nil:
+		return nil, fmt.Errorf("cannot scan NULL into %s; scan into a pointer to a pointer instead", typ)
+	default:
+		return nil, fmt.Errorf("cannot scan %T into %s", src, typ)
+	}
+}
+
+// Value returns the serialized ciphertext, or NULL for a nil c.
+func (c *Ciphertext) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return c.Serialize()
+}
+
+// Scan deserializes a column value into c.
+func (c *Ciphertext) Scan(src any) error {
+	data, err := scanBytes(src, "boolean ciphertext")
+	if err != nil {
+		return err
+	}
+	return c.UnmarshalBinary(data)
+}
+
+// Value returns the serialized ciphertext, or NULL for a nil c.
+func (c *Uint8Ciphertext) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return c.Uint8Serialize()
+}
+
+// Scan deserializes a column value into c.
+func (c *Uint8Ciphertext) Scan(src any) error {
+	data, err := scanBytes(src, "uint8 ciphertext")
+	if err != nil {
+		return err
+	}
+	return c.UnmarshalBinary(data)
+}
+
+// Value returns the serialized ciphertext, or NULL for a nil c.
+func (c *FheBool) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return c.Serialize()
+}
+
+// Scan deserializes a column value into c.
+func (c *FheBool) Scan(src any) error {
+	data, err := scanBytes(src, "bool ciphertext")
+	if err != nil {
+		return err
+	}
+	return c.UnmarshalBinary(data)
+}
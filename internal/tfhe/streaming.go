@@ -0,0 +1,161 @@
+package tfhe
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"tfhe-go/internal/bufpool"
+)
+
+// SerializeTo and DeserializeFrom move ciphertexts and keys to and from a
+// stream, for blobs such as server keys that should not be held in Go memory
+// twice. The native serializers work on whole buffers, so the serialized form
+// still exists once on the C side; SerializeTo writes it to w in place, and
+// DeserializeFrom reads into a pooled buffer that is reused across calls.
+
+// errTooLarge is wrapped with the sentinel of the object being read.
+var errTooLarge = errors.New("stream exceeds size limit")
+
+// readBounded reads all of r into a pooled buffer, failing with a kind
+// error once more than maxSize bytes arrive. maxSize <= 0 imposes no limit.
+// The caller returns the buffer with bufpool.Put.
+func readBounded(r io.Reader, maxSize int, kind error) (*[]byte, error) {
+	hint := 0
+	if f, ok := r.(*os.File); ok {
+		if st, err := f.Stat(); err == nil && st.Mode().IsRegular() {
+			hint = int(st.Size())
+		}
+	}
+	if maxSize > 0 {
+		if hint > maxSize {
+			return nil, fmt.Errorf("%w: %v: %d bytes exceeds %d", kind, errTooLarge, hint, maxSize)
+		}
+		r = io.LimitReader(r, int64(maxSize)+1)
+	}
+	buf := bufpool.Get(hint + 512)
+	b := (*buf)[:0]
+	for {
+		if len(b) == cap(b) {
+			b = append(b, 0)[:len(b)]
+		}
+		n, err := r.Read(b[len(b):cap(b)])
+		b = b[:len(b)+n]
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			*buf = b
+			bufpool.Put(buf)
+			return nil, err
+		}
+	}
+	*buf = b
+	if maxSize > 0 && len(b) > maxSize {
+		bufpool.Put(buf)
+		return nil, fmt.Errorf("%w: %v: more than %d bytes", kind, errTooLarge, maxSize)
+	}
+	return buf, nil
+}
+
+// deserializeFrom reads r with readBounded and passes the bytes to unmarshal.
+func deserializeFrom(r io.Reader, maxSize int, kind error, unmarshal func([]byte) error) error {
+	buf, err := readBounded(r, maxSize, kind)
+	if err != nil {
+		return err
+	}
+	defer bufpool.Put(buf)
+	return unmarshal(*buf)
+}
+
+// SerializeTo writes the serialized ciphertext to w.
+func (c *Ciphertext) SerializeTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// DeserializeFrom reads a serialized ciphertext of at most maxSize bytes
+// from r into c, replacing what c held as UnmarshalBinary does.
+func (c *Ciphertext) DeserializeFrom(r io.Reader, maxSize int) error {
+	return deserializeFrom(r, maxSize, ErrCiphertextTooLarge, c.UnmarshalBinary)
+}
+
+// SerializeTo writes the serialized ciphertext to w.
+func (c *Uint8Ciphertext) SerializeTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// DeserializeFrom reads a serialized ciphertext of at most maxSize bytes
+// from r into c, replacing what c held as UnmarshalBinary does.
+func (c *Uint8Ciphertext) DeserializeFrom(r io.Reader, maxSize int) error {
+	return deserializeFrom(r, maxSize, ErrCiphertextTooLarge, c.UnmarshalBinary)
+}
+
+// SerializeTo writes the serialized ciphertext to w.
+func (c *FheBool) SerializeTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// DeserializeFrom reads a serialized ciphertext of at most maxSize bytes
+// from r into c, replacing what c held as UnmarshalBinary does.
+func (c *FheBool) DeserializeFrom(r io.Reader, maxSize int) error {
+	return deserializeFrom(r, maxSize, ErrCiphertextTooLarge, c.UnmarshalBinary)
+}
+
+// SerializeTo writes the serialized ciphertext to w.
+func (c *IntCiphertext) SerializeTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// DeserializeIntFrom reads a serialized bits-wide ciphertext of at most
+// maxSize bytes from r.
+func DeserializeIntFrom(bits int, r io.Reader, maxSize int) (*IntCiphertext, error) {
+	buf, err := readBounded(r, maxSize, ErrCiphertextTooLarge)
+	if err != nil {
+		return nil, err
+	}
+	defer bufpool.Put(buf)
+	return DeserializeInt(bits, *buf)
+}
+
+// SerializeTo writes the serialized key to w.
+func (c *ClientKey) SerializeTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// DeserializeFrom reads a serialized key of at most maxSize bytes from r
+// into c, replacing what c held as UnmarshalBinary does.
+func (c *ClientKey) DeserializeFrom(r io.Reader, maxSize int) error {
+	return deserializeFrom(r, maxSize, ErrInvalidKey, c.UnmarshalBinary)
+}
+
+// SerializeTo writes the serialized key to w.
+func (s *ServerKey) SerializeTo(w io.Writer) (int64, error) { return writeView(s.WithBytes, w) }
+
+// DeserializeFrom reads a serialized key of at most maxSize bytes from r
+// into s, replacing what s held as UnmarshalBinary does.
+func (s *ServerKey) DeserializeFrom(r io.Reader, maxSize int) error {
+	return deserializeFrom(r, maxSize, ErrInvalidKey, s.UnmarshalBinary)
+}
+
+// SerializeTo writes the serialized key to w.
+func (c *Uint8ClientKey) SerializeTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// DeserializeFrom reads a serialized key of at most maxSize bytes from r
+// into c, replacing what c held as UnmarshalBinary does.
+func (c *Uint8ClientKey) DeserializeFrom(r io.Reader, maxSize int) error {
+	return deserializeFrom(r, maxSize, ErrInvalidKey, c.UnmarshalBinary)
+}
+
+// SerializeTo writes the serialized key to w.
+func (s *Uint8ServerKey) SerializeTo(w io.Writer) (int64, error) { return writeView(s.WithBytes, w) }
+
+// DeserializeFrom reads a serialized key of at most maxSize bytes from r
+// into s, replacing what s held as UnmarshalBinary does.
+func (s *Uint8ServerKey) DeserializeFrom(r io.Reader, maxSize int) error {
+	return deserializeFrom(r, maxSize, ErrInvalidKey, s.UnmarshalBinary)
+}
+
+// SerializeTo writes the serialized key to w.
+func (p *Uint8PublicKey) SerializeTo(w io.Writer) (int64, error) { return writeView(p.WithBytes, w) }
+
+// DeserializeFrom reads a serialized key of at most maxSize bytes from r
+// into p, replacing what p held as UnmarshalBinary does.
+func (p *Uint8PublicKey) DeserializeFrom(r io.Reader, maxSize int) error {
+	return deserializeFrom(r, maxSize, ErrInvalidKey, p.UnmarshalBinary)
+}
+
+// SerializeTo writes the serialized key to w.
+func (p *Uint8CompactPublicKey) SerializeTo(w io.Writer) (int64, error) {
+	return writeView(p.WithBytes, w)
+}
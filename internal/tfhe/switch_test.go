@@ -0,0 +1,61 @@
+//go:build purego
+
+package tfhe_test
+
+import (
+	"testing"
+
+	"tfhe-go/internal/tfhe"
+)
+
+// TestSwitchKey checks that a ciphertext switched from one client key to
+// another decrypts to the same value under the target key, and still goes
+// through gates under the target's server key.
+func TestSwitchKey(t *testing.T) {
+	fromClient, fromServer, err := tfhe.GenerateBooleanKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer fromClient.Close()
+	defer fromServer.Close()
+	toClient, toServer, err := tfhe.GenerateBooleanKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer toClient.Close()
+	defer toServer.Close()
+
+	generated, err := tfhe.GenerateSwitchKey(fromClient, toClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer generated.Close()
+	data, err := generated.Serialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := tfhe.DeserializeSwitchKey(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer loaded.Close()
+
+	for name, k := range map[string]*tfhe.SwitchKey{"generated": generated, "deserialized": loaded} {
+		t.Run(name, func(t *testing.T) {
+			check(t, func(a, b bool) bool {
+				x := must(tfhe.EncryptBool(fromClient, a))
+				defer x.Close()
+				switched := must(k.Switch(x))
+				defer switched.Close()
+				if must(tfhe.DecryptBool(toClient, switched)) != a {
+					return false
+				}
+				y := must(tfhe.EncryptBool(toClient, b))
+				defer y.Close()
+				and := must(toServer.And(switched, y))
+				defer and.Close()
+				return must(tfhe.DecryptBool(toClient, and)) == (a && b)
+			})
+		})
+	}
+}
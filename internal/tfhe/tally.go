@@ -0,0 +1,197 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+)
+
+// tallyBits is the width of the encrypted vote tallies.
+const tallyBits = 32
+
+// TallyBallot adds a ballot, one encrypted choice per candidate, to the
+// encrypted uint32 tallies and returns the new tallies. A ballot counts
+// only if exactly one choice is true; otherwise it adds zero everywhere.
+// The check runs on ciphertexts, so neither the choice nor whether the
+// ballot was valid is revealed, and a voter cannot stuff several votes
+// into one ballot. The constants it needs are encrypted under pub. tallies
+// and ballot are neither modified nor closed; the results are new
+// ciphertexts the caller must close.
+func (sk *Uint8ServerKey) TallyBallot(pub *Uint8PublicKey, tallies []*IntCiphertext, ballot []*FheBool, workers int) ([]*IntCiphertext, error) {
+	if len(ballot) != len(tallies) {
+		return nil, fmt.Errorf("%w: ballot has %d choices for %d candidates", ErrValueOutOfRange, len(ballot), len(tallies))
+	}
+	for i, t := range tallies {
+		if t.Bits() != tallyBits {
+			return nil, fmt.Errorf("%w: tally %d is uint%d, want uint%d", ErrInvalidCiphertext, i, t.Bits(), tallyBits)
+		}
+	}
+	var arena Arena
+	defer arena.Close()
+	zero, err := EncryptIntPublic(pub, tallyBits, 0)
+	if err != nil {
+		return nil, err
+	}
+	arena.Track(zero)
+	one, err := EncryptIntPublic(pub, tallyBits, 1)
+	if err != nil {
+		return nil, err
+	}
+	arena.Track(one)
+
+	// Count the true choices.
+	marks := make([]*IntCiphertext, len(ballot))
+	err = parallel(len(ballot), workers, "uint8.tally", func(i int) (err error) {
+		marks[i], err = sk.IntIfThenElse(ballot[i], one, zero)
+		return err
+	})
+	TrackAll(&arena, marks)
+	if err != nil {
+		return nil, err
+	}
+	count := zero
+	for _, m := range marks {
+		if count, err = sk.IntAdd(count, m); err != nil {
+			return nil, err
+		}
+		arena.Track(count)
+	}
+
+	// weight is an encrypted 1 for a valid ballot and 0 otherwise.
+	valid, err := sk.IntCompare(CompareEq, count, one)
+	if err != nil {
+		return nil, err
+	}
+	arena.Track(valid)
+	weight, err := sk.IntIfThenElse(valid, one, zero)
+	if err != nil {
+		return nil, err
+	}
+	arena.Track(weight)
+
+	out := make([]*IntCiphertext, len(tallies))
+	err = parallel(len(tallies), workers, "uint8.tally", func(i int) error {
+		add, err := sk.IntIfThenElse(ballot[i], weight, zero)
+		if err != nil {
+			return err
+		}
+		defer add.Close()
+		out[i], err = sk.IntAdd(tallies[i], add)
+		return err
+	})
+	if err != nil {
+		TrackAll(&arena, out)
+		return nil, err
+	}
+	return out, nil
+}
+
+// NewTalliesRaw returns len serialized encrypted uint32 zeros, the tallies
+// of an election before any ballot.
+func (s *Uint8Service) NewTalliesRaw(ctx context.Context, n int) (out [][]byte, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ctx, end := begin(ctx, s.metrics, "uint8.tally_init", nil, &err)
+	defer end()
+	if s.withheld {
+		return nil, errClientKeyWithheld
+	}
+	zero, err := native(ctx, phaseCompute, "uint32.encrypt_public", func() (*IntCiphertext, error) { return EncryptIntPublic(s.public, tallyBits, 0) })
+	if err != nil {
+		return nil, err
+	}
+	defer zero.Close()
+	data, err := native(ctx, phaseSerialize, "uint32.serialize", zero.Serialize)
+	if err != nil {
+		return nil, err
+	}
+	// Each tally is rerandomized by its first ballot, so one encryption of
+	// zero may start them all.
+	out = make([][]byte, n)
+	for i := range out {
+		out[i] = data
+	}
+	return out, nil
+}
+
+// TallyBallotRaw adds a ballot of serialized FheBool choices to serialized
+// uint32 tallies with TallyBallot and returns the serialized new tallies.
+func (s *Uint8Service) TallyBallotRaw(ctx context.Context, tallies, ballot [][]byte, workers int) ([][]byte, error) {
+	if opBudget.Load() > 0 {
+		owned := make([][]byte, len(ballot))
+		for i, v := range ballot {
+			owned[i] = detach(v)
+		}
+		ballot = owned
+	}
+	return bounded(ctx, "uint8.tally", func(ctx context.Context) (out [][]byte, err error) {
+		s.mu.RLock()
+		defer s.mu.RUnlock()
+		ctx, end := begin(ctx, s.metrics, "uint8.tally", nil, &err)
+		defer end()
+		if s.withheld {
+			return nil, errClientKeyWithheld
+		}
+
+		current := make([]*IntCiphertext, len(tallies))
+		for i, data := range tallies {
+			ct, err := native(ctx, phaseDeserialize, "uint32.deserialize", func() (*IntCiphertext, error) { return DeserializeInt(tallyBits, data) })
+			if err != nil {
+				return nil, fmt.Errorf("tally %d: %w", i, err)
+			}
+			defer ct.Close()
+			current[i] = ct
+		}
+		choices := make([]*FheBool, len(ballot))
+		for i, data := range ballot {
+			ct, release, err := deserializeOperand(ctx, "bool", data, DeserializeFheBool)
+			if err != nil {
+				return nil, fmt.Errorf("choice %d: %w", i, err)
+			}
+			defer release()
+			choices[i] = ct
+		}
+
+		res, err := nativeFanout(ctx, phaseCompute, "uint8.tally", workers, func(workers int) ([]*IntCiphertext, error) {
+			return s.server.TallyBallot(s.public, current, choices, workers)
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			for _, ct := range res {
+				_ = ct.Close()
+			}
+		}()
+		out = make([][]byte, len(res))
+		for i, ct := range res {
+			if out[i], err = native(ctx, phaseSerialize, "uint32.serialize", ct.Serialize); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	})
+}
+
+// DecryptTalliesRaw decrypts serialized uint32 tallies.
+func (s *Uint8Service) DecryptTalliesRaw(ctx context.Context, tallies [][]byte) (out []uint64, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ctx, end := begin(ctx, s.metrics, "uint8.tally_decrypt", nil, &err)
+	defer end()
+	if s.withheld {
+		return nil, errClientKeyWithheld
+	}
+	out = make([]uint64, len(tallies))
+	for i, data := range tallies {
+		ct, err := native(ctx, phaseDeserialize, "uint32.deserialize", func() (*IntCiphertext, error) { return DeserializeInt(tallyBits, data) })
+		if err != nil {
+			return nil, fmt.Errorf("tally %d: %w", i, err)
+		}
+		out[i], err = native(ctx, phaseCompute, "uint32.decrypt", func() (uint64, error) { return DecryptInt(s.client, ct) })
+		_ = ct.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,89 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Timing accumulates the time the service operations run with a context
+// returned by WithTiming spend waiting for compute slots and in tfhe-c
+// calls, by phase, e.g. to report it to the caller of a request. Calls
+// running in parallel, as in batches, add up, so a phase can exceed the
+// wall time of the request.
+type Timing struct {
+	queue, deserialize, compute, serialize atomic.Int64
+}
+
+// phase is the part of a Timing a native call is charged to.
+type phase int
+
+const (
+	// phaseCompute is every call that is not a (de)serialization:
+	// evaluation, encryption, decryption and key generation.
+	phaseCompute phase = iota
+	phaseDeserialize
+	phaseSerialize
+)
+
+type timingKey struct{}
+
+// WithTiming returns a copy of ctx whose native calls are timed in t.
+func WithTiming(ctx context.Context, t *Timing) context.Context {
+	return context.WithValue(ctx, timingKey{}, t)
+}
+
+// Queue returns the time spent waiting for compute slots.
+func (t *Timing) Queue() time.Duration { return time.Duration(t.queue.Load()) }
+
+// Deserialize returns the time spent deserializing ciphertexts and keys.
+func (t *Timing) Deserialize() time.Duration { return time.Duration(t.deserialize.Load()) }
+
+// Compute returns the time spent in every other tfhe-c call: evaluation,
+// encryption and decryption.
+func (t *Timing) Compute() time.Duration { return time.Duration(t.compute.Load()) }
+
+// Serialize returns the time spent serializing results.
+func (t *Timing) Serialize() time.Duration { return time.Duration(t.serialize.Load()) }
+
+// ServerTiming formats the phases and total, the wall time of the request,
+// as the value of a Server-Timing header, in milliseconds.
+func (t *Timing) ServerTiming(total time.Duration) string {
+	var b strings.Builder
+	for i, m := range []struct {
+		name string
+		d    time.Duration
+	}{
+		{"queue", t.Queue()},
+		{"deserialize", t.Deserialize()},
+		{"compute", t.Compute()},
+		{"serialize", t.Serialize()},
+		{"total", total},
+	} {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		fmt.Fprintf(&b, "%s;dur=%.3f", m.name, float64(m.d)/float64(time.Millisecond))
+	}
+	return b.String()
+}
+
+// recordTiming charges a native call of phase p, which waited wait for a
+// slot and then ran for d, to the Timing of ctx.
+func recordTiming(ctx context.Context, p phase, wait, d time.Duration) {
+	t, ok := ctx.Value(timingKey{}).(*Timing)
+	if !ok {
+		return
+	}
+	t.queue.Add(int64(wait))
+	switch p {
+	case phaseDeserialize:
+		t.deserialize.Add(int64(d))
+	case phaseSerialize:
+		t.serialize.Add(int64(d))
+	default:
+		t.compute.Add(int64(d))
+	}
+}
@@ -0,0 +1,38 @@
+package tfhe
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Native calls are charged to the phase they are made with, whatever their
+// name.
+func TestTimingPhases(t *testing.T) {
+	var timing Timing
+	ctx := WithTiming(context.Background(), &timing)
+	for _, c := range []struct {
+		p    phase
+		name string
+	}{
+		{phaseDeserialize, "uint8.load"},
+		{phaseCompute, "uint8.serialize_like_name"},
+		{phaseSerialize, "bytes.out"},
+	} {
+		if _, err := native(ctx, c.p, c.name, func() (struct{}, error) {
+			time.Sleep(2 * time.Millisecond)
+			return struct{}{}, nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, d := range map[string]time.Duration{
+		"deserialize": timing.Deserialize(),
+		"compute":     timing.Compute(),
+		"serialize":   timing.Serialize(),
+	} {
+		if d < 2*time.Millisecond || d > time.Second {
+			t.Errorf("%s took %s, want one call's 2ms", name, d)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package tfhe
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer resolves the global provider lazily, so spans are exported once
+// the application installs one and are no-ops otherwise.
+var tracer = otel.Tracer("tfhe-go/internal/tfhe")
+
+// begin starts the span for a service operation and returns a function that
+// ends it and reports the measurement to m. out and err point at the
+// caller's named results so the function can be deferred.
+func begin(ctx context.Context, m Metrics, op string, out *[]byte, err *error) (context.Context, func()) {
+	start := time.Now()
+	inFlight.Add(1)
+	ctx, span := tracer.Start(ctx, op)
+	return ctx, func() {
+		inFlight.Add(-1)
+		elapsed := time.Since(start)
+		opNanos.Add(int64(elapsed))
+		recordUsage(ctx, elapsed)
+		size := 0
+		if out != nil {
+			size = len(*out)
+		}
+		m.ObserveOp(op, elapsed, size, *err)
+		span.SetAttributes(attribute.Int("tfhe.result_bytes", size))
+		endSpan(span, *err)
+	}
+}
+
+// native runs fn, a single tfhe-c call, in a child span of ctx once a
+// compute slot is free, and charges it to phase p of the Timing of ctx. A
+// panic in fn is returned as ErrNativePanic.
+func native[T any](ctx context.Context, p phase, name string, fn func() (T, error)) (T, error) {
+	return nativeFanout(ctx, p, name, 1, func(int) (T, error) { return fn() })
+}
+
+// nativeFanout is native for fn spreading tfhe-c calls across up to
+// workers goroutines. It holds a compute slot for each goroutine: one it
+// waits for, and as many more as are free at once, and passes fn how many
+// it holds, so the calls running never exceed the compute limit.
+func nativeFanout[T any](ctx context.Context, p phase, name string, workers int, fn func(workers int) (T, error)) (v T, err error) {
+	queued := time.Now()
+	workers, release, err := acquireSlots(ctx, name, max(workers, 1))
+	if err != nil {
+		recordTiming(ctx, p, time.Since(queued), 0)
+		return v, err
+	}
+	defer release()
+	_, span := tracer.Start(ctx, "tfhe_c."+name, trace.WithSpanKind(trace.SpanKindInternal))
+	start := time.Now()
+	defer func() {
+		elapsed := time.Since(start)
+		observeNative(name, elapsed)
+		recordTiming(ctx, p, start.Sub(queued), elapsed)
+		endSpan(span, err)
+	}()
+	defer recoverNative(name, &err)
+	return fn(workers)
+}
+
+// recoverNative, deferred around a tfhe-c call, turns a panic into an
+// ErrNativePanic stored in *err and logs the stack. tfhe-c itself catches
+// Rust panics and reports them as error codes; this covers the Go side of
+// the binding. An abort inside native code still ends the process.
+func recoverNative(name string, err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	nativePanics.Add(1)
+	log.Printf("tfhe: %s panicked: %v\n%s", name, r, debug.Stack())
+	*err = &kindError{msg: fmt.Sprintf("%s: native call panicked: %v", name, r), kind: ErrNativePanic}
+}
+
+var nativePanics atomic.Int64
+
+// NativePanics returns the number of tfhe-c calls that panicked since the
+// process started.
+func NativePanics() int64 {
+	return nativePanics.Load()
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+var inFlight atomic.Int64
+
+// InFlight returns the number of service operations currently running.
+func InFlight() int64 {
+	return inFlight.Load()
+}
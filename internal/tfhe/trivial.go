@@ -0,0 +1,80 @@
+package tfhe
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+)
+
+// A trivial encryption has a zero mask, so it carries its value in the clear:
+// anyone can read it without the client key. Fresh encryptions never are,
+// but evaluations over trivial operands yield trivial results, so
+// evaluations check what they return. Trivial results are counted and, with
+// SetRejectTrivial, refused. Not every backend can check every type: the
+// native one cannot tell for the boolean gate API, the pure Go one for the
+// integer types. Those results are counted apart as unchecked.
+
+var (
+	rejectTrivial    atomic.Bool
+	trivialResults   atomic.Int64
+	uncheckedResults atomic.Int64
+	uncheckedOnce    sync.Once
+)
+
+// SetRejectTrivial makes evaluations fail with ErrTrivialCiphertext instead
+// of returning a trivial encryption. Off, the default, such results are
+// returned and only counted. Results the backend cannot check pass either
+// way; they are counted by UncheckedResults and, with rejection on, the
+// first is logged.
+func SetRejectTrivial(on bool) { rejectTrivial.Store(on) }
+
+// TrivialResults returns the number of evaluations since the process started
+// whose result was a trivial encryption, refused or not.
+func TrivialResults() int64 { return trivialResults.Load() }
+
+// UncheckedResults returns the number of evaluations since the process
+// started whose result the backend could not check for a trivial
+// encryption, and so returned regardless of SetRejectTrivial.
+func UncheckedResults() int64 { return uncheckedResults.Load() }
+
+// trivialCiphertext is a ciphertext that can tell whether it is a trivial
+// encryption.
+type trivialCiphertext interface {
+	IsTrivial() (bool, error)
+}
+
+// trivialOf reports whether ct is a trivial encryption, or nil when the
+// backend cannot tell.
+func trivialOf(ct trivialCiphertext) *bool {
+	t, err := ct.IsTrivial()
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+// checkResult counts the result res of the evaluation name if it is a
+// trivial encryption and, under SetRejectTrivial, fails with
+// ErrTrivialCiphertext. Results the backend cannot check pass and are
+// counted as unchecked.
+func checkResult(name string, res trivialCiphertext) error {
+	t := trivialOf(res)
+	if t == nil {
+		uncheckedResults.Add(1)
+		if rejectTrivial.Load() {
+			uncheckedOnce.Do(func() {
+				log.Printf("tfhe: the %s backend cannot check %s results for trivial encryptions; they are returned despite reject-trivial", Backend, name)
+			})
+		}
+		return nil
+	}
+	if !*t {
+		return nil
+	}
+	trivialResults.Add(1)
+	if rejectTrivial.Load() {
+		return fmt.Errorf("%w: %s result would reveal its value", ErrTrivialCiphertext, name)
+	}
+	return nil
+}
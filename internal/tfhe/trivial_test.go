@@ -0,0 +1,105 @@
+//go:build purego
+
+package tfhe
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"testing"
+
+	"tfhe-go/internal/tfhe/purego"
+)
+
+// trivialBool returns the serialized trivial encryption of v: a fresh one
+// with its mask zeroed and its body set to the plain ±1/8.
+func trivialBool(t *testing.T, s *BooleanService, v bool) []byte {
+	t.Helper()
+	data, err := s.EncryptRaw(context.Background(), v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := len(data) - 4
+	clear(data[body-4*purego.DefaultParams.LWEDimension : body])
+	b := uint32(1 << 29)
+	if !v {
+		b = -b
+	}
+	binary.LittleEndian.PutUint32(data[body:], b)
+	return data
+}
+
+func setRejectTrivial(t *testing.T, on bool) {
+	t.Helper()
+	SetRejectTrivial(on)
+	t.Cleanup(func() { SetRejectTrivial(false) })
+}
+
+func TestRejectTrivial(t *testing.T) {
+	s, err := NewBooleanService()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	ctx := context.Background()
+	trivial := trivialBool(t, s, true)
+	fresh, err := s.EncryptRaw(ctx, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		reject  bool
+		input   []byte
+		err     error
+		counted int64
+	}{
+		{name: "fresh", input: fresh},
+		{name: "fresh rejecting", reject: true, input: fresh},
+		{name: "trivial", input: trivial, counted: 1},
+		{name: "trivial rejecting", reject: true, input: trivial, err: ErrTrivialCiphertext, counted: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			setRejectTrivial(t, tc.reject)
+			before := TrivialResults()
+			out, err := s.NotRaw(ctx, tc.input)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("got %v, want %v", err, tc.err)
+			}
+			if got := TrivialResults() - before; got != tc.counted {
+				t.Errorf("%d trivial results counted, want %d", got, tc.counted)
+			}
+			if err != nil {
+				return
+			}
+			if v, err := s.DecryptRaw(ctx, out); err != nil || v {
+				t.Errorf("not true decrypts to %v, %v", v, err)
+			}
+		})
+	}
+}
+
+// uncheckable is a result whose backend cannot tell if it is trivial.
+type uncheckable struct{}
+
+func (uncheckable) IsTrivial() (bool, error) {
+	return false, fmt.Errorf("is_trivial: %w", ErrUnsupported)
+}
+
+func TestUncheckedResults(t *testing.T) {
+	for _, reject := range []bool{false, true} {
+		setRejectTrivial(t, reject)
+		unchecked, trivial := UncheckedResults(), TrivialResults()
+		if err := checkResult("test.op", uncheckable{}); err != nil {
+			t.Fatalf("reject %v: %v", reject, err)
+		}
+		if got := UncheckedResults() - unchecked; got != 1 {
+			t.Errorf("reject %v: %d unchecked results counted, want 1", reject, got)
+		}
+		if TrivialResults() != trivial {
+			t.Errorf("reject %v: unchecked result counted as trivial", reject)
+		}
+	}
+}
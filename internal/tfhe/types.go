@@ -0,0 +1,136 @@
+package tfhe
+
+import "fmt"
+
+// This file holds the declarations shared by the native and pure-Go backends.
+
+// IntWidths lists the unsigned integer widths supported besides uint8. The
+// 2- and 4-bit types fit in one and two radix blocks, so they are several
+// times smaller and faster than uint8; they suit flags, enums and nibbles.
+var IntWidths = []int{2, 4, 16, 32, 64}
+
+func intObjectKind(bits int) objectKind {
+	switch bits {
+	case 2:
+		return objUint2Ciphertext
+	case 4:
+		return objUint4Ciphertext
+	case 16:
+		return objUint16Ciphertext
+	case 32:
+		return objUint32Ciphertext
+	default:
+		return objUint64Ciphertext
+	}
+}
+
+func checkBits(bits int) error {
+	switch bits {
+	case 2, 4, 16, 32, 64:
+		return nil
+	}
+	return fmt.Errorf("unsupported integer width %d", bits)
+}
+
+// checkIntValue rejects values that do not fit in bits.
+func checkIntValue(bits int, value uint64) error {
+	if bits < 64 && value>>bits != 0 {
+		return fmt.Errorf("%w: %d does not fit in uint%d", ErrValueOutOfRange, value, bits)
+	}
+	return nil
+}
+
+// Comparison identifies an integer comparison yielding an FheBool.
+type Comparison string
+
+const (
+	CompareEq Comparison = "eq"
+	CompareNe Comparison = "ne"
+	CompareLt Comparison = "lt"
+	CompareLe Comparison = "le"
+	CompareGt Comparison = "gt"
+	CompareGe Comparison = "ge"
+)
+
+// Comparisons lists the supported comparisons.
+var Comparisons = []Comparison{CompareEq, CompareNe, CompareLt, CompareLe, CompareGt, CompareGe}
+
+func checkComparison(cmp Comparison) error {
+	switch cmp {
+	case CompareEq, CompareNe, CompareLt, CompareLe, CompareGt, CompareGe:
+		return nil
+	}
+	return fmt.Errorf("unsupported comparison %q", cmp)
+}
+
+// BitCount identifies a bit-counting operation on a uint8, yielding an
+// encrypted uint32.
+type BitCount string
+
+const (
+	// BitCountOnes counts the set bits (popcount).
+	BitCountOnes BitCount = "count_ones"
+	// BitCountLeadingZeros counts the zero bits above the highest set bit.
+	BitCountLeadingZeros BitCount = "leading_zeros"
+	// BitCountIlog2 is the index of the highest set bit, floor(log2(x));
+	// it is meaningless for zero.
+	BitCountIlog2 BitCount = "ilog2"
+)
+
+// BitCounts lists the supported bit-counting operations.
+var BitCounts = []BitCount{BitCountOnes, BitCountLeadingZeros, BitCountIlog2}
+
+func checkBitCount(op BitCount) error {
+	switch op {
+	case BitCountOnes, BitCountLeadingZeros, BitCountIlog2:
+		return nil
+	}
+	return fmt.Errorf("unsupported bit count %q", op)
+}
+
+// Checksum identifies how ChecksumBytes folds a byte string into one byte.
+type Checksum string
+
+const (
+	// ChecksumXor is the XOR of all bytes, a parity byte.
+	ChecksumXor Checksum = "xor"
+	// ChecksumSum is the sum of all bytes modulo 256.
+	ChecksumSum Checksum = "sum"
+)
+
+// Checksums lists the supported checksums.
+var Checksums = []Checksum{ChecksumXor, ChecksumSum}
+
+func checkChecksum(kind Checksum) error {
+	switch kind {
+	case ChecksumXor, ChecksumSum:
+		return nil
+	}
+	return fmt.Errorf("unsupported checksum %q", kind)
+}
+
+// Shift identifies a shift or rotation of a uint8 by an encrypted amount.
+// Amounts wrap modulo 8, as in tfhe-rs.
+type Shift string
+
+const (
+	// ShiftLeft shifts towards the high bits, filling with zeros.
+	ShiftLeft Shift = "shl"
+	// ShiftRight shifts towards the low bits, filling with zeros.
+	ShiftRight Shift = "shr"
+	// RotateLeft rotates towards the high bits.
+	RotateLeft Shift = "rotate_left"
+	// RotateRight rotates towards the low bits.
+	RotateRight Shift = "rotate_right"
+)
+
+// Shifts lists the supported shifts and rotations.
+var Shifts = []Shift{ShiftLeft, ShiftRight, RotateLeft, RotateRight}
+
+func checkShift(op Shift) error {
+	switch op {
+	case ShiftLeft, ShiftRight, RotateLeft, RotateRight:
+		return nil
+	}
+	return fmt.Errorf("unsupported shift %q", op)
+}
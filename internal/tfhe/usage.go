@@ -0,0 +1,34 @@
+package tfhe
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Usage accumulates the service operations run with a context returned by
+// WithUsage, e.g. to charge them to the caller of a request.
+type Usage struct {
+	ops   atomic.Int64
+	nanos atomic.Int64
+}
+
+type usageKey struct{}
+
+// WithUsage returns a copy of ctx whose service operations are counted in u.
+func WithUsage(ctx context.Context, u *Usage) context.Context {
+	return context.WithValue(ctx, usageKey{}, u)
+}
+
+// Ops returns the number of operations counted.
+func (u *Usage) Ops() int64 { return u.ops.Load() }
+
+// Nanos returns the wall time of the operations counted.
+func (u *Usage) Nanos() int64 { return u.nanos.Load() }
+
+func recordUsage(ctx context.Context, d time.Duration) {
+	if u, ok := ctx.Value(usageKey{}).(*Usage); ok {
+		u.ops.Add(1)
+		u.nanos.Add(int64(d))
+	}
+}
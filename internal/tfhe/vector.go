@@ -0,0 +1,135 @@
+package tfhe
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// A vector serializes a sequence of ciphertexts as the magic "TFV1", the
+// element count and then each element's length and bytes, the integers as
+// uvarints. Elements are written and read one at a time, so a vector streams
+// in chunks without its serialized form being held whole.
+const vectorMagic = "TFV1"
+
+// WriteVector writes elems to w as a vector and returns the bytes written.
+func WriteVector(w io.Writer, elems [][]byte) (int64, error) {
+	var n int64
+	var prefix [binary.MaxVarintLen64]byte
+	write := func(p []byte) error {
+		m, err := w.Write(p)
+		n += int64(m)
+		return err
+	}
+	if err := write([]byte(vectorMagic)); err != nil {
+		return n, err
+	}
+	if err := write(binary.AppendUvarint(prefix[:0], uint64(len(elems)))); err != nil {
+		return n, err
+	}
+	for _, e := range elems {
+		if err := write(binary.AppendUvarint(prefix[:0], uint64(len(e)))); err != nil {
+			return n, err
+		}
+		if err := write(e); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// MarshalVector returns elems serialized as a vector.
+func MarshalVector(elems [][]byte) []byte {
+	var b bytes.Buffer
+	_, _ = WriteVector(&b, elems)
+	return b.Bytes()
+}
+
+// ReadVector reads a vector of at most maxElems elements of at most
+// maxElemSize bytes each from r, failing with ErrCiphertextTooLarge past
+// either bound and ErrInvalidCiphertext on malformed input.
+func ReadVector(r io.Reader, maxElems, maxElemSize int) ([][]byte, error) {
+	vr := &vectorReader{r: r}
+	if br, ok := r.(io.ByteReader); ok {
+		vr.br = br
+	} else {
+		buffered := bufio.NewReader(r)
+		vr.r, vr.br = buffered, buffered
+	}
+	magic := make([]byte, len(vectorMagic))
+	if _, err := io.ReadFull(vr, magic); err != nil || string(magic) != vectorMagic {
+		return nil, vr.fail("not a ciphertext vector")
+	}
+	count, err := binary.ReadUvarint(vr)
+	if err != nil {
+		return nil, vr.fail("malformed element count")
+	}
+	if count > uint64(maxElems) {
+		return nil, fmt.Errorf("%w: vector of %d elements exceeds limit of %d", ErrCiphertextTooLarge, count, maxElems)
+	}
+	elems := make([][]byte, count)
+	for i := range elems {
+		size, err := binary.ReadUvarint(vr)
+		if err != nil {
+			return nil, vr.fail(fmt.Sprintf("element %d: malformed length", i))
+		}
+		if size > uint64(maxElemSize) {
+			return nil, fmt.Errorf("%w: element %d of %d bytes exceeds limit of %d", ErrCiphertextTooLarge, i, size, maxElemSize)
+		}
+		elems[i] = make([]byte, size)
+		if _, err := io.ReadFull(vr, elems[i]); err != nil {
+			return nil, vr.fail(fmt.Sprintf("element %d: truncated", i))
+		}
+	}
+	return elems, nil
+}
+
+// UnmarshalVector parses data as a vector under the bounds of ReadVector,
+// rejecting trailing bytes.
+func UnmarshalVector(data []byte, maxElems, maxElemSize int) ([][]byte, error) {
+	r := bytes.NewReader(data)
+	elems, err := ReadVector(r, maxElems, maxElemSize)
+	if err != nil {
+		return nil, err
+	}
+	if r.Len() != 0 {
+		return nil, fmt.Errorf("%w: %d trailing bytes after vector", ErrInvalidCiphertext, r.Len())
+	}
+	return elems, nil
+}
+
+// vectorReader remembers the first error of its source other than its end,
+// so a failing source is not reported as a malformed vector.
+type vectorReader struct {
+	r   io.Reader
+	br  io.ByteReader
+	err error
+}
+
+func (v *vectorReader) Read(p []byte) (int, error) {
+	n, err := v.r.Read(p)
+	v.record(err)
+	return n, err
+}
+
+func (v *vectorReader) ReadByte() (byte, error) {
+	b, err := v.br.ReadByte()
+	v.record(err)
+	return b, err
+}
+
+func (v *vectorReader) record(err error) {
+	if err != nil && err != io.EOF && v.err == nil {
+		v.err = err
+	}
+}
+
+// fail returns the source's error or else ErrInvalidCiphertext with msg.
+func (v *vectorReader) fail(msg string) error {
+	if v.err != nil {
+		return v.err
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidCiphertext, msg)
+}
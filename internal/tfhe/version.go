@@ -0,0 +1,53 @@
+package tfhe
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// LibraryVersion is the tfhe-c release the binary links against. tfhe-c does
+// not report its version at run time, so it is stamped at build time with
+// -ldflags "-X tfhe-go/internal/tfhe.LibraryVersion=<version>"; the default
+// is the release scripts/build-tfhe.sh fetches.
+var LibraryVersion = "1.0.0"
+
+// VersionInfo describes the build, so clients can detect incompatibilities
+// before submitting ciphertexts.
+type VersionInfo struct {
+	// Module is the Go module version, "(devel)" for builds outside a
+	// tagged module download.
+	Module string `json:"module"`
+	// Revision is the VCS commit the binary was built from, when known.
+	Revision  string `json:"revision,omitempty"`
+	GoVersion string `json:"go_version"`
+	Backend   string `json:"backend"`
+	// Library is LibraryVersion for the native backend and empty otherwise.
+	Library string `json:"library_version,omitempty"`
+	// SerializationFormat names the encoding of serialized ciphertexts and
+	// keys; ciphertexts only deserialize under the same format.
+	SerializationFormat string `json:"serialization_format"`
+}
+
+// Version reports the build's versions.
+func Version() VersionInfo {
+	v := VersionInfo{
+		Module:              "(devel)",
+		GoVersion:           runtime.Version(),
+		Backend:             Backend,
+		SerializationFormat: serializationFormat(),
+	}
+	if Backend == "native" {
+		v.Library = LibraryVersion
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if bi.Main.Version != "" {
+			v.Module = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" {
+				v.Revision = s.Value
+			}
+		}
+	}
+	return v
+}
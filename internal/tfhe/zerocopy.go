@@ -0,0 +1,83 @@
+package tfhe
+
+import (
+	"encoding/base64"
+	"io"
+)
+
+// The ciphertext types' WithBytes lends out their serialized form without
+// copying it into Go memory. WriteTo, AppendBinary and AppendBase64 build on
+// it, so writing a ciphertext to a stream or encoding it as base64 costs no
+// intermediate copy.
+
+// viewer is a ciphertext's WithBytes method.
+type viewer func(fn func([]byte) error) error
+
+func writeView(view viewer, w io.Writer) (n int64, err error) {
+	err = view(func(data []byte) error {
+		m, err := w.Write(data)
+		n = int64(m)
+		return err
+	})
+	return n, err
+}
+
+func appendView(view viewer, b []byte) ([]byte, error) {
+	err := view(func(data []byte) error {
+		b = append(b, data...)
+		return nil
+	})
+	return b, err
+}
+
+func appendBase64View(view viewer, b []byte) ([]byte, error) {
+	err := view(func(data []byte) error {
+		b = base64.StdEncoding.AppendEncode(b, data)
+		return nil
+	})
+	return b, err
+}
+
+// WriteTo writes the serialized ciphertext to w.
+func (c *Ciphertext) WriteTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// AppendBinary appends the serialized ciphertext to b.
+func (c *Ciphertext) AppendBinary(b []byte) ([]byte, error) { return appendView(c.WithBytes, b) }
+
+// AppendBase64 appends the serialized ciphertext to b in standard base64.
+func (c *Ciphertext) AppendBase64(b []byte) ([]byte, error) {
+	return appendBase64View(c.WithBytes, b)
+}
+
+// WriteTo writes the serialized ciphertext to w.
+func (c *Uint8Ciphertext) WriteTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// AppendBinary appends the serialized ciphertext to b.
+func (c *Uint8Ciphertext) AppendBinary(b []byte) ([]byte, error) { return appendView(c.WithBytes, b) }
+
+// AppendBase64 appends the serialized ciphertext to b in standard base64.
+func (c *Uint8Ciphertext) AppendBase64(b []byte) ([]byte, error) {
+	return appendBase64View(c.WithBytes, b)
+}
+
+// WriteTo writes the serialized ciphertext to w.
+func (c *IntCiphertext) WriteTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// AppendBinary appends the serialized ciphertext to b.
+func (c *IntCiphertext) AppendBinary(b []byte) ([]byte, error) { return appendView(c.WithBytes, b) }
+
+// AppendBase64 appends the serialized ciphertext to b in standard base64.
+func (c *IntCiphertext) AppendBase64(b []byte) ([]byte, error) {
+	return appendBase64View(c.WithBytes, b)
+}
+
+// WriteTo writes the serialized FheBool to w.
+func (c *FheBool) WriteTo(w io.Writer) (int64, error) { return writeView(c.WithBytes, w) }
+
+// AppendBinary appends the serialized FheBool to b.
+func (c *FheBool) AppendBinary(b []byte) ([]byte, error) { return appendView(c.WithBytes, b) }
+
+// AppendBase64 appends the serialized FheBool to b in standard base64.
+func (c *FheBool) AppendBase64(b []byte) ([]byte, error) {
+	return appendBase64View(c.WithBytes, b)
+}
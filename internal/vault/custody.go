@@ -0,0 +1,165 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	vaultapi "github.com/hashicorp/vault/api"
+
+	"tfhe-go/internal/keys"
+)
+
+// Custody is a keys.Store keeping client keys in Vault's KV engine and the
+// rest of each record, server keys included, in an inner store. Client keys
+// are only read back when trusted decryption is enabled; otherwise the
+// loaded key sets can evaluate but not encrypt or decrypt.
+type Custody struct {
+	vault   *Client
+	inner   keys.Store
+	trusted bool
+}
+
+// NewCustody returns a store splitting records between c and inner. With
+// trusted set, LoadKeySets fetches client keys from Vault.
+func NewCustody(c *Client, inner keys.Store, trusted bool) *Custody {
+	return &Custody{vault: c, inner: inner, trusted: trusted}
+}
+
+func (c *Custody) kv() *vaultapi.KVv2 { return c.vault.api.KVv2(c.vault.cfg.KVMount) }
+
+func (c *Custody) path(id string) string { return c.vault.cfg.Prefix + "/key-sets/" + id }
+
+// withoutClientKeys returns rec as the inner store keeps it.
+func withoutClientKeys(rec keys.Record) keys.Record {
+	rec.Boolean.Client, rec.Uint8.Client = nil, nil
+	return rec
+}
+
+// CreateKeySet writes the client keys to Vault, unless another instance
+// already did, then the rest of rec to the inner store.
+func (c *Custody) CreateKeySet(ctx context.Context, rec keys.Record) error {
+	data, err := c.secret(ctx, rec)
+	if err != nil {
+		return err
+	}
+	// Check-and-set 0 writes only if the path is new, so concurrent
+	// instances cannot overwrite each other's keys.
+	if _, err := c.kv().Put(ctx, c.path(rec.ID), data, vaultapi.WithCheckAndSet(0)); err != nil {
+		if casMismatch(err) {
+			return fmt.Errorf("%w: %q", keys.ErrKeySetExists, rec.ID)
+		}
+		return fmt.Errorf("vault: store client keys: %w", err)
+	}
+	return c.inner.CreateKeySet(ctx, withoutClientKeys(rec))
+}
+
+// UpdateKeySet writes a new version of the client keys, keeping the old
+// ones as earlier KV versions, then updates the inner store.
+func (c *Custody) UpdateKeySet(ctx context.Context, rec keys.Record) error {
+	data, err := c.secret(ctx, rec)
+	if err != nil {
+		return err
+	}
+	if _, err := c.kv().Put(ctx, c.path(rec.ID), data); err != nil {
+		return fmt.Errorf("vault: store client keys: %w", err)
+	}
+	return c.inner.UpdateKeySet(ctx, withoutClientKeys(rec))
+}
+
+// RevokeKeySet revokes id in the inner store and destroys every version of
+// its client keys.
+func (c *Custody) RevokeKeySet(ctx context.Context, id string, at time.Time) error {
+	if err := c.inner.RevokeKeySet(ctx, id, at); err != nil {
+		return err
+	}
+	if err := c.kv().DeleteMetadata(ctx, c.path(id)); err != nil {
+		return fmt.Errorf("vault: destroy client keys: %w", err)
+	}
+	return nil
+}
+
+// LoadKeySets loads the inner store's records and, when trusted, their
+// client keys from Vault.
+func (c *Custody) LoadKeySets(ctx context.Context) ([]keys.Record, map[string]time.Time, error) {
+	recs, revoked, err := c.inner.LoadKeySets(ctx)
+	if err != nil || !c.trusted {
+		return recs, revoked, err
+	}
+	for i := range recs {
+		secret, err := c.kv().Get(ctx, c.path(recs[i].ID))
+		if err != nil {
+			return nil, nil, fmt.Errorf("vault: client keys of %q: %w", recs[i].ID, err)
+		}
+		if recs[i].Boolean.Client, err = c.open(ctx, secret.Data["boolean_client"]); err != nil {
+			return nil, nil, fmt.Errorf("vault: boolean client key of %q: %w", recs[i].ID, err)
+		}
+		if recs[i].Uint8.Client, err = c.open(ctx, secret.Data["uint8_client"]); err != nil {
+			return nil, nil, fmt.Errorf("vault: uint8 client key of %q: %w", recs[i].ID, err)
+		}
+	}
+	return recs, revoked, nil
+}
+
+// secret returns the KV data holding rec's client keys.
+func (c *Custody) secret(ctx context.Context, rec keys.Record) (map[string]any, error) {
+	if len(rec.Boolean.Client) == 0 || len(rec.Uint8.Client) == 0 {
+		return nil, fmt.Errorf("key set %q: client keys are withheld and cannot be stored", rec.ID)
+	}
+	booleanClient, err := c.seal(ctx, rec.Boolean.Client)
+	if err != nil {
+		return nil, err
+	}
+	uint8Client, err := c.seal(ctx, rec.Uint8.Client)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{"boolean_client": booleanClient, "uint8_client": uint8Client}, nil
+}
+
+// seal encodes key for KV, encrypting it with the transit key if one is
+// configured.
+func (c *Custody) seal(ctx context.Context, key []byte) (string, error) {
+	encoded := base64.StdEncoding.EncodeToString(key)
+	if c.vault.cfg.TransitKey == "" {
+		return encoded, nil
+	}
+	out, err := c.vault.api.Logical().WriteWithContext(ctx, c.vault.cfg.TransitMount+"/encrypt/"+c.vault.cfg.TransitKey, map[string]any{"plaintext": encoded})
+	if err != nil {
+		return "", fmt.Errorf("vault: transit encrypt: %w", err)
+	}
+	ciphertext, _ := out.Data["ciphertext"].(string)
+	if ciphertext == "" {
+		return "", errors.New("vault: transit encrypt returned no ciphertext")
+	}
+	return ciphertext, nil
+}
+
+// open reverses seal. Transit ciphertexts are recognised by their "vault:"
+// prefix, so keys written before transit was enabled still load.
+func (c *Custody) open(ctx context.Context, v any) ([]byte, error) {
+	s, _ := v.(string)
+	if s == "" {
+		return nil, errors.New("missing")
+	}
+	if strings.HasPrefix(s, "vault:") {
+		if c.vault.cfg.TransitKey == "" {
+			return nil, errors.New("encrypted with transit, but no transit key is configured")
+		}
+		out, err := c.vault.api.Logical().WriteWithContext(ctx, c.vault.cfg.TransitMount+"/decrypt/"+c.vault.cfg.TransitKey, map[string]any{"ciphertext": s})
+		if err != nil {
+			return nil, fmt.Errorf("transit decrypt: %w", err)
+		}
+		s, _ = out.Data["plaintext"].(string)
+	}
+	return base64.StdEncoding.DecodeString(s)
+}
+
+// casMismatch reports whether err is KV's check-and-set rejection.
+func casMismatch(err error) bool {
+	var re *vaultapi.ResponseError
+	return errors.As(err, &re) && re.StatusCode == 400 && strings.Contains(strings.Join(re.Errors, " "), "check-and-set")
+}
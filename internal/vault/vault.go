@@ -0,0 +1,179 @@
+// Package vault keeps client keys in HashiCorp Vault rather than on the
+// service's disks or database.
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	vaultapi "github.com/hashicorp/vault/api"
+)
+
+// Config selects the Vault mounts and how the service logs in. The address,
+// namespace and TLS settings come from the standard VAULT_* variables.
+type Config struct {
+	// KVMount is the KV version 2 mount holding client keys; default
+	// "secret".
+	KVMount string
+	// Prefix is the path under KVMount; default "tfhe-go".
+	Prefix string
+	// TransitMount and TransitKey name a transit key that encrypts the
+	// client keys before they are written to KV, so that reading the KV
+	// path alone does not reveal them. Empty TransitKey disables it.
+	TransitMount string
+	TransitKey   string
+	// Auth is "token" (VAULT_TOKEN, the default), "approle" or
+	// "kubernetes".
+	Auth string
+	// Role is the AppRole role ID or the Kubernetes auth role.
+	Role string
+	// SecretIDFile holds the AppRole secret ID.
+	SecretIDFile string
+	// AuthMount overrides the auth method's mount path.
+	AuthMount string
+}
+
+// serviceAccountToken is where Kubernetes mounts the pod's token.
+const serviceAccountToken = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+// Client is a logged-in Vault client.
+type Client struct {
+	api  *vaultapi.Client
+	cfg  Config
+	stop context.CancelFunc
+}
+
+// New logs in to Vault as cfg describes and keeps the token renewed until
+// Close.
+func New(ctx context.Context, cfg Config) (*Client, error) {
+	if cfg.KVMount == "" {
+		cfg.KVMount = "secret"
+	}
+	if cfg.Prefix == "" {
+		cfg.Prefix = "tfhe-go"
+	}
+	if cfg.TransitMount == "" {
+		cfg.TransitMount = "transit"
+	}
+	vc := vaultapi.DefaultConfig()
+	if vc.Error != nil {
+		return nil, fmt.Errorf("vault: %w", vc.Error)
+	}
+	api, err := vaultapi.NewClient(vc)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %w", err)
+	}
+	c := &Client{api: api, cfg: cfg, stop: func() {}}
+	secret, err := c.login(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("vault: %s login: %w", c.authMethod(), err)
+	}
+	if secret != nil && secret.Auth != nil && secret.Auth.Renewable {
+		var renewCtx context.Context
+		renewCtx, c.stop = context.WithCancel(context.Background())
+		go c.renew(renewCtx, secret)
+	}
+	return c, nil
+}
+
+// Close stops renewing the login token.
+func (c *Client) Close() error {
+	c.stop()
+	return nil
+}
+
+func (c *Client) authMethod() string {
+	if c.cfg.Auth == "" {
+		return "token"
+	}
+	return c.cfg.Auth
+}
+
+// login authenticates and installs the resulting token. Token auth returns
+// a nil secret: the token comes from VAULT_TOKEN and is not renewed here.
+func (c *Client) login(ctx context.Context) (*vaultapi.Secret, error) {
+	var path string
+	var body map[string]any
+	switch c.authMethod() {
+	case "token":
+		if c.api.Token() == "" {
+			return nil, errors.New("VAULT_TOKEN is not set")
+		}
+		return nil, nil
+	case "approle":
+		secretID, err := os.ReadFile(c.cfg.SecretIDFile)
+		if err != nil {
+			return nil, err
+		}
+		path = "auth/" + c.mount("approle") + "/login"
+		body = map[string]any{"role_id": c.cfg.Role, "secret_id": strings.TrimSpace(string(secretID))}
+	case "kubernetes":
+		jwt, err := os.ReadFile(serviceAccountToken)
+		if err != nil {
+			return nil, err
+		}
+		path = "auth/" + c.mount("kubernetes") + "/login"
+		body = map[string]any{"role": c.cfg.Role, "jwt": strings.TrimSpace(string(jwt))}
+	default:
+		return nil, fmt.Errorf("unknown auth method %q (want token, approle or kubernetes)", c.cfg.Auth)
+	}
+	secret, err := c.api.Logical().WriteWithContext(ctx, path, body)
+	if err != nil {
+		return nil, err
+	}
+	if secret == nil || secret.Auth == nil {
+		return nil, errors.New("no token in login response")
+	}
+	c.api.SetToken(secret.Auth.ClientToken)
+	return secret, nil
+}
+
+func (c *Client) mount(def string) string {
+	if c.cfg.AuthMount != "" {
+		return c.cfg.AuthMount
+	}
+	return def
+}
+
+// renew keeps the login token alive, logging in again when it can no longer
+// be renewed.
+func (c *Client) renew(ctx context.Context, secret *vaultapi.Secret) {
+	for {
+		watcher, err := c.api.NewLifetimeWatcher(&vaultapi.LifetimeWatcherInput{Secret: secret})
+		if err != nil {
+			log.Printf("vault: token renewal: %v", err)
+			return
+		}
+		go watcher.Start()
+		stopped := watch(ctx, watcher)
+		watcher.Stop()
+		if stopped {
+			return
+		}
+		if secret, err = c.login(ctx); err != nil {
+			log.Printf("vault: %s login: %v", c.authMethod(), err)
+			return
+		}
+	}
+}
+
+// watch waits until the watcher gives up renewing, or reports true when ctx
+// is done first.
+func watch(ctx context.Context, watcher *vaultapi.LifetimeWatcher) bool {
+	for {
+		select {
+		case <-ctx.Done():
+			return true
+		case err := <-watcher.DoneCh():
+			if err != nil {
+				log.Printf("vault: token renewal: %v", err)
+			}
+			return false
+		case <-watcher.RenewCh():
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package server
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"tfhe-go/internal/estimate"
+	"tfhe-go/internal/keys"
+)
+
+// startEstimator loads the cost model behind /estimate from profile, or,
+// without one, calibrates it in the background with iterations runs per
+// operation; /estimate answers 503 until calibration finishes.
+func startEstimator(profile string, iterations int) (*estimate.Estimator, error) {
+	e := new(estimate.Estimator)
+	if profile != "" {
+		m, err := estimate.Load(profile, keys.DefaultParams)
+		if err != nil {
+			return nil, fmt.Errorf("invalid estimate profile: %w", err)
+		}
+		e.Set(m)
+		return e, nil
+	}
+	go func() {
+		start := time.Now()
+		m, err := estimate.Calibrate(keys.DefaultParams, iterations)
+		if err != nil {
+			log.Printf("estimate calibration failed; /estimate stays unavailable: %v", err)
+			return
+		}
+		e.Set(m)
+		log.Printf("estimate calibration finished in %v", time.Since(start).Round(time.Millisecond))
+	}()
+	return e, nil
+}
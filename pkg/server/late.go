@@ -0,0 +1,28 @@
+package server
+
+import (
+	"net/http"
+	"sync/atomic"
+
+	"tfhe-go/internal/apierror"
+)
+
+// lateHandler answers 503 until the API handler is installed, so the
+// listener can serve probes while keys are still being generated.
+type lateHandler struct {
+	h atomic.Pointer[http.Handler]
+}
+
+// Set installs the API handler.
+func (l *lateHandler) Set(h http.Handler) {
+	l.h.Store(&h)
+}
+
+func (l *lateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if h := l.h.Load(); h != nil {
+		(*h).ServeHTTP(w, r)
+		return
+	}
+	w.Header().Set("Retry-After", "10")
+	apierror.Write(w, http.StatusServiceUnavailable, apierror.CodeUnavailable, "service is starting: keys are being generated")
+}
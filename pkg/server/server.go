@@ -0,0 +1,603 @@
+// Package server assembles the FHE HTTP and gRPC APIs so applications can
+// embed them in their own servers instead of running cmd/server, which is a
+// thin wrapper around it.
+//
+// New validates the options and returns a Server whose Handler answers
+// /healthz at once and everything else with 503 until Start has loaded or
+// generated the keys. The process-wide settings of package tfhe, such as
+// limits and caches, are left to the caller.
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"runtime"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+
+	"tfhe-go/internal/acl"
+	"tfhe-go/internal/apierror"
+	"tfhe-go/internal/audit"
+	"tfhe-go/internal/auth"
+	"tfhe-go/internal/counters"
+	"tfhe-go/internal/elections"
+	"tfhe-go/internal/features"
+	"tfhe-go/internal/grpcapi"
+	"tfhe-go/internal/health"
+	"tfhe-go/internal/httpapi"
+	"tfhe-go/internal/idempotency"
+	"tfhe-go/internal/keys"
+	"tfhe-go/internal/machines"
+	"tfhe-go/internal/metrics"
+	"tfhe-go/internal/models"
+	"tfhe-go/internal/queue"
+	"tfhe-go/internal/quota"
+	"tfhe-go/internal/ratelimit"
+	"tfhe-go/internal/replay"
+	"tfhe-go/internal/rotation"
+	"tfhe-go/internal/sessions"
+	"tfhe-go/internal/store"
+	"tfhe-go/internal/tfhe"
+	"tfhe-go/internal/tracing"
+)
+
+// Types of the hooks in Options, so applications outside this module can
+// implement and fill them in.
+type (
+	// KeyStore persists key sets, e.g. in an application's own database.
+	KeyStore = keys.Store
+	// KeyRecord is a key set as a KeyStore keeps it.
+	KeyRecord = keys.Record
+	// KeyPair is the serialized client and server key of one API.
+	KeyPair = tfhe.KeyPair
+	// KeySet is a loaded key set.
+	KeySet = keys.KeySet
+	// CiphertextStore keeps the ciphertexts behind handles.
+	CiphertextStore = store.Store
+	// StoreEntry, StoreListOptions and StorePage appear in CiphertextStore's
+	// methods.
+	StoreEntry       = store.Entry
+	StoreListOptions = store.ListOptions
+	StorePage        = store.Page
+	// MemoryOptions bounds the memory store used without a CiphertextStore.
+	MemoryOptions = store.MemoryOptions
+	// TokenAuthenticator maps a bearer token or API key to an Identity.
+	TokenAuthenticator = auth.TokenAuthenticator
+	// Identity is an authenticated caller.
+	Identity = auth.Identity
+	// QueueBroker is a message queue to evaluate circuits from.
+	QueueBroker = queue.Broker
+	// QuotaConfig holds the per-tenant daily and monthly quotas.
+	QuotaConfig = quota.Config
+	// CORSOptions configures cross-origin requests.
+	CORSOptions = httpapi.CORSOptions
+	// HandlerOption customizes the routes of the API; see WithPrefix,
+	// WithMiddleware and WithRouteWrapper.
+	HandlerOption = httpapi.Option
+	// Middleware wraps an http.Handler.
+	Middleware = httpapi.Middleware
+	// RouteWrapper wraps the handler of the route pattern, e.g.
+	// "/v1/uint8/add", or returns next to leave it alone.
+	RouteWrapper = httpapi.RouteWrapper
+)
+
+// WithPrefix mounts the API routes, but not /admin and the documentation,
+// under prefix, so "/fhe" serves /fhe/v1/boolean/and.
+func WithPrefix(prefix string) HandlerOption { return httpapi.WithPrefix(prefix) }
+
+// WithMiddleware wraps every API route in mw, the first outermost, inside
+// the server's own authentication, limits and audit log.
+func WithMiddleware(mw ...Middleware) HandlerOption { return httpapi.WithMiddleware(mw...) }
+
+// WithRouteWrapper wraps each API route in fn, inside any WithMiddleware.
+func WithRouteWrapper(fn RouteWrapper) HandlerOption { return httpapi.WithRouteWrapper(fn) }
+
+// Options configures a Server. The zero value serves the API without
+// authentication, keeping generated keys and ciphertext handles in memory.
+type Options struct {
+	// KeyStore persists key sets so they survive restarts and are shared
+	// by instances using the same store. Without one, Start generates the
+	// default key set and keeps it in memory.
+	KeyStore KeyStore
+	// GenerateKeys creates a key set: the default one when the key store
+	// has none, and those of sessions. Nil uses GenerateKeySet.
+	GenerateKeys func() (*KeySet, error)
+	// WithholdClientKeys releases the client keys of loaded key sets, so
+	// the server only evaluates; encrypt, decrypt and public keys fail.
+	WithholdClientKeys bool
+
+	// Store keeps ciphertext handles. Nil uses a memory store bounded by
+	// Memory.
+	Store  CiphertextStore
+	Memory MemoryOptions
+
+	// Workers is the number of operations a batch request or circuit runs
+	// in parallel; 0 means GOMAXPROCS.
+	Workers int
+	// Features switches route families off or restricts them to AdminIDs,
+	// e.g. "decrypt=off,keys=admin".
+	Features string
+	// AdminIDs are the identities allowed on /admin; empty allows any
+	// authenticated caller.
+	AdminIDs []string
+
+	// Authenticators check callers; none leaves the API open.
+	Authenticators []TokenAuthenticator
+	// RateLimit is the requests per second allowed per caller, with bursts
+	// of RateBurst; 0 disables rate limiting.
+	RateLimit float64
+	RateBurst int
+	Quotas    QuotaConfig
+	// IdempotencyTTL is how long responses to requests with an
+	// Idempotency-Key are replayed; 0 disables.
+	IdempotencyTTL time.Duration
+	// Compression negotiates gzip and deflate for bodies of at least
+	// CompressMinSize bytes.
+	Compression     bool
+	CompressMinSize int
+	// ServerTiming reports each request's queueing, deserialize, compute
+	// and serialize time in a Server-Timing header (a server-timing
+	// trailer over gRPC).
+	ServerTiming bool
+	// CORS allows browser calls from other origins; nil disables CORS.
+	CORS *CORSOptions
+	// ReplayGuard rejects unsigned and replayed mutating requests.
+	ReplayGuard *replay.Guard
+	// AuditLog records key, decrypt and compute events.
+	AuditLog *audit.Logger
+
+	// SessionLimit is the number of session key sets a tenant may hold
+	// open at once, living SessionTTL (15 minutes) unless they ask for
+	// another lifetime up to SessionMaxTTL (24 hours); 0 disables
+	// /sessions.
+	SessionLimit  int
+	SessionTTL    time.Duration
+	SessionMaxTTL time.Duration
+
+	// EstimateProfile is a `tfhe bench -format json` file /estimate
+	// predicts costs from. Without one, EstimateCalibrate iterations per
+	// operation are measured in the background; 0 disables /estimate.
+	EstimateProfile   string
+	EstimateCalibrate int
+
+	// ReadyMaxInFlight and ReadyMaxNativeMemory are the operations in
+	// flight and bytes of native memory at which /readyz reports not ready;
+	// 0 disables each check. The native self-test behind /readyz runs every
+	// SelfTestInterval, 30 seconds by default.
+	ReadyMaxInFlight     int
+	ReadyMaxNativeMemory int64
+	SelfTestInterval     time.Duration
+
+	// Metrics collects Prometheus metrics, served by MetricsHandler.
+	Metrics bool
+	// Tracing traces requests with the global OpenTelemetry provider.
+	Tracing bool
+	// SwaggerUI serves the interactive API documentation at /docs.
+	SwaggerUI bool
+	// Queue is a broker to evaluate circuits from alongside the APIs.
+	Queue QueueBroker
+	// Reload backs POST /admin/reload; nil leaves the route unregistered.
+	Reload func(context.Context) error
+	// HandlerOptions add prefixes, middleware and per-route wrappers to
+	// the API routes.
+	HandlerOptions []HandlerOption
+}
+
+// Server is the FHE API. Its methods are safe for concurrent use.
+type Server struct {
+	opts     Options
+	policy   features.Policy
+	usage    *quota.Tracker
+	limiter  *ratelimit.Limiter
+	checker  *health.Checker
+	recorder *tfhe.Recorder
+	front    *http.ServeMux
+	app      lateHandler
+	handler  http.Handler // front tagging requests with IDs
+
+	mu       sync.Mutex
+	started  bool
+	registry *keys.Registry
+	prom     *metrics.Prometheus
+	sessions *sessions.Manager
+	stop     context.CancelFunc
+	done     chan struct{} // closed when the queue worker has stopped
+}
+
+// New checks opts and returns a Server whose API is unavailable until Start.
+func New(opts Options) (*Server, error) {
+	if opts.Workers <= 0 {
+		opts.Workers = runtime.GOMAXPROCS(0)
+	}
+	if opts.SelfTestInterval <= 0 {
+		opts.SelfTestInterval = 30 * time.Second
+	}
+	if opts.GenerateKeys == nil {
+		opts.GenerateKeys = GenerateKeySet
+	}
+	policy, err := features.Parse(opts.Features)
+	if err != nil {
+		return nil, fmt.Errorf("invalid features: %w", err)
+	}
+	if a, ok := policy[features.KeySwitching]; ok && a != features.Off && !tfhe.KeySwitching {
+		return nil, fmt.Errorf("invalid features: %s=%s needs a server built with -tags purego; the native backend does not implement key switching", features.KeySwitching, a)
+	}
+	if opts.SessionTTL <= 0 {
+		opts.SessionTTL = 15 * time.Minute
+	}
+	if opts.SessionMaxTTL <= 0 {
+		opts.SessionMaxTTL = 24 * time.Hour
+	}
+	s := &Server{
+		opts:     opts,
+		policy:   policy,
+		usage:    quota.New(opts.Quotas),
+		checker:  health.New(opts.ReadyMaxInFlight),
+		recorder: tfhe.NewRecorder(),
+		front:    http.NewServeMux(),
+	}
+	if opts.RateLimit > 0 {
+		s.limiter = ratelimit.New(opts.RateLimit, opts.RateBurst)
+	}
+	s.checker.SetMaxNativeMemory(opts.ReadyMaxNativeMemory)
+	if opts.AuditLog != nil {
+		s.checker.SetCheck("audit", opts.AuditLog.Err)
+	}
+	s.checker.Register(s.front)
+	s.front.Handle("/", &s.app)
+	s.handler = apierror.Middleware(s.front)
+	return s, nil
+}
+
+// Handler returns the HTTP API with the /healthz and /readyz probes, giving
+// every request an X-Request-Id that error responses carry. It can be
+// mounted before Start; until then the API answers 503.
+func (s *Server) Handler() http.Handler {
+	return s.handler
+}
+
+// Start loads the key sets from the key store, generating the default one
+// when there is none, and starts serving the API. The self-test, session
+// expiry and the queue worker run until ctx is done or Shutdown is called.
+func (s *Server) Start(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return errors.New("server already started")
+	}
+	registry, err := s.loadKeys(ctx)
+	if err != nil {
+		return err
+	}
+	booleanService := registry.Default().Boolean
+	uint8Service := registry.Default().Uint8
+
+	var sink tfhe.Metrics = s.recorder
+	if s.opts.Metrics {
+		s.prom = metrics.NewPrometheus(registry)
+		sink = tfhe.MultiMetrics(s.recorder, s.prom)
+	}
+	booleanService.SetMetrics(sink)
+	uint8Service.SetMetrics(sink)
+
+	ciphertextStore := s.opts.Store
+	if ciphertextStore == nil {
+		ciphertextStore = store.NewMemory(s.opts.Memory)
+	}
+
+	ctx, s.stop = context.WithCancel(ctx)
+	mux := http.NewServeMux()
+	handler := httpapi.NewHandler(registry, ciphertextStore, s.opts.HandlerOptions...)
+	handler.SetBatchConcurrency(s.opts.Workers)
+	handler.SetUsageTracker(s.usage)
+	handleACL := acl.NewRegistry()
+	handler.SetACL(handleACL)
+	if m, ok := ciphertextStore.(*store.Memory); ok {
+		m.SetOnEvict(handleACL.Forget)
+		if s.prom != nil {
+			s.prom.ObserveStore(m)
+		}
+	}
+	counterRegistry := counters.NewRegistry()
+	handler.SetCounters(counterRegistry)
+	electionRegistry := elections.NewRegistry()
+	handler.SetElections(electionRegistry)
+	handler.SetMachines(machines.NewRegistry())
+	handler.SetModels(models.NewRegistry())
+	handler.SetFeatures(s.policy, s.opts.AdminIDs)
+	if s.opts.EstimateProfile != "" || s.opts.EstimateCalibrate > 0 {
+		estimator, err := startEstimator(s.opts.EstimateProfile, s.opts.EstimateCalibrate)
+		if err != nil {
+			s.stop()
+			return err
+		}
+		handler.SetEstimator(estimator)
+	}
+	if s.opts.SessionLimit > 0 {
+		s.sessions = sessions.New(registry, func() (*keys.KeySet, error) {
+			ks, err := s.opts.GenerateKeys()
+			if err == nil {
+				ks.Boolean.SetMetrics(sink)
+				ks.Uint8.SetMetrics(sink)
+			}
+			return ks, err
+		}, ciphertextStore, sessions.Options{DefaultTTL: s.opts.SessionTTL, MaxTTL: s.opts.SessionMaxTTL, MaxPerTenant: s.opts.SessionLimit})
+		s.sessions.SetForget(handleACL.Forget)
+		handler.SetSessions(s.sessions)
+		go s.sessions.Run(ctx, time.Minute)
+	}
+	handler.Register(mux)
+
+	rotationManager := rotation.NewManager(keys.DefaultID, booleanService, uint8Service, ciphertextStore)
+	rotationManager.SetPersist(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		defer cancel()
+		return registry.Save(ctx, keys.DefaultID)
+	})
+	handler.SetRotation(rotationManager)
+	admin := httpapi.NewAdminHandler(registry, rotationManager, s.recorder, s.opts.AdminIDs)
+	admin.SetUsageTracker(s.usage)
+	admin.SetCounters(counterRegistry)
+	admin.SetElections(electionRegistry)
+	admin.SetACL(handleACL, ciphertextStore)
+	admin.SetReloader(s.opts.Reload)
+	admin.SetFeatures(s.policy)
+	if s.opts.AuditLog != nil {
+		rotationManager.SetAudit(s.opts.AuditLog)
+		admin.SetAudit(s.opts.AuditLog)
+	}
+	admin.Register(mux)
+	httpapi.NewDocsHandler(s.opts.SwaggerUI).Register(mux)
+
+	s.app.Set(s.middleware(mux, handler))
+	s.registry = registry
+	s.checker.MarkKeysReady()
+	if tfhe.Backend == "purego" {
+		// The pure-Go backend has no integer types to exercise.
+		s.checker.SetSelfTest(health.BooleanSelfTest(booleanService))
+	} else {
+		s.checker.SetSelfTest(health.Uint8SelfTest(uint8Service))
+	}
+	go s.checker.Run(ctx, s.opts.SelfTestInterval, time.Minute)
+
+	if s.opts.Queue != nil {
+		worker := queue.NewWorker(s.opts.Queue, handler)
+		worker.SetConcurrency(s.opts.Workers)
+		s.done = make(chan struct{})
+		go func() {
+			defer close(s.done)
+			if err := worker.Run(ctx); err != nil {
+				log.Printf("queue worker stopped: %v", err)
+			}
+		}()
+	}
+	s.started = true
+	return nil
+}
+
+// loadKeys loads the key sets from the key store, or generates the default
+// set without one, and records how each came to be in the audit log.
+func (s *Server) loadKeys(ctx context.Context) (*keys.Registry, error) {
+	var registry *keys.Registry
+	// generated reports whether the default key set was created by this start.
+	var generated bool
+	if s.opts.KeyStore != nil {
+		var err error
+		registry, err = keys.Load(ctx, s.opts.KeyStore, func() (*keys.KeySet, error) {
+			generated = true
+			return s.opts.GenerateKeys()
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key sets: %w", err)
+		}
+	} else {
+		ks, err := s.opts.GenerateKeys()
+		if err != nil {
+			return nil, err
+		}
+		registry = keys.NewRegistry(ks)
+		generated = true
+	}
+	if s.opts.WithholdClientKeys {
+		// A freshly generated default set still holds its client keys.
+		for _, ks := range registry.List() {
+			if err := errors.Join(ks.Boolean.WithholdClientKey(), ks.Uint8.WithholdClientKey()); err != nil {
+				return nil, fmt.Errorf("failed to release client keys: %w", err)
+			}
+		}
+	}
+	for _, ks := range registry.List() {
+		action := audit.KeyImport
+		if generated && ks.ID == keys.DefaultID {
+			action = audit.KeyGenerate
+		}
+		_ = s.opts.AuditLog.Record(audit.Event{Action: action, Op: "startup", KeyID: ks.ID, Outcome: audit.Success})
+	}
+	return registry, nil
+}
+
+// middleware wraps the API routes in mux with the cross-cutting layers.
+//
+// Rate limiting, quotas and idempotency run inside authentication so
+// callers are keyed by identity. Quotas count bytes on the wire, outside
+// compression, and replayed idempotent responses cost no operations.
+// Idempotency sees JSON with ciphertexts in base64 and tfhe-c's
+// serialization whatever the media type, wire encoding and format version,
+// so a replay is re-encoded for the request that triggers it, and Server-Timing
+// sits outside it so a replay reports no compute. The audit log sits inside
+// authentication and session selection, so it records the session's key set
+// and also requests the limiter, quotas or replay protection reject. Replay
+// protection checks the body as sent, before decompression, and rejects
+// replays before they count against limits.
+func (s *Server) middleware(mux *http.ServeMux, handler *httpapi.Handler) http.Handler {
+	var root http.Handler = mux
+	if s.opts.IdempotencyTTL > 0 {
+		root = idempotency.New(idempotency.Options{TTL: s.opts.IdempotencyTTL, MaxRequestBytes: httpapi.MaxRequestBytes}).Middleware(root)
+	}
+	if s.opts.ServerTiming {
+		root = httpapi.ServerTiming(root)
+	}
+	root = handler.CiphertextFormat(root)
+	root = httpapi.CiphertextEncoding(root)
+	root = httpapi.CBOR(root)
+	if s.opts.Compression {
+		root = httpapi.Compress(httpapi.CompressionOptions{MinSize: s.opts.CompressMinSize})(root)
+	}
+	root = s.usage.Middleware(root)
+	if s.limiter != nil {
+		root = s.limiter.Middleware(root)
+	}
+	if s.opts.ReplayGuard != nil {
+		root = s.opts.ReplayGuard.Middleware(root)
+	}
+	if s.opts.AuditLog != nil {
+		root = s.opts.AuditLog.Middleware(root)
+	}
+	if s.sessions != nil {
+		root = s.sessions.Middleware(root)
+	}
+	if len(s.opts.Authenticators) > 0 {
+		root = auth.Middleware(s.opts.Authenticators...)(root)
+	}
+	if cors := s.opts.CORS; cors != nil && len(cors.AllowedOrigins) > 0 {
+		opts := *cors
+		if opts.ExposedHeaders == nil {
+			opts.ExposedHeaders = exposedHeaders
+		}
+		root = httpapi.CORS(opts)(root)
+	}
+	if s.prom != nil {
+		root = s.prom.Instrument(mux, root)
+	}
+	if s.opts.Tracing {
+		root = tracing.Middleware(mux, root)
+	}
+	return root
+}
+
+// exposedHeaders are the response headers cross-origin callers may read
+// unless CORSOptions lists its own.
+var exposedHeaders = []string{"Retry-After", "X-Request-Id", "ETag", "Key-Set", "Key-Version", "Idempotent-Replayed", "X-Quota-Daily-Operations-Remaining", "X-Quota-Monthly-Operations-Remaining", "X-Quota-Daily-Reset", "X-Quota-Monthly-Reset", "Ciphertext-Encoding", "Ciphertext-Format-Version", "Ciphertext-Type", "Server-Timing"}
+
+// GRPC returns a gRPC server with the API registered behind the same
+// authentication, sessions, audit log, rate limit and quotas as the HTTP
+// API, plus opts. It must be called after Start.
+func (s *Server) GRPC(opts ...grpc.ServerOption) (*grpc.Server, error) {
+	s.mu.Lock()
+	registry, sessionManager := s.registry, s.sessions
+	s.mu.Unlock()
+	if registry == nil {
+		return nil, errors.New("server not started")
+	}
+	grpcOpts := []grpc.ServerOption{
+		grpc.MaxRecvMsgSize(grpcapi.MaxMessageBytes()),
+		grpc.MaxSendMsgSize(grpcapi.MaxMessageBytes()),
+	}
+	var unaryInterceptors []grpc.UnaryServerInterceptor
+	var streamInterceptors []grpc.StreamServerInterceptor
+	if s.opts.Tracing {
+		unary, stream := grpcapi.TracingInterceptors()
+		unaryInterceptors = append(unaryInterceptors, unary)
+		streamInterceptors = append(streamInterceptors, stream)
+	}
+	if len(s.opts.Authenticators) > 0 {
+		unary, stream := grpcapi.AuthInterceptors(s.opts.Authenticators...)
+		unaryInterceptors = append(unaryInterceptors, unary)
+		streamInterceptors = append(streamInterceptors, stream)
+	}
+	if sessionManager != nil {
+		unary, stream := grpcapi.SessionInterceptors(sessionManager)
+		unaryInterceptors = append(unaryInterceptors, unary)
+		streamInterceptors = append(streamInterceptors, stream)
+	}
+	if s.opts.AuditLog != nil {
+		unary, stream := grpcapi.AuditInterceptors(s.opts.AuditLog)
+		unaryInterceptors = append(unaryInterceptors, unary)
+		streamInterceptors = append(streamInterceptors, stream)
+	}
+	if s.limiter != nil {
+		unary, stream := grpcapi.RateLimitInterceptors(s.limiter)
+		unaryInterceptors = append(unaryInterceptors, unary)
+		streamInterceptors = append(streamInterceptors, stream)
+	}
+	quotaUnary, quotaStream := grpcapi.QuotaInterceptors(s.usage)
+	unaryInterceptors = append(unaryInterceptors, quotaUnary)
+	streamInterceptors = append(streamInterceptors, quotaStream)
+	if s.opts.ServerTiming {
+		unary, stream := grpcapi.TimingInterceptors()
+		unaryInterceptors = append(unaryInterceptors, unary)
+		streamInterceptors = append(streamInterceptors, stream)
+	}
+	grpcOpts = append(grpcOpts,
+		grpc.ChainUnaryInterceptor(unaryInterceptors...),
+		grpc.ChainStreamInterceptor(streamInterceptors...),
+	)
+	grpcServer := grpc.NewServer(append(grpcOpts, opts...)...)
+	grpcService := grpcapi.NewServer(registry)
+	grpcService.SetFeatures(s.policy, s.opts.AdminIDs)
+	grpcService.Register(grpcServer)
+	return grpcServer, nil
+}
+
+// MetricsHandler serves the Prometheus metrics, or is nil without
+// Options.Metrics or before Start.
+func (s *Server) MetricsHandler() http.Handler {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.prom == nil {
+		return nil
+	}
+	return s.prom.Handler()
+}
+
+// Recorder returns the per-operation statistics behind /admin/stats.
+func (s *Server) Recorder() *tfhe.Recorder {
+	return s.recorder
+}
+
+// Shutdown stops the background work Start began, waiting until ctx is
+// done for the queue worker to publish the results of requests it took,
+// then closes open sessions and the default key set. The caller shuts down
+// the HTTP and gRPC servers first.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.started {
+		return nil
+	}
+	s.started = false
+	s.stop()
+	if s.done != nil {
+		select {
+		case <-s.done:
+		case <-ctx.Done():
+		}
+	}
+	if s.sessions != nil {
+		if n := s.sessions.CloseAll(); n > 0 {
+			log.Printf("closed %d open sessions", n)
+		}
+	}
+	ks := s.registry.Default()
+	return errors.Join(ks.Boolean.Close(), ks.Uint8.Close())
+}
+
+// GenerateKeySet generates a boolean and integer key set with the default
+// parameters.
+func GenerateKeySet() (*KeySet, error) {
+	booleanService, err := tfhe.NewBooleanService()
+	if err != nil {
+		return nil, fmt.Errorf("failed to init tfhe boolean service: %w", err)
+	}
+	uint8Service, err := tfhe.NewUint8Service()
+	if err != nil {
+		_ = booleanService.Close()
+		return nil, fmt.Errorf("failed to init tfhe uint8 service: %w", err)
+	}
+	return &keys.KeySet{Boolean: booleanService, Uint8: uint8Service}, nil
+}
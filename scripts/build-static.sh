@@ -0,0 +1,37 @@
+#!/usr/bin/env bash
+# Build cmd/server (or the package given as $1) linked against libtfhe.a.
+# On Linux the result is fully static and runs in a scratch container; use a
+# musl toolchain (CC=musl-gcc) to avoid glibc's warnings about static dlopen.
+# macOS does not support static executables, so there only libtfhe is
+# embedded and the system libraries stay dynamic.
+if [ -z "${BASH_VERSION:-}" ]; then
+  exec bash "$0" "$@"
+fi
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+
+PKG="${1:-./cmd/server}"
+OUT="${OUT:-bin/$(basename "${PKG}")}"
+LIB="tfhe-c/release/libtfhe.a"
+
+if [[ ! -f "${LIB}" ]]; then
+  echo "missing ${LIB}; run scripts/build-tfhe.sh first" >&2
+  exit 1
+fi
+
+LDFLAGS="-s -w"
+if [[ -n "${TFHE_VERSION:-}" ]]; then
+  LDFLAGS="${LDFLAGS} -X tfhe-go/internal/tfhe.LibraryVersion=${TFHE_VERSION}"
+fi
+if [[ "$(uname -s)" == "Linux" ]]; then
+  LDFLAGS="${LDFLAGS} -linkmode external -extldflags -static"
+fi
+
+mkdir -p "$(dirname "${OUT}")"
+echo ">> Building ${PKG} -> ${OUT}"
+CGO_ENABLED=1 go build -trimpath -tags tfhe_static -ldflags "${LDFLAGS}" -o "${OUT}" "${PKG}"
+
+if command -v file >/dev/null; then
+  file "${OUT}"
+fi
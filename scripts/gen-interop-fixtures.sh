@@ -0,0 +1,23 @@
+#!/usr/bin/env bash
+# Regenerate the interop fixtures in internal/tfhe/testdata/interop: keys and
+# safe-serialized ciphertexts from tfhe-rs, then ciphertexts from node-tfhe
+# under the same keys. With "verify", instead check the fixtures the Go test
+# writes with -interop.update decrypt correctly in tfhe-rs and node-tfhe:
+#   go test ./internal/tfhe -run Interop -interop.update
+#   scripts/gen-interop-fixtures.sh verify
+# Requires cargo and node; TFHE_VERSION must match the tfhe-c release the
+# server links against.
+if [ -z "${BASH_VERSION:-}" ]; then
+  exec bash "$0" "$@"
+fi
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+
+TFHE_VERSION="${TFHE_VERSION:-1.0.0}"
+MODE="${1:-generate}"
+OUT="$(pwd)/internal/tfhe/testdata/interop"
+
+mkdir -p "${OUT}"
+(cd scripts/interop && cargo run --release --quiet -- "${MODE}" "${OUT}")
+(cd scripts/interop && npm install --silent --no-save "node-tfhe@${TFHE_VERSION}" && node node.mjs "${MODE}" "${OUT}")
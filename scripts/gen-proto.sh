@@ -0,0 +1,16 @@
+#!/usr/bin/env bash
+# Regenerate Go code for api/ protobuf definitions.
+# Requires protoc, protoc-gen-go and protoc-gen-go-grpc on PATH:
+#   go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.34.2
+#   go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.4.0
+if [ -z "${BASH_VERSION:-}" ]; then
+  exec bash "$0" "$@"
+fi
+set -euo pipefail
+
+cd "$(dirname "$0")/.."
+
+protoc \
+  --go_out=. --go_opt=paths=source_relative \
+  --go-grpc_out=. --go-grpc_opt=paths=source_relative \
+  api/tfhe/v1/tfhe.proto
@@ -0,0 +1,66 @@
+// Writes the node-tfhe interop fixtures of tfhe-go under the keys tfhe-rs
+// wrote, or verifies those the Go test writes. See
+// scripts/gen-interop-fixtures.sh.
+import fs from 'node:fs';
+import path from 'node:path';
+import * as tfhe from 'node-tfhe';
+
+const LIMIT = BigInt(1 << 30);
+
+// The values each producer encrypts, by type; booleans are 0 or 1.
+const VALUES = [
+  ['bool', 1n],
+  ['uint2', 3n],
+  ['uint4', 11n],
+  ['uint8', 200n],
+  ['uint16', 51234n],
+  ['uint32', 3000000000n],
+  ['uint64', 1234567890123n],
+];
+
+const CLASSES = {
+  bool: tfhe.FheBool,
+  uint2: tfhe.FheUint2,
+  uint4: tfhe.FheUint4,
+  uint8: tfhe.FheUint8,
+  uint16: tfhe.FheUint16,
+  uint32: tfhe.FheUint32,
+  uint64: tfhe.FheUint64,
+};
+
+// clear converts a value to the argument type node-tfhe takes for typ.
+function clear(typ, value) {
+  if (typ === 'bool') return value === 1n;
+  if (typ === 'uint64') return value;
+  return Number(value);
+}
+
+const [mode, out] = process.argv.slice(2);
+const client = tfhe.TfheClientKey.deserialize(fs.readFileSync(path.join(out, 'keys', 'client_key.bin')));
+
+if (mode === 'generate') {
+  const dir = path.join(out, 'node-tfhe');
+  fs.mkdirSync(dir, { recursive: true });
+  const ciphertexts = [];
+  for (const [typ, value] of VALUES) {
+    const ct = CLASSES[typ].encrypt_with_client_key(clear(typ, value), client);
+    const file = `${typ}.bin`;
+    fs.writeFileSync(path.join(dir, file), ct.safe_serialize(LIMIT));
+    ciphertexts.push({ type: typ, value: Number(value), file });
+  }
+  const manifest = { producer: 'node-tfhe 1.0.0', ciphertexts };
+  fs.writeFileSync(path.join(dir, 'manifest.json'), JSON.stringify(manifest, null, 2));
+} else if (mode === 'verify') {
+  const dir = path.join(out, 'tfhe-go');
+  const manifest = JSON.parse(fs.readFileSync(path.join(dir, 'manifest.json')));
+  for (const { type, value, file } of manifest.ciphertexts) {
+    const ct = CLASSES[type].safe_deserialize(fs.readFileSync(path.join(dir, file)), LIMIT);
+    const got = BigInt(ct.decrypt(client));
+    if (got !== BigInt(value)) {
+      throw new Error(`tfhe-go ${type}: got ${got}, want ${value}`);
+    }
+  }
+  console.log(`node-tfhe read ${manifest.ciphertexts.length} tfhe-go ciphertexts`);
+} else {
+  throw new Error(`unknown mode ${mode}`);
+}
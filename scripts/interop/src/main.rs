@@ -0,0 +1,107 @@
+//! Writes the tfhe-rs interop fixtures of tfhe-go, or verifies those the Go
+//! test writes. See scripts/gen-interop-fixtures.sh.
+
+use std::{env, fs, path::Path};
+
+use serde_json::{json, Value};
+use tfhe::prelude::*;
+use tfhe::safe_serialization::{safe_deserialize, safe_serialize};
+use tfhe::{
+    generate_keys, ClientKey, ConfigBuilder, FheBool, FheUint16, FheUint2, FheUint32, FheUint4,
+    FheUint64, FheUint8,
+};
+
+const LIMIT: u64 = 1 << 30;
+
+/// The values each producer encrypts, by type; booleans are 0 or 1.
+const VALUES: [(&str, u64); 7] = [
+    ("bool", 1),
+    ("uint2", 3),
+    ("uint4", 11),
+    ("uint8", 200),
+    ("uint16", 51234),
+    ("uint32", 3_000_000_000),
+    ("uint64", 1_234_567_890_123),
+];
+
+fn main() {
+    let args: Vec<String> = env::args().collect();
+    let (mode, out) = (args[1].as_str(), Path::new(&args[2]));
+    match mode {
+        "generate" => generate(out),
+        "verify" => verify(out),
+        _ => panic!("unknown mode {mode}"),
+    }
+}
+
+fn generate(out: &Path) {
+    let (client, server) = generate_keys(ConfigBuilder::default());
+    fs::create_dir_all(out.join("keys")).unwrap();
+    fs::write(out.join("keys/client_key.bin"), bincode::serialize(&client).unwrap()).unwrap();
+    fs::write(out.join("keys/server_key.bin"), bincode::serialize(&server).unwrap()).unwrap();
+
+    let dir = out.join("tfhe-rs");
+    fs::create_dir_all(&dir).unwrap();
+    let mut entries = Vec::new();
+    for (typ, value) in VALUES {
+        let mut buf = Vec::new();
+        match typ {
+            "bool" => safe_serialize(&FheBool::encrypt(value == 1, &client), &mut buf, LIMIT),
+            "uint2" => safe_serialize(&FheUint2::encrypt(value as u8, &client), &mut buf, LIMIT),
+            "uint4" => safe_serialize(&FheUint4::encrypt(value as u8, &client), &mut buf, LIMIT),
+            "uint8" => safe_serialize(&FheUint8::encrypt(value as u8, &client), &mut buf, LIMIT),
+            "uint16" => safe_serialize(&FheUint16::encrypt(value as u16, &client), &mut buf, LIMIT),
+            "uint32" => safe_serialize(&FheUint32::encrypt(value as u32, &client), &mut buf, LIMIT),
+            _ => safe_serialize(&FheUint64::encrypt(value, &client), &mut buf, LIMIT),
+        }
+        .unwrap();
+        let file = format!("{typ}.bin");
+        fs::write(dir.join(&file), buf).unwrap();
+        entries.push(json!({"type": typ, "value": value, "file": file}));
+    }
+    let manifest = json!({"producer": "tfhe-rs 1.0.0", "ciphertexts": entries});
+    fs::write(dir.join("manifest.json"), serde_json::to_string_pretty(&manifest).unwrap()).unwrap();
+}
+
+fn verify(out: &Path) {
+    let client: ClientKey =
+        bincode::deserialize(&fs::read(out.join("keys/client_key.bin")).unwrap()).unwrap();
+    let dir = out.join("tfhe-go");
+    let manifest: Value =
+        serde_json::from_slice(&fs::read(dir.join("manifest.json")).unwrap()).unwrap();
+    for entry in manifest["ciphertexts"].as_array().unwrap() {
+        let typ = entry["type"].as_str().unwrap();
+        let data = fs::read(dir.join(entry["file"].as_str().unwrap())).unwrap();
+        let r = data.as_slice();
+        let got: u64 = match typ {
+            "bool" => safe_deserialize::<FheBool>(r, LIMIT).unwrap().decrypt(&client) as u64,
+            "uint2" => DecryptU8::decrypt_u8(safe_deserialize::<FheUint2>(r, LIMIT).unwrap(), &client),
+            "uint4" => DecryptU8::decrypt_u8(safe_deserialize::<FheUint4>(r, LIMIT).unwrap(), &client),
+            "uint8" => DecryptU8::decrypt_u8(safe_deserialize::<FheUint8>(r, LIMIT).unwrap(), &client),
+            "uint16" => {
+                let v: u16 = safe_deserialize::<FheUint16>(r, LIMIT).unwrap().decrypt(&client);
+                v as u64
+            }
+            "uint32" => {
+                let v: u32 = safe_deserialize::<FheUint32>(r, LIMIT).unwrap().decrypt(&client);
+                v as u64
+            }
+            _ => safe_deserialize::<FheUint64>(r, LIMIT).unwrap().decrypt(&client),
+        };
+        let want = entry["value"].as_u64().unwrap();
+        assert_eq!(got, want, "tfhe-go {typ}");
+    }
+    println!("tfhe-rs read {} tfhe-go ciphertexts", manifest["ciphertexts"].as_array().unwrap().len());
+}
+
+/// Decrypts the integer types whose clear type is u8.
+trait DecryptU8 {
+    fn decrypt_u8(self, client: &ClientKey) -> u64;
+}
+
+impl<T: FheDecrypt<u8>> DecryptU8 for T {
+    fn decrypt_u8(self, client: &ClientKey) -> u64 {
+        let v: u8 = self.decrypt(client);
+        v as u64
+    }
+}